@@ -1,17 +1,9 @@
 package main
 
-import (
-	"fmt"
-	"os"
-
-	"github.com/gitworkspaces/gitws/internal/cli"
-)
+import "github.com/gitworkspaces/gitws/internal/cli"
 
 var version = "dev"
 
 func main() {
-	if err := cli.Execute(version); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	cli.Main(version)
 }
@@ -0,0 +1,11 @@
+// Command gws is the short-name entry point for gitws. It shares the
+// internal/cli command tree, flags, and version with cmd/gitws.
+package main
+
+import "github.com/gitworkspaces/gitws/internal/cli"
+
+var version = "dev"
+
+func main() {
+	cli.Main(version)
+}
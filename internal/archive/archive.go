@@ -0,0 +1,272 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the archive layout version written to the manifest
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.yaml"
+	configName   = "config.yaml"
+	keysDir      = "keys"
+
+	encMagic  = "GWSENC1"
+	saltSize  = 16
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keyLength = 32
+)
+
+// ErrBadPassphrase is returned when an encrypted key cannot be decrypted
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupted key")
+
+// Manifest describes an export archive
+type Manifest struct {
+	Version     int       `yaml:"version"`
+	CreatedAt   time.Time `yaml:"created_at"`
+	Home        string    `yaml:"home"` // exporting user's home, for path remapping
+	IncludeKeys bool      `yaml:"include_keys"`
+}
+
+// Key holds a workspace key pair. Private is only populated on write or
+// after Unseal; Sealed is the encrypted form stored in the archive.
+type Key struct {
+	Public  []byte
+	Private []byte
+	Sealed  []byte
+}
+
+// Bundle is the in-memory content of an export archive
+type Bundle struct {
+	Manifest Manifest
+	Config   *config.File
+	Keys     map[string]Key // by workspace name
+}
+
+// Write writes the bundle to archivePath as a gzipped tarball. Private keys are
+// encrypted with passphrase; they are omitted when the manifest says so.
+func Write(archivePath string, b *Bundle, passphrase string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := yaml.Marshal(b.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeEntry(tw, manifestName, manifest); err != nil {
+		return err
+	}
+
+	cfg, err := yaml.Marshal(b.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := writeEntry(tw, configName, cfg); err != nil {
+		return err
+	}
+
+	if b.Manifest.IncludeKeys {
+		for name, key := range b.Keys {
+			if err := writeEntry(tw, path.Join(keysDir, name+".pub"), key.Public); err != nil {
+				return err
+			}
+			sealed, err := Encrypt(key.Private, passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt key for %q: %w", name, err)
+			}
+			if err := writeEntry(tw, path.Join(keysDir, name+".enc"), sealed); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Read reads an archive written by Write. Private keys stay sealed until
+// Unseal is called.
+func Read(archivePath string) (*Bundle, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	b := &Bundle{Keys: make(map[string]Key)}
+	var haveManifest bool
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		switch dir, file := path.Split(hdr.Name); {
+		case hdr.Name == manifestName:
+			if err := yaml.Unmarshal(data, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			haveManifest = true
+		case hdr.Name == configName:
+			b.Config = &config.File{}
+			if err := yaml.Unmarshal(data, b.Config); err != nil {
+				return nil, fmt.Errorf("failed to parse config: %w", err)
+			}
+		case dir == keysDir+"/" && path.Ext(file) == ".pub":
+			name := file[:len(file)-len(".pub")]
+			key := b.Keys[name]
+			key.Public = data
+			b.Keys[name] = key
+		case dir == keysDir+"/" && path.Ext(file) == ".enc":
+			name := file[:len(file)-len(".enc")]
+			key := b.Keys[name]
+			key.Sealed = data
+			b.Keys[name] = key
+		}
+	}
+
+	if !haveManifest || b.Config == nil {
+		return nil, fmt.Errorf("%s is not a gitws export archive", archivePath)
+	}
+	if b.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("archive format version %d is newer than supported (%d); upgrade gitws", b.Manifest.Version, FormatVersion)
+	}
+	if b.Config.Workspaces == nil {
+		b.Config.Workspaces = make(map[string]config.Workspace)
+	}
+
+	return b, nil
+}
+
+// Unseal decrypts every sealed private key in the bundle
+func (b *Bundle) Unseal(passphrase string) error {
+	for name, key := range b.Keys {
+		if key.Sealed == nil {
+			continue
+		}
+		plain, err := Decrypt(key.Sealed, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt key for %q: %w", name, err)
+		}
+		key.Private = plain
+		b.Keys[name] = key
+	}
+	return nil
+}
+
+// Encrypt seals plaintext with a key derived from passphrase (scrypt + AES-GCM)
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(encMagic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, []byte(encMagic)), nil
+}
+
+// Decrypt opens data produced by Encrypt
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if len(data) < len(encMagic)+saltSize || string(data[:len(encMagic)]) != encMagic {
+		return nil, ErrBadPassphrase
+	}
+	data = data[len(encMagic):]
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrBadPassphrase
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, sealed, []byte(encMagic))
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return plain, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
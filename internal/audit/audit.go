@@ -0,0 +1,182 @@
+// Package audit keeps the append-only log of every file gitws changes,
+// for compliance reviews of what was done to a machine and when.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// FileName is the audit log under the state directory
+const FileName = "audit.log"
+
+// Entry is one change in the audit log, stored as a line of JSON
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Path    string    `json:"path"`
+	// Before and After are SHA-256 digests of the file's contents; Before
+	// is empty when the file was created and After when it was removed
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	// Prev and Hash chain the entry to the one before it, when chaining is
+	// on: Hash covers the entry and Prev, so editing or removing an earlier
+	// entry breaks every later one
+	Prev string `json:"prev,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// ChainError reports the first entry whose hash chain does not verify
+type ChainError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log broken at line %d: %s", e.Line, e.Reason)
+}
+
+// Path returns the path of the audit log
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Digest returns the hex SHA-256 of data, or "" for nil (no file)
+func Digest(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Append adds e to the audit log. With chain, e is linked to the last
+// chained entry.
+func Append(e Entry, chain bool) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	lock, err := fsutil.LockFile(path, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	e.Prev, e.Hash = "", ""
+	if chain {
+		entries, err := Read()
+		if err != nil {
+			return err
+		}
+		e.Prev = lastHash(entries)
+		e.Hash = e.sum()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the entries of the audit log, oldest first, or none when
+// there is no log yet
+func Read() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes the lines of an audit log
+func Parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", n, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify checks the hash chain of entries. It returns how many entries are
+// chained; entries recorded while chaining was off are not covered.
+func Verify(entries []Entry) (int, error) {
+	chained := 0
+	prev := ""
+	for i, e := range entries {
+		if e.Hash == "" {
+			if e.Prev != "" {
+				return chained, &ChainError{Line: i + 1, Reason: "entry has a previous hash but no hash"}
+			}
+			continue
+		}
+		if e.Prev != prev {
+			return chained, &ChainError{Line: i + 1, Reason: "previous hash does not match the entry before it; an entry was removed or edited"}
+		}
+		if e.sum() != e.Hash {
+			return chained, &ChainError{Line: i + 1, Reason: "hash does not match the entry; it was edited"}
+		}
+		prev = e.Hash
+		chained++
+	}
+	return chained, nil
+}
+
+// lastHash returns the hash of the last chained entry
+func lastHash(entries []Entry) string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Hash != "" {
+			return entries[i].Hash
+		}
+	}
+	return ""
+}
+
+// sum hashes an entry together with Prev
+func (e Entry) sum() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	return Digest(data)
+}
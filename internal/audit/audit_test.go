@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAppendAndVerify(t *testing.T) {
+	t.Setenv("GWS_HOME", t.TempDir())
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	changes := []struct {
+		path  string
+		chain bool
+	}{
+		{"/home/me/.ssh/config", false},
+		{"/home/me/.gitconfig", true},
+		{"/home/me/.gws/config.yaml", true},
+	}
+	for _, c := range changes {
+		e := Entry{Time: at, Command: "gitws init work", Path: c.path, After: Digest([]byte(c.path))}
+		if err := Append(e, c.chain); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	entries, err := Read()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	chained, err := Verify(entries)
+	if err != nil || chained != 2 {
+		t.Errorf("expected 2 chained entries, got %d, %v", chained, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	t.Setenv("GWS_HOME", t.TempDir())
+
+	for _, path := range []string{"a", "b", "c"} {
+		if err := Append(Entry{Time: time.Now(), Path: path}, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]Entry) []Entry
+		line   int
+	}{
+		{"edited", func(e []Entry) []Entry { e[1].Path = "x"; return e }, 2},
+		{"removed", func(e []Entry) []Entry { return append(e[:1:1], e[2]) }, 2},
+		{"unchained", func(e []Entry) []Entry { e[0].Hash = ""; return e }, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := append([]Entry{}, entries...)
+			_, err := Verify(tt.tamper(copied))
+			var chainErr *ChainError
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("expected a chain error, got %v", err)
+			}
+			if chainErr.Line != tt.line {
+				t.Errorf("expected line %d, got %d", tt.line, chainErr.Line)
+			}
+		})
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	t.Setenv("GWS_HOME", t.TempDir())
+
+	entries, err := Read()
+	if err != nil || entries != nil {
+		t.Errorf("expected no entries, got %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package cache keeps what gitws last learned about each workspace: the
+// repositories under its root, what the provider reports, and doctor
+// results. Listings and shell completions read it, so they work without
+// the network or a scan of every workspace root.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// StaleAfter is how old cached data gets before listings flag it
+const StaleAfter = 7 * 24 * time.Hour
+
+// Data is the cache in ~/.gws/cache.json, by workspace name
+type Data struct {
+	Workspaces map[string]*Workspace `json:"workspaces"`
+}
+
+// Workspace is what is known about one workspace
+type Workspace struct {
+	// Repos are the repositories found under the root
+	Repos   []Repo    `json:"repos,omitempty"`
+	Scanned time.Time `json:"scanned,omitempty"`
+
+	// Account and Remote are what the provider API reported: the account
+	// the token authenticates as and the full names of the repositories
+	// it can reach
+	Account string    `json:"account,omitempty"`
+	Remote  []string  `json:"remote,omitempty"`
+	Fetched time.Time `json:"fetched,omitempty"`
+}
+
+// Repo is a repository under a workspace root
+type Repo struct {
+	Path   string  `json:"path"`
+	Name   string  `json:"name,omitempty"` // ORG/REPO of origin
+	Doctor *Doctor `json:"doctor,omitempty"`
+}
+
+// Doctor is the outcome of the last 'gitws doctor' run in a repository
+type Doctor struct {
+	At       time.Time `json:"at"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+// Path returns the path to the cache file
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache.json"), nil
+}
+
+// Load reads the cache, returning an empty one when there is none. A
+// corrupt cache is treated as empty; it only holds what can be
+// rediscovered.
+func Load() (*Data, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &Data{Workspaces: make(map[string]*Workspace)}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return &Data{Workspaces: make(map[string]*Workspace)}, nil
+	}
+	if data.Workspaces == nil {
+		data.Workspaces = make(map[string]*Workspace)
+	}
+	return data, nil
+}
+
+// Save writes the cache
+func (d *Data) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	return fsutil.AtomicWrite(path, append(raw, '\n'), 0600)
+}
+
+// Clear deletes the cache file
+func Clear() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cache: %w", err)
+	}
+	return nil
+}
+
+// Workspace returns the entry of a workspace, adding it when missing
+func (d *Data) Workspace(name string) *Workspace {
+	if d.Workspaces[name] == nil {
+		d.Workspaces[name] = &Workspace{}
+	}
+	return d.Workspaces[name]
+}
+
+// Prune drops the entries of workspaces not in names
+func (d *Data) Prune(names []string) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	for name := range d.Workspaces {
+		if !keep[name] {
+			delete(d.Workspaces, name)
+		}
+	}
+}
+
+// SetRepos replaces the repositories found under a workspace root,
+// keeping the doctor results of those still there
+func (w *Workspace) SetRepos(repos []Repo, at time.Time) {
+	doctor := make(map[string]*Doctor, len(w.Repos))
+	for _, r := range w.Repos {
+		doctor[r.Path] = r.Doctor
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	for i := range repos {
+		if repos[i].Doctor == nil {
+			repos[i].Doctor = doctor[repos[i].Path]
+		}
+	}
+	w.Repos = repos
+	w.Scanned = at
+}
+
+// RecordDoctor stores a doctor result for the repository at path,
+// adding the repository when the last scan did not see it
+func (w *Workspace) RecordDoctor(path string, result Doctor) {
+	for i := range w.Repos {
+		if w.Repos[i].Path == path {
+			w.Repos[i].Doctor = &result
+			return
+		}
+	}
+	w.Repos = append(w.Repos, Repo{Path: path, Doctor: &result})
+	sort.Slice(w.Repos, func(i, j int) bool { return w.Repos[i].Path < w.Repos[j].Path })
+}
+
+// Stale reports whether data from at is too old to trust, or missing
+func Stale(at, now time.Time) bool {
+	return at.IsZero() || now.Sub(at) > StaleAfter
+}
+
+// Age describes how long ago at was, e.g. "3h ago", or "never" when
+// it is zero
+func Age(at, now time.Time) string {
+	if at.IsZero() {
+		return "never"
+	}
+	d := now.Sub(at)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestSetReposKeepsDoctorResults(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	w := &Workspace{}
+	w.RecordDoctor("/code/acme/api", Doctor{At: at, Warnings: 2})
+	w.RecordDoctor("/code/acme/old", Doctor{At: at, Errors: 1})
+
+	w.SetRepos([]Repo{{Path: "/code/acme/web", Name: "acme/web"}, {Path: "/code/acme/api", Name: "acme/api"}}, at)
+
+	if len(w.Repos) != 2 || w.Repos[0].Path != "/code/acme/api" || w.Repos[1].Path != "/code/acme/web" {
+		t.Fatalf("expected api then web, got %+v", w.Repos)
+	}
+	if w.Repos[0].Doctor == nil || w.Repos[0].Doctor.Warnings != 2 {
+		t.Errorf("expected the doctor result of api to be kept, got %+v", w.Repos[0].Doctor)
+	}
+	if w.Repos[1].Doctor != nil {
+		t.Errorf("expected no doctor result for web, got %+v", w.Repos[1].Doctor)
+	}
+	if !w.Scanned.Equal(at) {
+		t.Errorf("expected scan time %v, got %v", at, w.Scanned)
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at       time.Time
+		expected string
+		stale    bool
+	}{
+		{time.Time{}, "never", true},
+		{now.Add(-30 * time.Second), "just now", false},
+		{now.Add(-5 * time.Minute), "5m ago", false},
+		{now.Add(-26 * time.Hour), "26h ago", false},
+		{now.Add(-9 * 24 * time.Hour), "9d ago", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := Age(tt.at, now); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+			if got := Stale(tt.at, now); got != tt.stale {
+				t.Errorf("expected stale %v, got %v", tt.stale, got)
+			}
+		})
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	data, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data.Workspace("work").Account = "me-work"
+	data.Workspace("gone").Account = "someone"
+	data.Prune([]string{"work", "personal"})
+	if err := data.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Workspaces) != 1 || data.Workspaces["work"].Account != "me-work" {
+		t.Errorf("expected only work (me-work), got %+v", data.Workspaces)
+	}
+
+	// A corrupt cache is as good as none
+	path, _ := Path()
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = Load(); err != nil || len(data.Workspaces) != 0 {
+		t.Errorf("expected an empty cache, got %+v, %v", data, err)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", path)
+	}
+}
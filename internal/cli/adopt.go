@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/layout"
+	"github.com/gitworkspaces/gitws/internal/progress"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/stats"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptWorkspace string
+	adoptDryRun    bool
+	adoptYes       bool
+	adoptNoHooks   bool
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <dir>",
+	Short: "Bring existing repositories under a workspace in one pass",
+	Long: `Scan a directory tree for git repositories and bring each one in line
+with its workspace.
+
+This command will:
+- Match each repository to a workspace by its remote alias, location, or
+  remote host (or use --workspace for all of them)
+- Rewrite the origin remote to use the workspace SSH alias
+- Set the workspace identity and signing configuration locally
+- Install the gitws hooks (existing hooks not written by gitws are kept)
+- Point out repositories that are not where the workspace clone_layout
+  would put them; nothing is moved
+- Print a summary of what was changed, skipped, and failed
+
+Examples:
+  gitws adopt ~/work-projects --dry-run
+  gitws adopt ~/work-projects --workspace work
+  gitws adopt ~/src --yes --no-hooks`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdopt,
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().StringVar(&adoptWorkspace, "workspace", "", "Adopt every repository into this workspace")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "Only show what would change")
+	adoptCmd.Flags().BoolVar(&adoptYes, "yes", false, "Skip confirmation prompt")
+	adoptCmd.Flags().BoolVar(&adoptNoHooks, "no-hooks", false, "Do not install hooks")
+}
+
+// adoption is the plan for one repository
+type adoption struct {
+	Path      string
+	Workspace string
+	RemoteURL string // rewritten origin URL, empty when unchanged
+	Identity  bool
+	Hooks     bool
+	// Expected is where the workspace clone layout would put the
+	// repository, when it set one and the repository is elsewhere.
+	// Repositories are never moved.
+	Expected string
+}
+
+// Changes lists the fixes the plan applies, named like the fix flags
+func (a adoption) Changes() []string {
+	var changes []string
+	if a.RemoteURL != "" {
+		changes = append(changes, "rewrite-remote")
+	}
+	if a.Identity {
+		changes = append(changes, "set-identity")
+	}
+	if a.Hooks {
+		changes = append(changes, "enable-guards")
+	}
+	return changes
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	dir, err := workspace.ExpandPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if adoptWorkspace != "" {
+		if _, exists := cfg.GetWorkspace(adoptWorkspace); !exists {
+			return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", adoptWorkspace, adoptWorkspace)
+		}
+	}
+
+	repos, err := findRepositories(dir)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Printf("No git repositories found under %s\n", dir)
+		return nil
+	}
+
+	fmt.Printf("Found %d repositories under %s\n\n", len(repos), dir)
+
+	var plans []adoption
+	var unmatched []string
+	upToDate := 0
+	bar := progress.New("Checking repositories", len(repos))
+	for _, repo := range repos {
+		plan, ok := planAdoption(cfg, repo)
+		bar.Add(1)
+		if !ok {
+			unmatched = append(unmatched, repo)
+			bar.Printf("  ? %s: no matching workspace\n", relativeTo(dir, repo))
+			continue
+		}
+		if plan.Expected != "" {
+			bar.Printf("  ! [%s] %s: clone_layout puts it at %s; left where it is\n", plan.Workspace, relativeTo(dir, repo), plan.Expected)
+		}
+		if len(plan.Changes()) == 0 {
+			upToDate++
+			if verbose {
+				bar.Printf(prompt.Text("  ✓ [%s] %s: up to date\n"), plan.Workspace, relativeTo(dir, repo))
+			}
+			continue
+		}
+		plans = append(plans, plan)
+		bar.Printf("  ~ [%s] %s: %s\n", plan.Workspace, relativeTo(dir, repo), strings.Join(plan.Changes(), ", "))
+	}
+	bar.Stop()
+	fmt.Println()
+
+	if len(plans) == 0 {
+		fmt.Println(prompt.Text("✓ Nothing to adopt."))
+		return showAdoptSummary(len(repos), 0, upToDate, unmatched, 0)
+	}
+
+	if adoptDryRun {
+		fmt.Printf("%d repositories would change. Run without --dry-run to apply.\n", len(plans))
+		return nil
+	}
+
+	if !adoptYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Adopt %d repositories?", len(plans)))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Adopt cancelled.")
+			return nil
+		}
+	}
+
+	adopted, failed := 0, 0
+	bar = progress.New("Adopting", len(plans))
+	for _, plan := range plans {
+		task := bar.Start(relativeTo(dir, plan.Path))
+		err := applyAdoption(cfg, plan)
+		task.Done()
+		if err != nil {
+			bar.Printf(prompt.Text("❌ %s: %v\n"), relativeTo(dir, plan.Path), err)
+			failed++
+			continue
+		}
+		adopted++
+	}
+	bar.Stop()
+
+	return showAdoptSummary(len(repos), adopted, upToDate, unmatched, failed)
+}
+
+// planAdoption works out what adopting a repository involves. It returns
+// false when the repository cannot be matched to a workspace.
+func planAdoption(cfg *config.File, repo string) (adoption, bool) {
+	name := adoptWorkspace
+	if name == "" {
+		name = workspaceForRepo(cfg, repo)
+	}
+	ws, exists := cfg.GetWorkspace(name)
+	if !exists {
+		return adoption{}, false
+	}
+
+	plan := adoption{Path: repo, Workspace: name}
+
+	if remoteURL, err := git.GetRemoteURL(repo); err == nil {
+		if remoteNeedsRewrite(remoteURL, ws) {
+			if _, _, newURL, err := workspaceRemote(ws, remoteURL); err == nil {
+				plan.RemoteURL = newURL
+			}
+		}
+		if ws.CloneLayout != "" && ws.Root != "" && isWithin(repo, ws.Root) {
+			if org, name, _, err := workspaceRemote(ws, remoteURL); err == nil {
+				v := layout.Vars{Root: ws.Root, Host: rewrite.PickHost(remoteURL, ws.HostAliases(), ws.HostName), Org: org, Repo: name}
+				if !layout.Match(ws.CloneLayout, v, repo) {
+					plan.Expected = layout.Expand(ws.CloneLayout, v)
+				}
+			}
+		}
+	}
+
+	userName, _ := git.GetLocalConfig(repo, "user.name")
+	userEmail, _ := git.GetLocalConfig(repo, "user.email")
+	plan.Identity = userName != ws.Name || userEmail != ws.Email
+
+	plan.Hooks = !adoptNoHooks && !git.HasManagedHooks(repo)
+
+	return plan, true
+}
+
+// applyAdoption applies a plan, stopping at the first failure
+func applyAdoption(cfg *config.File, plan adoption) error {
+	ws := cfg.Workspaces[plan.Workspace]
+
+	if plan.RemoteURL != "" {
+		if err := git.SetRemoteURL(plan.Path, plan.RemoteURL); err != nil {
+			return fmt.Errorf("failed to set remote URL: %w", err)
+		}
+		if ws.Transport == workspace.TransportHTTPS && (ws.Root == "" || !isWithin(plan.Path, ws.Root)) {
+			if err := setLocalCredentialConfig(plan.Path, plan.Workspace, ws); err != nil {
+				return err
+			}
+		}
+	}
+	if plan.Identity {
+		if err := setupRepositoryConfig(plan.Path, ws); err != nil {
+			return err
+		}
+	}
+	if plan.Hooks {
+		if err := git.InstallHooks(plan.Path, false); err != nil {
+			return err
+		}
+	}
+
+	for _, fix := range plan.Changes() {
+		recordUsage(stats.PrefixFix + fix)
+	}
+	return nil
+}
+
+func showAdoptSummary(found, adopted, upToDate int, unmatched []string, failed int) error {
+	summary := prompt.SummaryData{
+		Title: "Adopt summary",
+		Items: []prompt.SummaryItem{
+			{Label: "Repositories found", Value: fmt.Sprint(found), Icon: "📦"},
+			{Label: "Adopted", Value: fmt.Sprint(adopted), Icon: "✓"},
+			{Label: "Already configured", Value: fmt.Sprint(upToDate), Icon: "✓"},
+			{Label: "No matching workspace", Value: fmt.Sprint(len(unmatched)), Icon: "?"},
+			{Label: "Failed", Value: fmt.Sprint(failed), Icon: "❌"},
+		},
+	}
+	if len(unmatched) > 0 {
+		summary.NextSteps = append(summary.NextSteps, "Adopt unmatched repositories with --workspace <name>, or move them under a workspace root")
+	}
+	if adopted > 0 {
+		summary.NextSteps = append(summary.NextSteps, "Run 'gitws status' in any repository to verify")
+	}
+	return prompt.ShowSummary(summary)
+}
+
+// relativeTo shortens path for display relative to dir
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && rel != "." {
+		return rel
+	}
+	return path
+}
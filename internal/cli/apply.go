@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/compat"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	applyFile  string
+	applyPlan  bool
+	applyYes   bool
+	applyPrune bool
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <workspaces.yaml>",
+	Short: "Reconcile workspaces with a declarative definitions file",
+	Long: `Reconcile actual state with a workspace definitions file, e.g. one kept in dotfiles.
+
+The definitions file uses the same layout as ~/.gws/config.yaml; derived
+settings (host name, SSH alias, key path, root) may be omitted:
+
+  workspaces:
+    work:
+      email: you@work.com
+      provider: github
+      root: ~/code/work
+      signing: ssh
+    client:
+      email: you@client.com
+      host_name: gitlab.client.com
+
+apply first prints a plan of everything that differs (missing keys, stale
+SSH config blocks, workspace gitconfigs, includeIf entries), then applies
+it after confirmation. Workspaces that exist locally but are not in the
+file are reported, and removed with --prune.
+
+Examples:
+  gitws apply -f ~/dotfiles/workspaces.yaml --plan
+  gitws apply -f ~/dotfiles/workspaces.yaml
+  gitws apply -f ~/dotfiles/workspaces.yaml --yes --prune`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Workspace definitions file (required)")
+	applyCmd.Flags().BoolVar(&applyPlan, "plan", false, "Only show the plan, do not change anything")
+	applyCmd.Flags().BoolVar(&applyYes, "yes", false, "Apply without confirmation")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "Remove workspaces not present in the definitions file")
+
+	applyCmd.MarkFlagRequired("file")
+}
+
+// planChange is one line of an apply plan
+type planChange struct {
+	Action      string // "+", "~", "-", "!"
+	Workspace   string
+	Description string
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	defs, err := loadDefinitions(applyFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	names := defs.ListWorkspaces()
+	sort.Strings(names)
+
+	// Resolve every definition before touching anything
+	desired := make(map[string]config.Workspace)
+	for _, name := range names {
+		ws, err := resolveWorkspace(name, defs.Workspaces[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", applyFile, err)
+		}
+		if ws.Isolation == workspace.IsolationHasconfig {
+			warnHasconfigFallback(name, ws)
+		}
+		if ws.Signing == "ssh" {
+			if err := compat.Require(compat.SSHSigning); err != nil {
+				return fmt.Errorf("%s: workspace %q: %w", applyFile, name, err)
+			}
+		}
+		// Retired aliases come from 'gitws rename' and key age from key
+		// generation, not from definitions
+		if current, exists := cfg.GetWorkspace(name); exists {
+			if ws.RetiredAliases == nil {
+				ws.RetiredAliases = current.RetiredAliases
+			}
+			if ws.KeyCreatedAt.IsZero() && ws.SSHKey == current.SSHKey {
+				ws.KeyCreatedAt = current.KeyCreatedAt
+			}
+			for id, identity := range ws.Identities {
+				if old, ok := current.Identities[id]; ok && identity.KeyCreatedAt.IsZero() && identity.SSHKey == old.SSHKey {
+					identity.KeyCreatedAt = old.KeyCreatedAt
+					ws.Identities[id] = identity
+				}
+			}
+		}
+		desired[name] = ws
+	}
+
+	var extras []string
+	for _, name := range cfg.ListWorkspaces() {
+		if _, ok := desired[name]; !ok {
+			extras = append(extras, name)
+		}
+	}
+	sort.Strings(extras)
+
+	// Build the target config and the plan
+	target := &config.File{
+		Workspaces:  make(map[string]config.Workspace),
+		GlobalGuard: cfg.GlobalGuard,
+		Notify:      cfg.Notify,
+		Stats:       cfg.Stats,
+		GitPath:     cfg.GitPath,
+	}
+	for name, ws := range cfg.Workspaces {
+		target.SetWorkspace(name, ws)
+	}
+
+	var plan []planChange
+	for _, name := range names {
+		ws := desired[name]
+		target.SetWorkspace(name, ws)
+
+		if current, exists := cfg.GetWorkspace(name); !exists {
+			plan = append(plan, planChange{"+", name, "create workspace"})
+		} else if fields := changedFields(current, ws); len(fields) > 0 {
+			plan = append(plan, planChange{"~", name, "update workspace settings: " + strings.Join(fields, ", ")})
+		}
+
+		if !fsutil.FileExists(ws.SSHKey) {
+			plan = append(plan, planChange{"+", name, "generate SSH key " + ws.SSHKey})
+		}
+		for _, id := range sortedIdentities(ws) {
+			if keyPath := ws.Identities[id].SSHKey; !fsutil.FileExists(keyPath) {
+				plan = append(plan, planChange{"+", config.IdentityName(name, id), "generate SSH key " + keyPath})
+			}
+		}
+		if current, exists := cfg.GetWorkspace(name); exists {
+			kept := ws.HostAliases()
+			for _, h := range current.Hosts {
+				if _, ok := kept[h.HostName]; !ok {
+					plan = append(plan, planChange{"-", config.HostBlockName(name, h.HostName), "remove host SSH block"})
+				}
+			}
+			for _, id := range sortedIdentities(current) {
+				if _, kept := ws.Identities[id]; !kept {
+					plan = append(plan, planChange{"-", config.IdentityName(name, id), "remove identity SSH block"})
+				}
+			}
+		}
+
+		artifacts, err := workspaceArtifacts(name, ws)
+		if err != nil {
+			return err
+		}
+		for _, a := range artifacts {
+			if a.InSync() {
+				continue
+			}
+			action := "~"
+			if !a.Present {
+				action = "+"
+			}
+			plan = append(plan, planChange{action, name, fmt.Sprintf("write %s (%s)", a.Kind, a.Path)})
+		}
+	}
+
+	for _, name := range extras {
+		if applyPrune {
+			target.DeleteWorkspace(name)
+			plan = append(plan, planChange{"-", name, "remove workspace (SSH block, workspace gitconfig, config entry)"})
+		} else {
+			plan = append(plan, planChange{"!", name, "not in definitions file (use --prune to remove)"})
+		}
+	}
+
+	for _, name := range names {
+		if other := workspaceWithRoot(target, name, target.Workspaces[name].Root); other != "" {
+			return fmt.Errorf("%s: workspaces %q and %q share root %s", applyFile, other, name, target.Workspaces[name].Root)
+		}
+	}
+
+	includeIf, err := includeIfArtifact(target)
+	if err != nil {
+		return err
+	}
+	if !includeIf.InSync() {
+		plan = append(plan, planChange{"~", "", fmt.Sprintf("write %s block (%s)", includeIf.Kind, includeIf.Path)})
+	}
+
+	actionable := 0
+	for _, c := range plan {
+		if c.Action != "!" {
+			actionable++
+		}
+	}
+
+	printPlan(plan)
+	warnNestedRoots(target, "")
+
+	if actionable == 0 {
+		fmt.Printf(prompt.Text("✓ No changes. Actual state matches %s.\n"), applyFile)
+		return nil
+	}
+
+	if applyPlan {
+		fmt.Printf("%d change(s) pending. Run without --plan to apply.\n", actionable)
+		return nil
+	}
+
+	if !applyYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Apply %d change(s)?", actionable))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+	}
+
+	var newKeys []string
+	for _, name := range names {
+		ws := desired[name]
+
+		if !fsutil.FileExists(ws.SSHKey) {
+			defaultKey, err := ssh.KeyPath(name)
+			if err != nil {
+				return err
+			}
+			if ws.SSHKey != defaultKey {
+				return fmt.Errorf("workspace %q: ssh_key %s does not exist", name, ws.SSHKey)
+			}
+			if _, _, _, err := ssh.EnsureKey(name, ws.Email); err != nil {
+				return fmt.Errorf("failed to ensure SSH key for %q: %w", name, err)
+			}
+			ws.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+			target.SetWorkspace(name, ws)
+			newKeys = append(newKeys, name)
+		}
+		for _, id := range sortedIdentities(ws) {
+			identity := ws.Identities[id]
+			if fsutil.FileExists(identity.SSHKey) {
+				continue
+			}
+			defaultKey, err := ssh.KeyPath(identityKeyName(name, id))
+			if err != nil {
+				return err
+			}
+			if identity.SSHKey != defaultKey {
+				return fmt.Errorf("workspace %q: identity %q: ssh_key %s does not exist", name, id, identity.SSHKey)
+			}
+			if _, _, _, err := ssh.EnsureKey(identityKeyName(name, id), identity.Email); err != nil {
+				return fmt.Errorf("failed to ensure SSH key for %q: %w", config.IdentityName(name, id), err)
+			}
+			identity.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+			ws.Identities[id] = identity
+			target.SetWorkspace(name, ws)
+			newKeys = append(newKeys, config.IdentityName(name, id))
+		}
+		if current, exists := cfg.GetWorkspace(name); exists {
+			if err := removeDroppedSSHBlocks(name, current, ws); err != nil {
+				return err
+			}
+		}
+
+		artifacts, err := workspaceArtifacts(name, ws)
+		if err != nil {
+			return err
+		}
+		for _, a := range artifacts {
+			if a.InSync() {
+				continue
+			}
+			switch a.Kind {
+			case artifactSSHConfig:
+				if a.Workspace != name {
+					err = writeExtraSSHBlocks(name, ws)
+					break
+				}
+				err = ssh.UpsertSSHConfigBlock(name, ws.SSHHosts(time.Now()), ws.HostName, ws.SSHKey)
+			case artifactGitConfig:
+				err = createWorkspaceGitConfig(name, ws)
+			case artifactExcludes, artifactAttributes:
+				err = writeWorkspacePatternFiles(name, ws)
+			case artifactEnvrc:
+				err = writeWorkspaceEnvrc(name, ws)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write %s for %q: %w", a.Kind, name, err)
+			}
+		}
+		if ws.EnvFile != envFileEnvrc {
+			if err := removeWorkspaceEnvrc(name, ws); err != nil {
+				return err
+			}
+		}
+	}
+
+	if applyPrune {
+		for _, name := range extras {
+			if err := removeWorkspaceArtifacts(name); err != nil {
+				return err
+			}
+			if err := removeWorkspaceEnvrc(name, cfg.Workspaces[name]); err != nil {
+				return err
+			}
+			if err := removeExtraSSHBlocks(name, cfg.Workspaces[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := updateGlobalGitConfig(target); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+
+	if err := target.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if err := refreshGlobalGuard(target); err != nil {
+		return err
+	}
+
+	fmt.Printf(prompt.Text("✓ Applied %d change(s).\n"), actionable)
+	for _, name := range newKeys {
+		publicKey, err := ssh.GetPublicKey(desired[name].SSHKey + ".pub")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nNew public key for '%s' (add it to your %s account):\n%s\n", name, desired[name].HostName, publicKey)
+	}
+
+	return nil
+}
+
+// loadDefinitions reads a declarative workspace definitions file, rejecting unknown keys
+func loadDefinitions(path string) (*config.File, error) {
+	expanded, err := workspace.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read definitions file: %w", err)
+	}
+
+	var defs config.File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&defs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if len(defs.Workspaces) == 0 {
+		return nil, fmt.Errorf("%s defines no workspaces", path)
+	}
+
+	return &defs, nil
+}
+
+// changedFields lists the config.yaml keys that differ between two workspaces
+func changedFields(current, desired config.Workspace) []string {
+	var fields []string
+	check := func(name, a, b string) {
+		if a != b {
+			fields = append(fields, name)
+		}
+	}
+
+	check("email", current.Email, desired.Email)
+	check("primary_email", current.PrimaryEmail, desired.PrimaryEmail)
+	if current.EnforceNoreply != desired.EnforceNoreply {
+		fields = append(fields, "enforce_noreply")
+	}
+	check("provider", current.Provider, desired.Provider)
+	check("host_name", current.HostName, desired.HostName)
+	check("ssh_alias", current.SSHAlias, desired.SSHAlias)
+	check("ssh_key", current.SSHKey, desired.SSHKey)
+	check("root", current.Root, desired.Root)
+	check("signing", current.Signing, desired.Signing)
+	check("name", current.Name, desired.Name)
+	check("gpg_key", current.GPGKey, desired.GPGKey)
+	check("isolation", current.Isolation, desired.Isolation)
+	check("default_branch", current.DefaultBranch, desired.DefaultBranch)
+	check("pull_strategy", current.PullStrategy, desired.PullStrategy)
+	check("commit_template", current.CommitTemplate, desired.CommitTemplate)
+	check("max_key_age", current.MaxKeyAge, desired.MaxKeyAge)
+	check("worktree_layout", current.WorktreeLayout, desired.WorktreeLayout)
+	check("clone_layout", current.CloneLayout, desired.CloneLayout)
+	check("backup_dir", current.BackupDir, desired.BackupDir)
+	check("env_file", current.EnvFile, desired.EnvFile)
+	if !reflect.DeepEqual(current.Ignore, desired.Ignore) {
+		fields = append(fields, "ignore")
+	}
+	if !reflect.DeepEqual(current.Attributes, desired.Attributes) {
+		fields = append(fields, "attributes")
+	}
+	if !reflect.DeepEqual(current.Templates, desired.Templates) {
+		fields = append(fields, "templates")
+	}
+	if !reflect.DeepEqual(current.Hooks, desired.Hooks) {
+		fields = append(fields, "hooks")
+	}
+	if !reflect.DeepEqual(current.Clone, desired.Clone) {
+		fields = append(fields, "clone")
+	}
+	if !reflect.DeepEqual(current.Env, desired.Env) {
+		fields = append(fields, "env")
+	}
+	if !reflect.DeepEqual(current.Identities, desired.Identities) {
+		fields = append(fields, "identities")
+	}
+	if !reflect.DeepEqual(current.Hosts, desired.Hosts) {
+		fields = append(fields, "hosts")
+	}
+
+	return fields
+}
+
+// removeWorkspaceArtifacts removes a workspace's SSH block, gitconfig, and pattern files.
+// Keys are left on disk.
+func removeWorkspaceArtifacts(name string) error {
+	if err := ssh.RemoveSSHConfigBlock(name); err != nil {
+		return fmt.Errorf("failed to remove SSH config block for %q: %w", name, err)
+	}
+
+	gitConfigPath, err := workspace.GitConfigPath(name)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.Remove(gitConfigPath); err != nil {
+		return err
+	}
+
+	for _, path := range []func(string) (string, error){workspace.ExcludesFilePath, workspace.AttributesFilePath} {
+		filePath, err := path(name)
+		if err != nil {
+			return err
+		}
+		if err := fsutil.Remove(filePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printPlan(plan []planChange) {
+	if len(plan) == 0 {
+		return
+	}
+
+	fmt.Println("Plan:")
+	for _, c := range plan {
+		if c.Workspace == "" {
+			fmt.Printf("  %s %s\n", c.Action, c.Description)
+		} else {
+			fmt.Printf("  %s [%s] %s\n", c.Action, c.Workspace, c.Description)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+func TestRemoveWorkspaceArtifactsRecorded(t *testing.T) {
+	home := testHome(t)
+	gws := filepath.Join(home, ".gws")
+	writeTestFile(t, filepath.Join(gws, "gitconfig", "work"), "[user]\n")
+	writeTestFile(t, filepath.Join(gws, "work", "gitignore"), "*.log\n")
+	writeTestFile(t, filepath.Join(gws, "work", "gitattributes"), "*.bin binary\n")
+
+	var removed []string
+	fsutil.Recorder = func(path string, before, after []byte) {
+		if before != nil && after == nil {
+			removed = append(removed, strings.TrimPrefix(path, gws+string(filepath.Separator)))
+		}
+	}
+	defer func() { fsutil.Recorder = nil }()
+
+	if err := removeWorkspaceArtifacts("work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(removed)
+	expected := []string{filepath.Join("gitconfig", "work"), filepath.Join("work", "gitattributes"), filepath.Join("work", "gitignore")}
+	if strings.Join(removed, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v recorded, got %v", expected, removed)
+	}
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit [workspace...]",
+	Short: "Find past commits made with addresses a workspace forbids",
+	Long: `Scan the history of every repository under the workspace roots for
+commits authored or committed with an address the workspace forbids.
+
+A workspace forbids addresses and whole domains with forbidden_emails in
+config.yaml; the hooks then block new commits and pushes using them, and
+'gitws doctor' reports repositories configured with them:
+
+  workspaces:
+    work:
+      forbidden_emails:
+        - gmail.com
+        - me@personal.dev
+
+Every branch and tag is scanned. Commits found are already part of the
+history; fixing them means rewriting it, e.g. with git filter-repo
+--mailmap, and force pushing.
+
+Without arguments, every workspace with forbidden_emails is audited.
+Exits with 2 when a forbidden commit is found. --json prints the findings.
+
+Examples:
+  gitws audit
+  gitws audit work
+  gitws audit --json`,
+	RunE: runAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
+
+// auditFinding is a repository with forbidden commits, as --json prints it
+type auditFinding struct {
+	Repository string        `json:"repository"`
+	Workspace  string        `json:"workspace"`
+	Commits    []auditCommit `json:"commits"`
+}
+
+// auditCommit is a forbidden commit, as --json prints it
+type auditCommit struct {
+	SHA            string `json:"sha"`
+	AuthorEmail    string `json:"author_email"`
+	CommitterEmail string `json:"committer_email"`
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		for _, name := range cfg.ListWorkspaces() {
+			if len(cfg.Workspaces[name].ForbiddenEmails) > 0 {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no workspace sets forbidden_emails; nothing to audit")
+		}
+	}
+	sort.Strings(names)
+
+	findings := []auditFinding{}
+	scanned := 0
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+		if len(ws.ForbiddenEmails) == 0 {
+			if !jsonOutput {
+				fmt.Printf(prompt.Text("ℹ️  Workspace '%s' sets no forbidden_emails; skipped\n"), name)
+			}
+			continue
+		}
+
+		repos, err := workspaceRepos(cfg, name)
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			if r, err := config.LoadRepo(repo); err == nil && r.Unmanaged {
+				continue
+			}
+
+			finding, err := auditRepository(name, ws, repo)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s: %v\n"), repo, err)
+				continue
+			}
+			scanned++
+			if finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+	} else {
+		gws := guard.Workspace{}
+		for _, f := range findings {
+			gws.Name, gws.ForbiddenEmails = f.Workspace, cfg.Workspaces[f.Workspace].ForbiddenEmails
+			fmt.Printf(prompt.Text("❌ %s (workspace '%s'): %d forbidden commit(s)\n"), f.Repository, f.Workspace, len(f.Commits))
+			for _, c := range f.Commits {
+				commit := guard.Commit{SHA: c.SHA, AuthorEmail: c.AuthorEmail, CommitterEmail: c.CommitterEmail}
+				fmt.Printf("   %s\n", commit.Describe(gws))
+			}
+		}
+		if len(findings) == 0 {
+			fmt.Printf(prompt.Text("✓ No forbidden commits in %d repositories\n"), scanned)
+		} else {
+			fmt.Printf("\n%d of %d repositories have forbidden commits. Rewriting history (e.g. git filter-repo --mailmap) is the only fix.\n", len(findings), scanned)
+		}
+	}
+
+	if len(findings) > 0 {
+		return exitCode(cmd, ExitErrors)
+	}
+	return nil
+}
+
+// auditRepository scans every ref of repo, returning nil when no commit
+// uses a forbidden address
+func auditRepository(name string, ws config.Workspace, repo string) (*auditFinding, error) {
+	identities, err := git.CommitIdentities(repo, "--all")
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]guard.Commit, 0, len(identities))
+	for _, c := range identities {
+		commits = append(commits, guard.Commit{SHA: c.SHA, AuthorEmail: c.AuthorEmail, CommitterEmail: c.CommitterEmail})
+	}
+
+	forbidden := guard.ForbiddenCommits(guard.Workspace{Name: name, ForbiddenEmails: ws.ForbiddenEmails}, commits)
+	if len(forbidden) == 0 {
+		return nil, nil
+	}
+	finding := &auditFinding{Repository: repo, Workspace: name}
+	for _, c := range forbidden {
+		finding.Commits = append(finding.Commits, auditCommit{SHA: c.SHA, AuthorEmail: c.AuthorEmail, CommitterEmail: c.CommitterEmail})
+	}
+	return finding, nil
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/audit"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/policy"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/redact"
+	"github.com/gitworkspaces/gitws/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince string
+	auditPath  string
+)
+
+// auditLogCmd groups the audit log commands
+var auditLogCmd = &cobra.Command{
+	Use:   "audit-log",
+	Short: "Show and verify the log of changes gitws made",
+	Long: `Every file gitws changes is recorded in ~/.gws/audit.log: the file,
+SHA-256 digests of its contents before and after, the time, and the
+command line (with credentials redacted and email addresses hashed).
+The log is only ever appended to.
+
+Set audit_chain: true in config.yaml to hash-chain the entries, so
+'gitws audit-log verify' can tell when one was edited or removed.`,
+}
+
+var auditLogShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show recorded changes",
+	Long: `Show recorded changes, oldest first.
+
+Examples:
+  gitws audit-log show
+  gitws audit-log show --since 7d
+  gitws audit-log show --path .ssh/config
+  gitws audit-log show --json`,
+	Args: cobra.NoArgs,
+	RunE: runAuditLogShow,
+}
+
+var auditLogVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the hash chain of the audit log",
+	Long: `Check the hash chain of the audit log. It exits non-zero when a chained
+entry was edited or removed.
+
+Examples:
+  gitws audit-log verify`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runAuditLogVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(auditLogCmd)
+	auditLogCmd.AddCommand(auditLogShowCmd)
+	auditLogCmd.AddCommand(auditLogVerifyCmd)
+
+	auditLogShowCmd.Flags().StringVar(&auditSince, "since", "", "Only show changes from this period, in days (e.g. 30d)")
+	auditLogShowCmd.Flags().StringVar(&auditPath, "path", "", "Only show changes to files whose path contains this")
+}
+
+func runAuditLogShow(cmd *cobra.Command, args []string) error {
+	entries, err := audit.Read()
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if auditSince != "" {
+		days, err := parseDays(auditSince)
+		if err != nil {
+			return err
+		}
+		since = time.Now().AddDate(0, 0, -days+1).Truncate(24 * time.Hour)
+	}
+	var shown []audit.Entry
+	for _, e := range entries {
+		if e.Time.Before(since) || !strings.Contains(e.Path, auditPath) {
+			continue
+		}
+		shown = append(shown, e)
+	}
+
+	if jsonOutput {
+		if shown == nil {
+			shown = []audit.Entry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(shown); err != nil {
+			return fmt.Errorf("failed to encode audit log: %w", err)
+		}
+		return nil
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("Nothing recorded.")
+		return nil
+	}
+	for _, e := range shown {
+		fmt.Printf("%s  %s  %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), auditChange(e), e.Path)
+		fmt.Printf("    %s\n", e.Command)
+	}
+	return nil
+}
+
+// auditChange describes an entry's change by the short digests of the
+// contents before and after
+func auditChange(e audit.Entry) string {
+	short := func(digest string) string {
+		if digest == "" {
+			return "(none)  "
+		}
+		return digest[:8]
+	}
+	return short(e.Before) + prompt.Text(" → ") + short(e.After)
+}
+
+func runAuditLogVerify(cmd *cobra.Command, args []string) error {
+	entries, err := audit.Read()
+	if err != nil {
+		return err
+	}
+	chained, err := audit.Verify(entries)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(entries) == 0:
+		fmt.Println("Nothing recorded.")
+	case chained == 0:
+		fmt.Printf(prompt.Text("ℹ️  None of the %d entries are chained. Set audit_chain: true in config.yaml to chain new ones.\n"), len(entries))
+	default:
+		fmt.Printf(prompt.Text("✓ Hash chain intact: %d of %d entries chained\n"), chained, len(entries))
+	}
+	return nil
+}
+
+// startAuditLog records every file this gitws process changes in the
+// audit log. A failure to record is reported but does not stop the
+// command, which has already changed the file.
+func startAuditLog() {
+	chain := false
+	if cfg, err := config.Load(); err == nil {
+		chain = cfg.AuditChain
+	}
+	command := redact.Text(strings.Join(append([]string{"gitws"}, os.Args[1:]...), " "))
+	skipped := unauditedPaths()
+
+	fsutil.Recorder = func(path string, before, after []byte) {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if skipped[path] {
+			return
+		}
+		entry := audit.Entry{
+			Time:    time.Now().UTC(),
+			Command: command,
+			Path:    path,
+			Before:  audit.Digest(before),
+			After:   audit.Digest(after),
+		}
+		if err := audit.Append(entry, chain); err != nil {
+			fmt.Fprintf(os.Stderr, prompt.Text("⚠️  Failed to record the change to %s in the audit log: %v\n"), path, err)
+		}
+	}
+}
+
+// unauditedPaths are gitws's own bookkeeping files, which change on their
+// own and say nothing about the machine's configuration
+func unauditedPaths() map[string]bool {
+	skipped := make(map[string]bool)
+	if path, err := stats.Path(); err == nil {
+		skipped[path] = true
+	}
+	if dir, err := config.ConfigDir(); err == nil {
+		skipped[filepath.Join(dir, policy.CacheName)] = true
+	}
+	return skipped
+}
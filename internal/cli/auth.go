@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/gitworkspaces/gitws/internal/secrets"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authLoginProvider  string
+	authLoginClientID  string
+	authLoginWithToken bool
+	authLoginNoBrowser bool
+)
+
+// authCmd groups provider authentication commands
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage provider API tokens per workspace",
+}
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login <workspace>",
+	Short: "Store a provider API token for a workspace",
+	Long: `Store a provider API token for a workspace in the OS credential store.
+
+This command will:
+- Authorize in the browser with the OAuth device flow (GitHub, GitLab), or
+  read a pasted token from a hidden prompt, or from stdin when piped
+- Verify it with the provider and show which account it belongs to
+- Store it in the macOS Keychain, Windows Credential Manager, or libsecret
+
+The device flow needs the client ID of an OAuth application with device
+flow enabled, from --client-id or GWS_GITHUB_CLIENT_ID / GWS_GITLAB_CLIENT_ID.
+Without one, or with --with-token, a personal access token is read instead.
+
+Without an OS credential store the token is written to ~/.gws/secrets,
+encrypted with a passphrase (read from GWS_PASSPHRASE when set).
+
+GWS_TOKEN_<WORKSPACE> (e.g. GWS_TOKEN_WORK) overrides the stored token.
+
+Examples:
+  gitws auth login work
+  gitws auth login work --provider github --client-id Iv1.0123456789abcdef
+  op read op://work/github/token | gitws auth login work`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthLogin,
+}
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout <workspace>",
+	Short: "Remove the stored provider API token for a workspace",
+	Long: `Remove the stored provider API token for a workspace.
+
+Examples:
+  gitws auth logout work`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+
+	authLoginCmd.Flags().StringVar(&authLoginProvider, "provider", "", "Provider API for custom hosts (github, gitlab, bitbucket)")
+	authLoginCmd.Flags().StringVar(&authLoginClientID, "client-id", "", "OAuth application client ID for the device flow")
+	authLoginCmd.Flags().BoolVar(&authLoginWithToken, "with-token", false, "Read a personal access token instead of using the device flow")
+	authLoginCmd.Flags().BoolVar(&authLoginNoBrowser, "no-browser", false, "Print the verification URL instead of opening it")
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", workspaceName, workspaceName)
+	}
+
+	kind := workspaceProviderKind(ws)
+	if authLoginProvider != "" {
+		kind = authLoginProvider
+	}
+
+	var token string
+	if clientID := deviceFlowClientID(kind); clientID != "" && !authLoginWithToken {
+		token, err = deviceFlowLogin(cmd.Context(), kind, ws.HostName, clientID)
+	} else {
+		token, err = prompt.Secret(fmt.Sprintf("API token for %s (%s)", workspaceName, ws.HostName))
+	}
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no token provided")
+	}
+
+	// Verify the token when the provider API is known, so a token for
+	// the wrong account is caught now rather than on first use
+	account := "unverified"
+	if kind != "" {
+		client, err := provider.New(kind, ws.HostName, token)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := operationContext(cmd.Context(), opAPI)
+		account, err = client.CurrentUser(ctx)
+		err = operationError(ctx, opAPI, err)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+	if err := store.Set(workspaceName, token); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title: "✓ Logged in successfully",
+		Items: []prompt.SummaryItem{
+			{Label: "Workspace", Value: workspaceName, Icon: "📁"},
+			{Label: "Host", Value: ws.HostName, Icon: "🌐"},
+			{Label: "Account", Value: account, Icon: "👤"},
+			{Label: "Stored In", Value: store.Name(), Icon: "🔐"},
+		},
+		NextSteps: []string{
+			fmt.Sprintf("gitws repo create %s <owner/repo>", workspaceName),
+		},
+	})
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(workspaceName); err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			fmt.Printf("No token stored for workspace %s\n", workspaceName)
+			return nil
+		}
+		return fmt.Errorf("failed to remove token: %w", err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Removed token for workspace %s from %s\n"), workspaceName, store.Name())
+	return nil
+}
+
+// deviceFlowClientID returns the OAuth client ID for the device flow on
+// kind, or "" when the device flow is unavailable
+func deviceFlowClientID(kind string) string {
+	if kind != "github" && kind != "gitlab" {
+		return ""
+	}
+	if authLoginClientID != "" {
+		return authLoginClientID
+	}
+	return os.Getenv(fmt.Sprintf("GWS_%s_CLIENT_ID", strings.ToUpper(kind)))
+}
+
+// deviceFlowLogin runs the OAuth device flow and returns the access token
+func deviceFlowLogin(ctx context.Context, kind, hostName, clientID string) (string, error) {
+	flow, err := provider.NewDeviceFlow(kind, hostName, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	startCtx, cancel := operationContext(ctx, opAPI)
+	code, err := flow.Start(startCtx)
+	err = operationError(startCtx, opAPI, err)
+	cancel()
+	if err != nil {
+		return "", err
+	}
+
+	verifyURL := code.VerificationURI
+	if code.VerificationURIComplete != "" {
+		verifyURL = code.VerificationURIComplete
+	}
+
+	fmt.Printf("! One-time code: %s\n", code.UserCode)
+	if authLoginNoBrowser || openBrowser(verifyURL) != nil {
+		fmt.Printf("  Open %s and enter the code\n", verifyURL)
+	} else {
+		fmt.Printf("  Opened %s in your browser\n", verifyURL)
+	}
+	fmt.Println("  Waiting for authorization...")
+
+	// The code's expiry bounds the wait for the user
+	return flow.Poll(ctx, code)
+}
+
+// openBrowser opens url in the user's default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// openSecrets opens the secret store used for provider tokens
+func openSecrets() (secrets.Store, error) {
+	dir, err := workspace.SecretsDir()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Open(dir, func() (string, error) {
+		return prompt.Passphrase("Secrets passphrase")
+	}), nil
+}
+
+// tokenEnvVar returns the environment variable overriding a workspace token
+func tokenEnvVar(workspaceName string) string {
+	slug := regexp.MustCompile(`[^A-Z0-9]+`).ReplaceAllString(strings.ToUpper(workspaceName), "_")
+	return "GWS_TOKEN_" + slug
+}
+
+// workspaceToken returns the provider API token for a workspace, or ""
+// when none is configured
+func workspaceToken(workspaceName string) (string, error) {
+	if token := os.Getenv(tokenEnvVar(workspaceName)); token != "" {
+		return token, nil
+	}
+
+	store, err := openSecrets()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := store.Get(workspaceName)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/progress"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupDest string
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup <workspace>",
+	Short: "Keep bare mirrors of all workspace repositories",
+	Long: `Mirror every repository under a workspace root into a backup location.
+
+This command will:
+- Find the repositories under the workspace root
+- Create a bare mirror of each one's origin the first time
+- Fetch new and changed refs into existing mirrors, pruning deleted ones
+- Report repositories that could not be mirrored
+
+Mirrors are written to --dest, the workspace's backup_dir, or
+~/.gws/backups/<workspace>, keeping the <org>/<repo> layout of the root.
+Mirrors fetch through the workspace SSH alias, so they use its key.
+
+Examples:
+  gitws backup work
+  gitws backup work --dest /mnt/backup/work`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackup,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupCmd.Flags().StringVar(&backupDest, "dest", "", "Backup location (default: backup_dir, or ~/.gws/backups/<workspace>)")
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	dest, err := backupDir(workspaceName, ws)
+	if err != nil {
+		return err
+	}
+
+	repos, err := workspaceRepos(cfg, workspaceName)
+	if err != nil {
+		return err
+	}
+
+	var created, updated int
+	var failed []string
+	bar := progress.New(fmt.Sprintf("Backing up %s", workspaceName), len(repos))
+	for _, repo := range repos {
+		// Worktrees share their main repository's refs
+		if git.IsWorktree(repo) {
+			bar.Add(1)
+			continue
+		}
+		rel, err := filepath.Rel(ws.Root, repo)
+		if err != nil {
+			bar.Add(1)
+			continue
+		}
+
+		task := bar.Start(rel)
+		isNew, err := mirrorRepository(cmd.Context(), repo, filepath.Join(dest, rel)+".git", task.Update)
+		task.Done()
+		switch {
+		case err != nil:
+			bar.Printf(prompt.Text("❌ %s: %v\n"), rel, withGitHint(err, workspaceName, ws))
+			failed = append(failed, rel)
+		case isNew:
+			bar.Printf(prompt.Text("✓ %s: mirrored\n"), rel)
+			created++
+		default:
+			bar.Printf(prompt.Text("✓ %s: updated\n"), rel)
+			updated++
+		}
+	}
+	bar.Stop()
+
+	summary := prompt.SummaryData{
+		Title: i18n.T("✓ Backup of workspace '%s' complete", workspaceName),
+		Items: []prompt.SummaryItem{
+			{Label: "Backup Location", Value: dest, Icon: "💾"},
+			{Label: "New Mirrors", Value: strconv.Itoa(created), Icon: "➕"},
+			{Label: "Updated Mirrors", Value: strconv.Itoa(updated), Icon: "🔄"},
+			{Label: "Failed", Value: strconv.Itoa(len(failed)), Icon: "❌"},
+		},
+	}
+	if len(failed) > 0 {
+		summary.Title = fmt.Sprintf("⚠️ Backup of workspace '%s' incomplete", workspaceName)
+		for _, rel := range failed {
+			summary.NextSteps = append(summary.NextSteps, fmt.Sprintf("Check %s: gitws doctor %s", rel, filepath.Join(ws.Root, rel)))
+		}
+	}
+	if err := prompt.ShowSummary(summary); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d repositories could not be mirrored", len(failed), created+updated+len(failed))
+	}
+	return nil
+}
+
+// backupDir returns where a workspace's mirrors are kept
+func backupDir(workspaceName string, ws config.Workspace) (string, error) {
+	dir := backupDest
+	if dir == "" {
+		dir = ws.BackupDir
+	}
+	if dir == "" {
+		configDir, err := config.ConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "backups", workspaceName), nil
+	}
+	return workspace.ExpandPath(dir)
+}
+
+// mirrorRepository brings the mirror of repo's origin at mirrorPath up to
+// date, creating it when missing. isNew reports whether it was created.
+func mirrorRepository(ctx context.Context, repo, mirrorPath string, report func(received, total int64)) (isNew bool, err error) {
+	url, err := git.GetRemoteURL(repo)
+	if err != nil {
+		return false, fmt.Errorf("no origin remote to mirror")
+	}
+
+	if _, err := os.Stat(mirrorPath); err == nil {
+		// Follow the origin, e.g. after 'gitws rename' changed its alias
+		if err := git.SetRemoteURL(mirrorPath, url); err != nil {
+			return false, err
+		}
+		ctx, cancel := operationContext(ctx, opFetch)
+		defer cancel()
+		return false, operationError(ctx, opFetch, git.UpdateMirror(ctx, mirrorPath))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mirrorPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	fsutil.TrackCreated(mirrorPath)
+	ctx, cancel := operationContext(ctx, opClone)
+	defer cancel()
+	if err := git.CloneRepository(ctx, url, mirrorPath, git.CloneOptions{Mirror: true, Progress: report}); err != nil {
+		// git cleans up after itself unless it was killed
+		if ctx.Err() != nil {
+			os.RemoveAll(mirrorPath)
+		}
+		return false, operationError(ctx, opClone, err)
+	}
+	return true, nil
+}
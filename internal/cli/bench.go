@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchRepo string
+	benchRuns int
+)
+
+// benchRepos are small public repositories used when --repo is not given
+var benchRepos = map[string]string{
+	"github.com": "octocat/Hello-World",
+}
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench [workspace...]",
+	Short: "Measure fetch and clone speed through each workspace alias",
+	Long: `Measure fetch and clone speed through each workspace SSH alias, with SSH
+connection multiplexing (ControlMaster) off and on.
+
+This command will:
+- Run 'git ls-remote' (connection and ref advertisement) and a shallow bare
+  clone of a small test repository through the workspace alias
+- Repeat each measurement --runs times and report the median
+- Compare a fresh SSH connection per operation with a shared multiplexed one
+
+A failing workspace usually means its key is not added to the provider or a
+ProxyCommand in your SSH config is broken. On github.com the default test
+repository is octocat/Hello-World; other hosts need --repo.
+
+Examples:
+  gitws bench
+  gitws bench work --runs 5
+  gitws bench client --repo team/small-repo`,
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchRepo, "repo", "", "Test repository as org/repo or URL (default: a small public repository)")
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 3, "Runs per measurement")
+}
+
+// benchResult is the outcome of benchmarking one workspace in one mode
+type benchResult struct {
+	Workspace string
+	Multiplex bool
+	Fetch     time.Duration
+	Clone     time.Duration
+	Bytes     int64
+	Err       error
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no workspaces configured. Run 'gitws init' first")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gitws-bench-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The control sockets get a directory of their own under a short
+	// prefix: a socket path may only be about 100 bytes long, which the
+	// temp directory on macOS alone nearly uses up
+	socketDir, err := os.MkdirTemp(socketTempDir(), "gwsb-")
+	if err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(socketDir)
+
+	var results []benchResult
+	for i, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+
+		repo := benchRepo
+		if repo == "" {
+			repo = benchRepos[ws.HostName]
+		}
+		if repo == "" {
+			fmt.Printf(prompt.Text("⚠️  %s: no default test repository for %s, use --repo\n"), name, ws.HostName)
+			continue
+		}
+
+		_, _, sshURL, err := rewrite.RewriteURL(repo, ws.SSHAlias)
+		if err != nil {
+			return fmt.Errorf("failed to rewrite URL: %w", err)
+		}
+
+		// %C hashes the host name, not the alias, so workspaces on the same
+		// host need their own sockets to connect with their own keys
+		controlPath := filepath.Join(socketDir, fmt.Sprintf("%d-%%C", i))
+		fmt.Printf("Benchmarking %s (%s, %d runs)...\n", name, sshURL, benchRuns)
+		for _, multiplex := range []bool{false, true} {
+			result := benchWorkspace(cmd.Context(), ws.SSHAlias, sshURL, filepath.Join(tmpDir, name), controlPath, multiplex)
+			result.Workspace = name
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	printBenchResults(results)
+	return nil
+}
+
+// socketTempDir returns the directory to create control socket
+// directories in: /tmp where there is one, as it is short
+func socketTempDir() string {
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat("/tmp"); err == nil && info.IsDir() {
+			return "/tmp"
+		}
+	}
+	return os.TempDir()
+}
+
+// benchWorkspace clones into dir to measure one URL with multiplexing off
+// or on, sharing connections through controlPath
+func benchWorkspace(ctx context.Context, alias, sshURL, dir, controlPath string, multiplex bool) benchResult {
+	result := benchResult{Multiplex: multiplex}
+
+	// Only the SSH options under test are set; everything else, including
+	// the workspace key and any ProxyCommand, comes from the SSH config
+	sshCommand := "ssh -o ControlMaster=no -o ControlPath=none"
+	if multiplex {
+		sshCommand = fmt.Sprintf("ssh -o ControlMaster=auto -o ControlPath=%s -o ControlPersist=30", controlPath)
+	}
+	env := []string{"GIT_SSH_COMMAND=" + sshCommand, "GIT_TERMINAL_PROMPT=0"}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		result.Err = err
+		return result
+	}
+	if multiplex {
+		// Open the shared connection before timing, as a long-lived
+		// master would already be open in daily use
+		if err := git.RunWithEnv(ctx, env, "ls-remote", sshURL, "HEAD"); err != nil {
+			result.Err = err
+			return result
+		}
+		defer ssh.CloseControlMaster(alias, controlPath)
+	}
+
+	var fetches, clones []time.Duration
+	for i := 0; i < benchRuns; i++ {
+		start := time.Now()
+		if err := git.RunWithEnv(ctx, env, "ls-remote", sshURL, "HEAD"); err != nil {
+			result.Err = err
+			return result
+		}
+		fetches = append(fetches, time.Since(start))
+
+		dest := filepath.Join(dir, fmt.Sprintf("clone-%t-%d", multiplex, i))
+		start = time.Now()
+		if err := git.RunWithEnv(ctx, env, "clone", "--quiet", "--bare", "--depth", "1", sshURL, dest); err != nil {
+			result.Err = err
+			return result
+		}
+		clones = append(clones, time.Since(start))
+
+		result.Bytes = dirSize(dest)
+		os.RemoveAll(dest)
+	}
+
+	result.Fetch = median(fetches)
+	result.Clone = median(clones)
+	return result
+}
+
+func printBenchResults(results []benchResult) {
+	fmt.Println()
+	fmt.Printf("%-14s %-12s %10s %10s %12s\n", "Workspace", "Multiplexing", "Fetch", "Clone", "Throughput")
+	for _, r := range results {
+		mode := "off"
+		if r.Multiplex {
+			mode = "on"
+		}
+		if r.Err != nil {
+			msg, _, _ := strings.Cut(r.Err.Error(), "\n")
+			fmt.Printf(prompt.Text("%-14s %-12s ❌ %s\n"), r.Workspace, mode, msg)
+			continue
+		}
+		fmt.Printf("%-14s %-12s %10s %10s %12s\n", r.Workspace, mode,
+			r.Fetch.Round(time.Millisecond), r.Clone.Round(time.Millisecond), throughput(r.Bytes, r.Clone))
+	}
+
+	// Results come in off/on pairs per workspace
+	fmt.Println()
+	recommend := false
+	for i := 0; i+1 < len(results); i += 2 {
+		off, on := results[i], results[i+1]
+		if off.Err != nil || on.Err != nil || off.Fetch == 0 {
+			continue
+		}
+		saved := 100 - int(on.Fetch*100/off.Fetch)
+		if saved >= 20 {
+			fmt.Printf(prompt.Text("✓ %s: multiplexing cut connection time by %d%%\n"), off.Workspace, saved)
+			recommend = true
+		} else {
+			fmt.Printf(prompt.Text("ℹ️  %s: multiplexing made little difference (%d%%)\n"), off.Workspace, saved)
+		}
+	}
+
+	if recommend {
+		fmt.Println(`
+To enable multiplexing, add a second Host block for the alias in ~/.ssh/config,
+outside the gitws markers:
+
+  Host <alias>
+    ControlMaster auto
+    ControlPath ~/.ssh/cm-%C
+    ControlPersist 10m`)
+	}
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+func throughput(bytes int64, d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f KB/s", float64(bytes)/1024/d.Seconds())
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
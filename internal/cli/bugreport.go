@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/compat"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/redact"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	bugreportOutput string
+)
+
+// bugreportCmd represents the bugreport command
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Collect a redacted diagnostic bundle for an issue report",
+	Long: `Collect the information needed to investigate a gitws problem into a
+tarball you can attach to an issue.
+
+The bundle contains:
+- gitws, OS, git and OpenSSH versions
+- config.yaml, with email addresses hashed and env values and webhook URLs removed
+- the gws-managed blocks of ~/.ssh/config and ~/.gitconfig
+- the workspace gitconfig files
+- an offline doctor report, when run inside a repository
+
+Private and public key material, provider tokens and URL credentials are
+stripped from every file. Nothing is uploaded; review the bundle before
+attaching it.
+
+Examples:
+  gitws bugreport
+  gitws bugreport --output /tmp/gitws-report.tar.gz`,
+	Args: cobra.NoArgs,
+	RunE: runBugreport,
+}
+
+func init() {
+	rootCmd.AddCommand(bugreportCmd)
+
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Bundle path (default: gitws-bugreport-<time>.tar.gz)")
+}
+
+// bugreportFile is one file in the bundle
+type bugreportFile struct {
+	name    string
+	content string
+}
+
+func runBugreport(cmd *cobra.Command, args []string) error {
+	output := bugreportOutput
+	if output == "" {
+		output = fmt.Sprintf("gitws-bugreport-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	files := []bugreportFile{
+		{"environment.txt", bugreportEnvironment()},
+		{"config.yaml", sanitizedConfig(cfg)},
+		{"ssh_config.txt", bugreportSSHBlocks(cfg)},
+		{"gitconfig.txt", bugreportGitConfig(cfg)},
+		{"doctor.txt", bugreportDoctor()},
+	}
+
+	if err := writeBugreport(output, files); err != nil {
+		return err
+	}
+
+	fmt.Printf(prompt.Text("✓ Wrote %s\n"), output)
+	for _, f := range files {
+		fmt.Printf("   %s\n", f.name)
+	}
+	fmt.Println("\nKeys, tokens and credentials were removed and email addresses hashed.")
+	fmt.Println("Review the bundle before attaching it to an issue.")
+	return nil
+}
+
+func bugreportEnvironment() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gitws:   %s\n", rootCmd.Version)
+	fmt.Fprintf(&b, "os:      %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "go:      %s\n", runtime.Version())
+
+	if path, version, err := git.Locate(); err == nil {
+		fmt.Fprintf(&b, "git:     %s (%s)\n", version, path)
+	} else {
+		fmt.Fprintf(&b, "git:     %v\n", err)
+	}
+	if version, err := compat.Version(compat.ToolOpenSSH); err == nil {
+		fmt.Fprintf(&b, "openssh: %s\n", version)
+	} else {
+		fmt.Fprintf(&b, "openssh: %v\n", err)
+	}
+	if fs, network := fsutil.NetworkFilesystem(mustConfigDir()); network {
+		fmt.Fprintf(&b, "state:   on %s\n", fs)
+	}
+
+	b.WriteString("\nfeatures:\n")
+	for _, f := range compat.Features {
+		status := "ok"
+		if err := compat.Require(f); err != nil {
+			status = err.Error()
+		}
+		fmt.Fprintf(&b, "  %-28s %s\n", f.Name, status)
+	}
+	return b.String()
+}
+
+// mustConfigDir returns the state directory, or "" when it cannot be resolved
+func mustConfigDir() string {
+	dir, _ := config.ConfigDir()
+	return dir
+}
+
+// sanitizedConfig returns config.yaml with identities hashed and anything
+// that could hold a secret removed
+func sanitizedConfig(cfg *config.File) string {
+	clean := config.File{
+		Workspaces:  make(map[string]config.Workspace),
+		GlobalGuard: cfg.GlobalGuard,
+		Stats:       cfg.Stats,
+		GitPath:     cfg.GitPath,
+	}
+	for name, ws := range cfg.Workspaces {
+		ws.Email = redact.Email(ws.Email)
+		if len(ws.Env) > 0 {
+			env := make(map[string]string, len(ws.Env))
+			for key := range ws.Env {
+				env[key] = redact.Placeholder
+			}
+			ws.Env = env
+		}
+		clean.Workspaces[name] = ws
+	}
+	for _, sink := range cfg.Notify {
+		// Webhook URLs carry their secret in the path
+		sink.URL = redact.Placeholder
+		clean.Notify = append(clean.Notify, sink)
+	}
+
+	data, err := yaml.Marshal(clean)
+	if err != nil {
+		return fmt.Sprintf("failed to marshal config: %v\n", err)
+	}
+	return redact.Text(string(data))
+}
+
+func bugreportSSHBlocks(cfg *config.File) string {
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		block, found, err := ssh.ReadManagedBlock(name)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "# %s: %v\n\n", name, err)
+		case !found:
+			fmt.Fprintf(&b, "# %s: no managed block\n\n", name)
+		default:
+			fmt.Fprintf(&b, "%s\n%s\n%s\n\n", workspace.StartMarker(name), block, workspace.EndMarker(name))
+		}
+	}
+	return redact.Text(b.String())
+}
+
+func bugreportGitConfig(cfg *config.File) string {
+	var b strings.Builder
+
+	if path, err := globalGitConfigPath(); err == nil {
+		data, _ := os.ReadFile(path)
+		if block, found := fsutil.ExtractBetweenMarkers(string(data), workspace.IncludeIfStartMarker(), workspace.IncludeIfEndMarker()); found {
+			fmt.Fprintf(&b, "# ~/.gitconfig (managed block)\n%s\n\n", block)
+		} else {
+			b.WriteString("# ~/.gitconfig: no managed includeIf block\n\n")
+		}
+	}
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+	for _, name := range names {
+		path, err := workspace.GitConfigPath(name)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(&b, "# %s: %v\n\n", path, err)
+			continue
+		}
+		fmt.Fprintf(&b, "# %s\n%s\n", path, data)
+	}
+	return redact.Text(b.String())
+}
+
+// bugreportDoctor runs the offline doctor checks on the current repository
+func bugreportDoctor() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Sprintf("failed to get current directory: %v\n", err)
+	}
+	gitRoot, err := git.FindGitRoot(cwd)
+	if err != nil {
+		return "not run inside a git repository\n"
+	}
+
+	issues := runAllChecks(context.Background(), gitRoot, true)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "repository: %s\n\n", gitRoot)
+	if len(issues) == 0 {
+		b.WriteString("no issues found\n")
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "[%s] %s\n", issue.Type, issue.Message)
+		if fix := issue.FixText(); fix != "" {
+			fmt.Fprintf(&b, "   Fix: %s\n", fix)
+		}
+	}
+	return redact.Text(b.String())
+}
+
+// writeBugreport writes files into a gzip-compressed tarball readable only
+// by the user
+func writeBugreport(path string, files []bugreportFile) error {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    "gitws-bugreport/" + f.name,
+			Mode:    0600,
+			Size:    int64(len(f.content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/cache"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var cacheOffline bool
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the offline cache of workspace metadata",
+	Long: `Manage the cache in ~/.gws/cache.json of what gitws last learned about
+each workspace: the repositories under its root, the provider account and
+repositories its API token reaches, and the outcome of the last
+'gitws doctor' in each repository.
+
+'gitws list' and shell completions (e.g. of repositories for
+'gitws clone <workspace> <TAB>') read the cache, so they work offline.
+Listings say how old the data is and flag it after a week.
+
+Examples:
+  gitws cache refresh
+  gitws cache refresh work --offline
+  gitws cache clear`,
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh [workspace...]",
+	Short: "Rescan workspace roots and refetch provider metadata",
+	Long: `Rescan the roots of the given workspaces (default: all) for
+repositories and, for workspaces with an API token (see 'gitws auth
+login'), fetch the token's account and the repositories it can push to.
+--offline only rescans. Data that cannot be refreshed is kept.
+
+Examples:
+  gitws cache refresh
+  gitws cache refresh work personal
+  gitws cache refresh --offline`,
+	RunE: runCacheRefresh,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		fmt.Println(prompt.Text("✓ Cache cleared"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cacheRefreshCmd.Flags().BoolVar(&cacheOffline, "offline", false, "Only rescan workspace roots; do not contact the provider")
+}
+
+func runCacheRefresh(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, exists := cfg.GetWorkspace(name); !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+	}
+
+	data, err := cache.Load()
+	if err != nil {
+		return err
+	}
+	data.Prune(cfg.ListWorkspaces())
+
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		entry := data.Workspace(name)
+
+		if ws.Root != "" {
+			repos, err := cacheRepositories(cfg, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s: %v\n"), name, err)
+			} else {
+				entry.SetRepos(repos, time.Now())
+				fmt.Printf(prompt.Text("✓ %s: %d repositories under %s\n"), name, len(repos), ws.Root)
+			}
+		}
+
+		if !cacheOffline {
+			refreshProviderCache(cmd.Context(), name, ws, entry)
+		}
+	}
+
+	return data.Save()
+}
+
+// cacheRepositories returns the repositories under a workspace root that
+// belong to it
+func cacheRepositories(cfg *config.File, name string) ([]cache.Repo, error) {
+	paths, err := workspaceRepos(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []cache.Repo{}
+	for _, path := range paths {
+		repo := cache.Repo{Path: path}
+		if remoteURL, err := git.GetRemoteURL(path); err == nil {
+			repo.Name, _ = remoteFullName(remoteURL)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// refreshProviderCache stores the account a workspace's API token
+// authenticates as and the repositories it can push to. Without a token
+// it does nothing; on failure the cached data is kept.
+func refreshProviderCache(ctx context.Context, name string, ws config.Workspace, entry *cache.Workspace) {
+	kind := workspaceProviderKind(ws)
+	if kind == "" {
+		fmt.Printf(prompt.Text("ℹ️  %s: cannot tell which API %s speaks; provider metadata skipped\n"), name, ws.HostName)
+		return
+	}
+	token, err := workspaceToken(name)
+	if err != nil || token == "" {
+		fmt.Printf(prompt.Text("ℹ️  %s: no API token; provider metadata skipped\n"), name)
+		return
+	}
+	client, err := provider.New(kind, ws.HostName, token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s: %v\n"), name, err)
+		return
+	}
+
+	account, err := callAPI(ctx, client.CurrentUser)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s: %v; keeping provider metadata from %s\n"), name, err, cache.Age(entry.Fetched, time.Now()))
+		return
+	}
+	repos, err := callAPI(ctx, client.ListRepos)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s: %v; keeping provider metadata from %s\n"), name, err, cache.Age(entry.Fetched, time.Now()))
+		return
+	}
+
+	entry.Account = account
+	entry.Remote = make([]string, 0, len(repos))
+	for _, r := range repos {
+		entry.Remote = append(entry.Remote, r.FullName)
+	}
+	sort.Strings(entry.Remote)
+	entry.Fetched = time.Now()
+	fmt.Printf(prompt.Text("✓ %s: %s can push to %d repositories on %s\n"), name, account, len(repos), ws.HostName)
+}
+
+// recordDoctorResult stores the outcome of a doctor run in the cache.
+// The cache only helps listings, so failures are ignored.
+func recordDoctorResult(gitRoot string, issues []prompt.Issue) {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	name := workspaceForRepo(cfg, gitRoot)
+	if name == "" {
+		return
+	}
+	if abs, err := filepath.Abs(gitRoot); err == nil {
+		gitRoot = abs
+	}
+	data, err := cache.Load()
+	if err != nil {
+		return
+	}
+
+	result := cache.Doctor{At: time.Now()}
+	for _, issue := range issues {
+		switch issue.Type {
+		case "error":
+			result.Errors++
+		case "info":
+		default:
+			result.Warnings++
+		}
+	}
+	data.Workspace(name).RecordDoctor(gitRoot, result)
+	_ = data.Save()
+}
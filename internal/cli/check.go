@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/email"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkCI            bool
+	checkCommits       int
+	checkRange         string
+	checkEmails        []string
+	checkRequireSigned bool
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Check that recent commits use the expected identity and signing",
+	Long: `Check that the repository's recent commits were authored with the
+expected identity and signed when signing is required, and that the
+repository's own config does not override them. Meant for pipelines, as a
+required status check, where the hooks cannot run.
+
+What is expected comes from the flags and, when config.yaml has a
+workspace for the repository, from the workspace:
+
+- --email: addresses or domains (acme.com covers its subdomains) commits
+  may be authored with. The workspace adds the email of the identity the
+  repository uses
+- --require-signed: every commit must carry a signature. Workspaces that
+  sign commits require it too. Signatures are not verified, as the
+  signers' keys are not at hand in a pipeline
+- The addresses the workspace forbids with forbidden_emails or
+  enforce_noreply, as author or committer
+
+Without --range the last --commits commits of HEAD are checked. Shallow
+clones only have what was fetched: check out with full history, or pass
+the range of the change, e.g. origin/main..HEAD. In shared repositories,
+check only the commits of the change with --email set to the team's
+domain.
+
+--ci runs without a terminal and prints the results as JSON, in the form
+'gitws doctor --json' uses, unless --format is given. Exit codes: 0 when
+everything matches, 1 for warnings, 2 for errors, 3 outside a repository.
+Under GitHub Actions the issues are also written to stderr as workflow
+annotations, so they show up on the run and its pull request.
+
+Examples:
+  gitws check
+  gitws check --ci --email acme.com --require-signed
+  gitws check --ci --range origin/main..HEAD --email me@acme.com
+  gitws check --commits 100 --format markdown`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().BoolVar(&checkCI, "ci", false, "Run for a pipeline: print JSON results unless --format is given")
+	checkCmd.Flags().IntVar(&checkCommits, "commits", 20, "Number of recent commits to check; 0 checks all")
+	checkCmd.Flags().StringVar(&checkRange, "range", "", "Commits to check instead of the recent ones of HEAD, e.g. origin/main..HEAD")
+	checkCmd.Flags().StringSliceVar(&checkEmails, "email", nil, "Address or domain commits may be authored with (repeatable)")
+	checkCmd.Flags().BoolVar(&checkRequireSigned, "require-signed", false, "Require every commit to carry a signature")
+}
+
+// checkExpectations is what the commits of a repository are checked against
+type checkExpectations struct {
+	emails        []string
+	requireSigned bool
+	forbidden     guard.Workspace
+	workspace     string
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	if checkCI && !cmd.Flags().Changed("format") && !cmd.Flags().Changed("json") {
+		if err := prompt.SetFormat(prompt.FormatJSON); err != nil {
+			return err
+		}
+		jsonOutput = true
+	}
+
+	var repoPath string
+	var err error
+	if len(args) > 0 {
+		repoPath = args[0]
+	} else {
+		repoPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	gitRoot, err := git.FindGitRoot(repoPath)
+	if err != nil {
+		return notRepoError(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	expect := checkExpectationsFor(cfg, gitRoot)
+
+	var issues []prompt.Issue
+	if _, err := config.ReadRepo(gitRoot); err != nil {
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-CHECK-006",
+			Type:    "error",
+			Message: err.Error(),
+			Fix:     fmt.Sprintf("Correct %s", config.RepoFileName),
+			Path:    gitRoot,
+			File:    filepath.Join(gitRoot, config.RepoFileName),
+		})
+	}
+	if len(expect.emails) == 0 && !expect.requireSigned && !expect.forbidden.ChecksEmails() {
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-CHECK-001",
+			Type:    "error",
+			Message: "Nothing to check against: no workspace in config.yaml covers the repository",
+			Fix:     "Pass --email with the expected address or domain, or --require-signed",
+			Path:    gitRoot,
+		})
+	}
+
+	commitIssues, checked, err := checkRecentCommits(gitRoot, expect)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, commitIssues...)
+	issues = append(issues, checkRepositoryConfig(gitRoot, expect)...)
+	annotateIssues(issues)
+
+	if jsonOutput {
+		if err := writeDoctorJSON(issues, nil); err != nil {
+			return err
+		}
+	} else {
+		if err := prompt.ShowDoctorReport(issues); err != nil {
+			return err
+		}
+		if !prompt.Structured() {
+			fmt.Printf(prompt.Text("ℹ️  %d commit(s) checked\n"), checked)
+		}
+	}
+
+	if code := issueExitCode(issues); code != ExitOK {
+		return exitCode(cmd, code)
+	}
+	return nil
+}
+
+// checkExpectationsFor combines the flags with the workspace config.yaml
+// has for the repository, if any
+func checkExpectationsFor(cfg *config.File, gitRoot string) checkExpectations {
+	expect := checkExpectations{emails: checkEmails, requireSigned: checkRequireSigned}
+
+	name := workspaceForRepo(cfg, gitRoot)
+	if name == "" {
+		return expect
+	}
+	ws := repoIdentity(cfg, name, gitRoot)
+	expect.workspace = name
+	expect.emails = append(append([]string{}, expect.emails...), ws.Email)
+	expect.requireSigned = expect.requireSigned || (ws.Signing != "" && ws.Signing != "none")
+	expect.forbidden = guard.Workspace{Name: name, ForbiddenEmails: ws.ForbiddenEmails, RequireNoreply: ws.EnforceNoreply}
+	return expect
+}
+
+// checkRecentCommits checks the selected commits, returning the issues
+// found and how many commits were checked
+func checkRecentCommits(gitRoot string, expect checkExpectations) ([]prompt.Issue, int, error) {
+	revs := []string{"HEAD"}
+	if checkRange != "" {
+		revs = []string{checkRange}
+	}
+	if checkCommits > 0 {
+		revs = append(revs, fmt.Sprintf("--max-count=%d", checkCommits))
+	}
+
+	commits, err := git.CommitIdentities(gitRoot, revs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var signed map[string]bool
+	if expect.requireSigned {
+		if signed, err = git.SignedCommits(gitRoot, revs...); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var issues []prompt.Issue
+	add := func(code, message, fix string) {
+		issues = append(issues, prompt.Issue{
+			Code:      code,
+			Type:      "error",
+			Message:   message,
+			Fix:       fix,
+			Workspace: expect.workspace,
+			Path:      gitRoot,
+		})
+	}
+	rewrite := "Rewrite the commit with the expected identity, e.g. git rebase -i --exec 'git commit --amend --no-edit --reset-author'"
+	for _, c := range commits {
+		short := c.SHA
+		if len(short) > 12 {
+			short = short[:12]
+		}
+
+		commit := guard.Commit{SHA: c.SHA, AuthorEmail: c.AuthorEmail, CommitterEmail: c.CommitterEmail}
+		_, expected := email.Matches(c.AuthorEmail, expect.emails)
+		switch {
+		case len(guard.ForbiddenCommits(expect.forbidden, []guard.Commit{commit})) > 0:
+			add("GWS-CHECK-003", commit.Describe(expect.forbidden), rewrite)
+		case len(expect.emails) > 0 && !expected:
+			add("GWS-CHECK-002",
+				fmt.Sprintf("%s authored by %s, expected %s", short, c.AuthorEmail, strings.Join(expect.emails, " or ")),
+				rewrite)
+		}
+
+		if expect.requireSigned && !signed[c.SHA] {
+			add("GWS-CHECK-004",
+				fmt.Sprintf("%s is not signed", short),
+				"Sign the commit, e.g. git rebase -i --exec 'git commit --amend --no-edit -S'")
+		}
+	}
+	return issues, len(commits), nil
+}
+
+// checkRepositoryConfig reports the repository's own settings that defeat
+// the expectations. A pipeline checkout has none, so there is nothing to
+// report there.
+func checkRepositoryConfig(gitRoot string, expect checkExpectations) []prompt.Issue {
+	var issues []prompt.Issue
+	if addr, err := git.GetLocalConfig(gitRoot, "user.email"); err == nil && addr != "" {
+		if _, forbidden := guard.Forbids(expect.forbidden, addr); forbidden {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-CHECK-005",
+				Type:      "error",
+				Message:   fmt.Sprintf("The repository sets user.email to %s, which workspace '%s' forbids", addr, expect.workspace),
+				Fix:       "git config --unset user.email",
+				Workspace: expect.workspace,
+				Path:      gitRoot,
+			})
+		} else if _, ok := email.Matches(addr, expect.emails); len(expect.emails) > 0 && !ok {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-CHECK-005",
+				Type:      "error",
+				Message:   fmt.Sprintf("The repository sets user.email to %s, expected %s", addr, strings.Join(expect.emails, " or ")),
+				Fix:       "git config --unset user.email",
+				Workspace: expect.workspace,
+				Path:      gitRoot,
+			})
+		}
+	}
+	if expect.requireSigned {
+		if sign, err := git.GetLocalConfig(gitRoot, "commit.gpgsign"); err == nil && sign == "false" {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-CHECK-007",
+				Type:      "warning",
+				Message:   "The repository sets commit.gpgsign to false, so new commits will not be signed",
+				Fix:       "git config --unset commit.gpgsign",
+				Workspace: expect.workspace,
+				Path:      gitRoot,
+			})
+		}
+	}
+	return issues
+}
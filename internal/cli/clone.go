@@ -1,24 +1,40 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
 	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/layout"
+	"github.com/gitworkspaces/gitws/internal/progress"
 	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
 	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cloneBranch string
+	cloneBranch            string
+	cloneDepth             int
+	cloneFilter            string
+	cloneSparse            []string
+	cloneRecurseSubmodules bool
+	cloneSaveDefaults      bool
+	cloneMirror            bool
+	clonePath              string
+	cloneUpstream          string
+	cloneNoUpstream        bool
 )
 
 // cloneCmd represents the clone command
 var cloneCmd = &cobra.Command{
-	Use:   "clone <workspace> <url-or-org/repo>",
+	Use:   "clone [workspace[:identity]] <url-or-org/repo>",
 	Short: "Clone a repository into a workspace",
 	Long: `Clone a repository using workspace-specific SSH configuration.
 
@@ -27,11 +43,53 @@ This command will:
 - Clone into the workspace root directory
 - Set up proper Git configuration for the repository
 
+For large repositories, --depth, --filter and --sparse limit what is
+downloaded and checked out. --save-defaults stores the given options in
+the workspace's clone settings, which later clones use unless a flag
+overrides them (e.g. --depth 0 for full history).
+
+Append :<identity> to the workspace to clone as one of its additional
+identities (see 'gitws identity'); the repository then uses that
+identity's alias, key and email.
+
+Without a workspace, it is the one serving the URL's host; when several
+do, config.yaml's org map decides, e.g.
+
+  orgs:
+    myemployer/*: work
+    myuser/*: personal
+
+The repository goes to <root>/<org>/<repo>, or wherever the workspace's
+clone_layout puts it, e.g. {root}/{host}/{org}/{repo} like ghq or a flat
+{root}/{repo}. adopt and the repository scans follow the same layout.
+--path clones somewhere else.
+
+When that path is taken by another repository, e.g. one of the same
+name in another org under a flat layout, clone suggests free paths to
+pick from, or asks for one; without a terminal it fails with the
+suggestions for --path.
+
+A fork gets a second remote, upstream, for the repository it was forked
+from, through the same alias: name it with --upstream, or let clone ask
+the provider API when the workspace has a token (see 'gitws auth').
+remote.pushDefault is set to origin and the cloned branch tracks
+upstream, so pulls follow the original while pushes go to the fork.
+
+--mirror makes a bare mirror at that path plus .git instead, for
+backups; 'gitws backup' keeps mirrors of every workspace repository.
+
 Examples:
   gitws clone work microsoft/vscode
   gitws clone personal myorg/myrepo --branch main
-  gitws clone work https://github.com/microsoft/vscode.git`,
-	Args: cobra.ExactArgs(2),
+  gitws clone work https://github.com/microsoft/vscode.git
+  gitws clone work:bot acme/deploy-scripts
+  gitws clone myemployer/payments
+  gitws clone work acme/monorepo --filter blob:none --sparse services/api,libs
+  gitws clone work acme/app --depth 1 --recurse-submodules --save-defaults
+  gitws clone work acme/app --mirror
+  gitws clone work other-org/app --path ~/code/work/other-app
+  gitws clone work myfork/app --upstream acme/app`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: runClone,
 }
 
@@ -39,11 +97,51 @@ func init() {
 	rootCmd.AddCommand(cloneCmd)
 
 	cloneCmd.Flags().StringVarP(&cloneBranch, "branch", "b", "", "Branch to clone")
+	cloneCmd.Flags().IntVar(&cloneDepth, "depth", 0, "Clone only the last N commits (0 for full history)")
+	cloneCmd.Flags().StringVar(&cloneFilter, "filter", "", "Partial clone filter, e.g. blob:none (\"none\" to disable a saved default)")
+	cloneCmd.Flags().StringSliceVar(&cloneSparse, "sparse", nil, "Check out only these directories (comma-separated)")
+	cloneCmd.Flags().BoolVar(&cloneRecurseSubmodules, "recurse-submodules", false, "Clone submodules too")
+	cloneCmd.Flags().BoolVar(&cloneSaveDefaults, "save-defaults", false, "Save the clone options as workspace defaults")
+	cloneCmd.Flags().BoolVar(&cloneMirror, "mirror", false, "Make a bare mirror of all refs, e.g. for backups")
+	cloneCmd.Flags().StringVar(&clonePath, "path", "", "Clone into this directory instead of the clone layout path")
+	cloneCmd.Flags().StringVar(&cloneUpstream, "upstream", "", "Add the repository this one is a fork of (ORG/REPO) as the upstream remote")
+	cloneCmd.Flags().BoolVar(&cloneNoUpstream, "no-upstream", false, "Do not ask the provider whether the repository is a fork")
+
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "branch")
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "depth")
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "sparse")
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "recurse-submodules")
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "save-defaults")
+	cloneCmd.MarkFlagsMutuallyExclusive("mirror", "upstream")
+	cloneCmd.MarkFlagsMutuallyExclusive("upstream", "no-upstream")
+}
+
+// cloneWorkspace infers the workspace to clone urlOrRepo into: the only
+// one serving its host, or the one the org map assigns it to. ORG/REPO
+// shorthand names no host, so every workspace is a candidate.
+func cloneWorkspace(cfg *config.File, urlOrRepo string) (string, error) {
+	candidates := cfg.ListWorkspaces()
+	if host, err := rewrite.ExtractHost(urlOrRepo); err == nil && host != "" {
+		candidates = hostWorkspaces(cfg, host)
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return "", fmt.Errorf("no workspace serves %s; name the workspace: gitws clone <workspace> %s", urlOrRepo, urlOrRepo)
+	case len(candidates) == 1:
+		return candidates[0], nil
+	}
+	if name := orgWorkspace(cfg, urlOrRepo, candidates); name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("%s could belong to workspaces %s; name one, or map its org in config.yaml's orgs", urlOrRepo, strings.Join(candidates, ", "))
 }
 
 func runClone(cmd *cobra.Command, args []string) error {
-	workspaceName := args[0]
-	urlOrRepo := args[1]
+	target, urlOrRepo := "", args[0]
+	if len(args) == 2 {
+		target, urlOrRepo = args[0], args[1]
+	}
 
 	// Load workspace config
 	cfg, err := config.Load()
@@ -51,50 +149,105 @@ func runClone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if target == "" {
+		target, err = cloneWorkspace(cfg, urlOrRepo)
+		if err != nil {
+			return err
+		}
+		fmt.Printf(prompt.Text("ℹ️  Cloning into workspace '%s'\n"), target)
+	}
+	workspaceName, identity := splitIdentity(target)
+
 	ws, exists := cfg.GetWorkspace(workspaceName)
 	if !exists {
 		return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", workspaceName, workspaceName)
 	}
-
-	// Rewrite URL
-	org, repo, sshURL, err := rewrite.RewriteURL(urlOrRepo, ws.SSHAlias)
+	ws, err = ws.ForIdentity(identity)
 	if err != nil {
-		return fmt.Errorf("failed to rewrite URL: %w", err)
+		return fmt.Errorf("workspace %q: %w. Add it with 'gitws identity add %s %s'", workspaceName, err, workspaceName, identity)
 	}
 
-	// Build destination path
-	destPath := filepath.Join(ws.Root, org, repo)
+	defaults := cloneDefaultsFromFlags(cmd, ws.Clone)
+	if cloneSaveDefaults {
+		// Reload under the lock; clones in parallel may save at once
+		err := config.Update(func(f *config.File) error {
+			current, exists := f.GetWorkspace(workspaceName)
+			if !exists {
+				return fmt.Errorf("workspace %q not found", workspaceName)
+			}
+			current.Clone = defaults
+			f.SetWorkspace(workspaceName, current)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf(prompt.Text("✓ Saved clone defaults for workspace %s\n"), workspaceName)
+	}
 
-	// Ensure parent directory exists
-	parentDir := filepath.Dir(destPath)
-	if err := os.MkdirAll(parentDir, 0755); err != nil {
-		return fmt.Errorf("failed to create parent directory: %w", err)
+	opts := git.CloneOptions{Branch: cloneBranch, Mirror: cloneMirror}
+	if cloneMirror {
+		// A mirror copies everything; only an explicit filter applies
+		opts.Filter = cloneFilter
+	} else if defaults != nil {
+		opts.Depth = defaults.Depth
+		opts.Filter = defaults.Filter
+		opts.Sparse = defaults.Sparse
+		opts.RecurseSubmodules = defaults.RecurseSubmodules
 	}
 
-	// Check if destination already exists
-	if _, err := os.Stat(destPath); err == nil {
-		return fmt.Errorf("destination %s already exists", destPath)
+	destPath := ""
+	if clonePath != "" {
+		if destPath, err = workspace.ExpandPath(clonePath); err != nil {
+			return err
+		}
+		if destPath, err = filepath.Abs(destPath); err != nil {
+			return fmt.Errorf("failed to resolve path: %w", err)
+		}
 	}
 
-	// Clone repository
-	if err := git.CloneRepository(sshURL, destPath, cloneBranch); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+	org, repo, remoteURL, destPath, err := cloneIntoWorkspace(cmd.Context(), workspaceName, ws, urlOrRepo, destPath, opts)
+	if err != nil {
+		return withGitHint(err, workspaceName, ws)
 	}
 
-	// Set up repository configuration
-	if err := setupRepositoryConfig(destPath, ws); err != nil {
-		return fmt.Errorf("failed to setup repository config: %w", err)
+	if cloneMirror {
+		return prompt.ShowSummary(prompt.SummaryData{
+			Title: "✓ Repository mirrored successfully",
+			Items: []prompt.SummaryItem{
+				{Label: "Workspace", Value: target, Icon: "📁"},
+				{Label: "Repository", Value: fmt.Sprintf("%s/%s", org, repo), Icon: "📦"},
+				{Label: "Mirror", Value: destPath, Icon: "📍"},
+				{Label: "Remote URL", Value: remoteURL, Icon: "🔗"},
+			},
+			NextSteps: []string{
+				fmt.Sprintf("Update it later: git -C %s remote update --prune", destPath),
+				fmt.Sprintf("Or mirror every repository: gitws backup %s", workspaceName),
+			},
+		})
+	}
+
+	upstream := cloneUpstream
+	if upstream == "" && !cloneNoUpstream {
+		upstream = forkParent(cmd.Context(), workspaceName, ws, org+"/"+repo)
+	}
+	var upstreamURL string
+	if upstream != "" {
+		if upstreamURL, err = addUpstreamRemote(cmd.Context(), destPath, ws, upstream, cloneBranch); err != nil {
+			return withGitHint(err, workspaceName, ws)
+		}
 	}
 
 	// Show summary
 	summary := prompt.SummaryData{
 		Title: "✓ Repository cloned successfully",
 		Items: []prompt.SummaryItem{
-			{Label: "Workspace", Value: workspaceName, Icon: "📁"},
+			{Label: "Workspace", Value: target, Icon: "📁"},
 			{Label: "Repository", Value: fmt.Sprintf("%s/%s", org, repo), Icon: "📦"},
 			{Label: "Destination", Value: destPath, Icon: "📍"},
-			{Label: "SSH URL", Value: sshURL, Icon: "🔗"},
+			{Label: "Remote URL", Value: remoteURL, Icon: "🔗"},
 			{Label: "Branch", Value: getBranchDisplay(cloneBranch), Icon: "🌿"},
+			{Label: "Clone Options", Value: getCloneOptionsDisplay(opts), Icon: "⚙️"},
 		},
 		NextSteps: []string{
 			fmt.Sprintf("cd %s", destPath),
@@ -102,10 +255,247 @@ func runClone(cmd *cobra.Command, args []string) error {
 			"Start working with your isolated Git identity!",
 		},
 	}
+	if upstreamURL != "" {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Upstream URL", Value: upstreamURL, Icon: "🍴"})
+	}
 
 	return prompt.ShowSummary(summary)
 }
 
+// cloneDefaultsFromFlags returns the workspace clone defaults with the
+// options given on the command line applied over them, or nil when none
+// are set
+func cloneDefaultsFromFlags(cmd *cobra.Command, saved *config.CloneDefaults) *config.CloneDefaults {
+	var d config.CloneDefaults
+	if saved != nil {
+		d = *saved
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("depth") {
+		d.Depth = cloneDepth
+	}
+	if flags.Changed("filter") {
+		d.Filter = cloneFilter
+		if cloneFilter == "none" {
+			d.Filter = ""
+		}
+	}
+	if flags.Changed("sparse") {
+		d.Sparse = cloneSparse
+	}
+	if flags.Changed("recurse-submodules") {
+		d.RecurseSubmodules = cloneRecurseSubmodules
+	}
+
+	if d.Depth == 0 && d.Filter == "" && len(d.Sparse) == 0 && !d.RecurseSubmodules {
+		return nil
+	}
+	return &d
+}
+
+// cloneIntoWorkspace clones urlOrRepo through the workspace alias, or
+// over HTTPS with the workspace token, into dest or, when that is empty,
+// the path the workspace clone layout gives it, and applies the
+// workspace identity locally
+func cloneIntoWorkspace(ctx context.Context, workspaceName string, ws config.Workspace, urlOrRepo, dest string, opts git.CloneOptions) (org, repo, remoteURL, destPath string, err error) {
+	// Rewrite URL
+	// A URL on one of the workspace's extra hosts keeps that host
+	org, repo, remoteURL, err = workspaceRemote(ws, urlOrRepo)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to rewrite URL: %w", err)
+	}
+
+	// Build destination path
+	destPath = dest
+	explicit := dest != ""
+	if !explicit {
+		destPath = workspaceRepoPath(ws, urlOrRepo, org, repo)
+		if opts.Mirror {
+			destPath += ".git"
+		}
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		if destPath, err = resolveCollision(ws, urlOrRepo, org, repo, destPath, explicit); err != nil {
+			return "", "", "", "", err
+		}
+	}
+
+	// Ensure parent directory exists
+	parentDir := filepath.Dir(destPath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return "", "", "", "", fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	// Submodules on the same host need the workspace key too, and git
+	// does not read the workspace gitconfig with its token helper before
+	// the repository exists
+	if opts.RecurseSubmodules || ws.Transport == workspace.TransportHTTPS {
+		opts.Env = git.IdentityEnv(os.Environ(), workspaceIdentity(workspaceName, ws))
+	}
+
+	// Clone repository. An interrupt before the identity is set removes it.
+	fsutil.TrackCreated(destPath)
+	ctx, cancel := operationContext(ctx, opClone)
+	defer cancel()
+	bar := progress.New("Cloning", 1)
+	task := bar.Start(org + "/" + repo)
+	opts.Progress = task.Update
+	err = git.CloneRepository(ctx, remoteURL, destPath, opts)
+	task.Done()
+	bar.Stop()
+	if err != nil {
+		// git cleans up after itself unless it was killed
+		if ctx.Err() != nil {
+			os.RemoveAll(destPath)
+		}
+		return "", "", "", "", operationError(ctx, opClone, err)
+	}
+
+	// A mirror has no working tree to commit from
+	if opts.Mirror {
+		return org, repo, remoteURL, destPath, nil
+	}
+
+	// Set up repository configuration
+	if err := setupRepositoryConfig(destPath, ws); err != nil {
+		return "", "", "", "", fmt.Errorf("failed to setup repository config: %w", err)
+	}
+	// Outside the root the workspace gitconfig with its token helper
+	// does not apply
+	if ws.Transport == workspace.TransportHTTPS && (ws.Root == "" || !isWithin(destPath, ws.Root)) {
+		if err := setLocalCredentialConfig(destPath, workspaceName, ws); err != nil {
+			return "", "", "", "", err
+		}
+	}
+
+	return org, repo, remoteURL, destPath, nil
+}
+
+// forkParent asks the provider which repository fullName was forked
+// from. It returns "" when it is not a fork, or when the workspace has
+// no API token or the provider cannot tell; clones work without it.
+func forkParent(ctx context.Context, workspaceName string, ws config.Workspace, fullName string) string {
+	kind := workspaceProviderKind(ws)
+	if kind == "" {
+		return ""
+	}
+	token, err := workspaceToken(workspaceName)
+	if err != nil || token == "" {
+		return ""
+	}
+	client, err := provider.New(kind, ws.HostName, token)
+	if err != nil {
+		return ""
+	}
+
+	apiCtx, cancel := operationContext(ctx, opAPI)
+	found, err := client.GetRepo(apiCtx, fullName)
+	err = operationError(apiCtx, opAPI, err)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  Could not tell whether %s is a fork: %v\n"), fullName, err)
+		return ""
+	}
+	return found.Parent
+}
+
+// addUpstreamRemote adds upstream (a URL or ORG/REPO) as the upstream
+// remote of the fork at repoPath, through the workspace alias, and has
+// pushes go to origin. Once upstream is fetched, branch (or origin's
+// default branch) tracks it. It returns the upstream remote URL.
+func addUpstreamRemote(ctx context.Context, repoPath string, ws config.Workspace, upstream, branch string) (string, error) {
+	_, _, upstreamURL, err := workspaceRemote(ws, upstream)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite upstream URL: %w", err)
+	}
+	if err := git.AddRemote(repoPath, "upstream", upstreamURL); err != nil {
+		return "", err
+	}
+	if err := git.SetLocalConfig(repoPath, "remote.pushDefault", "origin"); err != nil {
+		return "", err
+	}
+
+	fetchCtx, cancel := operationContext(ctx, opClone)
+	err = operationError(fetchCtx, opClone, git.Fetch(fetchCtx, repoPath, "upstream"))
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %v; branches keep tracking origin\n"), err)
+		return upstreamURL, nil
+	}
+
+	if branch == "" {
+		branch = git.GetRemoteHead(repoPath)
+	}
+	if branch != "" && git.RefExists(repoPath, "refs/remotes/upstream/"+branch) {
+		if err := git.SetLocalConfig(repoPath, "branch."+branch+".remote", "upstream"); err != nil {
+			return "", err
+		}
+	}
+	return upstreamURL, nil
+}
+
+// resolveCollision picks another destination when destPath exists. A
+// clone of the same repository is reported as such; otherwise free
+// paths are suggested to choose from, or another is asked for.
+func resolveCollision(ws config.Workspace, input, org, repo, destPath string, explicit bool) (string, error) {
+	if existing, err := git.GetRemoteURL(destPath); err == nil {
+		if o, r, _, err := rewrite.RewriteURL(existing, ""); err == nil && strings.EqualFold(o, org) && strings.EqualFold(r, repo) {
+			return "", fmt.Errorf("%s/%s is already cloned at %s", org, repo, destPath)
+		}
+	}
+	if explicit {
+		return "", fmt.Errorf("destination %s already exists", destPath)
+	}
+
+	taken := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	v := layout.Vars{Root: ws.Root, Host: rewrite.PickHost(input, ws.HostAliases(), ws.HostName), Org: org, Repo: repo}
+	suggestions := layout.Alternatives(ws.CloneLayout, v, taken)
+
+	options := append(append([]string{}, suggestions...), "Another path")
+	fmt.Printf(prompt.Text("⚠️  %s already exists\n"), destPath)
+	choice, err := prompt.Choose("Where should "+org+"/"+repo+" go?", options)
+	if err != nil {
+		var msg strings.Builder
+		fmt.Fprintf(&msg, "destination %s already exists", destPath)
+		if len(suggestions) > 0 {
+			msg.WriteString("; clone elsewhere with --path, e.g.")
+			for _, s := range suggestions {
+				fmt.Fprintf(&msg, "\n  --path %s", s)
+			}
+		}
+		return "", fmt.Errorf("%s", msg.String())
+	}
+	if choice < len(suggestions) {
+		return suggestions[choice], nil
+	}
+
+	path, err := prompt.Input("Destination path")
+	if err != nil {
+		return "", err
+	}
+	if path, err = workspace.ExpandPath(path); err != nil {
+		return "", err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if taken(path) {
+		return "", fmt.Errorf("destination %s already exists", path)
+	}
+	return path, nil
+}
+
+// workspaceRepoPath returns where ws keeps the repository org/repo of
+// input (a URL or ORG/REPO), following its clone layout
+func workspaceRepoPath(ws config.Workspace, input, org, repo string) string {
+	host := rewrite.PickHost(input, ws.HostAliases(), ws.HostName)
+	return layout.Expand(ws.CloneLayout, layout.Vars{Root: ws.Root, Host: host, Org: org, Repo: repo})
+}
+
 func setupRepositoryConfig(repoPath string, ws config.Workspace) error {
 	// Set user name and email
 	if err := git.SetLocalConfig(repoPath, "user.name", ws.Name); err != nil {
@@ -116,6 +506,14 @@ func setupRepositoryConfig(repoPath string, ws config.Workspace) error {
 		return fmt.Errorf("failed to set user.email: %w", err)
 	}
 
+	// Set the commit template locally so it survives the repository
+	// moving out of the workspace root
+	if ws.CommitTemplate != "" {
+		if err := git.SetLocalConfig(repoPath, "commit.template", ws.CommitTemplate); err != nil {
+			return fmt.Errorf("failed to set commit.template: %w", err)
+		}
+	}
+
 	// Set up signing if configured
 	switch ws.Signing {
 	case "ssh":
@@ -148,3 +546,23 @@ func getBranchDisplay(branch string) string {
 	}
 	return branch
 }
+
+func getCloneOptionsDisplay(opts git.CloneOptions) string {
+	var parts []string
+	if opts.Depth > 0 {
+		parts = append(parts, fmt.Sprintf("depth %d", opts.Depth))
+	}
+	if opts.Filter != "" {
+		parts = append(parts, "filter "+opts.Filter)
+	}
+	if len(opts.Sparse) > 0 {
+		parts = append(parts, "sparse "+strings.Join(opts.Sparse, ", "))
+	}
+	if opts.RecurseSubmodules {
+		parts = append(parts, "submodules")
+	}
+	if len(parts) == 0 {
+		return "full clone"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/cache"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	completionNoDesc bool
+	completionDir    string
+)
+
+// completionShells generates the completion script of each supported shell
+var completionShells = map[string]func(w io.Writer, desc bool) error{
+	"bash": func(w io.Writer, desc bool) error { return rootCmd.GenBashCompletionV2(w, desc) },
+	"zsh": func(w io.Writer, desc bool) error {
+		if desc {
+			return rootCmd.GenZshCompletion(w)
+		}
+		return rootCmd.GenZshCompletionNoDesc(w)
+	},
+	"fish": func(w io.Writer, desc bool) error { return rootCmd.GenFishCompletion(w, desc) },
+	"powershell": func(w io.Writer, desc bool) error {
+		if desc {
+			return rootCmd.GenPowerShellCompletionWithDesc(w)
+		}
+		return rootCmd.GenPowerShellCompletion(w)
+	},
+}
+
+// completionCmd replaces cobra's default completion command, adding install
+var completionCmd = &cobra.Command{
+	Use:   "completion <shell>",
+	Short: "Generate or install shell completion scripts",
+	Long: `Generate the completion script of a shell on stdout, or install it where
+the shell loads completions from.
+
+Examples:
+  gitws completion install
+  gitws completion install zsh
+  gitws completion install bash --dir /usr/share/bash-completion/completions
+  gitws completion fish > ~/.config/fish/completions/gitws.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: completionShellNames(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		generate, ok := completionShells[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell %q (supported: %s)", args[0], strings.Join(completionShellNames(), ", "))
+		}
+		return generate(os.Stdout, !completionNoDesc)
+	},
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install [shell]",
+	Short: "Install the completion script for a shell",
+	Long: `Install the completion script where the shell finds it. The shell
+defaults to the one in $SHELL.
+
+This command will:
+- bash: write $XDG_DATA_HOME/bash-completion/completions/gitws, which
+  bash-completion loads on demand
+- zsh: write ~/.zfunc/_gitws; add ~/.zfunc to fpath before compinit
+- fish: write ~/.config/fish/completions/gitws.fish
+- powershell: write gitws.ps1 in the gitws state directory; dot-source it
+  from your $PROFILE
+
+Packagers can pass --dir to install into a system directory instead.
+
+Examples:
+  gitws completion install
+  gitws completion install fish
+  gitws completion install zsh --dir /usr/share/zsh/site-functions`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: completionShellNames(),
+	RunE:      runCompletionInstall,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+	completionCmd.AddCommand(completionInstallCmd)
+
+	completionCmd.PersistentFlags().BoolVar(&completionNoDesc, "no-descriptions", false, "Leave command descriptions out of completions")
+	completionInstallCmd.Flags().StringVar(&completionDir, "dir", "", "Install into this directory instead of the shell's per-user one")
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) error {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	} else {
+		shell = detectShell()
+		if shell == "" {
+			return fmt.Errorf("cannot tell your shell from $SHELL; pass one of %s", strings.Join(completionShellNames(), ", "))
+		}
+	}
+	generate, ok := completionShells[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(completionShellNames(), ", "))
+	}
+
+	path, hint, err := completionPath(shell, completionDir)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := generate(&buf, !completionNoDesc); err != nil {
+		return fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create completion directory: %w", err)
+	}
+	if err := fsutil.AtomicWrite(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Installed %s completion: %s\n"), shell, path)
+	if hint != "" && completionDir == "" {
+		fmt.Printf("   %s\n", hint)
+	}
+	fmt.Println("   Start a new shell to use it.")
+	return nil
+}
+
+// completionPath returns where the completion script of shell is
+// installed, under dir when given, and what the user still has to do for
+// the shell to load it
+func completionPath(shell, dir string) (path, hint string, err error) {
+	names := map[string]string{
+		"bash":       "gitws",
+		"zsh":        "_gitws",
+		"fish":       "gitws.fish",
+		"powershell": "gitws.ps1",
+	}
+	if dir != "" {
+		return filepath.Join(dir, names[shell]), "", nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(dataHome, "bash-completion", "completions", names[shell]), "", nil
+	case "zsh":
+		return filepath.Join(home, ".zfunc", names[shell]),
+			"Add 'fpath=(~/.zfunc $fpath)' before 'compinit' in ~/.zshrc if it is not there yet.", nil
+	case "fish":
+		return filepath.Join(configHome, "fish", "completions", names[shell]), "", nil
+	default:
+		configDir, err := config.ConfigDir()
+		if err != nil {
+			return "", "", err
+		}
+		path := filepath.Join(configDir, names[shell])
+		return path, fmt.Sprintf("Add '. %s' to your PowerShell $PROFILE if it is not there yet.", path), nil
+	}
+}
+
+// detectShell returns the completion shell of the user's login shell, or ""
+func detectShell() string {
+	if runtime.GOOS == "windows" && os.Getenv("SHELL") == "" {
+		return "powershell"
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Getenv("SHELL")), ".exe")
+	switch name {
+	case "pwsh":
+		return "powershell"
+	case "bash", "zsh", "fish", "powershell":
+		return name
+	}
+	return ""
+}
+
+// completionShellNames returns the supported shells, sorted
+func completionShellNames() []string {
+	names := make([]string, 0, len(completionShells))
+	for name := range completionShells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// workspaceArgCommands take a workspace as their first argument
+var workspaceArgCommands = []*cobra.Command{
+	authLoginCmd, authLogoutCmd, backupCmd, envCmd, execCmd, hooksListCmd,
+	identityAddCmd, identityListCmd, identityRemoveCmd, keyCommentShowCmd,
+	keyCommentEditCmd, moveCmd, newCmd, renameCmd, repoCreateCmd, rotateCmd,
+}
+
+// workspacesArgCommands take any number of workspaces
+var workspacesArgCommands = []*cobra.Command{
+	auditCmd, benchCmd, cacheRefreshCmd, diffCmd, hooksUpdateCmd, listCmd, watchCmd,
+}
+
+func init() {
+	for _, cmd := range workspaceArgCommands {
+		cmd.ValidArgsFunction = completeFirstWorkspace
+	}
+	for _, cmd := range workspacesArgCommands {
+		cmd.ValidArgsFunction = completeWorkspaces
+	}
+	cloneCmd.ValidArgsFunction = completeClone
+	worktreeAddCmd.ValidArgsFunction = completeWorktreeAdd
+}
+
+// completeWorkspaces completes the workspaces not named yet. Like every
+// completion it reads only config.yaml and the cache, so it works
+// offline and stays fast.
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	named := make(map[string]bool, len(args))
+	for _, arg := range args {
+		named[arg] = true
+	}
+	var names []string
+	for _, name := range cfg.ListWorkspaces() {
+		if !named[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFirstWorkspace completes a workspace as the first argument
+func completeFirstWorkspace(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completeWorkspaces(cmd, nil, toComplete)
+}
+
+// completeClone completes the workspace, then the repositories the
+// workspace's account can push to as last cached. As the first argument
+// the repositories of every workspace are offered too, since clone
+// infers the workspace from them.
+func completeClone(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		names, directive := completeWorkspaces(cmd, nil, toComplete)
+		return append(names, cachedRepoNames("", true)...), directive
+	case 1:
+		workspaceName, _ := splitIdentity(args[0])
+		return cachedRepoNames(workspaceName, true), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktreeAdd completes the workspace, then its cloned
+// repositories
+func completeWorktreeAdd(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeWorkspaces(cmd, nil, toComplete)
+	case 1:
+		return cachedRepoNames(args[0], false), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedRepoNames returns the ORG/REPO names the cache knows for a
+// workspace, or for all of them when it is "": those cloned under its
+// root, or with remote those its account can push to
+func cachedRepoNames(workspaceName string, remote bool) []string {
+	data, err := cache.Load()
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for name, entry := range data.Workspaces {
+		if workspaceName != "" && name != workspaceName {
+			continue
+		}
+		candidates := entry.Remote
+		if !remote {
+			candidates = nil
+			for _, r := range entry.Repos {
+				candidates = append(candidates, r.Name)
+			}
+		}
+		for _, c := range candidates {
+			if c != "" && !seen[c] {
+				seen[c] = true
+				names = append(names, c)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	credentialWorkspace string
+)
+
+// credentialHelperCmd represents the credential-helper command
+var credentialHelperCmd = &cobra.Command{
+	Use:   "credential-helper <get|store|erase>",
+	Short: "Serve workspace HTTPS tokens to git",
+	Long: `Implement git's credential helper protocol, answering HTTPS
+authentication requests with the token 'gitws auth login' stored for the
+workspace.
+
+'gitws init --transport https' configures this helper in the workspace
+gitconfig, scoped to the workspace's hosts; you do not normally run it
+yourself. The workspace is the one given with --workspace, or else the one
+owning the current directory or serving the requested host.
+
+A token is only ever sent over https, and only to a host the workspace
+uses. store does nothing: tokens come from 'gitws auth login'. When git
+reports a token was rejected, erase says how to replace it but keeps it.
+
+Examples:
+  git config credential.https://github.com.helper '/usr/local/bin/gitws credential-helper --workspace work'
+  printf 'protocol=https\nhost=github.com\n\n' | gitws credential-helper --workspace work get`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCredentialHelper,
+}
+
+func init() {
+	rootCmd.AddCommand(credentialHelperCmd)
+
+	credentialHelperCmd.Flags().StringVar(&credentialWorkspace, "workspace", "", "Workspace whose token to serve (default: detected from the directory and host)")
+}
+
+func runCredentialHelper(cmd *cobra.Command, args []string) error {
+	operation := args[0]
+	// git may grow operations; helpers ignore the ones they do not know
+	if operation != "get" && operation != "erase" {
+		return nil
+	}
+
+	request, err := git.ReadCredential(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if request.Protocol != "https" || request.Host == "" {
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	host := request.HostName()
+	workspaceName := credentialWorkspaceFor(cfg, host)
+	if workspaceName == "" {
+		return nil
+	}
+	ws := cfg.Workspaces[workspaceName]
+
+	token, err := workspaceToken(workspaceName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  gitws: no token for workspace %s: %v\n"), workspaceName, err)
+		return nil
+	}
+
+	if operation == "erase" {
+		if token != "" && request.Password == token {
+			fmt.Fprintf(os.Stderr, prompt.Text("⚠️  gitws: %s rejected the token of workspace %s; replace it with 'gitws auth login %s'\n"), host, workspaceName, workspaceName)
+		}
+		return nil
+	}
+
+	if token == "" {
+		fmt.Fprintf(os.Stderr, prompt.Text("ℹ️  gitws: no token stored for workspace %s; run 'gitws auth login %s'\n"), workspaceName, workspaceName)
+		return nil
+	}
+
+	answer := git.Credential{
+		Protocol: request.Protocol,
+		Host:     request.Host,
+		Username: request.Username,
+		Password: token,
+	}
+	if answer.Username == "" {
+		answer.Username = credentialUsername(ws, host)
+	}
+	_, err = answer.WriteTo(os.Stdout)
+	return err
+}
+
+// credentialWorkspaceFor returns the workspace whose token may answer a
+// request for host: --workspace, the workspace owning the current
+// directory, or the only workspace serving host. "" when the workspace
+// does not use host, so its token never goes to another server.
+func credentialWorkspaceFor(cfg *config.File, host string) string {
+	serves := func(name string) bool {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return false
+		}
+		for _, h := range ws.HostNames() {
+			if strings.EqualFold(h, host) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if credentialWorkspace != "" {
+		if serves(credentialWorkspace) {
+			return credentialWorkspace
+		}
+		return ""
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if name := workspaceForDir(cfg, cwd); serves(name) {
+			return name
+		}
+	}
+	var candidates []string
+	for _, name := range cfg.ListWorkspaces() {
+		if serves(name) {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// credentialUsername returns the user name a token of ws authenticates as
+// on host
+func credentialUsername(ws config.Workspace, host string) string {
+	kind := provider.Detect(host)
+	if strings.EqualFold(host, ws.HostName) {
+		kind = workspaceProviderKind(ws)
+	}
+	return provider.TokenUsername(kind)
+}
+
+// credentialConfig returns the config pairs the workspace gitconfig of an
+// https workspace holds, for commands run with the identity in their
+// environment; nil for SSH workspaces. The empty helper drops helpers
+// configured before, so a global keychain cannot answer with another
+// account's credentials.
+func credentialConfig(workspaceName string, ws config.Workspace) [][2]string {
+	if ws.Transport != workspace.TransportHTTPS {
+		return nil
+	}
+	helper, err := credentialHelper(workspaceName)
+	if err != nil {
+		return nil
+	}
+	var pairs [][2]string
+	for _, host := range ws.HostNames() {
+		section := "credential.https://" + host
+		pairs = append(pairs,
+			[2]string{section + ".helper", ""},
+			[2]string{section + ".helper", helper},
+			[2]string{section + ".username", credentialUsername(ws, host)},
+		)
+	}
+	return pairs
+}
+
+// setLocalCredentialConfig writes the credential settings of an https
+// workspace to a repository that does not include its gitconfig
+func setLocalCredentialConfig(gitRoot, workspaceName string, ws config.Workspace) error {
+	helper, err := credentialHelper(workspaceName)
+	if err != nil {
+		return err
+	}
+	for _, host := range ws.HostNames() {
+		section := "credential.https://" + host
+		if err := git.SetLocalConfigAll(gitRoot, section+".helper", "", helper); err != nil {
+			return err
+		}
+		if err := git.SetLocalConfig(gitRoot, section+".username", credentialUsername(ws, host)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/textdiff"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [workspace...]",
+	Short: "Show drift between config.yaml and managed files",
+	Long: `Compare the state derived from ~/.gws/config.yaml with what is on disk.
+
+This command checks:
+- Managed workspace blocks in ~/.ssh/config
+- The managed includeIf block in ~/.gitconfig
+- Workspace gitconfigs under ~/.gws/gitconfig
+- Workspace SSH keys
+
+Differences are shown as unified diffs from the actual to the desired
+content. The exit code is 1 when drift is found, so it can gate CI on a
+dotfiles repository. Run 'gitws init <workspace> --force' or 'gitws apply' to repair.
+
+Examples:
+  gitws diff
+  gitws diff work
+  gitws diff --json`,
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+// driftReport is the JSON form of a single artifact comparison
+type driftReport struct {
+	Kind      string `json:"kind"`
+	Workspace string `json:"workspace,omitempty"`
+	Path      string `json:"path"`
+	InSync    bool   `json:"in_sync"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+
+	var artifacts []managedArtifact
+	var reports []driftReport
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+
+		wsArtifacts, err := workspaceArtifacts(name, ws)
+		if err != nil {
+			return fmt.Errorf("workspace %q: %w", name, err)
+		}
+		artifacts = append(artifacts, wsArtifacts...)
+
+		keyPresent := fsutil.FileExists(ws.SSHKey)
+		report := driftReport{Kind: "ssh-key", Workspace: name, Path: ws.SSHKey, InSync: keyPresent}
+		if !keyPresent {
+			report.Diff = "missing"
+		}
+		reports = append(reports, report)
+
+		for _, id := range sortedIdentities(ws) {
+			keyPath := ws.Identities[id].SSHKey
+			report := driftReport{Kind: "ssh-key", Workspace: config.IdentityName(name, id), Path: keyPath, InSync: fsutil.FileExists(keyPath)}
+			if !report.InSync {
+				report.Diff = "missing"
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	// The includeIf block covers every workspace, so only check it when
+	// diffing the whole configuration
+	if len(args) == 0 {
+		includeIf, err := includeIfArtifact(cfg)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, includeIf)
+	}
+
+	for _, a := range artifacts {
+		report := driftReport{Kind: a.Kind, Workspace: a.Workspace, Path: a.Path, InSync: a.InSync()}
+		if !report.InSync {
+			report.Diff = artifactDiff(a)
+		}
+		reports = append(reports, report)
+	}
+
+	drift := 0
+	for _, r := range reports {
+		if !r.InSync {
+			drift++
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+	} else {
+		for _, r := range reports {
+			if r.InSync {
+				continue
+			}
+			if r.Kind == "ssh-key" {
+				fmt.Printf(prompt.Text("❌ [%s] SSH key missing: %s\n\n"), r.Workspace, r.Path)
+				continue
+			}
+			if err := prompt.ShowDiff(r.Diff); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+
+		if drift == 0 {
+			fmt.Println(prompt.Text("✓ No drift. Managed files match config.yaml."))
+		} else {
+			fmt.Printf(prompt.Text("⚠️  %d managed item(s) drifted from config.yaml.\n"), drift)
+			fmt.Println("Run 'gitws init <workspace> --force' (or 'gitws apply -f <file>') to restore them.")
+		}
+	}
+
+	if drift > 0 {
+		return exitCode(cmd, 1)
+	}
+
+	return nil
+}
+
+// artifactDiff renders a unified diff from the on-disk content to the desired content
+func artifactDiff(a managedArtifact) string {
+	label := a.Path
+	if a.Kind == artifactSSHConfig {
+		label = fmt.Sprintf("%s [gws %s]", a.Path, a.Workspace)
+	} else if a.Kind == artifactIncludeIf {
+		label = fmt.Sprintf("%s [gws includeIf]", a.Path)
+	}
+
+	actualName := label + " (actual)"
+	if !a.Present {
+		actualName = label + " (missing)"
+	}
+
+	actual := normalizeForDiff(a.Actual)
+	desired := normalizeForDiff(a.Desired)
+	return textdiff.Unified(actualName, label+" (desired)", actual, desired, 3)
+}
+
+func normalizeForDiff(s string) string {
+	if s == "" || s[len(s)-1] == '\n' {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var (
+	docsDir string
+)
+
+// docsCmd groups documentation commands
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate gitws documentation",
+}
+
+var docsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate man pages for every command",
+	Long: `Generate a man page for gitws and each of its commands, e.g.
+gitws.1 and gitws-clone.1, for packagers to install under
+/usr/share/man/man1.
+
+The date in the pages is taken from $SOURCE_DATE_EPOCH when it is set,
+so the output is reproducible.
+
+Examples:
+  gitws docs generate
+  gitws docs generate --dir dist/man`,
+	Args: cobra.NoArgs,
+	RunE: runDocsGenerate,
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsGenerateCmd)
+
+	docsGenerateCmd.Flags().StringVar(&docsDir, "dir", "man", "Directory to write the man pages to")
+}
+
+func runDocsGenerate(cmd *cobra.Command, args []string) error {
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", docsDir, err)
+	}
+
+	// The date comes from SOURCE_DATE_EPOCH when it is set
+	header := &doc.GenManHeader{
+		Section: "1",
+		Source:  strings.TrimSpace("gitws " + cmd.Root().Version),
+		Manual:  "gitws Manual",
+	}
+	if err := doc.GenManTree(cmd.Root(), header, docsDir); err != nil {
+		return fmt.Errorf("failed to generate man pages: %w", err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Wrote %d man pages to %s\n"), countDocumented(cmd.Root()), docsDir)
+	return nil
+}
+
+// countDocumented counts the commands GenManTree writes a page for
+func countDocumented(c *cobra.Command) int {
+	count := 1
+	for _, child := range c.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			count += countDocumented(child)
+		}
+	}
+	return count
+}
@@ -1,15 +1,34 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/gitworkspaces/gitws/internal/compat"
 	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/email"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
 	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/gpg"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/health"
+	"github.com/gitworkspaces/gitws/internal/i18n"
 	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
 	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // doctorCmd represents the doctor command
@@ -22,18 +41,87 @@ This command checks for:
 - Identity mismatches
 - Remote URL issues
 - Signing configuration problems
+- With gpg signing: that gpg is installed, the key exists and has not
+  expired, gpg-agent and pinentry can make a test signature, and GPG_TTY
+  is exported. The test signature may ask for the key's passphrase
 - Missing guard hooks
 - Workspace configuration issues
+- Commit identities the workspace forbids with forbidden_emails, or any
+  but the noreply address when the workspace sets enforce_noreply
+- Global gitconfig settings that defeat workspace isolation
+- Default SSH keys that leak to provider hosts outside the aliases
+- Whether nested git processes under 'gitws exec' see the workspace identity
+- Missing or changed host keys for workspace hosts in ~/.ssh/known_hosts
+- SSH key and ~/.ssh permissions, ownership, and key pair consistency
+- SSH keys older than their workspace's max_key_age
+- Worktrees of the repository: stale entries and per-worktree identity
+- Identity settings that override the workspace identity, traced through
+  git's config precedence chain
+- git and OpenSSH versions too old for the features workspaces use
+  (hasconfig includes, SSH signing)
+- Nested workspace roots around the repository, and which gitconfig wins
+- The repository's .gitws.yaml: workspaces, identities and guard rules it
+  names must exist; an unmanaged repository only gets machine-wide checks
+- The organization policy an administrator provides in
+  /etc/gitws/policy.yaml (or $GWS_POLICY): required signing, banned HTTPS
+  remotes, mandatory hooks. Its issues are coded GWS-ORG-* and cannot be
+  suppressed
+- SSH connectivity through the workspace alias
+- With an API token, what the provider would reject pushes for: the
+  workspace key not registered with the account, a commit email the
+  account has not verified, or one GitHub keeps private (GH007 when the
+  account blocks pushes that expose its email)
+
+Checks run concurrently, each with its own timeout, so one slow ssh or gpg
+call does not stall the report. --verbose shows how long each check took.
+
+Every issue has a stable code, e.g. GWS-HOOKS-002. Known deviations can be
+accepted per repository in .gitws.yaml, or for a whole workspace in
+config.yaml; suppressed issues are counted but not shown, and do not
+affect the exit code:
+
+  suppress:
+    - GWS-HOOKS-002
+
+Every run is remembered per repository in ~/.gws/state. --changed-since
+compares with an earlier run and shows only issues that are new or got
+worse since, so a weekly run over many repositories reports what changed:
+'last' compares with the previous run, a duration such as 7d or a date
+such as 2026-03-01 with the most recent run before then. Without an
+earlier run every issue is shown.
+
+Exit codes: 0 when nothing needs attention, 1 for warnings, 2 for errors,
+3 outside a repository. --json prints the issues with their codes, and
+--format yaml or markdown prints the report as YAML or a Markdown table.
+With --changed-since they count only the issues shown. Under GitHub
+Actions each issue is also written to stderr as an ::error, ::warning or
+::notice annotation with its fix, pointing at the file it is in, if any.
 
 Examples:
   gitws doctor
-  gitws doctor /path/to/repo`,
+  gitws doctor /path/to/repo
+  gitws doctor --offline --verbose
+  gitws doctor --json
+  gitws doctor --format markdown > report.md
+  gitws doctor --show-suppressed
+  gitws doctor --changed-since last
+  gitws doctor --changed-since 7d`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDoctor,
 }
 
+var (
+	doctorOffline        bool
+	doctorShowSuppressed bool
+	doctorChangedSince   string
+)
+
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip checks that contact the network (SSH connectivity, provider API)")
+	doctorCmd.Flags().BoolVar(&doctorShowSuppressed, "show-suppressed", false, "Also list issues suppressed by .gitws.yaml or the workspace")
+	doctorCmd.Flags().StringVar(&doctorChangedSince, "changed-since", "", "Only show issues new or worse since an earlier run: last, a duration such as 7d, or a date")
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
@@ -52,65 +140,397 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	// Find git root
 	gitRoot, err := git.FindGitRoot(repoPath)
 	if err != nil {
-		return fmt.Errorf("not in a git repository: %w", err)
+		return notRepoError(err)
+	}
+
+	if abs, err := filepath.Abs(gitRoot); err == nil {
+		gitRoot = abs
+	}
+	history, err := health.Load(gitRoot)
+	if err != nil {
+		return err
+	}
+	var baseline *health.Snapshot
+	if doctorChangedSince != "" {
+		if baseline, err = history.Baseline(doctorChangedSince, time.Now()); err != nil {
+			return fmt.Errorf("invalid --changed-since: %w", err)
+		}
 	}
 
 	// Run all checks
-	issues := runAllChecks(gitRoot)
+	issues, suppressed := suppressIssues(gitRoot, runAllChecks(cmd.Context(), gitRoot, doctorOffline))
+	recordDoctorResult(gitRoot, issues)
+	last, _ := history.Baseline(health.Last, time.Now())
+	notifyDoctorIssues(gitRoot, issues, last)
+	history.Record(health.Snapshot{At: time.Now(), Issues: healthIssues(issues)})
+	_ = history.Save()
+
+	var note string
+	if doctorChangedSince != "" {
+		issues, note = changedIssues(issues, baseline)
+	}
+	annotateIssues(issues)
+
+	if jsonOutput {
+		if err := writeDoctorJSON(issues, suppressed); err != nil {
+			return err
+		}
+		if code := issueExitCode(issues); code != ExitOK {
+			return exitCode(cmd, code)
+		}
+		return nil
+	}
 
 	// Show doctor report
 	if err := prompt.ShowDoctorReport(issues); err != nil {
 		return err
 	}
+	if len(suppressed) > 0 && !prompt.Structured() {
+		if doctorShowSuppressed {
+			bullet := "   •"
+			if prompt.Format() == prompt.FormatMarkdown {
+				fmt.Printf("\n**%s**\n\n", i18n.T("Suppressed:"))
+				bullet = "-"
+			} else {
+				fmt.Println(i18n.T("Suppressed:"))
+			}
+			for _, issue := range suppressed {
+				fmt.Printf(prompt.Text("%s %s [%s]\n"), bullet, issue.Message, issue.Code)
+			}
+		} else {
+			fmt.Println(prompt.Text(i18n.T("ℹ️  %d issue(s) suppressed by .gitws.yaml or the workspace (--show-suppressed lists them)", len(suppressed))))
+		}
+	}
+	if note != "" && !prompt.Structured() {
+		fmt.Println(prompt.Text(note))
+	}
 
-	// Exit with non-zero if issues found
-	if len(issues) > 0 {
-		os.Exit(1)
+	// Exit with 1 for warnings, 2 for errors
+	if code := issueExitCode(issues); code != ExitOK {
+		return exitCode(cmd, code)
 	}
 
 	return nil
 }
 
-func runAllChecks(gitRoot string) []prompt.Issue {
-	var issues []prompt.Issue
+// healthIssues returns issues as the doctor history remembers them
+func healthIssues(issues []prompt.Issue) []health.Issue {
+	remembered := make([]health.Issue, 0, len(issues))
+	for _, issue := range issues {
+		remembered = append(remembered, health.Issue{
+			Code:    issue.Code,
+			Type:    issue.Type,
+			Message: issue.Message,
+			Path:    issue.Path,
+		})
+	}
+	return remembered
+}
+
+// changedIssues keeps the issues that are new or worse than in baseline,
+// and describes the comparison. Without a baseline every issue is kept.
+func changedIssues(issues []prompt.Issue, baseline *health.Snapshot) ([]prompt.Issue, string) {
+	if baseline == nil {
+		return issues, i18n.T("ℹ️  No earlier doctor run to compare with; showing all issues")
+	}
+
+	changes, resolved := health.Compare(baseline.Issues, healthIssues(issues))
+	kept := make([]prompt.Issue, 0, len(changes))
+	for _, change := range changes {
+		issue := issues[change.Index]
+		if change.Was != "" {
+			issue.Message = i18n.T("%s (was %s)", issue.Message, change.Was)
+		}
+		kept = append(kept, issue)
+	}
+	note := i18n.T("ℹ️  Since the run of %s: %d unchanged, %d resolved",
+		baseline.At.Local().Format("2006-01-02 15:04"), len(issues)-len(changes), len(resolved))
+	return kept, note
+}
+
+// annotateIssues adds issues to the run as annotations under GitHub
+// Actions. They go to stderr, which the runner reads commands from too,
+// so they do not mix with a JSON report.
+func annotateIssues(issues []prompt.Issue) {
+	if prompt.GitHubActions() {
+		_ = prompt.WriteAnnotations(os.Stderr, issues)
+	}
+}
+
+// doctorIssue is the JSON and YAML form of a doctor issue
+type doctorIssue struct {
+	Code       string   `json:"code" yaml:"code"`
+	Type       string   `json:"type" yaml:"type"`
+	Message    string   `json:"message" yaml:"message"`
+	Fix        string   `json:"fix,omitempty" yaml:"fix,omitempty"`
+	Command    []string `json:"command,omitempty" yaml:"command,omitempty"`
+	Workspace  string   `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	Path       string   `json:"path,omitempty" yaml:"path,omitempty"`
+	File       string   `json:"file,omitempty" yaml:"file,omitempty"`
+	Suppressed bool     `json:"suppressed,omitempty" yaml:"suppressed,omitempty"`
+}
+
+// writeDoctorJSON prints issues, then suppressed ones, as JSON
+func writeDoctorJSON(issues, suppressed []prompt.Issue) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doctorReport(issues, suppressed)); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// doctorReport lists issues, then suppressed ones, as they are encoded
+func doctorReport(issues, suppressed []prompt.Issue) []doctorIssue {
+	report := []doctorIssue{}
+	add := func(issue prompt.Issue, isSuppressed bool) {
+		report = append(report, doctorIssue{
+			Code:       issue.Code,
+			Type:       issue.Type,
+			Message:    issue.Message,
+			Fix:        issue.Fix,
+			Command:    issue.Command,
+			Workspace:  issue.Workspace,
+			Path:       issue.Path,
+			File:       issue.File,
+			Suppressed: isSuppressed,
+		})
+	}
+	for _, issue := range issues {
+		add(issue, false)
+	}
+	for _, issue := range suppressed {
+		add(issue, true)
+	}
+	return report
+}
+
+// suppressIssues splits off the issues whose codes the repository's
+// .gitws.yaml or its workspace accept. An unreadable .gitws.yaml is
+// reported by checkRepoFile.
+func suppressIssues(gitRoot string, issues []prompt.Issue) (shown, suppressed []prompt.Issue) {
+	accepted := make(map[string]bool)
+	if repo, err := config.LoadRepo(gitRoot); err == nil {
+		for _, code := range repo.Suppress {
+			accepted[strings.ToUpper(code)] = true
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		if ws, exists := cfg.GetWorkspace(workspaceForRepo(cfg, gitRoot)); exists {
+			for _, code := range ws.Suppress {
+				accepted[strings.ToUpper(code)] = true
+			}
+		}
+	}
+
+	for _, issue := range issues {
+		if issue.Code != "" && accepted[issue.Code] && !orgPolicyCode(issue.Code) {
+			suppressed = append(suppressed, issue)
+		} else {
+			shown = append(shown, issue)
+		}
+	}
+	return shown, suppressed
+}
+
+// machineChecks are the checks that do not concern the repository itself
+var machineChecks = map[string]bool{
+	"git": true, "global-config": true, "filesystem": true, "known-hosts": true,
+	"keys": true, "key-age": true, "compat": true, "repo-file": true,
+	"org-policy": true,
+}
+
+// Per-check time limits. A check that runs over is reported and the
+// rest of the report is not held up by it.
+const (
+	localCheckTimeout   = 5 * time.Second
+	networkCheckTimeout = 15 * time.Second
+	// The gpg check may wait for a passphrase in pinentry
+	gpgCheckTimeout = time.Minute
+)
+
+// doctorCheck is one independent doctor check
+type doctorCheck struct {
+	name    string
+	timeout time.Duration
+	run     func(ctx context.Context) []prompt.Issue
+}
+
+// checkResult is the outcome of a doctor check
+type checkResult struct {
+	issues   []prompt.Issue
+	duration time.Duration
+	timedOut bool
+}
 
-	// Check 1: Git repository validity
-	issues = append(issues, checkGitRepository(gitRoot)...)
+func runAllChecks(ctx context.Context, gitRoot string, offline bool) []prompt.Issue {
+	// Resolve the workspace up front so fixes can name it
+	var workspaceName string
+	if cfg, err := config.Load(); err == nil {
+		workspaceName = workspaceForRepo(cfg, gitRoot)
+	}
 
-	// Check 2: Remote configuration
-	issues = append(issues, checkRemoteConfiguration(gitRoot)...)
+	checks := []doctorCheck{
+		// Check 1: Git repository validity
+		{"git", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkGitRepository(ctx, gitRoot) }},
+		// Check 2: Remote configuration
+		{"remote", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkRemoteConfiguration(ctx, gitRoot, workspaceName) }},
+		// Check 3: User identity
+		{"identity", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkUserIdentity(ctx, gitRoot, workspaceName) }},
+		// Check 4: Signing configuration
+		{"signing", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkSigningConfiguration(ctx, gitRoot) }},
+		// Check 5: Guard hooks
+		{"hooks", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkGuardHooks(ctx, gitRoot, workspaceName) }},
+		// Check 6: Workspace consistency
+		{"workspace", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkWorkspaceConsistency(ctx, gitRoot) }},
+		// Check 7: Workspace policy
+		{"policy", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkWorkspacePolicy(ctx, gitRoot, workspaceName) }},
+		// Check 8: Global gitconfig hygiene
+		{"global-config", localCheckTimeout, checkGlobalGitConfig},
+		// Check 9: Network filesystems under gitws-managed files
+		{"filesystem", localCheckTimeout, checkFilesystems},
+		// Check 10: Keys ssh offers to the alias and the bare host
+		{"ssh-config", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkKeyLeakage(ctx, workspaceName) }},
+		// Check 11: Identity seen by nested git processes under 'gitws exec'
+		{"exec-env", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkExecEnvironment(ctx, gitRoot, workspaceName) }},
+		// Check 12: Host keys for workspace hosts
+		{"known-hosts", localCheckTimeout, checkKnownHosts},
+		// Check 13: SSH key permissions, ownership and pairing
+		{"keys", localCheckTimeout, checkKeyFiles},
+		// Check 14: Key age against max_key_age
+		{"key-age", localCheckTimeout, checkKeyAge},
+		// Check 15: Worktrees sharing this repository
+		{"worktrees", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkWorktrees(ctx, gitRoot, workspaceName) }},
+		// Check 16: Which config file wins for user.name and user.email
+		{"precedence", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkIdentityPrecedence(ctx, gitRoot, workspaceName) }},
+		// Check 17: git and OpenSSH versions against the features workspaces use
+		{"compat", localCheckTimeout, checkCompatibility},
+		// Check 18: Workspace roots nested around this repository
+		{"nesting", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkNestedRoots(ctx, gitRoot) }},
+		// Check 19: The repository's .gitws.yaml against config.yaml
+		{"repo-file", localCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkRepoFile(ctx, gitRoot) }},
+		// Check 20: The organization policy; it may be fetched, so it gets
+		// the network timeout
+		{"org-policy", networkCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkOrgPolicy(ctx, gitRoot, workspaceName) }},
+		// Check 21: gpg, its agent and pinentry, when signing with gpg
+		{"gpg", gpgCheckTimeout, func(ctx context.Context) []prompt.Issue { return checkGPG(ctx, gitRoot, workspaceName) }},
+	}
+	if !offline {
+		// Check 22: SSH connectivity through the workspace alias
+		checks = append(checks, doctorCheck{"ssh", networkCheckTimeout, func(ctx context.Context) []prompt.Issue {
+			return checkSSHConnection(ctx, gitRoot, workspaceName)
+		}})
+		// Check 23: What the provider would reject pushes for
+		checks = append(checks, doctorCheck{"push", networkCheckTimeout, func(ctx context.Context) []prompt.Issue {
+			return checkPushRejection(ctx, gitRoot, workspaceName)
+		}})
+	}
 
-	// Check 3: User identity
-	issues = append(issues, checkUserIdentity(gitRoot)...)
+	// An unmanaged repository only gets the machine-wide checks
+	if repo, err := config.LoadRepo(gitRoot); err == nil && repo.Unmanaged {
+		var kept []doctorCheck
+		for _, check := range checks {
+			if machineChecks[check.name] {
+				kept = append(kept, check)
+			}
+		}
+		checks = kept
+	}
 
-	// Check 4: Signing configuration
-	issues = append(issues, checkSigningConfiguration(gitRoot)...)
+	results := runChecks(ctx, checks)
 
-	// Check 5: Guard hooks
-	issues = append(issues, checkGuardHooks(gitRoot)...)
+	var issues []prompt.Issue
+	for i, result := range results {
+		if result.timedOut {
+			fix := i18n.T("Re-run with --verbose to see how long each check takes")
+			switch checks[i].timeout {
+			case networkCheckTimeout:
+				fix = i18n.T("Check your network connection, or skip network checks with --offline")
+			case gpgCheckTimeout:
+				fix = i18n.T("Enter the key's passphrase when pinentry asks; if it never appears, restart the agent: gpgconf --kill gpg-agent")
+			}
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-DOCTOR-001",
+				Type:    "warning",
+				Message: i18n.T("Check '%s' timed out after %s", checks[i].name, checks[i].timeout),
+				Fix:     fix,
+			})
+			continue
+		}
+		issues = append(issues, result.issues...)
+	}
 
-	// Check 6: Workspace consistency
-	issues = append(issues, checkWorkspaceConsistency(gitRoot)...)
+	if verbose {
+		fmt.Println(i18n.T("Feature support:"))
+		for _, f := range compat.Features {
+			status := "✓"
+			if err := compat.Require(f); err != nil {
+				status = "❌ " + err.Error()
+			}
+			fmt.Printf("  %-28s %s\n", f.Name, prompt.Text(status))
+		}
+		fmt.Println(i18n.T("Check durations:"))
+		for i, result := range results {
+			status := ""
+			if result.timedOut {
+				status = " " + i18n.T("(timed out)")
+			}
+			fmt.Printf("  %-14s %8s%s\n", checks[i].name, result.duration.Round(time.Millisecond), status)
+		}
+	}
 
 	return issues
 }
 
-func checkGitRepository(gitRoot string) []prompt.Issue {
+// runChecks runs checks concurrently, each under its own timeout, and
+// returns their results in the order given. Cancelling ctx stops them all.
+func runChecks(ctx context.Context, checks []doctorCheck) []checkResult {
+	results := make([]checkResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check doctorCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, check.timeout)
+			defer cancel()
+
+			start := time.Now()
+			done := make(chan []prompt.Issue, 1)
+			go func() { done <- check.run(ctx) }()
+
+			select {
+			case issues := <-done:
+				results[i] = checkResult{issues: issues, duration: time.Since(start)}
+			case <-ctx.Done():
+				results[i] = checkResult{duration: time.Since(start), timedOut: true}
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func checkGitRepository(ctx context.Context, gitRoot string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	// Check git version
 	version, err := git.CheckGitPresence()
 	if err != nil {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-GIT-001",
 			Type:    "error",
-			Message: "Git is not installed or not in PATH",
-			Fix:     "Install Git and ensure it's in your PATH",
+			Message: i18n.T("Git is not installed or not in PATH"),
+			Fix:     i18n.T("Install Git and ensure it's in your PATH"),
 		})
 	} else if verbose {
 		// Add info about git version
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-GIT-002",
 			Type:    "info",
-			Message: fmt.Sprintf("Git version: %s", version),
+			Message: i18n.T("Git version: %s", version),
 			Fix:     "",
 		})
 	}
@@ -118,25 +538,48 @@ func checkGitRepository(gitRoot string) []prompt.Issue {
 	return issues
 }
 
-func checkRemoteConfiguration(gitRoot string) []prompt.Issue {
+func checkRemoteConfiguration(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	remoteURL, err := git.GetRemoteURL(gitRoot)
 	if err != nil {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-REMOTE-001",
 			Type:    "error",
-			Message: "No origin remote configured",
-			Fix:     "Add origin remote: git remote add origin <url>",
+			Message: i18n.T("No origin remote configured"),
+			Fix:     i18n.T("Add origin remote: git remote add origin <url>"),
 		})
 		return issues
 	}
 
+	// An https workspace reaches its hosts directly, with its token
+	if cfg, err := config.Load(); err == nil {
+		if ws, exists := cfg.GetWorkspace(workspaceName); exists && ws.Transport == workspace.TransportHTTPS {
+			if remoteNeedsRewrite(remoteURL, ws) {
+				issues = append(issues, prompt.Issue{
+					Code:      "GWS-REMOTE-004",
+					Type:      "warning",
+					Message:   i18n.T("Remote URL not using HTTPS, which workspace %s authenticates with (current: %s)", workspaceName, remoteURL),
+					Fix:       i18n.T("Rewrite remote URL to HTTPS"),
+					Workspace: workspaceName,
+					Path:      gitRoot,
+					Command:   fixCommand(workspaceName, gitRoot, "rewrite-remote"),
+				})
+			}
+			return issues
+		}
+	}
+
 	// Check if using SSH
 	if !strings.HasPrefix(remoteURL, "git@") {
 		issues = append(issues, prompt.Issue{
-			Type:    "warning",
-			Message: "Remote URL is not using SSH",
-			Fix:     "Use 'gitws fix' to rewrite remote URL to SSH",
+			Code:      "GWS-REMOTE-002",
+			Type:      "warning",
+			Message:   i18n.T("Remote URL is not using SSH"),
+			Fix:       i18n.T("Rewrite remote URL to SSH"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+			Command:   fixCommand(workspaceName, gitRoot, "rewrite-remote"),
 		})
 	}
 
@@ -146,9 +589,13 @@ func checkRemoteConfiguration(gitRoot string) []prompt.Issue {
 		if err == nil {
 			if !strings.Contains(host, "gws") && !strings.Contains(host, "gitws") {
 				issues = append(issues, prompt.Issue{
-					Type:    "warning",
-					Message: fmt.Sprintf("Remote URL not using gitws alias (current: %s)", host),
-					Fix:     "Use 'gitws fix' to rewrite remote URL to use workspace alias",
+					Code:      "GWS-REMOTE-003",
+					Type:      "warning",
+					Message:   i18n.T("Remote URL not using gitws alias (current: %s)", host),
+					Fix:       i18n.T("Rewrite remote URL to use workspace alias"),
+					Workspace: workspaceName,
+					Path:      gitRoot,
+					Command:   fixCommand(workspaceName, gitRoot, "rewrite-remote"),
 				})
 			}
 		}
@@ -157,39 +604,53 @@ func checkRemoteConfiguration(gitRoot string) []prompt.Issue {
 	return issues
 }
 
-func checkUserIdentity(gitRoot string) []prompt.Issue {
+func checkUserIdentity(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	userName, err := git.GetLocalConfig(gitRoot, "user.name")
 	if err != nil || userName == "" {
 		issues = append(issues, prompt.Issue{
-			Type:    "error",
-			Message: "No user.name configured",
-			Fix:     "Set user.name: git config user.name 'Your Name'",
+			Code:      "GWS-IDENTITY-001",
+			Type:      "error",
+			Message:   i18n.T("No user.name configured"),
+			Fix:       i18n.T("Set user.name: git config user.name 'Your Name'"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
 		})
 	}
 
 	userEmail, err := git.GetLocalConfig(gitRoot, "user.email")
 	if err != nil || userEmail == "" {
 		issues = append(issues, prompt.Issue{
-			Type:    "error",
-			Message: "No user.email configured",
-			Fix:     "Set user.email: git config user.email 'your@email.com'",
+			Code:      "GWS-IDENTITY-002",
+			Type:      "error",
+			Message:   i18n.T("No user.email configured"),
+			Fix:       i18n.T("Set user.email: git config user.email 'your@email.com'"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
 		})
 	}
 
+	// Identity comes from the workspace, so point at it when it is known
+	if workspaceName != "" {
+		for i := range issues {
+			issues[i].Command = fixCommand(workspaceName, gitRoot, "set-identity")
+		}
+	}
+
 	return issues
 }
 
-func checkSigningConfiguration(gitRoot string) []prompt.Issue {
+func checkSigningConfiguration(ctx context.Context, gitRoot string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	signingEnabled, signingMethod, signingKey, err := git.GetSigningStatus(gitRoot)
 	if err != nil {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-SIGNING-001",
 			Type:    "warning",
-			Message: "Could not determine signing configuration",
-			Fix:     "Check your Git signing configuration",
+			Message: i18n.T("Could not determine signing configuration"),
+			Fix:     i18n.T("Check your Git signing configuration"),
 		})
 		return issues
 	}
@@ -197,9 +658,10 @@ func checkSigningConfiguration(gitRoot string) []prompt.Issue {
 	if signingEnabled {
 		if signingKey == "" {
 			issues = append(issues, prompt.Issue{
+				Code:    "GWS-SIGNING-002",
 				Type:    "error",
-				Message: "Signing enabled but no signing key configured",
-				Fix:     "Configure signing key: git config user.signingkey <key>",
+				Message: i18n.T("Signing enabled but no signing key configured"),
+				Fix:     i18n.T("Configure signing key: git config user.signingkey <key>"),
 			})
 		}
 
@@ -207,9 +669,10 @@ func checkSigningConfiguration(gitRoot string) []prompt.Issue {
 			// Check if SSH key exists
 			if signingKey != "" && !strings.HasSuffix(signingKey, ".pub") {
 				issues = append(issues, prompt.Issue{
+					Code:    "GWS-SIGNING-003",
 					Type:    "warning",
-					Message: "SSH signing key should end with .pub",
-					Fix:     "Update signing key to use .pub file",
+					Message: i18n.T("SSH signing key should end with .pub"),
+					Fix:     i18n.T("Update signing key to use .pub file"),
 				})
 			}
 		}
@@ -218,31 +681,454 @@ func checkSigningConfiguration(gitRoot string) []prompt.Issue {
 	return issues
 }
 
-func checkGuardHooks(gitRoot string) []prompt.Issue {
+// gpgExpiryWarning is how long before a gpg signing key expires doctor
+// starts warning
+const gpgExpiryWarning = 30 * 24 * time.Hour
+
+// checkGPG follows a gpg-signed commit through what usually makes it fail
+// with "gpg failed to sign the data": a missing gpg, a missing or expired
+// key, gpg-agent or pinentry not working, and GPG_TTY not exported
+func checkGPG(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	if sign, _ := git.GetConfig(gitRoot, "commit.gpgsign"); sign != "true" {
+		return nil
+	}
+	if format, _ := git.GetConfig(gitRoot, "gpg.format"); format != "" && format != "openpgp" {
+		return nil
+	}
+
+	// git signs with the committer identity when no key is set
+	keyID, _ := git.GetConfig(gitRoot, "user.signingkey")
+	if keyID == "" {
+		keyID, _ = git.GetConfig(gitRoot, "user.email")
+	}
+	program := gpgProgram(gitRoot)
+
+	issue := func(code, typ, message, fix string) prompt.Issue {
+		return prompt.Issue{Code: code, Type: typ, Message: message, Fix: fix, Workspace: workspaceName, Path: gitRoot}
+	}
+
+	if err := gpg.Installed(program); err != nil {
+		return []prompt.Issue{issue("GWS-GPG-001", "error",
+			i18n.T("Commits are signed with gpg, but %s is not installed", program),
+			i18n.T("Install GnuPG (e.g. brew install gnupg, apt install gnupg), or point git at it: git config --global gpg.program <path>"))}
+	}
+
+	keys, err := gpg.ListSecretKeys(ctx, program, keyID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []prompt.Issue{issue("GWS-GPG-002", "warning", err.Error(), "Check the keyring with: gpg --list-secret-keys")}
+	}
+	if len(keys) == 0 {
+		return []prompt.Issue{issue("GWS-GPG-003", "error",
+			i18n.T("No gpg secret key for %s", keyID),
+			i18n.T("Import the key (gpg --import <file>), or sign with one you have: git config user.signingkey <key-id> (list them with: gpg --list-secret-keys --keyid-format long)"))}
+	}
+
+	// gpg signs with the first usable key that matches
+	var issues []prompt.Issue
+	key := keys[0]
+	expires, usable := key.SigningExpiry()
+	now := time.Now()
+	switch {
+	case key.Revoked:
+		return []prompt.Issue{issue("GWS-GPG-004", "error",
+			i18n.T("gpg key %s is revoked", key.ID),
+			i18n.T("Generate a new key (gpg --quick-gen-key), then sign with it: git config user.signingkey <key-id>"))}
+	case !usable:
+		return []prompt.Issue{issue("GWS-GPG-004", "error",
+			i18n.T("gpg key %s has no key that can sign", key.ID),
+			i18n.T("Add a signing subkey: gpg --quick-add-key %s ed25519 sign 1y", key.Fingerprint))}
+	case !expires.IsZero() && expires.Before(now):
+		expired := issue("GWS-GPG-005", "error",
+			i18n.T("gpg key %s expired on %s", key.ID, expires.Format("2006-01-02")),
+			gpgExtendFix(key, expires))
+		expired.Command = gpgRotateCommand(workspaceName)
+		return []prompt.Issue{expired}
+	case !expires.IsZero() && expires.Sub(now) < gpgExpiryWarning:
+		expiring := issue("GWS-GPG-006", "warning",
+			i18n.T("gpg key %s expires on %s", key.ID, expires.Format("2006-01-02")),
+			gpgExtendFix(key, expires))
+		expiring.Command = gpgRotateCommand(workspaceName)
+		issues = append(issues, expiring)
+	}
+
+	ttyProblem := false
+	if err := gpg.TestSign(ctx, program, keyID); err != nil {
+		if ctx.Err() != nil {
+			return issues
+		}
+		message, fix := err.Error(), i18n.T("Reproduce it with: echo test | %s --clearsign -u %s", program, keyID)
+		var signErr *gpg.SignError
+		if errors.As(err, &signErr) {
+			switch signErr.Problem() {
+			case gpg.ProblemTTY:
+				ttyProblem = true
+				message = i18n.T("gpg cannot ask for the passphrase: pinentry has no terminal")
+				fix = i18n.T("Export GPG_TTY in your shell profile: export GPG_TTY=$(tty)")
+			case gpg.ProblemPinentry:
+				message = i18n.T("gpg-agent cannot start pinentry to ask for the passphrase")
+				fix = i18n.T("Install pinentry (e.g. brew install pinentry-mac, apt install pinentry-curses), set pinentry-program in ~/.gnupg/gpg-agent.conf, then: gpgconf --kill gpg-agent")
+			case gpg.ProblemAgent:
+				message = i18n.T("gpg cannot reach gpg-agent")
+				fix = i18n.T("Restart it: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent")
+			case gpg.ProblemCancelled:
+				message = i18n.T("The test signature was cancelled: pinentry was dismissed or timed out")
+				fix = i18n.T("Run 'gitws doctor' again and enter the passphrase; raise pinentry-timeout in ~/.gnupg/gpg-agent.conf if it closes too soon")
+			case gpg.ProblemPassphrase:
+				message = i18n.T("The test signature failed: wrong passphrase")
+				fix = i18n.T("Run 'gitws doctor' again with the right passphrase, or change it: gpg --change-passphrase %s", key.ID)
+			}
+		}
+		issues = append(issues, issue("GWS-GPG-007", "error", message, fix))
+	}
+
+	// Curses pinentry reads the passphrase from GPG_TTY
+	if !ttyProblem && runtime.GOOS != "windows" && os.Getenv("GPG_TTY") == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+		issues = append(issues, issue("GWS-GPG-008", "warning",
+			i18n.T("GPG_TTY is not exported, so a terminal pinentry cannot ask for the passphrase"),
+			i18n.T("Add to your shell profile: export GPG_TTY=$(tty)")))
+	}
+	return issues
+}
+
+// gpgProgram returns the gpg git runs in repoPath or, when it is "",
+// outside repositories
+func gpgProgram(repoPath string) string {
+	for _, key := range []string{"gpg.openpgp.program", "gpg.program"} {
+		var program string
+		if repoPath == "" {
+			program, _ = git.GetGlobalConfig(key)
+		} else {
+			program, _ = git.GetConfig(repoPath, key)
+		}
+		if program != "" {
+			return program
+		}
+	}
+	return "gpg"
+}
+
+// gpgRotateCommand returns the command that renews the signing key of a
+// workspace signing with gpg, or nil for any other
+func gpgRotateCommand(workspaceName string) []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	if ws, exists := cfg.GetWorkspace(workspaceName); !exists || ws.Signing != "gpg" {
+		return nil
+	}
+	return []string{"gitws", "rotate", workspaceName, "--gpg"}
+}
+
+// gpgExtendFix tells how to extend whichever of key's primary key or
+// signing subkeys expires at expires
+func gpgExtendFix(key gpg.Key, expires time.Time) string {
+	extend := fmt.Sprintf("gpg --quick-set-expire %s 1y '*'", key.Fingerprint)
+	if key.Expires.Equal(expires) {
+		extend = fmt.Sprintf("gpg --quick-set-expire %s 1y", key.Fingerprint)
+	}
+	return i18n.T("Extend it: %s, then upload the public key to your provider again", extend)
+}
+
+func checkGuardHooks(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	hooksInstalled, err := git.CheckHooksInstalled(gitRoot)
 	if err != nil {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-HOOKS-001",
 			Type:    "warning",
-			Message: "Could not check guard hooks status",
-			Fix:     "Manually verify hooks in .git/hooks/",
+			Message: i18n.T("Could not check guard hooks status"),
+			Fix:     i18n.T("Manually verify hooks in %s", git.HooksDir(gitRoot)),
 		})
 		return issues
 	}
 
 	if !hooksInstalled {
 		issues = append(issues, prompt.Issue{
-			Type:    "warning",
-			Message: "Guard hooks not installed",
-			Fix:     "Use 'gitws fix --enable-guards' to install hooks",
+			Code:      "GWS-HOOKS-002",
+			Type:      "warning",
+			Message:   i18n.T("Guard hooks not installed"),
+			Fix:       i18n.T("Install guard hooks"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+			Command:   fixCommand(workspaceName, gitRoot, "enable-guards"),
 		})
 	}
 
 	return issues
 }
 
-func checkWorkspaceConsistency(gitRoot string) []prompt.Issue {
+// checkWorktrees reports worktrees whose directory is gone and worktrees
+// that commit with a different identity than the workspace, e.g. through
+// a per-worktree config (extensions.worktreeConfig)
+func checkWorktrees(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	worktrees, err := git.ListWorktrees(gitRoot)
+	if err != nil || len(worktrees) < 2 {
+		return nil
+	}
+
+	var expected string
+	if cfg, err := config.Load(); err == nil {
+		if _, ok := cfg.GetWorkspace(workspaceName); ok {
+			expected = repoIdentity(cfg, workspaceName, gitRoot).Email
+		}
+	}
+
+	var issues []prompt.Issue
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-WORKTREE-001",
+				Type:    "warning",
+				Message: i18n.T("Worktree %s no longer exists", wt.Path),
+				Command: []string{"git", "-C", gitRoot, "worktree", "prune"},
+			})
+			continue
+		}
+		if expected == "" || wt.Path == gitRoot {
+			continue
+		}
+		if email, _ := git.GetConfig(wt.Path, "user.email"); email != expected {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-WORKTREE-002",
+				Type:      "error",
+				Message:   i18n.T("Worktree %s commits as %q, expected %s", wt.Path, email, expected),
+				Fix:       i18n.T("Check 'git -C %s config --show-origin user.email' and remove the override", wt.Path),
+				Workspace: workspaceName,
+				Path:      wt.Path,
+			})
+		}
+	}
+	return issues
+}
+
+// checkIdentityPrecedence traces every value of user.name and user.email
+// git sees in the repository and, when the one it uses is not the
+// workspace's, reports which setting wins and why
+func checkIdentityPrecedence(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	if _, ok := cfg.GetWorkspace(workspaceName); !ok {
+		return nil
+	}
+	ws := repoIdentity(cfg, workspaceName, gitRoot)
+	wsConfigPath, err := workspace.GitConfigPath(workspaceName)
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	for _, k := range []struct {
+		key      string
+		expected string
+		envVars  []string
+	}{
+		{"user.name", ws.Name, []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"}},
+		{"user.email", ws.Email, []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"}},
+	} {
+		// The environment beats every config file
+		for _, name := range k.envVars {
+			if value := os.Getenv(name); value != "" && value != k.expected {
+				issues = append(issues, prompt.Issue{
+					Code:      "GWS-PRECEDENCE-001",
+					Type:      "error",
+					Message:   i18n.T("%s=%q in the environment overrides %s %q from every gitconfig", name, value, k.key, k.expected),
+					Fix:       i18n.T("Unset %s in your shell profile or direnv setup", name),
+					Workspace: workspaceName,
+					Path:      gitRoot,
+				})
+			}
+		}
+
+		origins, err := git.ConfigOrigins(gitRoot, k.key)
+		if err != nil || len(origins) == 0 {
+			continue // Unset values are reported by the identity check
+		}
+		winner := origins[len(origins)-1]
+		if winner.Value == k.expected {
+			continue
+		}
+
+		issue := prompt.Issue{
+			Code:      "GWS-PRECEDENCE-002",
+			Type:      "error",
+			Workspace: workspaceName,
+			Path:      gitRoot,
+		}
+		var reason string
+		included := false
+		for _, o := range origins {
+			if o.File() == wsConfigPath {
+				included = true
+			}
+		}
+
+		switch {
+		case winner.Scope == "command":
+			reason = i18n.T("it is passed with -c or GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS, which override every file")
+			issue.Fix = i18n.T("Remove the override from your shell environment or git alias")
+		case winner.Scope == "worktree":
+			reason = i18n.T("per-worktree config overrides the repository and global files")
+			issue.Command = []string{"git", "-C", gitRoot, "config", "--worktree", "--unset", k.key}
+		case winner.Scope == "local":
+			reason = i18n.T("the repository's own config overrides global files, including the workspace gitconfig")
+			issue.Command = fixCommand(workspaceName, gitRoot, "set-identity")
+		case !included:
+			reason = i18n.T("the workspace gitconfig %s is not included for this repository", wsConfigPath)
+			issue.Fix = i18n.T("Check the includeIf entries in ~/.gitconfig, and that the repository is under %s", ws.Root)
+		default:
+			reason = i18n.T("it is read after the workspace gitconfig, and the last value git reads wins")
+			issue.Fix = i18n.T("Remove %s from %s, or move it above the gitws includeIf block", k.key, winner.File())
+		}
+
+		var chain strings.Builder
+		for _, o := range origins {
+			fmt.Fprintf(&chain, "\n     %-8s %s = %q", o.Scope, o.File(), o.Value)
+		}
+		issue.Message = i18n.T("%s resolves to %q from %s (%s), not the workspace's %q: %s. Values in the order git reads them:%s",
+			k.key, winner.Value, winner.File(), winner.Scope, k.expected, reason, chain.String())
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// checkCompatibility reports workspaces configured for features the
+// installed git or OpenSSH does not support
+func checkCompatibility(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+
+	var issues []prompt.Issue
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		if ws.Isolation == workspace.IsolationHasconfig {
+			if err := compat.Require(compat.Hasconfig); err != nil {
+				issues = append(issues, prompt.Issue{
+					Code:      "GWS-COMPAT-001",
+					Type:      "warning",
+					Message:   i18n.T("Workspace '%s': %v; it falls back to directory-based isolation under %s", name, err, ws.Root),
+					Fix:       i18n.T("Upgrade git, then run 'gitws init %s --force' to switch back to remote-based isolation", name),
+					Workspace: name,
+				})
+			}
+		}
+		if ws.Signing == "ssh" {
+			if err := compat.Require(compat.SSHSigning); err != nil {
+				issues = append(issues, prompt.Issue{
+					Code:      "GWS-COMPAT-002",
+					Type:      "error",
+					Message:   i18n.T("Workspace '%s': %v; commits fail to sign", name, err),
+					Fix:       i18n.T("Upgrade git and OpenSSH, or set signing: none for the workspace"),
+					Workspace: name,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// checkNestedRoots explains which workspace gitconfig wins for a
+// repository under several nested workspace roots
+func checkNestedRoots(ctx context.Context, gitRoot string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var matching []string
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		if ws.Root == "" || followsRemote(ws) {
+			continue
+		}
+		if gitRoot == filepath.Clean(ws.Root) || isWithin(gitRoot, filepath.Clean(ws.Root)) {
+			matching = append(matching, name)
+		}
+	}
+	if len(matching) < 2 {
+		return nil
+	}
+
+	// The innermost root is the workspace the repository belongs to
+	innermost := matching[0]
+	for _, name := range matching[1:] {
+		if len(cfg.Workspaces[name].Root) > len(cfg.Workspaces[innermost].Root) {
+			innermost = name
+		}
+	}
+
+	// git applies every matching include in file order, so the one read
+	// last wins for each setting
+	gitConfigPath, err := globalGitConfigPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(gitConfigPath)
+	if err != nil {
+		return nil
+	}
+	block, found := fsutil.ExtractBetweenMarkers(string(data), workspace.IncludeIfStartMarker(), workspace.IncludeIfEndMarker())
+	if !found {
+		return nil
+	}
+	lines := strings.Split(block, "\n")
+	winner, winnerLine := "", -1
+	for _, name := range matching {
+		path, err := workspace.GitConfigPath(name)
+		if err != nil {
+			continue
+		}
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "path = "+path && i > winnerLine {
+				winner, winnerLine = name, i
+			}
+		}
+	}
+	if winner == "" {
+		return nil
+	}
+
+	var others []string
+	for _, name := range matching {
+		if name != winner {
+			others = append(others, fmt.Sprintf("'%s'", name))
+		}
+	}
+
+	if winner != innermost {
+		configPath, _ := config.ConfigPath()
+		return []prompt.Issue{{
+			Code:      "GWS-NESTING-001",
+			Type:      "warning",
+			Message:   i18n.T("Repository is under nested workspace roots; '%s' wins over the innermost workspace '%s' because its includeIf is read later", winner, innermost),
+			Fix:       i18n.T("Rewrite the includeIf block so nested workspaces come last"),
+			Workspace: innermost,
+			Path:      gitRoot,
+			Command:   []string{"gitws", "apply", "-f", configPath},
+		}}
+	}
+	return []prompt.Issue{{
+		Code:      "GWS-NESTING-002",
+		Type:      "info",
+		Message:   i18n.T("Repository is also under the root of %s; '%s' wins because its includeIf is read last, but settings only %s sets still apply", strings.Join(others, ", "), winner, strings.Join(others, ", ")),
+		Fix:       i18n.T("Set those values explicitly in '%s', move one of the roots so they do not nest, or give one workspace --isolation hasconfig", winner),
+		Workspace: winner,
+		Path:      gitRoot,
+	}}
+}
+
+func checkWorkspaceConsistency(ctx context.Context, gitRoot string) []prompt.Issue {
 	var issues []prompt.Issue
 
 	// Try to determine workspace from remote URL
@@ -264,9 +1150,10 @@ func checkWorkspaceConsistency(gitRoot string) []prompt.Issue {
 	cfg, err := config.Load()
 	if err != nil {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-WORKSPACE-001",
 			Type:    "warning",
-			Message: "Could not load workspace configuration",
-			Fix:     "Check ~/.gws/config.yaml",
+			Message: i18n.T("Could not load workspace configuration"),
+			Fix:     i18n.T("Check ~/.gws/config.yaml"),
 		})
 		return issues
 	}
@@ -282,22 +1169,745 @@ func checkWorkspaceConsistency(gitRoot string) []prompt.Issue {
 
 	if foundWorkspace == "" {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-WORKSPACE-002",
 			Type:    "warning",
-			Message: fmt.Sprintf("SSH alias '%s' not found in workspace configuration", host),
-			Fix:     "Run 'gitws init' to create workspace or check configuration",
+			Message: i18n.T("SSH alias '%s' not found in workspace configuration", host),
+			Fix:     i18n.T("Run 'gitws init' to create workspace or check configuration"),
 		})
 		return issues
 	}
 
-	// Check if repository is in expected workspace root
+	// Check that the key comment still names the workspace identity;
+	// provided keys keep the comment their issuer gave them
+	if comment, err := ssh.GetKeyComment(cfg.Workspaces[foundWorkspace].SSHKey + ".pub"); err == nil && cfg.Workspaces[foundWorkspace].KeySource == nil {
+		if expected := ssh.KeyComment(cfg.Workspaces[foundWorkspace].Email, foundWorkspace); comment != expected {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-WORKSPACE-003",
+				Type:      "info",
+				Message:   i18n.T("SSH key comment %q does not match workspace identity %q", comment, expected),
+				Fix:       i18n.T("Update the key comment (the key itself is unchanged)"),
+				Workspace: foundWorkspace,
+				Command:   []string{"gitws", "keys", "comment", "edit", foundWorkspace},
+			})
+		}
+	}
+
+	// Check if repository is in expected workspace root; hasconfig
+	// workspaces follow the remote, so any location is fine
 	ws := cfg.Workspaces[foundWorkspace]
-	if !strings.HasPrefix(gitRoot, ws.Root) {
+	if !followsRemote(ws) && !strings.HasPrefix(gitRoot, ws.Root) {
 		issues = append(issues, prompt.Issue{
+			Code:    "GWS-WORKSPACE-004",
 			Type:    "warning",
-			Message: fmt.Sprintf("Repository not in workspace root (expected: %s)", ws.Root),
-			Fix:     "Move repository to workspace root or update workspace configuration",
+			Message: i18n.T("Repository not in workspace root (expected: %s)", ws.Root),
+			Fix:     i18n.T("Move repository to workspace root or update workspace configuration"),
 		})
 	}
 
 	return issues
 }
+
+// checkWorkspacePolicy flags repository settings that deviate from the
+// default branch, pull strategy, and commit template of its workspace
+func checkWorkspacePolicy(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	deviation := func(key, want string) {
+		if have, _ := git.GetConfig(gitRoot, key); have != want {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-POLICY-001",
+				Type:      "warning",
+				Message:   i18n.T("%s is %q, workspace '%s' policy is %q", key, have, workspaceName, want),
+				Workspace: workspaceName,
+				Path:      gitRoot,
+				Command:   []string{"git", "-C", gitRoot, "config", key, want},
+			})
+		}
+	}
+
+	if rebase, ff := workspace.PullConfig(ws.PullStrategy); rebase != "" {
+		deviation("pull.rebase", rebase)
+	} else if ff != "" {
+		deviation("pull.ff", ff)
+	}
+	if ws.CommitTemplate != "" {
+		deviation("commit.template", ws.CommitTemplate)
+	}
+
+	// A forbidden address, from config or from GIT_AUTHOR_EMAIL. A
+	// repository using one of the workspace's identities commits as another
+	// account, which noreply enforcement does not cover.
+	gws := guard.Workspace{Name: workspaceName, ForbiddenEmails: ws.ForbiddenEmails, RequireNoreply: repoIdentity(cfg, workspaceName, gitRoot).EnforceNoreply}
+	if gws.ChecksEmails() {
+		configured, _ := git.GetConfig(gitRoot, "user.email")
+		_, author, _ := git.AuthorIdent(gitRoot)
+		seen := make(map[string]bool)
+		for _, addr := range []string{configured, author} {
+			entry, blocked := guard.Forbids(gws, addr)
+			if !blocked || seen[strings.ToLower(addr)] {
+				continue
+			}
+			seen[strings.ToLower(addr)] = true
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-POLICY-003",
+				Type:      "error",
+				Message:   i18n.T("Commits would use %s, which workspace '%s' forbids (%s)", addr, workspaceName, entry),
+				Workspace: workspaceName,
+				Path:      gitRoot,
+				Command:   fixCommand(workspaceName, gitRoot, "set-identity"),
+			})
+		}
+	}
+
+	if ws.DefaultBranch != "" {
+		if head := git.GetRemoteHead(gitRoot); head != "" && head != ws.DefaultBranch {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-POLICY-002",
+				Type:      "info",
+				Message:   i18n.T("Default branch is '%s', workspace '%s' policy is '%s'", head, workspaceName, ws.DefaultBranch),
+				Fix:       i18n.T("Rename the default branch on the provider, then run 'git remote set-head origin --auto'"),
+				Workspace: workspaceName,
+				Path:      gitRoot,
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkSSHConnection verifies the workspace alias the repository pushes
+// through actually authenticates
+func checkSSHConnection(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return nil
+	}
+
+	// Only test the alias this repository actually uses
+	remoteURL, err := git.GetRemoteURL(gitRoot)
+	if err != nil {
+		return nil
+	}
+	if host, err := rewrite.ExtractHost(remoteURL); err != nil || host != ws.SSHAlias {
+		return nil
+	}
+
+	if err := ssh.TestSSHConnectionContext(ctx, ws.SSHAlias); err != nil {
+		return []prompt.Issue{{
+			Code:      "GWS-SSH-001",
+			Type:      "error",
+			Message:   i18n.T("Cannot connect to %s through alias %s", ws.HostName, ws.SSHAlias),
+			Fix:       i18n.T("Add %s.pub to your %s account, then test with: ssh -T %s", ws.SSHKey, ws.HostName, ws.SSHAlias),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+		}}
+	}
+
+	return nil
+}
+
+// checkPushRejection asks the provider about what would get the
+// repository's pushes rejected, before work piles up behind them: the
+// workspace key not being registered with the account, and a commit email
+// that is neither a verified address of the account nor its noreply
+// address. An address GitHub keeps private is rejected (GH007) when the
+// account also blocks pushes that expose its email, a setting the API
+// does not reveal; the noreply address is safe either way.
+func checkPushRejection(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return nil
+	}
+	// The token is the workspace account's on its own host, not an
+	// identity's or an extra host's
+	if repoIdentity(cfg, workspaceName, gitRoot).Email != ws.Email {
+		return nil
+	}
+	remoteURL, err := git.GetRemoteURL(gitRoot)
+	if err != nil {
+		return nil
+	}
+	host, err := rewrite.ExtractHost(remoteURL)
+	if err != nil || (host != ws.SSHAlias && host != ws.HostName) {
+		return nil
+	}
+	if token, err := workspaceToken(workspaceName); err != nil || token == "" {
+		return nil
+	}
+	client, err := workspaceClient(workspaceName, ws)
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	issue := func(code, typ, message, fix string, command ...string) {
+		issues = append(issues, prompt.Issue{
+			Code:      code,
+			Type:      typ,
+			Message:   message,
+			Fix:       fix,
+			Workspace: workspaceName,
+			Path:      gitRoot,
+			Command:   command,
+		})
+	}
+
+	// Only the alias authenticates with the key
+	if host == ws.SSHAlias {
+		if public, err := ssh.GetPublicKey(ws.SSHKey + ".pub"); err == nil {
+			fingerprint, _ := ssh.Fingerprint(public)
+			if registered, err := client.ListSSHKeys(ctx); err == nil && !containsFingerprint(registered, fingerprint) {
+				issue("GWS-SSH-002", "error",
+					i18n.T("SSH key %s is not registered with your %s account; pushes will be rejected unless it is a deploy key", ws.SSHKey+".pub", ws.HostName),
+					i18n.T("Register the key with the account"), "gitws", "keys", "upload", workspaceName, "--ssh")
+			}
+		}
+	}
+
+	addr, _ := git.GetConfig(gitRoot, "user.email")
+	if addr == "" {
+		return issues
+	}
+	useNoreply := []string{"gitws", "email", "use-noreply", workspaceName}
+
+	if email.IsNoreply(addr) {
+		noreply, err := client.NoreplyEmail(ctx)
+		if err == nil && !strings.EqualFold(noreply, addr) {
+			issue("GWS-EMAIL-002", "warning",
+				i18n.T("Commits use %s, which is not your %s account's noreply address (%s); they will not be attributed to the account and push rules may reject them", addr, ws.HostName, noreply),
+				i18n.T("Commit with the account's noreply address"), useNoreply...)
+		}
+		return issues
+	}
+
+	emails, err := client.ListEmails(ctx)
+	if err != nil {
+		return issues
+	}
+	var found *provider.Email
+	for i := range emails {
+		if strings.EqualFold(emails[i].Address, addr) {
+			found = &emails[i]
+			break
+		}
+	}
+	switch {
+	case found == nil:
+		issue("GWS-EMAIL-002", "warning",
+			i18n.T("Commits use %s, which is not an address of your %s account; they will not be attributed to the account and push rules may reject them", addr, ws.HostName),
+			i18n.T("Add and verify %s in the account's email settings, or commit with the noreply address", addr), useNoreply...)
+	case !found.Verified:
+		issue("GWS-EMAIL-002", "warning",
+			i18n.T("Commits use %s, which your %s account has not verified; push rules may reject them", addr, ws.HostName),
+			i18n.T("Verify %s in the account's email settings, or commit with the noreply address", addr), useNoreply...)
+	case found.Private:
+		issue("GWS-EMAIL-001", "warning",
+			i18n.T("%s keeps %s private; pushes of commits using it are rejected (GH007) if the account blocks pushes that expose its email", ws.HostName, addr),
+			i18n.T("Commit with the noreply address"), append(useNoreply, "--enforce")...)
+	}
+	return issues
+}
+
+// containsFingerprint reports whether one of keys has fingerprint
+func containsFingerprint(keys []provider.SSHKey, fingerprint string) bool {
+	for _, k := range keys {
+		if f, err := ssh.Fingerprint(k.Key); err == nil && f == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKeyLeakage uses 'ssh -G' to confirm the workspace alias offers only
+// the workspace key, and that the bare provider hosts offer no key at all.
+// Otherwise a remote that skips the alias authenticates with a default
+// key, often as a different account.
+func checkKeyLeakage(ctx context.Context, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	if ws, exists := cfg.GetWorkspace(workspaceName); exists {
+		resolved, err := ssh.ResolveConfig(ctx, ws.SSHAlias)
+		if err != nil {
+			return []prompt.Issue{{Code: "GWS-SSHCONFIG-001", Type: "warning", Message: err.Error(), Fix: i18n.T("Check ~/.ssh/config with: ssh -G %s", ws.SSHAlias)}}
+		}
+		if resolved.Get("identitiesonly") != "yes" {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-SSHCONFIG-002",
+				Type:      "warning",
+				Message:   i18n.T("Alias %s does not use IdentitiesOnly; ssh may offer agent keys before the workspace key", ws.SSHAlias),
+				Fix:       i18n.T("An earlier 'Host *' block likely sets IdentitiesOnly no; move it below the gws blocks in ~/.ssh/config"),
+				Workspace: workspaceName,
+			})
+		}
+		if files := resolved.ExistingIdentityFiles(); len(files) > 0 && files[0] != ws.SSHKey {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-SSHCONFIG-003",
+				Type:      "warning",
+				Message:   i18n.T("Alias %s offers %s before the workspace key %s", ws.SSHAlias, files[0], ws.SSHKey),
+				Fix:       i18n.T("Remove the IdentityFile from the earlier matching block in ~/.ssh/config"),
+				Workspace: workspaceName,
+			})
+		}
+	}
+
+	for _, host := range workspaceHosts(cfg) {
+		resolved, err := ssh.ResolveConfig(ctx, host)
+		if err != nil {
+			continue
+		}
+		leaked := resolved.ExistingIdentityFiles()
+		if resolved.Get("identitiesonly") != "yes" && os.Getenv("SSH_AUTH_SOCK") != "" {
+			leaked = append(leaked, "keys in ssh-agent")
+		}
+		if len(leaked) > 0 {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-SSHCONFIG-004",
+				Type:    "warning",
+				Message: i18n.T("ssh offers %s to bare %s; remotes that bypass the workspace alias authenticate as whichever account owns it", strings.Join(leaked, ", "), host),
+				Fix:     "gitws guard ssh",
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkExecEnvironment starts a nested git the way submodule and subtree
+// commands do, under the environment 'gitws exec' sets, and verifies it
+// sees the workspace identity
+func checkExecEnvironment(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return nil
+	}
+
+	env := git.IdentityEnv(os.Environ(), workspaceIdentity(workspaceName, ws))
+	email, author, err := git.ProbeIdentity(ctx, gitRoot, env)
+	if err != nil {
+		return []prompt.Issue{{Code: "GWS-EXEC-001", Type: "warning", Message: err.Error(), Workspace: workspaceName, Path: gitRoot}}
+	}
+
+	var issues []prompt.Issue
+	if !strings.Contains(author, "<"+ws.Email+">") {
+		issues = append(issues, prompt.Issue{
+			Code:      "GWS-EXEC-002",
+			Type:      "error",
+			Message:   i18n.T("Nested git processes under 'gitws exec %s' commit as %s", workspaceName, author),
+			Fix:       i18n.T("Check for a wrapper or shell profile that resets GIT_AUTHOR_* variables"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+		})
+	}
+	if email != ws.Email {
+		version, _ := git.GetVersion()
+		issues = append(issues, prompt.Issue{
+			Code:      "GWS-EXEC-003",
+			Type:      "warning",
+			Message:   i18n.T("Nested git processes see user.email %q, not %s; git %s ignores GIT_CONFIG_COUNT", email, ws.Email, version),
+			Fix:       i18n.T("Upgrade git to %s or later so signing settings reach submodules under 'gitws exec'", compat.ConfigEnv.Requires[0].Min),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+		})
+	}
+	return issues
+}
+
+// checkKnownHosts reports workspace hosts whose key is missing from
+// known_hosts, which makes the first BatchMode connection fail, or does not
+// match the provider's published fingerprints
+func checkKnownHosts(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	for _, host := range workspaceHosts(cfg) {
+		state, recorded, err := ssh.CheckKnownHost(ctx, host)
+		if err != nil {
+			continue
+		}
+		switch state {
+		case ssh.HostKeyMissing:
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-HOSTKEY-001",
+				Type:    "warning",
+				Message: i18n.T("No host key for %s in known_hosts; the first clone will prompt, or fail in BatchMode", host),
+				Command: []string{"gitws", "known-hosts", host},
+			})
+		case ssh.HostKeyChanged:
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-HOSTKEY-002",
+				Type:    "error",
+				Message: i18n.T("known_hosts key for %s (%s) does not match the published fingerprints", host, strings.Join(recorded, ", ")),
+				Fix:     i18n.T("Verify the key with the provider, then: ssh-keygen -R %s && gitws known-hosts %s", host, host),
+			})
+		}
+	}
+	return issues
+}
+
+// checkKeyFiles audits workspace SSH keys and ~/.ssh
+func checkKeyFiles(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	for _, f := range auditKeys(cfg) {
+		issues = append(issues, keyFindingIssue(f))
+	}
+	return issues
+}
+
+// checkKeyAge warns about keys older than their workspace's max_key_age
+func checkKeyAge(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	now := time.Now()
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		if age, _, due := keyRotationDue(ws, 0, now); due {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-KEYAGE-001",
+				Type:      "warning",
+				Message:   i18n.T("SSH key for workspace %s is %d days old (max_key_age %s)", name, age, ws.MaxKeyAge),
+				Workspace: name,
+				Command:   []string{"gitws", "rotate", name},
+			})
+		}
+	}
+	return issues
+}
+
+// checkFilesystems warns when gitws state or workspace keys live on a
+// network filesystem, where ssh may ignore keys
+func checkFilesystems(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	if configDir, err := config.ConfigDir(); err == nil {
+		if fsType, isNetwork := fsutil.NetworkFilesystem(configDir); isNetwork {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-FS-001",
+				Type:    "info",
+				Message: i18n.T("%s is on a network filesystem (%s); gitws falls back to in-place writes where rename is unreliable", configDir, fsType),
+			})
+		}
+	}
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+	for _, name := range names {
+		for _, issue := range keyFilesystemIssues(cfg.Workspaces[name].SSHKey) {
+			issue.Workspace = name
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// keyFilesystemIssues reports a private key on a network filesystem, and
+// the case ssh actually trips over: permission bits the mount does not
+// keep, which make ssh ignore the key as unprotected
+func keyFilesystemIssues(keyPath string) []prompt.Issue {
+	fsType, isNetwork := fsutil.NetworkFilesystem(keyPath)
+	if !isNetwork {
+		return nil
+	}
+
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		return nil
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return []prompt.Issue{{
+			Code:    "GWS-FS-002",
+			Type:    "error",
+			Message: i18n.T("SSH key %s is on a %s mount that reports mode %04o; ssh will ignore it as unprotected", keyPath, fsType, info.Mode().Perm()),
+			Fix:     i18n.T("Keep the key on a local disk and register it with 'gitws init <workspace> --force --key-file <path> --key-mode reference', or mount the share with owner-only file modes"),
+		}}
+	}
+
+	return []prompt.Issue{{
+		Code:    "GWS-FS-003",
+		Type:    "warning",
+		Message: i18n.T("SSH key %s is on a network filesystem (%s); ssh stops using it if the mount loosens its permissions", keyPath, fsType),
+		Fix:     i18n.T("Prefer a key on a local disk: 'gitws init <workspace> --force --key-file <path> --key-mode reference'"),
+	}}
+}
+
+// checkGlobalGitConfig looks for global settings that quietly undo
+// per-workspace isolation
+func checkGlobalGitConfig(ctx context.Context) []prompt.Issue {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Workspaces) == 0 {
+		return nil // Nothing to isolate yet
+	}
+
+	var issues []prompt.Issue
+	issues = append(issues, checkGlobalIdentity()...)
+	issues = append(issues, checkUseConfigOnly()...)
+	issues = append(issues, checkCredentialHelper(cfg)...)
+	issues = append(issues, checkURLRewrites(cfg)...)
+	return issues
+}
+
+func checkGlobalIdentity() []prompt.Issue {
+	globalEmail, _ := git.GetGlobalConfig("user.email")
+	if globalEmail == "" {
+		return nil
+	}
+
+	return []prompt.Issue{{
+		Code:    "GWS-GLOBAL-001",
+		Type:    "warning",
+		Message: i18n.T("Global user.email is set (%s); repos outside every workspace commit as it without complaint", globalEmail),
+		Fix:     "git config --global --unset user.email && git config --global user.useConfigOnly true",
+	}}
+}
+
+func checkUseConfigOnly() []prompt.Issue {
+	if value, _ := git.GetGlobalConfig("user.useConfigOnly"); value == "true" {
+		return nil
+	}
+
+	return []prompt.Issue{{
+		Code:    "GWS-GLOBAL-002",
+		Type:    "warning",
+		Message: i18n.T("user.useConfigOnly is not enabled; outside a workspace git guesses an identity instead of refusing to commit"),
+		Fix:     "git config --global user.useConfigOnly true",
+	}}
+}
+
+func checkCredentialHelper(cfg *config.File) []prompt.Issue {
+	helper, _ := git.GetGlobalConfig("credential.helper")
+	if helper == "" {
+		return nil
+	}
+	if useHTTPPath, _ := git.GetGlobalConfig("credential.useHttpPath"); useHTTPPath == "true" {
+		return nil // Credentials are already keyed per repository path
+	}
+
+	// A helper stores one login per host, so only hosts shared by
+	// several workspaces are at risk
+	byHost := make(map[string][]string)
+	for name, ws := range cfg.Workspaces {
+		byHost[ws.HostName] = append(byHost[ws.HostName], name)
+	}
+
+	hosts := make([]string, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var issues []prompt.Issue
+	for _, host := range hosts {
+		names := byHost[host]
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-GLOBAL-003",
+			Type:    "warning",
+			Message: i18n.T("Global credential.helper '%s' caches a single HTTPS login for %s, shared by workspaces %s", helper, host, strings.Join(names, ", ")),
+			Fix:     i18n.T("git config --global credential.useHttpPath true, or use 'gitws fix' to switch remotes to SSH aliases"),
+		})
+	}
+
+	return issues
+}
+
+func checkURLRewrites(cfg *config.File) []prompt.Issue {
+	entries, err := git.GetGlobalConfigRegexp(`^url\..*\.(insteadof|pushinsteadof)$`)
+	if err != nil {
+		return nil
+	}
+
+	aliases := make(map[string]string)
+	hosts := make(map[string]bool)
+	for name, ws := range cfg.Workspaces {
+		aliases[ws.SSHAlias] = name
+		hosts[ws.HostName] = true
+	}
+
+	var issues []prompt.Issue
+	for _, entry := range entries {
+		// Keys look like url.<base>.insteadof <prefix>
+		base := strings.TrimPrefix(entry.Key, "url.")
+		base = base[:strings.LastIndex(base, ".")]
+
+		fromHost, err := rewrite.ExtractHost(entry.Value)
+		if err != nil {
+			continue
+		}
+		toHost, _ := rewrite.ExtractHost(base)
+
+		if name, ok := aliases[fromHost]; ok && toHost != fromHost {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-GLOBAL-004",
+				Type:    "error",
+				Message: i18n.T("Global url rewrite sends '%s' to '%s', bypassing workspace '%s'", entry.Value, base, name),
+				Fix:     fmt.Sprintf("git config --global --unset-all %s", entry.Key),
+			})
+			continue
+		}
+
+		viaSSH := strings.HasPrefix(base, "git@") || strings.HasPrefix(base, "ssh://")
+		if hosts[toHost] && viaSSH {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-GLOBAL-005",
+				Type:    "warning",
+				Message: i18n.T("Global url rewrite maps '%s' to '%s', which uses the default SSH key instead of a workspace key", entry.Value, base),
+				Fix:     i18n.T("git config --global --unset-all %s, then clone with 'gitws clone'", entry.Key),
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkRepoFile validates the repository's .gitws.yaml against config.yaml
+func checkRepoFile(ctx context.Context, gitRoot string) []prompt.Issue {
+	path := filepath.Join(gitRoot, config.RepoFileName)
+	repo, err := config.ReadRepo(gitRoot)
+	if err != nil {
+		return []prompt.Issue{{
+			Code:    "GWS-REPO-001",
+			Type:    "error",
+			Message: err.Error(),
+			Fix:     i18n.T("Fix or remove %s", path),
+			Path:    gitRoot,
+			File:    path,
+		}}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	if applied, err := config.LoadRepo(gitRoot); err == nil && applied.Untrusted {
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-REPO-007",
+			Type:    "warning",
+			Message: i18n.T("%s is not trusted or changed since it was, so its settings are ignored; review it, then trust it", config.RepoFileName),
+			Command: []string{"gitws", "trust", gitRoot},
+			Path:    gitRoot,
+			File:    path,
+		})
+	} else if repo.Unmanaged {
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-REPO-002",
+			Type:    "info",
+			Message: i18n.T("%s declares the repository unmanaged; only machine-wide checks ran", config.RepoFileName),
+			Path:    gitRoot,
+			File:    path,
+		})
+		if repo.Workspace != "" || repo.Identity != "" {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-REPO-003",
+				Type:    "warning",
+				Message: i18n.T("%s is unmanaged but also names a workspace or identity, which are ignored", config.RepoFileName),
+				Fix:     i18n.T("Remove either unmanaged or workspace/identity from %s", path),
+				Path:    gitRoot,
+				File:    path,
+			})
+		}
+	}
+
+	workspaceName := repo.Workspace
+	if workspaceName != "" {
+		if _, exists := cfg.GetWorkspace(workspaceName); !exists {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-REPO-004",
+				Type:    "error",
+				Message: i18n.T("%s pins workspace %q, which is not in config.yaml", config.RepoFileName, workspaceName),
+				Fix:     i18n.T("Create it with 'gitws init %s', or correct %s", workspaceName, path),
+				Path:    gitRoot,
+				File:    path,
+			})
+			workspaceName = ""
+		}
+	} else if !repo.Unmanaged {
+		workspaceName = workspaceForRepo(cfg, gitRoot)
+	}
+
+	if repo.Identity != "" && workspaceName != "" {
+		if _, err := cfg.Workspaces[workspaceName].ForIdentity(repo.Identity); err != nil {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-REPO-005",
+				Type:      "error",
+				Message:   i18n.T("%s selects identity %q, which workspace '%s' does not have", config.RepoFileName, repo.Identity, workspaceName),
+				Fix:       i18n.T("Add it with 'gitws identity add %s %s --email <email>', or correct %s", workspaceName, repo.Identity, path),
+				Workspace: workspaceName,
+				Path:      gitRoot,
+				File:      path,
+			})
+		}
+	}
+
+	for _, rule := range repo.DisableGuards {
+		known := false
+		for _, r := range guard.Rules {
+			known = known || strings.EqualFold(r, rule)
+		}
+		if known && guard.IsMandatory(rule) {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-REPO-008",
+				Type:    "warning",
+				Message: i18n.T("%s disables guard rule %q, which cannot be disabled and stays enforced", config.RepoFileName, rule),
+				Fix:     i18n.T("Remove %q from disable_guards in %s", rule, path),
+				Path:    gitRoot,
+				File:    path,
+			})
+		}
+		if !known {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-REPO-006",
+				Type:    "warning",
+				Message: i18n.T("%s disables unknown guard rule %q", config.RepoFileName, rule),
+				Fix:     i18n.T("Use one of: %s", strings.Join(guard.Rules, ", ")),
+				Path:    gitRoot,
+				File:    path,
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/prompt"
+)
+
+func TestRunChecksOrder(t *testing.T) {
+	// Later checks finish first; results still follow the given order
+	var checks []doctorCheck
+	for i := 0; i < 5; i++ {
+		delay := time.Duration(5-i) * 10 * time.Millisecond
+		code := fmt.Sprintf("GWS-TEST-%03d", i)
+		checks = append(checks, doctorCheck{code, time.Second, func(ctx context.Context) []prompt.Issue {
+			time.Sleep(delay)
+			return []prompt.Issue{{Code: code}}
+		}})
+	}
+
+	results := runChecks(context.Background(), checks)
+	if len(results) != len(checks) {
+		t.Fatalf("expected %d results, got %d", len(checks), len(results))
+	}
+	for i, result := range results {
+		if result.timedOut || len(result.issues) != 1 {
+			t.Fatalf("expected one issue from check %d, got %+v", i, result)
+		}
+		if result.issues[0].Code != checks[i].name {
+			t.Errorf("expected %q, got %q", checks[i].name, result.issues[0].Code)
+		}
+	}
+}
+
+func TestRunChecksTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	stopped := make(chan error, 1)
+	checks := []doctorCheck{
+		{"fast", time.Second, func(ctx context.Context) []prompt.Issue {
+			return []prompt.Issue{{Code: "GWS-TEST-001"}}
+		}},
+		{"hung", 50 * time.Millisecond, func(ctx context.Context) []prompt.Issue {
+			// A process started with the check's context is stopped with it
+			stopped <- exec.CommandContext(ctx, "sleep", "10").Run()
+			return []prompt.Issue{{Code: "GWS-TEST-002"}}
+		}},
+	}
+
+	start := time.Now()
+	results := runChecks(context.Background(), checks)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the hung check not to hold up the report, took %s", elapsed)
+	}
+	if results[0].timedOut || len(results[0].issues) != 1 {
+		t.Errorf("expected the fast check to report, got %+v", results[0])
+	}
+	if !results[1].timedOut || len(results[1].issues) != 0 {
+		t.Errorf("expected the hung check to time out without issues, got %+v", results[1])
+	}
+
+	select {
+	case err := <-stopped:
+		if err == nil {
+			t.Error("expected the hung check's process to be killed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the hung check's process to stop with its context")
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var emailEnforce bool
+
+// emailCmd represents the email command
+var emailCmd = &cobra.Command{
+	Use:   "email",
+	Short: "Manage the addresses a workspace commits with",
+	Long: `Manage the addresses a workspace commits with.
+
+A workspace has an email its commits use and, when that is the provider's
+noreply address (e.g. 42+octo@users.noreply.github.com), a primary email:
+the account's own address, kept out of commit history.
+
+Examples:
+  gitws email list
+  gitws auth login work && gitws email use-noreply work
+  gitws email use-primary work`,
+}
+
+var emailListCmd = &cobra.Command{
+	Use:   "list [workspace]",
+	Short: "List workspace commit and primary emails",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runEmailList,
+}
+
+var emailUseNoreplyCmd = &cobra.Command{
+	Use:   "use-noreply <workspace>",
+	Short: "Commit with the provider's noreply address",
+	Long: `Look up the account's noreply address through the provider API and
+commit with it from now on. The current email is kept as the primary email.
+
+Needs an API token (see 'gitws auth login'). GitHub and GitLab hand out
+noreply addresses; Bitbucket does not.
+
+Repositories with their own user.email keep it until
+'gitws fix --set-identity' is run in them.
+
+--enforce goes further, for accounts that block pushes exposing their
+email (GitHub's GH007): user.email is set to the noreply address in every
+repository of the workspace, and the guard hooks block commits and pushes
+with any other address. Run it again to catch repositories added since.
+'gitws email use-primary' lifts the enforcement.
+
+Examples:
+  gitws email use-noreply work
+  gitws email use-noreply oss --enforce`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEmailUseNoreply,
+}
+
+var emailUsePrimaryCmd = &cobra.Command{
+	Use:   "use-primary <workspace>",
+	Short: "Commit with the primary email again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEmailUsePrimary,
+}
+
+func init() {
+	rootCmd.AddCommand(emailCmd)
+	emailCmd.AddCommand(emailListCmd)
+	emailCmd.AddCommand(emailUseNoreplyCmd)
+	emailCmd.AddCommand(emailUsePrimaryCmd)
+
+	emailUseNoreplyCmd.Flags().BoolVar(&emailEnforce, "enforce", false, "Set user.email in every repository and block commits with any other address")
+}
+
+// fetchNoreplyEmail asks the workspace's provider for the account's
+// noreply address
+func fetchNoreplyEmail(ctx context.Context, name string, ws config.Workspace) (string, error) {
+	client, err := workspaceClient(name, ws)
+	if err != nil {
+		return "", err
+	}
+	addr, err := callAPI(ctx, client.NoreplyEmail)
+	if errors.Is(err, provider.ErrUnsupported) {
+		return "", fmt.Errorf("%s has no noreply addresses", ws.HostName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the noreply address: %w", err)
+	}
+	return addr, nil
+}
+
+func runEmailList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := cfg.ListWorkspaces()
+	if len(args) > 0 {
+		if _, exists := cfg.GetWorkspace(args[0]); !exists {
+			return fmt.Errorf("workspace %q not found", args[0])
+		}
+		names = []string{args[0]}
+	}
+
+	headers := []string{"Workspace", "Email", "Primary Email"}
+	var rows [][]string
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		rows = append(rows, []string{name, ws.Email, ws.PrimaryEmail})
+	}
+
+	return prompt.ShowStatusTable(headers, rows)
+}
+
+func runEmailUseNoreply(cmd *cobra.Command, args []string) error {
+	return switchWorkspaceEmail(cmd.Context(), args[0], func(ws *config.Workspace) error {
+		noreply, err := fetchNoreplyEmail(cmd.Context(), args[0], *ws)
+		if err != nil {
+			return err
+		}
+		if ws.Email != noreply {
+			ws.PrimaryEmail, ws.Email = ws.AccountEmail(), noreply
+		}
+		ws.EnforceNoreply = ws.EnforceNoreply || emailEnforce
+		return nil
+	})
+}
+
+func runEmailUsePrimary(cmd *cobra.Command, args []string) error {
+	return switchWorkspaceEmail(cmd.Context(), args[0], func(ws *config.Workspace) error {
+		if ws.PrimaryEmail == "" {
+			return fmt.Errorf("workspace %q has no primary email; it already commits as %s", args[0], ws.Email)
+		}
+		ws.Email, ws.PrimaryEmail = ws.PrimaryEmail, ""
+		ws.EnforceNoreply = false
+		return nil
+	})
+}
+
+// switchWorkspaceEmail changes the email a workspace commits with and
+// rewrites what carries it: the key comment, the workspace gitconfig and
+// the .envrc. Under noreply enforcement it also sets user.email in every
+// repository of the workspace.
+func switchWorkspaceEmail(ctx context.Context, name string, change func(ws *config.Workspace) error) error {
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	current, exists := cfg.GetWorkspace(name)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", name)
+	}
+	if err := runPreflight(ctx, preflightChecks(cfg, false)); err != nil {
+		return err
+	}
+
+	ws := current
+	if err := change(&ws); err != nil {
+		return err
+	}
+	if ws.Email == current.Email && ws.EnforceNoreply == current.EnforceNoreply {
+		fmt.Printf(prompt.Text("✓ Workspace '%s' already commits as %s\n"), name, ws.Email)
+		if !ws.EnforceNoreply {
+			return nil
+		}
+		return enforceRepositoryEmails(cfg, name, ws)
+	}
+	if ws, err = resolveWorkspace(name, ws); err != nil {
+		return err
+	}
+
+	// A provided key's comment belongs to whoever issued it
+	if ws.KeySource == nil {
+		if err := reconcileKeyComment(name, ws.Email, ws.SSHKey); err != nil {
+			return err
+		}
+	}
+	if err := createWorkspaceGitConfig(name, ws); err != nil {
+		return fmt.Errorf("failed to update workspace gitconfig: %w", err)
+	}
+	if err := writeWorkspaceEnvrc(name, ws); err != nil {
+		return fmt.Errorf("failed to update workspace .envrc: %w", err)
+	}
+
+	cfg.SetWorkspace(name, ws)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf(prompt.Text("✓ Workspace '%s' now commits as %s\n"), name, ws.Email)
+	switch {
+	case ws.EnforceNoreply:
+		return enforceRepositoryEmails(cfg, name, ws)
+	case current.EnforceNoreply:
+		fmt.Printf(prompt.Text("✓ Workspace '%s' no longer enforces its noreply address\n"), name)
+	}
+	fmt.Println("  Repositories with their own user.email keep it; run 'gitws fix --set-identity' in them.")
+	return nil
+}
+
+// enforceRepositoryEmails sets user.email to the workspace's in every
+// repository it owns, except those committing as one of its identities
+func enforceRepositoryEmails(cfg *config.File, name string, ws config.Workspace) error {
+	repos, err := findWorkspaceRepositories(ws)
+	if err != nil {
+		return fmt.Errorf("failed to find repositories: %w", err)
+	}
+
+	updated := 0
+	for _, repo := range repos {
+		if workspaceForRepo(cfg, repo) != name || repoIdentity(cfg, name, repo).Email != ws.Email {
+			continue
+		}
+		if current, _ := git.GetLocalConfig(repo, "user.email"); current == ws.Email {
+			continue
+		}
+		if err := git.SetLocalConfig(repo, "user.email", ws.Email); err != nil {
+			return fmt.Errorf("failed to set user.email in %s: %w", repo, err)
+		}
+		updated++
+	}
+
+	fmt.Printf(prompt.Text("✓ Workspace '%s' enforces its noreply address; user.email updated in %d of %d repositories\n"), name, updated, len(repos))
+	return nil
+}
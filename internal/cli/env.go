@@ -0,0 +1,359 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// envFileEnvrc keeps a managed block in <root>/.envrc for direnv
+const envFileEnvrc = "envrc"
+
+// envTrackingVar lists the variables the last 'gitws env' exported, so
+// leaving a workspace can unset them
+const envTrackingVar = "GWS_ENV_VARS"
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var (
+	envShell            string
+	shellInitGitWrapper bool
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env [workspace]",
+	Short: "Print shell exports for a workspace environment",
+	Long: `Print the environment of a workspace as shell commands, for eval.
+
+The environment holds GWS_WORKSPACE, the GIT_AUTHOR_*/GIT_COMMITTER_*
+identity, and the workspace's env settings from config.yaml:
+
+  workspaces:
+    work:
+      env:
+        SSH_AUTH_SOCK: ~/.1password/agent.sock
+        ARTIFACTORY_TOKEN: $(op read op://work/artifactory/token)
+      env_file: envrc
+
+env values are expanded by the shell, so they can reference secrets
+instead of containing them. With env_file: envrc, 'gitws apply' keeps a
+managed block with the same exports in <root>/.envrc for direnv.
+
+Without a workspace argument, the workspace owning the current directory
+is used, and variables exported for another workspace are unset when
+there is none. 'gitws shell-init' runs this on every directory change.
+
+Examples:
+  eval "$(gitws env work)"
+  gitws env --shell fish | source`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEnv,
+}
+
+// shellInitCmd represents the shell-init command
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print a shell hook that loads workspace environments",
+	Long: `Print a shell hook that runs 'gitws env' whenever the current
+directory changes, so entering a workspace root exports its environment
+and leaving it unsets it again. It also defines 'gwcd <pattern>', which
+changes to the best match of 'gitws find'.
+
+--git-wrapper also defines a git function that runs 'gitws git' while a
+workspace environment is loaded, so commits and pushes inside workspace
+roots are checked first. Outside them git runs directly.
+
+Examples:
+  echo 'eval "$(gitws shell-init bash)"' >> ~/.bashrc
+  echo 'eval "$(gitws shell-init bash --git-wrapper)"' >> ~/.bashrc
+  echo 'eval "$(gitws shell-init zsh)"' >> ~/.zshrc
+  echo 'gitws shell-init fish | source' >> ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE:      runShellInit,
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(shellInitCmd)
+
+	envCmd.Flags().StringVar(&envShell, "shell", "sh", "Output syntax (sh, fish)")
+	shellInitCmd.Flags().BoolVar(&shellInitGitWrapper, "git-wrapper", false, "Also route git through 'gitws git' inside workspace roots")
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	if envShell != "sh" && envShell != "fish" {
+		return fmt.Errorf("invalid shell: %s (must be sh or fish)", envShell)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var name string
+	if len(args) == 1 {
+		name = args[0]
+		if _, exists := cfg.GetWorkspace(name); !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		name = workspaceForDir(cfg, cwd)
+	}
+
+	var vars [][2]string
+	if name != "" {
+		vars = workspaceEnv(name, cfg.Workspaces[name])
+	}
+	fmt.Print(renderEnvExports(vars, strings.Fields(os.Getenv(envTrackingVar)), envShell))
+	return nil
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		fmt.Print(`_gitws_hook() {
+  if [ "$PWD" != "${_GITWS_PWD:-}" ]; then
+    _GITWS_PWD="$PWD"
+    eval "$(gitws env 2>/dev/null)"
+  fi
+}
+case ";${PROMPT_COMMAND:-};" in
+  *";_gitws_hook;"*) ;;
+  *) PROMPT_COMMAND="_gitws_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+`)
+	case "zsh":
+		fmt.Print(`_gitws_hook() {
+  eval "$(gitws env 2>/dev/null)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _gitws_hook
+_gitws_hook
+`)
+	case "fish":
+		fmt.Print(`function __gitws_hook --on-variable PWD
+  gitws env --shell fish 2>/dev/null | source
+end
+__gitws_hook
+`)
+	default:
+		return fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish)", args[0])
+	}
+
+	if args[0] == "fish" {
+		fmt.Print(`function gwcd --description 'cd to the repository gitws find matches best'
+  set -l dir (gitws find --first $argv); or return
+  cd $dir
+end
+`)
+	} else {
+		fmt.Print(`gwcd() {
+  local dir
+  dir="$(gitws find --first "$@")" || return
+  cd "$dir"
+}
+`)
+	}
+
+	// The hook exports GWS_WORKSPACE inside workspace roots
+	if shellInitGitWrapper {
+		if args[0] == "fish" {
+			fmt.Print(`function git --wraps git
+  if set -q GWS_WORKSPACE
+    gitws git -- $argv
+  else
+    command git $argv
+  end
+end
+`)
+		} else {
+			fmt.Print(`git() {
+  if [ -n "${GWS_WORKSPACE:-}" ]; then
+    gitws git -- "$@"
+  else
+    command git "$@"
+  fi
+}
+`)
+		}
+	}
+	return nil
+}
+
+// workspaceForDir returns the workspace owning dir: the repository's
+// workspace inside a repository, otherwise the one with the deepest root
+// containing dir
+func workspaceForDir(cfg *config.File, dir string) string {
+	if gitRoot, err := git.FindGitRoot(dir); err == nil {
+		if name := workspaceForRepo(cfg, gitRoot); name != "" {
+			return name
+		}
+	}
+
+	var best, bestRoot string
+	for name, ws := range cfg.Workspaces {
+		if ws.Root == "" || (dir != ws.Root && !isWithin(dir, ws.Root)) {
+			continue
+		}
+		if len(ws.Root) > len(bestRoot) || (len(ws.Root) == len(bestRoot) && name < best) {
+			best, bestRoot = name, ws.Root
+		}
+	}
+	return best
+}
+
+// workspaceEnv returns the variables of a workspace environment in output
+// order: the identity first, then the configured env sorted by name
+func workspaceEnv(name string, ws config.Workspace) [][2]string {
+	vars := [][2]string{
+		{"GWS_WORKSPACE", name},
+		{"GIT_AUTHOR_NAME", ws.Name},
+		{"GIT_AUTHOR_EMAIL", ws.Email},
+		{"GIT_COMMITTER_NAME", ws.Name},
+		{"GIT_COMMITTER_EMAIL", ws.Email},
+	}
+
+	keys := make([]string, 0, len(ws.Env))
+	for key := range ws.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		vars = append(vars, [2]string{key, ws.Env[key]})
+	}
+	return vars
+}
+
+// renderEnvExports returns shell commands exporting vars and unsetting the
+// previously exported variables that vars no longer sets
+func renderEnvExports(vars [][2]string, previous []string, shell string) string {
+	var out strings.Builder
+	set := make(map[string]bool, len(vars))
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		set[v[0]] = true
+		names = append(names, v[0])
+	}
+
+	for _, name := range previous {
+		if !set[name] && envVarName.MatchString(name) {
+			out.WriteString(unsetLine(name, shell))
+		}
+	}
+	for _, v := range vars {
+		out.WriteString(exportLine(v[0], envValue(v[1]), shell))
+	}
+
+	if len(names) > 0 {
+		out.WriteString(exportLine(envTrackingVar, "'"+strings.Join(names, " ")+"'", shell))
+	} else if len(previous) > 0 {
+		out.WriteString(unsetLine(envTrackingVar, shell))
+	}
+	return out.String()
+}
+
+func exportLine(name, quoted, shell string) string {
+	if shell == "fish" {
+		return fmt.Sprintf("set -gx %s %s\n", name, quoted)
+	}
+	return fmt.Sprintf("export %s=%s\n", name, quoted)
+}
+
+func unsetLine(name, shell string) string {
+	if shell == "fish" {
+		return fmt.Sprintf("set -e %s\n", name)
+	}
+	return fmt.Sprintf("unset %s\n", name)
+}
+
+// envValue double-quotes a value, leaving $ references and $(...) for the
+// shell to expand. A leading ~/ is expanded here, since quoting stops the
+// shell from doing it.
+func envValue(value string) string {
+	if strings.HasPrefix(value, "~/") {
+		if expanded, err := workspace.ExpandPath(value); err == nil {
+			value = expanded
+		}
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// envrcPath returns the .envrc gitws manages for a workspace
+func envrcPath(ws config.Workspace) string {
+	return filepath.Join(ws.Root, ".envrc")
+}
+
+// renderEnvrcBlock returns the managed .envrc block, including markers.
+// direnv unloads the variables itself, so nothing is tracked.
+func renderEnvrcBlock(name string, ws config.Workspace) string {
+	var block strings.Builder
+	block.WriteString(workspace.StartMarker(name) + "\n")
+	for _, v := range workspaceEnv(name, ws) {
+		block.WriteString(exportLine(v[0], envValue(v[1]), "sh"))
+	}
+	block.WriteString(workspace.EndMarker(name))
+	return block.String()
+}
+
+// writeWorkspaceEnvrc updates the managed block in the workspace .envrc,
+// leaving the rest of the file alone
+func writeWorkspaceEnvrc(name string, ws config.Workspace) error {
+	if ws.EnvFile != envFileEnvrc {
+		return nil
+	}
+
+	path := envrcPath(ws)
+	var content string
+	if data, err := os.ReadFile(path); err == nil {
+		content = string(data)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	newContent, _ := fsutil.ReplaceBetweenMarkers(content, workspace.StartMarker(name), workspace.EndMarker(name), renderEnvrcBlock(name, ws))
+	if err := fsutil.EnsureDir(ws.Root); err != nil {
+		return fmt.Errorf("failed to create workspace root: %w", err)
+	}
+	if err := fsutil.AtomicWrite(path, []byte(strings.TrimSuffix(newContent, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeWorkspaceEnvrc removes the managed block from the workspace
+// .envrc, deleting the file when nothing else is left in it
+func removeWorkspaceEnvrc(name string, ws config.Workspace) error {
+	path := envrcPath(ws)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	start, end := workspace.StartMarker(name), workspace.EndMarker(name)
+	if _, found := fsutil.ExtractBetweenMarkers(string(data), start, end); !found {
+		return nil
+	}
+	newContent, _ := fsutil.ReplaceBetweenMarkers(string(data), start, end, "")
+	if strings.TrimSpace(newContent) == "" {
+		return fsutil.Remove(path)
+	}
+	return fsutil.AtomicWrite(path, []byte(strings.TrimSpace(newContent)+"\n"), 0644)
+}
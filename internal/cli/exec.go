@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execAll      bool
+	execJobs     int
+	execFailFast bool
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec <workspace> -- <command> [args...]",
+	Short: "Run a command with a workspace identity",
+	Long: `Run a command with the environment of a workspace, so that every git
+process it starts uses the workspace identity and SSH key.
+
+This command will:
+- Set GIT_AUTHOR_*/GIT_COMMITTER_* to the workspace name and email
+- Set GIT_SSH_COMMAND to use only the workspace key
+- Pass user.name, user.email and signing settings via GIT_CONFIG_COUNT
+- Set GWS_WORKSPACE to the workspace name
+
+These variables reach nested processes too: submodule updates, subtree
+pulls, and tools that shell out to git for dependencies (for cargo, set
+net.git-fetch-with-cli). Repository-local config does not override them.
+
+--all runs the command in every repository under the workspace root
+instead, --jobs at a time, like mu or gita. Output lines are prefixed with
+the repository; failures are summarized at the end, and --fail-fast stops
+the remaining repositories after the first one. GWS_REPO is set to the
+repository's path.
+
+Examples:
+  gitws exec work -- git submodule update --init --recursive
+  gitws exec work -- git subtree pull --prefix vendor/lib git@github.com:org/lib.git main
+  gitws exec personal -- cargo fetch
+  gitws exec work --all -- git fetch --prune
+  gitws exec work --all --jobs 2 --fail-fast -- make test`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runExec,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().BoolVar(&execAll, "all", false, "Run in every repository under the workspace root")
+	execCmd.Flags().IntVarP(&execJobs, "jobs", "j", 8, "With --all, how many repositories to run in at once")
+	execCmd.Flags().BoolVar(&execFailFast, "fail-fast", false, "With --all, stop after the first failure")
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() != 1 {
+		return fmt.Errorf("usage: gitws exec <workspace> -- <command> [args...]")
+	}
+	workspaceName, command := args[0], args[1:]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	if execAll {
+		return runExecAll(cmd, workspaceName, ws, command)
+	}
+
+	child := exec.Command(command[0], command[1:]...)
+	child.Env = append(git.IdentityEnv(os.Environ(), workspaceIdentity(workspaceName, ws)), "GWS_WORKSPACE="+workspaceName)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitCode(cmd, exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run %s: %w", command[0], err)
+	}
+	return nil
+}
+
+// execResult is the outcome of a command in one repository
+type execResult struct {
+	repo    string
+	err     error
+	skipped bool
+}
+
+// runExecAll runs command in every repository of a workspace, execJobs
+// at a time, streaming prefixed output and summarizing failures
+func runExecAll(cmd *cobra.Command, workspaceName string, ws config.Workspace, command []string) error {
+	if ws.Root == "" {
+		return fmt.Errorf("workspace %q has no root to find repositories in", workspaceName)
+	}
+	if execJobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	found, err := workspaceRepos(cfg, workspaceName)
+	if err != nil {
+		return err
+	}
+	var repos, labels []string
+	width := 0
+	for _, repo := range found {
+		if r, err := config.LoadRepo(repo); err == nil && r.Unmanaged {
+			continue
+		}
+		label, err := filepath.Rel(ws.Root, repo)
+		if err != nil {
+			label = repo
+		}
+		repos = append(repos, repo)
+		labels = append(labels, label)
+		width = max(width, len(label))
+	}
+	if len(repos) == 0 {
+		fmt.Printf(prompt.Text("ℹ️  No repositories under %s\n"), ws.Root)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	env := append(git.IdentityEnv(os.Environ(), workspaceIdentity(workspaceName, ws)), "GWS_WORKSPACE="+workspaceName)
+
+	var out sync.Mutex
+	results := make([]execResult, len(repos))
+	jobs := make(chan struct{}, execJobs)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, repo := range repos {
+		results[i].repo = labels[i]
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			results[i].skipped = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			defer func() { <-jobs }()
+
+			prefix := fmt.Sprintf("%-*s | ", width, labels[i])
+			stdout := &linePrefixer{w: os.Stdout, mu: &out, prefix: prefix}
+			stderr := &linePrefixer{w: os.Stderr, mu: &out, prefix: prefix}
+
+			child := exec.CommandContext(ctx, command[0], command[1:]...)
+			child.Dir = repo
+			// Clipped, so concurrent children never append into the
+			// same backing array
+			child.Env = append(slices.Clip(env), "GWS_REPO="+repo)
+			child.Stdout = stdout
+			child.Stderr = stderr
+			err := child.Run()
+			stdout.Flush()
+			stderr.Flush()
+
+			if err != nil {
+				if ctx.Err() != nil && cmd.Context().Err() == nil {
+					// Stopped by --fail-fast
+					results[i].skipped = true
+					return
+				}
+				results[i].err = err
+				if execFailFast {
+					cancel()
+				}
+			}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	var failed []execResult
+	skipped := 0
+	for _, r := range results {
+		switch {
+		case r.skipped:
+			skipped++
+		case r.err != nil:
+			failed = append(failed, r)
+		}
+	}
+
+	fmt.Println()
+	summary := fmt.Sprintf("%d succeeded, %d failed", len(repos)-len(failed)-skipped, len(failed))
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	summary += fmt.Sprintf(" in %s", time.Since(start).Round(time.Millisecond))
+	if len(failed) == 0 && skipped == 0 {
+		fmt.Printf(prompt.Text("✓ %s\n"), summary)
+		return nil
+	}
+	fmt.Printf(prompt.Text("❌ %s\n"), summary)
+	for _, r := range failed {
+		fmt.Printf(prompt.Text("   • %s: %v\n"), r.repo, r.err)
+	}
+	if cmd.Context().Err() != nil {
+		return cmd.Context().Err()
+	}
+	return exitCode(cmd, ExitErrors)
+}
+
+// linePrefixer writes whole lines to w with prefix, holding mu so lines
+// from concurrent commands do not interleave
+type linePrefixer struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (p *linePrefixer) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes a final line that has no newline
+func (p *linePrefixer) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *linePrefixer) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+}
+
+// workspaceIdentity returns the identity nested git processes need to act
+// as ws, mirroring the settings in its workspace gitconfig
+func workspaceIdentity(workspaceName string, ws config.Workspace) git.Identity {
+	id := git.Identity{Name: ws.Name, Email: ws.Email, SSHKey: ws.SSHKey}
+
+	switch ws.Signing {
+	case "ssh":
+		id.Config = [][2]string{
+			{"gpg.format", "ssh"},
+			{"user.signingkey", ws.SSHKey + ".pub"},
+			{"commit.gpgsign", "true"},
+		}
+	case "gpg":
+		id.Config = [][2]string{
+			{"gpg.format", "openpgp"},
+			{"user.signingkey", ws.GPGKey},
+			{"commit.gpgsign", "true"},
+		}
+	default:
+		id.Config = [][2]string{{"commit.gpgsign", "false"}}
+	}
+	id.Config = append(id.Config, credentialConfig(workspaceName, ws)...)
+	return id
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestRunExecAllParallel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	testHome(t)
+	root := t.TempDir()
+	var repos []string
+	for i := 0; i < 12; i++ {
+		repo := filepath.Join(root, fmt.Sprintf("repo%02d", i))
+		if err := os.MkdirAll(filepath.Join(repo, ".git"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, repo)
+	}
+
+	defer func(jobs int) { execJobs = jobs }(execJobs)
+	execJobs = 4
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	ws := config.Workspace{Root: root, Email: "me@work.com", Provider: "github"}
+	cfg := &config.File{Workspaces: map[string]config.Workspace{"work": ws}}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	command := []string{"sh", "-c", `printf %s "$GWS_REPO" > seen`}
+	if err := runExecAll(cmd, "work", ws, command); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, repo := range repos {
+		seen, err := os.ReadFile(filepath.Join(repo, "seen"))
+		if err != nil {
+			t.Fatalf("expected the command to run in %s: %v", repo, err)
+		}
+		if string(seen) != repo {
+			t.Errorf("expected GWS_REPO %q, got %q", repo, seen)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of status and doctor, so pre-commit wrappers and CI can react
+// to severities differently
+const (
+	ExitOK       = 0
+	ExitWarnings = 1
+	ExitErrors   = 2
+	ExitNotRepo  = 3
+)
+
+// ExitError ends a command with a specific exit code. Commands return it
+// rather than calling os.Exit, so deferred cleanup (config locks, temp
+// files) still runs; Main exits with Code.
+type ExitError struct {
+	Code int
+	Err  error // nil when the command has already reported the problem
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode ends cmd with code without printing an error; the command has
+// already said why
+func exitCode(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &ExitError{Code: code}
+}
+
+// notRepoError reports that path is not in a git repository, with
+// ExitNotRepo
+func notRepoError(err error) error {
+	if errors.Is(err, git.ErrNotARepo) {
+		return &ExitError{Code: ExitNotRepo, Err: err}
+	}
+	return &ExitError{Code: ExitNotRepo, Err: fmt.Errorf("not in a git repository: %w", err)}
+}
+
+// issueExitCode returns the exit code for the most severe of issues.
+// Issues without a type count as warnings.
+func issueExitCode(issues []prompt.Issue) int {
+	code := ExitOK
+	for _, issue := range issues {
+		switch issue.Type {
+		case "error":
+			return ExitErrors
+		case "info":
+		default:
+			code = ExitWarnings
+		}
+	}
+	return code
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/archive"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportIncludeKeys bool
+	exportWorkspaces  []string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <archive>",
+	Short: "Export workspace definitions for another machine",
+	Long: `Export workspace definitions into a single archive for 'gitws import'.
+
+The archive contains config.yaml entries for the selected workspaces and,
+with --include-keys, their SSH key pairs. Private keys are encrypted with
+a passphrase (prompted, or taken from GWS_PASSPHRASE).
+
+Examples:
+  gitws export workspaces.tar.gz
+  gitws export workspaces.tar.gz --include-keys
+  gitws export work.tar.gz --workspace work`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().BoolVar(&exportIncludeKeys, "include-keys", false, "Include SSH key pairs (private keys are encrypted)")
+	exportCmd.Flags().StringSliceVar(&exportWorkspaces, "workspace", nil, "Workspaces to export (default: all)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	archivePath := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := exportWorkspaces
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return fmt.Errorf("no workspaces to export")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	bundle := &archive.Bundle{
+		Manifest: archive.Manifest{
+			Version:     archive.FormatVersion,
+			CreatedAt:   time.Now().UTC(),
+			Home:        home,
+			IncludeKeys: exportIncludeKeys,
+		},
+		Config: &config.File{Workspaces: make(map[string]config.Workspace)},
+		Keys:   make(map[string]archive.Key),
+	}
+
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+		bundle.Config.SetWorkspace(name, ws)
+
+		if exportIncludeKeys {
+			priv, err := os.ReadFile(ws.SSHKey)
+			if err != nil {
+				return fmt.Errorf("failed to read private key for %q: %w", name, err)
+			}
+			pub, err := os.ReadFile(ws.SSHKey + ".pub")
+			if err != nil {
+				return fmt.Errorf("failed to read public key for %q: %w", name, err)
+			}
+			bundle.Keys[name] = archive.Key{Public: pub, Private: priv}
+		}
+	}
+
+	var passphrase string
+	if exportIncludeKeys {
+		passphrase, err = prompt.Passphrase("Passphrase to encrypt private keys")
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("a non-empty passphrase is required with --include-keys")
+		}
+		if os.Getenv("GWS_PASSPHRASE") == "" {
+			again, err := prompt.Passphrase("Repeat passphrase")
+			if err != nil {
+				return err
+			}
+			if again != passphrase {
+				return fmt.Errorf("passphrases do not match")
+			}
+		}
+	}
+
+	if err := archive.Write(archivePath, bundle, passphrase); err != nil {
+		return err
+	}
+
+	keys := "not included"
+	if exportIncludeKeys {
+		keys = "included (encrypted)"
+	}
+
+	summary := prompt.SummaryData{
+		Title: i18n.T("✓ Exported %d workspace(s)", len(names)),
+		Items: []prompt.SummaryItem{
+			{Label: "Archive", Value: archivePath, Icon: "📦"},
+			{Label: "Workspaces", Value: strings.Join(names, ", "), Icon: "📁"},
+			{Label: "Keys", Value: keys, Icon: "🔑"},
+		},
+		NextSteps: []string{
+			fmt.Sprintf("Copy %s to the new machine", archivePath),
+			fmt.Sprintf("Run 'gitws import %s' there", archivePath),
+		},
+	}
+
+	return prompt.ShowSummary(summary)
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/cache"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fuzzy"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	findWorkspace string
+	findFirst     bool
+	findLimit     int
+)
+
+// findCmd represents the find command
+var findCmd = &cobra.Command{
+	Use:   "find <pattern...>",
+	Short: "Find cloned repositories by fuzzy name",
+	Long: `Search the repositories in the cache for ones matching a pattern and
+print their paths, best match first. Each repository is matched as
+"<workspace> <org>/<repo>" (or its path under the workspace root when it
+has no origin), so letters only need to appear in order: "wapi" finds
+work's acme/api. Every space-separated word must match.
+
+The search reads the cache only; workspaces that were never scanned are
+scanned once. Run 'gitws cache refresh' after cloning outside gitws.
+
+With the shell hook from 'gitws shell-init', 'gwcd <pattern>' changes to
+the best match.
+
+Examples:
+  gitws find api
+  gitws find work acme/api
+  gitws find web --workspace personal --first
+  cd "$(gitws find billing --first)"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFind,
+}
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+
+	findCmd.Flags().StringVar(&findWorkspace, "workspace", "", "Only search this workspace")
+	findCmd.Flags().BoolVar(&findFirst, "first", false, "Print only the best match")
+	findCmd.Flags().IntVar(&findLimit, "limit", 20, "Print at most this many matches (0 for all)")
+	_ = findCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaces)
+}
+
+// foundRepo is a repository matching a find pattern, as --json prints it
+type foundRepo struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name,omitempty"`
+	Path      string `json:"path"`
+	Score     int    `json:"score"`
+}
+
+func runFind(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if findWorkspace != "" {
+		if _, exists := cfg.GetWorkspace(findWorkspace); !exists {
+			return fmt.Errorf("workspace %q not found", findWorkspace)
+		}
+	}
+
+	repos, err := findIndex(cfg)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories in the cache; run 'gitws cache refresh'")
+	}
+
+	candidates := make([]string, len(repos))
+	for i, r := range repos {
+		candidates[i] = r.Workspace + " " + r.label(cfg)
+	}
+	pattern := strings.Join(args, " ")
+	matches := fuzzy.Rank(pattern, candidates)
+	if len(matches) == 0 {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  No repository matches %q\n"), pattern)
+		return exitCode(cmd, ExitWarnings)
+	}
+
+	limit := findLimit
+	if findFirst {
+		limit = 1
+	}
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	if jsonOutput {
+		found := make([]foundRepo, 0, len(matches))
+		for _, m := range matches {
+			r := repos[m.Index]
+			r.Score = m.Score
+			found = append(found, r)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(found)
+	}
+
+	for _, m := range matches {
+		fmt.Println(repos[m.Index].Path)
+	}
+	return nil
+}
+
+// label is what a found repository is matched by besides its workspace:
+// its ORG/REPO, or without one its path under the workspace root
+func (r foundRepo) label(cfg *config.File) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if root := cfg.Workspaces[r.Workspace].Root; root != "" {
+		if rel, err := filepath.Rel(root, r.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(r.Path)
+}
+
+// findIndex returns the cached repositories to search, scanning and
+// caching workspaces with a root that were never scanned
+func findIndex(cfg *config.File) ([]foundRepo, error) {
+	data, err := cache.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	names := cfg.ListWorkspaces()
+	if findWorkspace != "" {
+		names = []string{findWorkspace}
+	}
+	sort.Strings(names)
+
+	scanned := false
+	var repos []foundRepo
+	for _, name := range names {
+		ws, entry := cfg.Workspaces[name], data.Workspace(name)
+		if ws.Root != "" && entry.Scanned.IsZero() {
+			if found, err := cacheRepositories(cfg, name); err == nil {
+				entry.SetRepos(found, time.Now())
+				scanned = true
+			}
+		}
+		for _, r := range entry.Repos {
+			repos = append(repos, foundRepo{Workspace: name, Name: r.Name, Path: r.Path})
+		}
+	}
+
+	// The cache only speeds up later searches, so failing to save is fine
+	if scanned {
+		_ = data.Save()
+	}
+	return repos, nil
+}
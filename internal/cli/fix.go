@@ -1,14 +1,22 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gitworkspaces/gitws/internal/config"
 	"github.com/gitworkspaces/gitws/internal/git"
 	"github.com/gitworkspaces/gitws/internal/prompt"
 	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/stats"
+	"github.com/gitworkspaces/gitws/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +25,8 @@ var (
 	fixEnableGuards  bool
 	fixRewriteRemote bool
 	fixSetIdentity   bool
+	fixWorkspace     string
+	fixIdentity      string
 )
 
 // fixCmd represents the fix command
@@ -30,10 +40,23 @@ This command can:
 - Set proper user identity configuration
 - Install guard hooks to prevent identity mixing
 
+Without --workspace, the remote is rewritten to the workspace serving its
+host; when several do, the one whose root holds the repository. If that
+still leaves a choice you are asked, or with --yes the command fails.
+
+A .gitws.yaml at the top of the repository can pin the workspace and
+identity, or declare the repository unmanaged so fix leaves it alone.
+
+--identity switches the repository to one of the workspace's additional
+identities (see 'gitws identity'): its remote is pointed at the identity's
+alias and its user.name and user.email are set to the identity's.
+
 Examples:
   gitws fix
   gitws fix /path/to/repo --yes --enable-guards
-  gitws fix --rewrite-remote --set-identity`,
+  gitws fix --rewrite-remote --set-identity
+  gitws fix --workspace work --set-identity /path/to/repo
+  gitws fix --workspace work --identity bot`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFix,
 }
@@ -45,6 +68,8 @@ func init() {
 	fixCmd.Flags().BoolVar(&fixEnableGuards, "enable-guards", false, "Install guard hooks")
 	fixCmd.Flags().BoolVar(&fixRewriteRemote, "rewrite-remote", false, "Rewrite remote URL to use workspace alias")
 	fixCmd.Flags().BoolVar(&fixSetIdentity, "set-identity", false, "Set user identity from workspace config")
+	fixCmd.Flags().StringVar(&fixWorkspace, "workspace", "", "Workspace to apply (default: detected from remote and path)")
+	fixCmd.Flags().StringVar(&fixIdentity, "identity", "", "Workspace identity to use (default: the one the remote uses)")
 }
 
 func runFix(cmd *cobra.Command, args []string) error {
@@ -72,6 +97,43 @@ func runFix(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// .gitws.yaml stands in for flags that are not given
+	repo, err := config.LoadRepo(gitRoot)
+	if err != nil {
+		return err
+	}
+	if repo.Unmanaged && fixWorkspace == "" {
+		fmt.Printf(prompt.Text("ℹ️  %s declares the repository unmanaged; nothing to fix (pass --workspace to fix it anyway)\n"), config.RepoFileName)
+		return nil
+	}
+	if fixWorkspace == "" && fixIdentity == "" {
+		fixWorkspace, fixIdentity = repo.Workspace, repo.Identity
+	}
+
+	if fixWorkspace != "" {
+		if _, exists := cfg.GetWorkspace(fixWorkspace); !exists {
+			return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", fixWorkspace, fixWorkspace)
+		}
+	}
+	if fixIdentity != "" {
+		if fixWorkspace == "" {
+			fixWorkspace = workspaceForRepo(cfg, gitRoot)
+		}
+		if fixWorkspace == "" {
+			return fmt.Errorf("--identity needs the workspace; pass --workspace")
+		}
+		if _, err := cfg.Workspaces[fixWorkspace].ForIdentity(fixIdentity); err != nil {
+			return fmt.Errorf("workspace %q: %w", fixWorkspace, err)
+		}
+	}
+
+	// The organization policy's rules are fixed even when --yes does not
+	// ask for them
+	orgPolicy, err := loadOrgPolicy()
+	if err != nil {
+		return err
+	}
+
 	// Determine what to fix
 	var fixes []string
 	var changes []string
@@ -79,11 +141,24 @@ func runFix(cmd *cobra.Command, args []string) error {
 	// Check remote URL
 	remoteURL, err := git.GetRemoteURL(gitRoot)
 	if err == nil {
-		workspace, needsRewrite := checkRemoteURL(remoteURL, cfg)
-		if needsRewrite && (fixRewriteRemote || !fixYes) {
-			fixes = append(fixes, "rewrite-remote")
-			if workspace != "" {
-				changes = append(changes, fmt.Sprintf("Rewrite remote URL to use workspace '%s' alias", workspace))
+		workspace, needsRewrite := checkRemoteURL(remoteURL, cfg, gitRoot)
+		if needsRewrite && (fixRewriteRemote || !fixYes || orgPolicy.BansRemote(remoteURL)) {
+			// Settle the workspace before anything is changed, so the
+			// rewrite and the identity agree
+			if workspace == "" {
+				workspace, err = rewriteWorkspace(cfg, remoteURL, gitRoot)
+				if err != nil {
+					return err
+				}
+			}
+			fixWorkspace = workspace
+			// The workspace found may already be served by the remote as
+			// it is, over HTTPS
+			if workspace != "" && remoteNeedsRewrite(remoteURL, fixTarget(cfg, gitRoot)) {
+				fixes = append(fixes, "rewrite-remote")
+				changes = append(changes, rewriteRemoteChange(fixTarget(cfg, gitRoot), workspace))
+			} else if workspace == "" {
+				fmt.Printf(prompt.Text("⚠️  No workspace serves %s; leaving the remote as is (pass --workspace to choose one)\n"), remoteURL)
 			}
 		}
 	}
@@ -91,20 +166,32 @@ func runFix(cmd *cobra.Command, args []string) error {
 	// Check user identity
 	userName, _ := git.GetLocalConfig(gitRoot, "user.name")
 	userEmail, _ := git.GetLocalConfig(gitRoot, "user.email")
-	if (userName == "" || userEmail == "") && (fixSetIdentity || !fixYes) {
+	wrongIdentity := fixWorkspace != "" && userEmail != fixTarget(cfg, gitRoot).Email
+	if (userName == "" || userEmail == "" || wrongIdentity) && (fixSetIdentity || !fixYes) {
 		fixes = append(fixes, "set-identity")
 		changes = append(changes, "Set user identity from workspace configuration")
 	}
 
 	// Check guard hooks
 	hooksInstalled, _ := git.CheckHooksInstalled(gitRoot)
-	if !hooksInstalled && (fixEnableGuards || !fixYes) {
+	if !hooksInstalled && (fixEnableGuards || !fixYes || orgPolicy.HooksRequired()) {
 		fixes = append(fixes, "enable-guards")
 		changes = append(changes, "Install guard hooks")
 	}
 
+	// Signing is a workspace setting, which fix does not change
+	name := fixWorkspace
+	if name == "" {
+		name = workspaceForRepo(cfg, gitRoot)
+	}
+	if name != "" {
+		if ws := cfg.Workspaces[name]; orgPolicy.RequiresSigning(ws.HostName) && (ws.Signing == "" || ws.Signing == "none") {
+			fmt.Printf(prompt.Text("⚠️  Organization policy requires signed commits on %s; run 'gitws init %s --force --email %s --host-name %s --signing ssh'\n"), ws.HostName, name, ws.Email, ws.HostName)
+		}
+	}
+
 	if len(fixes) == 0 {
-		fmt.Println("✓ No fixes needed. Repository is properly configured.")
+		fmt.Println(prompt.Text("✓ No fixes needed. Repository is properly configured."))
 		return nil
 	}
 
@@ -134,23 +221,26 @@ func runFix(cmd *cobra.Command, args []string) error {
 		switch fix {
 		case "rewrite-remote":
 			if err := applyRewriteRemote(gitRoot, cfg); err != nil {
-				fmt.Printf("❌ Failed to rewrite remote: %v\n", err)
+				fmt.Printf(prompt.Text("❌ Failed to rewrite remote: %v\n"), err)
 			} else {
 				appliedFixes = append(appliedFixes, "Remote URL rewritten")
+				recordUsage(stats.PrefixFix + fix)
 			}
 
 		case "set-identity":
 			if err := applySetIdentity(gitRoot, cfg); err != nil {
-				fmt.Printf("❌ Failed to set identity: %v\n", err)
+				fmt.Printf(prompt.Text("❌ Failed to set identity: %v\n"), err)
 			} else {
 				appliedFixes = append(appliedFixes, "User identity set")
+				recordUsage(stats.PrefixFix + fix)
 			}
 
 		case "enable-guards":
-			if err := applyEnableGuards(gitRoot); err != nil {
-				fmt.Printf("❌ Failed to install guard hooks: %v\n", err)
+			if err := applyEnableGuards(gitRoot, cfg); err != nil {
+				fmt.Printf(prompt.Text("❌ Failed to install guard hooks: %v\n"), err)
 			} else {
 				appliedFixes = append(appliedFixes, "Guard hooks installed")
+				recordUsage(stats.PrefixFix + fix)
 			}
 		}
 	}
@@ -158,9 +248,9 @@ func runFix(cmd *cobra.Command, args []string) error {
 	// Show summary
 	if len(appliedFixes) > 0 {
 		fmt.Println()
-		fmt.Println("✓ Applied fixes:")
+		fmt.Println(prompt.Text("✓ Applied fixes:"))
 		for _, fix := range appliedFixes {
-			fmt.Printf("   • %s\n", fix)
+			fmt.Printf(prompt.Text("   • %s\n"), fix)
 		}
 		fmt.Println()
 		fmt.Println("Run 'gitws status' to verify the changes.")
@@ -169,7 +259,11 @@ func runFix(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func checkRemoteURL(remoteURL string, cfg *config.File) (string, bool) {
+func checkRemoteURL(remoteURL string, cfg *config.File, gitRoot string) (string, bool) {
+	if fixWorkspace != "" {
+		return fixWorkspace, remoteNeedsRewrite(remoteURL, fixTarget(cfg, gitRoot))
+	}
+
 	if !strings.HasPrefix(remoteURL, "git@") {
 		return "", true // Needs rewrite to SSH
 	}
@@ -179,95 +273,212 @@ func checkRemoteURL(remoteURL string, cfg *config.File) (string, bool) {
 		return "", true // Needs rewrite
 	}
 
+	// The alias of a workspace that moved to HTTPS goes back to its host
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		if ws.Transport != workspace.TransportHTTPS {
+			continue
+		}
+		for _, alias := range ws.Aliases() {
+			if alias == host {
+				return name, true
+			}
+		}
+	}
+
 	// Check if this is already a gitws alias
 	if strings.Contains(host, "gws") || strings.Contains(host, "gitws") {
 		return "", false // Already using gitws alias
 	}
 
-	// Try to find matching workspace
-	for name, ws := range cfg.Workspaces {
-		if strings.Contains(host, ws.HostName) {
-			return name, true // Found workspace, needs rewrite
+	// A host some workspace serves needs its alias; which workspace is
+	// decided by rewriteWorkspace
+	for _, ws := range cfg.Workspaces {
+		if _, ok := ws.HostAliases()[host]; ok {
+			return "", true
 		}
 	}
 
 	return "", false // No workspace found, leave as is
 }
 
-func applyRewriteRemote(gitRoot string, cfg *config.File) error {
-	remoteURL, err := git.GetRemoteURL(gitRoot)
+// rewriteWorkspace picks the workspace a remote is rewritten to when none
+// is given: the workspace serving the remote's host, narrowed to the one
+// whose root holds the repository and then by the org map when several
+// do, or "" when none serves it. Ambiguity is never settled by guessing: the user picks, and --yes
+// fails.
+func rewriteWorkspace(cfg *config.File, remoteURL, gitRoot string) (string, error) {
+	host, err := rewrite.ExtractHost(remoteURL)
 	if err != nil {
-		return fmt.Errorf("failed to get remote URL: %w", err)
+		return "", nil // Not a URL gitws can rewrite
 	}
 
-	// Parse the URL to get org/repo
-	org, repo, _, err := rewrite.RewriteURL(remoteURL, "dummy")
-	if err != nil {
-		return fmt.Errorf("failed to parse remote URL: %w", err)
+	candidates := hostWorkspaces(cfg, host)
+	if len(candidates) > 1 {
+		var best, bestRoot string
+		for _, name := range candidates {
+			ws := cfg.Workspaces[name]
+			if followsRemote(ws) || ws.Root == "" {
+				continue
+			}
+			if (gitRoot == ws.Root || isWithin(gitRoot, ws.Root)) && len(ws.Root) > len(bestRoot) {
+				best, bestRoot = name, ws.Root
+			}
+		}
+		if best != "" {
+			candidates = []string{best}
+		} else if name := orgWorkspace(cfg, remoteURL, candidates); name != "" {
+			candidates = []string{name}
+		}
 	}
 
-	// Find the appropriate workspace
-	var targetWorkspace config.Workspace
-	var found bool
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		return candidates[0], nil
+	}
 
-	// Try to match by hostname
-	if strings.HasPrefix(remoteURL, "git@") {
-		host, err := rewrite.ExtractHostFromSSHURL(remoteURL)
-		if err == nil {
-			for _, ws := range cfg.Workspaces {
-				if host == ws.HostName {
-					targetWorkspace = ws
-					found = true
-					break
-				}
-			}
-		}
+	if fixYes {
+		return "", fmt.Errorf("%s is served by workspaces %s and the repository is in none of their roots; pass --workspace to choose one", host, strings.Join(candidates, ", "))
 	}
+	choice, err := prompt.Choose(fmt.Sprintf("Several workspaces serve %s. Which one should %s use?", host, gitRoot), candidates)
+	if err != nil {
+		return "", fmt.Errorf("%w; pass --workspace to choose one", err)
+	}
+	return candidates[choice], nil
+}
 
-	// If not found by hostname, try to match by provider
-	if !found {
-		for _, ws := range cfg.Workspaces {
-			if ws.Provider != "" {
-				// This is a provider-based workspace
-				targetWorkspace = ws
-				found = true
-				break
-			}
+// hostWorkspaces returns the workspaces, in order, that serve host or use
+// it as one of their aliases
+func hostWorkspaces(cfg *config.File, host string) []string {
+	var names []string
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		_, served := ws.HostAliases()[host]
+		for _, alias := range ws.Aliases() {
+			served = served || alias == host
+		}
+		for _, retired := range ws.RetiredAliases {
+			served = served || retired.Alias == host
 		}
+		if served {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// workspaceAlias returns the SSH alias of ws for the host input points
+// at. A host ws does not serve, directly or through one of its current or
+// retired aliases, reports false: its remotes belong to another account.
+func workspaceAlias(ws config.Workspace, input string) (string, bool) {
+	alias, served := rewrite.PickAlias(input, ws.HostAliases(), ws.SSHAlias)
+	if served {
+		return alias, true
+	}
+	host, _ := rewrite.ExtractHost(input)
+	for _, a := range ws.Aliases() {
+		served = served || a == host
+	}
+	for _, retired := range ws.RetiredAliases {
+		served = served || retired.Alias == host
+	}
+	return alias, served
+}
+
+// workspaceRemote returns the org, repository and the remote URL of input
+// (a URL or ORG/REPO) for ws: its SSH alias for the host input points at,
+// or the host itself over HTTPS when ws uses the https transport. A host
+// ws does not serve is an error.
+func workspaceRemote(ws config.Workspace, input string) (org, repo, remoteURL string, err error) {
+	alias, served := workspaceAlias(ws, input)
+	if !served {
+		host, _ := rewrite.ExtractHost(input)
+		return "", "", "", fmt.Errorf("%s is not a host of the workspace; serve it with 'gitws init --extra-host'", host)
+	}
+	org, repo, remoteURL, err = rewrite.RewriteURL(input, alias)
+	if err != nil {
+		return "", "", "", err
+	}
+	if ws.Transport == workspace.TransportHTTPS {
+		remoteURL = rewrite.HTTPSURL(rewrite.PickHost(input, ws.HostAliases(), ws.HostName), org, repo)
+	}
+	return org, repo, remoteURL, nil
+}
+
+// remoteNeedsRewrite reports whether remoteURL does not yet reach its
+// host the way ws does: through the workspace alias, or over HTTPS
+func remoteNeedsRewrite(remoteURL string, ws config.Workspace) bool {
+	host, _ := rewrite.ExtractHost(remoteURL)
+	if _, served := workspaceAlias(ws, remoteURL); !served {
+		return false // Another host's remote, left alone
+	}
+	if ws.Transport == workspace.TransportHTTPS {
+		return !strings.HasPrefix(remoteURL, "https://") || host != rewrite.PickHost(remoteURL, ws.HostAliases(), ws.HostName)
+	}
+	alias, served := workspaceAlias(ws, remoteURL)
+	return served && host != alias
+}
+
+// rewriteRemoteChange describes the remote rewrite to ws for the
+// confirmation list
+func rewriteRemoteChange(ws config.Workspace, name string) string {
+	if ws.Transport == workspace.TransportHTTPS {
+		return fmt.Sprintf("Rewrite remote URL to HTTPS for workspace '%s'", name)
 	}
+	return fmt.Sprintf("Rewrite remote URL to use workspace '%s' alias", name)
+}
 
-	if !found {
+func applyRewriteRemote(gitRoot string, cfg *config.File) error {
+	remoteURL, err := git.GetRemoteURL(gitRoot)
+	if err != nil {
+		return fmt.Errorf("failed to get remote URL: %w", err)
+	}
+
+	// runFix settles the workspace before any fix is applied
+	if _, found := cfg.GetWorkspace(fixWorkspace); !found {
 		return fmt.Errorf("no suitable workspace found for remote URL")
 	}
+	targetWorkspace := fixTarget(cfg, gitRoot)
 
-	// Build new SSH URL
-	newURL := fmt.Sprintf("git@%s:%s/%s.git", targetWorkspace.SSHAlias, org, repo)
+	// Build the new URL, keeping the host the remote pointed at
+	_, _, newURL, err := workspaceRemote(targetWorkspace, remoteURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse remote URL: %w", err)
+	}
 
 	// Update remote
 	if err := git.SetRemoteURL(gitRoot, newURL); err != nil {
 		return fmt.Errorf("failed to set remote URL: %w", err)
 	}
 
-	fmt.Printf("✓ Rewritten remote URL: %s\n", newURL)
+	// Outside the workspace root the workspace gitconfig is not included,
+	// so the repository needs its own credential settings
+	if targetWorkspace.Transport == workspace.TransportHTTPS && (targetWorkspace.Root == "" || !isWithin(gitRoot, targetWorkspace.Root)) {
+		if err := setLocalCredentialConfig(gitRoot, fixWorkspace, targetWorkspace); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(prompt.Text("✓ Rewritten remote URL: %s\n"), newURL)
 	return nil
 }
 
 func applySetIdentity(gitRoot string, cfg *config.File) error {
-	// Find workspace by repository path
-	var targetWorkspace config.Workspace
-	var found bool
-
-	for _, ws := range cfg.Workspaces {
-		if strings.HasPrefix(gitRoot, ws.Root) {
-			targetWorkspace = ws
-			found = true
-			break
-		}
+	// Use the requested workspace, or the one the repository belongs to
+	name := fixWorkspace
+	if name == "" {
+		name = workspaceForRepo(cfg, gitRoot)
 	}
 
-	if !found {
+	if _, found := cfg.GetWorkspace(name); !found {
 		return fmt.Errorf("no workspace found for repository path")
 	}
+	targetWorkspace := repoIdentity(cfg, name, gitRoot)
+	if name == fixWorkspace {
+		targetWorkspace = fixTarget(cfg, gitRoot)
+	}
 
 	// Set user identity
 	if err := git.SetLocalConfig(gitRoot, "user.name", targetWorkspace.Name); err != nil {
@@ -300,15 +511,171 @@ func applySetIdentity(gitRoot string, cfg *config.File) error {
 		}
 	}
 
-	fmt.Printf("✓ Set user identity: %s <%s>\n", targetWorkspace.Name, targetWorkspace.Email)
+	fmt.Printf(prompt.Text("✓ Set user identity: %s <%s>\n"), targetWorkspace.Name, targetWorkspace.Email)
 	return nil
 }
 
-func applyEnableGuards(gitRoot string) error {
-	if err := git.InstallHooks(gitRoot); err != nil {
+func applyEnableGuards(gitRoot string, cfg *config.File) error {
+	if err := git.InstallHooks(gitRoot, true); err != nil {
 		return fmt.Errorf("failed to install hooks: %w", err)
 	}
 
-	fmt.Println("✓ Installed guard hooks")
+	fmt.Println(prompt.Text("✓ Installed guard hooks"))
 	return nil
 }
+
+// workspaceForRepo resolves the workspace a repository belongs to: as
+// pinned by its .gitws.yaml, then by the SSH alias in its origin remote, then by the deepest workspace root that
+// contains it, then by the remote host when a single workspace uses it or
+// the org map picks one of those that do.
+// It returns "" when the repository cannot be attributed.
+func workspaceForRepo(cfg *config.File, gitRoot string) string {
+	// .gitws.yaml has the last word: a pinned workspace, or none at all
+	if repo, err := config.LoadRepo(gitRoot); err == nil {
+		if repo.Unmanaged {
+			return ""
+		}
+		if _, exists := cfg.GetWorkspace(repo.Workspace); exists {
+			return repo.Workspace
+		}
+	}
+
+	remoteURL, _ := git.GetRemoteURL(gitRoot)
+	host, _ := rewrite.ExtractHost(remoteURL)
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if host == "" {
+			break
+		}
+		ws := cfg.Workspaces[name]
+		for _, alias := range ws.Aliases() {
+			if alias == host {
+				return name
+			}
+		}
+		for _, retired := range ws.RetiredAliases {
+			if retired.Alias == host {
+				return name
+			}
+		}
+	}
+
+	var best, bestRoot string
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		if followsRemote(ws) || ws.Root == "" {
+			continue
+		}
+		inRoot := gitRoot == ws.Root || strings.HasPrefix(gitRoot, strings.TrimSuffix(ws.Root, string(filepath.Separator))+string(filepath.Separator))
+		if inRoot && len(ws.Root) > len(bestRoot) {
+			best, bestRoot = name, ws.Root
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	if host == "" {
+		return ""
+	}
+	matches := hostWorkspaces(cfg, host)
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	return orgWorkspace(cfg, remoteURL, matches) // "" when still ambiguous
+}
+
+// orgWorkspace returns the workspace config.yaml's org map assigns to the
+// repository at remoteURL, provided it is one of candidates
+func orgWorkspace(cfg *config.File, remoteURL string, candidates []string) string {
+	org, repo, _, err := rewrite.RewriteURL(remoteURL, "")
+	if err != nil {
+		return ""
+	}
+	if name := rewrite.MatchOwner(cfg.Orgs, org, repo); slices.Contains(candidates, name) {
+		return name
+	}
+	return ""
+}
+
+// repoIdentity returns a workspace as seen by the repository at gitRoot:
+// through the identity its .gitws.yaml selects, or else the one whose
+// alias its origin uses, if any
+func repoIdentity(cfg *config.File, name, gitRoot string) config.Workspace {
+	ws := cfg.Workspaces[name]
+	if repo, err := config.LoadRepo(gitRoot); err == nil && repo.Identity != "" && (repo.Workspace == "" || repo.Workspace == name) {
+		if identity, err := ws.ForIdentity(repo.Identity); err == nil {
+			return identity
+		}
+	}
+
+	remoteURL, _ := git.GetRemoteURL(gitRoot)
+	host, _ := rewrite.ExtractHost(remoteURL)
+	if id := ws.IdentityForAlias(host); id != "" {
+		ws, _ = ws.ForIdentity(id)
+	}
+	return ws
+}
+
+// fixTarget returns the --workspace workspace as seen through --identity,
+// or through the identity the repository already uses
+func fixTarget(cfg *config.File, gitRoot string) config.Workspace {
+	if fixIdentity != "" {
+		ws, _ := cfg.Workspaces[fixWorkspace].ForIdentity(fixIdentity)
+		return ws
+	}
+	return repoIdentity(cfg, fixWorkspace, gitRoot)
+}
+
+// fixCommand builds the exact 'gitws fix' invocation applying actions to
+// the repository at gitRoot, pinned to workspaceName when known
+func fixCommand(workspaceName, gitRoot string, actions ...string) []string {
+	args := []string{"gitws", "fix"}
+	if workspaceName != "" {
+		args = append(args, "--workspace", workspaceName)
+	}
+	for _, action := range actions {
+		args = append(args, "--"+action)
+	}
+	return append(args, gitRoot)
+}
+
+// fixActions are the fix flags other commands may ask a fix process for
+var fixActions = map[string]bool{
+	"rewrite-remote": true,
+	"set-identity":   true,
+	"enable-guards":  true,
+}
+
+// fixTimeout bounds a fix process
+const fixTimeout = time.Minute
+
+// fixProcess runs 'gitws fix --yes' with actions on the repository at
+// gitRoot in a child process and returns its output. fix works on the
+// global flags and reloads what it changes, which long-running commands
+// cannot share between repositories.
+func fixProcess(ctx context.Context, workspaceName, identity, gitRoot string, actions ...string) (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate gitws: %w", err)
+	}
+	args := []string{"fix", "--yes", "--plain"}
+	if workspaceName != "" {
+		args = append(args, "--workspace", workspaceName)
+	}
+	if identity != "" {
+		args = append(args, "--identity", identity)
+	}
+	for _, action := range actions {
+		args = append(args, "--"+action)
+	}
+	args = append(args, gitRoot)
+
+	ctx, cancel := context.WithTimeout(ctx, fixTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, self, args...).CombinedOutput()
+	return string(output), err
+}
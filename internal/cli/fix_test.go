@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestRemoteNeedsRewrite(t *testing.T) {
+	ws := config.Workspace{
+		HostName:       "github.com",
+		SSHAlias:       "github-com-work",
+		Hosts:          []config.HostAlias{{HostName: "gitlab.acme.com", SSHAlias: "gitlab-acme-com-work"}},
+		RetiredAliases: []config.RetiredAlias{{Alias: "github-com-old"}},
+	}
+
+	tests := []struct {
+		remote   string
+		expected bool
+	}{
+		{"git@github.com:acme/app.git", true},
+		{"git@github-com-work:acme/app.git", false},
+		{"git@github-com-old:acme/app.git", true},
+		{"https://gitlab.acme.com/platform/app.git", true},
+		{"git@gitlab-acme-com-work:platform/app.git", false},
+		{"git@bitbucket.org:acme/app.git", false},
+		{"https://bitbucket.org/acme/app.git", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			if result := remoteNeedsRewrite(tt.remote, ws); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	if _, _, _, err := workspaceRemote(ws, "git@bitbucket.org:acme/app.git"); err == nil {
+		t.Error("expected an error for a host the workspace does not serve")
+	}
+	if _, _, remote, err := workspaceRemote(ws, "git@github-com-old:acme/app.git"); err != nil || remote != "git@github-com-work:acme/app.git" {
+		t.Errorf("expected a retired alias to move to the current one, got %q, %v", remote, err)
+	}
+}
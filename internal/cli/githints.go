@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+)
+
+// withGitHint adds advice for a failed git operation through a workspace,
+// on a line of its own, when the failure is one gitws recognizes
+func withGitHint(err error, workspaceName string, ws config.Workspace) error {
+	hint := gitHint(err, workspaceName, ws)
+	if hint == "" {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, prompt.Text("👉 "+hint))
+}
+
+// gitHint returns advice for err against the workspace's host, or ""
+func gitHint(err error, workspaceName string, ws config.Workspace) string {
+	switch {
+	case errors.Is(err, git.ErrAuthFailed):
+		hint := fmt.Sprintf("%s did not accept the key of workspace %s. Show it with 'gitws keys show %s'", ws.HostName, workspaceName, workspaceName)
+		if url := keySettingsURL(ws.HostName); url != "" {
+			return hint + " and add it at " + url
+		}
+		return hint + " and add it to your account"
+	case errors.Is(err, git.ErrRepoNotFound):
+		switch provider.Detect(ws.HostName) {
+		case "github", "bitbucket":
+			return fmt.Sprintf("%s reports private repositories the account cannot access as not found; check the name and that the account of %s (%s) has access", ws.HostName, workspaceName, ws.Email)
+		case "gitlab":
+			return fmt.Sprintf("%s reports projects the account cannot access as not found; check the path, including subgroups, and that the account of %s (%s) is a member", ws.HostName, workspaceName, ws.Email)
+		}
+		return "check the repository name and that the account has access"
+	case errors.Is(err, git.ErrHostUnreached):
+		return fmt.Sprintf("%s could not be reached; check the network or VPN, then run 'gitws doctor' to test the SSH connection", ws.HostName)
+	case errors.Is(err, git.ErrRemoteExists):
+		return "the repository already has this remote; change its URL with 'git remote set-url'"
+	}
+	return ""
+}
+
+// keySettingsURL returns the page where a provider account adds SSH keys,
+// or "" when the provider is not known
+func keySettingsURL(hostName string) string {
+	switch provider.Detect(hostName) {
+	case "github":
+		return "https://" + hostName + "/settings/keys"
+	case "gitlab":
+		return "https://" + hostName + "/-/user_settings/ssh_keys"
+	case "bitbucket":
+		return "https://bitbucket.org/account/settings/ssh-keys/"
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// gatedGitCommands are the git commands 'gitws git' checks the
+// repository before: those that record history or exchange it with a
+// remote
+var gatedGitCommands = map[string]bool{
+	"commit": true, "merge": true, "rebase": true, "cherry-pick": true,
+	"revert": true, "tag": true, "am": true,
+	"fetch": true, "pull": true, "push": true,
+}
+
+// gitCmd represents the git command
+var gitCmd = &cobra.Command{
+	Use:   "git -- <git arguments>",
+	Short: "Run git after checking the repository uses its workspace identity",
+	Long: `Run git with the given arguments, after checking that the repository
+commits as its workspace identity and reaches origin the way its workspace
+does. This is an explicit alternative to the guard hooks.
+
+Commands that record or exchange history (commit, merge, rebase,
+cherry-pick, revert, tag, am, fetch, pull, push) are refused when the check
+fails, with the 'gitws fix' command that repairs the repository. Git
+aliases are followed to the command they run. Everything else, and any
+command outside a repository, in an unmanaged one or in one no workspace
+serves, runs unchecked. git's exit code is passed on.
+
+'gitws shell-init <shell> --git-wrapper' also defines a git shell function
+that goes through 'gitws git' while a workspace environment is loaded,
+i.e. inside workspace roots. 'command git' skips the check.
+
+Examples:
+  gitws git -- push origin main
+  gitws git -- -C ~/code/work/acme/app commit -m "Fix typo"
+  echo 'eval "$(gitws shell-init bash --git-wrapper)"' >> ~/.bashrc`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGit,
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+}
+
+func runGit(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() != 0 {
+		return fmt.Errorf("usage: gitws git -- <git arguments>")
+	}
+
+	subcommand, gitRoot, problems := gitGate(args)
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, prompt.Text("❌ gitws: refusing to run 'git %s' in %s:\n"), subcommand, gitRoot)
+		for _, issue := range problems {
+			fmt.Fprintf(os.Stderr, prompt.Text("   • %s\n"), issue.Message)
+			if fix := issue.FixText(); fix != "" {
+				fmt.Fprintf(os.Stderr, "     Fix: %s\n", fix)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "Run 'command git' to skip the check.")
+		return exitCode(cmd, ExitErrors)
+	}
+
+	gitPath, _, err := git.Locate()
+	if err != nil {
+		return err
+	}
+	child := exec.Command(gitPath, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// git gets Ctrl-C too, being in the same foreground process group, and
+	// decides itself what it means. Catching it here rather than ignoring
+	// it keeps gitws waiting for git's exit status, while git still starts
+	// with the default disposition.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitCode(cmd, childExitCode(exitErr))
+		}
+		return fmt.Errorf("failed to run git: %w", err)
+	}
+	return nil
+}
+
+// childExitCode returns the exit status of a finished child the way a
+// shell reports it: 128 plus the signal number when a signal killed it
+func childExitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// gitGate checks the repository a git command line runs in, and returns
+// the command, the repository and what is wrong with it. Commands that
+// are not gated, and repositories gitws does not manage, pass.
+func gitGate(args []string) (subcommand, gitRoot string, problems []prompt.Issue) {
+	dirs, subcommand := git.ParseCommandLine(args)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return subcommand, "", nil
+	}
+	for _, d := range dirs {
+		if filepath.IsAbs(d) {
+			dir = d
+		} else {
+			dir = filepath.Join(dir, d)
+		}
+	}
+
+	if !gatedGitCommands[subcommand] {
+		// An alias that runs a git command is that command; shell
+		// aliases are left alone
+		alias, err := git.GetConfig(dir, "alias."+subcommand)
+		if err != nil || strings.HasPrefix(alias, "!") || len(strings.Fields(alias)) == 0 {
+			return subcommand, "", nil
+		}
+		if resolved := strings.Fields(alias)[0]; gatedGitCommands[resolved] {
+			subcommand = resolved
+		} else {
+			return subcommand, "", nil
+		}
+	}
+
+	gitRoot, err = git.FindGitRoot(dir)
+	if err != nil {
+		// git reports that itself
+		return subcommand, "", nil
+	}
+	if repo, err := config.LoadRepo(gitRoot); err == nil && repo.Unmanaged {
+		return subcommand, gitRoot, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return subcommand, gitRoot, []prompt.Issue{{Message: fmt.Sprintf("failed to load config: %v", err)}}
+	}
+	name := workspaceForRepo(cfg, gitRoot)
+	if name == "" {
+		return subcommand, gitRoot, nil
+	}
+	return subcommand, gitRoot, gitGateProblems(name, repoIdentity(cfg, name, gitRoot), gitRoot)
+}
+
+// gitGateProblems returns what keeps the repository from acting as ws:
+// another author identity, or an origin that bypasses the workspace
+func gitGateProblems(name string, ws config.Workspace, gitRoot string) []prompt.Issue {
+	var problems []prompt.Issue
+
+	_, email, err := git.AuthorIdent(gitRoot)
+	if err != nil {
+		problems = append(problems, prompt.Issue{
+			Message: err.Error(),
+			Command: fixCommand(name, gitRoot, "set-identity"),
+		})
+	} else if !strings.EqualFold(email, ws.Email) {
+		issue := prompt.Issue{
+			Message: fmt.Sprintf("Commits would be authored as %s, but workspace '%s' uses %s", email, name, ws.Email),
+			Command: fixCommand(name, gitRoot, "set-identity"),
+		}
+		if env := os.Getenv("GIT_AUTHOR_EMAIL"); env != "" {
+			issue.Command = nil
+			issue.Fix = "Unset GIT_AUTHOR_EMAIL, or load the workspace environment with 'gitws env'"
+		}
+		problems = append(problems, issue)
+	}
+
+	if remoteURL, err := git.GetRemoteURL(gitRoot); err == nil && remoteNeedsRewrite(remoteURL, ws) {
+		problems = append(problems, prompt.Issue{
+			Message: fmt.Sprintf("origin (%s) does not go through workspace '%s'", remoteURL, name),
+			Command: fixCommand(name, gitRoot, "rewrite-remote"),
+		})
+	}
+	return problems
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestChildExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tests := []struct {
+		script   string
+		expected int
+	}{
+		{"exit 3", 3},
+		{"kill -INT $$", 130},
+		{"kill -TERM $$", 143},
+	}
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			err := exec.Command("sh", "-c", tt.script).Run()
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("expected an exit error, got %v", err)
+			}
+			if result := childExitCode(exitErr); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	guardBlock bool
+	guardForce bool
+)
+
+// guardCmd represents the guard command
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Manage the global guard for repos outside any workspace",
+	Long: `Manage the global guard that catches commits in "unclassified" repositories.
+
+A repository is unclassified when its origin remote points at a provider
+host (github.com, gitlab.com, or any configured host name) but it neither
+uses a workspace SSH alias nor lives under a workspace root. Such repos
+silently fall back to your global identity.
+
+The guard is installed through the global core.hooksPath. Each repository's
+own .git/hooks are still run after the guard.
+
+'gitws guard ssh' adds a separate guard to ~/.ssh/config so the bare
+provider hosts (github.com, ...) offer no key at all. Without it, a remote
+that bypasses the workspace alias silently authenticates with whatever
+default key ssh finds, often as the wrong account.
+
+Examples:
+  gitws guard enable
+  gitws guard enable --block
+  gitws guard disable
+  gitws guard ssh`,
+}
+
+var guardEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Install the global unclassified-repo guard",
+	Args:  cobra.NoArgs,
+	RunE:  runGuardEnable,
+}
+
+var guardDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Remove the global unclassified-repo guard",
+	Args:  cobra.NoArgs,
+	RunE:  runGuardDisable,
+}
+
+var guardSSHCmd = &cobra.Command{
+	Use:   "ssh",
+	Short: "Stop bare provider hosts from offering default SSH keys",
+	Args:  cobra.NoArgs,
+	RunE:  runGuardSSH,
+}
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+	guardCmd.AddCommand(guardEnableCmd)
+	guardCmd.AddCommand(guardDisableCmd)
+	guardCmd.AddCommand(guardSSHCmd)
+
+	guardEnableCmd.Flags().BoolVar(&guardBlock, "block", false, "Block commits instead of warning")
+	guardEnableCmd.Flags().BoolVar(&guardForce, "force", false, "Replace an existing global core.hooksPath")
+}
+
+func runGuardEnable(cmd *cobra.Command, args []string) error {
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	hooksDir, err := workspace.GlobalHooksDir()
+	if err != nil {
+		return fmt.Errorf("failed to get hooks directory: %w", err)
+	}
+
+	if current, err := git.GetGlobalConfig("core.hooksPath"); err == nil && current != "" && current != hooksDir && !guardForce {
+		return fmt.Errorf("global core.hooksPath is already set to %s (use --force to replace it)", current)
+	}
+
+	cfg.GlobalGuard = guard.ModeWarn
+	if guardBlock {
+		cfg.GlobalGuard = guard.ModeBlock
+	}
+
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+
+	if err := git.SetGlobalConfig("core.hooksPath", hooksDir); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Global guard enabled (%s mode) via core.hooksPath = %s\n"), cfg.GlobalGuard, hooksDir)
+	return nil
+}
+
+func runGuardDisable(cmd *cobra.Command, args []string) error {
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	hooksDir, err := workspace.GlobalHooksDir()
+	if err != nil {
+		return fmt.Errorf("failed to get hooks directory: %w", err)
+	}
+
+	// Only unset core.hooksPath if it still points at our directory
+	if current, err := git.GetGlobalConfig("core.hooksPath"); err == nil && current == hooksDir {
+		if err := git.UnsetGlobalConfig("core.hooksPath"); err != nil {
+			return err
+		}
+	}
+
+	cfg.GlobalGuard = ""
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println(prompt.Text("✓ Global guard disabled"))
+	return nil
+}
+
+func runGuardSSH(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	hosts := workspaceHosts(cfg)
+	if len(hosts) == 0 {
+		return fmt.Errorf("no workspaces configured. Run 'gitws init' first")
+	}
+
+	if err := ssh.UpsertHostGuardBlock(hosts); err != nil {
+		return err
+	}
+
+	fmt.Printf(prompt.Text("✓ SSH host guard written for %s\n"), strings.Join(hosts, ", "))
+	fmt.Println("  Connections must now go through a workspace alias to authenticate")
+	return nil
+}
+
+// workspaceHosts returns the distinct provider hosts used by workspaces
+func workspaceHosts(cfg *config.File) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, ws := range cfg.Workspaces {
+		for host := range ws.HostAliases() {
+			if host != "" && !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// refreshGlobalGuard rewrites the global guard hooks. It is a no-op when
+// the guard is not enabled. The hooks read config.yaml when they run, so
+// this only matters when the hooks are missing or gitws has moved.
+func refreshGlobalGuard(cfg *config.File) error {
+	if cfg.GlobalGuard == "" {
+		return nil
+	}
+
+	hooksDir, err := workspace.GlobalHooksDir()
+	if err != nil {
+		return fmt.Errorf("failed to get hooks directory: %w", err)
+	}
+
+	if err := git.InstallGlobalGuard(hooksDir); err != nil {
+		return fmt.Errorf("failed to install global guard: %w", err)
+	}
+	return nil
+}
+
+// globalGuard derives what the global guard treats as classified from the
+// current workspaces
+func globalGuard(cfg *config.File) guard.Global {
+	g := guard.Global{Mode: cfg.GlobalGuard, HostWorkspaces: make(map[string]string)}
+	hostUsers := make(map[string][]string)
+
+	hosts := make(map[string]bool)
+	for _, host := range workspace.ProviderHosts {
+		hosts[host] = true
+	}
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		for host := range ws.HostAliases() {
+			if host != "" {
+				hosts[host] = true
+				hostUsers[host] = append(hostUsers[host], name)
+			}
+		}
+		for _, alias := range ws.Aliases() {
+			if alias != "" {
+				g.Aliases = append(g.Aliases, alias)
+			}
+		}
+		for _, retired := range ws.RetiredAliases {
+			g.Aliases = append(g.Aliases, retired.Alias)
+		}
+		if !followsRemote(ws) && ws.Root != "" {
+			g.Roots = append(g.Roots, ws.Root)
+		}
+	}
+
+	for host := range hosts {
+		g.ProviderHosts = append(g.ProviderHosts, host)
+	}
+	for host, users := range hostUsers {
+		if len(users) == 1 {
+			g.HostWorkspaces[host] = users[0]
+		}
+	}
+	sort.Strings(g.ProviderHosts)
+
+	return g
+}
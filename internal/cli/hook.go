@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookGlobal bool
+)
+
+// hookCmd groups the commands installed hooks call
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Run gitws hook logic (called by installed hooks)",
+}
+
+// hookRunCmd represents the hook run command
+var hookRunCmd = &cobra.Command{
+	Use:   "run <hook> [args...]",
+	Short: "Run the guard and policy checks for a git hook",
+	Long: `Run the guard and policy checks for a git hook. The hook stubs written by
+'gitws hooks install' and 'gitws guard enable' call this; it is not
+usually run by hand.
+
+Supported hooks:
+- pre-commit: committer identity, forbidden addresses, origin alias, and
+  large files
+- commit-msg: issue reference in the message
+- pre-push: protected branches, force pushes, and commits made with an
+  address the workspace forbids
+
+With --global, runs the unclassified-repo check of the global guard.
+
+A .gitws.yaml in the repository can turn off rules with disable_guards
+(e.g. large-file), or every check with unmanaged: true.`,
+	Args:         cobra.MinimumNArgs(1),
+	RunE:         runHookRun,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookRunCmd)
+
+	hookRunCmd.Flags().BoolVar(&hookGlobal, "global", false, "Run the global guard instead of the repository hooks")
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	hookName := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	gitRoot, err := git.FindGitRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	// An unmanaged repository is outside every guard. A broken .gitws.yaml
+	// disables nothing.
+	repo, err := config.LoadRepo(gitRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  Git workspace guard: %v\n"), err)
+	} else if repo.Unmanaged {
+		return nil
+	}
+
+	remoteURL, _ := git.GetRemoteURL(gitRoot)
+	host, _ := rewrite.ExtractHost(remoteURL)
+
+	if hookGlobal {
+		if hookName != "pre-commit" {
+			return nil
+		}
+		g := globalGuard(cfg)
+		if v := guard.CheckUnclassified(g, host, gitRoot); v != nil {
+			fix := fixCommand(g.HostWorkspaces[host], gitRoot, "rewrite-remote", "set-identity")
+			return reportViolations(cmd, gitRoot, []guard.Violation{*v}, fix)
+		}
+		return nil
+	}
+
+	name := workspaceForRepo(cfg, gitRoot)
+	if name == "" {
+		return nil // Not a workspace repository; the global guard covers it
+	}
+	ws, err := hookWorkspace(cfg, name)
+	if err != nil {
+		return err
+	}
+	// A repository using one of the workspace's identities commits as it;
+	// one on an extra host pushes through that host's alias
+	identity := repoIdentity(cfg, name, gitRoot)
+	ws.Email = identity.Email
+	ws.RequireNoreply = identity.EnforceNoreply
+	ws.Alias, _ = rewrite.PickAlias(remoteURL, identity.HostAliases(), identity.SSHAlias)
+
+	var violations []guard.Violation
+	var fix []string
+
+	switch hookName {
+	case "pre-commit":
+		email, _ := git.GetConfig(gitRoot, "user.email")
+		for _, v := range guard.CheckIdentity(ws, email, host) {
+			violations = append(violations, v)
+			switch v.Rule {
+			case guard.RuleWrongIdentity, guard.RuleForbiddenEmail:
+				fix = fixCommand(name, gitRoot, "set-identity")
+			case guard.RuleAlias:
+				if fix == nil {
+					fix = fixCommand(name, gitRoot, "rewrite-remote")
+				}
+			}
+		}
+
+		// GIT_AUTHOR_EMAIL can author the commit as someone else
+		if _, author, err := git.AuthorIdent(gitRoot); err == nil && !strings.EqualFold(author, email) {
+			if v := guard.CheckForbidden(ws, author); v != nil {
+				violations = append(violations, *v)
+			}
+		}
+
+		if ws.Policy.MaxFileSize > 0 {
+			sizes, err := git.StagedFileSizes(gitRoot)
+			if err != nil {
+				return err
+			}
+			if v := guard.CheckFileSizes(sizes, ws.Policy.MaxFileSize); v != nil {
+				violations = append(violations, *v)
+			}
+		}
+
+	case "commit-msg":
+		if len(args) < 2 {
+			return fmt.Errorf("commit-msg hook needs the message file")
+		}
+		message, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read commit message: %w", err)
+		}
+		v, err := guard.CheckCommitMessage(ws.Policy.IssuePattern, string(message))
+		if err != nil {
+			return fmt.Errorf("workspace %q: %w", name, err)
+		}
+		if v != nil {
+			violations = append(violations, *v)
+		}
+
+	case "pre-push":
+		updates, err := guard.ParsePushUpdates(os.Stdin)
+		if err != nil {
+			return err
+		}
+		isAncestor := func(ancestor, commit string) bool {
+			return git.IsAncestor(gitRoot, ancestor, commit)
+		}
+		violations = append(violations, guard.CheckPush(ws.Policy, updates, isAncestor)...)
+
+		if ws.ChecksEmails() {
+			commits, err := pushedCommits(gitRoot, updates)
+			if err != nil {
+				return err
+			}
+			if v := guard.CheckCommits(ws, commits); v != nil {
+				violations = append(violations, *v)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported hook: %s (supported: pre-commit, commit-msg, pre-push)", hookName)
+	}
+
+	return reportViolations(cmd, gitRoot, violations, fix)
+}
+
+// pushedCommits returns the commits a push sends that no remote-tracking
+// branch has yet
+func pushedCommits(gitRoot string, updates []guard.PushUpdate) ([]guard.Commit, error) {
+	seen := make(map[string]bool)
+	var commits []guard.Commit
+	for _, u := range updates {
+		if strings.Trim(u.LocalSHA, "0") == "" {
+			continue // A deleted ref pushes nothing
+		}
+		found, err := git.CommitIdentities(gitRoot, u.LocalSHA, "--not", "--remotes")
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range found {
+			if !seen[c.SHA] {
+				seen[c.SHA] = true
+				commits = append(commits, guard.Commit{SHA: c.SHA, AuthorEmail: c.AuthorEmail, CommitterEmail: c.CommitterEmail})
+			}
+		}
+	}
+	return commits, nil
+}
+
+// reportViolations prints violations to stderr and, when any of them
+// blocks, records the block and fails so git aborts. Rules the
+// repository's .gitws.yaml disables are dropped.
+func reportViolations(cmd *cobra.Command, gitRoot string, violations []guard.Violation, fix []string) error {
+	if repo, err := config.LoadRepo(gitRoot); err == nil {
+		enabled := violations[:0]
+		for _, v := range violations {
+			if !repo.GuardDisabled(v.Rule) {
+				enabled = append(enabled, v)
+			}
+		}
+		violations = enabled
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	blocked := false
+	for _, v := range violations {
+		icon := "⚠️ "
+		if v.Block {
+			icon = "❌"
+			blocked = true
+			recordUsage(stats.PrefixGuard + v.Rule)
+		}
+		fmt.Fprintf(os.Stderr, "%s Git workspace guard: %s\n", prompt.Text(icon), v.Message)
+		for _, detail := range v.Details {
+			fmt.Fprintf(os.Stderr, "   %s\n", detail)
+		}
+	}
+	if len(fix) > 0 {
+		fmt.Fprintf(os.Stderr, "   Fix: %s\n", prompt.ShellJoin(fix))
+	}
+
+	if blocked {
+		fmt.Fprintln(os.Stderr, "   Blocked. Use --no-verify to bypass once.")
+		notifyViolations(gitRoot, violations)
+		return exitCode(cmd, 1)
+	}
+	return nil
+}
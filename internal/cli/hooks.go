@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/layout"
+	"github.com/gitworkspaces/gitws/internal/progress"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksForce bool
+)
+
+// hooksCmd groups repository hook commands
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage workspace hook policies",
+	Long: `Manage the git hooks gitws installs into repositories.
+
+Installed hooks are small stubs that run 'gitws hook run'. Every hook
+checks the committer identity against the workspace, and a workspace can
+also declare a policy pack in config.yaml:
+
+  workspaces:
+    work:
+      hooks:
+        protected_branches: [main, release]
+        issue_pattern: '[A-Z]+-[0-9]+'
+        max_file_size: 5MB
+        forbid_force_push: true
+
+Policies are read from config.yaml each time a hook runs, so changes take
+effect immediately.`,
+}
+
+// hooksInstallCmd represents the hooks install command
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install [path]",
+	Short: "Install workspace hooks into a repository",
+	Long: `Install the guard and policy hooks into a repository.
+
+This command will:
+- Write pre-commit, commit-msg and pre-push hooks that run 'gitws hook run'
+- Refuse to replace hooks gitws did not write unless --force is given
+
+The hooks resolve the repository's workspace when they run, from its origin
+remote or location.
+
+Examples:
+  gitws hooks install
+  gitws hooks install ~/code/work/org/repo --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHooksInstall,
+}
+
+// hooksUpdateCmd represents the hooks update command
+var hooksUpdateCmd = &cobra.Command{
+	Use:   "update [workspace...]",
+	Short: "Rewrite installed hooks with the current stubs",
+	Long: `Rewrite the hooks in every repository under the workspace roots that
+already has gitws hooks installed, e.g. after gitws moved or to replace
+hooks written by older versions. Repositories without gitws hooks are left
+alone.
+
+Examples:
+  gitws hooks update
+  gitws hooks update work`,
+	RunE: runHooksUpdate,
+}
+
+// hooksListCmd represents the hooks list command
+var hooksListCmd = &cobra.Command{
+	Use:   "list [workspace]",
+	Short: "Show the hook policy of each workspace",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runHooksList,
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUpdateCmd)
+	hooksCmd.AddCommand(hooksListCmd)
+
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "Replace existing hooks not written by gitws")
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	var repoPath string
+	var err error
+	if len(args) > 0 {
+		repoPath = args[0]
+	} else {
+		repoPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	gitRoot, err := git.FindGitRoot(repoPath)
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	if err := git.InstallHooks(gitRoot, hooksForce); err != nil {
+		return fmt.Errorf("failed to install hooks: %w", err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Installed gitws hooks in %s\n"), gitRoot)
+	if cfg, err := config.Load(); err == nil {
+		if name := workspaceForRepo(cfg, gitRoot); name == "" {
+			fmt.Println(prompt.Text("⚠️  The repository does not belong to any workspace yet; only the global guard applies"))
+		}
+	}
+	return nil
+}
+
+func runHooksUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+		sort.Strings(names)
+	}
+
+	updated := 0
+	for _, name := range names {
+		if _, exists := cfg.GetWorkspace(name); !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+
+		repos, err := workspaceRepos(cfg, name)
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			if !git.HasManagedHooks(repo) {
+				continue
+			}
+			if err := git.InstallHooks(repo, false); err != nil {
+				fmt.Printf(prompt.Text("⚠️  %s: %v\n"), repo, err)
+				continue
+			}
+			fmt.Printf(prompt.Text("✓ Updated %s\n"), repo)
+			updated++
+		}
+	}
+
+	fmt.Printf("Updated hooks in %d repositories\n", updated)
+	return nil
+}
+
+func runHooksList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+		sort.Strings(names)
+	}
+
+	headers := []string{"Workspace", "Protected Branches", "Issue Pattern", "Max File Size", "Force Push"}
+	var rows [][]string
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+
+		policy := ws.Hooks
+		if policy == nil {
+			policy = &config.HookPolicy{}
+		}
+		forcePush := "allowed"
+		if policy.ForbidForcePush {
+			forcePush = "forbidden"
+		}
+		rows = append(rows, []string{
+			name,
+			displayOrNone(strings.Join(policy.ProtectedBranches, ", ")),
+			displayOrNone(policy.IssuePattern),
+			displayOrNone(policy.MaxFileSize),
+			forcePush,
+		})
+	}
+
+	return prompt.ShowStatusTable(headers, rows)
+}
+
+// hookWorkspace returns what the repository hooks enforce for a workspace
+func hookWorkspace(cfg *config.File, name string) (guard.Workspace, error) {
+	ws, exists := cfg.GetWorkspace(name)
+	if !exists {
+		return guard.Workspace{}, fmt.Errorf("workspace %q not found", name)
+	}
+
+	policy, err := hookPolicy(name, ws.Hooks)
+	if err != nil {
+		return guard.Workspace{}, err
+	}
+
+	return guard.Workspace{Name: name, Email: ws.Email, Alias: ws.SSHAlias, Policy: policy, ForbiddenEmails: ws.ForbiddenEmails, RequireNoreply: ws.EnforceNoreply}, nil
+}
+
+// hookPolicy validates a workspace policy pack and converts it to the
+// form the guard evaluates
+func hookPolicy(name string, def *config.HookPolicy) (guard.Policy, error) {
+	var policy guard.Policy
+	if def == nil {
+		return policy, nil
+	}
+
+	policy.ProtectedBranches = def.ProtectedBranches
+	policy.ForbidForcePush = def.ForbidForcePush
+
+	if def.IssuePattern != "" {
+		if _, err := regexp.Compile(def.IssuePattern); err != nil {
+			return policy, fmt.Errorf("workspace %q: invalid hooks.issue_pattern: %w", name, err)
+		}
+		policy.IssuePattern = def.IssuePattern
+	}
+
+	if def.MaxFileSize != "" {
+		size, err := fsutil.ParseSize(def.MaxFileSize)
+		if err != nil {
+			return policy, fmt.Errorf("workspace %q: hooks.max_file_size: %w", name, err)
+		}
+		policy.MaxFileSize = size
+	}
+
+	return policy, nil
+}
+
+// findRepositories returns the git repositories under root, including
+// linked worktrees, not descending into a repository once found
+func findRepositories(root string) ([]string, error) {
+	bar := progress.New("Scanning "+root, 0)
+	defer bar.Stop()
+	return scanRepositories(root, func() { bar.Add(1) })
+}
+
+// findWorkspaceRepositories finds the repositories under a workspace
+// root, looking no deeper than its clone layout puts them
+func findWorkspaceRepositories(ws config.Workspace) ([]string, error) {
+	bar := progress.New("Scanning "+ws.Root, 0)
+	defer bar.Stop()
+	return scanRepositoriesDepth(ws.Root, layout.MaxDepth(ws.CloneLayout), func() { bar.Add(1) })
+}
+
+// workspaceRepos finds the repositories under a workspace root that
+// belong to it. A repository under the root may still belong elsewhere,
+// to a workspace with a root nested inside or by its .gitws.yaml.
+func workspaceRepos(cfg *config.File, name string) ([]string, error) {
+	ws := cfg.Workspaces[name]
+	if ws.Root == "" {
+		return nil, nil
+	}
+	found, err := findWorkspaceRepositories(ws)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, repo := range found {
+		if owner := workspaceForRepo(cfg, repo); owner != "" && owner != name {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// scanRepositories is findRepositories without progress: visit is called
+// for every directory looked at
+func scanRepositories(root string, visit func()) ([]string, error) {
+	return scanRepositoriesDepth(root, 0, visit)
+}
+
+// scanRepositoriesDepth is scanRepositories looking at most maxDepth
+// directories below root; 0 means no limit
+func scanRepositoriesDepth(root string, maxDepth int, visit func()) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		visit()
+		// .git is a directory in a repository and a file in a worktree
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		if maxDepth > 0 && path != root {
+			if rel, err := filepath.Rel(root, path); err == nil && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return repos, nil
+}
+
+func displayOrNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
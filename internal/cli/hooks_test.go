@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestWorkspaceRepos(t *testing.T) {
+	home := testHome(t)
+	root := filepath.Join(home, "code")
+	for _, repo := range []string{"app", "nested/lib"} {
+		if err := os.MkdirAll(filepath.Join(root, repo, ".git"), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.File{Workspaces: map[string]config.Workspace{
+		"work":   {Root: root},
+		"nested": {Root: filepath.Join(root, "nested")},
+		"none":   {},
+	}}
+
+	tests := []struct {
+		workspace string
+		expected  []string
+	}{
+		{"work", []string{filepath.Join(root, "app")}},
+		{"nested", []string{filepath.Join(root, "nested", "lib")}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.workspace, func(t *testing.T) {
+			repos, err := workspaceRepos(cfg, tt.workspace)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(repos) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, repos)
+			}
+			for i := range tt.expected {
+				if repos[i] != tt.expected[i] {
+					t.Errorf("expected %q, got %q", tt.expected[i], repos[i])
+				}
+			}
+		})
+	}
+}
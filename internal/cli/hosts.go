@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+)
+
+// resolveHosts validates a workspace's extra hosts and fills in their
+// aliases. Provider names (github, gitlab, bitbucket) stand for their host.
+func resolveHosts(name string, ws config.Workspace) ([]config.HostAlias, error) {
+	seen := map[string]bool{ws.HostName: true}
+
+	var hosts []config.HostAlias
+	for _, h := range ws.Hosts {
+		if provider, ok := workspace.ProviderHosts[h.HostName]; ok {
+			h.HostName = provider
+		}
+		if h.HostName == "" {
+			return nil, fmt.Errorf("workspace %q: hosts: host_name is required", name)
+		}
+		if seen[h.HostName] {
+			return nil, fmt.Errorf("workspace %q: host %s is listed twice", name, h.HostName)
+		}
+		seen[h.HostName] = true
+
+		if h.SSHAlias == "" {
+			h.SSHAlias = workspace.BuildSSHAlias(h.HostName, name)
+		}
+		if !config.ValidSSHValue(h.HostName) || !config.ValidSSHValue(h.SSHAlias) {
+			return nil, fmt.Errorf("workspace %q: hosts: host name and SSH alias must not contain whitespace or control characters", name)
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// parseExtraHosts turns --extra-host values into unresolved extra hosts
+func parseExtraHosts(values []string) []config.HostAlias {
+	var hosts []config.HostAlias
+	for _, v := range values {
+		hosts = append(hosts, config.HostAlias{HostName: v})
+	}
+	return hosts
+}
+
+// writeExtraSSHBlocks writes the managed SSH config blocks of a
+// workspace's extra hosts and identities
+func writeExtraSSHBlocks(name string, ws config.Workspace) error {
+	for _, h := range ws.Hosts {
+		if err := ssh.UpsertSSHConfigBlock(config.HostBlockName(name, h.HostName), h.SSHAlias, h.HostName, ws.SSHKey); err != nil {
+			return fmt.Errorf("failed to write SSH config for host %s: %w", h.HostName, err)
+		}
+	}
+	return writeIdentitySSHBlocks(name, ws)
+}
+
+// removeExtraSSHBlocks removes the managed SSH config blocks of a
+// workspace's extra hosts and identities
+func removeExtraSSHBlocks(name string, ws config.Workspace) error {
+	for _, h := range ws.Hosts {
+		if err := ssh.RemoveSSHConfigBlock(config.HostBlockName(name, h.HostName)); err != nil {
+			return fmt.Errorf("failed to remove SSH config block for host %s: %w", h.HostName, err)
+		}
+	}
+	return removeIdentitySSHBlocks(name, ws)
+}
+
+// removeDroppedSSHBlocks removes the SSH blocks of extra hosts and
+// identities in current that desired no longer has
+func removeDroppedSSHBlocks(name string, current, desired config.Workspace) error {
+	var dropped config.Workspace
+	kept := desired.HostAliases()
+	for _, h := range current.Hosts {
+		if _, ok := kept[h.HostName]; !ok {
+			dropped.Hosts = append(dropped.Hosts, h)
+		}
+	}
+	for id, identity := range current.Identities {
+		if _, ok := desired.Identities[id]; !ok {
+			if dropped.Identities == nil {
+				dropped.Identities = make(map[string]config.Identity)
+			}
+			dropped.Identities[id] = identity
+		}
+	}
+	return removeExtraSSHBlocks(name, dropped)
+}
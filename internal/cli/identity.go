@@ -0,0 +1,345 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/email"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	identityEmail string
+	identityName  string
+	identityNoMX  bool
+	identityYes   bool
+)
+
+// identityCmd represents the identity command
+var identityCmd = &cobra.Command{
+	Use:   "identity",
+	Short: "Manage additional accounts within a workspace",
+	Long: `Manage additional accounts on a workspace's host, e.g. a bot account
+next to your own on the same GitHub organisation.
+
+Each identity has its own email, SSH key and SSH alias. Repositories whose
+origin uses the identity's alias commit as it; everything else (root,
+signing method, hooks) comes from the workspace.
+
+Examples:
+  gitws identity add work bot --email bot@work.com --name "Work Bot"
+  gitws clone work:bot acme/deploy-scripts
+  gitws fix --identity bot
+  gitws identity list`,
+}
+
+var identityAddCmd = &cobra.Command{
+	Use:   "add <workspace> <identity> --email <email>",
+	Short: "Add an identity to a workspace",
+	Long: `Add an identity to a workspace.
+
+This command will:
+- Generate an SSH key for the identity
+- Add a managed SSH config block with its own alias
+- Record the identity in the workspace configuration
+
+Examples:
+  gitws identity add work bot --email bot@work.com
+  gitws identity add work bot --email bot@work.com --name "Work Bot"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runIdentityAdd,
+}
+
+var identityListCmd = &cobra.Command{
+	Use:   "list [workspace]",
+	Short: "List workspace identities",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runIdentityList,
+}
+
+var identityRemoveCmd = &cobra.Command{
+	Use:   "remove <workspace> <identity>",
+	Short: "Remove an identity from a workspace",
+	Long: `Remove an identity and its managed SSH config block.
+
+The SSH key is kept, so the identity can be added back without uploading a
+new key. Repositories still using the identity's alias stop authenticating;
+point them back at the workspace with 'gitws fix --rewrite-remote --set-identity'.
+
+Examples:
+  gitws identity remove work bot
+  gitws identity remove work bot --yes`,
+	Args: cobra.ExactArgs(2),
+	RunE: runIdentityRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(identityCmd)
+	identityCmd.AddCommand(identityAddCmd)
+	identityCmd.AddCommand(identityListCmd)
+	identityCmd.AddCommand(identityRemoveCmd)
+
+	identityAddCmd.Flags().StringVar(&identityEmail, "email", "", "Email address for the identity (required)")
+	identityAddCmd.Flags().StringVar(&identityName, "name", "", "Display name (default: the workspace's)")
+	identityAddCmd.Flags().BoolVar(&identityNoMX, "no-mx-check", false, "Skip the DNS check of the email domain")
+	identityAddCmd.MarkFlagRequired("email")
+
+	identityRemoveCmd.Flags().BoolVar(&identityYes, "yes", false, "Skip confirmation prompt")
+}
+
+// splitIdentity splits a "workspace:identity" argument
+func splitIdentity(arg string) (workspaceName, identity string) {
+	workspaceName, identity, _ = strings.Cut(arg, ":")
+	return workspaceName, identity
+}
+
+// identityKeyName is the name an identity's key file is derived from
+func identityKeyName(workspaceName, identity string) string {
+	return workspaceName + "-" + identity
+}
+
+// resolveIdentity validates an identity definition and fills in its
+// derived alias and key path
+func resolveIdentity(workspaceName string, ws config.Workspace, name string, id config.Identity) (config.Identity, error) {
+	if err := config.ValidateName(name); err != nil {
+		return id, fmt.Errorf("workspace %q: identity %w", workspaceName, err)
+	}
+
+	id.Email = email.Normalize(id.Email)
+	id.Name = strings.Join(strings.Fields(id.Name), " ")
+	if id.Email == "" {
+		return id, fmt.Errorf("workspace %q: identity %q: email is required", workspaceName, name)
+	}
+	if err := email.Validate(id.Email); err != nil {
+		return id, fmt.Errorf("workspace %q: identity %q: %w", workspaceName, name, err)
+	}
+
+	if id.SSHAlias == "" {
+		providerOrHost := ws.Provider
+		if providerOrHost == "" {
+			providerOrHost = ws.HostName
+		}
+		id.SSHAlias = workspace.BuildSSHAlias(providerOrHost, identityKeyName(workspaceName, name))
+	}
+	if !config.ValidSSHValue(id.SSHAlias) {
+		return id, fmt.Errorf("workspace %q: identity %q: SSH alias must not contain whitespace or control characters", workspaceName, name)
+	}
+	if id.SSHKey == "" {
+		keyPath, err := ssh.KeyPath(identityKeyName(workspaceName, name))
+		if err != nil {
+			return id, err
+		}
+		id.SSHKey = keyPath
+	}
+	return id, nil
+}
+
+// sortedIdentities returns a workspace's identity names in order
+func sortedIdentities(ws config.Workspace) []string {
+	names := make([]string, 0, len(ws.Identities))
+	for name := range ws.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeIdentitySSHBlocks writes the managed SSH config block of every
+// identity in a workspace
+func writeIdentitySSHBlocks(workspaceName string, ws config.Workspace) error {
+	for _, name := range sortedIdentities(ws) {
+		id := ws.Identities[name]
+		if err := ssh.UpsertSSHConfigBlock(config.IdentityName(workspaceName, name), id.SSHAlias, ws.HostName, id.SSHKey); err != nil {
+			return fmt.Errorf("failed to write SSH config for identity %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// removeIdentitySSHBlocks removes the managed SSH config block of every
+// identity in a workspace
+func removeIdentitySSHBlocks(workspaceName string, ws config.Workspace) error {
+	for _, name := range sortedIdentities(ws) {
+		if err := ssh.RemoveSSHConfigBlock(config.IdentityName(workspaceName, name)); err != nil {
+			return fmt.Errorf("failed to remove SSH config block for identity %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// aliasOwner returns the workspace, workspace@host or workspace:identity
+// using alias
+func aliasOwner(cfg *config.File, alias string) string {
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		if ws.SSHAlias == alias {
+			return name
+		}
+		for _, h := range ws.Hosts {
+			if h.SSHAlias == alias {
+				return config.HostBlockName(name, h.HostName)
+			}
+		}
+		if id := ws.IdentityForAlias(alias); id != "" {
+			return config.IdentityName(name, id)
+		}
+	}
+	return ""
+}
+
+func runIdentityAdd(cmd *cobra.Command, args []string) error {
+	workspaceName, name := args[0], args[1]
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", workspaceName, workspaceName)
+	}
+	if _, exists := ws.Identities[name]; exists {
+		return fmt.Errorf("workspace %q already has an identity %q", workspaceName, name)
+	}
+
+	id, err := resolveIdentity(workspaceName, ws, name, config.Identity{Email: identityEmail, Name: identityName})
+	if err != nil {
+		return err
+	}
+	if owner := aliasOwner(cfg, id.SSHAlias); owner != "" {
+		return fmt.Errorf("SSH alias %s is already used by %s", id.SSHAlias, owner)
+	}
+
+	if ok, err := confirmEmail(id.Email, !identityNoMX); err != nil {
+		return err
+	} else if !ok {
+		fmt.Println("Identity not added.")
+		return nil
+	}
+
+	_, pubPath, created, err := ssh.EnsureKey(identityKeyName(workspaceName, name), id.Email)
+	if err != nil {
+		return fmt.Errorf("failed to ensure SSH key: %w", err)
+	}
+	if created {
+		id.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+
+	if err := ssh.UpsertSSHConfigBlock(config.IdentityName(workspaceName, name), id.SSHAlias, ws.HostName, id.SSHKey); err != nil {
+		return fmt.Errorf("failed to update SSH config: %w", err)
+	}
+
+	if ws.Identities == nil {
+		ws.Identities = make(map[string]config.Identity)
+	}
+	ws.Identities[name] = id
+	cfg.SetWorkspace(workspaceName, ws)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	publicKey, err := ssh.GetPublicKey(pubPath)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	effective, _ := ws.ForIdentity(name)
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title: i18n.T("✓ Identity '%s' added to workspace '%s'", name, workspaceName),
+		Items: []prompt.SummaryItem{
+			{Label: "SSH Alias", Value: id.SSHAlias, Icon: "🔑"},
+			{Label: "Email", Value: id.Email, Icon: "📧"},
+			{Label: "Name", Value: effective.Name, Icon: "👤"},
+		},
+		PublicKey: publicKey,
+		NextSteps: []string{
+			fmt.Sprintf("Add the public key to the %s account on %s", name, ws.HostName),
+			fmt.Sprintf("Clone with it: gitws clone %s ORG/REPO", config.IdentityName(workspaceName, name)),
+			fmt.Sprintf("Switch an existing repository: gitws fix --workspace %s --identity %s", workspaceName, name),
+		},
+	})
+}
+
+func runIdentityList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := cfg.ListWorkspaces()
+	if len(args) > 0 {
+		if _, exists := cfg.GetWorkspace(args[0]); !exists {
+			return fmt.Errorf("workspace %q not found", args[0])
+		}
+		names = []string{args[0]}
+	}
+
+	headers := []string{"Workspace", "Identity", "Email", "SSH Alias"}
+	var rows [][]string
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		rows = append(rows, []string{name, "(default)", ws.Email, ws.SSHAlias})
+		for _, id := range sortedIdentities(ws) {
+			identity := ws.Identities[id]
+			rows = append(rows, []string{name, id, identity.Email, identity.SSHAlias})
+		}
+	}
+
+	return prompt.ShowStatusTable(headers, rows)
+}
+
+func runIdentityRemove(cmd *cobra.Command, args []string) error {
+	workspaceName, name := args[0], args[1]
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", workspaceName)
+	}
+	id, exists := ws.Identities[name]
+	if !exists {
+		return fmt.Errorf("workspace %q has no identity %q", workspaceName, name)
+	}
+	if err := runPreflight(cmd.Context(), preflightChecks(cfg, false)); err != nil {
+		return err
+	}
+
+	if !identityYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Remove identity '%s' (%s) from workspace '%s'?", name, id.Email, workspaceName))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Remove cancelled.")
+			return nil
+		}
+	}
+
+	if err := ssh.RemoveSSHConfigBlock(config.IdentityName(workspaceName, name)); err != nil {
+		return fmt.Errorf("failed to remove SSH config block: %w", err)
+	}
+
+	delete(ws.Identities, name)
+	cfg.SetWorkspace(workspaceName, ws)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf(prompt.Text("✓ Removed identity '%s' from workspace '%s'\n"), name, workspaceName)
+
+	fmt.Printf(prompt.Text("ℹ️  Kept SSH key %s; revoke it on %s if the account is gone\n"), id.SSHKey, ws.HostName)
+	return nil
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/archive"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importForce      bool
+	importRotateKeys bool
+	importKeepKeys   bool
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import workspace definitions from 'gitws export'",
+	Long: `Import workspaces from an archive created by 'gitws export'.
+
+This command will:
+- Add the workspaces to config.yaml, remapping paths under the old home directory
+- Restore SSH keys from the archive, or generate new ones if none were exported
+- Regenerate SSH config blocks, includeIf entries, and workspace gitconfigs
+- Offer to rotate imported keys so each machine has its own key
+
+Examples:
+  gitws import workspaces.tar.gz
+  gitws import workspaces.tar.gz --rotate-keys
+  gitws import workspaces.tar.gz --force --keep-keys`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite existing workspaces and keys")
+	importCmd.Flags().BoolVar(&importRotateKeys, "rotate-keys", false, "Rotate imported keys without prompting")
+	importCmd.Flags().BoolVar(&importKeepKeys, "keep-keys", false, "Keep imported keys without prompting")
+
+	importCmd.MarkFlagsMutuallyExclusive("rotate-keys", "keep-keys")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	bundle, err := archive.Read(args[0])
+	if err != nil {
+		return err
+	}
+
+	if bundle.Manifest.IncludeKeys {
+		passphrase, err := prompt.Passphrase("Passphrase for exported private keys")
+		if err != nil {
+			return err
+		}
+		if err := bundle.Unseal(passphrase); err != nil {
+			return err
+		}
+	}
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	names := bundle.Config.ListWorkspaces()
+	sort.Strings(names)
+
+	// The archive is untrusted input: every workspace is resolved and the
+	// config it would produce validated before anything is written
+	resolved := make(map[string]config.Workspace, len(names))
+	candidate := &config.File{Workspaces: make(map[string]config.Workspace, len(cfg.Workspaces)+len(names))}
+	for name, ws := range cfg.Workspaces {
+		candidate.Workspaces[name] = ws
+	}
+	var imported, skipped, restoredKeys []string
+	for _, name := range names {
+		if err := config.ValidateWorkspaceName(name); err != nil {
+			return fmt.Errorf("archive workspace %w", err)
+		}
+		if _, exists := cfg.GetWorkspace(name); exists && !importForce {
+			fmt.Printf(prompt.Text("⚠️  Skipping workspace '%s': already exists (use --force to overwrite)\n"), name)
+			skipped = append(skipped, name)
+			continue
+		}
+
+		ws := bundle.Config.Workspaces[name]
+		ws.Root = remapHome(ws.Root, bundle.Manifest.Home, home)
+		ws.SSHKey = remapHome(ws.SSHKey, bundle.Manifest.Home, home)
+		for id, identity := range ws.Identities {
+			identity.SSHKey = remapHome(identity.SSHKey, bundle.Manifest.Home, home)
+			ws.Identities[id] = identity
+		}
+		ws, err := resolveWorkspace(name, ws)
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		if err := checkImportedKeyPath(ws.SSHKey, name); err != nil {
+			return fmt.Errorf("archive workspace %q: %w", name, err)
+		}
+		for id, identity := range ws.Identities {
+			if err := checkImportedKeyPath(identity.SSHKey, identityKeyName(name, id)); err != nil {
+				return fmt.Errorf("archive workspace %q: identity %q: %w", name, id, err)
+			}
+		}
+		resolved[name] = ws
+		candidate.Workspaces[name] = ws
+	}
+	if err := config.Validate(candidate); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		ws, ok := resolved[name]
+		if !ok {
+			continue
+		}
+
+		key, hasKey := bundle.Keys[name]
+		if hasKey && key.Private != nil {
+			// checkImportedKeyPath confirmed ws.SSHKey names this file
+			privPath, err := ssh.KeyPath(name)
+			if err != nil {
+				return err
+			}
+			if err := restoreKey(privPath, key); err != nil {
+				return fmt.Errorf("failed to restore key for %q: %w", name, err)
+			}
+			ws.SSHKey = privPath
+			restoredKeys = append(restoredKeys, name)
+		} else {
+			privPath, _, created, err := ssh.EnsureKey(name, ws.Email)
+			if err != nil {
+				return fmt.Errorf("failed to ensure SSH key for %q: %w", name, err)
+			}
+			ws.SSHKey = privPath
+			if created {
+				ws.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+			}
+		}
+
+		if err := ssh.UpsertSSHConfigBlock(name, ws.SSHHosts(time.Now()), ws.HostName, ws.SSHKey); err != nil {
+			return fmt.Errorf("failed to update SSH config for %q: %w", name, err)
+		}
+
+		if err := createWorkspaceGitConfig(name, ws); err != nil {
+			return fmt.Errorf("failed to create workspace gitconfig for %q: %w", name, err)
+		}
+
+		if err := writeWorkspaceEnvrc(name, ws); err != nil {
+			return fmt.Errorf("failed to write .envrc for %q: %w", name, err)
+		}
+
+		cfg.SetWorkspace(name, ws)
+		imported = append(imported, name)
+	}
+
+	if len(imported) == 0 {
+		fmt.Println("Nothing imported.")
+		return nil
+	}
+
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+
+	// Sharing one key across machines makes revocation all-or-nothing,
+	// so offer a per-machine key for each restored workspace
+	var rotated []string
+	for _, name := range restoredKeys {
+		rotate := importRotateKeys
+		if !importRotateKeys && !importKeepKeys {
+			rotate, err = prompt.Confirm(fmt.Sprintf("Rotate the imported key for workspace '%s' so this machine has its own key?", name))
+			if err != nil {
+				return fmt.Errorf("failed to get confirmation: %w", err)
+			}
+		}
+		if !rotate {
+			continue
+		}
+		if _, _, err := rotateWorkspaceKey(cfg, name); err != nil {
+			return fmt.Errorf("failed to rotate key for %q: %w", name, err)
+		}
+		rotated = append(rotated, name)
+	}
+	if len(rotated) > 0 {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	items := []prompt.SummaryItem{
+		{Label: "Imported", Value: strings.Join(imported, ", "), Icon: "📁"},
+	}
+	if len(skipped) > 0 {
+		items = append(items, prompt.SummaryItem{Label: "Skipped", Value: strings.Join(skipped, ", "), Icon: "⏭️"})
+	}
+	if len(rotated) > 0 {
+		items = append(items, prompt.SummaryItem{Label: "Rotated keys", Value: strings.Join(rotated, ", "), Icon: "🔄"})
+	}
+
+	nextSteps := []string{"Run 'gitws status' inside a repository to verify configuration"}
+	if len(rotated) > 0 || len(restoredKeys) < len(imported) {
+		nextSteps = append([]string{"Add the new public keys to your provider accounts"}, nextSteps...)
+	}
+
+	summary := prompt.SummaryData{
+		Title:     i18n.T("✓ Imported %d workspace(s)", len(imported)),
+		Items:     items,
+		NextSteps: nextSteps,
+	}
+
+	return prompt.ShowSummary(summary)
+}
+
+// remapHome rewrites a path under the exporting machine's home directory to
+// the same location under this machine's home directory
+func remapHome(path, fromHome, toHome string) string {
+	if fromHome == "" || fromHome == toHome {
+		return path
+	}
+	if path == fromHome {
+		return toHome
+	}
+	if strings.HasPrefix(path, fromHome+string(filepath.Separator)) {
+		return filepath.Join(toHome, strings.TrimPrefix(path, fromHome))
+	}
+	return path
+}
+
+// checkImportedKeyPath requires a key path from an archive to be the
+// one gitws generates for keyName. Any other path, even inside ~/.ssh,
+// would let a crafted archive write its key over a file such as
+// ~/.ssh/authorized_keys or ~/.ssh/config.
+func checkImportedKeyPath(keyPath, keyName string) error {
+	expected, err := ssh.KeyPath(keyName)
+	if err != nil {
+		return err
+	}
+	expanded, err := workspace.ExpandPath(keyPath)
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(expanded) != expected {
+		return fmt.Errorf("key %s is not at %s, where gitws keeps the key", keyPath, expected)
+	}
+	return nil
+}
+
+// restoreKey writes an imported key pair to privPath
+func restoreKey(privPath string, key archive.Key) error {
+	if fsutil.FileExists(privPath) {
+		if !importForce {
+			return fmt.Errorf("%s already exists (use --force to replace it)", privPath)
+		}
+		if err := backupExistingKey(privPath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(privPath), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := fsutil.AtomicWrite(privPath, key.Private, 0600); err != nil {
+		return err
+	}
+	if len(key.Public) > 0 {
+		if err := fsutil.AtomicWrite(privPath+".pub", key.Public, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckImportedKeyPath(t *testing.T) {
+	home := testHome(t)
+	sshDir := filepath.Join(home, ".ssh")
+
+	tests := []struct {
+		name    string
+		keyPath string
+		keyName string
+		valid   bool
+	}{
+		{"workspace key", filepath.Join(sshDir, "id_ed25519_gws_work"), "work", true},
+		{"tilde", "~/.ssh/id_ed25519_gws_work", "work", true},
+		{"identity key", filepath.Join(sshDir, "id_ed25519_gws_work-ci"), "work-ci", true},
+		{"other workspace's key", filepath.Join(sshDir, "id_ed25519_gws_oss"), "work", false},
+		{"authorized_keys", filepath.Join(sshDir, "authorized_keys"), "work", false},
+		{"ssh config", filepath.Join(sshDir, "config"), "work", false},
+		{"rc", filepath.Join(sshDir, "rc"), "work", false},
+		{"outside ~/.ssh", filepath.Join(home, ".bashrc"), "work", false},
+		{"traversal", filepath.Join(sshDir, "..", ".ssh", "id_ed25519_gws_work", "..", "config"), "work", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkImportedKeyPath(tt.keyPath, tt.keyName)
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
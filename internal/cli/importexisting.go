@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/migrate"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importExistingDryRun bool
+	importExistingYes    bool
+)
+
+// importExistingCmd represents the import-existing command
+var importExistingCmd = &cobra.Command{
+	Use:   "import-existing",
+	Short: "Turn a hand-written multi-identity setup into workspaces",
+	Long: `Find identities set up by hand and convert them into gitws workspaces.
+
+A hand-written setup is a Host alias in ~/.ssh/config with its own key,
+such as:
+
+  Host github-work
+    HostName github.com
+    IdentityFile ~/.ssh/id_work
+
+together with an includeIf section in ~/.gitconfig pointing at a file
+that sets user.email, such as [includeIf "gitdir:~/work/"]. The two are
+paired by name: the workspace name is the alias without the host
+(github-work becomes "work"), and an includeIf belongs to it when its
+directory or included file carries that name.
+
+This command will:
+- Propose a workspace for each pair, keeping the alias, key and directory
+- Replace the Host block with a managed one for the same alias, so
+  existing clones keep working without rewriting their remotes
+- Replace the includeIf section with the managed includeIf block
+- Leave the key where it is, referenced instead of copied
+
+Setups missing a part, or whose Host block sets options a managed block
+would drop (ProxyJump, Port, ...), are listed and left alone. The files
+the old includeIf sections pointed at are kept; settings in them other
+than the identity no longer apply and are listed.
+
+Examples:
+  gitws import-existing --dry-run
+  gitws import-existing
+  gitws import-existing --yes`,
+	Args: cobra.NoArgs,
+	RunE: runImportExisting,
+}
+
+func init() {
+	rootCmd.AddCommand(importExistingCmd)
+
+	importExistingCmd.Flags().BoolVar(&importExistingDryRun, "dry-run", false, "Only show the proposed workspaces")
+	importExistingCmd.Flags().BoolVar(&importExistingYes, "yes", false, "Skip confirmation prompt")
+}
+
+// existingSetup is a proposal and why it cannot be converted, if it cannot
+type existingSetup struct {
+	migrate.Proposal
+	Problems []string
+	// IncludedPath is the resolved file the includeIf section points at
+	IncludedPath string
+}
+
+func runImportExisting(cmd *cobra.Command, args []string) error {
+	sshPath, err := ssh.ConfigPath()
+	if err != nil {
+		return err
+	}
+	gitPath, err := globalGitConfigPath()
+	if err != nil {
+		return err
+	}
+	sshContent, err := readOptional(sshPath)
+	if err != nil {
+		return err
+	}
+	gitContent, err := readOptional(gitPath)
+	if err != nil {
+		return err
+	}
+
+	includes := migrate.ParseIncludes(gitContent)
+	included := make(map[int]string, len(includes))
+	for i := range includes {
+		path := includedFile(includes[i].Path, gitPath)
+		included[i] = path
+		includes[i].Email, _ = git.GetFileConfig(path, "user.email")
+		includes[i].UserName, _ = git.GetFileConfig(path, "user.name")
+	}
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	var ready, skipped []existingSetup
+	for _, p := range migrate.Detect(migrate.ParseSSHConfig(sshContent), includes) {
+		setup := existingSetup{Proposal: p, Problems: p.Missing()}
+		for i := range includes {
+			if p.Include == &includes[i] {
+				setup.IncludedPath = included[i]
+			}
+		}
+		if err := config.ValidateWorkspaceName(p.Name); err != nil {
+			setup.Problems = append(setup.Problems, fmt.Sprintf("a name other than %q, which is not a valid workspace name", p.Name))
+		} else if _, exists := cfg.GetWorkspace(p.Name); exists {
+			setup.Problems = append(setup.Problems, fmt.Sprintf("a name other than %q, which a workspace already uses", p.Name))
+		}
+		if other := workspaceForAlias(cfg, p.Alias); p.Alias != "" && other != "" {
+			setup.Problems = append(setup.Problems, fmt.Sprintf("an alias other than %s, which workspace '%s' already uses", p.Alias, other))
+		}
+		if len(setup.Problems) > 0 {
+			skipped = append(skipped, setup)
+		} else {
+			ready = append(ready, setup)
+		}
+	}
+
+	if len(ready) == 0 && len(skipped) == 0 {
+		fmt.Printf("No hand-written identity setups found in %s or %s.\n", sshPath, gitPath)
+		return nil
+	}
+
+	for _, s := range ready {
+		fmt.Printf(prompt.Text("📦 Workspace '%s'\n"), s.Name)
+		printExistingSetup(s, sshPath, gitPath)
+	}
+	for _, s := range skipped {
+		fmt.Printf(prompt.Text("⏭️  Not converting '%s'; it needs:\n"), s.Name)
+		for _, problem := range s.Problems {
+			fmt.Printf("   - %s\n", problem)
+		}
+		printExistingSetup(s, sshPath, gitPath)
+	}
+
+	if importExistingDryRun || len(ready) == 0 {
+		return nil
+	}
+
+	if !importExistingYes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Convert %d setup(s) into workspaces?", len(ready)))
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Import cancelled.")
+			return nil
+		}
+	}
+
+	if err := runPreflight(cmd.Context(), preflightChecks(cfg, false)); err != nil {
+		return err
+	}
+
+	workspaces := make([]config.Workspace, len(ready))
+	var hostLines, includeLines []migrate.Lines
+	for i, s := range ready {
+		ws, err := existingWorkspace(s.Proposal)
+		if err != nil {
+			return err
+		}
+		workspaces[i] = ws
+		hostLines = append(hostLines, s.Host.Lines)
+		includeLines = append(includeLines, s.Include.Lines)
+	}
+
+	// The hand-written blocks go first, so the managed ones for the same
+	// aliases and directories do not compete with them
+	if err := removeConfigLines(sshPath, hostLines); err != nil {
+		return fmt.Errorf("failed to update SSH config: %w", err)
+	}
+	if err := removeConfigLines(gitPath, includeLines); err != nil {
+		return fmt.Errorf("failed to update gitconfig: %w", err)
+	}
+
+	var converted []string
+	var leftover []string
+	for i, s := range ready {
+		ws := workspaces[i]
+		if err := ssh.UpsertSSHConfigBlock(s.Name, ws.SSHHosts(time.Now()), ws.HostName, ws.SSHKey); err != nil {
+			return fmt.Errorf("failed to update SSH config for %q: %w", s.Name, err)
+		}
+		if err := createWorkspaceGitConfig(s.Name, ws); err != nil {
+			return fmt.Errorf("failed to create workspace gitconfig for %q: %w", s.Name, err)
+		}
+		cfg.SetWorkspace(s.Name, ws)
+		converted = append(converted, s.Name)
+
+		if keys := droppedIncludeKeys(s.IncludedPath); len(keys) > 0 {
+			target, _ := workspace.GitConfigPath(s.Name)
+			leftover = append(leftover, fmt.Sprintf("Move %s from %s, which no longer applies, to %s", strings.Join(keys, ", "), s.IncludedPath, target))
+		}
+	}
+
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+
+	items := []prompt.SummaryItem{
+		{Label: "Converted", Value: strings.Join(converted, ", "), Icon: "📁"},
+	}
+	if len(skipped) > 0 {
+		names := make([]string, len(skipped))
+		for i, s := range skipped {
+			names[i] = s.Name
+		}
+		items = append(items, prompt.SummaryItem{Label: "Left alone", Value: strings.Join(names, ", "), Icon: "⏭️"})
+	}
+	nextSteps := []string{
+		"Run 'gitws doctor' inside a converted repository to verify it",
+		"Move repositories outside the workspace roots in with 'gitws adopt <dir>'",
+	}
+	nextSteps = append(nextSteps, leftover...)
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title:     i18n.T("✓ Converted %d setup(s) into workspaces", len(converted)),
+		Items:     items,
+		NextSteps: nextSteps,
+	})
+}
+
+// printExistingSetup prints where a proposal comes from
+func printExistingSetup(s existingSetup, sshPath, gitPath string) {
+	if s.Host != nil {
+		fmt.Printf("   Host %s (%s, key %s)  from %s line %d\n", s.Alias, s.HostName, s.IdentityFile, sshPath, s.Host.Lines.Start+1)
+	}
+	if s.Include != nil {
+		identity := s.Email
+		if s.UserName != "" {
+			identity = fmt.Sprintf("%s <%s>", s.UserName, s.Email)
+		}
+		fmt.Printf("   Root %s, %s  from [includeIf %q] in %s line %d\n", s.Root, identity, s.Include.Condition, gitPath, s.Include.Lines.Start+1)
+	}
+}
+
+// existingWorkspace builds the workspace for a proposal, keeping its
+// alias, key and directory
+func existingWorkspace(p migrate.Proposal) (config.Workspace, error) {
+	keyPath, err := workspace.ExpandPath(p.IdentityFile)
+	if err != nil {
+		return config.Workspace{}, err
+	}
+	root, err := workspace.ExpandPath(p.Root)
+	if err != nil {
+		return config.Workspace{}, err
+	}
+
+	def := config.Workspace{
+		Email:    p.Email,
+		Name:     p.UserName,
+		HostName: p.HostName,
+		SSHAlias: p.Alias,
+		SSHKey:   keyPath,
+		Root:     root,
+	}
+	for provider, host := range workspace.ProviderHosts {
+		if strings.EqualFold(host, p.HostName) {
+			def.Provider = provider
+		}
+	}
+	ws, err := resolveWorkspace(p.Name, def)
+	if err != nil {
+		return ws, err
+	}
+
+	// The key stays under the user's control, where the Host block had it
+	if info, err := ssh.InspectKey(keyPath); err == nil {
+		ws.KeySource = &config.KeySource{
+			Path:        keyPath,
+			Mode:        keyModeReference,
+			Type:        info.Type,
+			Fingerprint: info.Fingerprint,
+			ImportedAt:  time.Now().UTC().Truncate(time.Second),
+		}
+	}
+	return ws, nil
+}
+
+// workspaceForAlias returns the workspace using alias, if any
+func workspaceForAlias(cfg *config.File, alias string) string {
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		if strings.EqualFold(ws.SSHAlias, alias) {
+			return name
+		}
+		for _, h := range ws.Hosts {
+			if strings.EqualFold(h.SSHAlias, alias) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// includedFile resolves the path of an include the way git does: ~/ is
+// the home directory and a relative path is relative to the including file
+func includedFile(path, from string) string {
+	if expanded, err := workspace.ExpandPath(path); err == nil {
+		path = expanded
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	return path
+}
+
+// droppedIncludeKeys returns the settings of a formerly included file the
+// workspace gitconfig does not take over
+func droppedIncludeKeys(path string) []string {
+	keys, err := git.FileConfigKeys(path)
+	if err != nil {
+		return nil
+	}
+	var dropped []string
+	for _, key := range keys {
+		if key != "user.email" && key != "user.name" {
+			dropped = append(dropped, key)
+		}
+	}
+	return dropped
+}
+
+// readOptional reads a file that may not exist
+func readOptional(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// removeConfigLines removes line ranges from a config file, after backing
+// it up
+func removeConfigLines(path string, ranges []migrate.Lines) error {
+	lock, err := fsutil.LockFile(path, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.CreateBackup(path); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return fsutil.AtomicWrite(path, []byte(migrate.RemoveLines(string(data), ranges)), info.Mode().Perm())
+}
@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/gitworkspaces/gitws/internal/compat"
 	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/email"
 	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/notify"
 	"github.com/gitworkspaces/gitws/internal/prompt"
 	"github.com/gitworkspaces/gitws/internal/ssh"
 	"github.com/gitworkspaces/gitws/internal/workspace"
@@ -15,15 +23,30 @@ import (
 )
 
 var (
-	initEmail     string
-	initHost      string
-	initHostName  string
-	initRoot      string
-	initSigning   string
-	initName      string
-	initForce     bool
-	initRotateKey bool
-	initGPGKey    string
+	initEmail           string
+	initHost            string
+	initHostName        string
+	initRoot            string
+	initSigning         string
+	initName            string
+	initForce           bool
+	initRotateKey       bool
+	initGPGKey          string
+	initIsolation       string
+	initNoMXCheck       bool
+	initNoUseConfigOnly bool
+	initKeyFile         string
+	initKeyMode         string
+	initDefaultBranch   string
+	initPullStrategy    string
+	initCommitTemplate  string
+	initMaxKeyAge       string
+	initEnvFile         string
+	initExtraHosts      []string
+	initTransport       string
+	initCopy            bool
+	initQR              bool
+	initUseNoreply      bool
 )
 
 // initCmd represents the init command
@@ -33,15 +56,42 @@ var initCmd = &cobra.Command{
 	Long: `Initialize a new Git workspace with separate SSH keys and configuration.
 
 This command will:
-- Generate a new SSH key pair for the workspace
+- Generate a new SSH key pair for the workspace, or use one provided with --key-file
 - Configure SSH aliases in ~/.ssh/config
 - Set up Git configuration isolation
 - Create workspace-specific settings
 
+Nothing is written until pre-flight checks pass: ssh-keygen is installed,
+~/.ssh, ~/.gitconfig and ~/.gws are writable, no managed file holds an
+unresolved merge conflict, and there is free disk space. Every problem
+found is reported at once.
+
+--transport https is for networks that block SSH: git authenticates to the
+workspace's hosts over HTTPS with the token stored by 'gitws auth login',
+through 'gitws credential-helper'. clone and fix then point remotes at
+https://<host>/<org>/<repo>.git instead of the SSH alias; it needs the
+default gitdir isolation. An SSH key is still created for signing and
+for when SSH is reachable again.
+
+--use-noreply commits with the account's noreply address instead of
+--email, which is kept as the primary email (see 'gitws email'). The
+address is looked up through the provider API, so it needs a token in
+GWS_TOKEN_<WORKSPACE>; otherwise run 'gitws email use-noreply' after
+'gitws auth login'.
+
 Examples:
   gitws init work --email you@work.com --host github
   gitws init personal --email you@me.com --host github --signing ssh
-  gitws init client --email you@client.com --host-name gitlab.client.com`,
+  gitws init client --email you@client.com --host-name gitlab.client.com
+  gitws init oss --email you@me.com --host github --isolation hasconfig
+  gitws init work --email you@work.com --host github --default-branch main --pull rebase
+  gitws init work --email you@work.com --host github --key-file ~/Downloads/sso_key
+  gitws init work --email you@work.com --host github --env-file envrc
+  gitws init work --email you@work.com --host github --extra-host gitlab.work.com
+  gitws init work --email you@work.com --host github --transport https
+  gitws init work --email you@work.com --host github --copy --qr
+  GWS_TOKEN_OSS=... gitws init oss --email you@me.com --host github --use-noreply
+  gitws init work --email you@work.com --host github --format markdown`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInit,
 }
@@ -53,11 +103,26 @@ func init() {
 	initCmd.Flags().StringVar(&initHost, "host", "", "Git provider (github, gitlab, bitbucket)")
 	initCmd.Flags().StringVar(&initHostName, "host-name", "", "Custom hostname (mutually exclusive with --host)")
 	initCmd.Flags().StringVar(&initRoot, "root", "", "Workspace root directory (default: ~/code/<workspace>)")
-	initCmd.Flags().StringVar(&initSigning, "signing", "none", "Signing method (none, ssh, gpg)")
+	initCmd.Flags().StringVar(&initSigning, "signing", "", "Signing method (none, ssh, gpg) (default \"none\" unless the system config sets one)")
 	initCmd.Flags().StringVar(&initName, "name", "", "Display name (defaults to workspace name or $USER)")
 	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite existing managed blocks")
 	initCmd.Flags().BoolVar(&initRotateKey, "rotate-key", false, "Generate new SSH key even if one exists")
 	initCmd.Flags().StringVar(&initGPGKey, "gpg-key", "", "GPG key ID for signing (required with --signing gpg)")
+	initCmd.Flags().BoolVar(&initNoMXCheck, "no-mx-check", false, "Skip the DNS check that the email domain can receive mail")
+	initCmd.Flags().StringVar(&initKeyFile, "key-file", "", "Use this existing private key instead of generating one")
+	initCmd.Flags().StringVar(&initKeyMode, "key-mode", keyModeCopy, "How to use --key-file: copy it to ~/.ssh, or reference it in place")
+	initCmd.Flags().StringVar(&initDefaultBranch, "default-branch", "", "Default branch for new repositories (init.defaultBranch)")
+	initCmd.Flags().StringVar(&initPullStrategy, "pull", "", "Pull strategy (merge, rebase, ff-only)")
+	initCmd.Flags().StringVar(&initCommitTemplate, "commit-template", "", "Commit message template file")
+	initCmd.Flags().StringVar(&initMaxKeyAge, "max-key-age", "", "Warn when the SSH key is older than this, e.g. 90d")
+	initCmd.Flags().StringVar(&initEnvFile, "env-file", "", "Keep a managed environment file at the workspace root (envrc)")
+	initCmd.Flags().BoolVar(&initNoUseConfigOnly, "no-use-config-only", false, "Don't offer to set user.useConfigOnly=true globally")
+	initCmd.Flags().StringSliceVar(&initExtraHosts, "extra-host", nil, "Additional host served by this workspace, with its own SSH alias (repeatable)")
+	initCmd.Flags().StringVar(&initTransport, "transport", "", "How repositories reach the host (ssh, https) (default \"ssh\")")
+	initCmd.Flags().BoolVar(&initCopy, "copy", false, "Copy the public key to the clipboard")
+	initCmd.Flags().BoolVar(&initQR, "qr", false, "Also show the public key as a QR code, to scan from a phone")
+	initCmd.Flags().BoolVar(&initUseNoreply, "use-noreply", false, "Commit with the provider's noreply address, keeping --email as the primary email")
+	initCmd.Flags().StringVar(&initIsolation, "isolation", "", "Identity isolation mode (gitdir, hasconfig) (default \"gitdir\" unless the system config sets one)")
 
 	initCmd.MarkFlagRequired("email")
 	initCmd.MarkFlagsMutuallyExclusive("host", "host-name")
@@ -66,114 +131,197 @@ func init() {
 func runInit(cmd *cobra.Command, args []string) error {
 	workspaceName := args[0]
 
-	// Validate inputs
-	if initHost == "" && initHostName == "" {
+	// Validate inputs; a machine-wide install may supply the provider
+	system, err := config.LoadSystem()
+	if err != nil {
+		return err
+	}
+	if initHost == "" && initHostName == "" && system.Defaults.Provider == "" && system.Defaults.HostName == "" {
 		return fmt.Errorf("either --host or --host-name must be specified")
 	}
 
-	if initSigning == "gpg" && initGPGKey == "" {
-		return fmt.Errorf("--gpg-key is required when using --signing gpg")
+	if initKeyMode != keyModeCopy && initKeyMode != keyModeReference {
+		return fmt.Errorf("invalid key mode: %s (must be copy or reference)", initKeyMode)
 	}
 
-	// Resolve hostname
-	var hostName string
-	if initHost != "" {
-		if host, exists := workspace.ProviderHosts[initHost]; exists {
-			hostName = host
-		} else {
-			return fmt.Errorf("unknown provider: %s (supported: github, gitlab, bitbucket)", initHost)
-		}
-	} else {
-		hostName = initHostName
+	// Resolve derived settings (hostname, alias, root, display name)
+	ws, err := resolveWorkspace(workspaceName, config.Workspace{
+		Email:     initEmail,
+		Provider:  initHost,
+		HostName:  initHostName,
+		Root:      initRoot,
+		Signing:   initSigning,
+		Name:      initName,
+		GPGKey:    initGPGKey,
+		Isolation: initIsolation,
+		Transport: initTransport,
+
+		DefaultBranch:  initDefaultBranch,
+		PullStrategy:   initPullStrategy,
+		CommitTemplate: initCommitTemplate,
+		MaxKeyAge:      initMaxKeyAge,
+		EnvFile:        initEnvFile,
+		Hosts:          parseExtraHosts(initExtraHosts),
+	})
+	if err != nil {
+		return err
 	}
 
-	// Build SSH alias
-	providerOrHost := initHost
-	if providerOrHost == "" {
-		providerOrHost = initHostName
+	if ws.Isolation == workspace.IsolationHasconfig {
+		warnHasconfigFallback(workspaceName, ws)
 	}
-	alias := workspace.BuildSSHAlias(providerOrHost, workspaceName)
-
-	// Set default root if not provided
-	root := initRoot
-	if root == "" {
-		var err error
-		root, err = workspace.DefaultRoot(workspaceName)
-		if err != nil {
-			return fmt.Errorf("failed to get default root: %w", err)
+	if ws.Signing == "ssh" {
+		if err := compat.Require(compat.SSHSigning); err != nil {
+			return fmt.Errorf("--signing ssh: %w", err)
 		}
 	}
 
-	// Expand root path
-	expandedRoot, err := workspace.ExpandPath(root)
-	if err != nil {
-		return fmt.Errorf("failed to expand root path: %w", err)
+	// The email ends up in the key comment, SSH config, and gitconfig, so
+	// catch likely mistakes before anything is written
+	if ok, err := confirmEmail(ws.Email, !initNoMXCheck); err != nil {
+		return err
+	} else if !ok {
+		fmt.Println("Init cancelled.")
+		return nil
 	}
 
-	// Set display name
-	displayName := initName
-	if displayName == "" {
-		displayName = workspaceName
-		if user := os.Getenv("USER"); user != "" {
-			displayName = user
+	// Commit as the noreply address; --email stays the account's own
+	if initUseNoreply {
+		if token, err := workspaceToken(workspaceName); err == nil && token == "" {
+			return fmt.Errorf("--use-noreply needs an API token to look up the address: set %s, or init without it and run 'gitws auth login %s' and 'gitws email use-noreply %s'", tokenEnvVar(workspaceName), workspaceName, workspaceName)
 		}
+		noreply, err := fetchNoreplyEmail(cmd.Context(), workspaceName, ws)
+		if err != nil {
+			return fmt.Errorf("--use-noreply: %w", err)
+		}
+		ws.PrimaryEmail, ws.Email = ws.Email, noreply
 	}
 
 	// Load existing config
-	cfg, err := config.Load()
+	cfg, unlock, err := config.LoadLocked()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	defer unlock()
 
 	// Check if workspace already exists
-	if _, exists := cfg.GetWorkspace(workspaceName); exists && !initForce {
+	existing, exists := cfg.GetWorkspace(workspaceName)
+	if exists && !initForce {
 		return fmt.Errorf("workspace %q already exists (use --force to overwrite)", workspaceName)
 	}
+	if other := workspaceWithRoot(cfg, workspaceName, ws.Root); other != "" {
+		return fmt.Errorf("workspace %q already uses root %s; choose another with --root", other, ws.Root)
+	}
+	if err := runPreflight(cmd.Context(), preflightChecks(cfg, true)); err != nil {
+		return err
+	}
 
-	// Generate SSH key
-	privPath, pubPath, keyCreated, err := ssh.EnsureKey(workspaceName, initEmail)
-	if err != nil {
-		return fmt.Errorf("failed to ensure SSH key: %w", err)
+	// Use the provided key, or generate one
+	var privPath, pubPath string
+	var keyCreated bool
+	if initKeyFile != "" {
+		privPath, pubPath, err = useProvidedKey(workspaceName, &ws)
+		if err != nil {
+			return err
+		}
+	} else {
+		privPath, pubPath, keyCreated, err = ssh.EnsureKey(workspaceName, ws.Email)
+		if err != nil {
+			return fmt.Errorf("failed to ensure SSH key: %w", err)
+		}
 	}
 
 	// Rotate key if requested
-	if initRotateKey && !keyCreated {
-		// TODO: Implement key rotation with backup
-		return fmt.Errorf("key rotation not yet implemented")
+	if initRotateKey && !keyCreated && initKeyFile == "" {
+		if err := backupExistingKey(privPath); err != nil {
+			return fmt.Errorf("failed to backup existing key: %w", err)
+		}
+		privPath, pubPath, keyCreated, err = ssh.EnsureKey(workspaceName, ws.Email)
+		if err != nil {
+			return fmt.Errorf("failed to generate new key: %w", err)
+		}
+		notifyEvent(cfg, notify.EventKeyRotated, workspaceName, fmt.Sprintf("SSH key for %s rotated during init; the old key was moved aside", ws.HostName))
+	}
+
+	// A kept key may still carry the identity it was created with; a
+	// provided key's comment belongs to whoever issued it
+	if !keyCreated && initKeyFile == "" {
+		if err := reconcileKeyComment(workspaceName, ws.Email, privPath); err != nil {
+			return err
+		}
+	}
+
+	// ssh fails obscurely on keys a network mount exposes; say so now
+	for _, issue := range keyFilesystemIssues(privPath) {
+		fmt.Printf(prompt.Text("⚠️  %s\n   Fix: %s\n"), issue.Message, issue.Fix)
+	}
+
+	// A kept key keeps its age
+	if keyCreated || initKeyFile != "" {
+		ws.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+	} else if exists && existing.SSHKey == privPath {
+		ws.KeyCreatedAt = existing.KeyCreatedAt
+	}
+
+	// env has no flag, so re-initializing keeps it
+	if exists && ws.Env == nil {
+		ws.Env = existing.Env
+	}
+	// ...and so do identities, and extra hosts unless --extra-host is given
+	if exists {
+		ws.Identities = existing.Identities
+		if !cmd.Flags().Changed("extra-host") {
+			ws.Hosts = existing.Hosts
+		}
 	}
 
 	// Update SSH config
-	if err := ssh.UpsertSSHConfigBlock(workspaceName, alias, hostName, privPath); err != nil {
+	ws.SSHKey = privPath
+	if err := ssh.UpsertSSHConfigBlock(workspaceName, ws.SSHHosts(time.Now()), ws.HostName, privPath); err != nil {
 		return fmt.Errorf("failed to update SSH config: %w", err)
 	}
-
-	// Update global gitconfig with includeIf
-	if err := updateGlobalGitConfig(workspaceName, expandedRoot); err != nil {
-		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	if exists {
+		if err := removeDroppedSSHBlocks(workspaceName, existing, ws); err != nil {
+			return err
+		}
+	}
+	if err := writeExtraSSHBlocks(workspaceName, ws); err != nil {
+		return err
 	}
 
 	// Create workspace gitconfig
-	if err := createWorkspaceGitConfig(workspaceName, displayName, initEmail, initSigning, privPath, initGPGKey); err != nil {
+	if err := createWorkspaceGitConfig(workspaceName, ws); err != nil {
 		return fmt.Errorf("failed to create workspace gitconfig: %w", err)
 	}
 
-	// Save workspace config
-	ws := config.Workspace{
-		Email:    initEmail,
-		Provider: initHost,
-		HostName: hostName,
-		SSHAlias: alias,
-		SSHKey:   privPath,
-		Root:     expandedRoot,
-		Signing:  initSigning,
-		Name:     displayName,
+	if err := writeWorkspaceEnvrc(workspaceName, ws); err != nil {
+		return fmt.Errorf("failed to write workspace .envrc: %w", err)
 	}
+
+	// Save workspace config
 	cfg.SetWorkspace(workspaceName, ws)
+	warnNestedRoots(cfg, workspaceName)
+
+	// Update global gitconfig with includeIf entries for all workspaces
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
 
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
+	// Keep the global guard aware of the new workspace
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+
+	if !initNoUseConfigOnly {
+		if err := offerUseConfigOnly(); err != nil {
+			return err
+		}
+	}
+
 	// Get public key for display
 	publicKey, err := ssh.GetPublicKey(pubPath)
 	if err != nil {
@@ -182,32 +330,86 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Show summary
 	summary := prompt.SummaryData{
-		Title: fmt.Sprintf("✓ Workspace '%s' initialized successfully", workspaceName),
+		Title: i18n.T("✓ Workspace '%s' initialized successfully", workspaceName),
 		Items: []prompt.SummaryItem{
-			{Label: "SSH Alias", Value: alias, Icon: "🔑"},
-			{Label: "Host", Value: hostName, Icon: "🌐"},
-			{Label: "Root", Value: expandedRoot, Icon: "📁"},
-			{Label: "Email", Value: initEmail, Icon: "📧"},
-			{Label: "Signing", Value: initSigning, Icon: "✍️"},
+			{Label: "SSH Alias", Value: ws.SSHAlias, Icon: "🔑"},
+			{Label: "Host", Value: ws.HostName, Icon: "🌐"},
+			{Label: "Root", Value: ws.Root, Icon: "📁"},
+			{Label: "Email", Value: ws.Email, Icon: "📧"},
+			{Label: "Signing", Value: ws.Signing, Icon: "✍️"},
+			{Label: "Isolation", Value: isolationDisplay(ws.Isolation), Icon: "🛡️"},
 		},
-		PublicKey: publicKey,
+		PublicKey:     publicKey,
+		QR:            initQR,
+		CopyPublicKey: initCopy,
 		NextSteps: []string{
-			fmt.Sprintf("Add the public key to your %s account", hostName),
+			fmt.Sprintf("Add the public key to your %s account", ws.HostName),
 			fmt.Sprintf("Use 'gitws clone %s ORG/REPO' to clone repositories", workspaceName),
 			"Run 'gitws status' to check repository configuration",
 		},
 	}
 
+	if state, _, err := ssh.CheckKnownHost(cmd.Context(), ws.HostName); err == nil && state == ssh.HostKeyMissing {
+		summary.NextSteps = append(summary.NextSteps, fmt.Sprintf("Trust the host key before the first clone: gitws known-hosts %s", ws.HostName))
+	}
+
+	if ws.PrimaryEmail != "" {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Primary Email", Value: ws.PrimaryEmail, Icon: "📧"})
+	}
+	for _, h := range ws.Hosts {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Extra Host", Value: fmt.Sprintf("%s (%s)", h.HostName, h.SSHAlias), Icon: "🌐"})
+	}
+	if ws.DefaultBranch != "" {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Default Branch", Value: ws.DefaultBranch, Icon: "🌿"})
+	}
+	if ws.PullStrategy != "" {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Pull Strategy", Value: ws.PullStrategy, Icon: "⬇️"})
+	}
+	if ws.Transport == workspace.TransportHTTPS {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Transport", Value: ws.Transport, Icon: "🔒"})
+		summary.NextSteps[0] = fmt.Sprintf("Store an HTTPS token for %s: gitws auth login %s", ws.HostName, workspaceName)
+	}
+	if ws.EnvFile == envFileEnvrc {
+		summary.Items = append(summary.Items, prompt.SummaryItem{Label: "Env File", Value: envrcPath(ws), Icon: "🌱"})
+		summary.NextSteps = append(summary.NextSteps, fmt.Sprintf("Allow the workspace environment: direnv allow %s", ws.Root))
+	}
+
 	return prompt.ShowSummary(summary)
 }
 
-func updateGlobalGitConfig(workspaceName, root string) error {
-	home, err := os.UserHomeDir()
+// offerUseConfigOnly offers to set user.useConfigOnly=true globally, so
+// that a repo outside every workspace fails to commit instead of silently
+// committing under a guessed identity
+func offerUseConfigOnly() error {
+	if value, _ := git.GetGlobalConfig("user.useConfigOnly"); value == "true" {
+		return nil
+	}
+
+	confirmed, err := prompt.Confirm("Set user.useConfigOnly=true globally so git refuses to commit when no workspace identity applies?")
+	if err != nil || !confirmed {
+		return err
+	}
+
+	if err := git.SetGlobalConfig("user.useConfigOnly", "true"); err != nil {
+		return fmt.Errorf("failed to set user.useConfigOnly: %w", err)
+	}
+	fmt.Println(prompt.Text("✓ Set user.useConfigOnly=true in global gitconfig"))
+	return nil
+}
+
+func updateGlobalGitConfig(cfg *config.File) error {
+	gitConfigPath, err := globalGitConfigPath()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
 
-	gitConfigPath := filepath.Join(home, ".gitconfig")
+	// Another gitws may be rewriting the block; git's own
+	// ~/.gitconfig.lock only covers git config
+	lock, err := fsutil.LockFile(gitConfigPath, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 
 	// Read existing config
 	var content string
@@ -224,28 +426,14 @@ func updateGlobalGitConfig(workspaceName, root string) error {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Build includeIf condition
-	condition, err := workspace.BuildIncludeIfCondition(root)
+	newBlock, err := buildIncludeIfBlock(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to build includeIf condition: %w", err)
+		return err
 	}
 
-	// Get gitconfig path
-	gitConfigWorkspacePath, err := workspace.GitConfigPath(workspaceName)
-	if err != nil {
-		return fmt.Errorf("failed to get workspace gitconfig path: %w", err)
-	}
-
-	// Build new block
+	// Replace content between markers
 	startMarker := workspace.IncludeIfStartMarker()
 	endMarker := workspace.IncludeIfEndMarker()
-
-	newBlock := fmt.Sprintf(`%s
-[includeIf "%s"]
-  path = %s
-%s`, startMarker, condition, gitConfigWorkspacePath, endMarker)
-
-	// Replace content between markers
 	newContent, _ := fsutil.ReplaceBetweenMarkers(content, startMarker, endMarker, newBlock)
 
 	// Write updated config
@@ -256,7 +444,112 @@ func updateGlobalGitConfig(workspaceName, root string) error {
 	return nil
 }
 
-func createWorkspaceGitConfig(workspaceName, displayName, email, signing, keyPath, gpgKey string) error {
+// buildIncludeIfBlock renders the managed includeIf block covering every workspace
+func buildIncludeIfBlock(cfg *config.File) (string, error) {
+	names := includeIfOrder(cfg)
+
+	var block strings.Builder
+	block.WriteString(workspace.IncludeIfStartMarker())
+	block.WriteString("\n")
+
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+
+		conditions, err := includeIfConditions(ws)
+		if err != nil {
+			return "", fmt.Errorf("failed to build includeIf condition for %q: %w", name, err)
+		}
+
+		gitConfigWorkspacePath, err := workspace.GitConfigPath(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to get workspace gitconfig path: %w", err)
+		}
+
+		for _, condition := range conditions {
+			block.WriteString(fmt.Sprintf("[includeIf \"%s\"]\n", condition))
+			block.WriteString(fmt.Sprintf("  path = %s\n", gitConfigWorkspacePath))
+		}
+	}
+
+	block.WriteString(workspace.IncludeIfEndMarker())
+	return block.String(), nil
+}
+
+// includeIfConditions returns the includeIf conditions for a workspace's
+// isolation mode: one per SSH alias for hasconfig, so remotes on any of the
+// workspace's hosts match. hasconfig workspaces fall back to their root on
+// a git that would silently ignore the hasconfig condition.
+func includeIfConditions(ws config.Workspace) ([]string, error) {
+	if ws.Isolation == workspace.IsolationHasconfig && compat.Supported(compat.Hasconfig) {
+		var conditions []string
+		for _, alias := range ws.Aliases() {
+			conditions = append(conditions, workspace.BuildHasconfigCondition(alias))
+		}
+		return conditions, nil
+	}
+	condition, err := workspace.BuildIncludeIfCondition(ws.Root)
+	if err != nil {
+		return nil, err
+	}
+	return []string{condition}, nil
+}
+
+// confirmEmail warns about likely typos and undeliverable domains in addr
+// and asks whether to continue anyway. A suggested domain is only shown:
+// addr is used as given, and without a terminal the warning is all there is.
+func confirmEmail(addr string, checkMX bool) (bool, error) {
+	domain := email.Domain(addr)
+
+	var warning string
+	if suggestion, ok := email.SuggestDomain(domain); ok {
+		warning = fmt.Sprintf("Email domain %q looks like a typo of %q", domain, suggestion)
+	} else if checkMX {
+		if err := email.CheckDomain(domain, 3*time.Second); errors.Is(err, email.ErrNoMailServer) {
+			warning = fmt.Sprintf("Email domain %q has no mail server (use --no-mx-check to skip this check)", domain)
+		}
+	}
+
+	if warning == "" {
+		return true, nil
+	}
+
+	fmt.Printf(prompt.Text("⚠️  %s\n"), warning)
+	confirmed, err := prompt.Confirm(fmt.Sprintf("Continue with %s anyway?", addr))
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	return confirmed, nil
+}
+
+// followsRemote reports whether a workspace's identity follows the remote
+// URL rather than the workspace root
+func followsRemote(ws config.Workspace) bool {
+	return ws.Isolation == workspace.IsolationHasconfig && compat.Supported(compat.Hasconfig)
+}
+
+// warnHasconfigFallback tells the user when a hasconfig workspace has to
+// fall back to directory-based isolation. Older git ignores the condition
+// without an error.
+func warnHasconfigFallback(name string, ws config.Workspace) {
+	if err := compat.Require(compat.Hasconfig); err != nil {
+		fmt.Printf(prompt.Text("⚠️  %v; workspace '%s' uses directory-based isolation under %s until git is upgraded\n"), err, name, ws.Root)
+	}
+}
+
+// warnNestedRoots tells the user when a workspace root lies inside
+// another's, naming only the pairs involving name unless name is empty
+func warnNestedRoots(cfg *config.File, name string) {
+	for _, overlap := range nestedRoots(cfg) {
+		if name != "" && name != overlap.Outer && name != overlap.Inner {
+			continue
+		}
+		inner, outer := cfg.Workspaces[overlap.Inner], cfg.Workspaces[overlap.Outer]
+		fmt.Printf(prompt.Text("⚠️  Workspace '%s' (%s) is nested inside workspace '%s' (%s)\n"), overlap.Inner, inner.Root, overlap.Outer, outer.Root)
+		fmt.Printf("   Repositories under %s use '%s', but settings only '%s' sets still apply there\n", inner.Root, overlap.Inner, overlap.Outer)
+	}
+}
+
+func createWorkspaceGitConfig(workspaceName string, ws config.Workspace) error {
 	// Ensure directory exists
 	gitConfigPath, err := workspace.GitConfigPath(workspaceName)
 	if err != nil {
@@ -268,12 +561,32 @@ func createWorkspaceGitConfig(workspaceName, displayName, email, signing, keyPat
 		return fmt.Errorf("failed to create gitconfig directory: %w", err)
 	}
 
+	content, err := renderWorkspaceGitConfig(workspaceName, ws)
+	if err != nil {
+		return err
+	}
+
+	// Write gitconfig
+	if err := fsutil.AtomicWrite(gitConfigPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write workspace gitconfig: %w", err)
+	}
+
+	// Write the excludes and attributes files it points at
+	if err := writeWorkspacePatternFiles(workspaceName, ws); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// renderWorkspaceGitConfig returns the content of a workspace gitconfig
+func renderWorkspaceGitConfig(workspaceName string, ws config.Workspace) (string, error) {
 	// Build gitconfig content
 	var content strings.Builder
 
 	content.WriteString("[user]\n")
-	content.WriteString(fmt.Sprintf("  name = %s\n", displayName))
-	content.WriteString(fmt.Sprintf("  email = %s\n", email))
+	content.WriteString(fmt.Sprintf("  name = %s\n", ws.Name))
+	content.WriteString(fmt.Sprintf("  email = %s\n", ws.Email))
 	content.WriteString("\n")
 
 	content.WriteString("[commit]\n")
@@ -281,29 +594,154 @@ func createWorkspaceGitConfig(workspaceName, displayName, email, signing, keyPat
 	content.WriteString("\n")
 
 	// Add signing configuration
-	switch signing {
+	switch ws.Signing {
 	case "ssh":
 		content.WriteString("[gpg]\n")
 		content.WriteString("  format = ssh\n")
 		content.WriteString("\n")
 		content.WriteString("[user]\n")
-		content.WriteString(fmt.Sprintf("  signingkey = %s.pub\n", keyPath))
+		content.WriteString(fmt.Sprintf("  signingkey = %s.pub\n", ws.SSHKey))
 		content.WriteString("\n")
 		content.WriteString("[commit]\n")
 		content.WriteString("  gpgsign = true\n")
 		content.WriteString("\n")
 	case "gpg":
 		content.WriteString("[user]\n")
-		content.WriteString(fmt.Sprintf("  signingkey = %s\n", gpgKey))
+		content.WriteString(fmt.Sprintf("  signingkey = %s\n", ws.GPGKey))
 		content.WriteString("\n")
 		content.WriteString("[commit]\n")
 		content.WriteString("  gpgsign = true\n")
 		content.WriteString("\n")
 	}
 
-	// Write gitconfig
-	if err := fsutil.AtomicWrite(gitConfigPath, []byte(content.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write workspace gitconfig: %w", err)
+	// Add workspace policy
+	if ws.DefaultBranch != "" {
+		content.WriteString("[init]\n")
+		content.WriteString(fmt.Sprintf("  defaultBranch = %s\n", ws.DefaultBranch))
+		content.WriteString("\n")
+	}
+	if rebase, ff := workspace.PullConfig(ws.PullStrategy); rebase != "" || ff != "" {
+		content.WriteString("[pull]\n")
+		if rebase != "" {
+			content.WriteString(fmt.Sprintf("  rebase = %s\n", rebase))
+		}
+		if ff != "" {
+			content.WriteString(fmt.Sprintf("  ff = %s\n", ff))
+		}
+		content.WriteString("\n")
+	}
+	if ws.CommitTemplate != "" {
+		content.WriteString("[commit]\n")
+		content.WriteString(fmt.Sprintf("  template = %s\n", ws.CommitTemplate))
+		content.WriteString("\n")
+	}
+
+	// Add workspace excludes and attributes
+	if len(ws.Ignore) > 0 || len(ws.Attributes) > 0 {
+		content.WriteString("[core]\n")
+		if len(ws.Ignore) > 0 {
+			path, err := workspace.ExcludesFilePath(workspaceName)
+			if err != nil {
+				return "", err
+			}
+			content.WriteString(fmt.Sprintf("  excludesFile = %s\n", path))
+		}
+		if len(ws.Attributes) > 0 {
+			path, err := workspace.AttributesFilePath(workspaceName)
+			if err != nil {
+				return "", err
+			}
+			content.WriteString(fmt.Sprintf("  attributesFile = %s\n", path))
+		}
+		content.WriteString("\n")
+	}
+
+	// HTTPS authenticates with the workspace token. The empty helper
+	// drops helpers configured before, so a global keychain cannot answer
+	// with another account's credentials.
+	if ws.Transport == workspace.TransportHTTPS {
+		helper, err := credentialHelper(workspaceName)
+		if err != nil {
+			return "", err
+		}
+		for _, host := range ws.HostNames() {
+			content.WriteString(fmt.Sprintf("[credential \"https://%s\"]\n", host))
+			content.WriteString("  helper =\n")
+			content.WriteString(fmt.Sprintf("  helper = %s\n", configQuote(helper)))
+			content.WriteString(fmt.Sprintf("  username = %s\n", credentialUsername(ws, host)))
+			content.WriteString("\n")
+		}
+	}
+
+	return content.String(), nil
+}
+
+// credentialHelper returns the credential.helper value serving the
+// workspace's token. gitws is named by the path the shell finds it
+// under when that is this binary, so package upgrades that replace the
+// file behind a symlink keep working.
+func credentialHelper(workspaceName string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate gitws: %w", err)
+	}
+	if found, err := exec.LookPath("gitws"); err == nil {
+		if a, err := os.Stat(found); err == nil {
+			if b, err := os.Stat(exe); err == nil && os.SameFile(a, b) {
+				exe, _ = filepath.Abs(found)
+			}
+		}
+	}
+	return git.CredentialHelper(exe, "credential-helper", "--workspace", workspaceName), nil
+}
+
+// configQuote quotes a gitconfig value when it holds characters git
+// would read as a comment, quote or escape
+func configQuote(value string) string {
+	if !strings.ContainsAny(value, "\"\\#;") && strings.TrimSpace(value) == value {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// renderPatternFile returns the content of a workspace excludes or
+// attributes file. Git reads only one such file, so within the workspace
+// it replaces the global one.
+func renderPatternFile(workspaceName string, lines []string) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Managed by gitws for workspace %s. Edit config.yaml and run 'gitws apply'.\n", workspaceName))
+	for _, line := range lines {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// writeWorkspacePatternFiles writes the workspace excludes and attributes
+// files for the patterns configured on ws
+func writeWorkspacePatternFiles(workspaceName string, ws config.Workspace) error {
+	files := []struct {
+		lines []string
+		path  func(string) (string, error)
+	}{
+		{ws.Ignore, workspace.ExcludesFilePath},
+		{ws.Attributes, workspace.AttributesFilePath},
+	}
+
+	for _, f := range files {
+		if len(f.lines) == 0 {
+			continue
+		}
+		path, err := f.path(workspaceName)
+		if err != nil {
+			return err
+		}
+		if err := fsutil.EnsureDir(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to create workspace directory: %w", err)
+		}
+		if err := fsutil.AtomicWrite(path, []byte(renderPatternFile(workspaceName, f.lines)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+)
+
+// ExitInterrupted is the exit code after Ctrl-C, as shells report for SIGINT
+const ExitInterrupted = 130
+
+// interruptGrace is how long a command has to stop after Ctrl-C before
+// gitws rolls back and exits without waiting for it
+const interruptGrace = 3 * time.Second
+
+// executeInterruptible runs the command tree with a context that is
+// cancelled on Ctrl-C or SIGTERM. Files the command changed before it was
+// interrupted are restored from the journal; a second Ctrl-C exits at once.
+func executeInterruptible() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var once sync.Once
+	rollback := func() {
+		once.Do(func() {
+			restored, err := fsutil.Rollback()
+			for _, path := range restored {
+				fmt.Fprintf(os.Stderr, prompt.Text("↩️  Restored %s\n"), path)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, prompt.Text("⚠️  Could not undo every change: %v\n"), err)
+			}
+		})
+	}
+
+	// stop cancels ctx as well, so the watcher must have exited before the
+	// deferred stop runs, or it could take a normal return for Ctrl-C
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	defer func() {
+		close(done)
+		<-exited
+	}()
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		// Restore default handling so a second Ctrl-C kills gitws
+		stop()
+		fmt.Fprintln(os.Stderr, prompt.Text("\n⚠️  Interrupted, stopping (press Ctrl-C again to quit immediately)"))
+		select {
+		case <-done:
+		case <-time.After(interruptGrace):
+			rollback()
+			os.Exit(ExitInterrupted)
+		}
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		rollback()
+		if errors.Is(err, context.Canceled) || errors.Is(err, fsutil.ErrRolledBack) {
+			err = nil
+		}
+		return &ExitError{Code: ExitInterrupted, Err: err}
+	}
+	fsutil.EndJournal()
+	return err
+}
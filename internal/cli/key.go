@@ -0,0 +1,1002 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/gpg"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyCommentText string
+	keyAuditFix    bool
+)
+
+// keyCmd represents the keys command
+var keyCmd = &cobra.Command{
+	Use:     "keys",
+	Aliases: []string{"key"},
+	Short:   "Manage workspace SSH and GPG keys",
+	Long: `Manage the SSH key of each workspace and, for workspaces with
+signing: gpg, its GPG signing key: list them with their fingerprints,
+provider registration and agent state, show, rotate, upload to and remove
+from the provider, and audit them.
+
+Examples:
+  gitws keys list
+  gitws keys show work
+  gitws keys upload work --gpg
+  gitws keys fingerprint work --ssh`,
+}
+
+var keyCommentCmd = &cobra.Command{
+	Use:   "comment",
+	Short: "Show or edit the comment on a workspace SSH key",
+}
+
+var keyCommentShowCmd = &cobra.Command{
+	Use:   "show <workspace>",
+	Short: "Show the comment on a workspace SSH key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKeyCommentShow,
+}
+
+var keyCommentEditCmd = &cobra.Command{
+	Use:   "edit <workspace>",
+	Short: "Update the comment on a workspace SSH key without regenerating it",
+	Long: `Update the comment on a workspace SSH key in place (ssh-keygen -c).
+
+The key material and fingerprint are unchanged, so the key does not need to
+be re-uploaded to your provider. By default the comment is rebuilt from the
+workspace's current email and name.
+
+Examples:
+  gitws keys comment edit work
+  gitws keys comment edit work --comment "me@work.com laptop"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKeyCommentEdit,
+}
+
+var keyAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check permissions, ownership and pairing of workspace SSH keys",
+	Long: `Audit the SSH keys used by workspaces and the ~/.ssh directory.
+
+This command checks:
+- ~/.ssh is mode 0700 and owned by you
+- Private keys are mode 0600 and owned by you
+- Each .pub file holds the public half of its private key
+- Keys referenced by managed SSH config blocks exist on disk
+
+With --fix, modes are tightened and missing or mismatched .pub files are
+rewritten from the private key. Ownership and missing keys need you.
+
+Examples:
+  gitws keys audit
+  gitws keys audit --fix`,
+	Args: cobra.NoArgs,
+	RunE: runKeyAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(keyCmd)
+	keyCmd.AddCommand(keyAuditCmd)
+	keyAuditCmd.Flags().BoolVar(&keyAuditFix, "fix", false, "Repair what can be repaired automatically")
+	keyCmd.AddCommand(keyCommentCmd)
+	keyCommentCmd.AddCommand(keyCommentShowCmd)
+	keyCommentCmd.AddCommand(keyCommentEditCmd)
+
+	keyCommentEditCmd.Flags().StringVar(&keyCommentText, "comment", "", "New comment (default: derived from the workspace identity)")
+}
+
+func runKeyCommentShow(cmd *cobra.Command, args []string) error {
+	ws, err := loadWorkspaceKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	comment, err := ssh.GetKeyComment(ws.SSHKey + ".pub")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(comment)
+	return nil
+}
+
+func runKeyCommentEdit(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	ws, err := loadWorkspaceKey(workspaceName)
+	if err != nil {
+		return err
+	}
+
+	if ws.KeySource != nil && ws.KeySource.Mode == keyModeReference {
+		return fmt.Errorf("the key for workspace %q is referenced from %s and is not modified by gitws", workspaceName, ws.KeySource.Path)
+	}
+
+	comment := keyCommentText
+	if comment == "" {
+		comment = ssh.KeyComment(ws.Email, workspaceName)
+	}
+
+	current, err := ssh.GetKeyComment(ws.SSHKey + ".pub")
+	if err != nil {
+		return err
+	}
+	if current == comment {
+		fmt.Printf(prompt.Text("✓ Key comment is already %q\n"), comment)
+		return nil
+	}
+
+	if err := ssh.SetKeyComment(ws.SSHKey, comment); err != nil {
+		return err
+	}
+
+	fmt.Printf(prompt.Text("✓ Updated key comment: %q -> %q\n"), current, comment)
+	fmt.Println("  The key fingerprint is unchanged; no need to re-upload it.")
+	return nil
+}
+
+// loadWorkspaceKey loads a workspace and checks its key pair exists
+func loadWorkspaceKey(workspaceName string) (config.Workspace, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.Workspace{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return config.Workspace{}, fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	if !fsutil.FileExists(ws.SSHKey) || !fsutil.FileExists(ws.SSHKey+".pub") {
+		return config.Workspace{}, fmt.Errorf("SSH key for workspace %q not found at %s", workspaceName, ws.SSHKey)
+	}
+
+	return ws, nil
+}
+
+// Ways 'init --key-file' can use a provided key
+const (
+	keyModeCopy      = "copy"
+	keyModeReference = "reference"
+)
+
+// useProvidedKey validates the key passed to 'init --key-file' and either
+// copies it to the workspace key path or references it where it is. The
+// key's provenance is recorded on ws.
+func useProvidedKey(workspaceName string, ws *config.Workspace) (privPath, pubPath string, err error) {
+	srcPath, err := workspace.ExpandPath(initKeyFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to expand key path: %w", err)
+	}
+	if srcPath, err = filepath.Abs(srcPath); err != nil {
+		return "", "", fmt.Errorf("failed to resolve key path: %w", err)
+	}
+
+	info, err := ssh.InspectKey(srcPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch initKeyMode {
+	case keyModeReference:
+		// The file stays under its owner's control, so it must be complete
+		if !fsutil.FileExists(srcPath + ".pub") {
+			return "", "", fmt.Errorf("referenced key needs %s.pub next to it (or use --key-mode copy)", srcPath)
+		}
+		privPath, pubPath = srcPath, srcPath+".pub"
+	default:
+		if initRotateKey {
+			keyPath, err := ssh.KeyPath(workspaceName)
+			if err != nil {
+				return "", "", err
+			}
+			if err := backupExistingKey(keyPath); err != nil {
+				return "", "", fmt.Errorf("failed to backup existing key: %w", err)
+			}
+		}
+		if privPath, pubPath, err = ssh.ImportKey(srcPath, workspaceName, info); err != nil {
+			return "", "", err
+		}
+	}
+
+	ws.KeySource = &config.KeySource{
+		Path:        srcPath,
+		Mode:        initKeyMode,
+		Type:        info.Type,
+		Fingerprint: info.Fingerprint,
+		ImportedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+
+	fmt.Printf(prompt.Text("✓ Using provided %s key %s\n"), info.Type, info.Fingerprint)
+	if info.Encrypted {
+		fmt.Println("  The key is passphrase-protected; add it to ssh-agent to avoid repeated prompts")
+	}
+	if info.CertPath != "" {
+		fmt.Printf("  Found certificate %s\n", info.CertPath)
+	}
+
+	return privPath, pubPath, nil
+}
+
+// reconcileKeyComment offers to update an existing key's comment when it no
+// longer matches the workspace identity. The comment is the record of the
+// identity the key was created for.
+func reconcileKeyComment(workspaceName, email, privPath string) error {
+	current, err := ssh.GetKeyComment(privPath + ".pub")
+	if err != nil {
+		return err
+	}
+
+	expected := ssh.KeyComment(email, workspaceName)
+	if current == expected {
+		return nil
+	}
+
+	fmt.Printf(prompt.Text("⚠️  Existing key %s was created as %q, but the workspace identity is now %q\n"), privPath, current, expected)
+	confirmed, err := prompt.Confirm("Update the key comment? The key itself is unchanged and stays registered with your provider.")
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	if !confirmed {
+		fmt.Printf("Keeping the old comment. Run 'gitws keys comment edit %s' later, or 'gitws rotate %s' for a new key.\n", workspaceName, workspaceName)
+		return nil
+	}
+
+	if err := ssh.SetKeyComment(privPath, expected); err != nil {
+		return err
+	}
+	fmt.Printf(prompt.Text("✓ Updated key comment to %q\n"), expected)
+	return nil
+}
+
+// keyFinding is an audit finding attributed to a workspace; Workspace is
+// empty for findings about ~/.ssh itself
+type keyFinding struct {
+	Workspace string
+	ssh.Finding
+}
+
+// auditKeys audits ~/.ssh and every key referenced by a workspace, either
+// in config.yaml or in its managed SSH config block
+func auditKeys(cfg *config.File) []keyFinding {
+	var findings []keyFinding
+	for _, f := range ssh.AuditSSHDir() {
+		findings = append(findings, keyFinding{Finding: f})
+	}
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+	for _, name := range names {
+		paths := []string{cfg.Workspaces[name].SSHKey}
+		if managed, err := ssh.ManagedIdentityFiles(name); err == nil {
+			for _, path := range managed {
+				if expanded, err := workspace.ExpandPath(path); err == nil && expanded != paths[0] {
+					paths = append(paths, expanded)
+				}
+			}
+		}
+		for _, path := range paths {
+			for _, f := range ssh.AuditKey(path) {
+				findings = append(findings, keyFinding{Workspace: name, Finding: f})
+			}
+		}
+	}
+	return findings
+}
+
+// keyFindingCodes are the doctor issue codes of key audit findings
+var keyFindingCodes = map[string]string{
+	ssh.FindingKeyMissing:   "GWS-KEY-001",
+	ssh.FindingKeyMode:      "GWS-KEY-002",
+	ssh.FindingKeyOwner:     "GWS-KEY-003",
+	ssh.FindingDirMode:      "GWS-KEY-004",
+	ssh.FindingPubMissing:   "GWS-KEY-005",
+	ssh.FindingPairMismatch: "GWS-KEY-006",
+}
+
+// keyFindingIssue turns an audit finding into a doctor issue
+func keyFindingIssue(f keyFinding) prompt.Issue {
+	issue := prompt.Issue{Code: keyFindingCodes[f.Kind], Type: "error", Message: f.Message, Workspace: f.Workspace}
+	switch {
+	case f.Fixable:
+		issue.Command = []string{"gitws", "keys", "audit", "--fix"}
+		if f.Kind == ssh.FindingDirMode || f.Kind == ssh.FindingPubMissing {
+			issue.Type = "warning"
+		}
+	case f.Kind == ssh.FindingKeyMissing:
+		issue.Command = []string{"gitws", "rotate", f.Workspace}
+	case f.Kind == ssh.FindingKeyOwner:
+		issue.Fix = fmt.Sprintf("sudo chown \"$USER\" %s", prompt.ShellJoin([]string{f.Path}))
+	}
+	return issue
+}
+
+func runKeyAudit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	findings := auditKeys(cfg)
+	if len(findings) == 0 {
+		fmt.Println(prompt.Text("✓ All workspace SSH keys look good"))
+		return nil
+	}
+
+	remaining := 0
+	for _, f := range findings {
+		if keyAuditFix && f.Fixable {
+			if err := ssh.Repair(f.Finding); err != nil {
+				fmt.Printf(prompt.Text("❌ %s: %v\n"), f.Message, err)
+				remaining++
+				continue
+			}
+			fmt.Printf(prompt.Text("✓ Fixed: %s\n"), f.Message)
+			continue
+		}
+
+		issue := keyFindingIssue(f)
+		fmt.Printf(prompt.Text("⚠️  %s\n"), issue.Message)
+		if fix := issue.FixText(); fix != "" {
+			fmt.Printf("   Fix: %s\n", fix)
+		}
+		remaining++
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("%d key problem(s) remain", remaining)
+	}
+	return nil
+}
+
+var (
+	keysOffline bool
+	keysSSH     bool
+	keysGPG     bool
+	keysCopy    bool
+	keysQR      bool
+)
+
+var keysListCmd = &cobra.Command{
+	Use:   "list [workspace...]",
+	Short: "List workspace keys with provider and agent state",
+	Long: `List the SSH key of each workspace, and the GPG signing key of those
+with signing: gpg, in one table: fingerprint, creation and expiry dates,
+whether the provider account has the key (asked through the API for
+workspaces with a token) and whether the agent holds it, loaded into
+ssh-agent or with its passphrase cached by gpg-agent.
+
+--offline skips the provider.
+
+Examples:
+  gitws keys list
+  gitws keys list work --offline
+  gitws keys list --json`,
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runKeysList,
+}
+
+var keysShowCmd = &cobra.Command{
+	Use:   "show <workspace>",
+	Short: "Show a workspace's keys and public keys",
+	Long: `Show the SSH key and, with signing: gpg, the GPG signing key of a
+workspace, with the public key to add to the provider. --copy puts the
+public key on the clipboard, the SSH one unless --gpg is given, and --qr
+draws each as a QR code to scan from a phone.
+
+Examples:
+  gitws keys show work
+  gitws keys show work --gpg --offline
+  gitws keys show work --ssh --qr --copy`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runKeysShow,
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate [workspace]",
+	Short: "Rotate a workspace's SSH or GPG signing key",
+	Long: `Rotate a workspace's SSH key or, with --gpg, its GPG signing key.
+This is 'gitws rotate'; see 'gitws rotate --help'.
+
+Examples:
+  gitws keys rotate work
+  gitws keys rotate work --gpg --extend`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runRotate,
+}
+
+var keysUploadCmd = &cobra.Command{
+	Use:   "upload <workspace>",
+	Short: "Register a workspace's keys with the provider",
+	Long: `Register the SSH key and, with signing: gpg, the GPG public key of a
+workspace with its provider account through the API, using the token
+from 'gitws auth login'. Keys the account already has are left alone.
+
+Examples:
+  gitws keys upload work
+  gitws keys upload work --ssh`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runKeysUpload,
+}
+
+var keysRemoveCmd = &cobra.Command{
+	Use:   "remove <workspace>",
+	Short: "Remove a workspace's keys from the provider",
+	Long: `Remove the SSH key and, with signing: gpg, the GPG public key of a
+workspace from its provider account through the API. The key files and
+the gpg keyring are not touched; 'gitws keys upload' registers them
+again.
+
+Examples:
+  gitws keys remove work
+  gitws keys remove work --gpg`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runKeysRemove,
+}
+
+var keysFingerprintCmd = &cobra.Command{
+	Use:   "fingerprint <workspace>",
+	Short: "Print the fingerprints of a workspace's keys",
+	Long: `Print the SHA256 fingerprint of the workspace SSH key and, with
+signing: gpg, the fingerprint of its GPG key, one per line.
+
+Examples:
+  gitws keys fingerprint work
+  gitws keys fingerprint work --ssh`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	RunE:              runKeysFingerprint,
+}
+
+func init() {
+	keyCmd.AddCommand(keysListCmd)
+	keyCmd.AddCommand(keysShowCmd)
+	keyCmd.AddCommand(keysRotateCmd)
+	keyCmd.AddCommand(keysUploadCmd)
+	keyCmd.AddCommand(keysRemoveCmd)
+	keyCmd.AddCommand(keysFingerprintCmd)
+
+	keysListCmd.Flags().BoolVar(&keysOffline, "offline", false, "Don't ask the provider which keys it has")
+	keysShowCmd.Flags().BoolVar(&keysOffline, "offline", false, "Don't ask the provider which keys it has")
+	keysShowCmd.Flags().BoolVar(&keysCopy, "copy", false, "Copy the public key to the clipboard")
+	keysShowCmd.Flags().BoolVar(&keysQR, "qr", false, "Also show the public keys as QR codes")
+	for _, c := range []*cobra.Command{keysShowCmd, keysUploadCmd, keysRemoveCmd, keysFingerprintCmd} {
+		c.Flags().BoolVar(&keysSSH, "ssh", false, "Only the SSH key")
+		c.Flags().BoolVar(&keysGPG, "gpg", false, "Only the GPG signing key")
+	}
+
+	// The same flags as 'gitws rotate'
+	keysRotateCmd.Flags().BoolVar(&rotateAll, "all", false, "Rotate every workspace whose key is due")
+	keysRotateCmd.Flags().StringVar(&rotateOlderThan, "older-than", "", "With --all, rotate keys older than this (e.g. 90d) instead of each max_key_age")
+	keysRotateCmd.Flags().BoolVar(&rotateNoProvider, "no-provider", false, "Don't register or remove keys through the provider API")
+	keysRotateCmd.Flags().BoolVar(&rotateGPG, "gpg", false, "Rotate the GPG signing key instead of the SSH key")
+	keysRotateCmd.Flags().BoolVar(&rotateExtend, "extend", false, "With --gpg, extend the expiry of the current signing key instead of adding a subkey")
+	keysRotateCmd.Flags().StringVar(&rotateExpire, "expire", "1y", "With --gpg, how long the new or extended signing key is valid (gpg syntax, e.g. 1y, 6m)")
+	keysRotateCmd.Flags().StringVar(&rotateKeyserver, "keyserver", "", "With --gpg, also send the public key to this keyserver")
+	keysRotateCmd.Flags().BoolVar(&rotateCopy, "copy", false, "Copy the new public key to the clipboard")
+	keysRotateCmd.Flags().BoolVar(&rotateQR, "qr", false, "Also show the new public key as a QR code, to scan from a phone")
+}
+
+// Provider registration states of a workspace key
+const (
+	keyRegistered    = "registered"
+	keyNotRegistered = "not registered"
+	keyNoToken       = "no token"
+	keyUnsupported   = "not supported"
+	keyUnknown       = "unknown"
+)
+
+// workspaceKey is the SSH key or GPG signing key of a workspace
+type workspaceKey struct {
+	Workspace   string     `json:"workspace"`
+	Type        string     `json:"type"` // "ssh" or "gpg"
+	Key         string     `json:"key"`  // private key path, or GPG signing key ID
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	Created     *time.Time `json:"created,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	Provider    string     `json:"provider,omitempty"`
+	Agent       string     `json:"agent,omitempty"`
+	PublicKey   string     `json:"public_key,omitempty"`
+	Error       string     `json:"error,omitempty"`
+
+	keyID   string // GPG primary key ID, how providers know the key
+	keygrip string // how gpg-agent knows the GPG signing key
+}
+
+// selectedKeyTypes returns the key types --ssh and --gpg select
+func selectedKeyTypes() (ssh, gpg bool) {
+	if !keysSSH && !keysGPG {
+		return true, true
+	}
+	return keysSSH, keysGPG
+}
+
+// workspaceKeys returns a workspace's SSH key and, when it signs with
+// gpg, its GPG signing key, as far as they can be read locally
+func workspaceKeys(ctx context.Context, name string, ws config.Workspace, withSSH, withGPG bool) []workspaceKey {
+	var keys []workspaceKey
+	if withSSH {
+		key := workspaceKey{Workspace: name, Type: "ssh", Key: ws.SSHKey}
+		if public, err := ssh.GetPublicKey(ws.SSHKey + ".pub"); err != nil {
+			key.Error = err.Error()
+		} else {
+			key.PublicKey = public
+			key.Fingerprint, _ = ssh.Fingerprint(public)
+		}
+		if created, ok := keyCreated(ws); ok {
+			key.Created = &created
+		}
+		keys = append(keys, key)
+	}
+	if withGPG && ws.Signing == "gpg" {
+		keys = append(keys, gpgWorkspaceKey(ctx, name, ws))
+	}
+	return keys
+}
+
+// gpgWorkspaceKey looks up the key a workspace signs with in the gpg
+// keyring
+func gpgWorkspaceKey(ctx context.Context, name string, ws config.Workspace) workspaceKey {
+	key := workspaceKey{Workspace: name, Type: "gpg", Key: ws.GPGKey}
+	program := gpgProgram("")
+	found, err := gpg.ListSecretKeys(ctx, program, ws.GPGKey)
+	switch {
+	case err != nil:
+		key.Error = err.Error()
+		return key
+	case len(found) == 0:
+		key.Error = fmt.Sprintf("no gpg secret key for %s", ws.GPGKey)
+		return key
+	}
+
+	primary := found[0]
+	key.keyID = primary.ID
+	key.Fingerprint = primary.Fingerprint
+
+	// gpg signs with the subkey named with a trailing !, otherwise with
+	// the newest one that can sign
+	var signer *gpg.Signer
+	exact := strings.TrimSuffix(ws.GPGKey, "!")
+	for i, s := range primary.Signers {
+		if s.Revoked {
+			continue
+		}
+		if strings.HasSuffix(ws.GPGKey, "!") && (strings.EqualFold(s.ID, exact) || strings.EqualFold(s.Fingerprint, exact)) {
+			signer = &primary.Signers[i]
+			break
+		}
+		if signer == nil || s.Created.After(signer.Created) {
+			signer = &primary.Signers[i]
+		}
+	}
+	if signer == nil {
+		key.Error = fmt.Sprintf("gpg key %s has no key that can sign", primary.ID)
+		return key
+	}
+	key.Key = signer.ID
+	key.keygrip = signer.Keygrip
+	if !signer.Created.IsZero() {
+		key.Created = &signer.Created
+	}
+	if expires, ok := primary.SigningExpiry(); ok && !expires.IsZero() {
+		key.Expires = &expires
+	}
+	return key
+}
+
+// setAgentState records whether ssh-agent holds each SSH key and whether
+// gpg-agent has the passphrase of each GPG key cached
+func setAgentState(ctx context.Context, keys []workspaceKey) {
+	loaded := make(map[string]bool)
+	sshErr := error(nil)
+	if fingerprints, err := ssh.AgentFingerprints(ctx); err != nil {
+		sshErr = err
+	} else {
+		for _, f := range fingerprints {
+			loaded[f] = true
+		}
+	}
+	cached, gpgErr := gpg.CachedKeygrips(ctx)
+
+	for i := range keys {
+		key := &keys[i]
+		if key.Error != "" {
+			continue
+		}
+		switch {
+		case key.Type == "ssh" && sshErr != nil:
+			key.Agent = "no agent"
+		case key.Type == "ssh" && loaded[key.Fingerprint]:
+			key.Agent = "loaded"
+		case key.Type == "ssh":
+			key.Agent = "not loaded"
+		case gpgErr != nil:
+			key.Agent = "no agent"
+		case cached[key.keygrip]:
+			key.Agent = "cached"
+		default:
+			key.Agent = "not cached"
+		}
+	}
+}
+
+// setProviderState asks each workspace's provider whether its account has
+// the keys. Workspaces are asked concurrently.
+func setProviderState(ctx context.Context, cfg *config.File, keys []workspaceKey) {
+	byWorkspace := make(map[string][]int)
+	for i, key := range keys {
+		if key.Error == "" {
+			byWorkspace[key.Workspace] = append(byWorkspace[key.Workspace], i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for name, indexes := range byWorkspace {
+		wg.Add(1)
+		go func(name string, indexes []int) {
+			defer wg.Done()
+			ws := cfg.Workspaces[name]
+			token, err := workspaceToken(name)
+			if err != nil || token == "" || workspaceProviderKind(ws) == "" {
+				for _, i := range indexes {
+					keys[i].Provider = keyNoToken
+				}
+				return
+			}
+			client, err := workspaceClient(name, ws)
+			if err != nil {
+				for _, i := range indexes {
+					keys[i].Provider = keyUnknown
+				}
+				return
+			}
+			for _, i := range indexes {
+				keys[i].Provider = providerKeyState(ctx, client, &keys[i])
+			}
+		}(name, indexes)
+	}
+	wg.Wait()
+}
+
+// providerKeyState asks the provider whether the account has key
+func providerKeyState(ctx context.Context, client provider.Provider, key *workspaceKey) string {
+	if key.Type == "ssh" {
+		registered, err := callAPI(ctx, client.ListSSHKeys)
+		if err != nil {
+			return keyUnknown
+		}
+		for _, r := range registered {
+			if fingerprint, err := ssh.Fingerprint(r.Key); err == nil && fingerprint == key.Fingerprint {
+				return keyRegistered
+			}
+		}
+		return keyNotRegistered
+	}
+
+	registered, err := callAPI(ctx, client.ListGPGKeys)
+	if errors.Is(err, provider.ErrUnsupported) {
+		return keyUnsupported
+	}
+	if err != nil {
+		return keyUnknown
+	}
+	for _, r := range registered {
+		if providerGPGKeyMatches(ctx, gpgProgram(""), r, key.keyID) {
+			return keyRegistered
+		}
+	}
+	return keyNotRegistered
+}
+
+// loadKeys returns the keys of the named workspaces, or of all of them
+func loadKeys(ctx context.Context, cfg *config.File, names []string, withSSH, withGPG bool) ([]workspaceKey, error) {
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+
+	var keys []workspaceKey
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return nil, fmt.Errorf("workspace %q not found", name)
+		}
+		keys = append(keys, workspaceKeys(ctx, name, ws, withSSH, withGPG)...)
+	}
+	setAgentState(ctx, keys)
+	if !keysOffline {
+		setProviderState(ctx, cfg, keys)
+	}
+	return keys, nil
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	keys, err := loadKeys(cmd.Context(), cfg, args, true, true)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		for i := range keys {
+			keys[i].PublicKey = ""
+		}
+		return writeKeysJSON(keys)
+	}
+
+	rows := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Error != "" {
+			rows = append(rows, []string{key.Workspace, key.Type, key.Key, prompt.Text("❌ " + key.Error), "", "", "", ""})
+			continue
+		}
+		rows = append(rows, []string{
+			key.Workspace,
+			key.Type,
+			key.Key,
+			key.Fingerprint,
+			dateOrNone(key.Created),
+			dateOrNone(key.Expires),
+			displayOrNone(key.Provider),
+			key.Agent,
+		})
+	}
+	return prompt.ShowStatusTable([]string{"Workspace", "Type", "Key", "Fingerprint", "Created", "Expires", "Provider", "Agent"}, rows)
+}
+
+func runKeysShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	withSSH, withGPG := selectedKeyTypes()
+	keys, err := loadKeys(cmd.Context(), cfg, args[:1], withSSH, withGPG)
+	if err != nil {
+		return err
+	}
+	program := gpgProgram("")
+	for i, key := range keys {
+		if key.Type == "gpg" && key.Error == "" {
+			if public, err := gpg.ExportPublicKey(cmd.Context(), program, key.Fingerprint); err == nil {
+				keys[i].PublicKey = public
+			}
+		}
+	}
+
+	if jsonOutput {
+		return writeKeysJSON(keys)
+	}
+
+	copied := false
+	for _, key := range keys {
+		if key.Error != "" {
+			fmt.Printf(prompt.Text("❌ %s %s key: %s\n"), key.Workspace, key.Type, key.Error)
+			continue
+		}
+		items := []prompt.SummaryItem{
+			{Label: "Key", Value: key.Key, Icon: "🔑"},
+			{Label: "Fingerprint", Value: key.Fingerprint, Icon: "🔏"},
+			{Label: "Created", Value: dateOrNone(key.Created), Icon: "📅"},
+		}
+		if key.Expires != nil {
+			items = append(items, prompt.SummaryItem{Label: "Expires", Value: dateOrNone(key.Expires), Icon: "⏳"})
+		}
+		items = append(items, prompt.SummaryItem{Label: "Agent", Value: key.Agent, Icon: "🤖"})
+		if key.Provider != "" {
+			items = append(items, prompt.SummaryItem{Label: "Provider", Value: key.Provider, Icon: "🌐"})
+		}
+		title := i18n.T("SSH key of workspace '%s'", key.Workspace)
+		if key.Type == "gpg" {
+			title = i18n.T("GPG signing key of workspace '%s'", key.Workspace)
+		}
+		// The clipboard holds one key: the first shown
+		summary := prompt.SummaryData{
+			Title:         title,
+			Items:         items,
+			PublicKey:     key.PublicKey,
+			QR:            keysQR,
+			CopyPublicKey: keysCopy && !copied,
+		}
+		copied = copied || summary.CopyPublicKey
+		if err := prompt.ShowSummary(summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runKeysUpload(cmd *cobra.Command, args []string) error {
+	return updateProviderKeyRegistration(cmd.Context(), args[0], true)
+}
+
+func runKeysRemove(cmd *cobra.Command, args []string) error {
+	return updateProviderKeyRegistration(cmd.Context(), args[0], false)
+}
+
+// updateProviderKeyRegistration registers a workspace's keys with its
+// provider account, or with register false removes them from it
+func updateProviderKeyRegistration(ctx context.Context, name string, register bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	ws, exists := cfg.GetWorkspace(name)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", name)
+	}
+	client, err := workspaceClient(name, ws)
+	if err != nil {
+		return err
+	}
+
+	withSSH, withGPG := selectedKeyTypes()
+	if withGPG && !withSSH && ws.Signing != "gpg" {
+		return fmt.Errorf("workspace %q does not sign with gpg", name)
+	}
+
+	failed := 0
+	for _, key := range workspaceKeys(ctx, name, ws, withSSH, withGPG) {
+		if key.Error != "" {
+			fmt.Printf(prompt.Text("❌ %s key: %s\n"), key.Type, key.Error)
+			failed++
+			continue
+		}
+		var err error
+		if register {
+			err = registerKey(ctx, client, name, ws, key)
+		} else {
+			err = deregisterKey(ctx, client, ws, key)
+		}
+		if err != nil {
+			fmt.Printf(prompt.Text("❌ %s key: %v\n"), key.Type, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d key(s) could not be updated on %s", failed, ws.HostName)
+	}
+	return nil
+}
+
+// registerKey adds key to the provider account unless it is there
+func registerKey(ctx context.Context, client provider.Provider, name string, ws config.Workspace, key workspaceKey) error {
+	if state := providerKeyState(ctx, client, &key); state == keyRegistered {
+		fmt.Printf(prompt.Text("✓ %s already has the %s key %s\n"), ws.HostName, key.Type, key.Fingerprint)
+		return nil
+	} else if state == keyUnsupported {
+		return fmt.Errorf("add it in your %s settings: %w", ws.HostName, provider.ErrUnsupported)
+	}
+
+	if key.Type == "ssh" {
+		title := fmt.Sprintf("gitws %s (%s)", name, time.Now().Format("2006-01-02"))
+		apiCtx, cancel := operationContext(ctx, opAPI)
+		_, err := client.AddSSHKey(apiCtx, title, key.PublicKey)
+		err = operationError(apiCtx, opAPI, err)
+		cancel()
+		if err != nil {
+			return err
+		}
+	} else {
+		public, err := gpg.ExportPublicKey(ctx, gpgProgram(""), key.Fingerprint)
+		if err != nil {
+			return err
+		}
+		if _, err := callAPI(ctx, func(ctx context.Context) (*provider.GPGKey, error) { return client.AddGPGKey(ctx, public) }); err != nil {
+			return err
+		}
+	}
+	fmt.Printf(prompt.Text("✓ Registered the %s key %s with %s\n"), key.Type, key.Fingerprint, ws.HostName)
+	return nil
+}
+
+// deregisterKey removes every copy of key from the provider account
+func deregisterKey(ctx context.Context, client provider.Provider, ws config.Workspace, key workspaceKey) error {
+	removed := 0
+	if key.Type == "ssh" {
+		registered, err := callAPI(ctx, client.ListSSHKeys)
+		if err != nil {
+			return err
+		}
+		for _, r := range registered {
+			if fingerprint, err := ssh.Fingerprint(r.Key); err != nil || fingerprint != key.Fingerprint {
+				continue
+			}
+			apiCtx, cancel := operationContext(ctx, opAPI)
+			err := operationError(apiCtx, opAPI, client.DeleteSSHKey(apiCtx, r.ID))
+			cancel()
+			if err != nil {
+				return err
+			}
+			removed++
+		}
+	} else {
+		registered, err := callAPI(ctx, client.ListGPGKeys)
+		if err != nil {
+			return err
+		}
+		for _, r := range registered {
+			if !providerGPGKeyMatches(ctx, gpgProgram(""), r, key.keyID) {
+				continue
+			}
+			apiCtx, cancel := operationContext(ctx, opAPI)
+			err := operationError(apiCtx, opAPI, client.DeleteGPGKey(apiCtx, r.ID))
+			cancel()
+			if err != nil {
+				return err
+			}
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		fmt.Printf(prompt.Text("ℹ️  %s does not have the %s key %s\n"), ws.HostName, key.Type, key.Fingerprint)
+		return nil
+	}
+	fmt.Printf(prompt.Text("✓ Removed the %s key %s from %s\n"), key.Type, key.Fingerprint, ws.HostName)
+	return nil
+}
+
+func runKeysFingerprint(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	ws, exists := cfg.GetWorkspace(args[0])
+	if !exists {
+		return fmt.Errorf("workspace %q not found", args[0])
+	}
+
+	withSSH, withGPG := selectedKeyTypes()
+	for _, key := range workspaceKeys(cmd.Context(), args[0], ws, withSSH, withGPG) {
+		if key.Error != "" {
+			return fmt.Errorf("%s key: %s", key.Type, key.Error)
+		}
+		fmt.Println(key.Fingerprint)
+	}
+	return nil
+}
+
+// writeKeysJSON prints keys as JSON
+func writeKeysJSON(keys []workspaceKey) error {
+	if keys == nil {
+		keys = []workspaceKey{}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(keys)
+}
+
+// dateOrNone formats an optional date
+func dateOrNone(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	knownHostsYes bool
+)
+
+// knownHostsCmd represents the known-hosts command
+var knownHostsCmd = &cobra.Command{
+	Use:   "known-hosts [host...]",
+	Short: "Pre-populate ~/.ssh/known_hosts for workspace hosts",
+	Long: `Add the SSH host keys of workspace hosts to ~/.ssh/known_hosts, so the
+first clone through a new alias neither prompts nor fails in BatchMode.
+
+This command will:
+- Fetch each host's keys with ssh-keyscan
+- For github.com, gitlab.com and bitbucket.org, keep only keys matching the
+  fingerprints the provider publishes (bundled with gitws)
+- For other hosts, show the fingerprints and ask before trusting them.
+  Without a terminal, e.g. under CI, they are only trusted with
+  --accept-new
+- Refuse to add keys when known_hosts already holds a mismatching key
+
+With no arguments, every host used by a workspace is processed.
+
+Examples:
+  gitws known-hosts
+  gitws known-hosts gitlab.example.com --accept-new`,
+	RunE: runKnownHosts,
+}
+
+func init() {
+	rootCmd.AddCommand(knownHostsCmd)
+
+	knownHostsCmd.Flags().BoolVar(&knownHostsYes, "accept-new", false, "Trust keys of hosts without published fingerprints without asking")
+	knownHostsCmd.Flags().BoolVar(&knownHostsYes, "yes", false, "Same as --accept-new")
+}
+
+func runKnownHosts(cmd *cobra.Command, args []string) error {
+	hosts := args
+	if len(hosts) == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		hosts = workspaceHosts(cfg)
+		if len(hosts) == 0 {
+			return fmt.Errorf("no workspaces configured. Run 'gitws init' first")
+		}
+	}
+
+	failed := 0
+	for _, host := range hosts {
+		if err := addKnownHost(cmd.Context(), host); err != nil {
+			fmt.Printf(prompt.Text("❌ %s: %v\n"), host, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hosts could not be added", failed, len(hosts))
+	}
+	return nil
+}
+
+// addKnownHost scans, verifies and records the keys of one host
+func addKnownHost(ctx context.Context, host string) error {
+	state, recorded, err := ssh.CheckKnownHost(ctx, host)
+	if err != nil {
+		return err
+	}
+	if state == ssh.HostKeyChanged {
+		return fmt.Errorf("known_hosts has a key (%s) that does not match the published fingerprints; verify it, then run 'ssh-keygen -R %s' and retry", recorded[0], host)
+	}
+
+	scanCtx, cancel := operationContext(ctx, opSSH)
+	defer cancel()
+	keys, err := ssh.ScanHostKeys(scanCtx, host)
+	if err = operationError(scanCtx, opSSH, err); err != nil {
+		return err
+	}
+
+	trusted, published := ssh.VerifyHostKeys(host, keys)
+	if published {
+		if len(trusted) == 0 {
+			return fmt.Errorf("none of the scanned keys match the published fingerprints; the connection may be intercepted")
+		}
+	} else {
+		fmt.Printf("%s has no published fingerprints bundled with gitws. It offered:\n", host)
+		for _, key := range keys {
+			fmt.Printf("  %s %s\n", key.Type, key.Fingerprint)
+		}
+		if !knownHostsYes {
+			confirmed, err := prompt.ConfirmInteractive("Do these match the fingerprints your administrator published?")
+			if err != nil {
+				return fmt.Errorf("%w; pass --accept-new once the fingerprints are verified", err)
+			}
+			if !confirmed {
+				return fmt.Errorf("not trusted")
+			}
+		}
+		trusted = keys
+	}
+
+	added, err := ssh.AddKnownHosts(trusted)
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		fmt.Printf(prompt.Text("✓ %s: already in known_hosts\n"), host)
+	} else {
+		fmt.Printf(prompt.Text("✓ %s: added %d key(s) to known_hosts\n"), host, added)
+	}
+	return nil
+}
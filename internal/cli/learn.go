@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	learnNoPause bool
+	learnKeep    bool
+)
+
+// learnCmd represents the learn command
+var learnCmd = &cobra.Command{
+	Use:   "learn",
+	Short: "Interactive tutorial in a throwaway sandbox",
+	Long: `Walk through gitws step by step without touching your real setup.
+
+The tutorial runs real gitws and git commands against a temporary HOME,
+so your ~/.ssh, ~/.gitconfig and ~/.gws are never read or written. It
+will:
+- Create a demo workspace with its own SSH key
+- Clone a sample repository into the workspace root
+- Commit with the wrong email and watch the guard hook block it
+- Fix the repository and commit again
+
+Nothing leaves the machine; the sample "remote" is a local repository.
+The sandbox is deleted at the end unless --keep is given.
+
+Examples:
+  gitws learn
+  gitws learn --no-pause --keep`,
+	Args: cobra.NoArgs,
+	RunE: runLearn,
+}
+
+func init() {
+	rootCmd.AddCommand(learnCmd)
+
+	learnCmd.Flags().BoolVar(&learnNoPause, "no-pause", false, "Run every step without waiting for Enter")
+	learnCmd.Flags().BoolVar(&learnKeep, "keep", false, "Keep the sandbox directory afterwards")
+}
+
+// learnStep is one stage of the tutorial
+type learnStep struct {
+	Title   string
+	Explain string
+	Run     func(s *learnSandbox) error
+}
+
+// learnSandbox runs commands against a temporary HOME
+type learnSandbox struct {
+	Home string
+	Env  []string
+	Repo string
+	Self string // this gitws binary
+}
+
+const (
+	learnWorkspace = "demo"
+	learnEmail     = "you@demo.example"
+	learnWrong     = "you@personal.example"
+)
+
+var learnSteps = []learnStep{
+	{
+		Title: "Create a workspace",
+		Explain: `A workspace is one identity: an email, an SSH key, an SSH alias and a
+directory root. Everything under the root commits as that identity.`,
+		Run: func(s *learnSandbox) error {
+			return s.gitws("init", learnWorkspace, "--email", learnEmail, "--host", "github", "--no-mx-check", "--no-use-config-only")
+		},
+	},
+	{
+		Title: "Clone a sample repository",
+		Explain: `Normally 'gitws clone demo org/repo' clones through the workspace SSH
+alias. The sandbox is offline, so a local repository stands in for GitHub.
+What matters is where the clone lands: under ~/code/demo.`,
+		Run: func(s *learnSandbox) error {
+			remote := filepath.Join(s.Home, "remote", "sample.git")
+			seed := filepath.Join(s.Home, "seed")
+			if err := s.quiet("", "init", "-q", seed); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte("# sample\n"), 0644); err != nil {
+				return err
+			}
+			for _, args := range [][]string{
+				{"add", "README.md"},
+				{"-c", "user.name=Sample", "-c", "user.email=sample@demo.example", "commit", "-q", "-m", "Initial commit"},
+			} {
+				if err := s.quiet(seed, args...); err != nil {
+					return err
+				}
+			}
+			if err := s.quiet("", "clone", "-q", "--bare", seed, remote); err != nil {
+				return err
+			}
+			if err := s.git("", "clone", "-q", remote, s.Repo); err != nil {
+				return err
+			}
+			return s.gitws("status", s.Repo)
+		},
+	},
+	{
+		Title: "Install the guard hooks",
+		Explain: `Guard hooks check every commit and push against the workspace. They
+call 'gitws hook run', so the rules live in config.yaml, not in the repo.`,
+		Run: func(s *learnSandbox) error {
+			return s.gitws("hooks", "install", s.Repo)
+		},
+	},
+	{
+		Title: "Commit with the wrong email",
+		Explain: `Say an old global setting leaks in and the repository commits as your
+personal address. The pre-commit hook catches it before the commit exists.`,
+		Run: func(s *learnSandbox) error {
+			if err := s.git(s.Repo, "config", "user.email", learnWrong); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(s.Repo, "notes.txt"), []byte("hello\n"), 0644); err != nil {
+				return err
+			}
+			if err := s.git(s.Repo, "add", "notes.txt"); err != nil {
+				return err
+			}
+			if err := s.git(s.Repo, "commit", "-q", "-m", "Add notes"); err == nil {
+				return fmt.Errorf("expected the guard hook to block the commit")
+			}
+			fmt.Println(prompt.Text("\n👉 The hook blocked the commit: no commit with the wrong email was created."))
+			return nil
+		},
+	},
+	{
+		Title: "Fix the repository",
+		Explain: `'gitws fix' sets the workspace identity (and can rewrite remotes and
+install hooks). 'gitws doctor' explains problems in more detail.`,
+		Run: func(s *learnSandbox) error {
+			if err := s.gitws("fix", "--workspace", learnWorkspace, "--set-identity", "--yes", s.Repo); err != nil {
+				return err
+			}
+			if err := s.git(s.Repo, "commit", "-q", "-m", "Add notes"); err != nil {
+				return err
+			}
+			return s.git(s.Repo, "log", "-1", prompt.Text("--format=✓ Committed as %an <%ae>"))
+		},
+	},
+}
+
+func runLearn(cmd *cobra.Command, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gitws: %w", err)
+	}
+
+	home, err := os.MkdirTemp("", "gitws-learn-")
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox: %w", err)
+	}
+	if learnKeep {
+		defer fmt.Printf("\nSandbox kept at %s\n", home)
+	} else {
+		defer os.RemoveAll(home)
+	}
+
+	s := &learnSandbox{
+		Home: home,
+		Env:  learnEnv(home, filepath.Dir(self)),
+		Repo: filepath.Join(home, "code", learnWorkspace, "sample"),
+		Self: self,
+	}
+
+	fmt.Println("Welcome to gitws!")
+	fmt.Printf("This tutorial runs in a sandbox (%s); your real setup is not touched.\n", home)
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, step := range learnSteps {
+		fmt.Printf(prompt.Text("\n── Step %d/%d: %s ──\n%s\n"), i+1, len(learnSteps), step.Title, step.Explain)
+		if !learnNoPause && os.Getenv("CI") == "" {
+			fmt.Print("\nPress Enter to run this step...")
+			if _, err := reader.ReadString('\n'); err != nil {
+				return fmt.Errorf("tutorial aborted: %w", err)
+			}
+		}
+		fmt.Println()
+		if err := step.Run(s); err != nil {
+			return fmt.Errorf("step %d (%s) failed: %w", i+1, step.Title, err)
+		}
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title: "✓ Tutorial complete",
+		NextSteps: []string{
+			"Create your real workspaces: gitws init work --email you@work.com --host github",
+			"Clone through them: gitws clone work org/repo",
+			"Check any repository: gitws status, gitws doctor",
+		},
+	})
+}
+
+// learnEnv returns the environment for sandboxed commands: a fresh HOME,
+// no gitws or git settings inherited from the real one, and this gitws
+// first on PATH so the hooks call it
+func learnEnv(home, binDir string) []string {
+	drop := map[string]bool{
+		"HOME": true, "USERPROFILE": true, "XDG_CONFIG_HOME": true,
+		"GIT_CONFIG_GLOBAL": true, "GIT_CONFIG_COUNT": true, "GIT_CONFIG_PARAMETERS": true,
+		"GIT_AUTHOR_NAME": true, "GIT_AUTHOR_EMAIL": true, "GIT_COMMITTER_NAME": true, "GIT_COMMITTER_EMAIL": true,
+		"GIT_SSH_COMMAND": true, "PATH": true, "CI": true,
+		config.HomeEnv: true, config.SystemConfigEnv: true,
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if !drop[key] && !strings.HasPrefix(key, "GIT_CONFIG_KEY_") && !strings.HasPrefix(key, "GIT_CONFIG_VALUE_") {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		"HOME="+home,
+		"USERPROFILE="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		config.SystemConfigEnv+"="+filepath.Join(home, "no-system-config.yaml"),
+		"CI=1", // answer gitws prompts with their defaults
+	)
+}
+
+// gitws runs this gitws binary in the sandbox, echoing the command
+func (s *learnSandbox) gitws(args ...string) error {
+	return s.run("", true, s.Self, "gitws", args...)
+}
+
+// git runs git in the sandbox, echoing the command
+func (s *learnSandbox) git(dir string, args ...string) error {
+	path, _, err := git.Locate()
+	if err != nil {
+		return err
+	}
+	return s.run(dir, true, path, "git", args...)
+}
+
+// quiet runs git in the sandbox for setup the user need not see
+func (s *learnSandbox) quiet(dir string, args ...string) error {
+	path, _, err := git.Locate()
+	if err != nil {
+		return err
+	}
+	return s.run(dir, false, path, "git", args...)
+}
+
+func (s *learnSandbox) run(dir string, show bool, path, name string, args ...string) error {
+	if show {
+		line := prompt.ShellJoin(append([]string{name}, args...))
+		fmt.Printf("$ %s\n", strings.ReplaceAll(line, s.Home, "$HOME"))
+	}
+
+	if name == "gitws" {
+		args = append(outputFlags(), args...)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Dir = dir
+	cmd.Env = s.Env
+	if show {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stdout
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/cache"
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var listRepos bool
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list [workspace...]",
+	Short: "List workspaces and their repositories",
+	Long: `List workspaces with what the cache knows about them: the provider
+account, how many repositories are under the root, and how many more the
+account can push to. --repos lists the repositories instead, with the
+outcome of the last 'gitws doctor' in each.
+
+Nothing is scanned or fetched, so this works offline; each workspace says
+how old its data is, and data older than a week is flagged. Refresh it
+with 'gitws cache refresh'.
+
+Examples:
+  gitws list
+  gitws list --repos
+  gitws list work --repos --json`,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().BoolVar(&listRepos, "repos", false, "List the repositories under each workspace root")
+}
+
+// listedWorkspace is a workspace, as --json prints it
+type listedWorkspace struct {
+	Name    string       `json:"name"`
+	Email   string       `json:"email"`
+	Host    string       `json:"host"`
+	Root    string       `json:"root,omitempty"`
+	Account string       `json:"account,omitempty"`
+	Repos   []cache.Repo `json:"repos"`
+	Remote  []string     `json:"remote,omitempty"`
+	Scanned *time.Time   `json:"scanned,omitempty"`
+	Fetched *time.Time   `json:"fetched,omitempty"`
+	Stale   bool         `json:"stale"`
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, exists := cfg.GetWorkspace(name); !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+	}
+
+	data, err := cache.Load()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	if jsonOutput {
+		listed := make([]listedWorkspace, 0, len(names))
+		for _, name := range names {
+			ws, entry := cfg.Workspaces[name], data.Workspace(name)
+			l := listedWorkspace{
+				Name:    name,
+				Email:   ws.Email,
+				Host:    ws.HostName,
+				Root:    ws.Root,
+				Account: entry.Account,
+				Repos:   entry.Repos,
+				Remote:  entry.Remote,
+				Stale:   cache.Stale(entry.Scanned, now),
+			}
+			if l.Repos == nil {
+				l.Repos = []cache.Repo{}
+			}
+			if !entry.Scanned.IsZero() {
+				l.Scanned = &entry.Scanned
+			}
+			if !entry.Fetched.IsZero() {
+				l.Fetched = &entry.Fetched
+			}
+			listed = append(listed, l)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listed)
+	}
+
+	var rows [][]string
+	var stale []string
+	if listRepos {
+		for _, name := range names {
+			entry := data.Workspace(name)
+			for _, repo := range entry.Repos {
+				rows = append(rows, []string{name, displayOrNone(repo.Name), repo.Path, doctorDisplay(repo.Doctor, now)})
+			}
+			if cfg.Workspaces[name].Root != "" && cache.Stale(entry.Scanned, now) {
+				stale = append(stale, name)
+			}
+		}
+		if err := prompt.ShowStatusTable([]string{"Workspace", "Repository", "Path", "Doctor"}, rows); err != nil {
+			return err
+		}
+	} else {
+		for _, name := range names {
+			ws, entry := cfg.Workspaces[name], data.Workspace(name)
+			rows = append(rows, []string{
+				name,
+				ws.Email,
+				ws.HostName,
+				displayOrNone(entry.Account),
+				reposDisplay(ws, entry, now),
+			})
+			if (ws.Root != "" && cache.Stale(entry.Scanned, now)) || (entry.Account != "" && cache.Stale(entry.Fetched, now)) {
+				stale = append(stale, name)
+			}
+		}
+		if err := prompt.ShowStatusTable([]string{"Workspace", "Email", "Host", "Account", "Repositories"}, rows); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range stale {
+		entry := data.Workspace(name)
+		switch {
+		case entry.Scanned.IsZero():
+			fmt.Printf(prompt.Text("ℹ️  %s has not been scanned yet; run 'gitws cache refresh %s'\n"), name, name)
+		case cache.Stale(entry.Scanned, now):
+			fmt.Printf(prompt.Text("⚠️  %s: cached data is stale (scanned %s); run 'gitws cache refresh %s'\n"), name, cache.Age(entry.Scanned, now), name)
+		default:
+			fmt.Printf(prompt.Text("⚠️  %s: provider data is stale (fetched %s); run 'gitws cache refresh %s'\n"), name, cache.Age(entry.Fetched, now), name)
+		}
+	}
+	return nil
+}
+
+// reposDisplay summarizes the cached repositories of a workspace
+func reposDisplay(ws config.Workspace, entry *cache.Workspace, now time.Time) string {
+	if ws.Root == "" {
+		return "-"
+	}
+	if entry.Scanned.IsZero() {
+		return "not scanned"
+	}
+	display := fmt.Sprintf("%d (%s)", len(entry.Repos), cache.Age(entry.Scanned, now))
+	if entry.Fetched.IsZero() {
+		return display
+	}
+
+	cloned := make(map[string]bool, len(entry.Repos))
+	for _, r := range entry.Repos {
+		cloned[r.Name] = true
+	}
+	more := 0
+	for _, name := range entry.Remote {
+		if !cloned[name] {
+			more++
+		}
+	}
+	return fmt.Sprintf("%s, %d more on the provider (%s)", display, more, cache.Age(entry.Fetched, now))
+}
+
+// doctorDisplay describes a cached doctor result
+func doctorDisplay(result *cache.Doctor, now time.Time) string {
+	if result == nil {
+		return "-"
+	}
+	outcome := "ok"
+	switch {
+	case result.Errors > 0:
+		outcome = fmt.Sprintf("%d error(s)", result.Errors)
+	case result.Warnings > 0:
+		outcome = fmt.Sprintf("%d warning(s)", result.Warnings)
+	}
+	return fmt.Sprintf("%s (%s)", outcome, cache.Age(result.At, now))
+}
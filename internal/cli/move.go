@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	moveRoot  string
+	moveFiles bool
+	moveYes   bool
+)
+
+// moveCmd represents the move command
+var moveCmd = &cobra.Command{
+	Use:   "move <workspace> --root <newpath>",
+	Short: "Relocate a workspace root",
+	Long: `Change the root directory of a workspace.
+
+This command will:
+- Update the workspace root in config.yaml
+- Rewrite the includeIf block in ~/.gitconfig
+- Move the directory contents to the new root (with --move-files)
+- Re-validate the identity of every repository under the new root
+
+Without --move-files only the configuration changes; move the directory
+yourself before or after.
+
+Examples:
+  gitws move work --root ~/src/work --move-files
+  gitws move personal --root /Volumes/data/personal`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMove,
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+
+	moveCmd.Flags().StringVar(&moveRoot, "root", "", "New root directory (required)")
+	moveCmd.Flags().BoolVar(&moveFiles, "move-files", false, "Move the existing directory contents to the new root")
+	moveCmd.Flags().BoolVar(&moveYes, "yes", false, "Skip confirmation prompt")
+
+	moveCmd.MarkFlagRequired("root")
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	newRoot, err := workspace.ExpandPath(moveRoot)
+	if err != nil {
+		return fmt.Errorf("failed to expand root path: %w", err)
+	}
+	newRoot, err = filepath.Abs(newRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve root path: %w", err)
+	}
+	oldRoot := ws.Root
+
+	if newRoot == oldRoot {
+		return fmt.Errorf("workspace %q already uses %s", workspaceName, newRoot)
+	}
+	if isWithin(newRoot, oldRoot) || isWithin(oldRoot, newRoot) {
+		return fmt.Errorf("new root %s cannot contain or be inside the current root %s", newRoot, oldRoot)
+	}
+	for name, other := range cfg.Workspaces {
+		if name != workspaceName && other.Root != "" && (newRoot == other.Root || isWithin(newRoot, other.Root) || isWithin(other.Root, newRoot)) {
+			return fmt.Errorf("new root %s overlaps the root of workspace %q (%s)", newRoot, name, other.Root)
+		}
+	}
+
+	if moveFiles && fsutil.FileExists(newRoot) {
+		entries, err := os.ReadDir(newRoot)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", newRoot, err)
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf("%s already exists and is not empty", newRoot)
+		}
+	}
+
+	fmt.Printf(prompt.Text("Workspace '%s' root: %s → %s\n"), workspaceName, oldRoot, newRoot)
+	if moveFiles {
+		fmt.Printf("The contents of %s will be moved.\n", oldRoot)
+	}
+	if !moveYes {
+		confirmed, err := prompt.Confirm("Move workspace?")
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Move cancelled.")
+			return nil
+		}
+	}
+
+	if moveFiles && fsutil.FileExists(oldRoot) {
+		if err := moveDirectory(oldRoot, newRoot); err != nil {
+			return err
+		}
+		fmt.Printf(prompt.Text("✓ Moved %s to %s\n"), oldRoot, newRoot)
+	}
+
+	ws.Root = newRoot
+	cfg.SetWorkspace(workspaceName, ws)
+
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+	fmt.Println(prompt.Text("✓ Updated config.yaml and includeIf block"))
+
+	return revalidateWorkspace(cfg, workspaceName)
+}
+
+// moveDirectory renames src to dst, creating dst's parent
+func moveDirectory(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	// An empty destination left by the user would make rename fail
+	os.Remove(dst)
+
+	if err := os.Rename(src, dst); err != nil {
+		if errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("%s and %s are on different filesystems; move the directory yourself, then re-run without --move-files", src, dst)
+		}
+		return fmt.Errorf("failed to move %s: %w", src, err)
+	}
+	return nil
+}
+
+// revalidateWorkspace checks that every repository under the workspace
+// root still resolves to the workspace and its identity
+func revalidateWorkspace(cfg *config.File, workspaceName string) error {
+	ws := cfg.Workspaces[workspaceName]
+
+	repos, err := workspaceRepos(cfg, workspaceName)
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Printf("No repositories under %s to re-validate\n", ws.Root)
+		return nil
+	}
+
+	problems := 0
+	for _, repo := range repos {
+		owner := workspaceForRepo(cfg, repo)
+		email, _ := git.GetConfig(repo, "user.email")
+		switch {
+		case owner != workspaceName:
+			fmt.Printf(prompt.Text("⚠️  %s does not resolve to workspace %q\n"), repo, workspaceName)
+			problems++
+		case email != ws.Email:
+			fmt.Printf(prompt.Text("⚠️  %s commits as %q, expected %s\n"), repo, email, ws.Email)
+			fmt.Printf("   Fix: %s\n", prompt.ShellJoin(fixCommand(workspaceName, repo, "set-identity")))
+			problems++
+		}
+	}
+
+	if problems == 0 {
+		fmt.Printf(prompt.Text("✓ All %d repositories under %s use the '%s' identity\n"), len(repos), ws.Root, workspaceName)
+	} else {
+		fmt.Printf("%d of %d repositories need attention\n", problems, len(repos))
+	}
+	return nil
+}
+
+// isWithin reports whether path is strictly inside dir
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
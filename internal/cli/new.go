@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	newBranch       string
+	newPush         bool
+	newCreateRemote bool
+	newPrivate      bool
+)
+
+// defaultReadmeTemplate is used when a workspace has no README template
+const defaultReadmeTemplate = "# {{.Repo}}\n"
+
+// newCmd represents the new command
+var newCmd = &cobra.Command{
+	Use:   "new <workspace> <org/repo>",
+	Short: "Create a new repository in a workspace",
+	Long: `Create a new local repository in a workspace with its identity and templates.
+
+This command will:
+- Initialize <root>/<org>/<repo> (or the workspace clone_layout path)
+  with the workspace default branch
+- Configure the workspace identity and signing, and origin via the SSH alias
+- Render the workspace README, LICENSE, and .gitignore templates
+- Create the first commit, and push it with --push
+- With --create-remote, first create the repository on the provider with
+  the workspace API token ('gitws auth login'), public unless --private
+
+Templates are text/template files configured per workspace in config.yaml:
+
+  workspaces:
+    work:
+      default_branch: main
+      templates:
+        readme: ~/.gws/templates/work/README.md
+        license: ~/.gws/templates/work/LICENSE
+        gitignore: ~/.gws/templates/work/gitignore
+
+Templates can use {{.Org}}, {{.Repo}}, {{.Workspace}}, {{.Name}},
+{{.Email}}, {{.Branch}}, and {{.Year}}.
+
+Examples:
+  gitws new work myorg/newrepo
+  gitws new personal me/dotfiles --branch trunk --push
+  gitws new work myorg/service --create-remote --private --push`,
+	Args: cobra.ExactArgs(2),
+	RunE: runNew,
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+
+	newCmd.Flags().StringVarP(&newBranch, "branch", "b", "", "Initial branch (default: workspace default_branch or main)")
+	newCmd.Flags().BoolVar(&newPush, "push", false, "Push the first commit to origin (the remote repository must exist)")
+	newCmd.Flags().BoolVar(&newCreateRemote, "create-remote", false, "Create the repository on the provider and set origin to it")
+	newCmd.Flags().BoolVar(&newPrivate, "private", false, "Make the repository created with --create-remote private")
+}
+
+// repoTemplateData is the data available to repository templates
+type repoTemplateData struct {
+	Org       string
+	Repo      string
+	Workspace string
+	Name      string
+	Email     string
+	Branch    string
+	Year      int
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	if newPrivate && !newCreateRemote {
+		return fmt.Errorf("--private requires --create-remote")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", workspaceName, workspaceName)
+	}
+
+	org, repo, remoteURL, err := workspaceRemote(ws, args[1])
+	if err != nil {
+		return fmt.Errorf("failed to parse repository name: %w", err)
+	}
+
+	branch := newBranch
+	if branch == "" {
+		branch = ws.DefaultBranch
+	}
+	if branch == "" {
+		branch = "main"
+	}
+
+	destPath := workspaceRepoPath(ws, args[1], org, repo)
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("destination %s already exists", destPath)
+	}
+
+	var created *provider.Repo
+	if newCreateRemote {
+		created, err = createRemoteRepo(cmd.Context(), workspaceName, ws, org, repo, branch)
+		if err != nil {
+			return err
+		}
+		// The provider's spelling of the name is the one to clone from
+		if _, _, remoteURL, err = workspaceRemote(ws, created.FullName); err != nil {
+			return fmt.Errorf("failed to parse repository name: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := git.InitRepository(destPath, branch); err != nil {
+		return err
+	}
+	fsutil.TrackCreated(destPath)
+
+	if err := setupRepositoryConfig(destPath, ws); err != nil {
+		return fmt.Errorf("failed to setup repository config: %w", err)
+	}
+
+	if err := git.AddRemote(destPath, "origin", remoteURL); err != nil {
+		return err
+	}
+
+	data := repoTemplateData{
+		Org:       org,
+		Repo:      repo,
+		Workspace: workspaceName,
+		Name:      ws.Name,
+		Email:     ws.Email,
+		Branch:    branch,
+		Year:      time.Now().Year(),
+	}
+
+	files, err := renderRepoTemplates(ws.Templates, data)
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := fsutil.AtomicWrite(filepath.Join(destPath, name), content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := git.CommitAll(destPath, "Initial commit"); err != nil {
+		return err
+	}
+
+	pushed := "No (use 'git push -u origin " + branch + "')"
+	if newPush {
+		ctx, cancel := operationContext(cmd.Context(), opPush)
+		err := operationError(ctx, opPush, git.Push(ctx, destPath, "origin", branch))
+		cancel()
+		if err != nil {
+			return withGitHint(fmt.Errorf("%w (does %s/%s exist on %s?)", err, org, repo, ws.HostName), workspaceName, ws)
+		}
+		pushed = "Yes"
+	}
+
+	items := []prompt.SummaryItem{
+		{Label: "Workspace", Value: workspaceName, Icon: "📁"},
+		{Label: "Repository", Value: fmt.Sprintf("%s/%s", org, repo), Icon: "📦"},
+		{Label: "Destination", Value: destPath, Icon: "📍"},
+		{Label: "Remote URL", Value: remoteURL, Icon: "🔗"},
+		{Label: "Branch", Value: branch, Icon: "🌿"},
+		{Label: "Pushed", Value: pushed, Icon: "🚀"},
+	}
+	if created != nil {
+		items = append(items, prompt.SummaryItem{Label: "Web URL", Value: created.WebURL, Icon: "🌐"})
+	}
+	summary := prompt.SummaryData{
+		Title: "✓ Repository created successfully",
+		Items: items,
+		NextSteps: []string{
+			fmt.Sprintf("cd %s", destPath),
+			"Run 'gitws status' to verify configuration",
+		},
+	}
+
+	return prompt.ShowSummary(summary)
+}
+
+// createRemoteRepo creates org/repo on the workspace provider. An org
+// naming the token's own account creates a personal repository, which
+// the provider APIs do not accept as an owner.
+func createRemoteRepo(ctx context.Context, workspaceName string, ws config.Workspace, org, repo, branch string) (*provider.Repo, error) {
+	client, err := workspaceClient(workspaceName, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := provider.CreateRepoOptions{
+		Owner:         org,
+		Name:          repo,
+		Visibility:    provider.VisibilityPublic,
+		DefaultBranch: branch,
+	}
+	if newPrivate {
+		opts.Visibility = provider.VisibilityPrivate
+	}
+
+	ctx, cancel := operationContext(ctx, opAPI)
+	defer cancel()
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return nil, operationError(ctx, opAPI, err)
+	}
+	if strings.EqualFold(user, org) {
+		opts.Owner = ""
+	}
+	created, err := client.CreateRepo(ctx, opts)
+	err = operationError(ctx, opAPI, err)
+	if err != nil {
+		if created == nil {
+			return nil, err
+		}
+		// The repository exists; report the partial failure and carry on
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %v\n"), err)
+	}
+	return created, nil
+}
+
+// renderRepoTemplates renders the workspace templates into file contents
+// keyed by their path in the new repository
+func renderRepoTemplates(templates *config.RepoTemplates, data repoTemplateData) (map[string][]byte, error) {
+	sources := map[string]string{"README.md": ""}
+	if templates != nil {
+		sources["README.md"] = templates.Readme
+		sources["LICENSE"] = templates.License
+		sources[".gitignore"] = templates.Gitignore
+	}
+
+	files := make(map[string][]byte)
+	for name, source := range sources {
+		var text string
+		switch {
+		case source != "":
+			path, err := workspace.ExpandPath(source)
+			if err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s template: %w", name, err)
+			}
+			text = string(content)
+		case name == "README.md":
+			text = defaultReadmeTemplate
+		default:
+			continue
+		}
+
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s template: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+
+	return files, nil
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/health"
+	"github.com/gitworkspaces/gitws/internal/notify"
+	"github.com/gitworkspaces/gitws/internal/progress"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// notifyCmd groups notification commands
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage event notifications",
+	Long: `Manage webhook notifications for gitws events.
+
+Sinks are configured in config.yaml:
+
+  notify:
+    - type: slack
+      url: https://hooks.slack.com/services/T000/B000/XXXX
+      events: [key_rotated, policy_violation]
+    - type: webhook
+      url: https://example.com/gitws
+
+Supported types are slack, teams, and webhook (the event as JSON).
+Events are key_rotated, policy_violation (the guard blocked a commit or
+push, or 'gitws doctor' found a new policy issue), workspace_expired
+('gitws doctor' found a key that expired or is past max_key_age), and
+repo_fixed ('gitws watch' set up a new clone); a sink without events
+receives all of them. Doctor raises an event only when an issue is new
+since its last run in the repository.`,
+}
+
+// notifyTestCmd represents the notify test command
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test event to every configured sink",
+	Long: `Send a test event to every configured sink.
+
+Examples:
+  gitws notify test`,
+	Args: cobra.NoArgs,
+	RunE: runNotifyTest,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(cfg.Notify) == 0 {
+		fmt.Println("No notification sinks configured in config.yaml")
+		return nil
+	}
+
+	notifier, err := notify.New(cfg.Notify)
+	if err != nil {
+		return err
+	}
+
+	errs := notifier.Notify(notify.NewEvent(notify.EventTest, "", "test notification"))
+	for _, err := range errs {
+		fmt.Printf(prompt.Text("❌ %v\n"), err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d sinks failed", len(errs), len(cfg.Notify))
+	}
+
+	fmt.Printf(prompt.Text("✓ Delivered test event to %d sinks\n"), len(cfg.Notify))
+	return nil
+}
+
+// notifyEvent tells the configured sinks about an event. Delivery
+// problems are reported but never fail the command that raised the event.
+func notifyEvent(cfg *config.File, eventType, workspaceName, message string) {
+	if len(cfg.Notify) == 0 {
+		return
+	}
+
+	notifier, err := notify.New(cfg.Notify)
+	if err != nil {
+		progress.Fprintf(os.Stderr, prompt.Text("⚠️  Notifications disabled: %v\n"), err)
+		return
+	}
+
+	for _, err := range notifier.Notify(notify.NewEvent(eventType, workspaceName, message)) {
+		progress.Fprintf(os.Stderr, prompt.Text("⚠️  Failed to send notification: %v\n"), err)
+	}
+}
+
+// notifyViolations raises a policy_violation event for the violations
+// that blocked a commit or push in the repository at gitRoot
+func notifyViolations(gitRoot string, violations []guard.Violation) {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Notify) == 0 {
+		return
+	}
+
+	var messages []string
+	for _, v := range violations {
+		if v.Block {
+			messages = append(messages, v.Message)
+		}
+	}
+	notifyEvent(cfg, notify.EventPolicyViolation, workspaceForRepo(cfg, gitRoot),
+		fmt.Sprintf("Guard blocked git in %s: %s", gitRoot, strings.Join(messages, "; ")))
+}
+
+// doctorEvent returns the event a doctor issue raises, or "" for none.
+// Policy issues are violations; expired keys and keys past their maximum
+// age expire the workspace.
+func doctorEvent(issue prompt.Issue) string {
+	if issue.Type == "info" {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(issue.Code, "GWS-POLICY-"), orgPolicyCode(issue.Code):
+		return notify.EventPolicyViolation
+	case issue.Code == "GWS-KEYAGE-001", issue.Code == "GWS-GPG-005":
+		return notify.EventWorkspaceExpired
+	}
+	return ""
+}
+
+// notifyDoctorIssues raises the events of the issues that are new or
+// worse than in the last run, so a standing issue is only reported once
+func notifyDoctorIssues(gitRoot string, issues []prompt.Issue, last *health.Snapshot) {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Notify) == 0 {
+		return
+	}
+
+	var base []health.Issue
+	if last != nil {
+		base = last.Issues
+	}
+	changes, _ := health.Compare(base, healthIssues(issues))
+	for _, change := range changes {
+		issue := issues[change.Index]
+		event := doctorEvent(issue)
+		if event == "" {
+			continue
+		}
+		workspaceName := issue.Workspace
+		if workspaceName == "" {
+			workspaceName = workspaceForRepo(cfg, gitRoot)
+		}
+		notifyEvent(cfg, event, workspaceName, fmt.Sprintf("%s: %s", issue.Code, issue.Message))
+	}
+}
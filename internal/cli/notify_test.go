@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/notify"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+)
+
+func TestDoctorEvent(t *testing.T) {
+	tests := []struct {
+		code     string
+		typ      string
+		expected string
+	}{
+		{"GWS-POLICY-001", "warning", notify.EventPolicyViolation},
+		{"GWS-POLICY-003", "error", notify.EventPolicyViolation},
+		{"GWS-POLICY-002", "info", ""},
+		{"GWS-ORG-004", "error", notify.EventPolicyViolation},
+		{"GWS-KEYAGE-001", "warning", notify.EventWorkspaceExpired},
+		{"GWS-GPG-005", "error", notify.EventWorkspaceExpired},
+		{"GWS-GPG-006", "warning", ""},
+		{"GWS-SSH-001", "error", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			result := doctorEvent(prompt.Issue{Code: tt.code, Type: tt.typ})
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
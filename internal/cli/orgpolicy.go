@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/policy"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+)
+
+var (
+	orgPolicyOnce  sync.Once
+	orgPolicyValue *policy.Policy
+	orgPolicyErr   error
+)
+
+// loadOrgPolicy reads the organization policy once per process. It
+// returns nil when the administrator has not provided one.
+func loadOrgPolicy() (*policy.Policy, error) {
+	orgPolicyOnce.Do(func() {
+		configDir, err := config.ConfigDir()
+		if err != nil {
+			orgPolicyErr = err
+			return
+		}
+		orgPolicyValue, orgPolicyErr = policy.Load(configDir)
+	})
+	return orgPolicyValue, orgPolicyErr
+}
+
+// orgPolicyCode reports whether an issue code belongs to the organization
+// policy; those issues cannot be suppressed
+func orgPolicyCode(code string) bool {
+	return strings.HasPrefix(code, "GWS-ORG-")
+}
+
+// checkOrgPolicy reports where the repository and its workspace break the
+// organization policy
+func checkOrgPolicy(ctx context.Context, gitRoot, workspaceName string) []prompt.Issue {
+	pol, err := loadOrgPolicy()
+	if err != nil {
+		return []prompt.Issue{{
+			Code:    "GWS-ORG-001",
+			Type:    "error",
+			Message: i18n.T("Organization policy: %v", err),
+			Fix:     i18n.T("Ask your administrator to fix %s", policy.Path()),
+		}}
+	}
+	if pol == nil {
+		return nil
+	}
+
+	var issues []prompt.Issue
+	if pol.RefreshErr != nil {
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-ORG-002",
+			Type:    "warning",
+			Message: i18n.T("Organization policy could not be refreshed from %s: %v", pol.URL, pol.RefreshErr),
+			Fix:     i18n.T("Check your network connection; the last fetched rules still apply"),
+		})
+	}
+
+	if remoteURL, err := git.GetRemoteURL(gitRoot); err == nil && pol.BansRemote(remoteURL) {
+		issues = append(issues, prompt.Issue{
+			Code:      "GWS-ORG-003",
+			Type:      "error",
+			Message:   i18n.T("Organization policy bans HTTPS remotes; origin is %s", remoteURL),
+			Command:   fixCommand(workspaceName, gitRoot, "rewrite-remote"),
+			Workspace: workspaceName,
+			Path:      gitRoot,
+		})
+	}
+
+	if pol.HooksRequired() && workspaceName != "" {
+		if installed, err := git.CheckHooksInstalled(gitRoot); err == nil && !installed {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-ORG-004",
+				Type:      "error",
+				Message:   i18n.T("Organization policy requires guard hooks, which are not installed"),
+				Command:   fixCommand(workspaceName, gitRoot, "enable-guards"),
+				Workspace: workspaceName,
+				Path:      gitRoot,
+			})
+		}
+	}
+
+	if cfg, err := config.Load(); err == nil {
+		if ws, exists := cfg.GetWorkspace(workspaceName); exists && pol.RequiresSigning(ws.HostName) && (ws.Signing == "" || ws.Signing == "none") {
+			issues = append(issues, prompt.Issue{
+				Code:      "GWS-ORG-005",
+				Type:      "error",
+				Message:   i18n.T("Organization policy requires signed commits on %s, but workspace '%s' does not sign", ws.HostName, workspaceName),
+				Command:   []string{"gitws", "init", workspaceName, "--force", "--email", ws.Email, "--host-name", ws.HostName, "--signing", "ssh"},
+				Workspace: workspaceName,
+				Path:      gitRoot,
+			})
+		}
+	}
+	return issues
+}
+
+// enforceSigningPolicy makes a workspace on a host the organization
+// policy requires signing for sign with SSH by default, and refuses to
+// turn signing off
+func enforceSigningPolicy(name string, ws *config.Workspace) error {
+	pol, err := loadOrgPolicy()
+	if err != nil {
+		return err
+	}
+	if !pol.RequiresSigning(ws.HostName) {
+		return nil
+	}
+	switch ws.Signing {
+	case "":
+		ws.Signing = "ssh"
+	case "none":
+		return fmt.Errorf("workspace %q: organization policy requires signed commits on %s (use --signing ssh or gpg)", name, ws.HostName)
+	}
+	return nil
+}
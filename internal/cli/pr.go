@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prTitle string
+	prBody  string
+	prBase  string
+	prDraft bool
+)
+
+// prCmd represents the pr command
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Open and list pull requests with the workspace's token",
+	Long: `Open and list pull requests (merge requests on GitLab) for the current
+repository through the provider API, with the API token of the workspace
+the repository belongs to (see 'gitws auth login'). A work repository
+never gets a pull request from your personal account.
+
+A repository with an upstream remote (see 'gitws clone --upstream') is a
+fork: its pull requests go to upstream.
+
+Examples:
+  gitws pr create
+  gitws pr create --title "Fix login redirect" --base release --draft
+  gitws pr list`,
+}
+
+var prCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Open a pull request for the current branch",
+	Long: `Open a pull request from the current branch, which must be pushed to
+origin.
+
+The repository must commit as its workspace identity and reach origin
+through the workspace, as 'gitws git' checks; otherwise nothing is opened
+and the 'gitws fix' command that repairs it is shown.
+
+The title defaults to the subject of the last commit, the base branch to
+the repository's default branch.
+
+Examples:
+  gitws pr create
+  gitws pr create --title "Add retries" --body "Closes #12"
+  gitws pr create --base release --draft`,
+	Args: cobra.NoArgs,
+	RunE: runPRCreate,
+}
+
+var prListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the repository's open pull requests",
+	Args:  cobra.NoArgs,
+	RunE:  runPRList,
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prCreateCmd)
+	prCmd.AddCommand(prListCmd)
+
+	prCreateCmd.Flags().StringVar(&prTitle, "title", "", "Title (default: the last commit's subject)")
+	prCreateCmd.Flags().StringVar(&prBody, "body", "", "Description")
+	prCreateCmd.Flags().StringVar(&prBase, "base", "", "Branch to merge into (default: the repository's default branch)")
+	prCreateCmd.Flags().BoolVar(&prDraft, "draft", false, "Open it as a draft")
+}
+
+// prRepository is the repository a pr command works on
+type prRepository struct {
+	workspace string
+	ws        config.Workspace
+	gitRoot   string
+	client    provider.Provider
+	repo      string // Full name of the repository pull requests go to
+	origin    string // Full name of origin, a fork when it differs from repo
+}
+
+// pullRequestRepository resolves the current repository, its workspace
+// and a provider client with the workspace's token
+func pullRequestRepository() (*prRepository, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	gitRoot, err := git.FindGitRoot(cwd)
+	if err != nil {
+		return nil, notRepoError(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	name := workspaceForRepo(cfg, gitRoot)
+	if name == "" {
+		return nil, fmt.Errorf("no workspace serves %s; adopt it with 'gitws fix'", gitRoot)
+	}
+	ws := repoIdentity(cfg, name, gitRoot)
+
+	client, err := workspaceClient(name, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &prRepository{workspace: name, ws: ws, gitRoot: gitRoot, client: client}
+	remotes, err := git.RemoteURLs(gitRoot)
+	if err != nil {
+		return nil, err
+	}
+	if remotes["origin"] == "" {
+		return nil, fmt.Errorf("%s has no origin remote", gitRoot)
+	}
+	if r.origin, err = remoteFullName(remotes["origin"]); err != nil {
+		return nil, err
+	}
+	r.repo = r.origin
+	if upstream := remotes["upstream"]; upstream != "" {
+		if r.repo, err = remoteFullName(upstream); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// remoteFullName returns the ORG/REPO a remote URL points at
+func remoteFullName(remoteURL string) (string, error) {
+	org, repo, _, err := rewrite.RewriteURL(remoteURL, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse remote URL %s: %w", remoteURL, err)
+	}
+	return org + "/" + repo, nil
+}
+
+func runPRCreate(cmd *cobra.Command, args []string) error {
+	r, err := pullRequestRepository()
+	if err != nil {
+		return err
+	}
+
+	// The same checks as 'gitws git -- push'; upstream must go through
+	// the workspace too
+	problems := gitGateProblems(r.workspace, r.ws, r.gitRoot)
+	if upstream, err := git.GetConfig(r.gitRoot, "remote.upstream.url"); err == nil && upstream != "" && remoteNeedsRewrite(upstream, r.ws) {
+		issue := prompt.Issue{Message: fmt.Sprintf("upstream (%s) does not go through workspace '%s'", upstream, r.workspace)}
+		if _, _, rewritten, err := workspaceRemote(r.ws, upstream); err == nil {
+			issue.Command = []string{"git", "-C", r.gitRoot, "remote", "set-url", "upstream", rewritten}
+		}
+		problems = append(problems, issue)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, prompt.Text("❌ gitws: refusing to open a pull request from %s:\n"), r.gitRoot)
+		for _, issue := range problems {
+			fmt.Fprintf(os.Stderr, prompt.Text("   • %s\n"), issue.Message)
+			if fix := issue.FixText(); fix != "" {
+				fmt.Fprintf(os.Stderr, "     Fix: %s\n", fix)
+			}
+		}
+		return exitCode(cmd, ExitErrors)
+	}
+
+	branch := git.CurrentBranch(r.gitRoot)
+	if branch == "" {
+		return fmt.Errorf("HEAD is detached; check out the branch to open a pull request for")
+	}
+	if !git.RefExists(r.gitRoot, "refs/remotes/origin/"+branch) {
+		return fmt.Errorf("branch %s is not on origin; push it first: git push -u origin %s", branch, branch)
+	}
+
+	opts := provider.CreatePullRequestOptions{
+		Repo:  r.repo,
+		Head:  branch,
+		Base:  prBase,
+		Title: prTitle,
+		Body:  prBody,
+		Draft: prDraft,
+	}
+	if r.origin != r.repo {
+		opts.HeadRepo = r.origin
+	}
+	if opts.Title == "" {
+		if opts.Title, err = git.CommitSubject(r.gitRoot, "HEAD"); err != nil {
+			return err
+		}
+	}
+	if opts.Base == "" {
+		found, err := callAPI(cmd.Context(), func(ctx context.Context) (*provider.Repo, error) {
+			return r.client.GetRepo(ctx, r.repo)
+		})
+		if err != nil {
+			return err
+		}
+		opts.Base = found.DefaultBranch
+	}
+	if opts.HeadRepo == "" && opts.Base == branch {
+		return fmt.Errorf("%s is the base branch; open a pull request from another branch", branch)
+	}
+
+	pr, err := callAPI(cmd.Context(), func(ctx context.Context) (*provider.PullRequest, error) {
+		return r.client.CreatePullRequest(ctx, opts)
+	})
+	if err != nil {
+		return err
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title: "✓ Pull request opened",
+		Items: []prompt.SummaryItem{
+			{Label: "Workspace", Value: r.workspace, Icon: "📁"},
+			{Label: "Repository", Value: r.repo, Icon: "📦"},
+			{Label: "Pull Request", Value: fmt.Sprintf("#%d %s", pr.Number, pr.Title), Icon: "🔀"},
+			{Label: "Branch", Value: fmt.Sprintf("%s → %s", branch, opts.Base), Icon: "🌿"},
+			{Label: "Web URL", Value: pr.WebURL, Icon: "🌐"},
+		},
+	})
+}
+
+// prJSON is a pull request, as --json prints it
+type prJSON struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Head   string `json:"head"`
+	Base   string `json:"base"`
+	Draft  bool   `json:"draft"`
+	URL    string `json:"url"`
+}
+
+func runPRList(cmd *cobra.Command, args []string) error {
+	r, err := pullRequestRepository()
+	if err != nil {
+		return err
+	}
+
+	pulls, err := callAPI(cmd.Context(), func(ctx context.Context) ([]provider.PullRequest, error) {
+		return r.client.ListPullRequests(ctx, r.repo)
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		out := make([]prJSON, 0, len(pulls))
+		for _, p := range pulls {
+			out = append(out, prJSON{p.Number, p.Title, p.Author, p.Head, p.Base, p.Draft, p.WebURL})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	}
+
+	if len(pulls) == 0 {
+		fmt.Printf("No open pull requests in %s\n", r.repo)
+		return nil
+	}
+	headers := []string{"#", "Title", "Author", "Branch"}
+	var rows [][]string
+	for _, p := range pulls {
+		title := p.Title
+		if p.Draft && !strings.HasPrefix(title, "Draft:") {
+			title = "[draft] " + title
+		}
+		rows = append(rows, []string{strconv.Itoa(p.Number), title, p.Author, p.Head + " → " + p.Base})
+	}
+	return prompt.ShowStatusTable(headers, rows)
+}
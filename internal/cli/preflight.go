@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+)
+
+// preflightMinFree is the free space operations that write keys and
+// configs want in the home directory. Their files are small; this leaves
+// room for backups and the temporary copies atomic writes make.
+const preflightMinFree = 10 << 20
+
+// preflightChecks returns the checks init, rotate and remove run before
+// they change anything. needKeygen adds the check for ssh-keygen, for the
+// operations that create keys.
+func preflightChecks(cfg *config.File, needKeygen bool) []doctorCheck {
+	local := func(check func() []prompt.Issue) func(context.Context) []prompt.Issue {
+		return func(context.Context) []prompt.Issue { return check() }
+	}
+
+	checks := []doctorCheck{
+		{"writable", localCheckTimeout, local(checkManagedWritable)},
+		{"conflicts", localCheckTimeout, local(func() []prompt.Issue { return checkManagedConflicts(cfg) })},
+		{"disk-space", localCheckTimeout, local(checkDiskSpace)},
+	}
+	if needKeygen {
+		checks = append([]doctorCheck{{"ssh-keygen", localCheckTimeout, local(checkKeygen)}}, checks...)
+	}
+	return checks
+}
+
+// runPreflight runs checks and reports every problem they find at once,
+// so one run shows all that has to be fixed. It fails when any check
+// finds an error; warnings are printed and the operation goes ahead.
+func runPreflight(ctx context.Context, checks []doctorCheck) error {
+	results := runChecks(ctx, checks)
+
+	var problems, warnings []prompt.Issue
+	for i, result := range results {
+		if result.timedOut {
+			warnings = append(warnings, prompt.Issue{
+				Code:    "GWS-DOCTOR-001",
+				Type:    "warning",
+				Message: fmt.Sprintf("Check '%s' timed out after %s", checks[i].name, checks[i].timeout),
+			})
+			continue
+		}
+		for _, issue := range result.issues {
+			if issue.Type == "error" {
+				problems = append(problems, issue)
+			} else {
+				warnings = append(warnings, issue)
+			}
+		}
+	}
+
+	for _, issue := range warnings {
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %s [%s]\n"), issue.Message, issue.Code)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, prompt.Text("❌ Pre-flight checks failed; nothing was changed:"))
+	for _, issue := range problems {
+		fmt.Fprintf(os.Stderr, prompt.Text("   • %s [%s]\n"), issue.Message, issue.Code)
+		if fix := issue.FixText(); fix != "" {
+			fmt.Fprintf(os.Stderr, "     Fix: %s\n", fix)
+		}
+	}
+	return fmt.Errorf("pre-flight checks found %d problem(s)", len(problems))
+}
+
+// checkKeygen checks that ssh-keygen is available to create keys
+func checkKeygen() []prompt.Issue {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		return []prompt.Issue{{
+			Code:    "GWS-PREFLIGHT-001",
+			Type:    "error",
+			Message: "ssh-keygen was not found in PATH",
+			Fix:     "Install OpenSSH (the openssh-client package on Debian and Ubuntu)",
+		}}
+	}
+	return nil
+}
+
+// checkManagedWritable checks that the files and directories gitws
+// writes can be written
+func checkManagedWritable() []prompt.Issue {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".ssh"))
+	}
+	if path, err := ssh.ConfigPath(); err == nil {
+		paths = append(paths, path)
+	}
+	if path, err := globalGitConfigPath(); err == nil {
+		paths = append(paths, path)
+	}
+	if dir, err := config.ConfigDir(); err == nil {
+		paths = append(paths, dir)
+	}
+
+	var issues []prompt.Issue
+	for _, path := range paths {
+		if err := checkWritable(path); err != nil {
+			issues = append(issues, prompt.Issue{
+				Code:    "GWS-PREFLIGHT-002",
+				Type:    "error",
+				Message: fmt.Sprintf("%s is not writable: %v", path, err),
+				Fix:     fmt.Sprintf("Check the ownership and permissions of %s", path),
+				Path:    path,
+			})
+		}
+	}
+	return issues
+}
+
+// checkWritable checks that path can be written: an existing file is
+// opened for writing without changing it, and a temporary file is created
+// in the directory that holds or will hold path
+func checkWritable(path string) error {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		// Atomic writes replace the file from its directory
+		dir = filepath.Dir(path)
+	} else if err != nil {
+		dir = filepath.Dir(path)
+	}
+
+	// A directory still to be created is created in its closest
+	// existing parent
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".gitws-preflight-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkManagedConflicts looks for unresolved merge conflicts, left by a
+// dotfiles manager or a sync tool, in the files gitws manages. gitws
+// would otherwise rewrite them around the markers.
+func checkManagedConflicts(cfg *config.File) []prompt.Issue {
+	paths := make(map[string]bool)
+	if path, err := ssh.ConfigPath(); err == nil {
+		paths[path] = true
+	}
+	if path, err := globalGitConfigPath(); err == nil {
+		paths[path] = true
+	}
+	if artifacts, err := allArtifacts(cfg); err == nil {
+		for _, a := range artifacts {
+			paths[a.Path] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var issues []prompt.Issue
+	for _, path := range sorted {
+		data, err := os.ReadFile(path)
+		if err != nil || !fsutil.HasConflictMarkers(string(data)) {
+			continue
+		}
+		issues = append(issues, prompt.Issue{
+			Code:    "GWS-PREFLIGHT-003",
+			Type:    "error",
+			Message: fmt.Sprintf("%s has unresolved merge conflicts", path),
+			Fix:     fmt.Sprintf("Resolve the conflict markers in %s", path),
+			Path:    path,
+		})
+	}
+	return issues
+}
+
+// checkDiskSpace checks the free space in the home directory
+func checkDiskSpace() []prompt.Issue {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	free, err := fsutil.FreeSpace(home)
+	if err != nil {
+		// Not knowing is no reason to stop
+		return nil
+	}
+	if free < preflightMinFree {
+		return []prompt.Issue{{
+			Code:    "GWS-PREFLIGHT-004",
+			Type:    "error",
+			Message: fmt.Sprintf("Only %d KB free in %s; at least %d MB are needed", free>>10, home, preflightMinFree>>20),
+			Fix:     "Free some disk space",
+			Path:    home,
+		}}
+	}
+	return nil
+}
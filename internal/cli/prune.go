@@ -0,0 +1,458 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneYes                bool
+	pruneDryRun             bool
+	pruneRemoveMissingRoots bool
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Find and remove artifacts of workspaces that are gone",
+	Long: `Find what gitws left behind for workspaces that no longer exist in
+config.yaml, e.g. after editing it by hand or restoring an older copy:
+
+- Managed blocks in ~/.ssh/config, including those of removed extra
+  hosts and identities
+- includeIf entries in ~/.gitconfig
+- Workspace gitconfigs under ~/.gws/gitconfig, and excludes and
+  attributes files under ~/.gws/<workspace>
+- SSH keys named id_ed25519_gws_* that no workspace or identity uses
+- Workspaces whose root no longer exists on disk
+
+Each finding is offered for removal in turn. Keys are moved aside with an
+.old- suffix rather than deleted. A root can be missing for a while, e.g.
+before the first clone or while a disk is not mounted, so workspaces
+whose root is gone are only listed unless --remove-missing-roots is
+given; they are then removed from config.yaml after you confirm, together
+with their managed files, and their keys are kept. --yes alone never
+removes a workspace.
+
+Examples:
+  gitws prune --dry-run
+  gitws prune
+  gitws prune --yes
+  gitws prune --remove-missing-roots`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().BoolVar(&pruneYes, "yes", false, "Remove everything found without asking")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Only list what would be removed")
+	pruneCmd.Flags().BoolVar(&pruneRemoveMissingRoots, "remove-missing-roots", false, "Also remove workspaces whose root does not exist")
+}
+
+// Kinds of orphaned artifacts
+const (
+	orphanSSHBlock   = "ssh-block"
+	orphanIncludeIf  = "includeIf"
+	orphanGitConfig  = "gitconfig"
+	orphanPatterns   = "patterns"
+	orphanKey        = "key"
+	orphanMissingDir = "root"
+)
+
+// orphan is an artifact left behind by a workspace that is gone, and how
+// to remove it
+type orphan struct {
+	Kind   string
+	Name   string
+	Path   string
+	Reason string
+	remove func(cfg *config.File) error
+}
+
+// managedBlockStart matches the start marker of a managed block
+var managedBlockStart = regexp.MustCompile(`(?m)^# >>> gws (.+) >>> DO NOT EDIT$`)
+
+// includeIfPath matches the path line of an includeIf entry
+var includeIfPath = regexp.MustCompile(`(?m)^\s*path = (.+)$`)
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	orphans, err := findOrphans(cfg)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println(prompt.Text("✓ Nothing to prune"))
+		return nil
+	}
+
+	headers := []string{"Kind", "Name", "Path", "Reason"}
+	rows := make([][]string, 0, len(orphans))
+	for _, o := range orphans {
+		rows = append(rows, []string{o.Kind, o.Name, o.Path, o.Reason})
+	}
+	if err := prompt.ShowStatusTable(headers, rows); err != nil {
+		return err
+	}
+	if pruneDryRun || prompt.Structured() {
+		return nil
+	}
+
+	if err := runPreflight(cmd.Context(), preflightChecks(cfg, false)); err != nil {
+		return err
+	}
+
+	removed := 0
+	configChanged := false
+	for _, o := range orphans {
+		if o.Kind == orphanMissingDir && !pruneRemoveMissingRoots {
+			fmt.Printf(prompt.Text("ℹ️  Kept workspace '%s': its root may not be created or mounted yet; --remove-missing-roots removes it\n"), o.Name)
+			continue
+		}
+		if !pruneYes {
+			confirmed, err := prompt.Confirm(fmt.Sprintf("Remove %s %s (%s)?", o.Kind, o.Name, o.Reason))
+			if err != nil {
+				return fmt.Errorf("failed to get confirmation: %w", err)
+			}
+			if !confirmed {
+				continue
+			}
+		}
+		if err := o.remove(cfg); err != nil {
+			return err
+		}
+		removed++
+		configChanged = configChanged || o.Kind == orphanMissingDir
+	}
+
+	if configChanged {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		if err := refreshGlobalGuard(cfg); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(prompt.Text("✓ Pruned %d of %d\n"), removed, len(orphans))
+	return nil
+}
+
+// findOrphans returns the artifacts of workspaces, extra hosts and
+// identities missing from cfg, and the workspaces whose root is gone
+func findOrphans(cfg *config.File) ([]orphan, error) {
+	var orphans []orphan
+	for _, find := range []func(*config.File) ([]orphan, error){
+		orphanSSHBlocks, orphanIncludeIfs, orphanGitConfigs, orphanPatternDirs, orphanKeys, missingRoots,
+	} {
+		found, err := find(cfg)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, found...)
+	}
+	return orphans, nil
+}
+
+// blockOwnerMissing explains why the managed SSH block name belongs to
+// nothing in cfg, or returns ""
+func blockOwnerMissing(cfg *config.File, name string) string {
+	if wsName, identity, ok := strings.Cut(name, ":"); ok {
+		ws, exists := cfg.GetWorkspace(wsName)
+		if !exists {
+			return fmt.Sprintf("workspace %q not found", wsName)
+		}
+		if _, exists := ws.Identities[identity]; !exists {
+			return fmt.Sprintf("workspace %q has no identity %q", wsName, identity)
+		}
+		return ""
+	}
+	if wsName, host, ok := strings.Cut(name, "@"); ok {
+		ws, exists := cfg.GetWorkspace(wsName)
+		if !exists {
+			return fmt.Sprintf("workspace %q not found", wsName)
+		}
+		for _, h := range ws.Hosts {
+			if h.HostName == host {
+				return ""
+			}
+		}
+		return fmt.Sprintf("workspace %q has no extra host %s", wsName, host)
+	}
+	if _, exists := cfg.GetWorkspace(name); !exists {
+		return fmt.Sprintf("workspace %q not found", name)
+	}
+	return ""
+}
+
+func orphanSSHBlocks(cfg *config.File) ([]orphan, error) {
+	configPath, err := ssh.ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	var orphans []orphan
+	for _, m := range managedBlockStart.FindAllStringSubmatch(string(data), -1) {
+		name := m[1]
+		if m[0] == workspace.IncludeIfStartMarker() || m[0] == workspace.HostGuardStartMarker() {
+			continue
+		}
+		reason := blockOwnerMissing(cfg, name)
+		if reason == "" {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			Kind:   orphanSSHBlock,
+			Name:   name,
+			Path:   configPath,
+			Reason: reason,
+			remove: func(*config.File) error {
+				if err := ssh.RemoveSSHConfigBlock(name); err != nil {
+					return fmt.Errorf("failed to remove SSH config block for %q: %w", name, err)
+				}
+				return nil
+			},
+		})
+	}
+	return orphans, nil
+}
+
+func orphanIncludeIfs(cfg *config.File) ([]orphan, error) {
+	artifact, err := includeIfArtifact(cfg)
+	if err != nil || !artifact.Present {
+		return nil, err
+	}
+
+	var orphans []orphan
+	for _, m := range includeIfPath.FindAllStringSubmatch(artifact.Actual, -1) {
+		name := filepath.Base(strings.TrimSpace(m[1]))
+		if _, exists := cfg.GetWorkspace(name); exists {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			Kind:   orphanIncludeIf,
+			Name:   name,
+			Path:   artifact.Path,
+			Reason: fmt.Sprintf("workspace %q not found", name),
+			// Rewriting the block from config.yaml drops every stale entry
+			remove: func(cfg *config.File) error {
+				if err := updateGlobalGitConfig(cfg); err != nil {
+					return fmt.Errorf("failed to update global gitconfig: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+	return orphans, nil
+}
+
+func orphanGitConfigs(cfg *config.File) ([]orphan, error) {
+	probe, err := workspace.GitConfigPath("probe")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Dir(probe))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Dir(probe), err)
+	}
+
+	var orphans []orphan
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.Contains(name, ".gws-") {
+			continue
+		}
+		if _, exists := cfg.GetWorkspace(name); exists {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(probe), name)
+		orphans = append(orphans, orphan{
+			Kind:   orphanGitConfig,
+			Name:   name,
+			Path:   path,
+			Reason: fmt.Sprintf("workspace %q not found", name),
+			remove: func(*config.File) error { return fsutil.Remove(path) },
+		})
+	}
+	return orphans, nil
+}
+
+// orphanPatternDirs finds workspace directories under ~/.gws holding
+// nothing but the excludes and attributes files gitws writes there, so
+// directories of anything else are never touched
+func orphanPatternDirs(cfg *config.File) ([]orphan, error) {
+	configDir, err := workspace.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", configDir, err)
+	}
+
+	var orphans []orphan
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || config.IsReservedName(name) {
+			continue
+		}
+		if _, exists := cfg.GetWorkspace(name); exists {
+			continue
+		}
+		dir, err := workspace.Dir(name)
+		if err != nil {
+			return nil, err
+		}
+		if !onlyPatternFiles(dir) {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			Kind:   orphanPatterns,
+			Name:   name,
+			Path:   dir,
+			Reason: fmt.Sprintf("workspace %q not found", name),
+			remove: func(*config.File) error { return removePatternDir(dir) },
+		})
+	}
+	return orphans, nil
+}
+
+// onlyPatternFiles reports whether dir holds an excludes or attributes
+// file and nothing else
+func onlyPatternFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || (entry.Name() != "gitignore" && entry.Name() != "gitattributes") {
+			return false
+		}
+	}
+	return true
+}
+
+func orphanKeys(cfg *config.File) ([]orphan, error) {
+	probe, err := ssh.KeyPath("probe")
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(probe, "probe")
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, name := range cfg.ListWorkspaces() {
+		ws := cfg.Workspaces[name]
+		used[ws.SSHKey] = true
+		for _, id := range ws.Identities {
+			used[id.SSHKey] = true
+		}
+	}
+
+	var orphans []orphan
+	sort.Strings(matches)
+	for _, path := range matches {
+		if strings.HasSuffix(path, ".pub") || strings.Contains(filepath.Base(path), ".") || used[path] {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			Kind:   orphanKey,
+			Name:   strings.TrimPrefix(path, prefix),
+			Path:   path,
+			Reason: "no workspace or identity uses it",
+			remove: func(*config.File) error {
+				if err := backupExistingKey(path); err != nil {
+					return fmt.Errorf("failed to move %s aside: %w", path, err)
+				}
+				return nil
+			},
+		})
+	}
+	return orphans, nil
+}
+
+func missingRoots(cfg *config.File) ([]orphan, error) {
+	var orphans []orphan
+	for _, name := range cfg.ListWorkspaces() {
+		root, err := workspace.ExpandPath(cfg.Workspaces[name].Root)
+		if err != nil || fsutil.FileExists(root) {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			Kind:   orphanMissingDir,
+			Name:   name,
+			Path:   root,
+			Reason: "root does not exist",
+			remove: func(cfg *config.File) error {
+				return removeWorkspace(cfg, name)
+			},
+		})
+	}
+	return orphans, nil
+}
+
+// removeWorkspace drops a workspace from cfg along with its managed
+// files; its keys are kept. cfg is saved by the caller.
+func removeWorkspace(cfg *config.File, name string) error {
+	ws := cfg.Workspaces[name]
+	if err := removeWorkspaceArtifacts(name); err != nil {
+		return err
+	}
+	if err := removeExtraSSHBlocks(name, ws); err != nil {
+		return err
+	}
+	delete(cfg.Workspaces, name)
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+	return nil
+}
+
+// removePatternDir removes the excludes and attributes files in dir,
+// through fsutil so the removal is audited and can be rolled back, then
+// the emptied directory
+func removePatternDir(dir string) error {
+	for _, name := range []string{"gitignore", "gitattributes"} {
+		if err := fsutil.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return nil
+}
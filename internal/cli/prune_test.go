@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+// testHome points HOME and the gitws home at a temporary directory and
+// returns it
+func testHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.HomeEnv, filepath.Join(home, ".gws"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	return home
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockOwnerMissing(t *testing.T) {
+	cfg := &config.File{Workspaces: map[string]config.Workspace{
+		"work": {
+			Hosts:      []config.HostAlias{{HostName: "gitlab.com", SSHAlias: "gitlab-com-work"}},
+			Identities: map[string]config.Identity{"oss": {Email: "me@oss.org"}},
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		missing bool
+	}{
+		{"work", false},
+		{"work@gitlab.com", false},
+		{"work:oss", false},
+		{"gone", true},
+		{"work@bitbucket.org", true},
+		{"work:ci", true},
+		{"gone@gitlab.com", true},
+		{"gone:oss", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := blockOwnerMissing(cfg, tt.name)
+			if (reason != "") != tt.missing {
+				t.Errorf("expected missing %v, got reason %q", tt.missing, reason)
+			}
+		})
+	}
+}
+
+func TestOnlyPatternFiles(t *testing.T) {
+	base := t.TempDir()
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected bool
+	}{
+		{"empty", nil, false},
+		{"excludes", []string{"gitignore"}, true},
+		{"both", []string{"gitignore", "gitattributes"}, true},
+		{"other", []string{"gitignore", "notes.txt"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(base, tt.name)
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range tt.files {
+				writeTestFile(t, filepath.Join(dir, f), "*.log\n")
+			}
+			if result := onlyPatternFiles(dir); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	home := testHome(t)
+	root := filepath.Join(home, "code", "work")
+	if err := os.MkdirAll(root, 0700); err != nil {
+		t.Fatal(err)
+	}
+	gws := filepath.Join(home, ".gws")
+	writeTestFile(t, filepath.Join(gws, "gitconfig", "work"), "[user]\n")
+	writeTestFile(t, filepath.Join(gws, "gitconfig", "gone"), "[user]\n")
+	writeTestFile(t, filepath.Join(gws, "gone", "gitignore"), "*.log\n")
+	writeTestFile(t, filepath.Join(gws, "notes", "todo.txt"), "keep\n")
+	writeTestFile(t, filepath.Join(home, ".ssh", "id_ed25519_gws_gone"), "private")
+	writeTestFile(t, filepath.Join(home, ".ssh", "id_ed25519_gws_work"), "private")
+	writeTestFile(t, filepath.Join(home, ".ssh", "config"),
+		"# >>> gws gone >>> DO NOT EDIT\nHost github-com-gone\n# <<< gws gone <<<\n")
+
+	cfg := &config.File{Workspaces: map[string]config.Workspace{
+		"work":      {Root: root, SSHKey: filepath.Join(home, ".ssh", "id_ed25519_gws_work")},
+		"unmounted": {Root: filepath.Join(home, "mnt", "code")},
+	}}
+
+	orphans, err := findOrphans(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var found []string
+	for _, o := range orphans {
+		found = append(found, o.Kind+" "+o.Name)
+	}
+	sort.Strings(found)
+	expected := []string{"gitconfig gone", "key gone", "patterns gone", "root unmounted", "ssh-block gone"}
+	if len(found) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, found)
+	}
+	for i := range expected {
+		if found[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, found)
+			break
+		}
+	}
+
+	for _, o := range orphans {
+		if o.Kind != orphanGitConfig && o.Kind != orphanPatterns {
+			continue
+		}
+		if err := o.remove(cfg); err != nil {
+			t.Fatalf("unexpected error removing %s: %v", o.Path, err)
+		}
+		if _, err := os.Stat(o.Path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", o.Path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gws, "notes", "todo.txt")); err != nil {
+		t.Errorf("expected other directories to be kept, got %v", err)
+	}
+}
+
+func TestOrphanPatternDirsKeepsReserved(t *testing.T) {
+	home := testHome(t)
+	gws := filepath.Join(home, ".gws")
+	// The gitconfig of a workspace named gitignore looks like a pattern file
+	writeTestFile(t, filepath.Join(gws, "gitconfig", "gitignore"), "[user]\n")
+
+	cfg := &config.File{Workspaces: map[string]config.Workspace{"gitignore": {}}}
+	orphans, err := orphanPatternDirs(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("expected no orphans, got %v", orphans)
+	}
+}
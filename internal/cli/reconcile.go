@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/email"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+)
+
+// Managed artifact kinds
+const (
+	artifactSSHConfig  = "ssh-config"
+	artifactIncludeIf  = "includeIf"
+	artifactGitConfig  = "gitconfig"
+	artifactExcludes   = "excludes"
+	artifactAttributes = "attributes"
+	artifactEnvrc      = "envrc"
+)
+
+// managedArtifact is one piece of on-disk state gitws owns, with the content
+// derived from config.yaml and the content currently on disk
+type managedArtifact struct {
+	Kind      string
+	Workspace string // empty for artifacts shared by all workspaces
+	Path      string
+	Desired   string
+	Actual    string
+	Present   bool
+}
+
+// InSync reports whether the artifact on disk matches the desired content
+func (a managedArtifact) InSync() bool {
+	return a.Present && strings.TrimSpace(a.Desired) == strings.TrimSpace(a.Actual)
+}
+
+// resolveWorkspace validates a workspace definition and fills in every
+// derived setting (hostname, alias, key path, root, display name)
+func resolveWorkspace(name string, def config.Workspace) (config.Workspace, error) {
+	system, err := config.LoadSystem()
+	if err != nil {
+		return def, err
+	}
+	if err := config.ValidateWorkspaceName(name); err != nil {
+		return def, fmt.Errorf("workspace %w", err)
+	}
+	ws := system.ApplyDefaults(def)
+	ws.Email = email.Normalize(ws.Email)
+	ws.PrimaryEmail = email.Normalize(ws.PrimaryEmail)
+	ws.Name = strings.Join(strings.Fields(ws.Name), " ")
+
+	if ws.Email == "" {
+		return ws, fmt.Errorf("workspace %q: email is required", name)
+	}
+	if err := email.Validate(ws.Email); err != nil {
+		return ws, fmt.Errorf("workspace %q: %w", name, err)
+	}
+	if ws.EnforceNoreply && !email.IsNoreply(ws.Email) {
+		return ws, fmt.Errorf("workspace %q: enforce_noreply is set, but %s is not a noreply address (run 'gitws email use-noreply %s')", name, ws.Email, name)
+	}
+	if ws.PrimaryEmail == ws.Email {
+		ws.PrimaryEmail = ""
+	} else if ws.PrimaryEmail != "" {
+		if err := email.Validate(ws.PrimaryEmail); err != nil {
+			return ws, fmt.Errorf("workspace %q: primary_email: %w", name, err)
+		}
+	}
+	if ws.MaxKeyAge != "" {
+		if _, err := parseDays(ws.MaxKeyAge); err != nil {
+			return ws, fmt.Errorf("workspace %q: max_key_age: %w", name, err)
+		}
+	}
+	if ws.EnvFile != "" && ws.EnvFile != envFileEnvrc {
+		return ws, fmt.Errorf("workspace %q: invalid env_file: %s (must be %s)", name, ws.EnvFile, envFileEnvrc)
+	}
+	for key := range ws.Env {
+		if !envVarName.MatchString(key) {
+			return ws, fmt.Errorf("workspace %q: invalid env variable name: %q", name, key)
+		}
+	}
+
+	// Resolve hostname
+	switch {
+	case ws.Provider != "":
+		host, exists := workspace.ProviderHosts[ws.Provider]
+		if !exists {
+			return ws, fmt.Errorf("workspace %q: unknown provider: %s (supported: github, gitlab, bitbucket)", name, ws.Provider)
+		}
+		ws.HostName = host
+	case ws.HostName == "":
+		return ws, fmt.Errorf("workspace %q: either provider or host name must be specified", name)
+	}
+
+	// Build SSH alias
+	if ws.SSHAlias == "" {
+		providerOrHost := ws.Provider
+		if providerOrHost == "" {
+			providerOrHost = ws.HostName
+		}
+		ws.SSHAlias = workspace.BuildSSHAlias(providerOrHost, name)
+	}
+	if !config.ValidSSHValue(ws.HostName) || !config.ValidSSHValue(ws.SSHAlias) {
+		return ws, fmt.Errorf("workspace %q: host name and SSH alias must not contain whitespace or control characters", name)
+	}
+
+	if ws.SSHKey == "" {
+		keyPath, err := ssh.KeyPath(name)
+		if err != nil {
+			return ws, err
+		}
+		ws.SSHKey = keyPath
+	}
+
+	// Set default root if not provided
+	if ws.Root == "" {
+		root, err := workspace.DefaultRoot(name)
+		if err != nil {
+			return ws, fmt.Errorf("failed to get default root: %w", err)
+		}
+		ws.Root = root
+	}
+	root, err := workspace.ExpandPath(ws.Root)
+	if err != nil {
+		return ws, fmt.Errorf("failed to expand root path: %w", err)
+	}
+	ws.Root = root
+
+	// Set display name
+	if ws.Name == "" {
+		ws.Name = name
+		if user := os.Getenv("USER"); user != "" {
+			ws.Name = user
+		}
+	}
+
+	if err := enforceSigningPolicy(name, &ws); err != nil {
+		return ws, err
+	}
+	switch ws.Signing {
+	case "":
+		ws.Signing = "none"
+	case "none", "ssh":
+	case "gpg":
+		if ws.GPGKey == "" {
+			return ws, fmt.Errorf("workspace %q: a GPG key ID is required when using gpg signing", name)
+		}
+	default:
+		return ws, fmt.Errorf("workspace %q: unknown signing method: %s (supported: none, ssh, gpg)", name, ws.Signing)
+	}
+
+	if ws.Isolation != "" && !workspace.IsValidIsolation(ws.Isolation) {
+		return ws, fmt.Errorf("workspace %q: unknown isolation mode: %s (supported: gitdir, hasconfig)", name, ws.Isolation)
+	}
+	if ws.Isolation == workspace.IsolationGitDir {
+		ws.Isolation = "" // default, kept out of config.yaml
+	}
+
+	if ws.Transport != "" && !workspace.IsValidTransport(ws.Transport) {
+		return ws, fmt.Errorf("workspace %q: unknown transport: %s (supported: ssh, https)", name, ws.Transport)
+	}
+	if ws.Transport == workspace.TransportSSH {
+		ws.Transport = "" // default, kept out of config.yaml
+	}
+	// hasconfig isolation matches remotes by their SSH alias, which HTTPS
+	// remotes do not have
+	if ws.Transport == workspace.TransportHTTPS && ws.Isolation == workspace.IsolationHasconfig {
+		return ws, fmt.Errorf("workspace %q: the https transport needs gitdir isolation", name)
+	}
+
+	if ws.PullStrategy != "" && !workspace.IsValidPullStrategy(ws.PullStrategy) {
+		return ws, fmt.Errorf("workspace %q: unknown pull strategy: %s (supported: merge, rebase, ff-only)", name, ws.PullStrategy)
+	}
+
+	if ws.CommitTemplate != "" {
+		template, err := workspace.ExpandPath(ws.CommitTemplate)
+		if err != nil {
+			return ws, fmt.Errorf("failed to expand commit template path: %w", err)
+		}
+		ws.CommitTemplate = template
+	}
+
+	if _, err := hookPolicy(name, ws.Hooks); err != nil {
+		return ws, err
+	}
+
+	hosts, err := resolveHosts(name, ws)
+	if err != nil {
+		return ws, err
+	}
+	ws.Hosts = hosts
+
+	if len(ws.Identities) > 0 {
+		identities := make(map[string]config.Identity, len(ws.Identities))
+		for _, id := range sortedIdentities(ws) {
+			identity, err := resolveIdentity(name, ws, id, ws.Identities[id])
+			if err != nil {
+				return ws, err
+			}
+			identities[id] = identity
+		}
+		ws.Identities = identities
+	}
+
+	return ws, nil
+}
+
+func isolationDisplay(mode string) string {
+	if mode == "" {
+		return workspace.IsolationGitDir
+	}
+	return mode
+}
+
+// workspaceArtifacts returns the desired and actual state of the files a
+// single workspace owns
+func workspaceArtifacts(name string, ws config.Workspace) ([]managedArtifact, error) {
+	sshConfigPath, err := ssh.ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sshBlock := ssh.RenderSSHConfigBlock(name, ws.SSHHosts(time.Now()), ws.HostName, ws.SSHKey)
+	desiredBlock, _ := fsutil.ExtractBetweenMarkers(sshBlock, workspace.StartMarker(name), workspace.EndMarker(name))
+	actualBlock, found, err := ssh.ReadManagedBlock(name)
+	if err != nil {
+		return nil, err
+	}
+
+	gitConfigPath, err := workspace.GitConfigPath(name)
+	if err != nil {
+		return nil, err
+	}
+	var actualGitConfig string
+	gitConfigPresent := false
+	if data, err := os.ReadFile(gitConfigPath); err == nil {
+		actualGitConfig = string(data)
+		gitConfigPresent = true
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read workspace gitconfig: %w", err)
+	}
+
+	desiredGitConfig, err := renderWorkspaceGitConfig(name, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := []managedArtifact{
+		{
+			Kind:      artifactSSHConfig,
+			Workspace: name,
+			Path:      sshConfigPath,
+			Desired:   desiredBlock,
+			Actual:    actualBlock,
+			Present:   found,
+		},
+		{
+			Kind:      artifactGitConfig,
+			Workspace: name,
+			Path:      gitConfigPath,
+			Desired:   desiredGitConfig,
+			Actual:    actualGitConfig,
+			Present:   gitConfigPresent,
+		},
+	}
+
+	// Extra hosts and identities get their own SSH blocks, named
+	// workspace@host and workspace:identity
+	type extraBlock struct{ name, alias, host, key string }
+	var extras []extraBlock
+	for _, h := range ws.Hosts {
+		extras = append(extras, extraBlock{config.HostBlockName(name, h.HostName), h.SSHAlias, h.HostName, ws.SSHKey})
+	}
+	for _, id := range sortedIdentities(ws) {
+		identity := ws.Identities[id]
+		extras = append(extras, extraBlock{config.IdentityName(name, id), identity.SSHAlias, ws.HostName, identity.SSHKey})
+	}
+	for _, b := range extras {
+		block := ssh.RenderSSHConfigBlock(b.name, b.alias, b.host, b.key)
+		artifact := managedArtifact{Kind: artifactSSHConfig, Workspace: b.name, Path: sshConfigPath}
+		artifact.Desired, _ = fsutil.ExtractBetweenMarkers(block, workspace.StartMarker(b.name), workspace.EndMarker(b.name))
+		artifact.Actual, artifact.Present, err = ssh.ReadManagedBlock(b.name)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	// Pattern files only exist for workspaces that configure them
+	patternFiles := []struct {
+		kind  string
+		lines []string
+		path  func(string) (string, error)
+	}{
+		{artifactExcludes, ws.Ignore, workspace.ExcludesFilePath},
+		{artifactAttributes, ws.Attributes, workspace.AttributesFilePath},
+	}
+	for _, f := range patternFiles {
+		if len(f.lines) == 0 {
+			continue
+		}
+		path, err := f.path(name)
+		if err != nil {
+			return nil, err
+		}
+		artifact := managedArtifact{
+			Kind:      f.kind,
+			Workspace: name,
+			Path:      path,
+			Desired:   renderPatternFile(name, f.lines),
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			artifact.Actual, artifact.Present = string(data), true
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	if ws.EnvFile == envFileEnvrc {
+		path := envrcPath(ws)
+		block := renderEnvrcBlock(name, ws)
+		artifact := managedArtifact{
+			Kind:      artifactEnvrc,
+			Workspace: name,
+			Path:      path,
+		}
+		artifact.Desired, _ = fsutil.ExtractBetweenMarkers(block, workspace.StartMarker(name), workspace.EndMarker(name))
+		if data, err := os.ReadFile(path); err == nil {
+			artifact.Actual, artifact.Present = fsutil.ExtractBetweenMarkers(string(data), workspace.StartMarker(name), workspace.EndMarker(name))
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, nil
+}
+
+// includeIfArtifact returns the desired and actual managed includeIf block
+func includeIfArtifact(cfg *config.File) (managedArtifact, error) {
+	gitConfigPath, err := globalGitConfigPath()
+	if err != nil {
+		return managedArtifact{}, err
+	}
+
+	block, err := buildIncludeIfBlock(cfg)
+	if err != nil {
+		return managedArtifact{}, err
+	}
+	startMarker := workspace.IncludeIfStartMarker()
+	endMarker := workspace.IncludeIfEndMarker()
+	desired, _ := fsutil.ExtractBetweenMarkers(block, startMarker, endMarker)
+
+	artifact := managedArtifact{
+		Kind:    artifactIncludeIf,
+		Path:    gitConfigPath,
+		Desired: desired,
+	}
+
+	data, err := os.ReadFile(gitConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return artifact, nil
+		}
+		return artifact, fmt.Errorf("failed to read gitconfig: %w", err)
+	}
+	artifact.Actual, artifact.Present = fsutil.ExtractBetweenMarkers(string(data), startMarker, endMarker)
+
+	return artifact, nil
+}
+
+// allArtifacts returns every managed artifact for the workspaces in cfg
+func allArtifacts(cfg *config.File) ([]managedArtifact, error) {
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+
+	var artifacts []managedArtifact
+	for _, name := range names {
+		wsArtifacts, err := workspaceArtifacts(name, cfg.Workspaces[name])
+		if err != nil {
+			return nil, fmt.Errorf("workspace %q: %w", name, err)
+		}
+		artifacts = append(artifacts, wsArtifacts...)
+	}
+
+	includeIf, err := includeIfArtifact(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(artifacts, includeIf), nil
+}
+
+// rootOverlap is a pair of workspaces whose includeIf conditions both
+// match repositories under Inner's root
+type rootOverlap struct {
+	Outer string
+	Inner string
+}
+
+// nestedRoots returns every workspace whose root lies inside another
+// workspace's root. Workspaces isolated by remote are not matched by
+// directory, so they never overlap.
+func nestedRoots(cfg *config.File) []rootOverlap {
+	names := cfg.ListWorkspaces()
+
+	var overlaps []rootOverlap
+	for _, outer := range names {
+		o := cfg.Workspaces[outer]
+		if o.Root == "" || followsRemote(o) {
+			continue
+		}
+		for _, inner := range names {
+			i := cfg.Workspaces[inner]
+			if i.Root == "" || followsRemote(i) {
+				continue
+			}
+			if isWithin(filepath.Clean(i.Root), filepath.Clean(o.Root)) {
+				overlaps = append(overlaps, rootOverlap{Outer: outer, Inner: inner})
+			}
+		}
+	}
+	return overlaps
+}
+
+// workspaceWithRoot returns the workspace other than name whose root is
+// root, or ""
+func workspaceWithRoot(cfg *config.File, name, root string) string {
+	for _, other := range cfg.ListWorkspaces() {
+		if other != name && filepath.Clean(cfg.Workspaces[other].Root) == filepath.Clean(root) {
+			return other
+		}
+	}
+	return ""
+}
+
+// includeIfOrder returns the workspaces in the order their includeIf
+// entries are written: by name, except that a nested workspace follows
+// the workspaces it is nested in. git applies every matching include in
+// turn, so the innermost root is read last and wins.
+func includeIfOrder(cfg *config.File) []string {
+	names := cfg.ListWorkspaces()
+	outers := make(map[string][]string)
+	for _, overlap := range nestedRoots(cfg) {
+		outers[overlap.Inner] = append(outers[overlap.Inner], overlap.Outer)
+	}
+
+	order := make([]string, 0, len(names))
+	placed := make(map[string]bool)
+	for len(order) < len(names) {
+		next := ""
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, outer := range outers[name] {
+				ready = ready && placed[outer]
+			}
+			if ready {
+				next = name
+				break
+			}
+		}
+		if next == "" {
+			// Unreachable for strict nesting; keep the rest by name
+			for _, name := range names {
+				if !placed[name] {
+					order = append(order, name)
+				}
+			}
+			break
+		}
+		order = append(order, next)
+		placed[next] = true
+	}
+	return order
+}
+
+// globalGitConfigPath returns the path to the user's global gitconfig
+func globalGitConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gitconfig"), nil
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/secrets"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameKeepAlias string
+	renameYes       bool
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a workspace",
+	Long: `Rename a workspace without breaking the repositories that use it.
+
+This command will:
+- Rename the workspace in config.yaml
+- Rewrite its managed SSH config block under the new name
+- Derive the new SSH alias (custom aliases are kept as they are)
+- Move the workspace gitconfig and pattern files to the new name
+- Move the provider token stored by 'gitws auth login'
+- Rewrite every remote under the workspace root that uses the old alias
+
+With --keep-alias the old alias stays in the SSH block as a second Host
+pattern for the given number of days, so clones outside the root and
+scripts that still use it keep working. It is dropped the next time the
+block is rewritten after the grace period (e.g. by 'gitws apply').
+
+The SSH key and the workspace root are not moved; use 'gitws move' to
+relocate the root.
+
+Examples:
+  gitws rename work acme
+  gitws rename work acme --keep-alias 30d`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+
+	renameCmd.Flags().StringVar(&renameKeepAlias, "keep-alias", "", "Keep the old SSH alias working for this many days (e.g. 30d)")
+	renameCmd.Flags().BoolVar(&renameYes, "yes", false, "Skip confirmation prompt")
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	if newName == oldName {
+		return fmt.Errorf("workspace is already named %q", oldName)
+	}
+	if err := config.ValidateWorkspaceName(newName); err != nil {
+		return err
+	}
+
+	var keepDays int
+	if renameKeepAlias != "" {
+		days, err := parseDays(renameKeepAlias)
+		if err != nil {
+			return err
+		}
+		keepDays = days
+	}
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	ws, exists := cfg.GetWorkspace(oldName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", oldName)
+	}
+	if _, exists := cfg.GetWorkspace(newName); exists {
+		return fmt.Errorf("workspace %q already exists", newName)
+	}
+
+	// Only a derived alias follows the name; a custom one was chosen on purpose
+	oldAlias := ws.SSHAlias
+	providerOrHost := ws.Provider
+	if providerOrHost == "" {
+		providerOrHost = ws.HostName
+	}
+	if oldAlias == workspace.BuildSSHAlias(providerOrHost, oldName) {
+		ws.SSHAlias = workspace.BuildSSHAlias(providerOrHost, newName)
+	}
+	aliasChanged := ws.SSHAlias != oldAlias
+	if aliasChanged && keepDays > 0 {
+		ws.RetiredAliases = append(ws.RetiredAliases, config.RetiredAlias{
+			Alias: oldAlias,
+			Until: time.Now().AddDate(0, 0, keepDays).UTC().Truncate(time.Second),
+		})
+	}
+
+	fmt.Printf(prompt.Text("Workspace '%s' → '%s'\n"), oldName, newName)
+	if aliasChanged {
+		fmt.Printf(prompt.Text("SSH alias: %s → %s\n"), oldAlias, ws.SSHAlias)
+		if keepDays > 0 {
+			fmt.Printf("%s keeps working for %d days\n", oldAlias, keepDays)
+		}
+	} else {
+		fmt.Printf("SSH alias: %s (unchanged)\n", ws.SSHAlias)
+	}
+	if !renameYes {
+		confirmed, err := prompt.Confirm("Rename workspace?")
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Rename cancelled.")
+			return nil
+		}
+	}
+
+	// Write everything under the new name before removing the old files
+	tokenCopied, err := copyToken(oldName, newName)
+	if err != nil {
+		return err
+	}
+	if err := ssh.UpsertSSHConfigBlock(newName, ws.SSHHosts(time.Now()), ws.HostName, ws.SSHKey); err != nil {
+		return fmt.Errorf("failed to update SSH config: %w", err)
+	}
+	if err := createWorkspaceGitConfig(newName, ws); err != nil {
+		return fmt.Errorf("failed to create workspace gitconfig: %w", err)
+	}
+	if err := writeWorkspacePatternFiles(newName, ws); err != nil {
+		return fmt.Errorf("failed to write workspace pattern files: %w", err)
+	}
+	if err := writeWorkspaceEnvrc(newName, ws); err != nil {
+		return fmt.Errorf("failed to update workspace .envrc: %w", err)
+	}
+	if err := writeExtraSSHBlocks(newName, ws); err != nil {
+		return err
+	}
+	if err := removeWorkspaceArtifacts(oldName); err != nil {
+		return err
+	}
+	if err := removeExtraSSHBlocks(oldName, ws); err != nil {
+		return err
+	}
+	if err := removeWorkspaceEnvrc(oldName, ws); err != nil {
+		return err
+	}
+	if oldDir, err := workspace.Dir(oldName); err == nil {
+		os.Remove(oldDir) // Only succeeds once empty
+	}
+
+	cfg.DeleteWorkspace(oldName)
+	cfg.SetWorkspace(newName, ws)
+
+	if err := updateGlobalGitConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update global gitconfig: %w", err)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := refreshGlobalGuard(cfg); err != nil {
+		return err
+	}
+	if tokenCopied {
+		if err := deleteToken(oldName); err != nil {
+			fmt.Printf(prompt.Text("⚠️  Failed to remove the token of '%s': %v\n"), oldName, err)
+		}
+	}
+
+	rewritten := 0
+	if aliasChanged {
+		rewritten, err = rewriteAliasRemotes(ws.Root, oldAlias, ws.SSHAlias)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary := prompt.SummaryData{
+		Title: i18n.T("✓ Workspace '%s' renamed to '%s'", oldName, newName),
+		Items: []prompt.SummaryItem{
+			{Label: "SSH Alias", Value: ws.SSHAlias, Icon: "🔗"},
+			{Label: "Remotes Rewritten", Value: fmt.Sprintf("%d", rewritten), Icon: "🔁"},
+			{Label: "Root", Value: ws.Root, Icon: "📁"},
+		},
+		NextSteps: []string{
+			"Test SSH connection: ssh -T " + ws.SSHAlias,
+		},
+	}
+	if defaultRoot, err := workspace.DefaultRoot(oldName); err == nil && ws.Root == defaultRoot {
+		newRoot, _ := workspace.DefaultRoot(newName)
+		summary.NextSteps = append(summary.NextSteps,
+			fmt.Sprintf("Optionally move the root: gitws move %s --root %s --move-files", newName, newRoot))
+	}
+
+	return prompt.ShowSummary(summary)
+}
+
+// copyToken stores the provider token of workspace oldName under newName
+// too, reporting whether there was one. Secrets are keyed by workspace
+// name, so the token would otherwise be lost with the old name.
+func copyToken(oldName, newName string) (bool, error) {
+	store, err := openSecrets()
+	if err != nil {
+		return false, err
+	}
+	token, err := store.Get(oldName)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read token: %w", err)
+	}
+	if err := store.Set(newName, token); err != nil {
+		return false, fmt.Errorf("failed to store token: %w", err)
+	}
+	return true, nil
+}
+
+// deleteToken removes the provider token stored for the workspace
+func deleteToken(workspaceName string) error {
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(workspaceName); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// rewriteAliasRemotes points every remote under root that uses oldAlias at
+// newAlias, returning the number of remotes changed
+func rewriteAliasRemotes(root, oldAlias, newAlias string) (int, error) {
+	repos, err := findRepositories(root)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, repo := range repos {
+		remotes, err := git.RemoteURLs(repo)
+		if err != nil {
+			fmt.Printf(prompt.Text("⚠️  %s: %v\n"), repo, err)
+			continue
+		}
+		for name, url := range remotes {
+			newURL, ok := rewrite.ReplaceHost(url, oldAlias, newAlias)
+			if !ok {
+				continue
+			}
+			if err := git.SetNamedRemoteURL(repo, name, newURL); err != nil {
+				fmt.Printf(prompt.Text("⚠️  %s: %v\n"), repo, err)
+				continue
+			}
+			if verbose {
+				fmt.Printf("  %s (%s): %s\n", repo, name, newURL)
+			}
+			rewritten++
+		}
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestCopyToken(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the encrypted file store, which is the fallback on linux only")
+	}
+	testHome(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("GWS_PASSPHRASE", "test")
+
+	if copied, err := copyToken("work", "acme"); err != nil || copied {
+		t.Fatalf("expected nothing to copy without a token, got %v, %v", copied, err)
+	}
+
+	store, err := openSecrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("work", "ghp_secret"); err != nil {
+		t.Fatal(err)
+	}
+	if copied, err := copyToken("work", "acme"); err != nil || !copied {
+		t.Fatalf("expected the token to be copied, got %v, %v", copied, err)
+	}
+	if err := deleteToken("work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if token, err := store.Get("acme"); err != nil || token != "ghp_secret" {
+		t.Errorf("expected %q, got %q (%v)", "ghp_secret", token, err)
+	}
+	if _, err := store.Get("work"); err == nil {
+		t.Error("expected the old token to be removed")
+	}
+	if err := deleteToken("work"); err != nil {
+		t.Errorf("expected deleting a missing token to succeed, got %v", err)
+	}
+}
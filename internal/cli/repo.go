@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repoCreateVisibility    string
+	repoCreateDescription   string
+	repoCreateDefaultBranch string
+	repoCreateTeam          string
+	repoCreateProvider      string
+	repoCreateNoClone       bool
+)
+
+// repoCmd groups repository commands that talk to the provider API
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage repositories on the workspace provider",
+}
+
+// repoCreateCmd represents the repo create command
+var repoCreateCmd = &cobra.Command{
+	Use:   "create <workspace> <[owner/]repo>",
+	Short: "Create a repository on the provider and clone it",
+	Long: `Create a repository on GitHub, GitLab, or Bitbucket using the workspace API token.
+
+This command will:
+- Create the repository with the requested visibility and description
+- Grant the team access (GitHub team slug, GitLab group path, Bitbucket project key)
+- Clone it into the workspace root through the workspace SSH alias
+- Point HEAD at the default branch so the first push creates it
+
+Without an owner the repository is created for the token's user.
+
+The API token is the one stored by 'gitws auth login <workspace>', so each
+workspace authenticates as its own account.
+
+Examples:
+  gitws repo create work myorg/service --visibility private --team backend
+  gitws repo create personal dotfiles --visibility public --description "My dotfiles"
+  gitws repo create work group/project --default-branch trunk --no-clone`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRepoCreate,
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoCreateCmd)
+
+	repoCreateCmd.Flags().StringVar(&repoCreateVisibility, "visibility", provider.VisibilityPrivate, "Repository visibility (private, public, internal)")
+	repoCreateCmd.Flags().StringVar(&repoCreateDescription, "description", "", "Repository description")
+	repoCreateCmd.Flags().StringVar(&repoCreateDefaultBranch, "default-branch", "", "Default branch (default: workspace default_branch)")
+	repoCreateCmd.Flags().StringVar(&repoCreateTeam, "team", "", "Team, group, or project to grant access")
+	repoCreateCmd.Flags().StringVar(&repoCreateProvider, "provider", "", "Provider API for custom hosts (github, gitlab, bitbucket)")
+	repoCreateCmd.Flags().BoolVar(&repoCreateNoClone, "no-clone", false, "Create the repository without cloning it")
+}
+
+func runRepoCreate(cmd *cobra.Command, args []string) error {
+	workspaceName := args[0]
+
+	if !provider.IsValidVisibility(repoCreateVisibility) {
+		return fmt.Errorf("invalid visibility: %s (must be private, public, or internal)", repoCreateVisibility)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found. Run 'gitws init %s' first", workspaceName, workspaceName)
+	}
+
+	kind := workspaceProviderKind(ws)
+	if repoCreateProvider != "" {
+		kind = repoCreateProvider
+	}
+	if kind == "" {
+		return fmt.Errorf("cannot tell which API %s speaks; pass --provider", ws.HostName)
+	}
+
+	token, err := workspaceToken(workspaceName)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no API token for workspace %q. Run 'gitws auth login %s' first", workspaceName, workspaceName)
+	}
+
+	client, err := provider.New(kind, ws.HostName, token)
+	if err != nil {
+		return err
+	}
+
+	opts := provider.CreateRepoOptions{
+		Name:          args[1],
+		Description:   repoCreateDescription,
+		Visibility:    repoCreateVisibility,
+		DefaultBranch: repoCreateDefaultBranch,
+		Team:          repoCreateTeam,
+	}
+	if i := strings.LastIndex(args[1], "/"); i >= 0 {
+		opts.Owner, opts.Name = args[1][:i], args[1][i+1:]
+	}
+	if opts.DefaultBranch == "" {
+		opts.DefaultBranch = ws.DefaultBranch
+	}
+
+	ctx, cancel := operationContext(cmd.Context(), opAPI)
+	created, err := client.CreateRepo(ctx, opts)
+	err = operationError(ctx, opAPI, err)
+	cancel()
+	if err != nil {
+		if created == nil {
+			return err
+		}
+		// The repository exists; report the partial failure and carry on
+		fmt.Fprintf(os.Stderr, prompt.Text("⚠️  %v\n"), err)
+	}
+
+	items := []prompt.SummaryItem{
+		{Label: "Workspace", Value: workspaceName, Icon: "📁"},
+		{Label: "Repository", Value: created.FullName, Icon: "📦"},
+		{Label: "Visibility", Value: repoCreateVisibility, Icon: "🔒"},
+		{Label: "Web URL", Value: created.WebURL, Icon: "🌐"},
+	}
+	nextSteps := []string{fmt.Sprintf("gitws clone %s %s", workspaceName, created.FullName)}
+
+	if !repoCreateNoClone {
+		_, _, remoteURL, destPath, err := cloneIntoWorkspace(cmd.Context(), workspaceName, ws, created.FullName, "", git.CloneOptions{})
+		if err != nil {
+			return withGitHint(fmt.Errorf("repository created but %w", err), workspaceName, ws)
+		}
+		if created.DefaultBranch != "" {
+			if err := git.SetHeadBranch(destPath, created.DefaultBranch); err != nil {
+				return err
+			}
+		}
+		items = append(items,
+			prompt.SummaryItem{Label: "Destination", Value: destPath, Icon: "📍"},
+			prompt.SummaryItem{Label: "Remote URL", Value: remoteURL, Icon: "🔗"},
+		)
+		nextSteps = []string{
+			fmt.Sprintf("cd %s", destPath),
+			"Create your first commit and push it",
+		}
+	}
+	if created.DefaultBranch != "" {
+		items = append(items, prompt.SummaryItem{Label: "Default Branch", Value: created.DefaultBranch, Icon: "🌿"})
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title:     "✓ Repository created successfully",
+		Items:     items,
+		NextSteps: nextSteps,
+	})
+}
+
+// workspaceProviderKind returns the provider API a workspace speaks
+func workspaceProviderKind(ws config.Workspace) string {
+	if ws.Provider != "" {
+		return ws.Provider
+	}
+	return provider.Detect(ws.HostName)
+}
+
+// workspaceClient returns a provider client with a workspace's API token
+func workspaceClient(name string, ws config.Workspace) (provider.Provider, error) {
+	kind := workspaceProviderKind(ws)
+	if kind == "" {
+		return nil, fmt.Errorf("cannot tell which API %s speaks; set provider for workspace %q", ws.HostName, name)
+	}
+	token, err := workspaceToken(name)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no API token for workspace %q. Run 'gitws auth login %s' first", name, name)
+	}
+	return provider.New(kind, ws.HostName, token)
+}
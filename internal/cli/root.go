@@ -1,16 +1,27 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	jsonOutput bool
-	verbose    bool
+	jsonOutput   bool
+	verbose      bool
+	noColor      bool
+	noEmoji      bool
+	plainOutput  bool
+	outputFormat string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -26,29 +37,185 @@ Examples:
   gitws init personal --email you@me.com --host github
   gitws clone work microsoft/vscode
   gitws status
-  gitws doctor`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+  gitws doctor
+
+On shared build hosts an administrator can provide /etc/gitws/config.yaml
+(or $GWS_SYSTEM_CONFIG) with a per-user state_dir such as
+/opt/gitws/users/{user}, a git_path, and workspace defaults. $GWS_HOME
+overrides where state is kept.
+
+--no-color drops colors (as does NO_COLOR), --no-emoji prints ASCII
+markers such as [ok] and [warn] for terminals that garble emoji, and
+--plain does both and prints plain text instead of boxes, as is done
+whenever stdout is not a terminal. --format renders summaries, doctor
+reports and tables as text, plain, json, yaml or markdown (tables,
+fenced key blocks) for pasting into tickets; --json is --format json. no_color and no_emoji in config.yaml
+make the first two the default, and theme sets the colors (title,
+success, warning, error, info, border, muted) as ANSI numbers or #rrggbb.
+
+Network operations time out after timeouts.clone (30m), fetch (10m),
+push (10m), api (30s) and ssh (20s) in config.yaml; "0" waits forever.
+Ctrl-C stops the running operation and restores the files gitws changed;
+a second Ctrl-C quits immediately.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Ensure config directory exists
 		configDir, err := config.ConfigDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+
+		// State under a shared machine-wide base must stay private
+		shared := false
+		if system, err := config.LoadSystem(); err == nil && system.StateDir != "" && os.Getenv(config.HomeEnv) == "" {
+			shared = true
+		}
+		mode := os.FileMode(0755)
+		if shared {
+			mode = 0700
+		}
+		if err := os.MkdirAll(configDir, mode); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		// Checked again now it exists, in case someone else created it first
+		if shared {
+			if err := config.CheckStateDir(configDir); err != nil {
+				return err
+			}
 		}
 
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to create config directory: %v\n", err)
-			os.Exit(1)
+		// Locate git once, honoring GWS_GIT and git_path from config.yaml,
+		// then from the system config
+		gitPath, language, backend := "", "", ""
+		if system, err := config.LoadSystem(); err == nil {
+			gitPath = system.GitPath
+		}
+		if cfg, err := config.Load(); err == nil {
+			if cfg.GitPath != "" {
+				gitPath = cfg.GitPath
+			}
+			language = cfg.Language
+			backend = cfg.GitBackend
+			noColor = noColor || cfg.NoColor
+			noEmoji = noEmoji || cfg.NoEmoji
+			prompt.SetTheme(prompt.Theme(cfg.Theme))
+		}
+		i18n.SetLanguage(i18n.Detect(language))
+		// --json is --format json; commands with their own JSON output
+		// check jsonOutput, the rest render through the json renderer
+		if jsonOutput {
+			if cmd.Flags().Changed("format") && outputFormat != prompt.FormatJSON {
+				return fmt.Errorf("--json cannot be combined with --format %s", outputFormat)
+			}
+			outputFormat = prompt.FormatJSON
+		}
+		if err := prompt.SetFormat(outputFormat); err != nil {
+			return err
+		}
+		jsonOutput = outputFormat == prompt.FormatJSON
+		// Machine-readable output (JSON, YAML) is never translated
+		if prompt.Structured() {
+			i18n.SetLanguage(i18n.Default)
 		}
+		plainOutput = plainOutput || outputFormat == prompt.FormatPlain
+		prompt.Configure(noColor, noEmoji, plainOutput)
+		if gitPath != "" {
+			path, err := workspace.ExpandPath(gitPath)
+			if err == nil {
+				git.SetBinary(path)
+			}
+		}
+		if backend != "" {
+			git.SetBackend(backend)
+		}
+		// Commands that only read get by without git, reading with go-git
+		if _, _, err := git.Locate(); err != nil && (!readOnlyCommands[commandPath(cmd)] || backend == git.BackendExec) {
+			return err
+		}
+
+		recordCommand(cmd)
+		startAuditLog()
+		fsutil.BeginJournal()
+		return nil
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute(version string) error {
 	rootCmd.Version = version
-	return rootCmd.Execute()
+	// 'gitws git' leaves Ctrl-C to git and passes on its exit status, so
+	// it is neither cancelled nor rolled back
+	if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil && cmd == gitCmd {
+		err := rootCmd.Execute()
+		fsutil.EndJournal()
+		return err
+	}
+	return executeInterruptible()
+}
+
+// Main runs gitws and exits with the status of the command; it is the
+// whole of the gitws and gws binaries
+func Main(version string) {
+	err := Execute(version)
+	if err == nil {
+		return
+	}
+	code := 1
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.Code
+	}
+	if err.Error() != "" {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(code)
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Print ASCII markers instead of emoji")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Plain text output: no colors, emoji or boxes")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", prompt.FormatText, "Output format of summaries, reports and tables: "+strings.Join(prompt.Formats(), ", "))
+	_ = rootCmd.RegisterFlagCompletionFunc("format", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return prompt.Formats(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// readOnlyCommands only read repositories, git config and history,
+// which works without git installed, as in minimal containers
+var readOnlyCommands = map[string]bool{
+	"status":           true,
+	"doctor":           true,
+	"audit":            true,
+	"check":            true,
+	"diff":             true,
+	"list":             true,
+	"keys audit":       true,
+	"keys list":        true,
+	"keys show":        true,
+	"keys fingerprint": true,
+	"audit-log show":   true,
+	"audit-log verify": true,
+}
+
+// commandPath returns the path of cmd below the root, e.g. "keys audit"
+func commandPath(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// outputFlags returns the flags that give a gitws child process the same
+// output settings as this one
+func outputFlags() []string {
+	var flags []string
+	if noColor {
+		flags = append(flags, "--no-color")
+	}
+	if noEmoji {
+		flags = append(flags, "--no-emoji")
+	}
+	if plainOutput {
+		flags = append(flags, "--plain")
+	}
+	return flags
 }
@@ -1,20 +1,43 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/gpg"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/notify"
+	"github.com/gitworkspaces/gitws/internal/progress"
 	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/provider"
 	"github.com/gitworkspaces/gitws/internal/ssh"
 	"github.com/spf13/cobra"
 )
 
+var (
+	rotateAll        bool
+	rotateOlderThan  string
+	rotateNoProvider bool
+	rotateGPG        bool
+	rotateExtend     bool
+	rotateExpire     string
+	rotateKeyserver  string
+	rotateCopy       bool
+	rotateQR         bool
+)
+
 // rotateCmd represents the rotate command
 var rotateCmd = &cobra.Command{
-	Use:   "rotate <workspace>",
-	Short: "Rotate SSH keys for a workspace",
+	Use:   "rotate [workspace]",
+	Short: "Rotate SSH or GPG signing keys for a workspace",
 	Long: `Generate new SSH keys for a workspace and update configuration.
 
 This command will:
@@ -23,30 +46,95 @@ This command will:
 - Update SSH configuration
 - Display the new public key
 
+The pre-flight checks of 'gitws init' run first, so a missing ssh-keygen
+or a read-only ~/.ssh is reported before any key is moved.
+
+When the workspace has an API token ('gitws auth login'), the new key is
+also registered with the provider and, once it authenticates, the old key
+is found by fingerprint and removed from the account.
+
+With --all, every workspace whose key is due is rotated: keys older than
+--older-than, or without it, older than the workspace's max_key_age.
+
+--gpg rotates the GPG signing key of a workspace with signing: gpg
+instead. The secret key is first exported, still protected by its
+passphrase, to the workspace's backup directory (backup_dir, or
+~/.gws/backups/<workspace>/gpg). Then a new signing subkey valid for
+--expire is added and the workspace signs with it; --extend only pushes
+back the expiry of the current key. The updated public key replaces the
+old one on the provider when there is an API token, and is sent to
+--keyserver when given. With --all, every gpg workspace whose signing key
+expires within 30 days is rotated.
+
+--copy puts the new public key on the clipboard and --qr draws it as a
+QR code, to add it from a phone or a server console. --format markdown
+prints the summary as Markdown, to paste into a ticket.
+
 Examples:
   gitws rotate work
-  gitws rotate personal`,
-	Args: cobra.ExactArgs(1),
+  gitws rotate personal
+  gitws rotate work --qr
+  gitws rotate --all
+  gitws rotate --all --older-than 90d
+  gitws rotate work --gpg
+  gitws rotate work --gpg --extend --expire 2y
+  gitws rotate --all --gpg --keyserver hkps://keys.openpgp.org`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runRotate,
 }
 
 func init() {
 	rootCmd.AddCommand(rotateCmd)
+
+	rotateCmd.Flags().BoolVar(&rotateAll, "all", false, "Rotate every workspace whose key is due")
+	rotateCmd.Flags().StringVar(&rotateOlderThan, "older-than", "", "With --all, rotate keys older than this (e.g. 90d) instead of each max_key_age")
+	rotateCmd.Flags().BoolVar(&rotateNoProvider, "no-provider", false, "Don't register or remove keys through the provider API")
+	rotateCmd.Flags().BoolVar(&rotateGPG, "gpg", false, "Rotate the GPG signing key instead of the SSH key")
+	rotateCmd.Flags().BoolVar(&rotateExtend, "extend", false, "With --gpg, extend the expiry of the current signing key instead of adding a subkey")
+	rotateCmd.Flags().StringVar(&rotateExpire, "expire", "1y", "With --gpg, how long the new or extended signing key is valid (gpg syntax, e.g. 1y, 6m)")
+	rotateCmd.Flags().StringVar(&rotateKeyserver, "keyserver", "", "With --gpg, also send the public key to this keyserver")
+	rotateCmd.Flags().BoolVar(&rotateCopy, "copy", false, "Copy the new public key to the clipboard")
+	rotateCmd.Flags().BoolVar(&rotateQR, "qr", false, "Also show the new public key as a QR code, to scan from a phone")
 }
 
 func runRotate(cmd *cobra.Command, args []string) error {
+	if rotateAll && (rotateCopy || rotateQR) {
+		return fmt.Errorf("--copy and --qr show a single public key and cannot be combined with --all")
+	}
+	if rotateGPG {
+		return runRotateGPG(cmd.Context(), args)
+	}
+	if rotateExtend || rotateKeyserver != "" || cmd.Flags().Changed("expire") {
+		return fmt.Errorf("--extend, --expire and --keyserver require --gpg")
+	}
+	if rotateAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a workspace name")
+		}
+		return runRotateAll(cmd.Context())
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("specify a workspace, or --all to rotate every key that is due")
+	}
+	if rotateOlderThan != "" {
+		return fmt.Errorf("--older-than requires --all")
+	}
 	workspaceName := args[0]
 
 	// Load workspace config
-	cfg, err := config.Load()
+	cfg, unlock, err := config.LoadLocked()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	defer unlock()
 
 	ws, exists := cfg.GetWorkspace(workspaceName)
 	if !exists {
 		return fmt.Errorf("workspace %q not found", workspaceName)
 	}
+	if err := runPreflight(cmd.Context(), preflightChecks(cfg, true)); err != nil {
+		return err
+	}
 
 	// Confirm rotation
 	confirmed, err := prompt.Confirm(fmt.Sprintf("Rotate SSH keys for workspace '%s'? This will generate new keys and backup the old ones.", workspaceName))
@@ -58,25 +146,13 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Backup existing key
-	if err := backupExistingKey(ws.SSHKey); err != nil {
-		return fmt.Errorf("failed to backup existing key: %w", err)
-	}
+	oldFingerprint := keyFingerprint(ws.SSHKey + ".pub")
 
-	// Generate new key
-	privPath, pubPath, _, err := ssh.EnsureKey(workspaceName, ws.Email)
+	privPath, pubPath, err := rotateWorkspaceKey(cfg, workspaceName)
 	if err != nil {
-		return fmt.Errorf("failed to generate new key: %w", err)
-	}
-
-	// Update SSH config with new key
-	if err := ssh.UpsertSSHConfigBlock(workspaceName, ws.SSHAlias, ws.HostName, privPath); err != nil {
-		return fmt.Errorf("failed to update SSH config: %w", err)
+		return err
 	}
 
-	// Update workspace config
-	ws.SSHKey = privPath
-	cfg.SetWorkspace(workspaceName, ws)
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
@@ -87,26 +163,302 @@ func runRotate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read new public key: %w", err)
 	}
 
+	providerItems, providerDone := updateProviderKeys(cmd.Context(), workspaceName, ws, oldFingerprint, publicKey)
+
 	// Show summary
 	summary := prompt.SummaryData{
-		Title: fmt.Sprintf("✓ SSH keys rotated for workspace '%s'", workspaceName),
+		Title: i18n.T("✓ SSH keys rotated for workspace '%s'", workspaceName),
 		Items: []prompt.SummaryItem{
 			{Label: "New Private Key", Value: privPath, Icon: "🔑"},
 			{Label: "New Public Key", Value: pubPath, Icon: "🔓"},
 			{Label: "SSH Alias", Value: ws.SSHAlias, Icon: "🔗"},
 			{Label: "Host", Value: ws.HostName, Icon: "🌐"},
 		},
-		PublicKey: publicKey,
+		PublicKey:     publicKey,
+		QR:            rotateQR,
+		CopyPublicKey: rotateCopy,
 		NextSteps: []string{
 			fmt.Sprintf("Add the new public key to your %s account", ws.HostName),
 			"Remove the old public key from your account",
 			"Test SSH connection: ssh -T " + ws.SSHAlias,
 		},
 	}
+	summary.Items = append(summary.Items, providerItems...)
+	if providerDone {
+		summary.NextSteps = []string{"Nothing left to do: the provider already has the new key"}
+	}
 
 	return prompt.ShowSummary(summary)
 }
 
+// runRotateAll rotates the key of every workspace that is due
+func runRotateAll(ctx context.Context) error {
+	olderThan := 0
+	if rotateOlderThan != "" {
+		days, err := parseDays(rotateOlderThan)
+		if err != nil {
+			return err
+		}
+		olderThan = days
+	}
+
+	cfg, unlock, err := config.LoadLocked()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	names := cfg.ListWorkspaces()
+	sort.Strings(names)
+
+	var due []string
+	for _, name := range names {
+		ws := cfg.Workspaces[name]
+		age, limit, isDue := keyRotationDue(ws, olderThan, time.Now())
+		if !isDue {
+			continue
+		}
+		if ws.KeySource != nil && ws.KeySource.Mode == keyModeReference {
+			fmt.Printf(prompt.Text("⚠️  %s: key is %d days old but is referenced from %s; get a new key from its issuer\n"), name, age, ws.KeySource.Path)
+			continue
+		}
+		fmt.Printf(prompt.Text("• %s: key is %d days old (limit %d days)\n"), name, age, limit)
+		due = append(due, name)
+	}
+
+	if len(due) == 0 {
+		fmt.Println(prompt.Text("✓ No keys are due for rotation."))
+		return nil
+	}
+	if err := runPreflight(ctx, preflightChecks(cfg, true)); err != nil {
+		return err
+	}
+
+	confirmed, err := prompt.Confirm(fmt.Sprintf("Rotate SSH keys for %d workspace(s)? The old keys will be backed up.", len(due)))
+	if err != nil {
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	if !confirmed {
+		fmt.Println("Key rotation cancelled.")
+		return nil
+	}
+
+	var items []prompt.SummaryItem
+	var nextSteps []string
+	bar := progress.New("Rotating keys", len(due))
+	defer bar.Stop()
+	for _, name := range due {
+		ws := cfg.Workspaces[name]
+		oldFingerprint := keyFingerprint(ws.SSHKey + ".pub")
+
+		task := bar.Start(name)
+		privPath, pubPath, err := rotateWorkspaceKey(cfg, name)
+		if err != nil {
+			// Keep the rotations that already happened
+			if saveErr := cfg.Save(); saveErr != nil {
+				return fmt.Errorf("failed to save config: %w", saveErr)
+			}
+			return fmt.Errorf("failed to rotate %q: %w", name, err)
+		}
+		items = append(items, prompt.SummaryItem{Label: name, Value: privPath, Icon: "🔑"})
+
+		publicKey, err := ssh.GetPublicKey(pubPath)
+		if err != nil {
+			return fmt.Errorf("failed to read new public key: %w", err)
+		}
+		providerItems, providerDone := updateProviderKeys(ctx, name, ws, oldFingerprint, publicKey)
+		items = append(items, providerItems...)
+		if !providerDone {
+			nextSteps = append(nextSteps, fmt.Sprintf("Replace the %s key on %s with %s", name, ws.HostName, pubPath))
+		}
+		task.Done()
+	}
+	bar.Stop()
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title:     i18n.T("✓ SSH keys rotated for %d workspace(s)", len(due)),
+		Items:     items,
+		NextSteps: append(nextSteps, "Run 'gitws doctor' to test the new keys"),
+	})
+}
+
+// keyFingerprint returns the fingerprint of the public key at pubPath, or
+// "" when it cannot be read
+func keyFingerprint(pubPath string) string {
+	publicKey, err := ssh.GetPublicKey(pubPath)
+	if err != nil {
+		return ""
+	}
+	fingerprint, err := ssh.Fingerprint(publicKey)
+	if err != nil {
+		return ""
+	}
+	return fingerprint
+}
+
+// updateProviderKeys registers a rotated workspace's new key with the
+// provider and, once the new key authenticates, removes the old key by
+// fingerprint. It returns summary items describing what it did, and
+// whether nothing is left for the user to do on the provider. Without an
+// API token it does nothing.
+func updateProviderKeys(ctx context.Context, workspaceName string, ws config.Workspace, oldFingerprint, publicKey string) (items []prompt.SummaryItem, complete bool) {
+	kind := workspaceProviderKind(ws)
+	if rotateNoProvider || kind == "" {
+		return nil, false
+	}
+	token, err := workspaceToken(workspaceName)
+	if err != nil || token == "" {
+		return nil, false
+	}
+
+	client, err := provider.New(kind, ws.HostName, token)
+	if err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+		return nil, false
+	}
+
+	title := fmt.Sprintf("gitws %s (%s)", workspaceName, time.Now().Format("2006-01-02"))
+	apiCtx, cancel := operationContext(ctx, opAPI)
+	_, err = client.AddSSHKey(apiCtx, title, publicKey)
+	err = operationError(apiCtx, opAPI, err)
+	cancel()
+	if err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: could not register the new key with %s: %v\n"), workspaceName, ws.HostName, err)
+		return nil, false
+	}
+	items = append(items, prompt.SummaryItem{Label: "Provider Key Added", Value: title, Icon: "➕"})
+
+	// Only drop the old key once the new one is known to work
+	sshCtx, cancel := operationContext(ctx, opSSH)
+	err = ssh.TestSSHConnectionContext(sshCtx, ws.SSHAlias)
+	cancel()
+	if err != nil {
+		items = append(items, prompt.SummaryItem{Label: "Old Provider Key", Value: "kept: the new key did not authenticate", Icon: "⚠️"})
+		return items, false
+	}
+	if oldFingerprint == "" {
+		items = append(items, prompt.SummaryItem{Label: "Old Provider Key", Value: "kept: the old public key could not be read", Icon: "⚠️"})
+		return items, false
+	}
+
+	apiCtx, cancel = operationContext(ctx, opAPI)
+	keys, err := client.ListSSHKeys(apiCtx)
+	err = operationError(apiCtx, opAPI, err)
+	cancel()
+	if err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+		return items, false
+	}
+	for _, key := range keys {
+		if fingerprint, err := ssh.Fingerprint(key.Key); err != nil || fingerprint != oldFingerprint {
+			continue
+		}
+		apiCtx, cancel := operationContext(ctx, opAPI)
+		err := operationError(apiCtx, opAPI, client.DeleteSSHKey(apiCtx, key.ID))
+		cancel()
+		if err != nil {
+			progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+			return items, false
+		}
+		items = append(items, prompt.SummaryItem{Label: "Provider Key Removed", Value: fmt.Sprintf("%s (%s)", key.Title, oldFingerprint), Icon: "➖"})
+		return items, true
+	}
+
+	items = append(items, prompt.SummaryItem{Label: "Old Provider Key", Value: "not registered with " + ws.HostName, Icon: "ℹ️"})
+	return items, true
+}
+
+// keyAgeDays returns the age in days of a workspace's SSH key, from
+// key_created_at or, for keys created before it was recorded, the key
+// file's modification time. ok is false when neither is available.
+func keyAgeDays(ws config.Workspace, now time.Time) (days int, ok bool) {
+	created, ok := keyCreated(ws)
+	if !ok {
+		return 0, false
+	}
+	return int(now.Sub(created).Hours() / 24), true
+}
+
+// keyCreated returns when a workspace's SSH key was created: its recorded
+// creation time, or else when the key file was last written
+func keyCreated(ws config.Workspace) (time.Time, bool) {
+	if !ws.KeyCreatedAt.IsZero() {
+		return ws.KeyCreatedAt, true
+	}
+	info, err := os.Stat(ws.SSHKey)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// keyRotationDue reports whether a workspace's key is older than limit
+// days, or than its max_key_age when limit is 0
+func keyRotationDue(ws config.Workspace, limit int, now time.Time) (age, maxAge int, due bool) {
+	if limit == 0 {
+		if ws.MaxKeyAge == "" {
+			return 0, 0, false
+		}
+		days, err := parseDays(ws.MaxKeyAge)
+		if err != nil {
+			return 0, 0, false
+		}
+		limit = days
+	}
+
+	age, ok := keyAgeDays(ws, now)
+	if !ok {
+		return 0, limit, false
+	}
+	return age, limit, age > limit
+}
+
+// rotateWorkspaceKey moves the workspace's current key aside, generates a
+// new one, and points the SSH config and workspace entry at it. The caller
+// is responsible for saving cfg.
+func rotateWorkspaceKey(cfg *config.File, workspaceName string) (privPath, pubPath string, err error) {
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return "", "", fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	// A referenced key belongs to whoever provisioned it
+	if ws.KeySource != nil && ws.KeySource.Mode == keyModeReference {
+		return "", "", fmt.Errorf("the key for workspace %q is referenced from %s; get a new key from its issuer and run 'gitws init %s --force --key-file <path>'", workspaceName, ws.KeySource.Path, workspaceName)
+	}
+
+	// Backup existing key
+	if err := backupExistingKey(ws.SSHKey); err != nil {
+		return "", "", fmt.Errorf("failed to backup existing key: %w", err)
+	}
+
+	// Generate new key
+	privPath, pubPath, _, err = ssh.EnsureKey(workspaceName, ws.Email)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate new key: %w", err)
+	}
+
+	// Update SSH config with new key
+	if err := ssh.UpsertSSHConfigBlock(workspaceName, ws.SSHHosts(time.Now()), ws.HostName, privPath); err != nil {
+		return "", "", fmt.Errorf("failed to update SSH config: %w", err)
+	}
+
+	// Update workspace config
+	ws.SSHKey = privPath
+	ws.KeyCreatedAt = time.Now().UTC().Truncate(time.Second)
+	cfg.SetWorkspace(workspaceName, ws)
+
+	notifyEvent(cfg, notify.EventKeyRotated, workspaceName, fmt.Sprintf("SSH key for %s rotated; the old key was moved aside", ws.HostName))
+
+	return privPath, pubPath, nil
+}
+
+// backupExistingKey moves the key pair aside with a timestamp suffix so a
+// fresh key can be generated at the same path
 func backupExistingKey(keyPath string) error {
 	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
 		return nil // No existing key to backup
@@ -116,37 +468,311 @@ func backupExistingKey(keyPath string) error {
 	timestamp := time.Now().Format("20060102150405")
 	backupPath := keyPath + ".old-" + timestamp
 
-	// Copy private key
-	if err := copyFile(keyPath, backupPath); err != nil {
-		return fmt.Errorf("failed to backup private key: %w", err)
-	}
-
-	// Copy public key if it exists
 	pubPath := keyPath + ".pub"
-	if _, err := os.Stat(pubPath); err == nil {
-		backupPubPath := pubPath + ".old-" + timestamp
-		if err := copyFile(pubPath, backupPubPath); err != nil {
-			return fmt.Errorf("failed to backup public key: %w", err)
+	backupPubPath := pubPath + ".old-" + timestamp
+
+	// Recorded so an interrupted rotation puts the old key back
+	err := fsutil.RecordChange(func() error {
+		// Move private key
+		if err := os.Rename(keyPath, backupPath); err != nil {
+			return fmt.Errorf("failed to backup private key: %w", err)
 		}
+
+		// Move public key if it exists
+		if _, err := os.Stat(pubPath); err == nil {
+			if err := os.Rename(pubPath, backupPubPath); err != nil {
+				return fmt.Errorf("failed to backup public key: %w", err)
+			}
+		}
+		return nil
+	}, keyPath, pubPath, backupPath, backupPubPath)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("✓ Backed up existing keys with timestamp: %s\n", timestamp)
+	progress.Printf(prompt.Text("✓ Backed up existing keys with timestamp: %s\n"), timestamp)
 	return nil
 }
 
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
+// runRotateGPG rotates the GPG signing key of the named workspace, or
+// with --all of every gpg workspace whose key expires soon
+func runRotateGPG(ctx context.Context, args []string) error {
+	if rotateOlderThan != "" {
+		return fmt.Errorf("--older-than applies to SSH keys; with --gpg, --all rotates keys that expire within %d days", int(gpgExpiryWarning.Hours()/24))
+	}
+
+	cfg, unlock, err := config.LoadLocked()
 	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer unlock()
+
+	program := gpgProgram("")
+	if err := gpg.Installed(program); err != nil {
 		return err
 	}
-	defer srcFile.Close()
 
-	dstFile, err := os.Create(dst)
+	var due []string
+	if rotateAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a workspace name")
+		}
+		names := cfg.ListWorkspaces()
+		sort.Strings(names)
+		now := time.Now()
+		for _, name := range names {
+			ws := cfg.Workspaces[name]
+			if ws.Signing != "gpg" {
+				continue
+			}
+			keys, err := gpg.ListSecretKeys(ctx, program, ws.GPGKey)
+			if err != nil || len(keys) == 0 {
+				fmt.Printf(prompt.Text("⚠️  %s: no gpg secret key for %s\n"), name, ws.GPGKey)
+				continue
+			}
+			expires, ok := keys[0].SigningExpiry()
+			if !ok || expires.IsZero() || expires.Sub(now) > gpgExpiryWarning {
+				continue
+			}
+			fmt.Printf(prompt.Text("• %s: signing key %s expires on %s\n"), name, ws.GPGKey, expires.Format("2006-01-02"))
+			due = append(due, name)
+		}
+		if len(due) == 0 {
+			fmt.Println(prompt.Text("✓ No GPG signing keys expire within 30 days."))
+			return nil
+		}
+	} else {
+		if len(args) == 0 {
+			return fmt.Errorf("specify a workspace, or --all to rotate every GPG key that expires soon")
+		}
+		ws, exists := cfg.GetWorkspace(args[0])
+		if !exists {
+			return fmt.Errorf("workspace %q not found", args[0])
+		}
+		if ws.Signing != "gpg" {
+			return fmt.Errorf("workspace %q does not sign with gpg", args[0])
+		}
+		due = []string{args[0]}
+	}
+
+	action := "Add a new signing subkey"
+	if rotateExtend {
+		action = "Extend the signing key"
+	}
+	confirmed, err := prompt.Confirm(fmt.Sprintf("%s for %s? The secret key is backed up first.", action, strings.Join(due, ", ")))
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	if !confirmed {
+		fmt.Println("Key rotation cancelled.")
+		return nil
+	}
+
+	var items []prompt.SummaryItem
+	var nextSteps []string
+	for _, name := range due {
+		rotated, steps, err := rotateGPGKey(ctx, cfg, name, program)
+		if err != nil {
+			// Keep the rotations that already happened
+			if saveErr := cfg.Save(); saveErr != nil {
+				return fmt.Errorf("failed to save config: %w", saveErr)
+			}
+			return fmt.Errorf("failed to rotate the GPG key of %q: %w", name, err)
+		}
+		items = append(items, rotated...)
+		nextSteps = append(nextSteps, steps...)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
-	defer dstFile.Close()
 
-	_, err = dstFile.ReadFrom(srcFile)
-	return err
+	summary := prompt.SummaryData{
+		Title:         i18n.T("✓ GPG signing key rotated for %s", strings.Join(due, ", ")),
+		Items:         items,
+		NextSteps:     append(nextSteps, "Run 'gitws doctor' in a repository to test signing"),
+		QR:            rotateQR,
+		CopyPublicKey: rotateCopy,
+	}
+	if len(due) == 1 && (rotateQR || rotateCopy) {
+		keyID := strings.TrimSuffix(cfg.Workspaces[due[0]].GPGKey, "!")
+		public, err := gpg.ExportPublicKey(ctx, program, keyID)
+		if err != nil {
+			return err
+		}
+		summary.PublicKey = public
+	}
+	return prompt.ShowSummary(summary)
+}
+
+// rotateGPGKey backs up a workspace's GPG key, then adds a signing subkey
+// and signs with it or, with --extend, pushes back its expiry, and
+// publishes the public key. The caller is responsible for saving cfg.
+func rotateGPGKey(ctx context.Context, cfg *config.File, workspaceName, program string) (items []prompt.SummaryItem, nextSteps []string, err error) {
+	ws := cfg.Workspaces[workspaceName]
+	keys, err := gpg.ListSecretKeys(ctx, program, ws.GPGKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("no gpg secret key for %s", ws.GPGKey)
+	}
+	key := keys[0]
+
+	backup, err := backupGPGKey(ctx, program, workspaceName, ws, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	items = append(items, prompt.SummaryItem{Label: "Old Key Backup", Value: backup, Icon: "💾"})
+
+	if rotateExtend {
+		var subkeys []string
+		for _, s := range key.Signers {
+			if !s.Revoked && s.ID != key.ID && s.Fingerprint != "" {
+				subkeys = append(subkeys, s.Fingerprint)
+			}
+		}
+		if !key.Expires.IsZero() {
+			if err := gpg.SetExpiry(ctx, program, key.Fingerprint, rotateExpire); err != nil {
+				return items, nil, err
+			}
+		}
+		if len(subkeys) > 0 {
+			if err := gpg.SetExpiry(ctx, program, key.Fingerprint, rotateExpire, subkeys...); err != nil {
+				return items, nil, err
+			}
+		}
+		items = append(items, prompt.SummaryItem{Label: "Signing Key", Value: fmt.Sprintf("%s (extended by %s)", ws.GPGKey, rotateExpire), Icon: "🔑"})
+	} else {
+		signer, err := gpg.AddSigningSubkey(ctx, program, key.Fingerprint, rotateExpire)
+		if err != nil {
+			return items, nil, err
+		}
+
+		// The ! makes gpg sign with exactly this subkey
+		ws.GPGKey = signer.ID + "!"
+		cfg.SetWorkspace(workspaceName, ws)
+		if err := createWorkspaceGitConfig(workspaceName, ws); err != nil {
+			return items, nil, err
+		}
+		expires := "never"
+		if !signer.Expires.IsZero() {
+			expires = signer.Expires.Format("2006-01-02")
+		}
+		items = append(items, prompt.SummaryItem{Label: "New Signing Subkey", Value: fmt.Sprintf("%s (expires %s)", signer.ID, expires), Icon: "🔑"})
+	}
+	notifyEvent(cfg, notify.EventKeyRotated, workspaceName, fmt.Sprintf("GPG signing key for %s rotated; the old key material was backed up", ws.HostName))
+
+	public, err := gpg.ExportPublicKey(ctx, program, key.Fingerprint)
+	if err != nil {
+		return items, nil, err
+	}
+	if rotateKeyserver != "" {
+		if err := gpg.SendKey(ctx, program, rotateKeyserver, key.Fingerprint); err != nil {
+			progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+			nextSteps = append(nextSteps, fmt.Sprintf("Send the key again: gpg --keyserver %s --send-keys %s", rotateKeyserver, key.Fingerprint))
+		} else {
+			items = append(items, prompt.SummaryItem{Label: "Keyserver", Value: rotateKeyserver, Icon: "🌐"})
+		}
+	}
+
+	providerItems, providerDone := updateProviderGPGKey(ctx, workspaceName, ws, program, key.ID, public)
+	items = append(items, providerItems...)
+	if !providerDone {
+		nextSteps = append(nextSteps, fmt.Sprintf("Replace the GPG key on %s with the output of: gpg --armor --export %s", ws.HostName, key.Fingerprint))
+	}
+	return items, nextSteps, nil
+}
+
+// backupGPGKey writes the secret key material of key, still protected by
+// its passphrase, to the workspace's backup directory
+func backupGPGKey(ctx context.Context, program, workspaceName string, ws config.Workspace, key gpg.Key) (string, error) {
+	secret, err := gpg.ExportSecretKey(ctx, program, key.Fingerprint)
+	if err != nil {
+		return "", err
+	}
+	dir, err := backupDir(workspaceName, ws)
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "gpg")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.asc", key.Fingerprint, time.Now().Format("20060102150405")))
+	if err := fsutil.AtomicWrite(path, secret, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up GPG key: %w", err)
+	}
+	return path, nil
+}
+
+// updateProviderGPGKey replaces the GPG key with primary key ID keyID on
+// the provider with public, since providers take a key only once. It
+// returns summary items and whether nothing is left for the user to do.
+// Without an API token it does nothing.
+func updateProviderGPGKey(ctx context.Context, workspaceName string, ws config.Workspace, program, keyID, public string) (items []prompt.SummaryItem, complete bool) {
+	kind := workspaceProviderKind(ws)
+	if rotateNoProvider || kind == "" {
+		return nil, false
+	}
+	token, err := workspaceToken(workspaceName)
+	if err != nil || token == "" {
+		return nil, false
+	}
+	client, err := provider.New(kind, ws.HostName, token)
+	if err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+		return nil, false
+	}
+
+	keys, err := callAPI(ctx, client.ListGPGKeys)
+	if errors.Is(err, provider.ErrUnsupported) {
+		return []prompt.SummaryItem{{Label: "Provider Key", Value: "add it in your " + ws.HostName + " settings", Icon: "ℹ️"}}, false
+	}
+	if err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+		return nil, false
+	}
+
+	for _, k := range keys {
+		if !providerGPGKeyMatches(ctx, program, k, keyID) {
+			continue
+		}
+		apiCtx, cancel := operationContext(ctx, opAPI)
+		err := operationError(apiCtx, opAPI, client.DeleteGPGKey(apiCtx, k.ID))
+		cancel()
+		if err != nil {
+			progress.Printf(prompt.Text("⚠️  %s: %v\n"), workspaceName, err)
+			return items, false
+		}
+		items = append(items, prompt.SummaryItem{Label: "Provider Key Removed", Value: keyID, Icon: "➖"})
+	}
+
+	if _, err := callAPI(ctx, func(ctx context.Context) (*provider.GPGKey, error) { return client.AddGPGKey(ctx, public) }); err != nil {
+		progress.Printf(prompt.Text("⚠️  %s: could not register the GPG key with %s: %v\n"), workspaceName, ws.HostName, err)
+		return items, false
+	}
+	return append(items, prompt.SummaryItem{Label: "Provider Key Added", Value: keyID, Icon: "➕"}), true
+}
+
+// providerGPGKeyMatches reports whether a key registered with the
+// provider has the primary key ID keyID, reading the armored key when
+// the provider does not report its ID
+func providerGPGKeyMatches(ctx context.Context, program string, k provider.GPGKey, keyID string) bool {
+	if k.KeyID != "" {
+		return strings.EqualFold(k.KeyID, keyID)
+	}
+	if k.Key == "" {
+		return false
+	}
+	ids, err := gpg.KeyIDs(ctx, program, k.Key)
+	if err != nil {
+		return false
+	}
+	for _, id := range ids {
+		if strings.EqualFold(id, keyID) {
+			return true
+		}
+	}
+	return false
 }
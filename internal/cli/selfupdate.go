@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/update"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selfUpdateCheck bool
+)
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update gitws to the latest release",
+	Long: `Update gitws to the latest GitHub release.
+
+This command will:
+- Check the latest release against the running version
+- Download the archive for this platform
+- Check the signature of the release checksums against the gitws
+  release key built into this binary (minisign)
+- Verify the archive against those SHA-256 checksums
+- Replace the running binary
+
+Installs managed by Homebrew, Scoop, Nix, Snap or 'go install' are not
+overwritten; the command prints how to upgrade with that tool instead.
+
+Examples:
+  gitws self-update --check
+  gitws self-update`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only report whether an update is available")
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	current := rootCmd.Version
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the gitws binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	client := update.NewClient()
+	release, err := client.Latest()
+	if err != nil {
+		return err
+	}
+
+	if current != "dev" && !update.Newer(release.Version(), current) {
+		fmt.Printf(prompt.Text("✓ gitws %s is up to date\n"), current)
+		return nil
+	}
+
+	if current == "dev" {
+		fmt.Printf(prompt.Text("ℹ️  This is a development build; the latest release is %s\n"), release.Version())
+	} else {
+		fmt.Printf(prompt.Text("ℹ️  gitws %s is available (installed: %s)\n"), release.Version(), current)
+	}
+	if release.HTMLURL != "" {
+		fmt.Printf("   Release notes: %s\n", release.HTMLURL)
+	}
+
+	manager, upgrade, managed := update.Manager(exe)
+	if managed {
+		fmt.Printf("   gitws was installed with %s; upgrade it with: %s\n", manager, upgrade)
+		return nil
+	}
+	if selfUpdateCheck {
+		fmt.Println("   Run 'gitws self-update' to install it")
+		return nil
+	}
+
+	confirmed, err := prompt.Confirm(fmt.Sprintf("Replace %s with gitws %s?", exe, release.Version()))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Update cancelled.")
+		return nil
+	}
+
+	binary, err := client.FetchBinary(release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	fmt.Println(prompt.Text("✓ Verified release signature and checksum"))
+
+	if err := update.Replace(exe, binary); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("no permission to replace %s; re-run with the rights of its owner: %w", exe, err)
+		}
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+
+	fmt.Printf(prompt.Text("✓ Updated gitws to %s\n"), release.Version())
+	return nil
+}
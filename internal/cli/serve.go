@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/rpc"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// serveErrNotRepo is the JSON-RPC error code for a path outside any
+// repository, the same number as the exit code
+const serveErrNotRepo = ExitNotRepo
+
+var (
+	serveSocket string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Answer identity queries from editor extensions over a socket",
+	Long: `Listen on a Unix socket and answer JSON-RPC 2.0 requests, so editor
+extensions can ask which workspace and identity a file belongs to without
+starting gitws for every query. config.yaml is parsed once and read again
+only when it changes.
+
+Requests and responses are JSON objects, one per line. Paths must be
+absolute. Methods:
+
+  workspace.resolve {"path"}    workspace owning the path, and its repository
+  whoami {"path"}               identity the repository commits with, and
+                                whether it matches its workspace
+  doctor {"path", "offline"}    the issues 'gitws doctor --json' reports
+  fix {"path", "actions",       runs 'gitws fix --yes' with the actions
+       "workspace", "identity"}  (rewrite-remote, set-identity, enable-guards)
+  version {}                    gitws version and the methods served
+
+A path outside any repository gets error code 3, like the exit code.
+The socket is only accessible to its owner. Stop the server with Ctrl-C.
+
+Examples:
+  gitws serve
+  gitws serve --socket /run/user/1000/gitws.sock
+  echo '{"jsonrpc":"2.0","id":1,"method":"whoami","params":{"path":"'$PWD'"}}' | nc -U ~/.gws/gitws.sock`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Socket path (default: gitws.sock in the gitws directory)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	socketPath := serveSocket
+	if socketPath == "" {
+		dir, err := config.ConfigDir()
+		if err != nil {
+			return err
+		}
+		socketPath = filepath.Join(dir, "gitws.sock")
+	}
+	socketPath, err := workspace.ExpandPath(socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand socket path: %w", err)
+	}
+
+	listener, err := listenSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	config.CacheLoads()
+	server := rpc.NewServer()
+	server.Register("workspace.resolve", serveResolve)
+	server.Register("whoami", serveWhoami)
+	server.Register("doctor", serveDoctor)
+	server.Register("fix", serveFix)
+	server.Register("version", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return map[string]any{"version": rootCmd.Version, "methods": server.Methods()}, nil
+	})
+
+	fmt.Fprintf(os.Stderr, prompt.Text("🔌 Listening on %s (Ctrl-C to stop)\n"), socketPath)
+	return server.Serve(cmd.Context(), listener)
+}
+
+// listenSocket listens on a Unix socket only its owner may use. A socket
+// left behind by a server that is gone is replaced; one a running server
+// answers on is not.
+func listenSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another gitws serve is listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// The socket is created with the umask applied, so restrict it before
+	// listening; a chmod afterwards leaves a window where others connect
+	var listener net.Listener
+	err := fsutil.WithPrivateUmask(func() (err error) {
+		listener, err = net.Listen("unix", path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// servePathParams are the parameters of workspace.resolve and whoami
+type servePathParams struct {
+	Path string `json:"path"`
+}
+
+// decodeParams decodes the parameters of a request into v
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return rpc.InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// requestPath checks the path of a request and cleans it. The server's
+// working directory means nothing to the client, so it must be absolute.
+func requestPath(path string) (string, error) {
+	if path == "" {
+		return "", rpc.InvalidParams("path is required")
+	}
+	expanded, err := workspace.ExpandPath(path)
+	if err != nil || !filepath.IsAbs(expanded) {
+		return "", rpc.InvalidParams("path must be absolute: %s", path)
+	}
+	return filepath.Clean(expanded), nil
+}
+
+// serveRepo returns the repository holding path
+func serveRepo(path string) (string, error) {
+	gitRoot, err := git.FindGitRoot(path)
+	if err != nil {
+		return "", &rpc.Error{Code: serveErrNotRepo, Message: fmt.Sprintf("not in a git repository: %s", path)}
+	}
+	return gitRoot, nil
+}
+
+// serveConfig loads config.yaml, turning a broken one into an error the
+// client can show
+func serveConfig() (*config.File, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// resolveResult answers workspace.resolve
+type resolveResult struct {
+	Workspace  string `json:"workspace"`
+	Root       string `json:"root,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Unmanaged  bool   `json:"unmanaged,omitempty"`
+}
+
+func serveResolve(ctx context.Context, params json.RawMessage) (any, error) {
+	var p servePathParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	path, err := requestPath(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := serveConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	result := resolveResult{Workspace: workspaceForDir(cfg, path)}
+	if ws, exists := cfg.GetWorkspace(result.Workspace); exists {
+		result.Root = ws.Root
+	}
+	if gitRoot, err := git.FindGitRoot(path); err == nil {
+		result.Repository = gitRoot
+		if repo, err := config.LoadRepo(gitRoot); err == nil && repo.Unmanaged {
+			result.Unmanaged = true
+		}
+	}
+	return result, nil
+}
+
+// whoamiResult answers whoami
+type whoamiResult struct {
+	Repository    string `json:"repository"`
+	Workspace     string `json:"workspace"`
+	Unmanaged     bool   `json:"unmanaged,omitempty"`
+	Remote        string `json:"remote"`
+	SSHAlias      string `json:"ssh_alias,omitempty"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Signing       bool   `json:"signing"`
+	SigningMethod string `json:"signing_method,omitempty"`
+	SigningKey    string `json:"signing_key,omitempty"`
+	GuardHooks    bool   `json:"guard_hooks"`
+	ExpectedName  string `json:"expected_name,omitempty"`
+	ExpectedEmail string `json:"expected_email,omitempty"`
+	// Matches is true when the repository commits as its workspace
+	// identity, or has no workspace to compare against
+	Matches bool `json:"matches"`
+}
+
+func serveWhoami(ctx context.Context, params json.RawMessage) (any, error) {
+	var p servePathParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	path, err := requestPath(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	gitRoot, err := serveRepo(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := serveConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	result := whoamiResult{Repository: gitRoot}
+	result.Remote, _ = git.GetRemoteURL(gitRoot)
+	if strings.HasPrefix(result.Remote, "git@") {
+		result.SSHAlias, _ = rewrite.ExtractHostFromSSHURL(result.Remote)
+	}
+	// The effective identity: what a commit would use, local or not
+	result.Name, _ = git.GetConfig(gitRoot, "user.name")
+	result.Email, _ = git.GetConfig(gitRoot, "user.email")
+	result.Signing, result.SigningMethod, result.SigningKey, _ = git.GetSigningStatus(gitRoot)
+	result.GuardHooks, _ = git.CheckHooksInstalled(gitRoot)
+
+	if repo, err := config.LoadRepo(gitRoot); err == nil && repo.Unmanaged {
+		result.Unmanaged = true
+	}
+	result.Workspace = workspaceForRepo(cfg, gitRoot)
+	result.Matches = true
+	if _, exists := cfg.GetWorkspace(result.Workspace); exists {
+		ws := repoIdentity(cfg, result.Workspace, gitRoot)
+		result.ExpectedName, result.ExpectedEmail = ws.Name, ws.Email
+		result.Matches = strings.EqualFold(result.Email, ws.Email) && (ws.Name == "" || result.Name == ws.Name)
+	}
+	return result, nil
+}
+
+// serveDoctorParams are the parameters of doctor
+type serveDoctorParams struct {
+	Path    string `json:"path"`
+	Offline bool   `json:"offline"`
+}
+
+func serveDoctor(ctx context.Context, params json.RawMessage) (any, error) {
+	var p serveDoctorParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	path, err := requestPath(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	gitRoot, err := serveRepo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, suppressed := suppressIssues(gitRoot, runAllChecks(ctx, gitRoot, p.Offline))
+	return doctorReport(issues, suppressed), nil
+}
+
+// serveFixParams are the parameters of fix
+type serveFixParams struct {
+	Path      string   `json:"path"`
+	Actions   []string `json:"actions"`
+	Workspace string   `json:"workspace"`
+	Identity  string   `json:"identity"`
+}
+
+// fixResult answers fix
+type fixResult struct {
+	OK     bool   `json:"ok"`
+	Output string `json:"output"`
+}
+
+// serveFix runs 'gitws fix' in a child process, see fixProcess
+func serveFix(ctx context.Context, params json.RawMessage) (any, error) {
+	var p serveFixParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	path, err := requestPath(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	gitRoot, err := serveRepo(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Actions) == 0 && p.Identity == "" {
+		return nil, rpc.InvalidParams("actions is required: rewrite-remote, set-identity or enable-guards")
+	}
+	for _, action := range p.Actions {
+		if !fixActions[action] {
+			return nil, rpc.InvalidParams("unknown action %q", action)
+		}
+	}
+
+	output, err := fixProcess(ctx, p.Workspace, p.Identity, gitRoot, p.Actions...)
+	return fixResult{OK: err == nil, Output: output}, nil
+}
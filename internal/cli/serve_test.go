@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestListenSocketPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("modes are not checked on Windows")
+	}
+	// Unix socket paths are limited to about 100 bytes
+	dir, err := os.MkdirTemp("", "gws-serve-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "gitws.sock")
+
+	listener, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		t.Errorf("expected the socket to be private, got %04o", mode)
+	}
+
+	if _, err := listenSocket(path); err == nil {
+		t.Error("expected a second server on the same socket to fail")
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince string
+)
+
+// statsCmd groups local usage statistics commands
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Local usage statistics (opt-in)",
+	Long: `Keep local usage statistics: commands run, fixes applied, and commits
+the guard hooks blocked.
+
+Statistics are off until you run 'gitws stats enable'. They are aggregated
+per day in ~/.gws/stats.json and never leave this machine.`,
+}
+
+// statsUsageCmd represents the stats usage command
+var statsUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show usage totals",
+	Long: `Show usage totals for a period.
+
+Examples:
+  gitws stats usage
+  gitws stats usage --since 30d
+  gitws stats usage --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsUsage,
+}
+
+var statsEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Start recording local usage statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStatsEnabled(true)
+	},
+}
+
+var statsDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop recording local usage statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStatsEnabled(false)
+	},
+}
+
+var statsResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Delete all recorded statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := stats.Reset(); err != nil {
+			return err
+		}
+		fmt.Println(prompt.Text("✓ Statistics deleted"))
+		return nil
+	},
+}
+
+// statsRecordCmd is called by the installed hook scripts
+var statsRecordCmd = &cobra.Command{
+	Use:    "record <counter>",
+	Short:  "Record an event (used by gitws hooks)",
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		recordUsage(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsUsageCmd)
+	statsCmd.AddCommand(statsEnableCmd)
+	statsCmd.AddCommand(statsDisableCmd)
+	statsCmd.AddCommand(statsResetCmd)
+	statsCmd.AddCommand(statsRecordCmd)
+
+	statsUsageCmd.Flags().StringVar(&statsSince, "since", "90d", "Period to report, in days (e.g. 30d)")
+}
+
+// usageReport is the JSON form of 'gitws stats usage'
+type usageReport struct {
+	Since    string         `json:"since"`
+	Enabled  bool           `json:"enabled"`
+	Guard    map[string]int `json:"guard"`
+	Fixes    map[string]int `json:"fixes"`
+	Commands map[string]int `json:"commands"`
+}
+
+func runStatsUsage(cmd *cobra.Command, args []string) error {
+	days, err := parseDays(statsSince)
+	if err != nil {
+		return err
+	}
+	since := time.Now().AddDate(0, 0, -days+1)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	data, err := stats.Load()
+	if err != nil {
+		return err
+	}
+
+	guard := data.Totals(stats.PrefixGuard, since)
+	fixes := data.Totals(stats.PrefixFix, since)
+	commands := data.Totals(stats.PrefixCommand, since)
+
+	if jsonOutput {
+		report := usageReport{
+			Since:    since.Format("2006-01-02"),
+			Enabled:  cfg.Stats,
+			Guard:    countMap(guard),
+			Fixes:    countMap(fixes),
+			Commands: countMap(commands),
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		return nil
+	}
+
+	if !cfg.Stats {
+		fmt.Println(prompt.Text("ℹ️  Statistics are disabled. Run 'gitws stats enable' to start recording."))
+	}
+
+	fmt.Printf("Usage since %s (%d days)\n", since.Format("2006-01-02"), days)
+	if len(guard)+len(fixes)+len(commands) == 0 {
+		fmt.Println("\nNothing recorded.")
+		return nil
+	}
+
+	if blocked := total(guard); blocked > 0 {
+		fmt.Printf(prompt.Text("\n🛡️  Prevented %d wrong-identity or policy-violating commits and pushes\n"), blocked)
+		printCounts(guard)
+	}
+	if len(fixes) > 0 {
+		fmt.Printf(prompt.Text("\n🔧 Fixes applied: %d\n"), total(fixes))
+		printCounts(fixes)
+	}
+	if len(commands) > 0 {
+		fmt.Printf(prompt.Text("\n⌨️  Commands run: %d\n"), total(commands))
+		printCounts(commands)
+	}
+	return nil
+}
+
+func setStatsEnabled(enabled bool) error {
+	err := config.Update(func(f *config.File) error {
+		f.Stats = enabled
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if enabled {
+		path, _ := stats.Path()
+		fmt.Printf(prompt.Text("✓ Recording local usage statistics in %s\n"), path)
+		fmt.Println("   Nothing is ever sent anywhere. Disable with 'gitws stats disable'.")
+	} else {
+		fmt.Println(prompt.Text("✓ Statistics disabled. Existing data is kept; remove it with 'gitws stats reset'."))
+	}
+	return nil
+}
+
+// recordUsage increments a counter when the user has opted in. Statistics
+// must never get in the way, so failures are ignored.
+func recordUsage(counter string) {
+	cfg, err := config.Load()
+	if err != nil || !cfg.Stats {
+		return
+	}
+	_ = stats.Record(counter)
+}
+
+// recordCommand counts a command invocation by its path below gitws
+func recordCommand(cmd *cobra.Command) {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if path == cmd.CommandPath() || strings.HasPrefix(path, "stats") || strings.HasPrefix(path, "hook ") {
+		return
+	}
+	recordUsage(stats.PrefixCommand + path)
+}
+
+// parseDays parses a period such as "90d" or "90" into a number of days
+func parseDays(s string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "d"))
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("invalid period: %q (use a number of days, e.g. 30d)", s)
+	}
+	return days, nil
+}
+
+func countMap(counts []stats.Count) map[string]int {
+	m := make(map[string]int, len(counts))
+	for _, c := range counts {
+		m[c.Name] = c.Count
+	}
+	return m
+}
+
+func total(counts []stats.Count) int {
+	sum := 0
+	for _, c := range counts {
+		sum += c.Count
+	}
+	return sum
+}
+
+func printCounts(counts []stats.Count) {
+	for _, c := range counts {
+		fmt.Printf("   %6d  %s\n", c.Count, c.Name)
+	}
+}
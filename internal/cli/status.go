@@ -1,19 +1,32 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/gitworkspaces/gitws/internal/config"
 	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/progress"
 	"github.com/gitworkspaces/gitws/internal/prompt"
 	"github.com/gitworkspaces/gitws/internal/rewrite"
+	"github.com/gitworkspaces/gitws/internal/ssh"
+	"github.com/gitworkspaces/gitws/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
 	statusExitNonZero bool
+	statusAll         bool
+	statusWorkspaces  []string
+	statusUnpushed    bool
 )
 
 // statusCmd represents the status command
@@ -24,14 +37,29 @@ var statusCmd = &cobra.Command{
 
 This command displays:
 - Origin remote URL and resolved alias
+- The HostName, User, Port and IdentityFile OpenSSH actually uses for the
+  alias (from 'ssh -G'), flagged when they differ from the workspace
 - Local user configuration
 - Signing status
 - Guard hooks status
 
+--all reports on every repository under the workspace roots instead
+(--workspace limits it to some): the branch, or a detached HEAD,
+uncommitted changes and untracked files, commits ahead of and behind the
+upstream, and stashes. --unpushed leaves out repositories whose work is
+all committed and pushed, e.g. to check before going on vacation.
+
 Examples:
   gitws status
   gitws status /path/to/repo
-  gitws status --exit-non-zero`,
+  gitws status --exit-non-zero
+  gitws status --all
+  gitws status --all --workspace work --unpushed
+
+With --exit-non-zero the exit code reflects the most severe issue: 0 when
+all checks pass, 1 for warnings, 2 for errors. Outside a repository it is
+3. 'gitws doctor' uses the same codes. With --all it is 1 when any
+repository has unpushed work.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runStatus,
 }
@@ -39,10 +67,20 @@ Examples:
 func init() {
 	rootCmd.AddCommand(statusCmd)
 
-	statusCmd.Flags().BoolVar(&statusExitNonZero, "exit-non-zero", false, "Exit with non-zero code if issues found")
+	statusCmd.Flags().BoolVar(&statusExitNonZero, "exit-non-zero", false, "Exit with 1 for warnings or 2 for errors when issues are found")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "Report on every repository under the workspace roots")
+	statusCmd.Flags().StringSliceVar(&statusWorkspaces, "workspace", nil, "With --all, only these workspaces")
+	statusCmd.Flags().BoolVar(&statusUnpushed, "unpushed", false, "With --all, only repositories with unpushed work")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	if statusAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all takes no path")
+		}
+		return runStatusAll(cmd)
+	}
+
 	var repoPath string
 	var err error
 
@@ -58,7 +96,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Find git root
 	gitRoot, err := git.FindGitRoot(repoPath)
 	if err != nil {
-		return fmt.Errorf("not in a git repository: %w", err)
+		return notRepoError(err)
 	}
 
 	// Get remote URL
@@ -77,45 +115,115 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Check if hooks are installed
 	hooksInstalled, _ := git.CheckHooksInstalled(gitRoot)
 
-	// Try to determine workspace from SSH alias
-	workspaceName := "unknown"
+	// Determine workspace from the SSH alias, falling back to the root
+	var workspaceName string
+	var ws *config.Workspace
+	if cfg, err := config.Load(); err == nil {
+		workspaceName = workspaceForRepo(cfg, gitRoot)
+		if w, exists := cfg.GetWorkspace(workspaceName); exists {
+			ws = &w
+		}
+	}
 	realHost := "unknown"
 	if strings.HasPrefix(remoteURL, "git@") {
 		if host, err := rewrite.ExtractHostFromSSHURL(remoteURL); err == nil {
 			realHost = host
-			// Try to extract workspace from alias
-			if parts := strings.Split(host, "-"); len(parts) > 1 {
-				workspaceName = parts[len(parts)-1] // Last part is usually workspace
-			}
 		}
 	}
 
 	// Check for issues
-	var issues []string
-	if userName == "" {
-		issues = append(issues, "No user.name configured")
+	var issues []prompt.Issue
+	if userName == "" || userEmail == "" {
+		var identityCmd []string
+		if workspaceName != "" {
+			identityCmd = fixCommand(workspaceName, gitRoot, "set-identity")
+		}
+		if userName == "" {
+			issues = append(issues, prompt.Issue{Message: "No user.name configured", Command: identityCmd})
+		}
+		if userEmail == "" {
+			issues = append(issues, prompt.Issue{Message: "No user.email configured", Command: identityCmd})
+		}
+	}
+	if ws != nil {
+		if age, _, due := keyRotationDue(*ws, 0, time.Now()); due {
+			issues = append(issues, prompt.Issue{
+				Message: fmt.Sprintf("SSH key is %d days old, past max_key_age %s", age, ws.MaxKeyAge),
+				Command: []string{"gitws", "rotate", workspaceName},
+			})
+		}
 	}
-	if userEmail == "" {
-		issues = append(issues, "No user.email configured")
+	repo, _ := config.LoadRepo(gitRoot)
+	unmanaged := repo != nil && repo.Unmanaged
+	if repo != nil && repo.Untrusted {
+		issues = append(issues, prompt.Issue{
+			Message: fmt.Sprintf("%s is not trusted, so its settings are ignored", config.RepoFileName),
+			Command: []string{"gitws", "trust", gitRoot},
+		})
 	}
-	if !hooksInstalled {
-		issues = append(issues, "Guard hooks not installed")
+	if !hooksInstalled && !unmanaged {
+		issues = append(issues, prompt.Issue{
+			Message: "Guard hooks not installed",
+			Command: fixCommand(workspaceName, gitRoot, "enable-guards"),
+		})
+	}
+
+	var sshResolved *sshStatus
+	if realHost != "unknown" {
+		var sshIssues []prompt.Issue
+		sshResolved, sshIssues = effectiveSSH(cmd.Context(), realHost, ws)
+		issues = append(issues, sshIssues...)
+	}
+
+	if prompt.Structured() {
+		report := statusReport{
+			Repository:    filepath.Base(gitRoot),
+			Path:          gitRoot,
+			Origin:        remoteURL,
+			SSH:           sshResolved,
+			Unmanaged:     unmanaged,
+			UserName:      userName,
+			UserEmail:     userEmail,
+			Signing:       signingEnabled,
+			SigningMethod: signingMethod,
+			SigningKey:    signingKey,
+			GuardHooks:    hooksInstalled,
+			Issues:        doctorReport(issues, nil),
+		}
+		if realHost != "unknown" {
+			report.SSHAlias = realHost
+		}
+		if !unmanaged {
+			report.Workspace = workspaceName
+		}
+		if err := prompt.ShowData(report); err != nil {
+			return err
+		}
+		if statusExitNonZero && len(issues) > 0 {
+			return exitCode(cmd, issueExitCode(issues))
+		}
+		return nil
 	}
 
 	// Prepare status data
 	headers := []string{"Property", "Value"}
 	rows := [][]string{
-		{"Repository", filepath.Base(gitRoot)},
-		{"Path", gitRoot},
-		{"Origin", remoteURL},
-		{"SSH Alias", realHost},
-		{"Workspace", workspaceName},
-		{"User Name", getDisplayValue(userName, "Not set")},
-		{"User Email", getDisplayValue(userEmail, "Not set")},
-		{"Signing", getSigningDisplay(signingEnabled, signingMethod)},
-		{"Signing Key", getDisplayValue(signingKey, "Not set")},
-		{"Guard Hooks", getBoolDisplay(hooksInstalled)},
+		{i18n.T("Repository"), filepath.Base(gitRoot)},
+		{i18n.T("Path"), gitRoot},
+		{i18n.T("Origin"), remoteURL},
+		{i18n.T("SSH Alias"), realHost},
+	}
+	if sshResolved != nil {
+		rows = append(rows, sshResolved.rows()...)
 	}
+	rows = append(rows, [][]string{
+		{i18n.T("Workspace"), workspaceDisplay(workspaceName, unmanaged)},
+		{i18n.T("User Name"), getDisplayValue(userName, i18n.T("Not set"))},
+		{i18n.T("User Email"), getDisplayValue(userEmail, i18n.T("Not set"))},
+		{i18n.T("Signing"), getSigningDisplay(signingEnabled, signingMethod)},
+		{i18n.T("Signing Key"), getDisplayValue(signingKey, i18n.T("Not set"))},
+		{i18n.T("Guard Hooks"), getBoolDisplay(hooksInstalled)},
+	}...)
 
 	// Show status
 	if err := prompt.ShowStatusTable(headers, rows); err != nil {
@@ -125,24 +233,145 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Show issues if any
 	if len(issues) > 0 {
 		fmt.Println()
-		fmt.Println("⚠️  Issues found:")
+		fmt.Println(prompt.Text(i18n.T("⚠️  Issues found:")))
 		for _, issue := range issues {
-			fmt.Printf("   • %s\n", issue)
+			fmt.Printf(prompt.Text("   • %s\n"), issue.Message)
+			if fix := issue.FixText(); fix != "" {
+				fmt.Printf("     %s\n", fix)
+			}
 		}
 		fmt.Println()
-		fmt.Println("Run 'gitws doctor' for detailed analysis and fixes.")
+		fmt.Println(i18n.T("Run 'gitws doctor' for detailed analysis and fixes."))
 
 		if statusExitNonZero {
-			os.Exit(1)
+			return exitCode(cmd, issueExitCode(issues))
 		}
 	} else {
 		fmt.Println()
-		fmt.Println("✓ All checks passed!")
+		fmt.Println(prompt.Text(i18n.T("✓ All checks passed!")))
 	}
 
 	return nil
 }
 
+// statusReport is a repository's status as --format json and yaml
+// encode it: plain values, with what the workspace expects beside what
+// OpenSSH resolves
+type statusReport struct {
+	Repository    string        `json:"repository" yaml:"repository"`
+	Path          string        `json:"path" yaml:"path"`
+	Origin        string        `json:"origin" yaml:"origin"`
+	SSHAlias      string        `json:"ssh_alias,omitempty" yaml:"ssh_alias,omitempty"`
+	SSH           *sshStatus    `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+	Workspace     string        `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	Unmanaged     bool          `json:"unmanaged" yaml:"unmanaged"`
+	UserName      string        `json:"user_name" yaml:"user_name"`
+	UserEmail     string        `json:"user_email" yaml:"user_email"`
+	Signing       bool          `json:"signing" yaml:"signing"`
+	SigningMethod string        `json:"signing_method,omitempty" yaml:"signing_method,omitempty"`
+	SigningKey    string        `json:"signing_key,omitempty" yaml:"signing_key,omitempty"`
+	GuardHooks    bool          `json:"guard_hooks" yaml:"guard_hooks"`
+	Issues        []doctorIssue `json:"issues" yaml:"issues"`
+}
+
+// sshStatus is what 'ssh -G' resolves for a repository's alias
+type sshStatus struct {
+	HostName      sshSetting `json:"hostname" yaml:"hostname"`
+	User          sshSetting `json:"user" yaml:"user"`
+	Port          sshSetting `json:"port" yaml:"port"`
+	IdentityFile  sshSetting `json:"identity_file" yaml:"identity_file"`
+	IdentityFiles []string   `json:"identity_files" yaml:"identity_files"`
+	Error         string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// sshSetting is a resolved setting and, when the workspace checks it,
+// the value it expects
+type sshSetting struct {
+	Actual   string `json:"actual" yaml:"actual"`
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Mismatch bool   `json:"mismatch" yaml:"mismatch"`
+}
+
+// display is the status table cell of a setting, marking a mismatch
+func (s sshSetting) display() string {
+	if s.Mismatch {
+		return fmt.Sprintf("%s ⚠️ (workspace: %s)", getDisplayValue(s.Actual, "None"), s.Expected)
+	}
+	return getDisplayValue(s.Actual, "None")
+}
+
+// effectiveSSH resolves alias with 'ssh -G' and returns the settings
+// OpenSSH will use, with an issue for each one that does not match the
+// workspace
+func effectiveSSH(ctx context.Context, alias string, ws *config.Workspace) (*sshStatus, []prompt.Issue) {
+	resolved, err := ssh.ResolveConfig(ctx, alias)
+	if err != nil {
+		return &sshStatus{Error: "ssh -G failed"}, nil
+	}
+
+	identityFiles := make([]string, 0, len(resolved["identityfile"]))
+	for _, file := range resolved["identityfile"] {
+		if expanded, err := workspace.ExpandPath(file); err == nil {
+			file = expanded
+		}
+		identityFiles = append(identityFiles, file)
+	}
+	status := &sshStatus{
+		HostName:      sshSetting{Actual: resolved.Get("hostname")},
+		User:          sshSetting{Actual: resolved.Get("user")},
+		Port:          sshSetting{Actual: resolved.Get("port")},
+		IdentityFiles: identityFiles,
+	}
+	if len(identityFiles) > 0 {
+		status.IdentityFile.Actual = identityFiles[0]
+	}
+
+	var issues []prompt.Issue
+	if ws != nil {
+		check := func(name string, setting *sshSetting, expected string) {
+			setting.Expected = expected
+			if setting.Actual == expected {
+				return
+			}
+			setting.Mismatch = true
+			issues = append(issues, prompt.Issue{
+				Type:    "warning",
+				Message: fmt.Sprintf("ssh resolves %s for %s to %q, workspace expects %s", name, alias, setting.Actual, expected),
+				Fix:     "Check ~/.ssh/config for an earlier Host block matching " + alias + "; 'gitws diff' shows drift in the managed block",
+			})
+		}
+		check("HostName", &status.HostName, ws.HostName)
+		check("User", &status.User, "git")
+		check("IdentityFile", &status.IdentityFile, ws.SSHKey)
+	}
+	return status, issues
+}
+
+// rows are the status table rows of the resolved settings
+func (s *sshStatus) rows() [][]string {
+	if s.Error != "" {
+		return [][]string{{"SSH Config", "Could not resolve (" + s.Error + ")"}}
+	}
+	identityFile := s.IdentityFile.display()
+	if !s.IdentityFile.Mismatch && len(s.IdentityFiles) > 1 {
+		identityFile = strings.Join(s.IdentityFiles, ", ")
+	}
+	return [][]string{
+		{"SSH HostName", s.HostName.display()},
+		{"SSH User", s.User.display()},
+		{"SSH Port", s.Port.Actual},
+		{"SSH IdentityFile", identityFile},
+	}
+}
+
+// workspaceDisplay is the Workspace row of the status table
+func workspaceDisplay(name string, unmanaged bool) string {
+	if unmanaged {
+		return i18n.T("Unmanaged (%s)", config.RepoFileName)
+	}
+	return getDisplayValue(name, i18n.T("unknown"))
+}
+
 func getDisplayValue(value, defaultValue string) string {
 	if value == "" {
 		return defaultValue
@@ -152,14 +381,176 @@ func getDisplayValue(value, defaultValue string) string {
 
 func getSigningDisplay(enabled bool, method string) string {
 	if !enabled {
-		return "Disabled"
+		return i18n.T("Disabled")
 	}
-	return fmt.Sprintf("Enabled (%s)", method)
+	return i18n.T("Enabled (%s)", method)
 }
 
 func getBoolDisplay(value bool) string {
 	if value {
-		return "Installed"
+		return i18n.T("Installed")
+	}
+	return i18n.T("Not installed")
+}
+
+// repoStatus is the state of one workspace repository, as --all
+// --format json and yaml encode it
+type repoStatus struct {
+	Workspace string `json:"workspace" yaml:"workspace"`
+	Path      string `json:"path" yaml:"path"`
+	Branch    string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Detached  bool   `json:"detached" yaml:"detached"`
+	Upstream  string `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	Ahead     int    `json:"ahead" yaml:"ahead"`
+	Behind    int    `json:"behind" yaml:"behind"`
+	Changed   int    `json:"changed" yaml:"changed"`
+	Untracked int    `json:"untracked" yaml:"untracked"`
+	Stashes   int    `json:"stashes" yaml:"stashes"`
+	Unpushed  bool   `json:"unpushed" yaml:"unpushed"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	state git.RepoState
+	label string
+}
+
+// statusJobs is how many repositories --all reads at once
+const statusJobs = 8
+
+func runStatusAll(cmd *cobra.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := statusWorkspaces
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	sort.Strings(names)
+
+	var statuses []*repoStatus
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+		repos, err := workspaceRepos(cfg, name)
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			label, err := filepath.Rel(ws.Root, repo)
+			if err != nil {
+				label = repo
+			}
+			statuses = append(statuses, &repoStatus{Workspace: name, Path: repo, label: label})
+		}
+	}
+
+	bar := progress.New("Reading", len(statuses))
+	jobs := make(chan struct{}, statusJobs)
+	var wg sync.WaitGroup
+	for _, st := range statuses {
+		wg.Add(1)
+		jobs <- struct{}{}
+		go func(st *repoStatus) {
+			defer wg.Done()
+			defer func() { <-jobs }()
+			task := bar.Start(st.label)
+			defer task.Done()
+
+			state, err := git.GetRepoState(st.Path)
+			if err != nil {
+				st.Error = err.Error()
+				return
+			}
+			st.state = state
+			st.Branch, st.Detached, st.Upstream = state.Branch, state.Detached, state.Upstream
+			st.Ahead, st.Behind, st.Changed = state.Ahead, state.Behind, state.Changed
+			st.Untracked, st.Stashes, st.Unpushed = state.Untracked, state.Stashes, state.Unpushed()
+		}(st)
+	}
+	wg.Wait()
+	bar.Stop()
+
+	unpushed := 0
+	shown := []*repoStatus{}
+	for _, st := range statuses {
+		if st.Unpushed || st.Error != "" {
+			unpushed++
+		}
+		if !statusUnpushed || st.Unpushed || st.Error != "" {
+			shown = append(shown, st)
+		}
+	}
+
+	if prompt.Structured() {
+		if err := prompt.ShowData(shown); err != nil {
+			return err
+		}
+	} else {
+		if len(shown) > 0 {
+			rows := make([][]string, 0, len(shown))
+			for _, st := range shown {
+				rows = append(rows, repoStatusRow(st))
+			}
+			if err := prompt.ShowStatusTable([]string{"Workspace", "Repository", "Branch", "Changes", "Ahead/Behind", "Stashes"}, rows); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		if unpushed > 0 {
+			fmt.Println(prompt.Text(i18n.T("⚠️  %d of %d repositories have work that is not pushed", unpushed, len(statuses))))
+		} else {
+			fmt.Println(prompt.Text(i18n.T("✓ Everything in %d repositories is committed and pushed", len(statuses))))
+		}
+	}
+
+	if statusExitNonZero && unpushed > 0 {
+		return exitCode(cmd, ExitWarnings)
+	}
+	return nil
+}
+
+// repoStatusRow is the row of the --all table for a repository
+func repoStatusRow(st *repoStatus) []string {
+	if st.Error != "" {
+		return []string{st.Workspace, st.label, "⚠️ " + st.Error, "", "", ""}
+	}
+	s := st.state
+
+	branch := s.Branch
+	if s.Detached {
+		branch = i18n.T("detached HEAD") + " ⚠️"
+	}
+
+	changes := i18n.T("clean")
+	if !s.Clean() {
+		var parts []string
+		if s.Changed > 0 {
+			parts = append(parts, i18n.T("%d changed", s.Changed))
+		}
+		if s.Untracked > 0 {
+			parts = append(parts, i18n.T("%d untracked", s.Untracked))
+		}
+		changes = strings.Join(parts, ", ") + " ⚠️"
+	}
+
+	aheadBehind := fmt.Sprintf("↑%d ↓%d", s.Ahead, s.Behind)
+	switch {
+	case s.NoCommits:
+		aheadBehind = i18n.T("no commits")
+	case s.Detached:
+		aheadBehind = "-"
+	case s.Upstream == "":
+		aheadBehind = i18n.T("no upstream") + " ⚠️"
+	case s.Ahead > 0:
+		aheadBehind += " ⚠️"
+	}
+
+	stashes := strconv.Itoa(s.Stashes)
+	if s.Stashes > 0 {
+		stashes += " ⚠️"
 	}
-	return "Not installed"
+	return []string{st.Workspace, st.label, branch, changes, aheadBehind, stashes}
 }
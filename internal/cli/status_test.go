@@ -0,0 +1,61 @@
+package cli
+
+import "testing"
+
+func TestSSHStatusRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   sshStatus
+		expected [][]string
+	}{
+		{
+			name: "matching",
+			status: sshStatus{
+				HostName:      sshSetting{Actual: "github.com", Expected: "github.com"},
+				User:          sshSetting{Actual: "git", Expected: "git"},
+				Port:          sshSetting{Actual: "22"},
+				IdentityFile:  sshSetting{Actual: "/k/work", Expected: "/k/work"},
+				IdentityFiles: []string{"/k/work", "/k/id_rsa"},
+			},
+			expected: [][]string{
+				{"SSH HostName", "github.com"},
+				{"SSH User", "git"},
+				{"SSH Port", "22"},
+				{"SSH IdentityFile", "/k/work, /k/id_rsa"},
+			},
+		},
+		{
+			name: "mismatch",
+			status: sshStatus{
+				HostName:     sshSetting{Actual: "github.com", Expected: "github.com"},
+				User:         sshSetting{Actual: "bob", Expected: "git", Mismatch: true},
+				Port:         sshSetting{Actual: "22"},
+				IdentityFile: sshSetting{Expected: "/k/work", Mismatch: true},
+			},
+			expected: [][]string{
+				{"SSH HostName", "github.com"},
+				{"SSH User", "bob ⚠️ (workspace: git)"},
+				{"SSH Port", "22"},
+				{"SSH IdentityFile", "None ⚠️ (workspace: /k/work)"},
+			},
+		},
+		{
+			name:     "unresolved",
+			status:   sshStatus{Error: "ssh -G failed"},
+			expected: [][]string{{"SSH Config", "Could not resolve (ssh -G failed)"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := tt.status.rows()
+			if len(rows) != len(tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, rows)
+			}
+			for i := range rows {
+				if rows[i][0] != tt.expected[i][0] || rows[i][1] != tt.expected[i][1] {
+					t.Errorf("expected %q, got %q", tt.expected[i], rows[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+// Operations with their own timeout, named as in timeouts in config.yaml
+const (
+	opClone = "clone"
+	opFetch = "fetch"
+	opPush  = "push"
+	opAPI   = "api"
+	opSSH   = "ssh"
+)
+
+// defaultTimeouts are generous: they catch hung connections, not slow ones
+var defaultTimeouts = map[string]time.Duration{
+	opClone: 30 * time.Minute,
+	opFetch: 10 * time.Minute,
+	opPush:  10 * time.Minute,
+	opAPI:   30 * time.Second,
+	opSSH:   20 * time.Second,
+}
+
+var (
+	timeoutsOnce sync.Once
+	timeouts     map[string]time.Duration
+)
+
+// operationTimeout returns the timeout of op, 0 meaning none
+func operationTimeout(op string) time.Duration {
+	timeoutsOnce.Do(func() {
+		timeouts = make(map[string]time.Duration, len(defaultTimeouts))
+		for name, d := range defaultTimeouts {
+			timeouts[name] = d
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return
+		}
+		for name, value := range map[string]string{
+			opClone: cfg.Timeouts.Clone, opFetch: cfg.Timeouts.Fetch, opPush: cfg.Timeouts.Push,
+			opAPI: cfg.Timeouts.API, opSSH: cfg.Timeouts.SSH,
+		} {
+			if value == "0" {
+				timeouts[name] = 0
+			} else if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+				timeouts[name] = d
+			}
+		}
+	})
+	return timeouts[op]
+}
+
+// operationContext bounds one op by its timeout. ctx is normally the
+// command's, which is cancelled on Ctrl-C.
+func operationContext(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	if d := operationTimeout(op); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// operationError explains err when op failed because ctx timed out or was
+// interrupted
+func operationError(ctx context.Context, op string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s timed out after %s (set timeouts.%s in config.yaml to wait longer): %w", op, operationTimeout(op), op, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%s interrupted: %w", op, context.Canceled)
+	}
+	return err
+}
+
+// callAPI runs call with the API timeout
+func callAPI[T any](ctx context.Context, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := operationContext(ctx, opAPI)
+	defer cancel()
+	result, err := call(ctx)
+	return result, operationError(ctx, opAPI, err)
+}
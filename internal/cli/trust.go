@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trustRevoke bool
+)
+
+// trustCmd represents the trust command
+var trustCmd = &cobra.Command{
+	Use:   "trust [path]",
+	Short: "Apply the settings of a repository's .gitws.yaml",
+	Long: `Trust the repository's .gitws.yaml, so its settings apply.
+
+.gitws.yaml is committed with the repository, and anyone who can push to
+it can change it. Until you trust it, gitws ignores it: the repository is
+not unmanaged, no workspace or identity is pinned, no guard rule is
+disabled and no doctor issue is suppressed. Trust records the file's
+current content; after any change, e.g. from a pull, it is ignored again
+until you review and trust it anew. 'gitws doctor' reports files that are
+not trusted.
+
+Even a trusted file cannot disable the rules that keep commits on the
+workspace identity and pushes on its alias: ` + strings.Join(guard.Mandatory, ", ") + `.
+
+Examples:
+  gitws trust
+  gitws trust ~/code/work/api
+  gitws trust --revoke`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTrust,
+}
+
+func init() {
+	rootCmd.AddCommand(trustCmd)
+
+	trustCmd.Flags().BoolVar(&trustRevoke, "revoke", false, "Stop trusting the repository's .gitws.yaml")
+}
+
+func runTrust(cmd *cobra.Command, args []string) error {
+	var repoPath string
+	var err error
+	if len(args) > 0 {
+		repoPath = args[0]
+	} else {
+		repoPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	gitRoot, err := git.FindGitRoot(repoPath)
+	if err != nil {
+		return notRepoError(err)
+	}
+
+	if trustRevoke {
+		revoked, err := config.UntrustRepo(gitRoot)
+		if err != nil {
+			return fmt.Errorf("failed to revoke trust: %w", err)
+		}
+		if !revoked {
+			fmt.Printf(prompt.Text("ℹ️  %s of %s was not trusted\n"), config.RepoFileName, gitRoot)
+			return nil
+		}
+		fmt.Printf(prompt.Text("✓ No longer trusting %s of %s\n"), config.RepoFileName, gitRoot)
+		return nil
+	}
+
+	repo, err := config.ReadRepo(gitRoot)
+	if err != nil {
+		return err
+	}
+	if err := config.TrustRepo(gitRoot); err != nil {
+		return fmt.Errorf("failed to trust %s: %w", config.RepoFileName, err)
+	}
+	fmt.Printf(prompt.Text("✓ Trusted %s of %s\n"), config.RepoFileName, gitRoot)
+	for _, rule := range repo.DisableGuards {
+		if guard.IsMandatory(rule) {
+			fmt.Printf(prompt.Text("⚠️  The %s rule cannot be disabled and stays enforced\n"), rule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/notify"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// watchLogName is the log of what 'gitws watch' did, under the state
+// directory
+const watchLogName = "watch.log"
+
+var (
+	watchInterval time.Duration
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [workspace...]",
+	Short: "Set up repositories cloned into workspace roots without gitws",
+	Long: `Watch workspace roots for new repositories and set them up as 'gitws
+fix' would: the remote is rewritten to the workspace alias, the workspace
+identity set and guard hooks installed. A plain 'git clone' into a
+workspace root then still commits as the right identity.
+
+Repositories already present when watch starts are left alone; run
+'gitws doctor' or 'gitws fix' on those. A new repository is fixed once a
+second scan finds it settled, so clones still in progress are not touched.
+Repositories whose .gitws.yaml declares them unmanaged, or that no
+workspace serves, are skipped.
+
+Every action is printed and appended to ~/.gws/watch.log, and sinks
+subscribed to repo_fixed events are notified (see 'gitws notify').
+
+Without arguments every workspace with a root is watched. watch runs in
+the foreground until Ctrl-C; start it from your login session, a systemd
+user unit or a launchd agent to keep it running.
+
+Examples:
+  gitws watch
+  gitws watch work --interval 10s
+  nohup gitws watch >/dev/null 2>&1 &`,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Second, "How often workspace roots are scanned")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, name := range args {
+		if _, exists := cfg.GetWorkspace(name); !exists {
+			return fmt.Errorf("workspace %q not found", name)
+		}
+	}
+
+	logFile, err := openWatchLog()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	config.CacheLoads()
+	w := &watcher{
+		workspaces: args,
+		out:        io.MultiWriter(os.Stdout, logFile),
+		known:      make(map[string]bool),
+		pending:    make(map[string]bool),
+	}
+
+	roots := w.roots(cfg)
+	if len(roots) == 0 {
+		return fmt.Errorf("no workspace has a root to watch")
+	}
+	for _, repo := range w.scan(roots) {
+		w.known[repo] = true
+	}
+	w.logf("👀 Watching %s (%d repositories present)", strings.Join(roots, ", "), len(w.known))
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cmd.Context().Done():
+			w.logf("👋 Stopped watching")
+			return nil
+		case <-ticker.C:
+			w.poll(cmd)
+		}
+	}
+}
+
+// openWatchLog opens watch.log for appending
+func openWatchLog() (*os.File, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, watchLogName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch log: %w", err)
+	}
+	return f, nil
+}
+
+// watcher remembers the repositories under the watched roots between
+// scans
+type watcher struct {
+	workspaces []string // empty for all
+	out        io.Writer
+	known      map[string]bool // repositories handled or present at start
+	pending    map[string]bool // new repositories waiting for the next scan
+}
+
+// logf prints a timestamped line to the terminal and the log
+func (w *watcher) logf(format string, args ...any) {
+	fmt.Fprintf(w.out, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), prompt.Text(fmt.Sprintf(format, args...)))
+}
+
+// roots returns the roots of the watched workspaces, sorted
+func (w *watcher) roots(cfg *config.File) []string {
+	names := w.workspaces
+	if len(names) == 0 {
+		names = cfg.ListWorkspaces()
+	}
+	seen := make(map[string]bool)
+	var roots []string
+	for _, name := range names {
+		ws, exists := cfg.GetWorkspace(name)
+		if !exists || ws.Root == "" || seen[ws.Root] {
+			continue
+		}
+		seen[ws.Root] = true
+		roots = append(roots, ws.Root)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// scan returns the repositories under roots
+func (w *watcher) scan(roots []string) []string {
+	var repos []string
+	for _, root := range roots {
+		found, err := scanRepositories(root, func() {})
+		if err != nil {
+			w.logf("⚠️  %v", err)
+			continue
+		}
+		repos = append(repos, found...)
+	}
+	return repos
+}
+
+// poll rescans the roots, picking up workspaces added to config.yaml
+// since, and fixes the new repositories that have settled
+func (w *watcher) poll(cmd *cobra.Command) {
+	cfg, err := config.Load()
+	if err != nil {
+		w.logf("⚠️  Failed to load config: %v", err)
+		return
+	}
+
+	present := make(map[string]bool)
+	for _, repo := range w.scan(w.roots(cfg)) {
+		present[repo] = true
+		switch {
+		case w.known[repo]:
+		case w.pending[repo] && repoSettled(repo):
+			delete(w.pending, repo)
+			w.known[repo] = true
+			w.setUp(cmd, cfg, repo)
+		default:
+			w.pending[repo] = true
+		}
+	}
+
+	// A removed repository is forgotten, so cloning it again is noticed
+	for repo := range w.known {
+		if !present[repo] {
+			delete(w.known, repo)
+		}
+	}
+	for repo := range w.pending {
+		if !present[repo] {
+			delete(w.pending, repo)
+		}
+	}
+}
+
+// repoSettled reports whether git is done creating the repository at
+// path: it has a HEAD and no index being written
+func repoSettled(path string) bool {
+	gitDir := filepath.Join(path, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		// A linked worktree's .git file is written last
+		return err == nil
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(gitDir, "index.lock"))
+	return os.IsNotExist(err)
+}
+
+// setUp applies the workspace to a new repository
+func (w *watcher) setUp(cmd *cobra.Command, cfg *config.File, repo string) {
+	if r, err := config.LoadRepo(repo); err == nil && r.Unmanaged {
+		w.logf("ℹ️  Skipped %s: %s declares it unmanaged", repo, config.RepoFileName)
+		return
+	}
+	name := workspaceForRepo(cfg, repo)
+	if name == "" {
+		w.logf("ℹ️  Skipped %s: no workspace serves it", repo)
+		return
+	}
+
+	output, err := fixProcess(cmd.Context(), name, "", repo, "rewrite-remote", "set-identity", "enable-guards")
+	if err != nil {
+		w.logf("❌ Failed to set up %s for workspace '%s': %s", repo, name, lastOutputLine(output, err))
+		return
+	}
+	ws := cfg.Workspaces[name]
+	w.logf("✓ Set up %s for workspace '%s' as %s <%s>", repo, name, ws.Name, ws.Email)
+	notifyEvent(cfg, notify.EventRepoFixed, name, fmt.Sprintf("set up new clone %s: remote alias, identity and guard hooks", repo))
+}
+
+// lastOutputLine returns the last non-empty line a failed process
+// printed, or its error when it printed nothing
+func lastOutputLine(output string, err error) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return strings.TrimPrefix(last, "Error: ")
+	}
+	return err.Error()
+}
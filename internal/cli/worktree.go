@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/prompt"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// defaultWorktreeLayout places worktrees next to their repository
+const defaultWorktreeLayout = "{root}/{org}/{repo}@{branch}"
+
+// worktreeCmd groups worktree commands
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Manage git worktrees of workspace repositories",
+}
+
+// worktreeAddCmd represents the worktree add command
+var worktreeAddCmd = &cobra.Command{
+	Use:   "add <workspace> <org/repo> <branch>",
+	Short: "Check out a branch in a new worktree",
+	Long: `Check out a branch of a workspace repository in a new worktree.
+
+This command will:
+- Find the repository under the workspace root (as cloned by gitws clone)
+- Create the worktree at the workspace's worktree_layout, by default
+  {root}/{org}/{repo}@{branch} (slashes in the branch become dashes)
+- Check out the branch, creating it from HEAD if it does not exist locally
+  or on origin
+- Verify the worktree commits with the workspace identity
+
+Worktrees share the repository's config and hooks, so identity, signing
+and guard hooks carry over.
+
+Examples:
+  gitws worktree add work acme/api feature/login
+  gitws worktree add personal me/dotfiles experiment`,
+	Args: cobra.ExactArgs(3),
+	RunE: runWorktreeAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(worktreeCmd)
+	worktreeCmd.AddCommand(worktreeAddCmd)
+}
+
+func runWorktreeAdd(cmd *cobra.Command, args []string) error {
+	workspaceName, repoName, branch := args[0], strings.Trim(args[1], "/"), args[2]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ws, exists := cfg.GetWorkspace(workspaceName)
+	if !exists {
+		return fmt.Errorf("workspace %q not found", workspaceName)
+	}
+
+	org, repo := "", repoName
+	if i := strings.LastIndex(repoName, "/"); i >= 0 {
+		org, repo = repoName[:i], repoName[i+1:]
+	}
+	repoPath := workspaceRepoPath(ws, repoName, org, repo)
+	if !git.IsGitRepo(repoPath) {
+		return fmt.Errorf("%s is not a repository. Clone it first: gitws clone %s %s", repoPath, workspaceName, repoName)
+	}
+
+	destPath := worktreePath(ws, repoName, branch)
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("destination %s already exists", destPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := git.AddWorktree(repoPath, destPath, branch); err != nil {
+		return err
+	}
+
+	// The config is shared with the main checkout, but repositories
+	// cloned before gitws managed them may lack the local identity
+	if err := setupRepositoryConfig(destPath, ws); err != nil {
+		return fmt.Errorf("failed to setup repository config: %w", err)
+	}
+	if email, _ := git.GetConfig(destPath, "user.email"); email != ws.Email {
+		return fmt.Errorf("worktree created at %s but commits there would use %q instead of %s; check 'git config --show-origin user.email' in it", destPath, email, ws.Email)
+	}
+
+	return prompt.ShowSummary(prompt.SummaryData{
+		Title: "✓ Worktree created successfully",
+		Items: []prompt.SummaryItem{
+			{Label: "Workspace", Value: workspaceName, Icon: "📁"},
+			{Label: "Repository", Value: repoPath, Icon: "📦"},
+			{Label: "Branch", Value: branch, Icon: "🌿"},
+			{Label: "Worktree", Value: destPath, Icon: "📍"},
+			{Label: "Email", Value: ws.Email, Icon: "📧"},
+		},
+		NextSteps: []string{
+			fmt.Sprintf("cd %s", destPath),
+			fmt.Sprintf("Remove it when done: git -C %s worktree remove %s", repoPath, destPath),
+		},
+	})
+}
+
+// worktreePath expands the workspace worktree layout for a repository
+// ("org/repo", relative to the workspace root) and branch
+func worktreePath(ws config.Workspace, repoName, branch string) string {
+	layout := ws.WorktreeLayout
+	if layout == "" {
+		layout = defaultWorktreeLayout
+	}
+
+	org, repo := "", repoName
+	if i := strings.LastIndex(repoName, "/"); i >= 0 {
+		org, repo = repoName[:i], repoName[i+1:]
+	}
+
+	path := strings.NewReplacer(
+		"{root}", ws.Root,
+		"{org}", org,
+		"{repo}", repo,
+		"{branch}", strings.ReplaceAll(branch, "/", "-"),
+	).Replace(layout)
+	if expanded, err := workspace.ExpandPath(path); err == nil {
+		path = expanded
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
@@ -0,0 +1,136 @@
+package compat
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gitworkspaces/gitws/internal/git"
+)
+
+// Tools whose versions gate features
+const (
+	ToolGit     = "git"
+	ToolOpenSSH = "OpenSSH"
+)
+
+// Requirement is the oldest release of a tool a feature works with
+type Requirement struct {
+	Tool string
+	Min  git.Version
+}
+
+// Feature is something gitws configures that older git or OpenSSH
+// releases do not understand
+type Feature struct {
+	Name     string
+	Requires []Requirement
+}
+
+var (
+	// Hasconfig is includeIf "hasconfig:remote.*.url:", used by hasconfig isolation
+	Hasconfig = Feature{"hasconfig includes", []Requirement{{ToolGit, git.HasconfigMinVersion}}}
+	// ConfigEnv is GIT_CONFIG_COUNT, used to pass settings to nested git under 'gitws exec'
+	ConfigEnv = Feature{"GIT_CONFIG_COUNT settings", []Requirement{{ToolGit, git.Version{Major: 2, Minor: 31}}}}
+	// SSHSigning is gpg.format=ssh, which signs with ssh-keygen -Y sign
+	SSHSigning = Feature{"SSH commit signing", []Requirement{
+		{ToolGit, git.Version{Major: 2, Minor: 34}},
+		{ToolOpenSSH, git.Version{Major: 8, Minor: 2}},
+	}}
+	// AllowedSigners is gpg.ssh.allowedSignersFile, which git needs to
+	// verify SSH signatures with ssh-keygen -Y find-principals
+	AllowedSigners = Feature{"gpg.ssh.allowedSignersFile", []Requirement{
+		{ToolGit, git.Version{Major: 2, Minor: 34}},
+		{ToolOpenSSH, git.Version{Major: 8, Minor: 2}},
+	}}
+)
+
+// Features lists every gated feature
+var Features = []Feature{Hasconfig, ConfigEnv, SSHSigning, AllowedSigners}
+
+var (
+	sshOnce    sync.Once
+	sshVersion git.Version
+	sshErr     error
+)
+
+// Version returns the installed version of a tool. Each tool is run once
+// per process.
+func Version(tool string) (git.Version, error) {
+	switch tool {
+	case ToolGit:
+		return git.GetVersion()
+	case ToolOpenSSH:
+		sshOnce.Do(func() {
+			// ssh -V prints to stderr
+			output, err := exec.Command("ssh", "-V").CombinedOutput()
+			if err != nil {
+				sshErr = fmt.Errorf("failed to run ssh -V: %w", err)
+				return
+			}
+			sshVersion, sshErr = ParseOpenSSHVersion(string(output))
+		})
+		return sshVersion, sshErr
+	}
+	return git.Version{}, fmt.Errorf("unknown tool: %s", tool)
+}
+
+// ParseOpenSSHVersion parses the output of "ssh -V",
+// e.g. "OpenSSH_9.2p1 Debian-2, OpenSSL 3.0.15 3 Sep 2024"
+func ParseOpenSSHVersion(output string) (git.Version, error) {
+	fields := strings.Fields(strings.ReplaceAll(output, ",", " "))
+	for _, field := range fields {
+		// OpenSSH_for_Windows_8.6p1 on Windows
+		idx := strings.LastIndex(field, "_")
+		if !strings.HasPrefix(field, "OpenSSH_") || idx == -1 {
+			continue
+		}
+
+		var v git.Version
+		number := field[idx+1:]
+		if p := strings.IndexAny(number, "pP"); p != -1 {
+			number = number[:p]
+		}
+		parts := strings.Split(number, ".")
+		targets := []*int{&v.Major, &v.Minor, &v.Patch}
+		for i := 0; i < len(parts) && i < len(targets); i++ {
+			n, err := strconv.Atoi(parts[i])
+			if err != nil {
+				return git.Version{}, fmt.Errorf("unrecognized ssh version output: %q", strings.TrimSpace(output))
+			}
+			*targets[i] = n
+		}
+		return v, nil
+	}
+	return git.Version{}, fmt.Errorf("unrecognized ssh version output: %q", strings.TrimSpace(output))
+}
+
+// Supported reports whether every requirement of f is met
+func Supported(f Feature) bool {
+	return Require(f) == nil
+}
+
+// Require returns an error naming the tool to upgrade when f is not
+// supported by the installed versions
+func Require(f Feature) error {
+	for _, req := range f.Requires {
+		version, err := Version(req.Tool)
+		if err != nil {
+			return fmt.Errorf("%s needs %s %s or newer, and its version could not be detected: %w", f.Name, req.Tool, req.Min, err)
+		}
+		if err := check(f, req, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check compares one requirement with an installed version
+func check(f Feature, req Requirement, version git.Version) error {
+	if version.AtLeast(req.Min) {
+		return nil
+	}
+	return fmt.Errorf("%s needs %s %s or newer (found %s)", f.Name, req.Tool, req.Min, version)
+}
@@ -0,0 +1,61 @@
+package compat
+
+import (
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/git"
+)
+
+func TestParseOpenSSHVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected git.Version
+		hasErr   bool
+	}{
+		{"OpenSSH_9.2p1 Debian-2+deb12u3, OpenSSL 3.0.15 3 Sep 2024", git.Version{Major: 9, Minor: 2}, false},
+		{"OpenSSH_8.1p1, LibreSSL 2.7.3", git.Version{Major: 8, Minor: 1}, false},
+		{"OpenSSH_for_Windows_8.6p1, LibreSSL 3.4.3", git.Version{Major: 8, Minor: 6}, false},
+		{"OpenSSH_7.4", git.Version{Major: 7, Minor: 4}, false},
+		{"Sun_SSH_1.1", git.Version{}, true},
+		{"", git.Version{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseOpenSSHVersion(tt.input)
+
+			if tt.hasErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	req := SSHSigning.Requires[1]
+
+	if err := check(SSHSigning, req, git.Version{Major: 8, Minor: 2}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := check(SSHSigning, req, git.Version{Major: 7, Minor: 4})
+	if err == nil {
+		t.Fatalf("expected error but got none")
+	}
+	expected := "SSH commit signing needs OpenSSH 8.2.0 or newer (found 7.4.0)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
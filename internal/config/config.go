@@ -1,32 +1,353 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/gitworkspaces/gitws/internal/fsutil"
 	"gopkg.in/yaml.v3"
 )
 
 // Workspace represents a git workspace configuration
 type Workspace struct {
-	Email    string `yaml:"email"`
-	Provider string `yaml:"provider"`  // "github"|"gitlab"|"bitbucket"|"" if custom
-	HostName string `yaml:"host_name"` // fqdn
-	SSHAlias string `yaml:"ssh_alias"`
-	SSHKey   string `yaml:"ssh_key"`
-	Root     string `yaml:"root"`
-	Signing  string `yaml:"signing"` // "none"|"ssh"|"gpg"
-	Name     string `yaml:"name"`
+	// Email is the address commits use
+	Email    string `yaml:"email,omitempty"`
+	Provider string `yaml:"provider,omitempty"`  // "github"|"gitlab"|"bitbucket"|"" if custom
+	HostName string `yaml:"host_name,omitempty"` // fqdn
+	SSHAlias string `yaml:"ssh_alias,omitempty"`
+	SSHKey   string `yaml:"ssh_key,omitempty"`
+	Root     string `yaml:"root,omitempty"`
+	Signing  string `yaml:"signing,omitempty"` // "none"|"ssh"|"gpg"
+	Name     string `yaml:"name,omitempty"`
+	GPGKey   string `yaml:"gpg_key,omitempty"`
+	// PrimaryEmail is the account's own address when Email is the
+	// provider's noreply address
+	PrimaryEmail string `yaml:"primary_email,omitempty"`
+	// EnforceNoreply requires commits to use the noreply address: hooks
+	// block any other, and 'gitws email use-noreply --enforce' sets it as
+	// user.email in every repository
+	EnforceNoreply bool `yaml:"enforce_noreply,omitempty"`
+	// Isolation is "gitdir" (default) or "hasconfig"
+	Isolation string `yaml:"isolation,omitempty"`
+	// Transport is "ssh" (default) or "https"; https repositories
+	// authenticate with the workspace token through 'gitws credential-helper'
+	Transport     string         `yaml:"transport,omitempty"`
+	DefaultBranch string         `yaml:"default_branch,omitempty"`
+	Templates     *RepoTemplates `yaml:"templates,omitempty"`
+	// PullStrategy is "merge", "rebase" or "ff-only"; empty leaves git's default
+	PullStrategy   string `yaml:"pull_strategy,omitempty"`
+	CommitTemplate string `yaml:"commit_template,omitempty"`
+	// Ignore and Attributes are the workspace's excludes and gitattributes
+	// lines, written to ~/.gws/<workspace>/
+	Ignore     []string `yaml:"ignore,omitempty"`
+	Attributes []string `yaml:"attributes,omitempty"`
+	// KeySource records where an externally provisioned key came from
+	KeySource *KeySource `yaml:"key_source,omitempty"`
+	// Hooks is the policy enforced by hooks installed with 'gitws hooks'
+	Hooks *HookPolicy `yaml:"hooks,omitempty"`
+	// RetiredAliases are former SSH aliases kept working after a rename
+	RetiredAliases []RetiredAlias `yaml:"retired_aliases,omitempty"`
+	// MaxKeyAge is how long an SSH key may be used before it is due for
+	// rotation, e.g. "90d"; empty means no limit
+	MaxKeyAge string `yaml:"max_key_age,omitempty"`
+	// KeyCreatedAt is when the current SSH key was generated or imported
+	KeyCreatedAt time.Time `yaml:"key_created_at,omitempty"`
+	// Clone holds the defaults for 'gitws clone' in this workspace
+	Clone *CloneDefaults `yaml:"clone,omitempty"`
+	// WorktreeLayout is where 'gitws worktree add' puts worktrees, with
+	// {root}, {org}, {repo} and {branch} placeholders
+	WorktreeLayout string `yaml:"worktree_layout,omitempty"`
+	// CloneLayout is where the workspace's repositories live under its
+	// root, with {root}, {host}, {org} and {repo} placeholders; empty
+	// means {root}/{org}/{repo}
+	CloneLayout string `yaml:"clone_layout,omitempty"`
+	// BackupDir is where 'gitws backup' keeps mirrors of the workspace's
+	// repositories; empty means ~/.gws/backups/<workspace>
+	BackupDir string `yaml:"backup_dir,omitempty"`
+	// Env holds extra variables exported by 'gitws env'; values are
+	// expanded by the shell, so they can reference secrets
+	Env map[string]string `yaml:"env,omitempty"`
+	// EnvFile is "envrc" to keep a managed .envrc at the workspace root
+	EnvFile string `yaml:"env_file,omitempty"`
+	// Identities are further accounts on the workspace's host, e.g. a bot
+	// next to the human account, each with its own key and alias
+	Identities map[string]Identity `yaml:"identities,omitempty"`
+	// Hosts are further hosts the workspace's repositories live on, e.g.
+	// a self-hosted GitLab next to github.com, reached with the same key
+	Hosts []HostAlias `yaml:"hosts,omitempty"`
+	// Suppress lists doctor issue codes accepted for every repository in
+	// the workspace, e.g. GWS-HOOKS-002
+	Suppress []string `yaml:"suppress,omitempty"`
+	// ForbiddenEmails are addresses and domains (gmail.com) the workspace
+	// must never commit as; hooks block them and 'gitws audit' finds them
+	// in history
+	ForbiddenEmails []string `yaml:"forbidden_emails,omitempty"`
+}
+
+// HostAlias is an additional host of a workspace and the SSH alias its
+// repositories use
+type HostAlias struct {
+	HostName string `yaml:"host_name,omitempty"`
+	SSHAlias string `yaml:"ssh_alias,omitempty"`
+}
+
+// HostBlockName returns the name an extra host's SSH block is managed
+// under, e.g. "work@gitlab.acme.com"
+func HostBlockName(workspace, host string) string {
+	return workspace + "@" + host
+}
+
+// HostAliases maps each host the workspace uses to the SSH alias that
+// reaches it
+func (w Workspace) HostAliases() map[string]string {
+	aliases := map[string]string{w.HostName: w.SSHAlias}
+	for _, h := range w.Hosts {
+		aliases[h.HostName] = h.SSHAlias
+	}
+	return aliases
+}
+
+// HostNames returns the workspace's host followed by its extra hosts
+func (w Workspace) HostNames() []string {
+	hosts := []string{w.HostName}
+	for _, h := range w.Hosts {
+		hosts = append(hosts, h.HostName)
+	}
+	return hosts
+}
+
+// Aliases returns every SSH alias of the workspace: its own, its extra
+// hosts' and its identities'
+func (w Workspace) Aliases() []string {
+	aliases := []string{w.SSHAlias}
+	for _, h := range w.Hosts {
+		aliases = append(aliases, h.SSHAlias)
+	}
+	ids := make([]string, 0, len(w.Identities))
+	for name := range w.Identities {
+		ids = append(ids, name)
+	}
+	sort.Strings(ids)
+	for _, name := range ids {
+		aliases = append(aliases, w.Identities[name].SSHAlias)
+	}
+	return aliases
+}
+
+// Identity is an additional account within a workspace. Repositories
+// whose origin uses its alias commit as it and authenticate with its key;
+// everything else comes from the workspace.
+type Identity struct {
+	Email        string    `yaml:"email,omitempty"`
+	Name         string    `yaml:"name,omitempty"` // empty uses the workspace's
+	SSHAlias     string    `yaml:"ssh_alias,omitempty"`
+	SSHKey       string    `yaml:"ssh_key,omitempty"`
+	KeyCreatedAt time.Time `yaml:"key_created_at,omitempty"`
+}
+
+// IdentityName returns the name an identity's SSH block and key are
+// managed under, e.g. "work:bot"
+func IdentityName(workspace, identity string) string {
+	return workspace + ":" + identity
+}
+
+// ForIdentity returns the workspace as seen by repositories using the
+// named identity. "" is the workspace's own identity.
+func (w Workspace) ForIdentity(name string) (Workspace, error) {
+	if name == "" {
+		return w, nil
+	}
+	id, exists := w.Identities[name]
+	if !exists {
+		return w, fmt.Errorf("identity %q not found", name)
+	}
+	w.Email = id.Email
+	w.PrimaryEmail = ""
+	w.EnforceNoreply = false
+	if id.Name != "" {
+		w.Name = id.Name
+	}
+	w.SSHAlias = id.SSHAlias
+	w.SSHKey = id.SSHKey
+	w.KeyCreatedAt = id.KeyCreatedAt
+	w.RetiredAliases = nil
+	w.Identities = nil
+	// Identities are accounts on the workspace's own host
+	w.Hosts = nil
+	return w, nil
+}
+
+// AccountEmail returns the account's own address: the primary email when
+// commits use a noreply address, otherwise the commit email
+func (w Workspace) AccountEmail() string {
+	if w.PrimaryEmail != "" {
+		return w.PrimaryEmail
+	}
+	return w.Email
+}
+
+// IdentityForAlias returns the identity whose SSH alias is alias, or ""
+func (w Workspace) IdentityForAlias(alias string) string {
+	if alias == "" {
+		return ""
+	}
+	for name, id := range w.Identities {
+		if id.SSHAlias == alias {
+			return name
+		}
+	}
+	return ""
+}
+
+// CloneDefaults are the clone options used unless overridden by flags
+type CloneDefaults struct {
+	Depth             int      `yaml:"depth,omitempty"`
+	Filter            string   `yaml:"filter,omitempty"` // e.g. "blob:none"
+	Sparse            []string `yaml:"sparse,omitempty"` // sparse-checkout directories
+	RecurseSubmodules bool     `yaml:"recurse_submodules,omitempty"`
+}
+
+// RetiredAlias is an old SSH alias that still resolves until Until
+type RetiredAlias struct {
+	Alias string    `yaml:"alias,omitempty"`
+	Until time.Time `yaml:"until,omitempty"`
+}
+
+// SSHHosts returns the Host patterns for the workspace's SSH block: its
+// alias followed by any retired aliases still within their grace period
+func (w Workspace) SSHHosts(now time.Time) string {
+	hosts := []string{w.SSHAlias}
+	for _, retired := range w.RetiredAliases {
+		if now.Before(retired.Until) {
+			hosts = append(hosts, retired.Alias)
+		}
+	}
+	return strings.Join(hosts, " ")
+}
+
+// HookPolicy is a workspace's policy pack for repository hooks
+type HookPolicy struct {
+	ProtectedBranches []string `yaml:"protected_branches,omitempty"`
+	IssuePattern      string   `yaml:"issue_pattern,omitempty"` // regexp
+	MaxFileSize       string   `yaml:"max_file_size,omitempty"` // e.g. "5MB"
+	ForbidForcePush   bool     `yaml:"forbid_force_push,omitempty"`
+}
+
+// KeySource is the provenance of a key imported with 'gitws init --key-file'
+type KeySource struct {
+	Path        string    `yaml:"path,omitempty"`
+	Mode        string    `yaml:"mode,omitempty"` // "copy"|"reference"
+	Type        string    `yaml:"type,omitempty"`
+	Fingerprint string    `yaml:"fingerprint,omitempty"`
+	ImportedAt  time.Time `yaml:"imported_at,omitempty"`
+}
+
+// RepoTemplates configures the files added to repositories created with
+// 'gitws new'. Each value is a path to a text/template file.
+type RepoTemplates struct {
+	Readme    string `yaml:"readme,omitempty"`
+	License   string `yaml:"license,omitempty"`
+	Gitignore string `yaml:"gitignore,omitempty"`
+}
+
+// NotifySink is a webhook that receives gitws events
+type NotifySink struct {
+	Type   string   `yaml:"type,omitempty"` // "slack"|"teams"|"webhook"
+	URL    string   `yaml:"url,omitempty"`
+	Events []string `yaml:"events,omitempty"` // empty means every event
+}
+
+// Theme holds the colors of styled output, each an ANSI color number
+// (0-255) or a hex color such as #ff8700. Empty keeps the default.
+type Theme struct {
+	Title   string `yaml:"title,omitempty"`
+	Success string `yaml:"success,omitempty"`
+	Warning string `yaml:"warning,omitempty"`
+	Error   string `yaml:"error,omitempty"`
+	Info    string `yaml:"info,omitempty"`
+	Border  string `yaml:"border,omitempty"`
+	Muted   string `yaml:"muted,omitempty"`
+}
+
+// Timeouts bound how long gitws waits for each kind of operation, as
+// durations such as 90s or 30m. Empty keeps the default and 0 waits
+// indefinitely.
+type Timeouts struct {
+	Clone string `yaml:"clone,omitempty"` // clones and new mirrors
+	Fetch string `yaml:"fetch,omitempty"` // mirror updates
+	Push  string `yaml:"push,omitempty"`
+	API   string `yaml:"api,omitempty"` // provider API calls
+	SSH   string `yaml:"ssh,omitempty"` // SSH connection tests and ssh-keyscan
 }
 
 // File represents the complete configuration file
 type File struct {
 	Workspaces map[string]Workspace `yaml:"workspaces"`
+	// GlobalGuard is the unclassified-repo guard mode: "" (off), "warn" or "block"
+	GlobalGuard string `yaml:"global_guard,omitempty"`
+	// Notify lists webhooks told about key rotations and policy events
+	Notify []NotifySink `yaml:"notify,omitempty"`
+	// Stats opts in to local usage statistics (~/.gws/stats.json)
+	Stats bool `yaml:"stats,omitempty"`
+	// Language selects the language of gitws's output (en, es, de, ja);
+	// empty follows LC_ALL, LC_MESSAGES and LANG
+	Language string `yaml:"language,omitempty"`
+	// NoColor and NoEmoji are the defaults of --no-color and --no-emoji
+	NoColor bool `yaml:"no_color,omitempty"`
+	NoEmoji bool `yaml:"no_emoji,omitempty"`
+	// Theme overrides the colors of styled output
+	Theme Theme `yaml:"theme,omitempty"`
+	// Timeouts override how long operations may take
+	Timeouts Timeouts `yaml:"timeouts,omitempty"`
+	// AuditChain hash-chains the entries of the audit log
+	// (~/.gws/audit.log), so editing or deleting one can be detected
+	AuditChain bool `yaml:"audit_chain,omitempty"`
+	// GitPath selects the git binary when several are installed; GWS_GIT
+	// overrides it
+	GitPath string `yaml:"git_path,omitempty"`
+	// GitBackend selects how repositories, git config and history are
+	// read: auto (the default) runs git when it is installed and uses
+	// go-git otherwise, exec always runs git, native uses go-git for
+	// config, remotes, worktrees and the commit scans of audit, check and
+	// the hooks. Changes, and reads go-git cannot do, always run git.
+	GitBackend string `yaml:"git_backend,omitempty"`
+	// Orgs maps ORG/REPO patterns (myemployer/*) to the workspace that owns
+	// them, for hosts several workspaces share
+	Orgs map[string]string `yaml:"orgs,omitempty"`
+
+	// doc is config.yaml as last read or written, kept so Save can carry
+	// hand-written comments over to the regenerated file
+	doc *yaml.Node
 }
 
-// ConfigDir returns the configuration directory path
+// ConfigDir returns the state directory: $GWS_HOME, the per-user
+// state_dir of a machine-wide install, or ~/.gws
 func ConfigDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+
+	system, err := LoadSystem()
+	if err != nil {
+		return "", err
+	}
+	dir, err := system.UserStateDir()
+	if err != nil {
+		return "", err
+	}
+	if dir != "" {
+		if err := CheckStateDir(dir); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -43,6 +364,47 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
+// loadCache is the last valid config.yaml Load parsed, reused while the
+// file is unchanged once CacheLoads is called
+var loadCache struct {
+	sync.Mutex
+	enabled bool
+	path    string
+	info    os.FileInfo
+	doc     *yaml.Node
+}
+
+// CacheLoads makes Load skip parsing and validating config.yaml while the
+// file, its size and modification time stay the same, for long-running
+// processes that load it on every request. Save replaces the file, so a
+// save is always noticed.
+func CacheLoads() {
+	loadCache.Lock()
+	defer loadCache.Unlock()
+	loadCache.enabled = true
+}
+
+// cachedDoc returns the cached parse of path if the file has not changed
+func cachedDoc(path string, info os.FileInfo) *yaml.Node {
+	loadCache.Lock()
+	defer loadCache.Unlock()
+	cached := loadCache.info
+	if !loadCache.enabled || loadCache.path != path || cached == nil ||
+		!os.SameFile(info, cached) || !info.ModTime().Equal(cached.ModTime()) || info.Size() != cached.Size() {
+		return nil
+	}
+	return loadCache.doc
+}
+
+// storeDoc caches a valid parse of path
+func storeDoc(path string, info os.FileInfo, doc *yaml.Node) {
+	loadCache.Lock()
+	defer loadCache.Unlock()
+	if loadCache.enabled {
+		loadCache.path, loadCache.info, loadCache.doc = path, info, doc
+	}
+}
+
 // Load loads the configuration from disk
 func Load() (*File, error) {
 	path, err := ConfigPath()
@@ -50,6 +412,22 @@ func Load() (*File, error) {
 		return nil, err
 	}
 
+	info, err := os.Stat(path)
+	if err == nil {
+		if doc := cachedDoc(path, info); doc != nil {
+			// Decoding builds fresh maps and slices, so callers may change
+			// the result; the node itself is only read from here on
+			var config File
+			if err := doc.Decode(&config); err == nil {
+				config.doc = doc
+				if config.Workspaces == nil {
+					config.Workspaces = make(map[string]Workspace)
+				}
+				return &config, nil
+			}
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -58,10 +436,23 @@ func Load() (*File, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config File
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	var config File
+	if doc.Kind != 0 {
+		if err := doc.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if problems := validate(&doc, &config); len(problems) > 0 {
+			return nil, &ValidationError{Path: path, Problems: problems}
+		}
+		config.doc = &doc
+		if info != nil {
+			storeDoc(path, info, &doc)
+		}
+	}
 
 	if config.Workspaces == nil {
 		config.Workspaces = make(map[string]Workspace)
@@ -70,6 +461,45 @@ func Load() (*File, error) {
 	return &config, nil
 }
 
+// LoadLocked loads the configuration and holds the config lock until
+// unlock is called, so another gitws process cannot write config.yaml
+// between this load and the following Save
+func LoadLocked() (f *File, unlock func(), err error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	lock, err := fsutil.LockFile(path, fsutil.LockTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err = Load()
+	if err != nil {
+		lock.Unlock()
+		return nil, nil, err
+	}
+	return f, func() { lock.Unlock() }, nil
+}
+
+// Update loads the configuration, applies change and saves the result,
+// all under the config lock
+func Update(change func(*File) error) error {
+	f, unlock, err := LoadLocked()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := change(f); err != nil {
+		return err
+	}
+	return f.Save()
+}
+
 // Save saves the configuration to disk
 func (f *File) Save() error {
 	path, err := ConfigPath()
@@ -83,18 +513,98 @@ func (f *File) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(f)
-	if err != nil {
+	// Encoding sorts map keys, so workspaces come out in name order
+	var body yaml.Node
+	if err := body.Encode(f); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&body}}
+	if f.doc != nil {
+		mergeComments(doc, f.doc)
+	}
+	// yaml.Marshal indents by four; config.yaml is written by hand with
+	// two, so keep that rather than re-indent the whole file on save
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	data := buf.Bytes()
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// The config holds emails and paths, so new files are private. A mode
+	// the user chose is kept, except the world-readable 0644 older
+	// releases wrote by default.
+	perm := fsutil.ExistingMode(path, 0600)
+	if perm == 0644 {
+		perm = 0600
+	}
+	if err := fsutil.AtomicWrite(path, data, perm); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	f.doc = doc
 	return nil
 }
 
+// mergeComments copies the comments in src onto the matching nodes of
+// dst. Mapping entries are matched by key and sequence items by value,
+// falling back to position, so comments follow the settings they
+// describe even when entries are added or removed.
+func mergeComments(dst, src *yaml.Node) {
+	if dst.HeadComment == "" {
+		dst.HeadComment = src.HeadComment
+	}
+	if dst.LineComment == "" {
+		dst.LineComment = src.LineComment
+	}
+	if dst.FootComment == "" {
+		dst.FootComment = src.FootComment
+	}
+	if dst.Kind != src.Kind {
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.DocumentNode:
+		if len(dst.Content) > 0 && len(src.Content) > 0 {
+			mergeComments(dst.Content[0], src.Content[0])
+		}
+	case yaml.MappingNode:
+		entries := make(map[string]int, len(src.Content)/2)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			entries[src.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if j, ok := entries[dst.Content[i].Value]; ok {
+				mergeComments(dst.Content[i], src.Content[j])
+				mergeComments(dst.Content[i+1], src.Content[j+1])
+			}
+		}
+	case yaml.SequenceNode:
+		used := make(map[int]bool)
+		for i, item := range dst.Content {
+			match := -1
+			for j, old := range src.Content {
+				if !used[j] && old.Kind == yaml.ScalarNode && old.Value == item.Value {
+					match = j
+					break
+				}
+			}
+			if match < 0 && item.Kind != yaml.ScalarNode && i < len(src.Content) && !used[i] {
+				match = i
+			}
+			if match >= 0 {
+				used[match] = true
+				mergeComments(item, src.Content[match])
+			}
+		}
+	}
+}
+
 // GetWorkspace returns a workspace by name
 func (f *File) GetWorkspace(name string) (Workspace, bool) {
 	ws, exists := f.Workspaces[name]
@@ -114,11 +624,12 @@ func (f *File) DeleteWorkspace(name string) {
 	delete(f.Workspaces, name)
 }
 
-// ListWorkspaces returns all workspace names
+// ListWorkspaces returns all workspace names in sorted order
 func (f *File) ListWorkspaces() []string {
 	var names []string
 	for name := range f.Workspaces {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveKeepsComments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	path := filepath.Join(home, "config.yaml")
+	original := `# Managed by hand; gitws keeps these comments
+workspaces:
+  # Day job
+  work:
+    email: me@work.com # the verified address
+    provider: github
+    ssh_alias: github-com-work
+    suppress:
+      # Reviewed with security
+      - GWS-HOOKS-002
+language: de # for the team
+`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.SetWorkspace("oss", Workspace{Email: "me@oss.org", Provider: "github", SSHAlias: "github-com-oss"})
+	if err := f.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+	for _, expected := range []string{
+		"# Managed by hand; gitws keeps these comments\n",
+		"  # Day job\n  work:\n",
+		"    email: me@work.com # the verified address\n",
+		"      # Reviewed with security\n      - GWS-HOOKS-002\n",
+		"language: de # for the team\n",
+		"  oss:\n    email: me@oss.org\n",
+	} {
+		if !strings.Contains(saved, expected) {
+			t.Errorf("expected %q in the saved file, got:\n%s", expected, saved)
+		}
+	}
+	for _, unexpected := range []string{"host_name:", "ssh_key:", "root:", `""`} {
+		if strings.Contains(saved, unexpected) {
+			t.Errorf("expected empty fields to be omitted, got %q in:\n%s", unexpected, saved)
+		}
+	}
+
+	// A second round trip changes nothing
+	f, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != saved {
+		t.Errorf("expected a second save to keep the file, got:\n%s\nthen:\n%s", saved, again)
+	}
+}
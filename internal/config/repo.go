@@ -0,0 +1,214 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/guard"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoFileName is the optional per-repository settings file, kept at the
+// top of the working tree
+const RepoFileName = ".gitws.yaml"
+
+// TrustFileName is the file in the gitws home recording the .gitws.yaml
+// files the user trusts, by repository and content hash
+const TrustFileName = "trusted-repos.json"
+
+// Repo is a repository's .gitws.yaml. It is committed with the
+// repository, so it names workspaces and identities but holds no secrets.
+// Anyone who can push to the repository can change it, so it only applies
+// once the user trusts its current content with 'gitws trust'.
+type Repo struct {
+	// Workspace pins the workspace the repository belongs to, instead of
+	// inferring it from the remote and location
+	Workspace string `yaml:"workspace,omitempty"`
+	// Identity selects one of the workspace's identities
+	Identity string `yaml:"identity,omitempty"`
+	// DisableGuards lists guard rules not enforced in this repository,
+	// e.g. large-file. The identity and alias rules cannot be disabled.
+	DisableGuards []string `yaml:"disable_guards,omitempty"`
+	// Unmanaged declares an external repository gitws leaves alone
+	Unmanaged bool `yaml:"unmanaged,omitempty"`
+	// Suppress lists doctor issue codes accepted for this repository
+	Suppress []string `yaml:"suppress,omitempty"`
+
+	// Untrusted is set when the repository has a .gitws.yaml the user has
+	// not trusted, or that changed since; none of its settings apply
+	Untrusted bool `yaml:"-"`
+}
+
+// GuardDisabled reports whether the guard rule is disabled for the
+// repository. Mandatory rules never are.
+func (r *Repo) GuardDisabled(rule string) bool {
+	if guard.IsMandatory(rule) {
+		return false
+	}
+	for _, disabled := range r.DisableGuards {
+		if strings.EqualFold(disabled, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRepo returns the settings of the .gitws.yaml at gitRoot that apply:
+// all of them when the user trusts its content, none otherwise, with
+// Untrusted set. A missing file is an empty one.
+func LoadRepo(gitRoot string) (*Repo, error) {
+	data, err := readRepoFile(gitRoot)
+	if err != nil || data == nil {
+		return &Repo{}, err
+	}
+
+	trusted, err := RepoTrusted(gitRoot, data)
+	if err != nil {
+		return nil, err
+	}
+	if !trusted {
+		return &Repo{Untrusted: true}, nil
+	}
+	return parseRepo(gitRoot, data)
+}
+
+// ReadRepo reads the .gitws.yaml at gitRoot whether or not it is trusted,
+// for validating and showing it. A missing file is an empty one; unknown
+// keys are an error, so typos do not go unnoticed.
+func ReadRepo(gitRoot string) (*Repo, error) {
+	data, err := readRepoFile(gitRoot)
+	if err != nil || data == nil {
+		return &Repo{}, err
+	}
+	return parseRepo(gitRoot, data)
+}
+
+// readRepoFile returns the content of the .gitws.yaml at gitRoot, or nil
+// when there is none
+func readRepoFile(gitRoot string) ([]byte, error) {
+	path := filepath.Join(gitRoot, RepoFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func parseRepo(gitRoot string, data []byte) (*Repo, error) {
+	var repo Repo
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&repo); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(gitRoot, RepoFileName), err)
+	}
+	return &repo, nil
+}
+
+// repoHash identifies the content of a .gitws.yaml
+func repoHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// trustPath returns the path of the trust file
+func trustPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, TrustFileName), nil
+}
+
+// loadTrust reads the trusted content hashes by repository
+func loadTrust() (map[string]string, error) {
+	path, err := trustPath()
+	if err != nil {
+		return nil, err
+	}
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return trusted, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+func saveTrust(trusted map[string]string) error {
+	path, err := trustPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trusted repositories: %w", err)
+	}
+	return fsutil.AtomicWrite(path, append(data, '\n'), 0600)
+}
+
+// RepoTrusted reports whether the user trusts data as the .gitws.yaml of
+// the repository at gitRoot
+func RepoTrusted(gitRoot string, data []byte) (bool, error) {
+	trusted, err := loadTrust()
+	if err != nil {
+		return false, err
+	}
+	return trusted[filepath.Clean(gitRoot)] == repoHash(data), nil
+}
+
+// TrustRepo trusts the current content of the .gitws.yaml at gitRoot.
+// Any later change to the file needs to be trusted again.
+func TrustRepo(gitRoot string) error {
+	data, err := readRepoFile(gitRoot)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("%s has no %s", gitRoot, RepoFileName)
+	}
+	if _, err := parseRepo(gitRoot, data); err != nil {
+		return err
+	}
+
+	trusted, err := loadTrust()
+	if err != nil {
+		return err
+	}
+	trusted[filepath.Clean(gitRoot)] = repoHash(data)
+	return saveTrust(trusted)
+}
+
+// UntrustRepo stops trusting the .gitws.yaml at gitRoot, reporting
+// whether it was trusted
+func UntrustRepo(gitRoot string) (bool, error) {
+	trusted, err := loadTrust()
+	if err != nil {
+		return false, err
+	}
+	key := filepath.Clean(gitRoot)
+	if _, ok := trusted[key]; !ok {
+		return false, nil
+	}
+	delete(trusted, key)
+	return true, saveTrust(trusted)
+}
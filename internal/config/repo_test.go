@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRepoTrust(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	gitRoot := t.TempDir()
+	path := filepath.Join(gitRoot, RepoFileName)
+	if err := os.WriteFile(path, []byte("unmanaged: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := LoadRepo(gitRoot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.Untrusted || repo.Unmanaged {
+		t.Errorf("expected an untrusted file to be ignored, got %+v", repo)
+	}
+
+	if err := TrustRepo(gitRoot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo, err = LoadRepo(gitRoot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.Untrusted || !repo.Unmanaged {
+		t.Errorf("expected a trusted file to apply, got %+v", repo)
+	}
+
+	if err := os.WriteFile(path, []byte("unmanaged: true\ndisable_guards: [large-file]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err = LoadRepo(gitRoot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.Untrusted || repo.Unmanaged {
+		t.Errorf("expected a changed file to be ignored, got %+v", repo)
+	}
+
+	if err := TrustRepo(gitRoot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked, err := UntrustRepo(gitRoot); err != nil || !revoked {
+		t.Fatalf("expected trust to be revoked, got %v, %v", revoked, err)
+	}
+	if repo, err := LoadRepo(gitRoot); err != nil || !repo.Untrusted {
+		t.Errorf("expected the file to be ignored after revoking, got %+v, %v", repo, err)
+	}
+}
+
+func TestLoadRepoMissing(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	repo, err := LoadRepo(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.Untrusted {
+		t.Error("expected a missing file not to be untrusted")
+	}
+}
+
+func TestGuardDisabled(t *testing.T) {
+	repo := &Repo{DisableGuards: []string{"Large-File", "alias", "wrong-identity", "forbidden-email", "unclassified-commit"}}
+
+	tests := []struct {
+		rule     string
+		expected bool
+	}{
+		{"large-file", true},
+		{"missing-issue", false},
+		{"alias", false},
+		{"wrong-identity", false},
+		{"forbidden-email", false},
+		{"unclassified-commit", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			if result := repo.GuardDisabled(tt.rule); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment overrides for where gitws keeps its state
+const (
+	// HomeEnv points gitws at a state directory other than ~/.gws
+	HomeEnv = "GWS_HOME"
+	// SystemConfigEnv points gitws at a system config other than the default
+	SystemConfigEnv = "GWS_SYSTEM_CONFIG"
+)
+
+// System is the machine-wide configuration an administrator provides on
+// shared hosts. Users cannot change it; their own config.yaml takes
+// precedence for everything except StateDir.
+type System struct {
+	// StateDir is the per-user state directory, replacing ~/.gws.
+	// "{user}" is replaced with the login name, e.g. /opt/gitws/users/{user}.
+	StateDir string `yaml:"state_dir,omitempty"`
+	// GitPath is the git binary used when the user sets none
+	GitPath string `yaml:"git_path,omitempty"`
+	// Defaults fills in settings a workspace definition leaves empty
+	Defaults Workspace `yaml:"defaults,omitempty"`
+}
+
+var (
+	systemOnce   sync.Once
+	systemConfig *System
+	systemErr    error
+)
+
+// SystemConfigPath returns the path of the machine-wide config file
+func SystemConfigPath() string {
+	if path := os.Getenv(SystemConfigEnv); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "gitws", "config.yaml")
+	}
+	return "/etc/gitws/config.yaml"
+}
+
+// LoadSystem reads the machine-wide config once per process. A missing
+// file is an empty config.
+func LoadSystem() (*System, error) {
+	systemOnce.Do(func() {
+		systemConfig = &System{}
+
+		data, err := os.ReadFile(SystemConfigPath())
+		if err != nil {
+			if !os.IsNotExist(err) {
+				systemErr = fmt.Errorf("failed to read system config: %w", err)
+			}
+			return
+		}
+		if err := yaml.Unmarshal(data, systemConfig); err != nil {
+			systemErr = fmt.Errorf("failed to parse system config %s: %w", SystemConfigPath(), err)
+		}
+	})
+	return systemConfig, systemErr
+}
+
+// UserStateDir returns StateDir for the current user, or "" when the
+// system config does not set one
+func (s *System) UserStateDir() (string, error) {
+	if s.StateDir == "" {
+		return "", nil
+	}
+	if !strings.Contains(s.StateDir, "{user}") {
+		return "", fmt.Errorf("system config state_dir %q must contain {user} to keep users apart", s.StateDir)
+	}
+
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if name == "" {
+		return "", fmt.Errorf("failed to determine user name for state_dir")
+	}
+	// Windows user names are DOMAIN\name
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+
+	return strings.ReplaceAll(s.StateDir, "{user}", name), nil
+}
+
+// CheckStateDir refuses a per-user state directory that another user
+// owns, that is a symlink, or that anyone but its owner can read or
+// write: it sits under a base every user can reach, and whoever controls
+// it controls the user's SSH and git configuration. A directory that does
+// not exist yet is fine; it is created with mode 0700.
+func CheckStateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check state directory: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 || !info.IsDir() {
+		return fmt.Errorf("state directory %s is not a directory; remove it so gitws can create it", dir)
+	}
+	if owned, ok := fsutil.OwnedByCurrentUser(dir); ok && !owned {
+		return fmt.Errorf("state directory %s belongs to another user; ask your administrator to remove it", dir)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		return fmt.Errorf("state directory %s has mode %04o, expected 0700; run 'chmod 700 %s'", dir, info.Mode().Perm(), dir)
+	}
+	return nil
+}
+
+// ApplyDefaults fills the empty settings of a workspace definition from
+// the system defaults
+func (s *System) ApplyDefaults(ws Workspace) Workspace {
+	d := s.Defaults
+	if ws.Provider == "" && ws.HostName == "" {
+		ws.Provider, ws.HostName = d.Provider, d.HostName
+	}
+	fill := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	fill(&ws.Signing, d.Signing)
+	fill(&ws.Isolation, d.Isolation)
+	fill(&ws.DefaultBranch, d.DefaultBranch)
+	fill(&ws.PullStrategy, d.PullStrategy)
+	fill(&ws.CommitTemplate, d.CommitTemplate)
+	fill(&ws.MaxKeyAge, d.MaxKeyAge)
+	fill(&ws.WorktreeLayout, d.WorktreeLayout)
+	fill(&ws.CloneLayout, d.CloneLayout)
+	fill(&ws.EnvFile, d.EnvFile)
+	if ws.Ignore == nil {
+		ws.Ignore = d.Ignore
+	}
+	if ws.Attributes == nil {
+		ws.Attributes = d.Attributes
+	}
+	if ws.Templates == nil {
+		ws.Templates = d.Templates
+	}
+	if ws.Hooks == nil {
+		ws.Hooks = d.Hooks
+	}
+	if ws.Clone == nil {
+		ws.Clone = d.Clone
+	}
+	if ws.Env == nil {
+		ws.Env = d.Env
+	}
+	return ws
+}
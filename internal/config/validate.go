@@ -0,0 +1,473 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gitworkspaces/gitws/internal/email"
+	"github.com/gitworkspaces/gitws/internal/git"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/layout"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one schema violation in config.yaml
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// ValidationError reports every problem found in config.yaml at once
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config file %s:", e.Path)
+	for _, p := range e.Problems {
+		if p.Line > 0 {
+			fmt.Fprintf(&b, "\n  line %d: %s", p.Line, p.Message)
+		} else {
+			fmt.Fprintf(&b, "\n  %s", p.Message)
+		}
+	}
+	return b.String()
+}
+
+// issueCodePattern matches doctor issue codes
+var issueCodePattern = regexp.MustCompile(`(?i)^GWS-[A-Z]+-[0-9]{3}$`)
+
+// colorPattern matches theme colors: an ANSI color number or a hex color
+var colorPattern = regexp.MustCompile(`^([0-9]{1,3}|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+
+// workspaceNamePattern is what workspace and identity names may look
+// like: they name key files, gitconfigs and managed blocks, so they must
+// not carry path separators, whitespace or the @ and : of block names
+var workspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks a workspace or identity name
+func ValidateName(name string) error {
+	if !workspaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_' and '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// reservedNames are the entries gitws keeps in its own directory next to
+// the per-workspace directories, which a workspace of the same name would
+// write into. Compared case-insensitively for case-insensitive filesystems.
+var reservedNames = map[string]bool{
+	"gitconfig":   true,
+	"hooks":       true,
+	"secrets":     true,
+	"backups":     true,
+	"state":       true,
+	"gpg":         true,
+	"config.yaml": true,
+	"stats.json":  true,
+	"cache.json":  true,
+	"gitws.sock":  true,
+	"audit.log":   true,
+	TrustFileName: true,
+}
+
+// IsReservedName reports whether name is one of the entries gitws keeps
+// in its own directory
+func IsReservedName(name string) bool {
+	return reservedNames[strings.ToLower(name)]
+}
+
+// ValidateWorkspaceName checks a workspace name, which also names its
+// directory under the gitws directory
+func ValidateWorkspaceName(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if IsReservedName(name) {
+		return fmt.Errorf("invalid name %q: gitws uses it for its own files", name)
+	}
+	return nil
+}
+
+// ValidSSHValue reports whether value can be written to ~/.ssh/config as
+// a host name or alias: whitespace or a control character would end the
+// value and let the rest be read as another option
+func ValidSSHValue(value string) bool {
+	return !strings.ContainsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// validPath reports whether path holds no control characters, which
+// would break the line it is written on
+func validPath(path string) bool {
+	return !strings.ContainsFunc(path, unicode.IsControl)
+}
+
+// Validate checks f as Load checks config.yaml, for configs that were not
+// read from it, e.g. one imported from an archive. Problems carry no line
+// numbers.
+func Validate(f *File) error {
+	var node yaml.Node
+	if err := node.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&node}}
+	if problems := validate(doc, f); len(problems) > 0 {
+		return &ValidationError{Path: "(imported)", Problems: problems}
+	}
+	return nil
+}
+
+// validSigning are the accepted values of a workspace's signing setting
+var validSigning = map[string]bool{"": true, "none": true, "ssh": true, "gpg": true}
+
+// validTransport are the accepted values of a workspace's transport setting
+var validTransport = map[string]bool{"": true, "ssh": true, "https": true}
+
+// validate checks a decoded config against the document it came from, so
+// problems can be reported with line numbers
+func validate(doc *yaml.Node, f *File) []Problem {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Problem{{Line: root.Line, Message: "expected a mapping with a workspaces key"}}
+	}
+
+	var problems []Problem
+	unknownKeys(root, reflect.TypeOf(File{}), "", &problems)
+
+	system, err := LoadSystem()
+	if err != nil {
+		system = &System{}
+	}
+	workspaces := mappingValue(root, "workspaces")
+
+	roots := make(map[string]string)
+	aliases := make(map[string]string)
+	for _, name := range f.ListWorkspaces() {
+		node := mappingValue(workspaces, name)
+		line := keyLine(workspaces, name)
+		at := func(field string) int {
+			if l := keyLine(node, field); l > 0 {
+				return l
+			}
+			return line
+		}
+		ws := system.ApplyDefaults(f.Workspaces[name])
+
+		if err := ValidateWorkspaceName(name); err != nil {
+			problems = append(problems, Problem{line, fmt.Sprintf("workspace %q: %v", name, err)})
+		}
+		for field, value := range map[string]string{"host_name": ws.HostName, "ssh_alias": ws.SSHAlias} {
+			if !ValidSSHValue(value) {
+				problems = append(problems, Problem{at(field), fmt.Sprintf("workspace %q: %s %q must not contain whitespace or control characters", name, field, value)})
+			}
+		}
+		if !validPath(ws.SSHKey) {
+			problems = append(problems, Problem{at("ssh_key"), fmt.Sprintf("workspace %q: ssh_key %q must not contain control characters", name, ws.SSHKey)})
+		}
+
+		if strings.TrimSpace(ws.Email) == "" {
+			problems = append(problems, Problem{at("email"), fmt.Sprintf("workspace %q: email is required", name)})
+		}
+		if ws.Provider == "" && ws.HostName == "" {
+			problems = append(problems, Problem{line, fmt.Sprintf("workspace %q: either provider or host_name is required", name)})
+		}
+		if !validSigning[ws.Signing] {
+			problems = append(problems, Problem{at("signing"), fmt.Sprintf("workspace %q: unknown signing method %q (supported: none, ssh, gpg)", name, ws.Signing)})
+		}
+		if !validTransport[ws.Transport] {
+			problems = append(problems, Problem{at("transport"), fmt.Sprintf("workspace %q: unknown transport %q (supported: ssh, https)", name, ws.Transport)})
+		}
+		if ws.Signing == "gpg" && ws.GPGKey == "" {
+			problems = append(problems, Problem{at("signing"), fmt.Sprintf("workspace %q: gpg_key is required when signing is gpg", name)})
+		}
+
+		if ws.Root != "" {
+			if !isAbsRoot(ws.Root) {
+				problems = append(problems, Problem{at("root"), fmt.Sprintf("workspace %q: root must be an absolute path or start with ~/, got %q", name, ws.Root)})
+			} else {
+				// Nested roots are legal; the same root for two
+				// workspaces can never be told apart
+				key := rootKey(ws.Root)
+				if other, taken := roots[key]; taken {
+					problems = append(problems, Problem{at("root"), fmt.Sprintf("workspace %q: root %s is also the root of workspace %q", name, ws.Root, other)})
+				} else {
+					roots[key] = name
+				}
+			}
+		}
+
+		hosts := []string{ws.SSHAlias}
+		for _, retired := range ws.RetiredAliases {
+			if !ValidSSHValue(retired.Alias) {
+				problems = append(problems, Problem{at("retired_aliases"), fmt.Sprintf("workspace %q: retired alias %q must not contain whitespace or control characters", name, retired.Alias)})
+			}
+			hosts = append(hosts, retired.Alias)
+		}
+		for _, extra := range ws.Hosts {
+			if extra.HostName == "" {
+				problems = append(problems, Problem{at("hosts"), fmt.Sprintf("workspace %q: hosts: host_name is required", name)})
+			}
+			if !ValidSSHValue(extra.HostName) || !ValidSSHValue(extra.SSHAlias) {
+				problems = append(problems, Problem{at("hosts"), fmt.Sprintf("workspace %q: hosts: %q must not contain whitespace or control characters", name, extra.HostName+" "+extra.SSHAlias)})
+			}
+			hosts = append(hosts, extra.SSHAlias)
+		}
+		identities := make([]string, 0, len(ws.Identities))
+		for id := range ws.Identities {
+			identities = append(identities, id)
+		}
+		sort.Strings(identities)
+		for _, id := range identities {
+			identity := ws.Identities[id]
+			if err := ValidateName(id); err != nil {
+				problems = append(problems, Problem{at("identities"), fmt.Sprintf("workspace %q: identity %v", name, err)})
+			}
+			if !ValidSSHValue(identity.SSHAlias) || !validPath(identity.SSHKey) {
+				problems = append(problems, Problem{at("identities"), fmt.Sprintf("workspace %q: identity %q: ssh_alias and ssh_key must not contain control characters, nor the alias whitespace", name, id)})
+			}
+			if strings.TrimSpace(identity.Email) == "" {
+				problems = append(problems, Problem{at("identities"), fmt.Sprintf("workspace %q: identity %q: email is required", name, id)})
+			}
+			if entry, blocked := email.Blocked(identity.Email, ws.ForbiddenEmails); blocked {
+				problems = append(problems, Problem{at("identities"), fmt.Sprintf("workspace %q: identity %q: email %s is forbidden by forbidden_emails entry %q", name, id, identity.Email, entry)})
+			}
+			hosts = append(hosts, identity.SSHAlias)
+		}
+		for _, entry := range ws.ForbiddenEmails {
+			if strings.TrimSpace(entry) == "" || strings.ContainsAny(strings.TrimSpace(entry), " \t,") {
+				problems = append(problems, Problem{at("forbidden_emails"), fmt.Sprintf("workspace %q: forbidden_emails: %q is not an address or domain", name, entry)})
+			}
+		}
+		if entry, blocked := email.Blocked(ws.Email, ws.ForbiddenEmails); blocked {
+			problems = append(problems, Problem{at("email"), fmt.Sprintf("workspace %q: email %s is forbidden by forbidden_emails entry %q", name, ws.Email, entry)})
+		}
+		if ws.CloneLayout != "" {
+			if err := layout.Validate(ws.CloneLayout); err != nil {
+				problems = append(problems, Problem{at("clone_layout"), fmt.Sprintf("workspace %q: %v", name, err)})
+			}
+		}
+		for _, code := range ws.Suppress {
+			if !issueCodePattern.MatchString(code) {
+				problems = append(problems, Problem{at("suppress"), fmt.Sprintf("workspace %q: suppress: %q is not a doctor issue code like GWS-HOOKS-002", name, code)})
+			}
+		}
+		for i, alias := range hosts {
+			if alias == "" {
+				continue
+			}
+			field := "ssh_alias"
+			switch {
+			case i > len(ws.RetiredAliases)+len(ws.Hosts):
+				field = "identities"
+			case i > len(ws.RetiredAliases):
+				field = "hosts"
+			case i > 0:
+				field = "retired_aliases"
+			}
+			if other, taken := aliases[alias]; taken && other != name {
+				problems = append(problems, Problem{at(field), fmt.Sprintf("workspace %q: ssh alias %s is already used by workspace %q", name, alias, other)})
+			} else {
+				aliases[alias] = name
+			}
+		}
+	}
+
+	if f.Language != "" && !i18n.Supported(f.Language) {
+		problems = append(problems, Problem{keyLine(root, "language"), fmt.Sprintf("unknown language %q (supported: %s)", f.Language, strings.Join(i18n.Languages(), ", "))})
+	}
+
+	if f.GitBackend != "" && !git.IsValidBackend(f.GitBackend) {
+		problems = append(problems, Problem{keyLine(root, "git_backend"), fmt.Sprintf("unknown git backend %q (supported: auto, exec, native)", f.GitBackend)})
+	}
+
+	theme := mappingValue(root, "theme")
+	for key, color := range map[string]string{
+		"title": f.Theme.Title, "success": f.Theme.Success, "warning": f.Theme.Warning,
+		"error": f.Theme.Error, "info": f.Theme.Info, "border": f.Theme.Border, "muted": f.Theme.Muted,
+	} {
+		if color == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(color); !colorPattern.MatchString(color) || (err == nil && n > 255) {
+			problems = append(problems, Problem{keyLine(theme, key), fmt.Sprintf("theme: %s: invalid color %q (expected 0-255 or #rrggbb)", key, color)})
+		}
+	}
+
+	timeouts := mappingValue(root, "timeouts")
+	for key, value := range map[string]string{
+		"clone": f.Timeouts.Clone, "fetch": f.Timeouts.Fetch, "push": f.Timeouts.Push,
+		"api": f.Timeouts.API, "ssh": f.Timeouts.SSH,
+	} {
+		if value == "" || value == "0" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			problems = append(problems, Problem{keyLine(timeouts, key), fmt.Sprintf("timeouts: %s: invalid duration %q (expected e.g. 90s or 30m)", key, value)})
+		}
+	}
+
+	orgs := mappingValue(root, "orgs")
+	patterns := make([]string, 0, len(f.Orgs))
+	for pattern := range f.Orgs {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil || strings.Count(pattern, "/") > 1 {
+			problems = append(problems, Problem{keyLine(orgs, pattern), fmt.Sprintf("orgs: invalid pattern %q (expected ORG or ORG/REPO, with * wildcards)", pattern)})
+		}
+		if _, exists := f.Workspaces[f.Orgs[pattern]]; !exists {
+			problems = append(problems, Problem{keyLine(orgs, pattern), fmt.Sprintf("orgs: %s maps to unknown workspace %q", pattern, f.Orgs[pattern])})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// unknownKeys reports mapping keys that do not correspond to a field of
+// typ, descending into nested settings
+func unknownKeys(node *yaml.Node, typ reflect.Type, path string, problems *[]Problem) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if node == nil {
+		return
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return
+	case typ.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			field, known := fields[key]
+			if !known {
+				message := fmt.Sprintf("unknown key %q", path+key)
+				if guess := closestKey(key, fields); guess != "" {
+					message += fmt.Sprintf(" (did you mean %q?)", guess)
+				}
+				*problems = append(*problems, Problem{node.Content[i].Line, message})
+				continue
+			}
+			unknownKeys(node.Content[i+1], field, path+key+".", problems)
+		}
+	case typ.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			unknownKeys(node.Content[i+1], typ.Elem(), path+node.Content[i].Value+".", problems)
+		}
+	case typ.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			unknownKeys(item, typ.Elem(), path, problems)
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct type to their field types
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// closestKey returns the known key nearest to a misspelt one, or "" when
+// none is close
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// mappingValue returns the value stored under key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// keyLine returns the line of key in a mapping node, or 0
+func keyLine(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i].Line
+		}
+	}
+	return 0
+}
+
+// isAbsRoot reports whether a root is absolute once ~ is expanded
+func isAbsRoot(root string) bool {
+	return root == "~" || strings.HasPrefix(root, "~/") || filepath.IsAbs(root)
+}
+
+// rootKey normalises a root so equal directories compare equal
+func rootKey(root string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(root, "~") {
+		root = home + strings.TrimPrefix(root, "~")
+	}
+	return filepath.Clean(root)
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadValidation(t *testing.T) {
+	type problem struct {
+		line    int
+		message string
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		expected []problem
+	}{
+		{
+			name: "valid",
+			config: `workspaces:
+  work:
+    email: me@work.com
+    provider: github
+    ssh_alias: github-com-work
+    root: ~/code/work
+`,
+		},
+		{
+			name: "unknown top-level key",
+			config: `workspaces: {}
+langauge: de
+`,
+			expected: []problem{{2, `unknown key "langauge" (did you mean "language"?)`}},
+		},
+		{
+			name: "unknown nested key",
+			config: `workspaces:
+  work:
+    email: me@work.com
+    provider: github
+    signnig: ssh
+    hosts:
+      - host_name: gitlab.com
+        ssh_alais: gitlab-com-work
+`,
+			expected: []problem{
+				{5, `unknown key "workspaces.work.signnig" (did you mean "signing"?)`},
+				{8, `unknown key "workspaces.work.hosts.ssh_alais" (did you mean "ssh_alias"?)`},
+			},
+		},
+		{
+			name: "unknown key without a guess",
+			config: `workspaces: {}
+colour_scheme: dark
+`,
+			expected: []problem{{2, `unknown key "colour_scheme"`}},
+		},
+		{
+			name: "field problems at their lines",
+			config: `workspaces:
+  work:
+    provider: github
+    signing: pgp
+    transport: ftp
+    root: code/work
+language: xx
+`,
+			expected: []problem{
+				{2, `workspace "work": email is required`},
+				{4, `workspace "work": unknown signing method "pgp"`},
+				{5, `workspace "work": unknown transport "ftp"`},
+				{6, `workspace "work": root must be an absolute path or start with ~/`},
+				{7, `unknown language "xx"`},
+			},
+		},
+		{
+			name: "duplicate ssh alias",
+			config: `workspaces:
+  oss:
+    email: me@oss.org
+    provider: github
+    ssh_alias: github-com-shared
+  work:
+    email: me@work.com
+    provider: github
+    ssh_alias: github-com-shared
+`,
+			expected: []problem{{9, `workspace "work": ssh alias github-com-shared is already used by workspace "oss"`}},
+		},
+		{
+			name: "duplicate retired and identity aliases",
+			config: `workspaces:
+  oss:
+    email: me@oss.org
+    provider: github
+    ssh_alias: github-com-oss
+  work:
+    email: me@work.com
+    provider: github
+    ssh_alias: github-com-work
+    retired_aliases:
+      - alias: github-com-oss
+    identities:
+      ci:
+        email: ci@work.com
+        ssh_alias: github-com-work
+`,
+			expected: []problem{
+				{10, `workspace "work": ssh alias github-com-oss is already used by workspace "oss"`},
+			},
+		},
+		{
+			name: "duplicate alias across identities",
+			config: `workspaces:
+  oss:
+    email: me@oss.org
+    provider: github
+    identities:
+      bot:
+        email: bot@oss.org
+        ssh_alias: github-com-bot
+  work:
+    email: me@work.com
+    provider: github
+    identities:
+      bot:
+        email: bot@work.com
+        ssh_alias: github-com-bot
+`,
+			expected: []problem{
+				{12, `workspace "work": ssh alias github-com-bot is already used by workspace "oss"`},
+			},
+		},
+		{
+			name: "duplicate root",
+			config: `workspaces:
+  oss:
+    email: me@oss.org
+    provider: github
+    root: ~/code
+  work:
+    email: me@work.com
+    provider: github
+    root: ~/code/
+`,
+			expected: []problem{{9, `workspace "work": root ~/code/ is also the root of workspace "oss"`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv(HomeEnv, home)
+			if err := os.WriteFile(filepath.Join(home, "config.yaml"), []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load()
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if len(invalid.Problems) != len(tt.expected) {
+				t.Fatalf("expected %d problems, got %v", len(tt.expected), invalid.Problems)
+			}
+			for i, expected := range tt.expected {
+				problem := invalid.Problems[i]
+				if problem.Line != expected.line || !strings.HasPrefix(problem.Message, expected.message) {
+					t.Errorf("expected line %d: %q, got line %d: %q", expected.line, expected.message, problem.Line, problem.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorLines(t *testing.T) {
+	err := &ValidationError{Path: "config.yaml", Problems: []Problem{
+		{Line: 3, Message: `unknown key "emial"`},
+		{Message: "orgs: acme maps to unknown workspace \"gone\""},
+	}}
+
+	expected := "invalid config file config.yaml:\n  line 3: unknown key \"emial\"\n  orgs: acme maps to unknown workspace \"gone\""
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestValidateWorkspaceName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"work", true},
+		{"oss.2024", true},
+		{"gitignore", true},
+		{"hooks", false},
+		{"GitConfig", false},
+		{"config.yaml", false},
+		{TrustFileName, false},
+		{"../work", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWorkspaceName(tt.name)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected %q to be rejected", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CommonDomains are well-known mail domains used to detect typos
+var CommonDomains = []string{
+	"gmail.com",
+	"googlemail.com",
+	"outlook.com",
+	"hotmail.com",
+	"live.com",
+	"yahoo.com",
+	"icloud.com",
+	"me.com",
+	"protonmail.com",
+	"proton.me",
+	"fastmail.com",
+	"gmx.com",
+	"gmx.de",
+	"aol.com",
+	"users.noreply.github.com",
+}
+
+// knownTypos maps frequent misspellings to the intended domain
+var knownTypos = map[string]string{
+	"gamil.com":   "gmail.com",
+	"gmial.com":   "gmail.com",
+	"gmai.com":    "gmail.com",
+	"gmal.com":    "gmail.com",
+	"gmail.co":    "gmail.com",
+	"gmail.con":   "gmail.com",
+	"gnail.com":   "gmail.com",
+	"hotmial.com": "hotmail.com",
+	"hotmai.com":  "hotmail.com",
+	"hotmail.co":  "hotmail.com",
+	"outlok.com":  "outlook.com",
+	"outloo.com":  "outlook.com",
+	"yaho.com":    "yahoo.com",
+	"yahooo.com":  "yahoo.com",
+	"icloud.co":   "icloud.com",
+	"iclod.com":   "icloud.com",
+}
+
+// ErrNoMailServer is returned by CheckDomain when the domain cannot receive mail
+var ErrNoMailServer = errors.New("domain has no mail server")
+
+// Limits from RFC 5321, in octets
+const (
+	maxLocalPart = 64
+	maxDomain    = 253
+	maxLabel     = 63
+	maxAddress   = 254
+)
+
+// Validate checks that addr is a bare email address (no display name)
+// within the limits of RFC 5321. Internationalized addresses (RFC 6531)
+// are accepted; quoted local parts and address literals are not, as git
+// and the providers do not handle them.
+func Validate(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("email is empty")
+	}
+
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Address != addr || strings.ContainsAny(addr, "\"[]") {
+		return fmt.Errorf("invalid email address: %q", addr)
+	}
+	if len(addr) > maxAddress {
+		return fmt.Errorf("invalid email address: longer than %d bytes", maxAddress)
+	}
+
+	at := strings.LastIndex(addr, "@")
+	if at > maxLocalPart {
+		return fmt.Errorf("invalid email address: local part %q is longer than %d bytes", addr[:at], maxLocalPart)
+	}
+
+	domain := Domain(addr)
+	if err := validateDomain(domain); err != nil {
+		return fmt.Errorf("invalid email domain %q: %w", domain, err)
+	}
+
+	return nil
+}
+
+// validateDomain checks a domain name label by label. Labels outside
+// ASCII are internationalized (IDN) and left to the registry to judge.
+func validateDomain(domain string) error {
+	if len(domain) > maxDomain {
+		return fmt.Errorf("longer than %d bytes", maxDomain)
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("no top-level domain")
+	}
+	for _, label := range labels {
+		switch {
+		case label == "":
+			return fmt.Errorf("empty label")
+		case len(label) > maxLabel:
+			return fmt.Errorf("label %q is longer than %d bytes", label, maxLabel)
+		case strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-"):
+			return fmt.Errorf("label %q starts or ends with a hyphen", label)
+		}
+		for _, r := range label {
+			if r < utf8.RuneSelf && !isLetterDigitHyphen(byte(r)) {
+				return fmt.Errorf("label %q contains %q", label, r)
+			}
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return fmt.Errorf("numeric top-level domain")
+	}
+	return nil
+}
+
+func isLetterDigitHyphen(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+// IsNoreply reports whether addr is a private commit address handed out
+// by a provider, e.g. 42+octo@users.noreply.github.com
+func IsNoreply(addr string) bool {
+	return strings.HasPrefix(Domain(addr), "users.noreply.")
+}
+
+// Normalize trims surrounding whitespace and lowercases the domain part, so
+// the same address is written identically to keys, gitconfig, and config.yaml
+func Normalize(addr string) string {
+	addr = strings.TrimSpace(addr)
+	at := strings.LastIndex(addr, "@")
+	if at == -1 {
+		return addr
+	}
+	return addr[:at+1] + strings.ToLower(addr[at+1:])
+}
+
+// Domain returns the lowercased domain part of addr
+func Domain(addr string) string {
+	at := strings.LastIndex(addr, "@")
+	if at == -1 {
+		return ""
+	}
+	return strings.ToLower(addr[at+1:])
+}
+
+// Blocked returns the entry of blocklist that forbids addr. An entry is
+// an address, or a domain (gmail.com or @gmail.com) that also covers its
+// subdomains. Comparison ignores case.
+func Blocked(addr string, blocklist []string) (string, bool) {
+	return Matches(addr, blocklist)
+}
+
+// Matches returns the entry of list that covers addr, with entries as
+// Blocked takes them
+func Matches(addr string, list []string) (string, bool) {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	domain := Domain(addr)
+	if addr == "" {
+		return "", false
+	}
+	for _, entry := range list {
+		e := strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case e == "":
+		case strings.HasPrefix(e, "@"):
+			e = e[1:]
+			fallthrough
+		case !strings.Contains(e, "@"):
+			if domain == e || strings.HasSuffix(domain, "."+e) {
+				return entry, true
+			}
+		case addr == e:
+			return entry, true
+		}
+	}
+	return "", false
+}
+
+// minSuggestLength is the length a common domain needs for a one-letter
+// difference to suggest it: short ones, such as me.com, are one letter away
+// from too many real domains and are only matched as known typos
+const minSuggestLength = 9
+
+// SuggestDomain returns a likely intended domain if domain looks like a typo
+// of a common mail domain: a known misspelling, or one letter off a long
+// common domain with the same first letter, so real domains such as
+// ymail.com or email.com are not taken for gmail.com. The suggestion is
+// for showing to the user; it must never be applied unasked.
+func SuggestDomain(domain string) (string, bool) {
+	domain = strings.ToLower(domain)
+	if domain == "" {
+		return "", false
+	}
+
+	if fixed, ok := knownTypos[domain]; ok {
+		return fixed, true
+	}
+
+	for _, common := range CommonDomains {
+		if domain == common {
+			return "", false
+		}
+	}
+
+	for _, common := range CommonDomains {
+		if len(common) < minSuggestLength || domain[0] != common[0] {
+			continue
+		}
+		if distance(domain, common) == 1 {
+			return common, true
+		}
+	}
+
+	return "", false
+}
+
+// CheckDomain verifies that domain can receive mail (MX record, or an
+// address record as the implicit MX). It returns ErrNoMailServer when DNS
+// definitively says no, and nil when the lookup could not be completed
+// (e.g. offline), so callers can skip the check.
+func CheckDomain(domain string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var resolver net.Resolver
+
+	mxs, err := resolver.LookupMX(ctx, domain)
+	if err == nil && len(mxs) > 0 {
+		return nil
+	}
+	if err != nil && !isNotFound(err) {
+		return nil // inconclusive
+	}
+
+	addrs, err := resolver.LookupHost(ctx, domain)
+	if err == nil && len(addrs) > 0 {
+		return nil
+	}
+	if err != nil && !isNotFound(err) {
+		return nil // inconclusive
+	}
+
+	return ErrNoMailServer
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// distance returns the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b
+func distance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
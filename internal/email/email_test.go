@@ -0,0 +1,155 @@
+package email
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input  string
+		hasErr bool
+	}{
+		{"you@work.com", false},
+		{"first.last+tag@sub.example.co.uk", false},
+		{"", true},
+		{"not-an-email", true},
+		{"You <you@work.com>", true},
+		{"you@localhost", true},
+		{"you@work.", true},
+		{"you@@work.com", true},
+		{"josé@münchen.de", false},
+		{"用户@例子.广告", false},
+		{"42+octo@users.noreply.github.com", false},
+		{strings.Repeat("a", 64) + "@work.com", false},
+		{strings.Repeat("a", 65) + "@work.com", true},
+		{"you@" + strings.Repeat("a", 64) + ".com", true},
+		{"you@-work.com", true},
+		{"you@work-.com", true},
+		{"you@work..com", true},
+		{"you@work_place.com", true},
+		{"you@1.2", true},
+		{"you@[192.0.2.1]", true},
+		{`"you me"@work.com`, true},
+		{"you me@work.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.hasErr && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.hasErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsNoreply(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"42+octo@users.noreply.github.com", true},
+		{"octo@users.noreply.github.com", true},
+		{"9-lab@users.noreply.gitlab.com", true},
+		{"7+me@users.noreply.ghe.acme.com", true},
+		{"noreply@github.com", false},
+		{"you@work.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsNoreply(tt.input); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuggestDomain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"gamil.com", "gmail.com", true},
+		{"GMAIL.CON", "gmail.com", true},
+		{"outlookk.com", "outlook.com", true},
+		{"yahoo.cmo", "yahoo.com", true},
+		{"gmail.com", "", false},
+		{"me.com", "", false},
+		{"work.com", "", false},
+		{"mycompany.io", "", false},
+		{"ms.com", "", false},
+		{"mo.com", "", false},
+		{"lime.com", "", false},
+		{"gmx.co", "", false},
+		{"ymail.com", "", false},
+		{"email.com", "", false},
+		{"proton.mx", "proton.me", true},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, ok := SuggestDomain(tt.input)
+			if ok != tt.ok {
+				t.Errorf("expected ok %v, got %v", tt.ok, ok)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"you@work.com", "you@work.com"},
+		{"  You@Work.COM ", "You@work.com"},
+		{"no-at-sign", "no-at-sign"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := Normalize(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	blocklist := []string{"gmail.com", "@proton.me", "old@work.com"}
+
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{"me@gmail.com", "gmail.com"},
+		{"Me@GMail.com", "gmail.com"},
+		{"me@mail.gmail.com", "gmail.com"},
+		{"me@notgmail.com", ""},
+		{"me@proton.me", "@proton.me"},
+		{"old@work.com", "old@work.com"},
+		{"OLD@work.com", "old@work.com"},
+		{"new@work.com", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			entry, blocked := Blocked(tt.addr, blocklist)
+			if entry != tt.expected || blocked != (tt.expected != "") {
+				t.Errorf("expected %q, got %q (%v)", tt.expected, entry, blocked)
+			}
+		})
+	}
+}
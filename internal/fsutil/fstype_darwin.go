@@ -0,0 +1,21 @@
+package fsutil
+
+import "syscall"
+
+// filesystemType returns the name of the filesystem holding path, e.g.
+// "apfs" or "smbfs"
+func filesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+
+	var name []byte
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return string(name), nil
+}
@@ -0,0 +1,25 @@
+package fsutil
+
+import "syscall"
+
+// linuxFilesystems maps statfs magic numbers to filesystem names
+var linuxFilesystems = map[uint32]string{
+	0x6969:     "nfs",
+	0x517B:     "smb",
+	0xFF534D42: "cifs",
+	0xFE534D42: "smb2",
+	0x5346414F: "afs",
+	0x73757245: "coda",
+	0x00C36400: "ceph",
+	0x01021997: "9p", // also WSL's drvfs
+}
+
+// filesystemType returns the name of the filesystem holding path, or ""
+// for local filesystems
+func filesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+	return linuxFilesystems[uint32(st.Type)], nil
+}
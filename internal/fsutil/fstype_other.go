@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+
+package fsutil
+
+// filesystemType is not implemented here; every path is treated as local
+func filesystemType(path string) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,34 @@
+package fsutil
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const driveRemote = 4 // DRIVE_REMOTE
+
+var procGetDriveTypeW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+// filesystemType returns "smb" for UNC paths and mapped network drives,
+// and "" otherwise
+func filesystemType(path string) (string, error) {
+	volume := filepath.VolumeName(path)
+	if strings.HasPrefix(volume, `\\`) {
+		return "smb", nil
+	}
+	if volume == "" {
+		return "", nil
+	}
+
+	root, err := syscall.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return "", err
+	}
+	driveType, _, _ := procGetDriveTypeW.Call(uintptr(unsafe.Pointer(root)))
+	if driveType == driveRemote {
+		return "smb", nil
+	}
+	return "", nil
+}
@@ -1,15 +1,97 @@
 package fsutil
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Recorder, when set, is told about every file gitws changes, with its
+// contents before and after the change. before is nil when the file was
+// created and after is nil when it was removed.
+var Recorder func(path string, before, after []byte)
+
 // AtomicWrite writes data to a file atomically
 func AtomicWrite(path string, data []byte, perm os.FileMode) error {
+	done, err := beginChange(path)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if Recorder != nil {
+		before := readExisting(path)
+		if err := atomicWrite(path, data, perm); err != nil {
+			return err
+		}
+		if before == nil || !bytes.Equal(before, data) {
+			Recorder(path, before, append([]byte{}, data...))
+		}
+		return nil
+	}
+	return atomicWrite(path, data, perm)
+}
+
+// RecordChange runs change, which modifies paths by other means than
+// AtomicWrite (a git or ssh-keygen command, say), and tells Recorder which
+// of them it changed. The journal remembers all of them.
+func RecordChange(change func() error, paths ...string) error {
+	done, err := beginChange(paths...)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if Recorder == nil {
+		return change()
+	}
+	befores := make([][]byte, len(paths))
+	for i, path := range paths {
+		befores[i] = readExisting(path)
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	for i, path := range paths {
+		after := readExisting(path)
+		if (befores[i] == nil) != (after == nil) || !bytes.Equal(befores[i], after) {
+			Recorder(path, befores[i], after)
+		}
+	}
+	return nil
+}
+
+// Remove removes the file at path, which may already be gone, telling
+// Recorder and the journal
+func Remove(path string) error {
+	return RecordChange(func() error {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}, path)
+}
+
+// readExisting returns the contents of path, or nil when it cannot be read
+func readExisting(path string) []byte {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data
+}
+
+func atomicWrite(path string, data []byte, perm os.FileMode) error {
 	// Create temporary file in same directory
 	dir := filepath.Dir(path)
 	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp")
@@ -30,6 +112,13 @@ func AtomicWrite(path string, data []byte, perm os.FileMode) error {
 		return fmt.Errorf("failed to set temp file permissions: %w", err)
 	}
 
+	// Flush to disk before the rename makes the file visible; network
+	// filesystems may otherwise expose a truncated file
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+
 	// Close temp file
 	if err := tmpFile.Close(); err != nil {
 		return fmt.Errorf("failed to close temp file: %w", err)
@@ -37,12 +126,98 @@ func AtomicWrite(path string, data []byte, perm os.FileMode) error {
 
 	// Atomic rename
 	if err := os.Rename(tmpFile.Name(), path); err != nil {
-		return fmt.Errorf("failed to rename temp file: %w", err)
+		// Some SMB servers refuse to rename over an existing file; fall
+		// back to rewriting it in place
+		if _, isNetwork := NetworkFilesystem(dir); !isNetwork {
+			return fmt.Errorf("failed to rename temp file: %w", err)
+		}
+		if err := writeInPlace(path, data, perm); err != nil {
+			return err
+		}
 	}
 
+	syncDir(dir)
 	return nil
 }
 
+// syncDir flushes a directory entry so a rename survives a crash. It is
+// best-effort: Windows and some network filesystems cannot sync directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// ExistingMode returns the permission bits of path, or fallback when the
+// file does not exist yet
+func ExistingMode(path string, fallback os.FileMode) os.FileMode {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fallback
+	}
+	return info.Mode().Perm()
+}
+
+// writeInPlace overwrites path with data and flushes it to disk
+func writeInPlace(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	return os.Chmod(path, perm)
+}
+
+// networkFilesystems are the filesystem types treated as network mounts
+var networkFilesystems = map[string]bool{
+	"nfs":    true,
+	"smb":    true,
+	"smb2":   true,
+	"smbfs":  true,
+	"cifs":   true,
+	"afs":    true,
+	"afpfs":  true,
+	"coda":   true,
+	"ceph":   true,
+	"webdav": true,
+	"9p":     true,
+}
+
+// NetworkFilesystem reports whether path, or its nearest existing parent,
+// is on a network filesystem, and which one. Rename, fsync and permission
+// bits behave differently there.
+func NetworkFilesystem(path string) (string, bool) {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", false
+		}
+		path = parent
+	}
+
+	fsType, err := filesystemType(path)
+	if err != nil {
+		return "", false
+	}
+	return fsType, networkFilesystems[fsType]
+}
+
 // CreateBackup creates a backup of a file with timestamp
 func CreateBackup(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -88,7 +263,13 @@ func ReplaceBetweenMarkers(content, startMarker, endMarker, newContent string) (
 	before := content[:startIdx]
 	after := content[endIdx:]
 
-	result := before + newContent + "\n" + after
+	// Keep exactly one newline after the block so repeated rewrites don't
+	// accumulate blank lines
+	result := before + newContent
+	if !strings.HasPrefix(after, "\n") {
+		result += "\n"
+	}
+	result += after
 	return result, true
 }
 
@@ -104,8 +285,8 @@ func ExtractBetweenMarkers(content, startMarker, endMarker string) (string, bool
 		return "", false
 	}
 
-	startIdx += len(startMarker)
 	endIdx += startIdx
+	startIdx += len(startMarker)
 
 	// Extract content between markers
 	extracted := content[startIdx:endIdx]
@@ -114,6 +295,22 @@ func ExtractBetweenMarkers(content, startMarker, endMarker string) (string, bool
 	return extracted, true
 }
 
+// HasConflictMarkers reports whether content holds an unresolved merge
+// conflict: a line opening with <<<<<<< and a later one opening with
+// >>>>>>>
+func HasConflictMarkers(content string) bool {
+	open := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			open = true
+		case open && strings.HasPrefix(line, ">>>>>>>"):
+			return true
+		}
+	}
+	return false
+}
+
 // EnsureDir ensures a directory exists
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0755)
@@ -133,3 +330,31 @@ func IsEmpty(path string) bool {
 	}
 	return info.Size() == 0
 }
+
+// ParseSize parses a human-readable size such as "512K", "5MB" or "1GiB"
+// into bytes. Units are binary; a bare number is bytes.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+
+	multiplier := int64(1)
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			value = value[:len(value)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q (examples: 500K, 5MB, 1G)", s)
+	}
+	return n * multiplier, nil
+}
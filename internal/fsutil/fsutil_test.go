@@ -0,0 +1,177 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestExtractBetweenMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		found    bool
+	}{
+		{"block only", "# >>> start\nHost a\n# <<< end", "Host a", true},
+		{"surrounded", "before\n# >>> start\nHost a\n  User git\n# <<< end\nafter\n", "Host a\n  User git", true},
+		{"empty block", "# >>> start\n# <<< end", "", true},
+		{"no start", "Host a\n# <<< end", "", false},
+		{"no end", "# >>> start\nHost a", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := ExtractBetweenMarkers(tt.content, "# >>> start", "# <<< end")
+
+			if found != tt.found {
+				t.Errorf("expected found %v, got %v", tt.found, found)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReplaceBetweenMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty", "", "# >>> start\nnew\n# <<< end"},
+		{"append", "existing", "existing\n# >>> start\nnew\n# <<< end"},
+		{"replace", "a\n# >>> start\nold\n# <<< end\nb", "a\n# >>> start\nnew\n# <<< end\nb"},
+		{"replace at end", "a\n# >>> start\nold\n# <<< end", "a\n# >>> start\nnew\n# <<< end\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := ReplaceBetweenMarkers(tt.content, "# >>> start", "# <<< end", "# >>> start\nnew\n# <<< end")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"1024", 1024, false},
+		{"100B", 100, false},
+		{"512K", 512 << 10, false},
+		{"5MB", 5 << 20, false},
+		{"5mb", 5 << 20, false},
+		{"1GiB", 1 << 30, false},
+		{" 2 M ", 2 << 20, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"-1K", 0, true},
+		{"1.5M", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSize(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %d", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	lock, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := LockFile(path, 100*time.Millisecond); err == nil {
+		t.Errorf("expected second lock to time out")
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	again, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("expected lock after unlock, got %v", err)
+	}
+	again.Unlock()
+}
+
+func TestAtomicWritePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if mode := ExistingMode(path, 0600); mode != 0600 {
+		t.Errorf("expected %v, got %v", os.FileMode(0600), mode)
+	}
+	if err := AtomicWrite(path, []byte("a"), 0640); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode := ExistingMode(path, 0600); mode != 0640 {
+		t.Errorf("expected %v, got %v", os.FileMode(0640), mode)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "a" {
+		t.Errorf("expected %q, got %q (%v)", "a", data, err)
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	free, err := FreeSpace(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if free == 0 {
+		t.Errorf("expected free space on the temporary directory")
+	}
+	if _, err := FreeSpace(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing path")
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"clean", "[user]\n  name = Me\n", false},
+		{"conflict", "[user]\n<<<<<<< HEAD\n  name = Me\n=======\n  name = You\n>>>>>>> main\n", true},
+		{"opening marker only", "<<<<<<< HEAD\n  name = Me\n", false},
+		{"closing before opening", ">>>>>>> main\n<<<<<<< HEAD\n", false},
+		{"indented markers", "  <<<<<<< HEAD\n  >>>>>>> main\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := HasConflictMarkers(tt.content); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// original is a file as it was before the journaled command changed it
+type original struct {
+	data   []byte // nil when the file did not exist
+	mode   os.FileMode
+	remove bool // created as a whole (a clone, say): removed recursively
+}
+
+// ErrRolledBack is returned by AtomicWrite and RecordChange once the
+// journal has been rolled back, so a command still running after an
+// interrupt cannot undo the rollback
+var ErrRolledBack = errors.New("changes were rolled back after an interrupt")
+
+// journal remembers the original state of everything changed while it is
+// open, so an interrupted command can be undone. Changes hold it for
+// reading while they run and Rollback holds it for writing, so a rollback
+// never races a change.
+var journal struct {
+	sync.RWMutex
+	mu         sync.Mutex // guards the fields below among concurrent changes
+	open       bool
+	rolledBack bool
+	originals  map[string]original
+	order      []string
+}
+
+// BeginJournal starts remembering the original contents of files before
+// AtomicWrite or RecordChange changes them
+func BeginJournal() {
+	journal.Lock()
+	defer journal.Unlock()
+	journal.open = true
+	journal.rolledBack = false
+	journal.originals = make(map[string]original)
+	journal.order = nil
+}
+
+// EndJournal stops journaling, keeping the changes made
+func EndJournal() {
+	journal.Lock()
+	defer journal.Unlock()
+	journal.open = false
+	journal.originals = nil
+	journal.order = nil
+}
+
+// beginChange is called before a change to paths; the change must call
+// the returned function when done
+func beginChange(paths ...string) (func(), error) {
+	journal.RLock()
+	if journal.rolledBack {
+		journal.RUnlock()
+		return nil, ErrRolledBack
+	}
+	for _, path := range paths {
+		remember(path)
+	}
+	return journal.RUnlock, nil
+}
+
+// remember saves the state of path before its first change
+func remember(path string) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	if !journal.open || path == "" {
+		return
+	}
+	if _, seen := journal.originals[path]; seen {
+		return
+	}
+	o := original{data: readExisting(path), mode: ExistingMode(path, 0600)}
+	journal.originals[path] = o
+	journal.order = append(journal.order, path)
+}
+
+// TrackCreated records that path, a file or directory tree that did not
+// exist before, was created by this command and is removed on rollback
+func TrackCreated(path string) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	if !journal.open || path == "" {
+		return
+	}
+	if _, seen := journal.originals[path]; seen {
+		return
+	}
+	journal.originals[path] = original{remove: true}
+	journal.order = append(journal.order, path)
+}
+
+// Rollback restores every journaled file to its original state, newest
+// change first, and closes the journal. It returns the paths it restored.
+// Later changes fail with ErrRolledBack.
+func Rollback() ([]string, error) {
+	journal.Lock()
+	defer journal.Unlock()
+	if !journal.open {
+		return nil, nil
+	}
+	journal.open = false
+	journal.rolledBack = true
+
+	var restored []string
+	var errs []error
+	for i := len(journal.order) - 1; i >= 0; i-- {
+		path := journal.order[i]
+		if err := restore(path, journal.originals[path]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", path, err))
+			continue
+		}
+		restored = append(restored, path)
+	}
+	sort.Strings(restored)
+	return restored, errors.Join(errs...)
+}
+
+// restore puts path back as it was
+func restore(path string, o original) error {
+	current := readExisting(path)
+	switch {
+	case o.remove:
+		err := os.RemoveAll(path)
+		if Recorder != nil && current != nil {
+			Recorder(path, current, nil)
+		}
+		return err
+	case o.data == nil:
+		if current == nil {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if Recorder != nil {
+			Recorder(path, current, nil)
+		}
+		return nil
+	default:
+		if err := atomicWrite(path, o.data, o.mode); err != nil {
+			return err
+		}
+		if Recorder != nil {
+			Recorder(path, current, o.data)
+		}
+		return nil
+	}
+}
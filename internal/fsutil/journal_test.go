@@ -0,0 +1,111 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "config")
+	created := filepath.Join(dir, "new")
+	clone := filepath.Join(dir, "clone")
+	if err := os.WriteFile(changed, []byte("original\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	BeginJournal()
+	if err := AtomicWrite(changed, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := AtomicWrite(changed, []byte("second\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordChange(func() error { return os.WriteFile(created, []byte("x"), 0644) }, created); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(clone, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	TrackCreated(clone)
+
+	restored, err := Rollback()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restored) != 3 {
+		t.Errorf("expected 3 restored paths, got %v", restored)
+	}
+
+	data, err := os.ReadFile(changed)
+	if err != nil || string(data) != "original\n" {
+		t.Errorf("expected %q, got %q (%v)", "original\n", data, err)
+	}
+	if info, err := os.Stat(changed); err == nil && info.Mode().Perm() != 0600 && os.PathSeparator == '/' {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	for _, path := range []string{created, clone} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+
+	if err := AtomicWrite(changed, []byte("late\n"), 0600); err != ErrRolledBack {
+		t.Errorf("expected %v, got %v", ErrRolledBack, err)
+	}
+
+	// A new journal allows changes again
+	BeginJournal()
+	defer EndJournal()
+	if err := AtomicWrite(changed, []byte("late\n"), 0600); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNoJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := AtomicWrite(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Rollback()
+	if err != nil || len(restored) != 0 {
+		t.Errorf("expected nothing to roll back, got %v (%v)", restored, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gitconfig")
+	if err := os.WriteFile(path, []byte("[user]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var recorded [][]byte
+	Recorder = func(p string, before, after []byte) {
+		if p == path && after == nil {
+			recorded = append(recorded, before)
+		}
+	}
+	defer func() { Recorder = nil }()
+
+	BeginJournal()
+	if err := Remove(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("expected removing a missing file to succeed, got %v", err)
+	}
+	if len(recorded) != 1 || string(recorded[0]) != "[user]\n" {
+		t.Errorf("expected one recorded removal, got %q", recorded)
+	}
+
+	if _, err := Rollback(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "[user]\n" {
+		t.Errorf("expected the file restored, got %q (%v)", data, err)
+	}
+	BeginJournal()
+	EndJournal()
+}
@@ -0,0 +1,62 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LockTimeout is how long gitws waits for another gitws process to finish
+// writing a shared file
+const LockTimeout = 30 * time.Second
+
+// lockSuffix names the lock file next to the protected file. git itself
+// creates and removes <file>.lock, so that name is not available.
+const lockSuffix = ".gws-lock"
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("locked")
+
+// Lock is an advisory lock on a file shared between gitws processes
+type Lock struct {
+	file *os.File
+}
+
+// LockFile takes an exclusive advisory lock for path, waiting up to
+// timeout for other processes to release it. The lock is held on a
+// separate <path>.gws-lock file, so path can still be replaced atomically.
+func LockFile(path string, timeout time.Duration) (*Lock, error) {
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return &Lock{file: f}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for another gitws process to finish with %s", timeout, path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlock(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
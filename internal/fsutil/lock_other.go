@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package fsutil
+
+import "os"
+
+// Files are not locked on this platform
+func tryLock(f *os.File) error {
+	return nil
+}
+
+func unlock(f *os.File) {}
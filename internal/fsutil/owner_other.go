@@ -0,0 +1,9 @@
+//go:build !unix
+
+package fsutil
+
+// OwnedByCurrentUser reports whether path belongs to the user running gitws.
+// Ownership is not checked on this platform, so ok is always false.
+func OwnedByCurrentUser(path string) (owned, ok bool) {
+	return false, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// OwnedByCurrentUser reports whether path belongs to the user running gitws.
+// ok is false when ownership cannot be determined.
+func OwnedByCurrentUser(path string) (owned, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, false
+	}
+	stat, isStat := info.Sys().(*syscall.Stat_t)
+	if !isStat {
+		return false, false
+	}
+	return int(stat.Uid) == os.Getuid(), true
+}
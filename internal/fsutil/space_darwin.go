@@ -0,0 +1,13 @@
+package fsutil
+
+import "syscall"
+
+// FreeSpace returns the bytes available to the current user on the
+// filesystem holding path
+func FreeSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
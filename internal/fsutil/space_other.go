@@ -0,0 +1,10 @@
+//go:build !darwin && !linux && !windows
+
+package fsutil
+
+import "errors"
+
+// FreeSpace is not implemented here
+func FreeSpace(path string) (uint64, error) {
+	return 0, errors.New("free space is not available on this platform")
+}
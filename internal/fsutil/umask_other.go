@@ -0,0 +1,8 @@
+//go:build !unix
+
+package fsutil
+
+// WithPrivateUmask runs create. There is no umask on this platform.
+func WithPrivateUmask(create func() error) error {
+	return create()
+}
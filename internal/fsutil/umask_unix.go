@@ -0,0 +1,15 @@
+//go:build unix
+
+package fsutil
+
+import "syscall"
+
+// WithPrivateUmask runs create with a umask of 077, so files and sockets
+// it creates are never accessible to other users, not even briefly
+// before a chmod. The umask is process-wide; call it before starting
+// goroutines that create files of their own.
+func WithPrivateUmask(create func() error) error {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return create()
+}
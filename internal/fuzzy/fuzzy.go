@@ -0,0 +1,108 @@
+// Package fuzzy ranks strings against a short typed pattern, the way
+// repository pickers do: "wapi" finds "work acme/api", and an exact
+// segment beats letters scattered across the name.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Scores added per matched rune
+const (
+	matchScore       = 1
+	consecutiveBonus = 5
+	boundaryBonus    = 6
+	gapPenalty       = 3
+)
+
+// Match is a candidate that matched a pattern
+type Match struct {
+	Index int // position in the candidates passed to Rank
+	Score int
+}
+
+// Score reports whether every space-separated term of pattern occurs in
+// candidate as a case-insensitive subsequence, and how well: runes
+// matched consecutively and at the start of a word (after /, -, _, .
+// or a space) score higher. An empty pattern matches everything with
+// score 0.
+func Score(pattern, candidate string) (int, bool) {
+	text := []rune(strings.ToLower(candidate))
+	total := 0
+	for _, term := range strings.Fields(strings.ToLower(pattern)) {
+		score, ok := scoreTerm([]rune(term), text)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// Rank returns the candidates matching pattern, best first. Ties go to
+// the shorter candidate, then to the earlier one.
+func Rank(pattern string, candidates []string) []Match {
+	var matches []Match
+	for i, c := range candidates {
+		if score, ok := Score(pattern, c); ok {
+			matches = append(matches, Match{Index: i, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return utf8.RuneCountInString(candidates[a.Index]) < utf8.RuneCountInString(candidates[b.Index])
+	})
+	return matches
+}
+
+// scoreTerm scores the best match of term in text, trying each position
+// where its first rune occurs and matching the rest greedily from there
+func scoreTerm(term, text []rune) (int, bool) {
+	best, found := 0, false
+	for start := range text {
+		if text[start] != term[0] {
+			continue
+		}
+		if score, ok := scoreFrom(term, text, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+func scoreFrom(term, text []rune, start int) (int, bool) {
+	score, last := 0, -1
+	t := 0
+	for i := start; i < len(text) && t < len(term); i++ {
+		if text[i] != term[t] {
+			continue
+		}
+		score += matchScore
+		if last >= 0 {
+			if i == last+1 {
+				score += consecutiveBonus
+			} else {
+				score -= gapPenalty * (i - last - 1)
+			}
+		}
+		if i == 0 || isBoundary(text[i-1]) {
+			score += boundaryBonus
+		}
+		last = i
+		t++
+	}
+	return score, t == len(term)
+}
+
+func isBoundary(r rune) bool {
+	switch r {
+	case '/', '-', '_', '.', ' ':
+		return true
+	}
+	return false
+}
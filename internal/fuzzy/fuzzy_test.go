@@ -0,0 +1,82 @@
+package fuzzy
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		candidate string
+		matches   bool
+	}{
+		{"substring", "api", "work acme/api", true},
+		{"subsequence", "wapi", "work acme/api", true},
+		{"case insensitive", "ACME", "work acme/api", true},
+		{"terms in any order", "api work", "work acme/api", true},
+		{"every term must match", "api beta", "work acme/api", false},
+		{"out of order", "ipa", "work acme/api", false},
+		{"empty pattern", "", "work acme/api", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := Score(tt.pattern, tt.candidate)
+			if ok != tt.matches {
+				t.Errorf("expected match %v, got %v", tt.matches, ok)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		candidates []string
+		expected   []int
+	}{
+		{
+			name:       "word start beats scattered letters",
+			pattern:    "api",
+			candidates: []string{"work acme/rapid-io", "work acme/api-gateway"},
+			expected:   []int{1, 0},
+		},
+		{
+			name:       "consecutive beats gaps",
+			pattern:    "web",
+			candidates: []string{"work acme/w-e-b", "work acme/webapp"},
+			expected:   []int{1, 0},
+		},
+		{
+			name:       "shorter wins a tie",
+			pattern:    "api",
+			candidates: []string{"work acme/api-legacy", "work acme/api"},
+			expected:   []int{1, 0},
+		},
+		{
+			name:       "workspace term narrows",
+			pattern:    "personal api",
+			candidates: []string{"work acme/api", "personal me/api"},
+			expected:   []int{1},
+		},
+		{
+			name:       "no match",
+			pattern:    "zzz",
+			candidates: []string{"work acme/api"},
+			expected:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Rank(tt.pattern, tt.candidates)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d matches, got %+v", len(tt.expected), got)
+			}
+			for i, m := range got {
+				if m.Index != tt.expected[i] {
+					t.Errorf("expected %v, got %+v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
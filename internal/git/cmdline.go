@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// globalOptionsWithValue are the options before the subcommand that may
+// take their value as the next argument
+var globalOptionsWithValue = map[string]bool{
+	"-C":             true,
+	"-c":             true,
+	"--git-dir":      true,
+	"--work-tree":    true,
+	"--namespace":    true,
+	"--config-env":   true,
+	"--super-prefix": true,
+}
+
+// ParseCommandLine splits the arguments of a git invocation: the
+// directories given with -C, in order, and the subcommand. subcommand is
+// "" when there is none, as in git --version.
+func ParseCommandLine(args []string) (dirs []string, subcommand string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return dirs, arg
+		}
+		if globalOptionsWithValue[arg] && i+1 < len(args) {
+			if arg == "-C" {
+				dirs = append(dirs, args[i+1])
+			}
+			i++
+		}
+	}
+	return dirs, ""
+}
+
+// AuthorIdent returns the name and email a commit in dir would be
+// authored with, after the GIT_AUTHOR_* variables and every config file
+// are taken into account
+func AuthorIdent(dir string) (name, email string, err error) {
+	cmd := command("var", "GIT_AUTHOR_IDENT")
+	cmd.Dir = dir
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine the author identity: %w", err)
+	}
+	name, email, ok := ParseIdent(string(output))
+	if !ok {
+		return "", "", fmt.Errorf("unexpected author identity %q", strings.TrimSpace(string(output)))
+	}
+	return name, email, nil
+}
+
+// ParseIdent splits an identity as git prints it, "Name <email> 1700000000
+// +0100", into name and email
+func ParseIdent(ident string) (name, email string, ok bool) {
+	open := strings.IndexByte(ident, '<')
+	end := strings.IndexByte(ident, '>')
+	if open < 0 || end < open {
+		return "", "", false
+	}
+	return strings.TrimSpace(ident[:open]), ident[open+1 : end], true
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		dirs       []string
+		subcommand string
+	}{
+		{"plain", []string{"push", "--force"}, nil, "push"},
+		{"config before", []string{"-c", "user.email=a@b.com", "commit", "-m", "x"}, nil, "commit"},
+		{"directories", []string{"-C", "src", "-C", "app", "--no-pager", "log"}, []string{"src", "app"}, "log"},
+		{"joined value", []string{"--git-dir=.git", "fetch"}, nil, "fetch"},
+		{"separate value", []string{"--work-tree", "tree", "status"}, nil, "status"},
+		{"no subcommand", []string{"--version"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, subcommand := ParseCommandLine(tt.args)
+			if !reflect.DeepEqual(dirs, tt.dirs) {
+				t.Errorf("expected dirs %q, got %q", tt.dirs, dirs)
+			}
+			if subcommand != tt.subcommand {
+				t.Errorf("expected %q, got %q", tt.subcommand, subcommand)
+			}
+		})
+	}
+}
+
+func TestParseIdent(t *testing.T) {
+	tests := []struct {
+		ident string
+		name  string
+		email string
+		ok    bool
+	}{
+		{"Me Work <me@work.com> 1700000000 +0100\n", "Me Work", "me@work.com", true},
+		{"<me@work.com> 1700000000 +0000", "", "me@work.com", true},
+		{"Me Work 1700000000 +0100", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ident, func(t *testing.T) {
+			name, email, ok := ParseIdent(tt.ident)
+			if name != tt.name || email != tt.email || ok != tt.ok {
+				t.Errorf("expected %q %q %v, got %q %q %v", tt.name, tt.email, tt.ok, name, email, ok)
+			}
+		})
+	}
+}
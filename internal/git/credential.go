@@ -0,0 +1,97 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Credential is a request or answer of the git credential helper
+// protocol: key=value lines ended by a blank line or end of input
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+	// Other holds the attributes gitws does not use, kept so they can be
+	// passed on
+	Other []string
+}
+
+// ReadCredential reads a credential description from r, as git sends it
+// to helpers
+func ReadCredential(r io.Reader) (Credential, error) {
+	var c Credential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return c, fmt.Errorf("invalid credential line %q", line)
+		}
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		default:
+			c.Other = append(c.Other, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return c, fmt.Errorf("failed to read credential: %w", err)
+	}
+	return c, nil
+}
+
+// WriteTo writes the set attributes of c in the credential protocol
+func (c Credential) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, attr := range [][2]string{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	} {
+		if attr[1] != "" {
+			fmt.Fprintf(&b, "%s=%s\n", attr[0], attr[1])
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// HostName returns the host of the credential without its port
+func (c Credential) HostName() string {
+	host := c.Host
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.ToLower(host)
+}
+
+// CredentialHelper returns a credential.helper value running exe with
+// args. git runs helpers given by absolute path through the shell, so
+// words with shell syntax in them are quoted.
+func CredentialHelper(exe string, args ...string) string {
+	var words []string
+	for _, word := range append([]string{exe}, args...) {
+		if word == "" || strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@+") != "" {
+			word = shellQuote(word)
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
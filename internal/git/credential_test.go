@@ -0,0 +1,58 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadCredential(t *testing.T) {
+	input := "protocol=https\nhost=github.com:8443\npath=acme/app.git\nwwwauth[]=Basic realm=\"GitHub\"\n\nignored=after blank line\n"
+	c, err := ReadCredential(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Protocol != "https" || c.Host != "github.com:8443" || c.Path != "acme/app.git" {
+		t.Errorf("unexpected credential %+v", c)
+	}
+	if len(c.Other) != 1 || c.Other[0] != `wwwauth[]=Basic realm="GitHub"` {
+		t.Errorf("expected the unknown attribute to be kept, got %q", c.Other)
+	}
+	if host := c.HostName(); host != "github.com" {
+		t.Errorf("expected %q, got %q", "github.com", host)
+	}
+
+	if _, err := ReadCredential(strings.NewReader("protocol\n")); err == nil {
+		t.Errorf("expected an error for a line without =")
+	}
+}
+
+func TestCredentialWriteTo(t *testing.T) {
+	var b strings.Builder
+	c := Credential{Protocol: "https", Host: "github.com", Username: "x-access-token", Password: "secret"}
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "protocol=https\nhost=github.com\nusername=x-access-token\npassword=secret\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestCredentialHelper(t *testing.T) {
+	tests := []struct {
+		exe      string
+		expected string
+	}{
+		{"/usr/local/bin/gitws", "/usr/local/bin/gitws credential-helper --workspace work"},
+		{"/Users/me/My Tools/gitws", "'/Users/me/My Tools/gitws' credential-helper --workspace work"},
+		{"/opt/it's/gitws", `'/opt/it'\''s/gitws' credential-helper --workspace work`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exe, func(t *testing.T) {
+			if result := CredentialHelper(tt.exe, "credential-helper", "--workspace", "work"); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
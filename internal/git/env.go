@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Identity is what every git process started by a command, however deeply
+// nested, must see to act as a workspace
+type Identity struct {
+	Name   string
+	Email  string
+	SSHKey string
+	// Config holds extra key/value pairs, e.g. signing settings
+	Config [][2]string
+}
+
+// IdentityEnv returns environ with id applied. Only variables git passes
+// on to every child process are used: submodule updates, subtree pulls and
+// tools that shell out to git inherit them, while -c options and the
+// repository's own config would not reach them.
+//
+// GIT_SSH_COMMAND and the author/committer variables are replaced. Config
+// pairs are appended after any GIT_CONFIG_COUNT entries already present,
+// so nesting one exec inside another lets the inner identity win.
+func IdentityEnv(environ []string, id Identity) []string {
+	vars := make(map[string]string)
+	var order []string
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if _, seen := vars[key]; !seen {
+			order = append(order, key)
+		}
+		vars[key] = value
+	}
+	set := func(key, value string) {
+		if _, seen := vars[key]; !seen {
+			order = append(order, key)
+		}
+		vars[key] = value
+	}
+
+	set("GIT_AUTHOR_NAME", id.Name)
+	set("GIT_AUTHOR_EMAIL", id.Email)
+	set("GIT_COMMITTER_NAME", id.Name)
+	set("GIT_COMMITTER_EMAIL", id.Email)
+	if id.SSHKey != "" {
+		set("GIT_SSH_COMMAND", fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", shellQuote(id.SSHKey)))
+	}
+
+	count, err := strconv.Atoi(vars["GIT_CONFIG_COUNT"])
+	if err != nil || count < 0 {
+		count = 0
+	}
+	pairs := append([][2]string{{"user.name", id.Name}, {"user.email", id.Email}}, id.Config...)
+	for _, pair := range pairs {
+		set(fmt.Sprintf("GIT_CONFIG_KEY_%d", count), pair[0])
+		set(fmt.Sprintf("GIT_CONFIG_VALUE_%d", count), pair[1])
+		count++
+	}
+	set("GIT_CONFIG_COUNT", strconv.Itoa(count))
+
+	result := make([]string, 0, len(order))
+	for _, key := range order {
+		result = append(result, key+"="+vars[key])
+	}
+	return result
+}
+
+// ProbeIdentity runs a git alias in dir under env that starts a nested git,
+// the way submodule and subtree commands do, and returns the email and
+// author identity that nested process sees
+func ProbeIdentity(ctx context.Context, dir string, env []string) (email, author string, err error) {
+	cmd := commandContext(ctx, "-c", "alias.gws-probe=!git config --get user.email; git var GIT_AUTHOR_IDENT", "gws-probe")
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to run nested git probe: %w", err)
+	}
+
+	lines := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)
+	if len(lines) < 2 {
+		return "", "", fmt.Errorf("unexpected nested git probe output: %q", output)
+	}
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]), nil
+}
@@ -0,0 +1,156 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Failures recognized from git's stderr. Test for them with errors.Is.
+var (
+	ErrNotARepo      = errors.New("not a git repository")
+	ErrAuthFailed    = errors.New("authentication failed")
+	ErrRepoNotFound  = errors.New("repository not found")
+	ErrRemoteExists  = errors.New("remote already exists")
+	ErrHostUnreached = errors.New("host unreachable")
+)
+
+// stderrPatterns classify a failure by what git printed, checked in order
+var stderrPatterns = []struct {
+	substr string
+	err    error
+}{
+	{"not a git repository", ErrNotARepo},
+	{"permission denied (publickey", ErrAuthFailed},
+	{"authentication failed", ErrAuthFailed},
+	{"could not read username", ErrAuthFailed},
+	{"host key verification failed", ErrAuthFailed},
+	{"repository not found", ErrRepoNotFound},
+	{"does not appear to be a git repository", ErrRepoNotFound},
+	{"already exists", ErrRemoteExists},
+	{"could not resolve hostname", ErrHostUnreached},
+	{"could not resolve host", ErrHostUnreached},
+	{"connection timed out", ErrHostUnreached},
+	{"connection refused", ErrHostUnreached},
+	{"network is unreachable", ErrHostUnreached},
+}
+
+// Error is a failed git command with what it printed on stderr
+type Error struct {
+	Args   []string // arguments, without the git binary
+	Stderr string
+	Err    error // how the command failed, usually an *exec.ExitError
+	kind   error // one of the sentinel errors above, or nil
+}
+
+// Error returns git's explanation of the failure, or the exit status when
+// it printed none
+func (e *Error) Error() string {
+	if msg := stderrMessage(e.Stderr); msg != "" {
+		return msg
+	}
+	return e.Err.Error()
+}
+
+// Unwrap lets errors.Is match both the sentinel and the exec error
+func (e *Error) Unwrap() []error {
+	if e.kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.kind, e.Err}
+}
+
+// newError wraps err from running cmd with its stderr
+func newError(cmd *exec.Cmd, stderr string, err error) *Error {
+	e := &Error{Args: cmd.Args[1:], Stderr: stderr, Err: err}
+	lower := strings.ToLower(stderr)
+	for _, p := range stderrPatterns {
+		// "already exists" only means a remote for remote commands; for
+		// clone it is the destination directory
+		if p.err == ErrRemoteExists && (len(e.Args) == 0 || e.Args[0] != "remote") {
+			continue
+		}
+		if strings.Contains(lower, p.substr) {
+			e.kind = p.err
+			break
+		}
+	}
+	return e
+}
+
+// run runs cmd, returning an *Error with git's stderr when it fails.
+// Anything already reading cmd.Stderr, such as a progress parser, still
+// sees it.
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return newError(cmd, stderr.String(), err)
+	}
+	return nil
+}
+
+// runOutput runs cmd and returns its stdout, with an *Error when it fails
+func runOutput(cmd *exec.Cmd) ([]byte, error) {
+	out, err := cmd.Output()
+	if err != nil {
+		stderr := ""
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr = string(exitErr.Stderr)
+		}
+		return out, newError(cmd, stderr, err)
+	}
+	return out, nil
+}
+
+// exitCode returns the exit code of a failed git command, or -1 when it
+// did not run to completion
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// maxMessageLines limits how much of git's stderr goes into an error
+const maxMessageLines = 3
+
+// stderrMessage picks the lines of git's stderr that explain a failure:
+// progress, hints and the generic access advice are dropped, as are the
+// fatal:/error: prefixes
+func stderrMessage(stderr string) string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(stderr, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "hint:"),
+			strings.HasPrefix(line, "Cloning into"),
+			strings.HasPrefix(line, "remote: Enumerating"),
+			strings.HasPrefix(line, "remote: Counting"),
+			strings.HasPrefix(line, "remote: Compressing"),
+			strings.HasPrefix(line, "remote: Total"),
+			strings.HasPrefix(line, "Receiving objects"),
+			strings.HasPrefix(line, "Resolving deltas"),
+			strings.HasPrefix(line, "Please make sure you have the correct access rights"),
+			line == "and the repository exists.":
+			continue
+		}
+		for _, prefix := range []string{"fatal: ", "error: ", "ERROR: "} {
+			line = strings.TrimPrefix(line, prefix)
+		}
+		lines = append(lines, strings.TrimSuffix(line, "."))
+	}
+	if len(lines) > maxMessageLines {
+		lines = lines[:maxMessageLines]
+	}
+	return strings.Join(lines, "; ")
+}
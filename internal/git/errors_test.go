@@ -0,0 +1,92 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	exitErr := errors.New("exit status 128")
+	tests := []struct {
+		name     string
+		args     []string
+		stderr   string
+		message  string
+		expected error
+	}{
+		{
+			name:     "ssh key rejected",
+			args:     []string{"clone", "--", "git@github.com-work:acme/app.git", "app"},
+			stderr:   "Cloning into 'app'...\ngit@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists.\n",
+			message:  "git@github.com: Permission denied (publickey); Could not read from remote repository",
+			expected: ErrAuthFailed,
+		},
+		{
+			name:     "repository not found",
+			args:     []string{"clone", "--", "git@github.com-work:acme/nope.git", "nope"},
+			stderr:   "Cloning into 'nope'...\nERROR: Repository not found.\nfatal: Could not read from remote repository.\n",
+			message:  "Repository not found; Could not read from remote repository",
+			expected: ErrRepoNotFound,
+		},
+		{
+			name:     "not a repository",
+			args:     []string{"rev-parse", "--show-cdup"},
+			stderr:   "fatal: not a git repository (or any of the parent directories): .git\n",
+			message:  "not a git repository (or any of the parent directories): .git",
+			expected: ErrNotARepo,
+		},
+		{
+			name:     "remote exists",
+			args:     []string{"remote", "add", "origin", "git@github.com:a/b.git"},
+			stderr:   "error: remote origin already exists.\n",
+			message:  "remote origin already exists",
+			expected: ErrRemoteExists,
+		},
+		{
+			name:    "destination exists",
+			args:    []string{"clone", "--", "git@github.com:a/b.git", "b"},
+			stderr:  "fatal: destination path 'b' already exists and is not an empty directory.\n",
+			message: "destination path 'b' already exists and is not an empty directory",
+		},
+		{
+			name:     "unreachable",
+			args:     []string{"ls-remote", "git@github.com:a/b.git"},
+			stderr:   "ssh: Could not resolve hostname github.com: Name or service not known\r\nfatal: Could not read from remote repository.\n",
+			message:  "ssh: Could not resolve hostname github.com: Name or service not known; Could not read from remote repository",
+			expected: ErrHostUnreached,
+		},
+		{
+			name:    "no stderr",
+			args:    []string{"push"},
+			message: "exit status 128",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("git", tt.args...)
+			err := newError(cmd, tt.stderr, exitErr)
+
+			if err.Error() != tt.message {
+				t.Errorf("expected %q, got %q", tt.message, err.Error())
+			}
+			if !errors.Is(err, exitErr) {
+				t.Errorf("expected the error to wrap the exec error")
+			}
+			for _, sentinel := range []error{ErrNotARepo, ErrAuthFailed, ErrRepoNotFound, ErrRemoteExists, ErrHostUnreached} {
+				if errors.Is(err, sentinel) != (sentinel == tt.expected) {
+					t.Errorf("expected errors.Is(%v) to be %v", sentinel, sentinel == tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestStderrMessageLimit(t *testing.T) {
+	stderr := "error: one\nerror: two\nhint: try this\nerror: three\nerror: four\n"
+	expected := "one; two; three"
+	if result := stderrMessage(stderr); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
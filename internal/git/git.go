@@ -1,50 +1,131 @@
 package git
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
 )
 
+// Version is a parsed git version number
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// HasconfigMinVersion is the first git release supporting
+// includeIf "hasconfig:remote.*.url:" conditions
+var HasconfigMinVersion = Version{Major: 2, Minor: 36}
+
+// String returns the dotted version string
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is greater than or equal to min
+func (v Version) AtLeast(min Version) bool {
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+// ParseVersion parses the output of "git --version",
+// e.g. "git version 2.39.2 (Apple Git-143)"
+func ParseVersion(output string) (Version, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return Version{}, fmt.Errorf("unrecognized git version output: %q", output)
+	}
+
+	var v Version
+	parts := strings.Split(fields[2], ".")
+	targets := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := 0; i < len(parts) && i < len(targets); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			if i == 0 {
+				return Version{}, fmt.Errorf("unrecognized git version output: %q", output)
+			}
+			break // e.g. "2.45.rc0"
+		}
+		*targets[i] = n
+	}
+	return v, nil
+}
+
+// GetVersion returns the installed git version
+func GetVersion() (Version, error) {
+	_, version, err := Locate()
+	return version, err
+}
+
 // CheckGitPresence checks if git is available and returns version
 func CheckGitPresence() (string, error) {
-	cmd := exec.Command("git", "--version")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("git not found: %w", err)
+	if _, _, err := Locate(); err != nil {
+		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return located.output, nil
 }
 
-// IsGitRepo checks if the current directory is a git repository
+// IsGitRepo reports whether path is the top level of a working tree: a
+// repository, a linked worktree or a submodule
 func IsGitRepo(path string) bool {
-	gitDir := filepath.Join(path, ".git")
-	return isDir(gitDir)
+	cdup, err := showCdup(path)
+	return err == nil && cdup == ""
 }
 
-// FindGitRoot finds the root of the git repository containing the given path
+// FindGitRoot finds the top level of the working tree containing the given
+// path. Git does the lookup, so worktrees and submodules (whose .git is a
+// file) are found too. The result keeps path's spelling rather than
+// resolving symlinks, so it can be compared with workspace roots.
 func FindGitRoot(path string) (string, error) {
-	current := path
-	for {
-		if IsGitRepo(current) {
-			return current, nil
-		}
+	cdup, err := showCdup(path)
+	if err != nil {
+		return "", ErrNotARepo
+	}
+	return filepath.Join(path, cdup), nil
+}
 
-		parent := filepath.Dir(current)
-		if parent == current {
-			return "", fmt.Errorf("not in a git repository")
-		}
-		current = parent
+// showCdup returns the relative path from dir up to the top level of its
+// working tree ("" at the top level)
+func showCdup(dir string) (string, error) {
+	if !isDir(dir) {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	if cdup, ok, err := readNative(func() (string, error) { return nativeShowCdup(dir) }); ok {
+		return cdup, err
 	}
+	cmd := command("rev-parse", "--show-cdup")
+	cmd.Dir = dir
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
 }
 
 // GetRemoteURL gets the origin remote URL
 func GetRemoteURL(repoPath string) (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
+	if url, ok, err := readNative(func() (string, error) { return nativeRemoteURL(repoPath) }); ok {
+		if err != nil {
+			return "", fmt.Errorf("failed to get remote URL: %w", err)
+		}
+		return url, nil
+	}
+	cmd := command("remote", "get-url", "origin")
 	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	output, err := runOutput(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to get remote URL: %w", err)
 	}
@@ -53,30 +134,132 @@ func GetRemoteURL(repoPath string) (string, error) {
 
 // SetRemoteURL sets the origin remote URL
 func SetRemoteURL(repoPath, url string) error {
-	cmd := exec.Command("git", "remote", "set-url", "origin", url)
+	cmd := command("remote", "set-url", "origin", url)
 	cmd.Dir = repoPath
-	if err := cmd.Run(); err != nil {
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, localConfigPath(repoPath)); err != nil {
 		return fmt.Errorf("failed to set remote URL: %w", err)
 	}
 	return nil
 }
 
+// RemoteURLs returns the URL of every remote, keyed by remote name
+func RemoteURLs(repoPath string) (map[string]string, error) {
+	if remotes, ok, err := readNative(func() (map[string]string, error) { return nativeRemoteURLs(repoPath) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remotes: %w", err)
+		}
+		return remotes, nil
+	}
+	cmd := command("config", "--local", "--get-regexp", `^remote\..*\.url$`)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		// Exit code 1 means no remotes
+		if exitCode(err) == 1 {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	remotes := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, url, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".url")
+		remotes[name] = url
+	}
+	return remotes, nil
+}
+
+// SetNamedRemoteURL sets the URL of a named remote
+func SetNamedRemoteURL(repoPath, name, url string) error {
+	cmd := command("remote", "set-url", name, url)
+	cmd.Dir = repoPath
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, localConfigPath(repoPath)); err != nil {
+		return fmt.Errorf("failed to set URL of remote %s: %w", name, err)
+	}
+	return nil
+}
+
 // GetLocalConfig gets a local git config value
 func GetLocalConfig(repoPath, key string) (string, error) {
-	cmd := exec.Command("git", "config", "--local", key)
+	if value, ok, err := readNative(func() (string, error) { return nativeLocalConfig(repoPath, key) }); ok {
+		if err != nil {
+			return "", fmt.Errorf("failed to get local config %s: %w", key, err)
+		}
+		return value, nil
+	}
+	cmd := command("config", "--local", key)
 	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	output, err := runOutput(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to get local config %s: %w", key, err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetConfig gets the effective git config value for a repository, taking
+// global and included files into account
+func GetConfig(repoPath, key string) (string, error) {
+	if value, ok, err := readNative(func() (string, error) { return nativeConfig(repoPath, key) }); ok {
+		if err != nil {
+			return "", fmt.Errorf("failed to get config %s: %w", key, err)
+		}
+		return value, nil
+	}
+	cmd := command("config", key)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get config %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetRemoteHead returns the default branch of origin as last fetched, or
+// "" when origin/HEAD is not known
+func GetRemoteHead(repoPath string) string {
+	if head, ok, _ := readNative(func() (string, error) { return nativeRemoteHead(repoPath) }); ok {
+		return head
+	}
+	cmd := command("symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+}
+
 // SetLocalConfig sets a local git config value
 func SetLocalConfig(repoPath, key, value string) error {
-	cmd := exec.Command("git", "config", "--local", key, value)
+	cmd := command("config", "--local", key, value)
 	cmd.Dir = repoPath
-	if err := cmd.Run(); err != nil {
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, localConfigPath(repoPath)); err != nil {
+		return fmt.Errorf("failed to set local config %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetLocalConfigAll replaces every local value of a multi-valued key
+// with values, in order
+func SetLocalConfigAll(repoPath, key string, values ...string) error {
+	err := fsutil.RecordChange(func() error {
+		unset := command("config", "--local", "--unset-all", key)
+		unset.Dir = repoPath
+		_ = run(unset) // Fails when the key is not set yet
+		for _, value := range values {
+			cmd := command("config", "--local", "--add", key, value)
+			cmd.Dir = repoPath
+			if err := run(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, localConfigPath(repoPath))
+	if err != nil {
 		return fmt.Errorf("failed to set local config %s: %w", key, err)
 	}
 	return nil
@@ -84,120 +267,526 @@ func SetLocalConfig(repoPath, key, value string) error {
 
 // UnsetLocalConfig unsets a local git config value
 func UnsetLocalConfig(repoPath, key string) error {
-	cmd := exec.Command("git", "config", "--local", "--unset", key)
+	cmd := command("config", "--local", "--unset", key)
 	cmd.Dir = repoPath
-	if err := cmd.Run(); err != nil {
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, localConfigPath(repoPath)); err != nil {
 		// Ignore error if key doesn't exist
 		return nil
 	}
 	return nil
 }
 
+// GetGlobalConfig gets a global git config value
+func GetGlobalConfig(key string) (string, error) {
+	if value, ok, err := readNative(func() (string, error) { return nativeGlobalConfig(key) }); ok {
+		if err != nil {
+			return "", fmt.Errorf("failed to get global config %s: %w", key, err)
+		}
+		return value, nil
+	}
+	cmd := command("config", "--global", key)
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get global config %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetFileConfig gets a value from a git config file
+func GetFileConfig(path, key string) (string, error) {
+	cmd := command("config", "--file", path, key)
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get config %s from %s: %w", key, path, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FileConfigKeys returns the keys a git config file sets, in order
+func FileConfigKeys(path string) ([]string, error) {
+	cmd := command("config", "--file", path, "--name-only", "--list")
+	output, err := runOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config in %s: %w", path, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// SetGlobalConfig sets a global git config value
+func SetGlobalConfig(key, value string) error {
+	cmd := command("config", "--global", key, value)
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, globalConfigPath()); err != nil {
+		return fmt.Errorf("failed to set global config %s: %w", key, err)
+	}
+	return nil
+}
+
+// localConfigPath returns the file 'git config --local' writes in a
+// repository, or "" when it cannot be found
+func localConfigPath(repoPath string) string {
+	common, err := CommonDir(repoPath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(common, "config")
+}
+
+// globalConfigPath returns the file 'git config --global' writes, or ""
+// when it cannot be found
+func globalConfigPath() string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if path := filepath.Join(home, ".gitconfig"); fsutil.FileExists(path) {
+		return path
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		if path := filepath.Join(xdg, "git", "config"); fsutil.FileExists(path) {
+			return path
+		}
+	} else if path := filepath.Join(home, ".config", "git", "config"); fsutil.FileExists(path) {
+		return path
+	}
+	return filepath.Join(home, ".gitconfig")
+}
+
+// ConfigEntry is a single key/value pair from git config
+type ConfigEntry struct {
+	Key   string
+	Value string
+}
+
+// GetGlobalConfigRegexp returns global config entries whose key matches pattern
+func GetGlobalConfigRegexp(pattern string) ([]ConfigEntry, error) {
+	if entries, ok, err := readNative(func() ([]ConfigEntry, error) { return nativeGlobalConfigRegexp(pattern) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global config: %w", err)
+		}
+		return entries, nil
+	}
+	cmd := command("config", "--global", "--get-regexp", pattern)
+	output, err := runOutput(cmd)
+	if err != nil {
+		if exitCode(err) == 1 {
+			return nil, nil // No matching keys
+		}
+		return nil, fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	var entries []ConfigEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		entries = append(entries, ConfigEntry{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+// ConfigOrigin is one value of a config key together with where it was set
+type ConfigOrigin struct {
+	Scope  string // "system", "global", "local", "worktree" or "command"
+	Origin string // e.g. "file:/home/me/.gitconfig" or "command line:"
+	Value  string
+}
+
+// File returns the file the value was read from, or "" when it did not
+// come from a file
+func (o ConfigOrigin) File() string {
+	return strings.TrimPrefix(o.Origin, "file:")
+}
+
+// ConfigOrigins returns every value of key that applies in a repository,
+// in the order git reads them, so the last one is the one git uses.
+// It needs git 2.26 for --show-scope.
+func ConfigOrigins(repoPath, key string) ([]ConfigOrigin, error) {
+	if origins, ok, err := readNative(func() ([]ConfigOrigin, error) { return nativeConfigOrigins(repoPath, key) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to trace config %s: %w", key, err)
+		}
+		return origins, nil
+	}
+	cmd := command("config", "--show-origin", "--show-scope", "--null", "--get-all", key)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		if exitCode(err) == 1 {
+			return nil, nil // Not set anywhere
+		}
+		return nil, fmt.Errorf("failed to trace config %s: %w", key, err)
+	}
+	return parseConfigOrigins(string(output)), nil
+}
+
+// parseConfigOrigins parses the NUL-separated scope, origin and value
+// triples of 'git config --show-scope --show-origin --null'
+func parseConfigOrigins(output string) []ConfigOrigin {
+	fields := strings.Split(output, "\x00")
+	var origins []ConfigOrigin
+	for i := 0; i+2 < len(fields); i += 3 {
+		origins = append(origins, ConfigOrigin{Scope: fields[i], Origin: fields[i+1], Value: fields[i+2]})
+	}
+	return origins
+}
+
+// UnsetGlobalConfig unsets a global git config value
+func UnsetGlobalConfig(key string) error {
+	cmd := command("config", "--global", "--unset", key)
+	if err := fsutil.RecordChange(func() error { return run(cmd) }, globalConfigPath()); err != nil {
+		// Ignore error if key doesn't exist
+		return nil
+	}
+	return nil
+}
+
+// CloneOptions are the optional settings of CloneRepository
+type CloneOptions struct {
+	Branch            string
+	Depth             int      // shallow clone depth; 0 clones full history
+	Filter            string   // partial clone filter, e.g. "blob:none"
+	Sparse            []string // sparse-checkout directories; empty checks out everything
+	RecurseSubmodules bool
+	Mirror            bool     // bare mirror of all refs, for backups
+	Env               []string // environment for git; nil inherits gitws's
+	// Progress, when set, is told how many objects have been received
+	Progress func(received, total int64)
+}
+
 // CloneRepository clones a repository
-func CloneRepository(url, destPath, branch string) error {
+func CloneRepository(ctx context.Context, url, destPath string, opts CloneOptions) error {
+	cmd := commandContext(ctx, cloneArgs(url, destPath, opts)...)
+	cmd.Env = opts.Env
+	if opts.Progress != nil {
+		cmd.Stderr = &progressWriter{report: opts.Progress}
+	}
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	if len(opts.Sparse) == 0 {
+		return nil
+	}
+
+	cmd = commandContext(ctx, append([]string{"sparse-checkout", "set"}, opts.Sparse...)...)
+	cmd.Dir = destPath
+	cmd.Env = opts.Env
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to set sparse-checkout paths: %w", err)
+	}
+
+	// Submodules inside the sparse paths only appear once they are set
+	if opts.RecurseSubmodules {
+		cmd = commandContext(ctx, "submodule", "update", "--init", "--recursive")
+		cmd.Dir = destPath
+		cmd.Env = opts.Env
+		if err := run(cmd); err != nil {
+			return fmt.Errorf("failed to update submodules: %w", err)
+		}
+	}
+	return nil
+}
+
+// cloneArgs returns the git clone command line for opts
+func cloneArgs(url, destPath string, opts CloneOptions) []string {
 	args := []string{"clone"}
+	if opts.Mirror {
+		args = append(args, "--mirror")
+	}
+	if opts.Progress != nil {
+		args = append(args, "--progress")
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--sparse")
+	} else if opts.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	return append(args, "--", url, destPath)
+}
+
+// receivingPattern matches the object count in git's transfer progress,
+// as in "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s"
+var receivingPattern = regexp.MustCompile(`^Receiving objects:\s+\d+% \((\d+)/(\d+)\)`)
+
+// progressWriter reads git's --progress output, whose updates end in \r,
+// and reports the objects received
+type progressWriter struct {
+	report  func(received, total int64)
+	pending []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexAny(w.pending, "\r\n")
+		if i < 0 {
+			break
+		}
+		line := strings.TrimPrefix(string(w.pending[:i]), "remote: ")
+		w.pending = w.pending[i+1:]
+		if m := receivingPattern.FindStringSubmatch(line); m != nil {
+			received, _ := strconv.ParseInt(m[1], 10, 64)
+			total, _ := strconv.ParseInt(m[2], 10, 64)
+			w.report(received, total)
+		}
+	}
+	return len(p), nil
+}
+
+// UpdateMirror fetches new and changed refs into a mirror clone and drops
+// refs deleted upstream
+func UpdateMirror(ctx context.Context, mirrorPath string) error {
+	cmd := commandContext(ctx, "remote", "update", "--prune")
+	cmd.Dir = mirrorPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to update mirror: %w", err)
+	}
+	return nil
+}
+
+// InitRepository creates a new repository with the given initial branch
+func InitRepository(path, branch string) error {
+	args := []string{"init"}
 	if branch != "" {
-		args = append(args, "--branch", branch)
+		args = append(args, "--initial-branch", branch)
 	}
-	args = append(args, url, destPath)
+	args = append(args, path)
 
-	cmd := exec.Command("git", args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository: %w", err)
+	cmd := command(args...)
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 	return nil
 }
 
-// InstallHooks installs pre-commit and pre-push hooks
-func InstallHooks(repoPath string) error {
-	hookDir := filepath.Join(repoPath, ".git", "hooks")
+// SetHeadBranch points HEAD at branch, which names the first branch
+// created in an empty repository
+func SetHeadBranch(repoPath, branch string) error {
+	cmd := command("symbolic-ref", "HEAD", "refs/heads/"+branch)
+	cmd.Dir = repoPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to set HEAD to %s: %w", branch, err)
+	}
+	return nil
+}
 
-	// Install pre-commit hook
-	preCommitHook := `#!/bin/sh
-# Git Workspace Guard - Pre-commit Hook
+// AddRemote adds a named remote
+func AddRemote(repoPath, name, url string) error {
+	cmd := command("remote", "add", name, url)
+	cmd.Dir = repoPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to add remote %s: %w", name, err)
+	}
+	return nil
+}
 
-# Get current user email
-CURRENT_EMAIL=$(git config user.email)
+// Fetch fetches a remote
+func Fetch(ctx context.Context, repoPath, remote string) error {
+	cmd := commandContext(ctx, "fetch", "--quiet", remote)
+	cmd.Dir = repoPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", remote, err)
+	}
+	return nil
+}
 
-# Get workspace from remote URL
-REMOTE_URL=$(git remote get-url origin 2>/dev/null)
-if [ -z "$REMOTE_URL" ]; then
-    echo "Warning: No origin remote found"
-    exit 0
-fi
+// CurrentBranch returns the branch checked out in repoPath, or "" when
+// HEAD is detached
+func CurrentBranch(repoPath string) string {
+	cmd := command("symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
 
-# Extract host from SSH URL (e.g., git@github-work:org/repo.git -> github-work)
-HOST=$(echo "$REMOTE_URL" | sed -n 's/git@\([^:]*\):.*/\1/p')
+// CommitSubject returns the subject line of the commit rev names
+func CommitSubject(repoPath, rev string) (string, error) {
+	cmd := command("log", "-1", "--format=%s", rev, "--")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-if [ -z "$HOST" ]; then
-    echo "Warning: Could not extract host from remote URL"
-    exit 0
-fi
+// RefExists returns true if ref, e.g. refs/remotes/upstream/main, exists
+func RefExists(repoPath, ref string) bool {
+	cmd := command("rev-parse", "--verify", "--quiet", ref)
+	cmd.Dir = repoPath
+	return run(cmd) == nil
+}
 
-# Check if this is a gitws managed workspace
-if echo "$HOST" | grep -q "gws\|gitws"; then
-    echo "✓ Git workspace guard: Using managed workspace"
-    exit 0
-fi
+// CommitAll stages every file in the working tree and commits it
+func CommitAll(repoPath, message string) error {
+	add := command("add", "--all")
+	add.Dir = repoPath
+	if err := run(add); err != nil {
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
 
-# For non-managed workspaces, just warn
-echo "⚠️  Git workspace guard: Using unmanaged workspace ($HOST)"
-echo "   Current email: $CURRENT_EMAIL"
-echo "   Consider using 'gitws init' to set up workspace isolation"
-exit 0
-`
+	commit := command("commit", "--quiet", "--allow-empty", "-m", message)
+	commit.Dir = repoPath
+	if err := run(commit); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
 
-	preCommitPath := filepath.Join(hookDir, "pre-commit")
-	if err := os.WriteFile(preCommitPath, []byte(preCommitHook), 0755); err != nil {
-		return fmt.Errorf("failed to write pre-commit hook: %w", err)
+// Push pushes a branch to a remote and sets it as upstream
+func Push(ctx context.Context, repoPath, remote, branch string) error {
+	cmd := commandContext(ctx, "push", "--set-upstream", remote, branch)
+	cmd.Dir = repoPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to push %s to %s: %w", branch, remote, err)
 	}
+	return nil
+}
 
-	// Install pre-push hook
-	prePushHook := `#!/bin/sh
-# Git Workspace Guard - Pre-push Hook
+// RunWithEnv runs git with extra environment variables
+func RunWithEnv(ctx context.Context, env []string, args ...string) error {
+	cmd := commandContext(ctx, args...)
+	cmd.Env = append(os.Environ(), env...)
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
 
-# Get current user email
-CURRENT_EMAIL=$(git config user.email)
+// StagedFileSizes returns the size of every file added or modified in the
+// index, keyed by path
+func StagedFileSizes(repoPath string) (map[string]int64, error) {
+	cmd := command("diff", "--cached", "--name-only", "-z", "--diff-filter=AM")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
 
-# Get workspace from remote URL
-REMOTE_URL=$(git remote get-url origin 2>/dev/null)
-if [ -z "$REMOTE_URL" ]; then
-    echo "Warning: No origin remote found"
-    exit 0
-fi
+	sizes := make(map[string]int64)
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path == "" {
+			continue
+		}
+		cmd := command("cat-file", "-s", ":"+path)
+		cmd.Dir = repoPath
+		out, err := runOutput(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get size of %s: %w", path, err)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse size of %s: %w", path, err)
+		}
+		sizes[path] = size
+	}
 
-# Extract host from SSH URL
-HOST=$(echo "$REMOTE_URL" | sed -n 's/git@\([^:]*\):.*/\1/p')
+	return sizes, nil
+}
 
-if [ -z "$HOST" ]; then
-    echo "Warning: Could not extract host from remote URL"
-    exit 0
-fi
+// IsAncestor reports whether ancestor is reachable from commit. Commits
+// missing from the repository are not ancestors.
+func IsAncestor(repoPath, ancestor, commit string) bool {
+	cmd := command("merge-base", "--is-ancestor", ancestor, commit)
+	cmd.Dir = repoPath
+	return run(cmd) == nil
+}
 
-# Check if this is a gitws managed workspace
-if echo "$HOST" | grep -q "gws\|gitws"; then
-    echo "✓ Git workspace guard: Using managed workspace"
-    exit 0
-fi
+// CommitIdentity is a commit and the addresses it was made with
+type CommitIdentity struct {
+	SHA            string
+	AuthorEmail    string
+	CommitterEmail string
+}
 
-# For non-managed workspaces, just warn
-echo "⚠️  Git workspace guard: Using unmanaged workspace ($HOST)"
-echo "   Current email: $CURRENT_EMAIL"
-echo "   Consider using 'gitws init' to set up workspace isolation"
-exit 0
-`
+// CommitIdentities lists the commits revs selects, in git log order, with
+// the addresses they were authored and committed with. revs are passed to
+// git log as they are, e.g. --all or a range.
+func CommitIdentities(repoPath string, revs ...string) ([]CommitIdentity, error) {
+	if commits, ok, err := readNative(func() ([]CommitIdentity, error) { return nativeCommitIdentities(repoPath, revs) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+		return commits, nil
+	}
+	args := append([]string{"log", "--format=%H%x00%ae%x00%ce"}, revs...)
+	cmd := command(append(args, "--")...)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
 
-	prePushPath := filepath.Join(hookDir, "pre-push")
-	if err := os.WriteFile(prePushPath, []byte(prePushHook), 0755); err != nil {
-		return fmt.Errorf("failed to write pre-push hook: %w", err)
+	var commits []CommitIdentity
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, CommitIdentity{SHA: fields[0], AuthorEmail: fields[1], CommitterEmail: fields[2]})
 	}
+	return commits, nil
+}
 
-	return nil
+// SignedCommits reports, by SHA, whether each commit revs selects carries
+// a signature. The signature is not verified: verifying needs the
+// signers' keys, which a CI runner does not have.
+func SignedCommits(repoPath string, revs ...string) (map[string]bool, error) {
+	if signed, ok, err := readNative(func() (map[string]bool, error) { return nativeSignedCommits(repoPath, revs) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+		return signed, nil
+	}
+	args := append([]string{"log", "--format=raw"}, revs...)
+	cmd := command(append(args, "--")...)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return parseSignedCommits(string(output)), nil
+}
+
+// parseSignedCommits reads git log --format=raw output. Headers end at
+// the first blank line; message lines are indented, so a gpgsig header
+// cannot be confused with one.
+func parseSignedCommits(output string) map[string]bool {
+	signed := make(map[string]bool)
+	sha, inHeaders := "", false
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			sha, inHeaders = strings.Fields(line)[1], true
+			signed[sha] = false
+		case line == "":
+			inHeaders = false
+		case inHeaders && (strings.HasPrefix(line, "gpgsig ") || strings.HasPrefix(line, "gpgsig-sha256 ")):
+			signed[sha] = true
+		}
+	}
+	return signed
 }
 
 // CheckHooksInstalled checks if hooks are installed
 func CheckHooksInstalled(repoPath string) (bool, error) {
-	hookDir := filepath.Join(repoPath, ".git", "hooks")
+	hookDir := HooksDir(repoPath)
 
 	preCommitPath := filepath.Join(hookDir, "pre-commit")
 	prePushPath := filepath.Join(hookDir, "pre-push")
@@ -214,13 +803,10 @@ func GetSigningStatus(repoPath string) (enabled bool, method string, key string,
 	signCommit, err := GetLocalConfig(repoPath, "commit.gpgsign")
 	if err != nil {
 		// Check global config
-		cmd := exec.Command("git", "config", "--global", "commit.gpgsign")
-		cmd.Dir = repoPath
-		output, err := cmd.Output()
+		signCommit, err = GetGlobalConfig("commit.gpgsign")
 		if err != nil {
 			return false, "", "", nil // Signing not configured
 		}
-		signCommit = strings.TrimSpace(string(output))
 	}
 
 	enabled = signCommit == "true"
@@ -232,13 +818,9 @@ func GetSigningStatus(repoPath string) (enabled bool, method string, key string,
 	gpgFormat, err := GetLocalConfig(repoPath, "gpg.format")
 	if err != nil {
 		// Check global config
-		cmd := exec.Command("git", "config", "--global", "gpg.format")
-		cmd.Dir = repoPath
-		output, err := cmd.Output()
+		method, err = GetGlobalConfig("gpg.format")
 		if err != nil {
 			method = "gpg" // Default
-		} else {
-			method = strings.TrimSpace(string(output))
 		}
 	} else {
 		method = gpgFormat
@@ -248,14 +830,7 @@ func GetSigningStatus(repoPath string) (enabled bool, method string, key string,
 	signingKey, err := GetLocalConfig(repoPath, "user.signingkey")
 	if err != nil {
 		// Check global config
-		cmd := exec.Command("git", "config", "--global", "user.signingkey")
-		cmd.Dir = repoPath
-		output, err := cmd.Output()
-		if err != nil {
-			key = ""
-		} else {
-			key = strings.TrimSpace(string(output))
-		}
+		key, _ = GetGlobalConfig("user.signingkey")
 	} else {
 		key = signingKey
 	}
@@ -0,0 +1,274 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		hasErr   bool
+	}{
+		{"git version 2.39.2", Version{2, 39, 2}, false},
+		{"git version 2.39.3 (Apple Git-145)", Version{2, 39, 3}, false},
+		{"git version 2.36.0.windows.1", Version{2, 36, 0}, false},
+		{"git version 2.45.rc0", Version{2, 45, 0}, false},
+		{"not git", Version{}, true},
+		{"", Version{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseVersion(tt.input)
+
+			if tt.hasErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  Version
+		min      Version
+		expected bool
+	}{
+		{Version{2, 36, 0}, HasconfigMinVersion, true},
+		{Version{2, 35, 9}, HasconfigMinVersion, false},
+		{Version{3, 0, 0}, HasconfigMinVersion, true},
+		{Version{1, 99, 0}, HasconfigMinVersion, false},
+		{Version{2, 36, 1}, Version{2, 36, 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			if result := tt.version.AtLeast(tt.min); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVerifyVersion(t *testing.T) {
+	tests := []struct {
+		input  string
+		hasErr bool
+	}{
+		{"git version 2.39.2", false},
+		{"git version 2.13.0", false},
+		{"git version 2.12.5", true},
+		{"git version 1.9.1", true},
+		{"not git", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := verifyVersion(tt.input)
+			if tt.hasErr && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.hasErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIdentityEnv(t *testing.T) {
+	id := Identity{
+		Name:   "Jane Doe",
+		Email:  "jane@work.com",
+		SSHKey: "/home/jane/.ssh/id_ed25519_gws_work",
+		Config: [][2]string{{"commit.gpgsign", "true"}},
+	}
+
+	tests := []struct {
+		name     string
+		environ  []string
+		expected map[string]string
+	}{
+		{
+			name:    "fresh environment",
+			environ: []string{"PATH=/usr/bin"},
+			expected: map[string]string{
+				"PATH":               "/usr/bin",
+				"GIT_AUTHOR_EMAIL":   "jane@work.com",
+				"GIT_COMMITTER_NAME": "Jane Doe",
+				"GIT_SSH_COMMAND":    "ssh -i '/home/jane/.ssh/id_ed25519_gws_work' -o IdentitiesOnly=yes",
+				"GIT_CONFIG_COUNT":   "3",
+				"GIT_CONFIG_KEY_0":   "user.name",
+				"GIT_CONFIG_VALUE_1": "jane@work.com",
+				"GIT_CONFIG_KEY_2":   "commit.gpgsign",
+				"GIT_CONFIG_VALUE_2": "true",
+			},
+		},
+		{
+			name: "nested inside another identity",
+			environ: []string{
+				"GIT_AUTHOR_EMAIL=other@me.com",
+				"GIT_SSH_COMMAND=ssh -i '/home/jane/.ssh/other'",
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=user.email",
+				"GIT_CONFIG_VALUE_0=other@me.com",
+			},
+			expected: map[string]string{
+				"GIT_AUTHOR_EMAIL":   "jane@work.com",
+				"GIT_SSH_COMMAND":    "ssh -i '/home/jane/.ssh/id_ed25519_gws_work' -o IdentitiesOnly=yes",
+				"GIT_CONFIG_COUNT":   "4",
+				"GIT_CONFIG_VALUE_0": "other@me.com",
+				"GIT_CONFIG_KEY_2":   "user.email",
+				"GIT_CONFIG_VALUE_2": "jane@work.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := make(map[string]string)
+			for _, kv := range IdentityEnv(tt.environ, id) {
+				key, value, _ := strings.Cut(kv, "=")
+				if _, dup := result[key]; dup {
+					t.Errorf("duplicate variable %s", key)
+				}
+				result[key] = value
+			}
+			for key, expected := range tt.expected {
+				if result[key] != expected {
+					t.Errorf("%s: expected %q, got %q", key, expected, result[key])
+				}
+			}
+		})
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CloneOptions
+		expected string
+	}{
+		{"plain", CloneOptions{}, "clone -- url dest"},
+		{"progress", CloneOptions{Mirror: true, Progress: func(int64, int64) {}}, "clone --mirror --progress -- url dest"},
+		{"branch and depth", CloneOptions{Branch: "main", Depth: 1}, "clone --branch main --depth 1 -- url dest"},
+		{"partial", CloneOptions{Filter: "blob:none"}, "clone --filter=blob:none -- url dest"},
+		{"submodules", CloneOptions{RecurseSubmodules: true}, "clone --recurse-submodules -- url dest"},
+		{"sparse defers submodules", CloneOptions{Sparse: []string{"svc"}, RecurseSubmodules: true}, "clone --sparse -- url dest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := strings.Join(cloneArgs("url", "dest", tt.opts), " ")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var reports []string
+	w := &progressWriter{report: func(received, total int64) {
+		reports = append(reports, fmt.Sprintf("%d/%d", received, total))
+	}}
+
+	// Updates end in \r and may be split across writes
+	w.Write([]byte("Cloning into 'dest'...\nremote: Counting objects: 100% (10/10), done.\nReceiving objects:  40% (4/10)\rReceiving obj"))
+	w.Write([]byte("ects: 100% (10/10), 1.20 KiB | 1.20 MiB/s, done.\nResolving deltas: 100% (2/2), done.\n"))
+
+	expected := []string{"4/10", "10/10"}
+	if strings.Join(reports, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, reports)
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /code/acme/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /code/acme/app@feature/login
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/login
+
+worktree /code/acme/app@old
+HEAD 3333333333333333333333333333333333333333
+detached
+prunable gitdir file points to non-existent location
+`
+
+	expected := []Worktree{
+		{Path: "/code/acme/app", Branch: "main", Main: true},
+		{Path: "/code/acme/app@feature/login", Branch: "feature/login"},
+		{Path: "/code/acme/app@old", Prunable: true},
+	}
+
+	result := parseWorktreeList(output)
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d worktrees, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], result[i])
+		}
+	}
+}
+
+func TestParseConfigOrigins(t *testing.T) {
+	output := "global\x00file:/home/me/.gws/work/gitconfig\x00me@work.com\x00" +
+		"global\x00file:/home/me/.gitconfig\x00me@home.com\x00" +
+		"command\x00command line:\x00ci@example.com\x00"
+
+	expected := []ConfigOrigin{
+		{Scope: "global", Origin: "file:/home/me/.gws/work/gitconfig", Value: "me@work.com"},
+		{Scope: "global", Origin: "file:/home/me/.gitconfig", Value: "me@home.com"},
+		{Scope: "command", Origin: "command line:", Value: "ci@example.com"},
+	}
+
+	result := parseConfigOrigins(output)
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d origins, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], result[i])
+		}
+	}
+
+	if file := result[1].File(); file != "/home/me/.gitconfig" {
+		t.Errorf("expected %q, got %q", "/home/me/.gitconfig", file)
+	}
+}
+
+func TestParseSignedCommits(t *testing.T) {
+	output := "commit 4008071d\ntree 4b825dc6\nparent d2b7ba16\nauthor a <a@b.c> 1792205604 +0000\ncommitter a <a@b.c> 1792205604 +0000\n\n" +
+		"    gpgsig in a message is not a header\n\n" +
+		"commit d2b7ba16\ntree 4b825dc6\nauthor a <a@b.c> 1792205604 +0000\ncommitter a <a@b.c> 1792205604 +0000\n" +
+		"gpgsig -----BEGIN SSH SIGNATURE-----\n U1NIU0lH\n -----END SSH SIGNATURE-----\n\n    signed\n"
+
+	result := parseSignedCommits(output)
+	expected := map[string]bool{"4008071d": false, "d2b7ba16": true}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+	for sha, signed := range expected {
+		if result[sha] != signed {
+			t.Errorf("expected %s signed %v, got %v", sha, signed, result[sha])
+		}
+	}
+}
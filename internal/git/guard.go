@@ -0,0 +1,72 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// ChainedHooks lists the client-side hooks the global hooks directory forwards
+// to each repository's own .git/hooks, since core.hooksPath disables them
+var ChainedHooks = []string{
+	"applypatch-msg",
+	"pre-applypatch",
+	"post-applypatch",
+	"pre-commit",
+	"pre-merge-commit",
+	"prepare-commit-msg",
+	"commit-msg",
+	"post-commit",
+	"pre-rebase",
+	"post-checkout",
+	"post-merge",
+	"pre-push",
+	"post-rewrite",
+	"pre-auto-gc",
+}
+
+// InstallGlobalGuard writes the global guard hooks into hooksDir. The
+// pre-commit hook runs 'gitws hook run --global pre-commit' before chaining
+// to the repository's own hook; the rest only chain.
+func InstallGlobalGuard(hooksDir string) error {
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	for _, name := range ChainedHooks {
+		var script string
+		if name == "pre-commit" {
+			script = HookStub("Global pre-commit", "hook run --global pre-commit") + chainSnippet(name)
+		} else {
+			script = "#!/bin/sh\n# Git Workspace Guard - Global hook chain (managed by gitws)\n" + chainSnippet(name)
+		}
+
+		hookPath := filepath.Join(hooksDir, name)
+		write := func() error { return os.WriteFile(hookPath, []byte(script), 0755) }
+		if err := fsutil.RecordChange(write, hookPath); err != nil {
+			return fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// chainSnippet runs the repository's own hook, if any. --git-common-dir is
+// used because --git-path hooks would resolve back to core.hooksPath.
+func chainSnippet(name string) string {
+	return fmt.Sprintf(`
+REPO_HOOK="$(git rev-parse --git-common-dir)/hooks/%s"
+if [ -x "$REPO_HOOK" ]; then
+    exec "$REPO_HOOK" "$@"
+fi
+exit 0
+`, name)
+}
+
+// shellQuote single-quotes s for use as a literal shell pattern
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// hookMarker identifies hook scripts written by gitws
+const hookMarker = "# Git Workspace Guard"
+
+// ManagedHooks lists the repository hooks gitws installs
+var ManagedHooks = []string{"pre-commit", "commit-msg", "pre-push"}
+
+// HookState reports a hook in a repository: absent, written by gitws, or
+// someone else's
+type HookState struct {
+	Name    string
+	Present bool
+	Managed bool
+}
+
+// HooksDir returns the hooks directory of a repository. Worktrees share
+// the hooks of their main repository; a submodule's hooks live in the
+// superproject's .git/modules.
+func HooksDir(repoPath string) string {
+	if common, err := CommonDir(repoPath); err == nil {
+		return filepath.Join(common, "hooks")
+	}
+	return filepath.Join(repoPath, ".git", "hooks")
+}
+
+// ListHooks returns the state of each managed hook in a repository
+func ListHooks(repoPath string) []HookState {
+	var states []HookState
+	for _, name := range ManagedHooks {
+		state := HookState{Name: name}
+		if data, err := os.ReadFile(filepath.Join(HooksDir(repoPath), name)); err == nil {
+			state.Present = true
+			state.Managed = strings.Contains(string(data), hookMarker)
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// HasManagedHooks reports whether gitws hooks are installed in a repository
+func HasManagedHooks(repoPath string) bool {
+	for _, state := range ListHooks(repoPath) {
+		if state.Managed {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallHooks writes the gitws hook stubs into a repository. The guard
+// and policy logic lives in 'gitws hook run', so the stubs only change
+// when gitws itself moves. Hooks written by someone else are only
+// replaced with force.
+func InstallHooks(repoPath string, force bool) error {
+	hookDir := HooksDir(repoPath)
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	var foreign []string
+	for _, state := range ListHooks(repoPath) {
+		if state.Present && !state.Managed {
+			foreign = append(foreign, state.Name)
+		}
+	}
+	if len(foreign) > 0 && !force {
+		return fmt.Errorf("existing hooks not written by gitws: %s (use --force to replace them)", strings.Join(foreign, ", "))
+	}
+
+	for _, name := range ManagedHooks {
+		script := HookStub(name, "hook run "+name) + "exit 0\n"
+		path := filepath.Join(hookDir, name)
+		write := func() error { return os.WriteFile(path, []byte(script), 0755) }
+		if err := fsutil.RecordChange(write, path); err != nil {
+			return fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// HookStub returns the start of a hook script that runs 'gitws <args>' with
+// the hook's arguments and standard input, exiting if it fails. gitws is
+// looked up on PATH first, then at the path of the binary that installed
+// the hook. When it cannot be found the hook warns and lets git continue.
+func HookStub(name, args string) string {
+	installed, err := os.Executable()
+	if err != nil {
+		installed = "gitws"
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+%s - %s hook (managed by gitws)
+
+GITWS=$(command -v gitws 2>/dev/null || echo %s)
+if [ -x "$GITWS" ]; then
+    "$GITWS" %s "$@" || exit $?
+else
+    echo "⚠️  gitws not found, skipping the workspace guard" >&2
+fi
+`, hookMarker, name, shellQuote(installed), args)
+}
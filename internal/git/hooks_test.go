@@ -0,0 +1,41 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// shellCheck fails the test when sh cannot parse the script at path
+func shellCheck(t *testing.T, path string) {
+	t.Helper()
+	if output, err := exec.Command("sh", "-n", path).CombinedOutput(); err != nil {
+		t.Errorf("%s is not valid sh: %v\n%s", filepath.Base(path), err, output)
+	}
+}
+
+func TestHookScriptsParse(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallHooks(repo, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range ManagedHooks {
+		shellCheck(t, filepath.Join(HooksDir(repo), name))
+	}
+
+	global := t.TempDir()
+	if err := InstallGlobalGuard(global); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range ChainedHooks {
+		shellCheck(t, filepath.Join(global, name))
+	}
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BinaryEnv names the environment variable that overrides the git binary
+const BinaryEnv = "GWS_GIT"
+
+// MinVersion is the oldest git gitws supports; includeIf arrived in 2.13
+var MinVersion = Version{Major: 2, Minor: 13}
+
+var (
+	binaryOverride string
+
+	locateOnce sync.Once
+	located    struct {
+		path    string
+		output  string // "git --version" output
+		version Version
+		err     error
+	}
+)
+
+// SetBinary sets the git binary to use, e.g. from config.yaml. GWS_GIT
+// still takes precedence. It has no effect once git has been located.
+func SetBinary(path string) {
+	binaryOverride = path
+}
+
+// Locate finds the git binary and checks its version. The result is
+// cached for the life of the process.
+func Locate() (path string, version Version, err error) {
+	locateOnce.Do(func() {
+		name := "git"
+		switch {
+		case os.Getenv(BinaryEnv) != "":
+			name = os.Getenv(BinaryEnv)
+		case binaryOverride != "":
+			name = binaryOverride
+		}
+
+		path, err := exec.LookPath(name)
+		if err != nil {
+			located.err = fmt.Errorf("git not found: %w", err)
+			return
+		}
+		located.path = path
+
+		output, err := exec.Command(path, "--version").Output()
+		if err != nil {
+			located.err = fmt.Errorf("failed to run %s --version: %w", path, err)
+			return
+		}
+		located.output = strings.TrimSpace(string(output))
+		located.version, located.err = verifyVersion(located.output)
+		if located.err != nil {
+			located.err = fmt.Errorf("%s: %w", path, located.err)
+		}
+	})
+
+	return located.path, located.version, located.err
+}
+
+// verifyVersion parses "git --version" output and rejects versions older
+// than MinVersion
+func verifyVersion(output string) (Version, error) {
+	version, err := ParseVersion(output)
+	if err != nil {
+		return Version{}, err
+	}
+	if !version.AtLeast(MinVersion) {
+		return version, fmt.Errorf("git %s is too old, gitws needs %s or newer (set %s or git_path in config.yaml to use another git)", version, MinVersion, BinaryEnv)
+	}
+	return version, nil
+}
+
+// command builds a git command using the located binary. When git could
+// not be located the plain name is used, so the failure surfaces from Run.
+func command(args ...string) *exec.Cmd {
+	return commandContext(context.Background(), args...)
+}
+
+// cancelGrace is how long git has to clean up after being interrupted,
+// e.g. to remove a half-cloned directory, before it is killed
+const cancelGrace = 5 * time.Second
+
+// commandContext is command for operations that can be cancelled, such as
+// network transfers. When ctx is done git is interrupted, as by Ctrl-C.
+func commandContext(ctx context.Context, args ...string) *exec.Cmd {
+	path, _, _ := Locate()
+	if path == "" {
+		path = "git"
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Cancel = func() error {
+		// Windows cannot deliver an interrupt
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = cancelGrace
+	return cmd
+}
@@ -0,0 +1,501 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Backends for read-only operations. exec runs git; native reads
+// repositories with go-git, so status, doctor and audit work in
+// containers without git. Writes always run git.
+//
+// go-git opens repositories, reads remotes and refs and walks history,
+// and its config reader parses config files. It does not follow include
+// and includeIf, which decide a repository's identity, so nativeconfig.go
+// evaluates those the way git does. Anything else the native backend
+// cannot answer as git would, such as GIT_DIR, extensions or a revision
+// syntax it does not know, returns errUnsupported and git runs instead.
+const (
+	BackendAuto   = "auto" // native only when git cannot be located
+	BackendExec   = "exec"
+	BackendNative = "native"
+)
+
+var backend = BackendAuto
+
+// errUnsupported is returned by the native backend for repositories and
+// configurations it cannot read faithfully; git is run instead
+var errUnsupported = errors.New("not supported by the native backend")
+
+// errNotSet is the native backend's error for a config key with no value
+var errNotSet = errors.New("not set")
+
+// SetBackend selects how read-only operations are done
+func SetBackend(name string) {
+	backend = name
+}
+
+// IsValidBackend reports whether name is a backend SetBackend accepts
+func IsValidBackend(name string) bool {
+	return name == BackendAuto || name == BackendExec || name == BackendNative
+}
+
+// useNative reports whether read-only operations read files directly
+func useNative() bool {
+	switch backend {
+	case BackendNative:
+		return true
+	case BackendExec:
+		return false
+	}
+	_, _, err := Locate()
+	return err != nil
+}
+
+// readNative runs read, the native version of an operation, when the
+// native backend is in use. ok is false when git must run instead: the
+// exec backend is selected, or read met something it does not support.
+func readNative[T any](read func() (T, error)) (result T, ok bool, err error) {
+	if !useNative() {
+		return result, false, nil
+	}
+	result, err = read()
+	if errors.Is(err, errUnsupported) {
+		return result, false, nil
+	}
+	return result, true, err
+}
+
+// nativeRepo is a repository opened with go-git
+type nativeRepo struct {
+	repo      *gogit.Repository
+	workTree  string // "" for a bare repository
+	gitDir    string
+	commonDir string // shared by worktrees; gitDir when there are none
+}
+
+// discover opens the repository containing path, the way git finds it
+func discover(path string) (*nativeRepo, error) {
+	if os.Getenv("GIT_DIR") != "" || os.Getenv("GIT_WORK_TREE") != "" {
+		return nil, errUnsupported
+	}
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir(dir) {
+		return nil, fmt.Errorf("%s is not a directory", path)
+	}
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return nil, ErrNotARepo
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errUnsupported)
+	}
+
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errUnsupported
+	}
+	r := &nativeRepo{repo: repo, gitDir: storage.Filesystem().Root()}
+	if wt, err := repo.Worktree(); err == nil {
+		r.workTree = wt.Filesystem.Root()
+	}
+	// go-git finds the working tree from inside its .git directory, where
+	// git sees none
+	if rel, err := filepath.Rel(r.gitDir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, errUnsupported
+	}
+
+	r.commonDir = r.gitDir
+	if data, err := os.ReadFile(filepath.Join(r.gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(r.gitDir, common)
+		}
+		r.commonDir = filepath.Clean(common)
+	}
+
+	// Repositories whose layout go-git does not know
+	local, err := readConfigFile(r.localConfig(), "local", &configContext{}, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"core.worktree", "extensions.worktreeconfig", "extensions.refstorage", "extensions.objectformat"} {
+		if _, err := lastValue(local, key); err == nil {
+			return nil, fmt.Errorf("%s: %w", key, errUnsupported)
+		}
+	}
+	return r, nil
+}
+
+// localConfig returns the path of the repository's config file
+func (r *nativeRepo) localConfig() string {
+	return filepath.Join(r.commonDir, "config")
+}
+
+// headBranch returns the branch HEAD of gitDir points at, or "" when it
+// is detached
+func headBranch(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// configFile is a config file git reads and the scope it belongs to
+type configFile struct {
+	path  string
+	scope string
+}
+
+// configFiles returns the files git reads in repo (nil outside one), in
+// order, the last value read winning
+func configFiles(repo *nativeRepo) []configFile {
+	var files []configFile
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		files = append(files, configFile{systemConfigPath(), "system"})
+	}
+	for _, path := range globalConfigPaths() {
+		files = append(files, configFile{path, "global"})
+	}
+	if repo != nil {
+		files = append(files, configFile{repo.localConfig(), "local"})
+	}
+	return files
+}
+
+// systemConfigPath returns the machine-wide config file
+func systemConfigPath() string {
+	if path := os.Getenv("GIT_CONFIG_SYSTEM"); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramFiles"), "Git", "etc", "gitconfig")
+	}
+	return "/etc/gitconfig"
+}
+
+// globalConfigPaths returns the files git reads for --global, in order
+func globalConfigPaths() []string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return []string{path}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	xdg := filepath.Join(home, ".config", "git", "config")
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		xdg = filepath.Join(dir, "git", "config")
+	}
+	return []string{xdg, filepath.Join(home, ".gitconfig")}
+}
+
+// effectiveConfig returns every config value that applies in repo (nil
+// outside a repository) with includes followed, in the order git reads
+// them
+func effectiveConfig(repo *nativeRepo) ([]configValue, error) {
+	if os.Getenv("GIT_CONFIG_PARAMETERS") != "" || os.Getenv("GIT_CONFIG_COUNT") != "" {
+		return nil, errUnsupported
+	}
+	ctx := &configContext{firstPass: true}
+	if repo != nil {
+		ctx.gitDir = repo.gitDir
+		ctx.branch = headBranch(repo.gitDir)
+	}
+	read := func() ([]configValue, error) {
+		var values []configValue
+		for _, f := range configFiles(repo) {
+			v, err := readConfigFile(f.path, f.scope, ctx, true, 0)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v...)
+		}
+		return values, nil
+	}
+
+	values, err := read()
+	if err != nil {
+		return nil, err
+	}
+	// hasconfig:remote.*.url: includes depend on the remote URLs of the
+	// other files, so a second pass reads them knowing the URLs
+	var hasconfig bool
+	for _, v := range values {
+		if strings.HasPrefix(v.key, "includeif.hasconfig:") {
+			hasconfig = true
+		}
+		if strings.HasPrefix(v.key, "remote.") && strings.HasSuffix(v.key, ".url") {
+			ctx.remoteURLs = append(ctx.remoteURLs, v.value)
+		}
+	}
+	if !hasconfig {
+		return values, nil
+	}
+	ctx.firstPass = false
+	return read()
+}
+
+// scopeConfig returns the values of files without following includes, as
+// 'git config --local' and '--global' read them
+func scopeConfig(paths []string, scope string) ([]configValue, error) {
+	var values []configValue
+	for _, path := range paths {
+		v, err := readConfigFile(path, scope, &configContext{}, false, 0)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	return values, nil
+}
+
+// lastValue returns the value of key that git uses, the last one read
+func lastValue(values []configValue, key string) (string, error) {
+	key = canonicalKey(key)
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i].key == key {
+			return values[i].value, nil
+		}
+	}
+	return "", errNotSet
+}
+
+// repoOrNil discovers the repository at path, nil when there is none, as
+// git config reads only system and global files outside a repository
+func repoOrNil(path string) (*nativeRepo, error) {
+	repo, err := discover(path)
+	if errors.Is(err, ErrNotARepo) {
+		return nil, nil
+	}
+	return repo, err
+}
+
+// nativeShowCdup is showCdup for the native backend
+func nativeShowCdup(dir string) (string, error) {
+	repo, err := discover(dir)
+	if err != nil {
+		return "", err
+	}
+	if repo.workTree == "" {
+		return "", fmt.Errorf("%s is not in a working tree", dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(abs, repo.workTree)
+	if err != nil || rel == "." {
+		return "", err
+	}
+	return rel, nil
+}
+
+// nativeRevParsePath is revParsePath for --git-dir and --git-common-dir
+func nativeRevParsePath(repoPath, flag string) (string, error) {
+	repo, err := discover(repoPath)
+	if err != nil {
+		return "", err
+	}
+	switch flag {
+	case "--git-dir":
+		return repo.gitDir, nil
+	case "--git-common-dir":
+		return repo.commonDir, nil
+	}
+	return "", errUnsupported
+}
+
+// nativeLocalConfig reads key from the repository's own config file
+func nativeLocalConfig(repoPath, key string) (string, error) {
+	repo, err := discover(repoPath)
+	if err != nil {
+		return "", err
+	}
+	values, err := scopeConfig([]string{repo.localConfig()}, "local")
+	if err != nil {
+		return "", err
+	}
+	return lastValue(values, key)
+}
+
+// nativeConfig reads the value of key git uses in repoPath
+func nativeConfig(repoPath, key string) (string, error) {
+	repo, err := repoOrNil(repoPath)
+	if err != nil {
+		return "", err
+	}
+	values, err := effectiveConfig(repo)
+	if err != nil {
+		return "", err
+	}
+	return lastValue(values, key)
+}
+
+// nativeGlobalConfig reads key from the global config files
+func nativeGlobalConfig(key string) (string, error) {
+	values, err := scopeConfig(globalConfigPaths(), "global")
+	if err != nil {
+		return "", err
+	}
+	return lastValue(values, key)
+}
+
+// nativeGlobalConfigRegexp is GetGlobalConfigRegexp for the native backend
+func nativeGlobalConfigRegexp(pattern string) ([]ConfigEntry, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values, err := scopeConfig(globalConfigPaths(), "global")
+	if err != nil {
+		return nil, err
+	}
+	var entries []ConfigEntry
+	for _, v := range values {
+		if re.MatchString(v.key) {
+			entries = append(entries, ConfigEntry{Key: v.key, Value: v.value})
+		}
+	}
+	return entries, nil
+}
+
+// nativeConfigOrigins is ConfigOrigins for the native backend
+func nativeConfigOrigins(repoPath, key string) ([]ConfigOrigin, error) {
+	repo, err := repoOrNil(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	values, err := effectiveConfig(repo)
+	if err != nil {
+		return nil, err
+	}
+	key = canonicalKey(key)
+	var origins []ConfigOrigin
+	for _, v := range values {
+		if v.key == key {
+			origins = append(origins, ConfigOrigin{Scope: v.scope, Origin: "file:" + v.file, Value: v.value})
+		}
+	}
+	return origins, nil
+}
+
+// nativeRemoteURLs is RemoteURLs for the native backend
+func nativeRemoteURLs(repoPath string) (map[string]string, error) {
+	r, err := discover(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errUnsupported)
+	}
+	remotes := make(map[string]string)
+	for name, remote := range cfg.Remotes {
+		// As with the url keys git lists, the last one wins
+		if len(remote.URLs) > 0 {
+			remotes[name] = remote.URLs[len(remote.URLs)-1]
+		}
+	}
+	return remotes, nil
+}
+
+// nativeRemoteURL is GetRemoteURL for the native backend, applying
+// url.<base>.insteadOf as 'git remote get-url' does
+func nativeRemoteURL(repoPath string) (string, error) {
+	repo, err := discover(repoPath)
+	if err != nil {
+		return "", err
+	}
+	values, err := effectiveConfig(repo)
+	if err != nil {
+		return "", err
+	}
+	url, err := lastValue(values, "remote.origin.url")
+	if err != nil {
+		return "", fmt.Errorf("no such remote 'origin'")
+	}
+	return insteadOf(values, url), nil
+}
+
+// insteadOf rewrites url by the longest matching url.<base>.insteadOf
+func insteadOf(values []configValue, url string) string {
+	base, longest := "", ""
+	for _, v := range values {
+		if !strings.HasPrefix(v.key, "url.") || !strings.HasSuffix(v.key, ".insteadof") {
+			continue
+		}
+		if strings.HasPrefix(url, v.value) && len(v.value) > len(longest) {
+			base = strings.TrimSuffix(strings.TrimPrefix(v.key, "url."), ".insteadof")
+			longest = v.value
+		}
+	}
+	if longest == "" {
+		return url
+	}
+	return base + strings.TrimPrefix(url, longest)
+}
+
+// nativeRemoteHead is GetRemoteHead for the native backend
+func nativeRemoteHead(repoPath string) (string, error) {
+	r, err := discover(repoPath)
+	if err != nil {
+		return "", err
+	}
+	ref, err := r.repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return "", nil
+	}
+	return strings.TrimPrefix(ref.Target().String(), "refs/remotes/origin/"), nil
+}
+
+// nativeWorktrees is ListWorktrees for the native backend
+func nativeWorktrees(repoPath string) ([]Worktree, error) {
+	repo, err := discover(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	main := repo.commonDir
+	if filepath.Base(main) == ".git" {
+		main = filepath.Dir(main)
+	}
+	worktrees := []Worktree{{Path: main, Branch: headBranch(repo.commonDir), Main: true}}
+
+	entries, err := os.ReadDir(filepath.Join(repo.commonDir, "worktrees"))
+	if err != nil {
+		return worktrees, nil
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(repo.commonDir, "worktrees", entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "gitdir"))
+		if err != nil {
+			continue
+		}
+		// gitdir holds the path of the worktree's .git file
+		path := filepath.Dir(filepath.Clean(strings.TrimSpace(string(data))))
+		worktrees = append(worktrees, Worktree{
+			Path:     path,
+			Branch:   headBranch(dir),
+			Prunable: !isDir(path),
+		})
+	}
+	return worktrees, nil
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"simple", "[user]\n\temail = a@example.com\n", []string{"user.email=a@example.com"}},
+		{"case", "[User]\n\tEMail = a\n", []string{"user.email=a"}},
+		{"subsection", "[remote \"Origin\"]\n\turl = git@github.com:a/b.git\n", []string{"remote.Origin.url=git@github.com:a/b.git"}},
+		{"escaped subsection", "[includeIf \"gitdir:~/a \\\"b\\\"/\"]\n\tpath = x\n", []string{`includeif.gitdir:~/a "b"/.path=x`}},
+		{"quotes and comments", "[a]\n\tb = \"x ; y\" # comment\n\tc = one  two\t ; trailing\n", []string{"a.b=x ; y", "a.c=one  two"}},
+		{"escapes", "[a]\n\tb = tab\\there\\\\\n", []string{"a.b=tab\there\\"}},
+		{"no value", "[core]\n\tbare\n", []string{"core.bare="}},
+		{"crlf", "[a]\r\n\tb = c\r\n", []string{"a.b=c"}},
+		{"no newline at end", "[a]\n\tb = c", []string{"a.b=c"}},
+		{"byte order mark", "\ufeff[a]\n\tb = c\n", []string{"a.b=c"}},
+		{"escaped backslash at end of line", "[a]\n\tb = c\\\\\n", []string{"a.b=c\\"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := parseConfig(tt.input, "config", "local")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var result []string
+			for _, v := range values {
+				result = append(result, v.key+"="+v.value)
+			}
+			if strings.Join(result, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseConfigUnsupported(t *testing.T) {
+	// git reads these; go-git's reader does not, so git is left to
+	for _, input := range []string{
+		"[Remote.Origin]\nurl = x\n",
+		"[core] bare = true\n",
+		"[a]\n\tb = one \\\ntwo\n",
+		"[a]\n\tb = one \\\r\ntwo\r\n",
+	} {
+		if _, err := parseConfig(input, "config", "local"); !errors.Is(err, errUnsupported) {
+			t.Errorf("expected %q to be unsupported, got %v", input, err)
+		}
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, input := range []string{
+		"key = outside\n",
+		"[a\n",
+		"[a]\n\tb = \"unterminated\n",
+		"[a]\n\tb = bad \\q escape\n",
+	} {
+		if _, err := parseConfig(input, "config", "local"); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"/home/me/code/work/**", "/home/me/code/work/acme/app/.git", true},
+		{"/home/me/code/work/**", "/home/me/code/personal/app/.git", false},
+		{"**/work/**", "/srv/work/app/.git", true},
+		{"/home/*/code/**", "/home/me/code/app/.git", true},
+		{"/home/*/code/**", "/home/me/extra/code/app/.git", false},
+		{"https://github.com/acme/**", "https://github.com/acme/app.git", true},
+		{"git@github.com:acme/*", "git@github.com:acme/app.git", true},
+		{"git@github.com:acme/*", "git@github.com:other/app.git", false},
+		{"release/**", "release/1.0", true},
+		{"feature-?", "feature-a", true},
+		{"[ab]x", "bx", true},
+		{"[!ab]x", "bx", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if result := globMatch(tt.pattern, tt.name, false); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestNativeMatchesGit checks the native backend against git itself on a
+// repository with conditional includes, a worktree and URL rewriting
+func TestNativeMatchesGit(t *testing.T) {
+	if _, _, err := Locate(); err != nil {
+		t.Skip("git not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, name := range []string{"GIT_CONFIG_GLOBAL", "GIT_CONFIG_COUNT", "GIT_CONFIG_PARAMETERS", "GIT_DIR", "GIT_WORK_TREE"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	work := filepath.Join(home, "code", "work")
+	repo := filepath.Join(work, "app")
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[user]\n\temail = personal@example.com\n"+
+		"[includeIf \"gitdir:~/code/work/\"]\n\tpath = ~/.gws/work.gitconfig\n"+
+		"[url \"git@github.com-work:\"]\n\tinsteadOf = https://github.com/\n"+
+		"[commit]\n\tgpgsign = true\n")
+	writeFile(t, filepath.Join(home, ".gws", "work.gitconfig"), "[user]\n\temail = me@work.com\n\tsigningkey = ~/.ssh/work\n")
+
+	gitIn(t, home, "init", "-q", "-b", "main", repo)
+	gitIn(t, repo, "remote", "add", "origin", "https://github.com/acme/app.git")
+	// Commit dates decide the order of git log, and so of the native walk
+	commit := func(dir, email, date string) {
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		gitIn(t, dir, "-c", "user.name=t", "-c", "user.email="+email, "-c", "commit.gpgsign=false",
+			"commit", "-q", "--allow-empty", "--date", date, "-m", date)
+	}
+	commit(repo, "a@example.com", "2024-01-01T00:00:00Z")
+	gitIn(t, repo, "update-ref", "refs/remotes/origin/main", "HEAD")
+	gitIn(t, repo, "worktree", "add", "-q", "-b", "topic", filepath.Join(work, "app-topic"))
+	commit(filepath.Join(work, "app-topic"), "b@example.com", "2024-01-03T00:00:00Z")
+	commit(repo, "c@example.com", "2024-01-02T00:00:00Z")
+	gitIn(t, repo, "tag", "-a", "-m", "v1", "v1", "HEAD~1")
+	gitIn(t, repo, "checkout", "-q", "--detach", "HEAD")
+	commit(repo, "d@example.com", "2024-01-04T00:00:00Z")
+	if err := os.MkdirAll(filepath.Join(repo, "sub", "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]func() (string, error){
+		"config":         func() (string, error) { return GetConfig(repo, "user.email") },
+		"config outside": func() (string, error) { return GetConfig(home, "user.email") },
+		"local":          func() (string, error) { return GetLocalConfig(repo, "remote.origin.url") },
+		"global":         func() (string, error) { return GetGlobalConfig("user.email") },
+		"remote url":     func() (string, error) { return GetRemoteURL(repo) },
+		"root":           func() (string, error) { return FindGitRoot(filepath.Join(repo, "sub", "dir")) },
+		"common dir":     func() (string, error) { return CommonDir(filepath.Join(work, "app-topic")) },
+		"is worktree": func() (string, error) {
+			return fmt.Sprint(IsWorktree(filepath.Join(work, "app-topic")), IsWorktree(repo)), nil
+		},
+		"worktrees": func() (string, error) {
+			worktrees, err := ListWorktrees(repo)
+			return fmt.Sprint(worktrees), err
+		},
+		"signing": func() (string, error) {
+			enabled, method, key, err := GetSigningStatus(repo)
+			return fmt.Sprint(enabled, method, key), err
+		},
+		"log all": func() (string, error) {
+			commits, err := CommitIdentities(repo, "--all")
+			return fmt.Sprint(commits), err
+		},
+		"log not remotes": func() (string, error) {
+			commits, err := CommitIdentities(repo, "HEAD", "--not", "--remotes")
+			return fmt.Sprint(commits), err
+		},
+		"log range": func() (string, error) {
+			commits, err := CommitIdentities(repo, "v1..topic", "--max-count=1")
+			return fmt.Sprint(commits), err
+		},
+		"log unknown revision": func() (string, error) {
+			commits, err := CommitIdentities(repo, "missing")
+			return fmt.Sprint(commits), err
+		},
+		"signed": func() (string, error) {
+			signed, err := SignedCommits(repo, "--all")
+			return fmt.Sprint(signed), err
+		},
+		"origins": func() (string, error) {
+			origins, err := ConfigOrigins(repo, "user.email")
+			var values []string
+			for _, o := range origins {
+				values = append(values, o.Scope+" "+o.Value)
+			}
+			return strings.Join(values, ","), err
+		},
+	}
+
+	defer SetBackend(BackendAuto)
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			SetBackend(BackendExec)
+			expected, expectedErr := query()
+			SetBackend(BackendNative)
+			result, err := query()
+
+			if (err != nil) != (expectedErr != nil) {
+				t.Fatalf("expected error %v, got %v", expectedErr, err)
+			}
+			if result != expected {
+				t.Errorf("expected %q, got %q", expected, result)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/go-git/gcfg"
+)
+
+// maxIncludeDepth is how deeply config files may include each other, as
+// in git
+const maxIncludeDepth = 10
+
+// configValue is one value read from a config file
+type configValue struct {
+	key   string // section and name lowercased, subsection as written
+	value string
+	scope string // "system", "global" or "local"
+	file  string
+}
+
+// configContext is what includeIf conditions are evaluated against
+type configContext struct {
+	gitDir     string   // "" outside a repository
+	branch     string   // "" when HEAD is detached or unknown
+	remoteURLs []string // for hasconfig:remote.*.url
+	firstPass  bool     // remote URLs are being collected; skip hasconfig
+}
+
+// readConfigFile reads a config file, following include and includeIf
+// when includes is set. A missing file has no values.
+func readConfigFile(path, scope string, ctx *configContext, includes bool, depth int) ([]configValue, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("config includes nested more than %d deep at %s", maxIncludeDepth, path)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseConfig(string(data), path, scope)
+	if err != nil || !includes {
+		return values, err
+	}
+
+	var result []configValue
+	for _, v := range values {
+		result = append(result, v)
+
+		var included string
+		switch {
+		case v.key == "include.path":
+			included = v.value
+		case strings.HasPrefix(v.key, "includeif.") && strings.HasSuffix(v.key, ".path"):
+			condition := strings.TrimSuffix(strings.TrimPrefix(v.key, "includeif."), ".path")
+			ok, err := includeCondition(condition, path, ctx)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				included = v.value
+			}
+		}
+		if included == "" {
+			continue
+		}
+		values, err := readConfigFile(includePath(included, path), scope, ctx, true, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, values...)
+	}
+	return result, nil
+}
+
+// includePath resolves the path of an include: ~/ is the home directory
+// and relative paths are relative to the including file
+func includePath(path, from string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(filepath.Dir(from), path)
+	}
+	return path
+}
+
+// includeCondition evaluates the condition of an includeIf section
+func includeCondition(condition, from string, ctx *configContext) (bool, error) {
+	kind, pattern, _ := strings.Cut(condition, ":")
+	switch kind {
+	case "gitdir", "gitdir/i":
+		if ctx.gitDir == "" {
+			return false, nil
+		}
+		fold := kind == "gitdir/i"
+		pattern = gitdirPattern(pattern, from)
+		if globMatch(pattern, filepath.ToSlash(ctx.gitDir), fold) {
+			return true, nil
+		}
+		// git also tries the path with symlinks resolved
+		if real, err := filepath.EvalSymlinks(ctx.gitDir); err == nil {
+			return globMatch(pattern, filepath.ToSlash(real), fold), nil
+		}
+		return false, nil
+	case "onbranch":
+		if ctx.branch == "" {
+			return false, nil
+		}
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return globMatch(pattern, ctx.branch, false), nil
+	case "hasconfig":
+		urlPattern, ok := strings.CutPrefix(pattern, "remote.*.url:")
+		if !ok {
+			return false, fmt.Errorf("includeIf %q: %w", condition, errUnsupported)
+		}
+		if ctx.firstPass {
+			return false, nil
+		}
+		for _, url := range ctx.remoteURLs {
+			if globMatch(urlPattern, url, false) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("includeIf %q: %w", condition, errUnsupported)
+}
+
+// gitdirPattern expands a gitdir: pattern the way git does: ~/ and ./
+// are expanded, a relative pattern matches at any depth, and a trailing
+// slash matches everything below
+func gitdirPattern(pattern, from string) string {
+	switch {
+	case strings.HasPrefix(pattern, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.ToSlash(home) + pattern[1:]
+		}
+	case strings.HasPrefix(pattern, "./"):
+		pattern = filepath.ToSlash(filepath.Dir(from)) + pattern[1:]
+	}
+	if !strings.HasPrefix(pattern, "/") && !isDrivePath(pattern) {
+		pattern = "**/" + pattern
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return pattern
+}
+
+// isDrivePath reports whether path starts with a Windows drive letter
+func isDrivePath(path string) bool {
+	return runtime.GOOS == "windows" && len(path) >= 2 && path[1] == ':'
+}
+
+// globMatch matches name against a wildmatch pattern in which * and ?
+// stay within a path segment and ** crosses segments
+func globMatch(pattern, name string, fold bool) bool {
+	expr := globRegexp(pattern)
+	if fold {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(name)
+}
+
+// globRegexp translates a wildmatch pattern into an anchored regexp
+func globRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern) && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// continuationLine matches a line ending in an unescaped backslash
+var continuationLine = regexp.MustCompile(`(?m)(?:^|[^\\])(?:\\\\)*\\\r?$`)
+
+// parseConfig parses the contents of a git config file with go-git's
+// config reader. It keeps the order values are read in, which decides
+// what git uses. Syntax the reader handles differently from git, i.e.
+// line continuations, [section.subsection] headers and keys on the
+// header line, is errUnsupported so git reads the file instead.
+func parseConfig(data, file, scope string) ([]configValue, error) {
+	data = strings.TrimPrefix(data, "\ufeff")
+	if continuationLine.MatchString(data) {
+		return nil, fmt.Errorf("%s: continuation line: %w", file, errUnsupported)
+	}
+	var values []configValue
+	err := gcfg.ReadWithCallback(strings.NewReader(data), func(section, subsection, key, value string, blank bool) error {
+		if key == "" {
+			return nil // a section header
+		}
+		name := strings.ToLower(section)
+		if subsection != "" {
+			name += "." + subsection
+		}
+		values = append(values, configValue{key: name + "." + strings.ToLower(key), value: value, scope: scope, file: file})
+		return nil
+	})
+	if err := gcfg.FatalOnly(err); err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", file, err, errUnsupported)
+	}
+	return values, nil
+}
+
+// canonicalKey normalizes a key as given on the command line: section and
+// name lowercased, the subsection kept as written
+func canonicalKey(key string) string {
+	first := strings.IndexByte(key, '.')
+	last := strings.LastIndexByte(key, '.')
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
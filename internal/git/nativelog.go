@@ -0,0 +1,251 @@
+package git
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// logQuery is what a git log argument list selects: commits reachable
+// from include but not from exclude, at most limit of them (0 is all)
+type logQuery struct {
+	include []plumbing.Hash
+	exclude []plumbing.Hash
+	limit   int
+}
+
+// parseLogArgs resolves the revisions CommitIdentities and SignedCommits
+// pass to git log. Only the forms gitws uses are known: revisions,
+// A..B ranges, --all, --remotes, --not and --max-count.
+func parseLogArgs(repo *gogit.Repository, revs []string) (*logQuery, error) {
+	q := &logQuery{}
+	negate := false
+	add := func(hashes []plumbing.Hash, exclude bool) {
+		if exclude != negate {
+			q.exclude = append(q.exclude, hashes...)
+		} else {
+			q.include = append(q.include, hashes...)
+		}
+	}
+
+	for _, rev := range revs {
+		switch {
+		case rev == "--not":
+			negate = !negate
+		case rev == "--all":
+			hashes, err := refCommits(repo, func(name plumbing.ReferenceName) bool { return true })
+			if err != nil {
+				return nil, err
+			}
+			if head, err := repo.Head(); err == nil {
+				hashes = append(hashes, head.Hash())
+			}
+			add(hashes, false)
+		case rev == "--remotes":
+			hashes, err := refCommits(repo, plumbing.ReferenceName.IsRemote)
+			if err != nil {
+				return nil, err
+			}
+			add(hashes, false)
+		case strings.HasPrefix(rev, "--max-count="):
+			n, err := strconv.Atoi(strings.TrimPrefix(rev, "--max-count="))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rev, errUnsupported)
+			}
+			q.limit = n
+		case strings.HasPrefix(rev, "-") || strings.Contains(rev, "..."):
+			return nil, fmt.Errorf("%s: %w", rev, errUnsupported)
+		case strings.Contains(rev, ".."):
+			from, to, _ := strings.Cut(rev, "..")
+			for i, r := range []string{from, to} {
+				if r == "" {
+					r = "HEAD"
+				}
+				hash, err := resolveCommit(repo, r)
+				if err != nil {
+					return nil, err
+				}
+				add([]plumbing.Hash{hash}, i == 0)
+			}
+		default:
+			hash, err := resolveCommit(repo, rev)
+			if err != nil {
+				return nil, err
+			}
+			add([]plumbing.Hash{hash}, false)
+		}
+	}
+	return q, nil
+}
+
+// resolveCommit resolves a revision to the commit it names
+func resolveCommit(repo *gogit.Repository, rev string) (plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision %s", rev)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%s: %v: %w", rev, err, errUnsupported)
+	}
+	return *hash, nil
+}
+
+// refCommits returns the commits the refs selected by match point at,
+// annotated tags peeled and refs to other objects skipped
+func refCommits(repo *gogit.Repository, match func(plumbing.ReferenceName) bool) ([]plumbing.Hash, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var hashes []plumbing.Hash
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !match(ref.Name()) {
+			return nil
+		}
+		hash := ref.Hash()
+		if tag, err := repo.TagObject(hash); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return nil
+			}
+			hash = commit.Hash
+		}
+		if _, err := repo.CommitObject(hash); err == nil {
+			hashes = append(hashes, hash)
+		}
+		return nil
+	})
+	return hashes, err
+}
+
+// nativeLog returns the commits revs selects in git log's default order,
+// newest commit date first
+func nativeLog(repoPath string, revs []string) ([]*object.Commit, error) {
+	r, err := discover(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	q, err := parseLogArgs(r.repo, revs)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[plumbing.Hash]bool)
+	for _, hash := range q.exclude {
+		commit, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(commit, excluded, nil).ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	queue := &commitQueue{}
+	seen := make(map[plumbing.Hash]bool)
+	push := func(hash plumbing.Hash) error {
+		if seen[hash] || excluded[hash] {
+			return nil
+		}
+		seen[hash] = true
+		commit, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		queue.push(commit)
+		return nil
+	}
+	for _, hash := range q.include {
+		if err := push(hash); err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []*object.Commit
+	for queue.Len() > 0 && (q.limit == 0 || len(commits) < q.limit) {
+		commit := heap.Pop(queue).(*object.Commit)
+		commits = append(commits, commit)
+		for _, parent := range commit.ParentHashes {
+			if err := push(parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return commits, nil
+}
+
+// nativeCommitIdentities is CommitIdentities for the native backend
+func nativeCommitIdentities(repoPath string, revs []string) ([]CommitIdentity, error) {
+	log, err := nativeLog(repoPath, revs)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]CommitIdentity, 0, len(log))
+	for _, c := range log {
+		commits = append(commits, CommitIdentity{SHA: c.Hash.String(), AuthorEmail: c.Author.Email, CommitterEmail: c.Committer.Email})
+	}
+	return commits, nil
+}
+
+// nativeSignedCommits is SignedCommits for the native backend
+func nativeSignedCommits(repoPath string, revs []string) (map[string]bool, error) {
+	log, err := nativeLog(repoPath, revs)
+	if err != nil {
+		return nil, err
+	}
+	signed := make(map[string]bool, len(log))
+	for _, c := range log {
+		signed[c.Hash.String()] = c.PGPSignature != ""
+	}
+	return signed, nil
+}
+
+// commitQueue orders commits newest commit date first, like git log's
+// walk; commits with the same date keep the order they were queued in
+type commitQueue struct {
+	commits []*object.Commit
+	order   []int
+	next    int
+}
+
+func (q *commitQueue) push(c *object.Commit) {
+	heap.Push(q, c)
+}
+
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	ti, tj := q.commits[i].Committer.When, q.commits[j].Committer.When
+	if ti.Equal(tj) {
+		return q.order[i] < q.order[j]
+	}
+	return ti.After(tj)
+}
+
+func (q *commitQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+}
+
+func (q *commitQueue) Push(x any) {
+	q.commits = append(q.commits, x.(*object.Commit))
+	q.order = append(q.order, q.next)
+	q.next++
+}
+
+func (q *commitQueue) Pop() any {
+	n := len(q.commits) - 1
+	c := q.commits[n]
+	q.commits, q.order = q.commits[:n], q.order[:n]
+	return c
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RepoState is the work in a repository that may not be on its remote:
+// uncommitted changes, commits ahead of the upstream and stashes
+type RepoState struct {
+	Branch    string // short branch name; empty when detached
+	Detached  bool
+	Upstream  string // e.g. origin/main; empty when the branch tracks none
+	Ahead     int
+	Behind    int
+	Changed   int // tracked files with staged, unstaged or conflicting changes
+	Untracked int
+	Stashes   int
+	NoCommits bool // the branch is unborn, as in a fresh repository
+}
+
+// Clean reports whether the working tree has no changes or untracked files
+func (s RepoState) Clean() bool {
+	return s.Changed == 0 && s.Untracked == 0
+}
+
+// Unpushed reports whether the repository holds work that exists only
+// locally: changes, stashes, commits ahead of the upstream, or commits
+// on a detached HEAD or a branch without an upstream
+func (s RepoState) Unpushed() bool {
+	return !s.Clean() || s.Stashes > 0 || s.Ahead > 0 || s.Detached || (s.Upstream == "" && !s.NoCommits)
+}
+
+// GetRepoState reads the state of the repository at repoPath
+func GetRepoState(repoPath string) (RepoState, error) {
+	cmd := command("status", "--porcelain=v2", "--branch", "--untracked-files=normal")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return RepoState{}, fmt.Errorf("failed to read status of %s: %w", repoPath, err)
+	}
+	state := parseStatus(string(output))
+
+	// Fails when there are no stashes
+	stash := command("rev-list", "--walk-reflogs", "--count", "refs/stash", "--")
+	stash.Dir = repoPath
+	if out, err := runOutput(stash); err == nil {
+		state.Stashes, _ = strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+	return state, nil
+}
+
+// parseStatus parses 'git status --porcelain=v2 --branch'
+func parseStatus(output string) RepoState {
+	var state RepoState
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "# branch.oid (initial)":
+			state.NoCommits = true
+		case strings.HasPrefix(line, "# branch.head "):
+			head := strings.TrimPrefix(line, "# branch.head ")
+			if head == "(detached)" {
+				state.Detached = true
+			} else {
+				state.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			state.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				state.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				state.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			state.Changed++
+		case strings.HasPrefix(line, "? "):
+			state.Untracked++
+		}
+	}
+	return state
+}
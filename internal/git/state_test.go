@@ -0,0 +1,56 @@
+package git
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected RepoState
+		unpushed bool
+	}{
+		{
+			name:     "clean and pushed",
+			output:   "# branch.oid 1234\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0\n",
+			expected: RepoState{Branch: "main", Upstream: "origin/main"},
+		},
+		{
+			name: "dirty, ahead and behind",
+			output: "# branch.oid 1234\n# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +2 -3\n" +
+				"1 .M N... 100644 100644 100644 abc abc README.md\n" +
+				"2 R. N... 100644 100644 100644 abc abc R100 new.go\told.go\n" +
+				"u UU N... 100644 100644 100644 100644 abc abc abc conflict.go\n" +
+				"? notes.txt\n",
+			expected: RepoState{Branch: "feature", Upstream: "origin/feature", Ahead: 2, Behind: 3, Changed: 3, Untracked: 1},
+			unpushed: true,
+		},
+		{
+			name:     "detached",
+			output:   "# branch.oid 1234\n# branch.head (detached)\n",
+			expected: RepoState{Detached: true},
+			unpushed: true,
+		},
+		{
+			name:     "fresh repository",
+			output:   "# branch.oid (initial)\n# branch.head main\n",
+			expected: RepoState{Branch: "main", NoCommits: true},
+		},
+		{
+			name:     "no upstream",
+			output:   "# branch.oid 1234\n# branch.head local-only\n",
+			expected: RepoState{Branch: "local-only"},
+			unpushed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseStatus(tt.output)
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+			if got.Unpushed() != tt.unpushed {
+				t.Errorf("expected unpushed %v, got %v", tt.unpushed, got.Unpushed())
+			}
+		})
+	}
+}
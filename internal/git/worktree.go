@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is an entry of 'git worktree list'
+type Worktree struct {
+	Path     string
+	Branch   string // short branch name; empty when detached
+	Main     bool
+	Prunable bool // the worktree directory no longer exists
+}
+
+// IsWorktree reports whether path is a linked worktree, whose git
+// directory lives inside the main repository's
+func IsWorktree(path string) bool {
+	gitDir, err := revParsePath(path, "--git-dir")
+	if err != nil {
+		return false
+	}
+	common, err := revParsePath(path, "--git-common-dir")
+	return err == nil && gitDir != common
+}
+
+// CommonDir returns the git directory shared by all worktrees of a
+// repository: the main repository's .git, or for a submodule its
+// directory under the superproject's .git/modules
+func CommonDir(repoPath string) (string, error) {
+	dir, err := revParsePath(repoPath, "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to find git directory of %s: %w", repoPath, err)
+	}
+	return dir, nil
+}
+
+// revParsePath runs 'git rev-parse <flag>' in repoPath and returns the
+// resulting path made absolute
+func revParsePath(repoPath, flag string) (string, error) {
+	if dir, ok, err := readNative(func() (string, error) { return nativeRevParsePath(repoPath, flag) }); ok {
+		return dir, err
+	}
+	cmd := command("rev-parse", flag)
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.FromSlash(strings.TrimSpace(string(output)))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// ListWorktrees returns the worktrees of a repository, main one first
+func ListWorktrees(repoPath string) ([]Worktree, error) {
+	if worktrees, ok, err := readNative(func() ([]Worktree, error) { return nativeWorktrees(repoPath) }); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		return worktrees, nil
+	}
+	cmd := command("worktree", "list", "--porcelain")
+	cmd.Dir = repoPath
+	output, err := runOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	return parseWorktreeList(string(output)), nil
+}
+
+// parseWorktreeList parses 'git worktree list --porcelain' output
+func parseWorktreeList(output string) []Worktree {
+	var worktrees []Worktree
+	for _, record := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		var wt Worktree
+		for _, line := range strings.Split(record, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "prunable":
+				wt.Prunable = true
+			}
+		}
+		if wt.Path != "" {
+			wt.Main = len(worktrees) == 0
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+// AddWorktree checks out branch in a new worktree at path. A branch that
+// exists locally or on origin is checked out; otherwise it is created
+// from HEAD.
+func AddWorktree(repoPath, path, branch string) error {
+	args := []string{"worktree", "add", path, branch}
+	if !refExists(repoPath, "refs/heads/"+branch) && !refExists(repoPath, "refs/remotes/origin/"+branch) {
+		args = []string{"worktree", "add", "-b", branch, path}
+	}
+
+	cmd := command(args...)
+	cmd.Dir = repoPath
+	if err := run(cmd); err != nil {
+		return fmt.Errorf("failed to add worktree: %w", err)
+	}
+	return nil
+}
+
+// refExists reports whether ref names an object in the repository
+func refExists(repoPath, ref string) bool {
+	cmd := command("rev-parse", "--verify", "--quiet", ref)
+	cmd.Dir = repoPath
+	return run(cmd) == nil
+}
@@ -0,0 +1,260 @@
+// Package gpg inspects GnuPG the way git uses it to sign commits: which
+// secret keys it holds, whether they can still sign, and why a signature
+// fails when git only reports "gpg failed to sign the data".
+package gpg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key is a secret key as 'gpg --list-secret-keys' reports it
+type Key struct {
+	ID          string // long ID of the primary key
+	Fingerprint string
+	UserIDs     []string
+	Expires     time.Time // zero when the key never expires
+	Revoked     bool
+
+	// Signers are the primary key and subkeys that may sign
+	Signers []Signer
+}
+
+// Signer is a key or subkey with the signing capability
+type Signer struct {
+	ID          string
+	Fingerprint string
+	Keygrip     string // how gpg-agent knows the key
+	Created     time.Time
+	Expires     time.Time
+	Revoked     bool
+}
+
+// SigningExpiry returns when the key stops being able to sign: the latest
+// expiry of its signers, but no later than the primary key's. A zero time
+// means never. ok is false when the key is revoked or has no signer left.
+func (k Key) SigningExpiry() (expires time.Time, ok bool) {
+	if k.Revoked {
+		return time.Time{}, false
+	}
+	never := false
+	for _, s := range k.Signers {
+		if s.Revoked {
+			continue
+		}
+		ok = true
+		if s.Expires.IsZero() {
+			never = true
+		} else if s.Expires.After(expires) {
+			expires = s.Expires
+		}
+	}
+	if never {
+		expires = time.Time{}
+	}
+	if !k.Expires.IsZero() && (expires.IsZero() || k.Expires.Before(expires)) {
+		expires = k.Expires
+	}
+	return expires, ok
+}
+
+// Installed reports whether the gpg program can be found
+func Installed(program string) error {
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%s not found: %w", program, err)
+	}
+	return nil
+}
+
+// ListSecretKeys returns the secret keys matching keyID (a key ID,
+// fingerprint or user ID). None is not an error.
+func ListSecretKeys(ctx context.Context, program, keyID string) ([]Key, error) {
+	cmd := exec.CommandContext(ctx, program, "--batch", "--with-colons", "--fixed-list-mode", "--list-secret-keys", "--", keyID)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if Classify(stderr.String()) == ProblemNoKey {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list secret keys: %s", lastMessage(stderr.String(), err))
+	}
+	return parseColons(stdout.String()), nil
+}
+
+// SignError is a failed test signature, with what gpg printed
+type SignError struct {
+	Output string
+}
+
+func (e *SignError) Error() string {
+	return "gpg failed to sign: " + lastMessage(e.Output, nil)
+}
+
+// Problem is why gpg failed to sign
+func (e *SignError) Problem() Problem {
+	return Classify(e.Output)
+}
+
+// TestSign signs a short message with keyID the way git does, so it
+// goes through gpg-agent and, without a cached passphrase, pinentry
+func TestSign(ctx context.Context, program, keyID string) error {
+	cmd := exec.CommandContext(ctx, program, "--status-fd=2", "-bsau", keyID)
+	cmd.Stdin = strings.NewReader("gitws test signature\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run %s: %w", program, err)
+		}
+		return &SignError{Output: stderr.String()}
+	}
+	return nil
+}
+
+// Problem is a known cause of signing failures
+type Problem int
+
+const (
+	ProblemUnknown    Problem = iota
+	ProblemNoKey              // the secret key is not in the keyring
+	ProblemExpired            // the key or its signing subkey expired
+	ProblemRevoked            // the key was revoked
+	ProblemTTY                // pinentry has no terminal: GPG_TTY is unset
+	ProblemPinentry           // no pinentry program could be started
+	ProblemCancelled          // the passphrase prompt was dismissed or timed out
+	ProblemPassphrase         // the passphrase was wrong
+	ProblemAgent              // gpg-agent is not running and could not start
+)
+
+// Classify tells from gpg's output why it failed
+func Classify(output string) Problem {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "inappropriate ioctl for device"):
+		return ProblemTTY
+	case strings.Contains(lower, "no pinentry"):
+		return ProblemPinentry
+	case strings.Contains(lower, "operation cancelled"), strings.Contains(lower, "timeout"):
+		return ProblemCancelled
+	case strings.Contains(lower, "bad passphrase"):
+		return ProblemPassphrase
+	case strings.Contains(lower, "no agent running"), strings.Contains(lower, "can't connect to the agent"), strings.Contains(lower, "connection to agent"):
+		return ProblemAgent
+	case strings.Contains(lower, "expired"):
+		return ProblemExpired
+	case strings.Contains(lower, "revoked"):
+		return ProblemRevoked
+	case strings.Contains(lower, "no secret key"), strings.Contains(lower, "unusable secret key"),
+		strings.Contains(lower, "no public key"):
+		return ProblemNoKey
+	}
+	return ProblemUnknown
+}
+
+// parseColons parses 'gpg --with-colons --fixed-list-mode' listings of
+// secret or public keys. See doc/DETAILS in the GnuPG sources.
+func parseColons(output string) []Key {
+	var keys []Key
+	var current *Key
+	// The signer the fpr and grp records that follow belong to, if the
+	// last key or subkey can sign
+	var signer *Signer
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		// Trailing empty fields may be left out
+		for len(fields) < 12 {
+			fields = append(fields, "")
+		}
+		switch fields[0] {
+		case "sec", "pub":
+			keys = append(keys, Key{
+				ID:      fields[4],
+				Expires: parseTime(fields[6]),
+				Revoked: fields[1] == "r",
+			})
+			current, signer = &keys[len(keys)-1], nil
+			if strings.Contains(fields[11], "s") {
+				current.Signers = append(current.Signers, Signer{ID: fields[4], Created: parseTime(fields[5]), Expires: current.Expires})
+				signer = &current.Signers[len(current.Signers)-1]
+			}
+		case "ssb", "sub":
+			signer = nil
+			if current != nil && strings.Contains(fields[11], "s") {
+				current.Signers = append(current.Signers, Signer{
+					ID:      fields[4],
+					Created: parseTime(fields[5]),
+					Expires: parseTime(fields[6]),
+					Revoked: fields[1] == "r",
+				})
+				signer = &current.Signers[len(current.Signers)-1]
+			}
+		case "fpr":
+			if current != nil && current.Fingerprint == "" {
+				current.Fingerprint = fields[9]
+			}
+			if signer != nil && signer.Fingerprint == "" {
+				signer.Fingerprint = fields[9]
+			}
+		case "grp":
+			if signer != nil && signer.Keygrip == "" {
+				signer.Keygrip = fields[9]
+			}
+		case "uid":
+			if current != nil && fields[1] != "r" {
+				current.UserIDs = append(current.UserIDs, fields[9])
+			}
+		}
+	}
+	return keys
+}
+
+// parseTime parses a colon-listing date: seconds since the epoch or, with
+// some options, an ISO 8601 timestamp
+func parseTime(field string) time.Time {
+	if field == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseInt(field, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC()
+	}
+	if t, err := time.Parse("20060102T150405", field); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// lastMessage returns the message gpg failed with: the last one naming
+// a failure, or else the last one. err stands in when it printed none.
+func lastMessage(output string, err error) string {
+	var last, failure string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "gpg: ")
+		if line == "" || strings.HasPrefix(line, "[GNUPG:]") {
+			continue
+		}
+		last = line
+		if strings.Contains(line, "failed") {
+			failure = line
+		}
+	}
+	switch {
+	case failure != "":
+		return failure
+	case last != "":
+		return last
+	case err != nil:
+		return err.Error()
+	}
+	return "no output"
+}
@@ -0,0 +1,141 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+)
+
+// A certify-only primary key with a signing subkey, as gpg 2.2 lists it
+const listing = `sec:u:255:22:F823B24766BB3F3D:1792203384:1823739384::u:::cSC:::+::ed25519:::0:
+fpr:::::::::BE4CECB54A30B73E0A6F41B9F823B24766BB3F3D:
+grp:::::::::1C711B7D8599497115FF2322251151F4563E5E0C:
+uid:u::::1792203384::10EC2F614A378EE95E5D804444F8F89CA52CF30E::Me <me@work.com>::::::::::0:
+ssb:u:255:22:AEA1D740562C980C:1792203384:1807755384:::::s:::+::ed25519::
+fpr:::::::::52D24C7E19AB548101E39B9FAEA1D740562C980C:
+grp:::::::::47438EEA6F96EB7960B2EBC39F6E56327C84B7C2:
+`
+
+func TestParseColons(t *testing.T) {
+	keys := parseColons(listing)
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+	key := keys[0]
+	if key.ID != "F823B24766BB3F3D" {
+		t.Errorf("expected ID %q, got %q", "F823B24766BB3F3D", key.ID)
+	}
+	if key.Fingerprint != "BE4CECB54A30B73E0A6F41B9F823B24766BB3F3D" {
+		t.Errorf("expected the primary fingerprint, got %q", key.Fingerprint)
+	}
+	if len(key.UserIDs) != 1 || key.UserIDs[0] != "Me <me@work.com>" {
+		t.Errorf("expected one user ID, got %q", key.UserIDs)
+	}
+	if len(key.Signers) != 1 || key.Signers[0].ID != "AEA1D740562C980C" {
+		t.Fatalf("expected only the subkey to sign, got %+v", key.Signers)
+	}
+	if key.Signers[0].Fingerprint != "52D24C7E19AB548101E39B9FAEA1D740562C980C" {
+		t.Errorf("expected the subkey fingerprint, got %q", key.Signers[0].Fingerprint)
+	}
+	if key.Signers[0].Keygrip != "47438EEA6F96EB7960B2EBC39F6E56327C84B7C2" {
+		t.Errorf("expected the subkey keygrip, got %q", key.Signers[0].Keygrip)
+	}
+	if expected := time.Unix(1807755384, 0).UTC(); !key.Signers[0].Expires.Equal(expected) {
+		t.Errorf("expected subkey expiry %s, got %s", expected, key.Signers[0].Expires)
+	}
+}
+
+func TestSigningExpiry(t *testing.T) {
+	june := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		key     Key
+		expires time.Time
+		ok      bool
+	}{
+		{
+			name: "never expires",
+			key:  Key{Signers: []Signer{{ID: "A"}}},
+			ok:   true,
+		},
+		{
+			name:    "latest signing subkey",
+			key:     Key{Signers: []Signer{{ID: "A", Expires: june}, {ID: "B", Expires: july}}},
+			expires: july,
+			ok:      true,
+		},
+		{
+			name:    "capped by the primary key",
+			key:     Key{Expires: june, Signers: []Signer{{ID: "A"}}},
+			expires: june,
+			ok:      true,
+		},
+		{
+			name:    "revoked subkey ignored",
+			key:     Key{Signers: []Signer{{ID: "A", Revoked: true}, {ID: "B", Expires: june}}},
+			expires: june,
+			ok:      true,
+		},
+		{
+			name: "no signing key",
+			key:  Key{},
+		},
+		{
+			name: "revoked key",
+			key:  Key{Revoked: true, Signers: []Signer{{ID: "A"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expires, ok := tt.key.SigningExpiry()
+			if ok != tt.ok || !expires.Equal(tt.expires) {
+				t.Errorf("expected %s, %v, got %s, %v", tt.expires, tt.ok, expires, ok)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected Problem
+	}{
+		{"no tty", "gpg: signing failed: Inappropriate ioctl for device\n", ProblemTTY},
+		{"no pinentry", "gpg: signing failed: No pinentry\n", ProblemPinentry},
+		{"cancelled", "gpg: signing failed: Operation cancelled\n", ProblemCancelled},
+		{"wrong passphrase", "gpg: signing failed: Bad passphrase\n", ProblemPassphrase},
+		{"agent", "gpg: can't connect to the agent: IPC connect call failed\n", ProblemAgent},
+		{"expired", "[GNUPG:] KEYEXPIRED 1700000000\ngpg: skipped \"ABCD\": Unusable secret key\n", ProblemExpired},
+		{"missing", "gpg: skipped \"nobody@x\": No secret key\n[GNUPG:] INV_SGNR 9 nobody@x\ngpg: signing failed: No secret key\n", ProblemNoKey},
+		{"other", "gpg: something else\n", ProblemUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.output); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSignErrorMessage(t *testing.T) {
+	err := &SignError{Output: "gpg: skipped \"nobody@x\": No secret key\n[GNUPG:] FAILURE sign 17\ngpg: signing failed: No secret key\n"}
+	expected := "gpg failed to sign: signing failed: No secret key"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestParseKeyinfo(t *testing.T) {
+	output := "S KEYINFO 47438EEA6F96EB7960B2EBC39F6E56327C84B7C2 D - - 1 P - - -\n" +
+		"S KEYINFO 1C711B7D8599497115FF2322251151F4563E5E0C D - - - P - - -\n" +
+		"OK\n"
+	cached := parseKeyinfo(output)
+	if !cached["47438EEA6F96EB7960B2EBC39F6E56327C84B7C2"] {
+		t.Errorf("expected the first keygrip to be cached")
+	}
+	if cached["1C711B7D8599497115FF2322251151F4563E5E0C"] {
+		t.Errorf("expected the second keygrip not to be cached")
+	}
+}
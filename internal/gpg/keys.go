@@ -0,0 +1,147 @@
+package gpg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// AddSigningSubkey adds an ed25519 signing subkey to the key with
+// fingerprint fpr, expiring after expire (e.g. "1y"), and returns it.
+// gpg asks for the key's passphrase through pinentry.
+func AddSigningSubkey(ctx context.Context, program, fpr, expire string) (Signer, error) {
+	before, err := ListSecretKeys(ctx, program, fpr)
+	if err != nil {
+		return Signer{}, err
+	}
+	if len(before) == 0 {
+		return Signer{}, fmt.Errorf("no secret key %s", fpr)
+	}
+	existing := make(map[string]bool)
+	for _, s := range before[0].Signers {
+		existing[s.ID] = true
+	}
+
+	if _, err := run(ctx, program, nil, "--quick-add-key", fpr, "ed25519", "sign", expire); err != nil {
+		return Signer{}, fmt.Errorf("failed to add signing subkey: %w", err)
+	}
+
+	after, err := ListSecretKeys(ctx, program, fpr)
+	if err != nil {
+		return Signer{}, err
+	}
+	for _, key := range after {
+		for _, s := range key.Signers {
+			if !existing[s.ID] {
+				return s, nil
+			}
+		}
+	}
+	return Signer{}, fmt.Errorf("gpg added no signing subkey to %s", fpr)
+}
+
+// SetExpiry sets when the key with fingerprint fpr expires or, given
+// subkey fingerprints, when those subkeys do
+func SetExpiry(ctx context.Context, program, fpr, expire string, subkeys ...string) error {
+	args := append([]string{"--quick-set-expire", fpr, expire}, subkeys...)
+	if _, err := run(ctx, program, nil, args...); err != nil {
+		return fmt.Errorf("failed to set expiry: %w", err)
+	}
+	return nil
+}
+
+// ExportPublicKey returns the armored public key with fingerprint fpr
+func ExportPublicKey(ctx context.Context, program, fpr string) (string, error) {
+	out, err := run(ctx, program, nil, "--armor", "--export", fpr)
+	if err != nil {
+		return "", fmt.Errorf("failed to export public key: %w", err)
+	}
+	if len(out) == 0 {
+		return "", fmt.Errorf("failed to export public key: no key %s", fpr)
+	}
+	return string(out), nil
+}
+
+// ExportSecretKey returns the armored secret key material of fpr. It
+// stays protected by the key's passphrase.
+func ExportSecretKey(ctx context.Context, program, fpr string) ([]byte, error) {
+	out, err := run(ctx, program, nil, "--armor", "--export-secret-keys", fpr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export secret key: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("failed to export secret key: no key %s", fpr)
+	}
+	return out, nil
+}
+
+// SendKey uploads the public key fpr to keyserver
+func SendKey(ctx context.Context, program, keyserver, fpr string) error {
+	if _, err := run(ctx, program, nil, "--keyserver", keyserver, "--send-keys", fpr); err != nil {
+		return fmt.Errorf("failed to send key to %s: %w", keyserver, err)
+	}
+	return nil
+}
+
+// KeyIDs returns the long IDs of the primary keys in an armored key
+// block without importing it
+func KeyIDs(ctx context.Context, program, armored string) ([]string, error) {
+	out, err := run(ctx, program, strings.NewReader(armored), "--with-colons", "--fixed-list-mode", "--import-options", "show-only", "--import")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	var ids []string
+	for _, key := range parseColons(string(out)) {
+		ids = append(ids, key.ID)
+	}
+	return ids, nil
+}
+
+// CachedKeygrips returns the keygrips whose passphrase gpg-agent has
+// cached, so signing with them will not ask for it
+func CachedKeygrips(ctx context.Context) (map[string]bool, error) {
+	cmd := exec.CommandContext(ctx, "gpg-connect-agent", "--no-autostart", "KEYINFO --list", "/bye")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	// It exits 0 when no agent is running
+	if err != nil || !strings.Contains(stdout.String(), "OK") {
+		return nil, fmt.Errorf("failed to query gpg-agent: %s", lastMessage(stderr.String()+stdout.String(), err))
+	}
+	return parseKeyinfo(stdout.String()), nil
+}
+
+// parseKeyinfo parses 'KEYINFO --list' replies:
+// S KEYINFO <keygrip> <type> <serial> <idstr> <cached> <protection> ...
+func parseKeyinfo(output string) map[string]bool {
+	cached := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 7 && fields[0] == "S" && fields[1] == "KEYINFO" && fields[6] == "1" {
+			cached[fields[2]] = true
+		}
+	}
+	return cached
+}
+
+// run runs gpg in batch mode and returns its output, or an error with
+// the message it failed with
+func run(ctx context.Context, program string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, program, append([]string{"--batch"}, args...)...)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := lastMessage(stderr.String(), err)
+		if Classify(stderr.String()) == ProblemTTY {
+			message += "; export GPG_TTY=$(tty) so pinentry can ask for the passphrase"
+		}
+		return nil, fmt.Errorf("%s", message)
+	}
+	return stdout.Bytes(), nil
+}
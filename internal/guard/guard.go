@@ -0,0 +1,361 @@
+package guard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/email"
+)
+
+// Rules, also used as the stats counters for blocked commits and pushes
+const (
+	RuleUnclassified    = "unclassified-commit"
+	RuleWrongIdentity   = "wrong-identity"
+	RuleAlias           = "alias"
+	RuleLargeFile       = "large-file"
+	RuleMissingIssue    = "missing-issue"
+	RuleProtectedBranch = "protected-branch"
+	RuleForcePush       = "force-push"
+	RuleForbiddenEmail  = "forbidden-email"
+)
+
+// Rules lists every rule, for validating rule names in settings
+var Rules = []string{RuleUnclassified, RuleWrongIdentity, RuleAlias, RuleLargeFile, RuleMissingIssue, RuleProtectedBranch, RuleForcePush, RuleForbiddenEmail}
+
+// Mandatory lists the rules a repository's .gitws.yaml cannot disable:
+// they keep commits on the workspace identity and pushes on its alias
+var Mandatory = []string{RuleUnclassified, RuleWrongIdentity, RuleAlias, RuleForbiddenEmail}
+
+// IsMandatory reports whether rule is one of Mandatory
+func IsMandatory(rule string) bool {
+	for _, r := range Mandatory {
+		if strings.EqualFold(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// Global guard modes
+const (
+	ModeWarn  = "warn"
+	ModeBlock = "block"
+)
+
+// zeroSHA is the object name git uses for a missing ref
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// Policy is the set of workspace rules enforced by repository hooks
+type Policy struct {
+	ProtectedBranches []string // branches that may not be pushed to directly
+	IssuePattern      string   // regexp commit messages must match
+	MaxFileSize       int64    // largest file in bytes a commit may add; 0 disables
+	ForbidForcePush   bool
+}
+
+// Workspace is what the repository hooks know about the workspace a
+// repository belongs to
+type Workspace struct {
+	Name   string
+	Email  string
+	Alias  string
+	Policy Policy
+	// ForbiddenEmails are addresses and domains the workspace never
+	// commits as, e.g. personal mail in a work workspace
+	ForbiddenEmails []string
+	// RequireNoreply forbids every address but the provider's noreply ones
+	RequireNoreply bool
+}
+
+// ChecksEmails reports whether the workspace forbids any address
+func (ws Workspace) ChecksEmails() bool {
+	return len(ws.ForbiddenEmails) > 0 || ws.RequireNoreply
+}
+
+// Forbids returns why the workspace forbids addr, if it does: the
+// blocklist entry it matches, or that it is not a noreply address
+func Forbids(ws Workspace, addr string) (string, bool) {
+	if entry, blocked := email.Blocked(addr, ws.ForbiddenEmails); blocked {
+		return entry, true
+	}
+	if ws.RequireNoreply && strings.TrimSpace(addr) != "" && !email.IsNoreply(addr) {
+		return "not a noreply address", true
+	}
+	return "", false
+}
+
+// Global describes what the global guard treats as classified
+type Global struct {
+	Mode          string   // "warn" or "block"
+	ProviderHosts []string // real hostnames that require a workspace
+	Aliases       []string // workspace SSH aliases
+	Roots         []string // workspace roots using directory isolation
+	// HostWorkspaces maps a real hostname to the workspace using it, for
+	// hosts only one workspace uses; fix hints name it
+	HostWorkspaces map[string]string
+}
+
+// Violation is a rule a commit or push breaks. Violations that do not
+// block are shown as warnings.
+type Violation struct {
+	Rule    string
+	Message string
+	Details []string
+	Block   bool
+}
+
+// PushUpdate is one line of the pre-push hook's standard input
+type PushUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// CheckIdentity compares the repository's committer email and origin host
+// with the workspace. A wrong or forbidden email blocks; an origin that
+// bypasses the workspace alias only warns, since the commit itself is
+// still correct.
+func CheckIdentity(ws Workspace, addr, host string) []Violation {
+	var violations []Violation
+
+	if ws.Email != "" && !strings.EqualFold(addr, ws.Email) {
+		current := addr
+		if current == "" {
+			current = "(none)"
+		}
+		violations = append(violations, Violation{
+			Rule:    RuleWrongIdentity,
+			Message: fmt.Sprintf("committing as %s, workspace '%s' uses %s", current, ws.Name, ws.Email),
+			Block:   true,
+		})
+	}
+	if v := CheckForbidden(ws, addr); v != nil {
+		violations = append(violations, *v)
+	}
+
+	if ws.Alias != "" && host != "" && host != ws.Alias {
+		violations = append(violations, Violation{
+			Rule:    RuleAlias,
+			Message: fmt.Sprintf("origin uses %s instead of the '%s' alias %s", host, ws.Name, ws.Alias),
+		})
+	}
+
+	return violations
+}
+
+// CheckForbidden blocks committing as an address the workspace forbids
+func CheckForbidden(ws Workspace, addr string) *Violation {
+	entry, blocked := Forbids(ws, addr)
+	if !blocked {
+		return nil
+	}
+	return &Violation{
+		Rule:    RuleForbiddenEmail,
+		Message: fmt.Sprintf("committing as %s, which workspace '%s' forbids (%s)", addr, ws.Name, entry),
+		Block:   true,
+	}
+}
+
+// Commit is a commit and the addresses it was made with
+type Commit struct {
+	SHA            string
+	AuthorEmail    string
+	CommitterEmail string
+}
+
+// ForbiddenCommits returns the commits authored or committed with an
+// address the workspace forbids
+func ForbiddenCommits(ws Workspace, commits []Commit) []Commit {
+	var forbidden []Commit
+	for _, c := range commits {
+		_, author := Forbids(ws, c.AuthorEmail)
+		_, committer := Forbids(ws, c.CommitterEmail)
+		if author || committer {
+			forbidden = append(forbidden, c)
+		}
+	}
+	return forbidden
+}
+
+// Describe returns a line naming the commit and the forbidden address it
+// was made with
+func (c Commit) Describe(ws Workspace) string {
+	short := c.SHA
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	if entry, blocked := Forbids(ws, c.AuthorEmail); blocked {
+		return fmt.Sprintf("%s authored by %s (%s)", short, c.AuthorEmail, entry)
+	}
+	entry, _ := Forbids(ws, c.CommitterEmail)
+	return fmt.Sprintf("%s committed by %s (%s)", short, c.CommitterEmail, entry)
+}
+
+// CheckCommits blocks commits authored or committed with an address the
+// workspace forbids, listing each of them
+func CheckCommits(ws Workspace, commits []Commit) *Violation {
+	var details []string
+	for _, c := range ForbiddenCommits(ws, commits) {
+		details = append(details, c.Describe(ws))
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return &Violation{
+		Rule:    RuleForbiddenEmail,
+		Message: fmt.Sprintf("%d commit(s) use an address workspace '%s' forbids", len(details), ws.Name),
+		Details: details,
+		Block:   true,
+	}
+}
+
+// CheckFileSizes blocks staged files larger than max bytes
+func CheckFileSizes(sizes map[string]int64, max int64) *Violation {
+	if max <= 0 {
+		return nil
+	}
+
+	var details []string
+	for path, size := range sizes {
+		if size > max {
+			details = append(details, fmt.Sprintf("%s (%d bytes)", path, size))
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	sort.Strings(details)
+
+	return &Violation{
+		Rule:    RuleLargeFile,
+		Message: fmt.Sprintf("files larger than %d bytes are not allowed", max),
+		Details: details,
+		Block:   true,
+	}
+}
+
+// CheckCommitMessage blocks messages that do not match the issue pattern.
+// Comment lines, which git strips, are ignored.
+func CheckCommitMessage(pattern, message string) (*Violation, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue pattern: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if re.MatchString(strings.Join(lines, "\n")) {
+		return nil, nil
+	}
+
+	return &Violation{
+		Rule:    RuleMissingIssue,
+		Message: fmt.Sprintf("commit message must reference an issue (matching %s)", pattern),
+		Block:   true,
+	}, nil
+}
+
+// ParsePushUpdates reads the ref updates git passes to pre-push
+func ParsePushUpdates(r io.Reader) ([]PushUpdate, error) {
+	var updates []PushUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected pre-push input: %q", scanner.Text())
+		}
+		updates = append(updates, PushUpdate{fields[0], fields[1], fields[2], fields[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pre-push input: %w", err)
+	}
+	return updates, nil
+}
+
+// CheckPush blocks pushes and deletions of protected branches and, when
+// the policy forbids it, force pushes. isAncestor reports whether the
+// first commit is an ancestor of the second; a remote commit that is not
+// an ancestor of what is pushed can only be replaced by force.
+func CheckPush(policy Policy, updates []PushUpdate, isAncestor func(ancestor, commit string) bool) []Violation {
+	protected := make(map[string]bool)
+	for _, branch := range policy.ProtectedBranches {
+		protected[branch] = true
+	}
+
+	var violations []Violation
+	for _, u := range updates {
+		branch := strings.TrimPrefix(u.RemoteRef, "refs/heads/")
+		if branch == u.RemoteRef {
+			continue // tags and other refs are not policed
+		}
+
+		if protected[branch] {
+			violations = append(violations, Violation{
+				Rule:    RuleProtectedBranch,
+				Message: fmt.Sprintf("pushing directly to %s is not allowed; open a pull request", branch),
+				Block:   true,
+			})
+			continue
+		}
+
+		if policy.ForbidForcePush && u.RemoteSHA != zeroSHA && u.LocalSHA != zeroSHA && !isAncestor(u.RemoteSHA, u.LocalSHA) {
+			violations = append(violations, Violation{
+				Rule:    RuleForcePush,
+				Message: fmt.Sprintf("force pushing %s is not allowed", branch),
+				Block:   true,
+			})
+		}
+	}
+
+	return violations
+}
+
+// CheckUnclassified flags commits in repositories whose origin points at a
+// provider host but which neither use a workspace alias nor live under a
+// workspace root. toplevel is the repository's top-level directory.
+func CheckUnclassified(g Global, host, toplevel string) *Violation {
+	if g.Mode == "" || host == "" || !contains(g.ProviderHosts, host) {
+		return nil
+	}
+	if contains(g.Aliases, host) {
+		return nil
+	}
+
+	dir := strings.TrimSuffix(toplevel, "/") + "/"
+	for _, root := range g.Roots {
+		if strings.HasPrefix(dir, strings.TrimSuffix(root, "/")+"/") {
+			return nil
+		}
+	}
+
+	return &Violation{
+		Rule:    RuleUnclassified,
+		Message: fmt.Sprintf("%s is not in any gitws workspace (%s)", toplevel, host),
+		Block:   g.Mode == ModeBlock,
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
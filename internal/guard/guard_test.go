@@ -0,0 +1,241 @@
+package guard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckIdentity(t *testing.T) {
+	ws := Workspace{Name: "work", Email: "me@work.com", Alias: "github.com-work"}
+
+	tests := []struct {
+		name     string
+		email    string
+		host     string
+		expected []string // rules, in order
+		blocks   bool
+	}{
+		{"matching", "me@work.com", "github.com-work", nil, false},
+		{"email case differs", "Me@Work.com", "github.com-work", nil, false},
+		{"wrong email", "me@home.com", "github.com-work", []string{RuleWrongIdentity}, true},
+		{"no email", "", "github.com-work", []string{RuleWrongIdentity}, true},
+		{"real host", "me@work.com", "github.com", []string{RuleAlias}, false},
+		{"no remote", "me@work.com", "", nil, false},
+		{"both", "me@home.com", "github.com", []string{RuleWrongIdentity, RuleAlias}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := CheckIdentity(ws, tt.email, tt.host)
+			var rules []string
+			blocks := false
+			for _, v := range violations {
+				rules = append(rules, v.Rule)
+				blocks = blocks || v.Block
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, rules)
+			}
+			if blocks != tt.blocks {
+				t.Errorf("expected block %v, got %v", tt.blocks, blocks)
+			}
+		})
+	}
+}
+
+func TestCheckFileSizes(t *testing.T) {
+	sizes := map[string]int64{"small.txt": 10, "big.bin": 4096, "huge.iso": 1 << 20}
+
+	if v := CheckFileSizes(sizes, 0); v != nil {
+		t.Errorf("expected no violation when disabled, got %v", v)
+	}
+	if v := CheckFileSizes(sizes, 2<<20); v != nil {
+		t.Errorf("expected no violation under the limit, got %v", v)
+	}
+
+	v := CheckFileSizes(sizes, 1024)
+	if v == nil {
+		t.Fatal("expected a violation")
+	}
+	expected := []string{"big.bin (4096 bytes)", "huge.iso (1048576 bytes)"}
+	if strings.Join(v.Details, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, v.Details)
+	}
+}
+
+func TestCheckCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		message string
+		blocks  bool
+	}{
+		{"no pattern", "", "anything", false},
+		{"matches", `[A-Z]+-[0-9]+`, "ABC-123 fix login\n", false},
+		{"matches in body", `[A-Z]+-[0-9]+`, "fix login\n\nRefs: ABC-123\n", false},
+		{"missing", `[A-Z]+-[0-9]+`, "fix login\n", true},
+		{"only in comments", `[A-Z]+-[0-9]+`, "fix login\n# On branch ABC-123\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := CheckCommitMessage(tt.pattern, tt.message)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (v != nil) != tt.blocks {
+				t.Errorf("expected block %v, got %v", tt.blocks, v)
+			}
+		})
+	}
+
+	if _, err := CheckCommitMessage("[", "msg"); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestCheckPush(t *testing.T) {
+	const (
+		a = "1111111111111111111111111111111111111111"
+		b = "2222222222222222222222222222222222222222"
+	)
+	// b descends from a; nothing else is related
+	isAncestor := func(ancestor, commit string) bool { return ancestor == a && commit == b }
+
+	input := "refs/heads/x " + b + " refs/heads/feature " + a + "\n"
+	updates, err := ParsePushUpdates(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates) != 1 || updates[0].RemoteRef != "refs/heads/feature" {
+		t.Fatalf("unexpected updates: %v", updates)
+	}
+
+	policy := Policy{ProtectedBranches: []string{"main", "release"}, ForbidForcePush: true}
+	tests := []struct {
+		name     string
+		update   PushUpdate
+		expected string
+	}{
+		{"fast-forward", PushUpdate{"refs/heads/f", b, "refs/heads/feature", a}, ""},
+		{"new branch", PushUpdate{"refs/heads/f", b, "refs/heads/feature", zeroSHA}, ""},
+		{"delete branch", PushUpdate{"(delete)", zeroSHA, "refs/heads/feature", a}, ""},
+		{"force push", PushUpdate{"refs/heads/f", a, "refs/heads/feature", b}, RuleForcePush},
+		{"protected", PushUpdate{"refs/heads/main", b, "refs/heads/main", a}, RuleProtectedBranch},
+		{"delete protected", PushUpdate{"(delete)", zeroSHA, "refs/heads/main", a}, RuleProtectedBranch},
+		{"second protected", PushUpdate{"refs/heads/release", b, "refs/heads/release", a}, RuleProtectedBranch},
+		{"tag", PushUpdate{"refs/tags/v1", a, "refs/tags/v1", b}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := CheckPush(policy, []PushUpdate{tt.update}, isAncestor)
+			result := ""
+			if len(violations) > 0 {
+				result = violations[0].Rule
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := ParsePushUpdates(strings.NewReader("too few fields\n")); err == nil {
+		t.Error("expected error for malformed input")
+	}
+}
+
+func TestCheckUnclassified(t *testing.T) {
+	g := Global{
+		Mode:          ModeBlock,
+		ProviderHosts: []string{"github.com", "gitlab.com"},
+		Aliases:       []string{"github.com-work"},
+		Roots:         []string{"/home/me/code/work"},
+	}
+
+	tests := []struct {
+		name     string
+		host     string
+		toplevel string
+		flagged  bool
+	}{
+		{"workspace alias", "github.com-work", "/tmp/repo", false},
+		{"under root", "github.com", "/home/me/code/work/org/repo", false},
+		{"root prefix only", "github.com", "/home/me/code/workshop/repo", true},
+		{"unclassified", "github.com", "/tmp/repo", true},
+		{"other host", "example.com", "/tmp/repo", false},
+		{"no remote", "", "/tmp/repo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := CheckUnclassified(g, tt.host, tt.toplevel)
+			if (v != nil) != tt.flagged {
+				t.Errorf("expected flagged %v, got %v", tt.flagged, v)
+			}
+		})
+	}
+
+	g.Mode = ModeWarn
+	if v := CheckUnclassified(g, "github.com", "/tmp/repo"); v == nil || v.Block {
+		t.Errorf("expected a non-blocking violation in warn mode, got %v", v)
+	}
+}
+
+func TestCheckIdentityForbidden(t *testing.T) {
+	ws := Workspace{Name: "work", ForbiddenEmails: []string{"gmail.com"}}
+
+	violations := CheckIdentity(ws, "me@gmail.com", "")
+	if len(violations) != 1 || violations[0].Rule != RuleForbiddenEmail || !violations[0].Block {
+		t.Errorf("expected a blocking %s violation, got %v", RuleForbiddenEmail, violations)
+	}
+	if violations := CheckIdentity(ws, "me@work.com", ""); len(violations) != 0 {
+		t.Errorf("expected no violation, got %v", violations)
+	}
+}
+
+func TestCheckCommits(t *testing.T) {
+	ws := Workspace{Name: "work", ForbiddenEmails: []string{"gmail.com"}}
+	commits := []Commit{
+		{SHA: "1111111111111111", AuthorEmail: "me@work.com", CommitterEmail: "me@work.com"},
+		{SHA: "2222222222222222", AuthorEmail: "me@gmail.com", CommitterEmail: "me@work.com"},
+		{SHA: "3333333333333333", AuthorEmail: "me@work.com", CommitterEmail: "me@gmail.com"},
+	}
+
+	if v := CheckCommits(Workspace{Name: "work"}, commits); v != nil {
+		t.Errorf("expected no violation without a blocklist, got %v", v)
+	}
+
+	v := CheckCommits(ws, commits)
+	if v == nil {
+		t.Fatal("expected a violation")
+	}
+	expected := []string{"222222222222 authored by me@gmail.com (gmail.com)", "333333333333 committed by me@gmail.com (gmail.com)"}
+	if strings.Join(v.Details, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, v.Details)
+	}
+}
+
+func TestCheckCommitsRequireNoreply(t *testing.T) {
+	ws := Workspace{Name: "oss", RequireNoreply: true}
+	commits := []Commit{
+		{SHA: "1111111111111111", AuthorEmail: "42+me@users.noreply.github.com", CommitterEmail: "42+me@users.noreply.github.com"},
+		{SHA: "2222222222222222", AuthorEmail: "me@me.com", CommitterEmail: "42+me@users.noreply.github.com"},
+	}
+
+	v := CheckCommits(ws, commits)
+	if v == nil {
+		t.Fatal("expected a violation")
+	}
+	expected := []string{"222222222222 authored by me@me.com (not a noreply address)"}
+	if strings.Join(v.Details, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, v.Details)
+	}
+
+	if v := CheckForbidden(ws, "42+me@users.noreply.github.com"); v != nil {
+		t.Errorf("expected the noreply address to be allowed, got %v", v)
+	}
+	if v := CheckForbidden(ws, "me@me.com"); v == nil || !v.Block {
+		t.Errorf("expected a blocking violation, got %v", v)
+	}
+}
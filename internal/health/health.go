@@ -0,0 +1,229 @@
+// Package health keeps the history of 'gitws doctor' results per
+// repository in ~/.gws/state, so a run can be compared with an earlier
+// one and only what got worse is reported.
+package health
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// Keep is how many snapshots are kept per repository
+const Keep = 50
+
+// Last selects the most recent snapshot as the baseline
+const Last = "last"
+
+// Issue is a doctor issue as it is remembered
+type Issue struct {
+	Code    string `json:"code,omitempty"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Snapshot is the outcome of one doctor run
+type Snapshot struct {
+	At     time.Time `json:"at"`
+	Issues []Issue   `json:"issues"`
+}
+
+// History is the doctor runs of one repository, oldest first
+type History struct {
+	Repo      string     `json:"repo"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// Change is an issue that is new or more severe than in the baseline
+type Change struct {
+	Issue
+	// Index is the position of the issue in the current run
+	Index int
+	// Was is the type the issue had in the baseline, "" when it is new
+	Was string
+}
+
+// Dir returns the directory the histories are kept in
+func Dir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state"), nil
+}
+
+// Path returns the path to the history of the repository at repo
+func Path(repo string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(repo)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Load reads the history of the repository at repo, returning an empty
+// one when there is none. A corrupt history is treated as empty.
+func Load(repo string) (*History, error) {
+	path, err := Path(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &History{Repo: repo}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, fmt.Errorf("failed to read doctor history: %w", err)
+	}
+	if err := json.Unmarshal(raw, history); err != nil || history.Repo != repo {
+		return &History{Repo: repo}, nil
+	}
+	return history, nil
+}
+
+// Save writes the history
+func (h *History) Save() error {
+	path, err := Path(h.Repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode doctor history: %w", err)
+	}
+	return fsutil.AtomicWrite(path, append(raw, '\n'), 0600)
+}
+
+// Record appends a snapshot, dropping the oldest beyond Keep
+func (h *History) Record(s Snapshot) {
+	h.Snapshots = append(h.Snapshots, s)
+	if len(h.Snapshots) > Keep {
+		h.Snapshots = h.Snapshots[len(h.Snapshots)-Keep:]
+	}
+}
+
+// Baseline returns the snapshot to compare with: the most recent for
+// Last, otherwise the most recent taken at or before the time since
+// names. It returns nil when there is no such snapshot.
+func (h *History) Baseline(since string, now time.Time) (*Snapshot, error) {
+	if since == Last {
+		if len(h.Snapshots) == 0 {
+			return nil, nil
+		}
+		return &h.Snapshots[len(h.Snapshots)-1], nil
+	}
+
+	cutoff, err := ParseSince(since, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(h.Snapshots) - 1; i >= 0; i-- {
+		if !h.Snapshots[i].At.After(cutoff) {
+			return &h.Snapshots[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ParseSince returns the time since names: Last, a duration such as 36h
+// or 7d, or a date such as 2026-03-01
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	if since == Last {
+		return now, nil
+	}
+	if days, ok := strings.CutSuffix(since, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if at, err := time.ParseInLocation("2006-01-02", since, now.Location()); err == nil {
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use %q, a duration such as 7d or 36h, or a date such as 2006-01-02", since, Last)
+}
+
+// severity orders issue types
+func severity(issueType string) int {
+	switch issueType {
+	case "error":
+		return 2
+	case "info":
+		return 0
+	}
+	return 1
+}
+
+// Compare returns the issues of current that are new or more severe than
+// in base, and the issues of base that are gone. Issues are matched by
+// code, path and message, then by code and path alone, so an issue whose
+// message carries a changing detail such as a key's age is not new.
+func Compare(base, current []Issue) (changed []Change, resolved []Issue) {
+	exact := func(i Issue) string { return i.Code + "\x00" + i.Path + "\x00" + i.Message }
+	loose := func(i Issue) string {
+		if i.Code == "" {
+			return exact(i)
+		}
+		return i.Code + "\x00" + i.Path
+	}
+
+	matched := make([]bool, len(base))
+	match := func(issue Issue, key func(Issue) string) (Issue, bool) {
+		for i, b := range base {
+			if !matched[i] && key(b) == key(issue) {
+				matched[i] = true
+				return b, true
+			}
+		}
+		return Issue{}, false
+	}
+
+	was := make([]*Issue, len(current))
+	for i, issue := range current {
+		if b, ok := match(issue, exact); ok {
+			was[i] = &b
+		}
+	}
+	for i, issue := range current {
+		if was[i] != nil {
+			continue
+		}
+		if b, ok := match(issue, loose); ok {
+			was[i] = &b
+		}
+	}
+
+	for i, issue := range current {
+		switch {
+		case was[i] == nil:
+			changed = append(changed, Change{Issue: issue, Index: i})
+		case severity(issue.Type) > severity(was[i].Type):
+			changed = append(changed, Change{Issue: issue, Index: i, Was: was[i].Type})
+		}
+	}
+	for i, b := range base {
+		if !matched[i] {
+			resolved = append(resolved, b)
+		}
+	}
+	return changed, resolved
+}
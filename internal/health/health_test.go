@@ -0,0 +1,126 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestCompare(t *testing.T) {
+	base := []Issue{
+		{Code: "GWS-HOOKS-002", Type: "warning", Message: "Guard hooks missing"},
+		{Code: "GWS-KEY-004", Type: "warning", Message: "SSH key is 91 days old"},
+		{Code: "GWS-SIGN-001", Type: "warning", Message: "Signing disabled"},
+		{Code: "GWS-REMOTE-003", Type: "error", Message: "Remote bypasses the alias"},
+	}
+	current := []Issue{
+		{Code: "GWS-HOOKS-002", Type: "warning", Message: "Guard hooks missing"},
+		{Code: "GWS-KEY-004", Type: "warning", Message: "SSH key is 98 days old"},
+		{Code: "GWS-SIGN-001", Type: "error", Message: "Signing disabled"},
+		{Code: "GWS-IDENTITY-001", Type: "error", Message: "Wrong user.email"},
+	}
+
+	changed, resolved := Compare(base, current)
+
+	if len(changed) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changed)
+	}
+	if changed[0].Code != "GWS-SIGN-001" || changed[0].Was != "warning" {
+		t.Errorf("expected GWS-SIGN-001 to be worse than a warning, got %+v", changed[0])
+	}
+	if changed[1].Code != "GWS-IDENTITY-001" || changed[1].Was != "" {
+		t.Errorf("expected GWS-IDENTITY-001 to be new, got %+v", changed[1])
+	}
+	if len(resolved) != 1 || resolved[0].Code != "GWS-REMOTE-003" {
+		t.Errorf("expected GWS-REMOTE-003 to be resolved, got %+v", resolved)
+	}
+}
+
+func TestCompareRepeatedCode(t *testing.T) {
+	base := []Issue{
+		{Code: "GWS-WORKTREE-002", Type: "warning", Message: "Worktree a commits as x", Path: "/r"},
+	}
+	current := []Issue{
+		{Code: "GWS-WORKTREE-002", Type: "warning", Message: "Worktree b commits as y", Path: "/r"},
+		{Code: "GWS-WORKTREE-002", Type: "warning", Message: "Worktree a commits as x", Path: "/r"},
+	}
+
+	changed, resolved := Compare(base, current)
+	if len(changed) != 1 || changed[0].Message != "Worktree b commits as y" {
+		t.Errorf("expected only worktree b to be new, got %+v", changed)
+	}
+	if len(resolved) != 0 {
+		t.Errorf("expected nothing resolved, got %+v", resolved)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	h := &History{Repo: "/code/api"}
+	for _, days := range []int{20, 8, 6, 1} {
+		h.Record(Snapshot{At: now.AddDate(0, 0, -days)})
+	}
+
+	tests := []struct {
+		since    string
+		expected time.Time
+	}{
+		{Last, now.AddDate(0, 0, -1)},
+		{"7d", now.AddDate(0, 0, -8)},
+		{"48h", now.AddDate(0, 0, -6)},
+		{"2026-02-25", now.AddDate(0, 0, -20)},
+		{"30d", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			baseline, err := h.Baseline(tt.since, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got time.Time
+			if baseline != nil {
+				got = baseline.At
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := h.Baseline("yesterday", now); err == nil {
+		t.Error("expected an error for an unknown time")
+	}
+}
+
+func TestLoadSaveRecord(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	h, err := Load("/code/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < Keep+5; i++ {
+		h.Record(Snapshot{At: at.Add(time.Duration(i) * time.Hour), Issues: []Issue{{Type: "warning", Message: "m"}}})
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h, err = Load("/code/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(h.Snapshots) != Keep {
+		t.Fatalf("expected %d snapshots, got %d", Keep, len(h.Snapshots))
+	}
+	if expected := at.Add(5 * time.Hour); !h.Snapshots[0].At.Equal(expected) {
+		t.Errorf("expected the oldest snapshot from %v, got %v", expected, h.Snapshots[0].At)
+	}
+
+	other, err := Load("/code/web")
+	if err != nil || len(other.Snapshots) != 0 {
+		t.Errorf("expected no history for another repository, got %+v, %v", other, err)
+	}
+}
@@ -0,0 +1,268 @@
+package i18n
+
+// de is the German catalog
+var de = map[string]string{
+	// Prompts
+	"%s (y/N): ":      "%s (j/N): ",
+	"Choose [1-%d]: ": "Auswahl [1-%d]: ",
+
+	// Summaries
+	"Public Key:":                               "Öffentlicher Schlüssel:",
+	"Public Key QR Code:":                       "QR-Code des öffentlichen Schlüssels:",
+	"Next Steps:":                               "Nächste Schritte:",
+	"Adopt summary":                             "Übernahme-Zusammenfassung",
+	"✓ Backup of workspace '%s' complete":       "✓ Sicherung des Workspace '%s' abgeschlossen",
+	"✓ Exported %d workspace(s)":                "✓ %d Workspace(s) exportiert",
+	"✓ Identity '%s' added to workspace '%s'":   "✓ Identität '%s' zum Workspace '%s' hinzugefügt",
+	"✓ Imported %d workspace(s)":                "✓ %d Workspace(s) importiert",
+	"✓ Logged in successfully":                  "✓ Erfolgreich angemeldet",
+	"✓ Repository cloned successfully":          "✓ Repository erfolgreich geklont",
+	"✓ Repository created successfully":         "✓ Repository erfolgreich erstellt",
+	"✓ Repository mirrored successfully":        "✓ Repository erfolgreich gespiegelt",
+	"✓ SSH keys rotated for %d workspace(s)":    "✓ SSH-Schlüssel für %d Workspace(s) rotiert",
+	"✓ SSH keys rotated for workspace '%s'":     "✓ SSH-Schlüssel für Workspace '%s' rotiert",
+	"✓ Tutorial complete":                       "✓ Tutorial abgeschlossen",
+	"✓ Workspace '%s' initialized successfully": "✓ Workspace '%s' erfolgreich eingerichtet",
+	"✓ Workspace '%s' renamed to '%s'":          "✓ Workspace '%s' in '%s' umbenannt",
+	"✓ Worktree created successfully":           "✓ Worktree erfolgreich erstellt",
+
+	// Summary labels and table headers
+	"Account":               "Konto",
+	"Adopted":               "Übernommen",
+	"Already configured":    "Bereits eingerichtet",
+	"Archive":               "Archiv",
+	"Backup Location":       "Sicherungsort",
+	"Branch":                "Branch",
+	"Clone Options":         "Klon-Optionen",
+	"Default Branch":        "Standard-Branch",
+	"Destination":           "Ziel",
+	"Email":                 "E-Mail",
+	"Env File":              "Umgebungsdatei",
+	"Extra Host":            "Zusätzlicher Host",
+	"Failed":                "Fehlgeschlagen",
+	"Force Push":            "Force-Push",
+	"Host":                  "Host",
+	"Identity":              "Identität",
+	"Imported":              "Importiert",
+	"Isolation":             "Isolierung",
+	"Issue Pattern":         "Ticket-Muster",
+	"Keys":                  "Schlüssel",
+	"Max File Size":         "Maximale Dateigröße",
+	"Mirror":                "Spiegel",
+	"Name":                  "Name",
+	"New Mirrors":           "Neue Spiegel",
+	"New Private Key":       "Neuer privater Schlüssel",
+	"New Public Key":        "Neuer öffentlicher Schlüssel",
+	"No matching workspace": "Kein passender Workspace",
+	"Old Provider Key":      "Alter Schlüssel beim Anbieter",
+	"Primary Email":         "Primäre E-Mail",
+	"Property":              "Eigenschaft",
+	"Protected Branches":    "Geschützte Branches",
+	"Provider Key Added":    "Schlüssel beim Anbieter hinzugefügt",
+	"Provider Key Removed":  "Schlüssel beim Anbieter entfernt",
+	"Pull Strategy":         "Pull-Strategie",
+	"Pushed":                "Gepusht",
+	"Remotes Rewritten":     "Remotes umgeschrieben",
+	"Repositories found":    "Gefundene Repositorys",
+	"Repository":            "Repository",
+	"Root":                  "Stammverzeichnis",
+	"Rotated keys":          "Rotierte Schlüssel",
+	"SSH Alias":             "SSH-Alias",
+	"SSH URL":               "SSH-URL",
+	"Signing":               "Signierung",
+	"Skipped":               "Übersprungen",
+	"Stored In":             "Gespeichert in",
+	"Updated Mirrors":       "Aktualisierte Spiegel",
+	"Value":                 "Wert",
+	"Visibility":            "Sichtbarkeit",
+	"Web URL":               "Web-URL",
+	"Workspace":             "Workspace",
+	"Workspaces":            "Workspaces",
+	"Worktree":              "Worktree",
+
+	// Status
+	"Repository Status":    "Repository-Status",
+	"Path":                 "Pfad",
+	"Origin":               "Origin",
+	"User Name":            "Benutzername",
+	"User Email":           "Benutzer-E-Mail",
+	"Signing Key":          "Signaturschlüssel",
+	"Guard Hooks":          "Schutz-Hooks",
+	"Not set":              "Nicht gesetzt",
+	"unknown":              "unbekannt",
+	"Unmanaged (%s)":       "Nicht verwaltet (%s)",
+	"Disabled":             "Deaktiviert",
+	"Enabled (%s)":         "Aktiviert (%s)",
+	"Installed":            "Installiert",
+	"Not installed":        "Nicht installiert",
+	"⚠️  Issues found:":    "⚠️  Gefundene Probleme:",
+	"✓ All checks passed!": "✓ Alle Prüfungen bestanden!",
+	"Run 'gitws doctor' for detailed analysis and fixes.": "Führen Sie 'gitws doctor' für eine genaue Analyse und Korrekturen aus.",
+
+	// Doctor
+	"Doctor Report": "Diagnosebericht",
+	"Fix: %s":       "Lösung: %s",
+	"✓ All checks passed! No issues found.": "✓ Alle Prüfungen bestanden! Keine Probleme gefunden.",
+	"Suppressed:": "Unterdrückt:",
+	"ℹ️  %d issue(s) suppressed by .gitws.yaml or the workspace (--show-suppressed lists them)": "ℹ️  %d Problem(e) durch .gitws.yaml oder den Workspace unterdrückt (--show-suppressed zeigt sie an)",
+	"ℹ️  No earlier doctor run to compare with; showing all issues":                             "ℹ️  Kein früherer Diagnoselauf zum Vergleich; alle Probleme werden angezeigt",
+	"ℹ️  Since the run of %s: %d unchanged, %d resolved":                                        "ℹ️  Seit dem Lauf vom %s: %d unverändert, %d behoben",
+	"Feature support:": "Unterstützte Funktionen:",
+	"Check durations:": "Dauer der Prüfungen:",
+	"(timed out)":      "(Zeitüberschreitung)",
+
+	// Doctor issues
+	"%s (was %s)": "%s (vorher %s)",
+	"%s declares the repository unmanaged; only machine-wide checks ran":                                                                             "%s erklärt das Repository als nicht verwaltet; nur rechnerweite Prüfungen wurden ausgeführt",
+	"%s disables guard rule %q, which cannot be disabled and stays enforced":                                                                         "%s deaktiviert die Guard-Regel %q, die nicht deaktiviert werden kann und weiter gilt",
+	"%s disables unknown guard rule %q":                                                                                                              "%s deaktiviert die unbekannte Guard-Regel %q",
+	"%s is %q, workspace '%s' policy is %q":                                                                                                          "%s ist %q, die Richtlinie von Workspace '%s' ist %q",
+	"%s is not trusted or changed since it was, so its settings are ignored; review it, then trust it":                                               "%s ist nicht vertrauenswürdig oder wurde seitdem geändert, daher werden seine Einstellungen ignoriert; prüfen Sie es und vertrauen Sie ihm dann",
+	"%s is on a network filesystem (%s); gitws falls back to in-place writes where rename is unreliable":                                             "%s liegt auf einem Netzwerkdateisystem (%s); gitws schreibt direkt in die Datei, wo Umbenennen unzuverlässig ist",
+	"%s is unmanaged but also names a workspace or identity, which are ignored":                                                                      "%s ist nicht verwaltet, nennt aber auch einen Workspace oder eine Identität, die ignoriert werden",
+	"%s keeps %s private; pushes of commits using it are rejected (GH007) if the account blocks pushes that expose its email":                        "%s hält %s privat; Pushes von Commits damit werden abgelehnt (GH007), wenn das Konto Pushes blockiert, die seine E-Mail offenlegen",
+	"%s pins workspace %q, which is not in config.yaml":                                                                                              "%s legt den Workspace %q fest, der nicht in config.yaml steht",
+	"%s resolves to %q from %s (%s), not the workspace's %q: %s. Values in the order git reads them:%s":                                              "%s ergibt %q aus %s (%s), nicht das %q des Workspace: %s. Werte in der Reihenfolge, in der git sie liest:%s",
+	"%s selects identity %q, which workspace '%s' does not have":                                                                                     "%s wählt die Identität %q, die Workspace '%s' nicht hat",
+	"%s=%q in the environment overrides %s %q from every gitconfig":                                                                                  "%s=%q in der Umgebung überschreibt %s %q aus jeder gitconfig",
+	"Add %s.pub to your %s account, then test with: ssh -T %s":                                                                                       "Fügen Sie %s.pub zu Ihrem %s-Konto hinzu und testen Sie mit: ssh -T %s",
+	"Add a signing subkey: gpg --quick-add-key %s ed25519 sign 1y":                                                                                   "Fügen Sie einen Signatur-Unterschlüssel hinzu: gpg --quick-add-key %s ed25519 sign 1y",
+	"Add and verify %s in the account's email settings, or commit with the noreply address":                                                          "Fügen Sie %s in den E-Mail-Einstellungen des Kontos hinzu und bestätigen Sie sie, oder committen Sie mit der Noreply-Adresse",
+	"Add it with 'gitws identity add %s %s --email <email>', or correct %s":                                                                          "Fügen Sie sie mit 'gitws identity add %s %s --email <email>' hinzu, oder korrigieren Sie %s",
+	"Add origin remote: git remote add origin <url>":                                                                                                 "Fügen Sie das Remote origin hinzu: git remote add origin <url>",
+	"Add to your shell profile: export GPG_TTY=$(tty)":                                                                                               "Fügen Sie Ihrem Shell-Profil hinzu: export GPG_TTY=$(tty)",
+	"Alias %s does not use IdentitiesOnly; ssh may offer agent keys before the workspace key":                                                        "Alias %s verwendet IdentitiesOnly nicht; ssh bietet eventuell Agent-Schlüssel vor dem Workspace-Schlüssel an",
+	"Alias %s offers %s before the workspace key %s":                                                                                                 "Alias %s bietet %s vor dem Workspace-Schlüssel %s an",
+	"An earlier 'Host *' block likely sets IdentitiesOnly no; move it below the gws blocks in ~/.ssh/config":                                         "Vermutlich setzt ein früherer 'Host *'-Block IdentitiesOnly no; verschieben Sie ihn in ~/.ssh/config unter die gws-Blöcke",
+	"Ask your administrator to fix %s":                                                                                                               "Bitten Sie Ihren Administrator, %s zu korrigieren",
+	"Cannot connect to %s through alias %s":                                                                                                          "Keine Verbindung zu %s über Alias %s möglich",
+	"Check '%s' timed out after %s":                                                                                                                  "Prüfung '%s' hat nach %s das Zeitlimit überschritten",
+	"Check 'git -C %s config --show-origin user.email' and remove the override":                                                                      "Prüfen Sie 'git -C %s config --show-origin user.email' und entfernen Sie die Überschreibung",
+	"Check for a wrapper or shell profile that resets GIT_AUTHOR_* variables":                                                                        "Suchen Sie nach einem Wrapper oder Shell-Profil, das die GIT_AUTHOR_*-Variablen zurücksetzt",
+	"Check the includeIf entries in ~/.gitconfig, and that the repository is under %s":                                                               "Prüfen Sie die includeIf-Einträge in ~/.gitconfig und ob das Repository unter %s liegt",
+	"Check your Git signing configuration":                                                                                                           "Prüfen Sie Ihre Git-Signaturkonfiguration",
+	"Check your network connection, or skip network checks with --offline":                                                                           "Prüfen Sie Ihre Netzwerkverbindung, oder überspringen Sie Netzwerkprüfungen mit --offline",
+	"Check your network connection; the last fetched rules still apply":                                                                              "Prüfen Sie Ihre Netzwerkverbindung; die zuletzt abgerufenen Regeln gelten weiter",
+	"Check ~/.gws/config.yaml":                                                                                                                       "Prüfen Sie ~/.gws/config.yaml",
+	"Check ~/.ssh/config with: ssh -G %s":                                                                                                            "Prüfen Sie ~/.ssh/config mit: ssh -G %s",
+	"Commit with the account's noreply address":                                                                                                      "Committen Sie mit der Noreply-Adresse des Kontos",
+	"Commit with the noreply address":                                                                                                                "Committen Sie mit der Noreply-Adresse",
+	"Commits are signed with gpg, but %s is not installed":                                                                                           "Commits werden mit gpg signiert, aber %s ist nicht installiert",
+	"Commits use %s, which is not an address of your %s account; they will not be attributed to the account and push rules may reject them":          "Commits verwenden %s, keine Adresse Ihres %s-Kontos; sie werden dem Konto nicht zugeordnet und Push-Regeln können sie ablehnen",
+	"Commits use %s, which is not your %s account's noreply address (%s); they will not be attributed to the account and push rules may reject them": "Commits verwenden %s, nicht die Noreply-Adresse Ihres %s-Kontos (%s); sie werden dem Konto nicht zugeordnet und Push-Regeln können sie ablehnen",
+	"Commits use %s, which your %s account has not verified; push rules may reject them":                                                             "Commits verwenden %s, die Ihr %s-Konto nicht bestätigt hat; Push-Regeln können sie ablehnen",
+	"Commits would use %s, which workspace '%s' forbids (%s)":                                                                                        "Commits würden %s verwenden, was Workspace '%s' verbietet (%s)",
+	"Configure signing key: git config user.signingkey <key>":                                                                                        "Konfigurieren Sie den Signaturschlüssel: git config user.signingkey <key>",
+	"Could not check guard hooks status":                                                                                                             "Der Status der Guard-Hooks konnte nicht geprüft werden",
+	"Could not determine signing configuration":                                                                                                      "Die Signaturkonfiguration konnte nicht ermittelt werden",
+	"Could not load workspace configuration":                                                                                                         "Die Workspace-Konfiguration konnte nicht geladen werden",
+	"Create it with 'gitws init %s', or correct %s":                                                                                                  "Legen Sie ihn mit 'gitws init %s' an, oder korrigieren Sie %s",
+	"Default branch is '%s', workspace '%s' policy is '%s'":                                                                                          "Standard-Branch ist '%s', die Richtlinie von Workspace '%s' ist '%s'",
+	"Enter the key's passphrase when pinentry asks; if it never appears, restart the agent: gpgconf --kill gpg-agent":                                "Geben Sie die Passphrase des Schlüssels ein, wenn pinentry danach fragt; erscheint es nie, starten Sie den Agent neu: gpgconf --kill gpg-agent",
+	"Export GPG_TTY in your shell profile: export GPG_TTY=$(tty)":                                                                                    "Exportieren Sie GPG_TTY in Ihrem Shell-Profil: export GPG_TTY=$(tty)",
+	"Extend it: %s, then upload the public key to your provider again":                                                                               "Verlängern Sie ihn: %s, und laden Sie dann den öffentlichen Schlüssel erneut zu Ihrem Anbieter hoch",
+	"Fix or remove %s": "Korrigieren oder entfernen Sie %s",
+	"GPG_TTY is not exported, so a terminal pinentry cannot ask for the passphrase":                    "GPG_TTY ist nicht exportiert, daher kann ein Terminal-pinentry nicht nach der Passphrase fragen",
+	"Generate a new key (gpg --quick-gen-key), then sign with it: git config user.signingkey <key-id>": "Erzeugen Sie einen neuen Schlüssel (gpg --quick-gen-key) und signieren Sie damit: git config user.signingkey <key-id>",
+	"Git is not installed or not in PATH":                                                              "Git ist nicht installiert oder nicht im PATH",
+	"Git version: %s":                                                                                  "Git-Version: %s",
+	"Global credential.helper '%s' caches a single HTTPS login for %s, shared by workspaces %s":        "Der globale credential.helper '%s' speichert eine einzige HTTPS-Anmeldung für %s, die sich die Workspaces %s teilen",
+	"Global url rewrite maps '%s' to '%s', which uses the default SSH key instead of a workspace key":  "Eine globale url-Umschreibung bildet '%s' auf '%s' ab, das den Standard-SSH-Schlüssel statt eines Workspace-Schlüssels verwendet",
+	"Global url rewrite sends '%s' to '%s', bypassing workspace '%s'":                                  "Eine globale url-Umschreibung leitet '%s' auf '%s' um und umgeht Workspace '%s'",
+	"Global user.email is set (%s); repos outside every workspace commit as it without complaint":      "Globales user.email ist gesetzt (%s); Repositories außerhalb aller Workspaces committen damit ohne Warnung",
+	"Guard hooks not installed":                                                                        "Guard-Hooks nicht installiert",
+	"Import the key (gpg --import <file>), or sign with one you have: git config user.signingkey <key-id> (list them with: gpg --list-secret-keys --keyid-format long)": "Importieren Sie den Schlüssel (gpg --import <file>), oder signieren Sie mit einem vorhandenen: git config user.signingkey <key-id> (Liste mit: gpg --list-secret-keys --keyid-format long)",
+	"Install Git and ensure it's in your PATH": "Installieren Sie Git und stellen Sie sicher, dass es im PATH ist",
+	"Install GnuPG (e.g. brew install gnupg, apt install gnupg), or point git at it: git config --global gpg.program <path>": "Installieren Sie GnuPG (z. B. brew install gnupg, apt install gnupg), oder zeigen Sie git den Pfad: git config --global gpg.program <path>",
+	"Install guard hooks": "Installieren Sie die Guard-Hooks",
+	"Install pinentry (e.g. brew install pinentry-mac, apt install pinentry-curses), set pinentry-program in ~/.gnupg/gpg-agent.conf, then: gpgconf --kill gpg-agent":          "Installieren Sie pinentry (z. B. brew install pinentry-mac, apt install pinentry-curses), setzen Sie pinentry-program in ~/.gnupg/gpg-agent.conf, dann: gpgconf --kill gpg-agent",
+	"Keep the key on a local disk and register it with 'gitws init <workspace> --force --key-file <path> --key-mode reference', or mount the share with owner-only file modes": "Legen Sie den Schlüssel auf eine lokale Platte und registrieren Sie ihn mit 'gitws init <workspace> --force --key-file <path> --key-mode reference', oder binden Sie die Freigabe mit Rechten nur für den Eigentümer ein",
+	"Manually verify hooks in %s":                                                                                                  "Prüfen Sie die Hooks in %s von Hand",
+	"Move repository to workspace root or update workspace configuration":                                                          "Verschieben Sie das Repository in das Workspace-Stammverzeichnis oder passen Sie die Workspace-Konfiguration an",
+	"Nested git processes see user.email %q, not %s; git %s ignores GIT_CONFIG_COUNT":                                              "Verschachtelte git-Prozesse sehen user.email %q, nicht %s; git %s ignoriert GIT_CONFIG_COUNT",
+	"Nested git processes under 'gitws exec %s' commit as %s":                                                                      "Verschachtelte git-Prozesse unter 'gitws exec %s' committen als %s",
+	"No gpg secret key for %s":                                                                                                     "Kein geheimer gpg-Schlüssel für %s",
+	"No host key for %s in known_hosts; the first clone will prompt, or fail in BatchMode":                                         "Kein Hostschlüssel für %s in known_hosts; der erste Klon fragt nach oder schlägt im BatchMode fehl",
+	"No origin remote configured":                                                                                                  "Kein Remote origin konfiguriert",
+	"No user.email configured":                                                                                                     "Kein user.email konfiguriert",
+	"No user.name configured":                                                                                                      "Kein user.name konfiguriert",
+	"Organization policy bans HTTPS remotes; origin is %s":                                                                         "Die Organisationsrichtlinie verbietet HTTPS-Remotes; origin ist %s",
+	"Organization policy could not be refreshed from %s: %v":                                                                       "Die Organisationsrichtlinie konnte nicht von %s aktualisiert werden: %v",
+	"Organization policy requires guard hooks, which are not installed":                                                            "Die Organisationsrichtlinie verlangt Guard-Hooks, die nicht installiert sind",
+	"Organization policy requires signed commits on %s, but workspace '%s' does not sign":                                          "Die Organisationsrichtlinie verlangt signierte Commits auf %s, aber Workspace '%s' signiert nicht",
+	"Organization policy: %v":                                                                                                      "Organisationsrichtlinie: %v",
+	"Prefer a key on a local disk: 'gitws init <workspace> --force --key-file <path> --key-mode reference'":                        "Verwenden Sie besser einen Schlüssel auf einer lokalen Platte: 'gitws init <workspace> --force --key-file <path> --key-mode reference'",
+	"Re-run with --verbose to see how long each check takes":                                                                       "Führen Sie den Befehl mit --verbose erneut aus, um die Dauer jeder Prüfung zu sehen",
+	"Register the key with the account":                                                                                            "Registrieren Sie den Schlüssel beim Konto",
+	"Remote URL is not using SSH":                                                                                                  "Die Remote-URL verwendet kein SSH",
+	"Remote URL not using HTTPS, which workspace %s authenticates with (current: %s)":                                              "Die Remote-URL verwendet kein HTTPS, mit dem sich Workspace %s anmeldet (aktuell: %s)",
+	"Remote URL not using gitws alias (current: %s)":                                                                               "Die Remote-URL verwendet nicht den gitws-Alias (aktuell: %s)",
+	"Remove %q from disable_guards in %s":                                                                                          "Entfernen Sie %q aus disable_guards in %s",
+	"Remove %s from %s, or move it above the gitws includeIf block":                                                                "Entfernen Sie %s aus %s, oder verschieben Sie es über den includeIf-Block von gitws",
+	"Remove either unmanaged or workspace/identity from %s":                                                                        "Entfernen Sie entweder unmanaged oder workspace/identity aus %s",
+	"Remove the IdentityFile from the earlier matching block in ~/.ssh/config":                                                     "Entfernen Sie das IdentityFile aus dem früheren passenden Block in ~/.ssh/config",
+	"Remove the override from your shell environment or git alias":                                                                 "Entfernen Sie die Überschreibung aus Ihrer Shell-Umgebung oder Ihrem git-Alias",
+	"Rename the default branch on the provider, then run 'git remote set-head origin --auto'":                                      "Benennen Sie den Standard-Branch beim Anbieter um und führen Sie dann 'git remote set-head origin --auto' aus",
+	"Repository is also under the root of %s; '%s' wins because its includeIf is read last, but settings only %s sets still apply": "Das Repository liegt auch unter dem Stammverzeichnis von %s; '%s' gewinnt, weil sein includeIf zuletzt gelesen wird, aber Einstellungen, die nur %s setzt, gelten weiterhin",
+	"Repository is under nested workspace roots; '%s' wins over the innermost workspace '%s' because its includeIf is read later":  "Das Repository liegt unter verschachtelten Workspace-Stammverzeichnissen; '%s' gewinnt gegen den innersten Workspace '%s', weil sein includeIf später gelesen wird",
+	"Repository not in workspace root (expected: %s)":                                                                              "Repository liegt nicht im Workspace-Stammverzeichnis (erwartet: %s)",
+	"Reproduce it with: echo test | %s --clearsign -u %s":                                                                          "Reproduzieren Sie es mit: echo test | %s --clearsign -u %s",
+	"Restart it: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent":                                                           "Starten Sie ihn neu: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent",
+	"Rewrite remote URL to HTTPS":                                                                                                  "Schreiben Sie die Remote-URL auf HTTPS um",
+	"Rewrite remote URL to SSH":                                                                                                    "Schreiben Sie die Remote-URL auf SSH um",
+	"Rewrite remote URL to use workspace alias":                                                                                    "Schreiben Sie die Remote-URL auf den Workspace-Alias um",
+	"Rewrite the includeIf block so nested workspaces come last":                                                                   "Schreiben Sie den includeIf-Block neu, sodass verschachtelte Workspaces zuletzt kommen",
+	"Run 'gitws doctor' again and enter the passphrase; raise pinentry-timeout in ~/.gnupg/gpg-agent.conf if it closes too soon":   "Führen Sie 'gitws doctor' erneut aus und geben Sie die Passphrase ein; erhöhen Sie pinentry-timeout in ~/.gnupg/gpg-agent.conf, falls es zu früh schließt",
+	"Run 'gitws doctor' again with the right passphrase, or change it: gpg --change-passphrase %s":                                 "Führen Sie 'gitws doctor' mit der richtigen Passphrase erneut aus, oder ändern Sie sie: gpg --change-passphrase %s",
+	"Run 'gitws init' to create workspace or check configuration":                                                                  "Führen Sie 'gitws init' aus, um den Workspace anzulegen, oder prüfen Sie die Konfiguration",
+	"SSH alias '%s' not found in workspace configuration":                                                                          "SSH-Alias '%s' nicht in der Workspace-Konfiguration gefunden",
+	"SSH key %s is not registered with your %s account; pushes will be rejected unless it is a deploy key":                         "SSH-Schlüssel %s ist nicht bei Ihrem %s-Konto registriert; Pushes werden abgelehnt, sofern er kein Deploy-Key ist",
+	"SSH key %s is on a %s mount that reports mode %04o; ssh will ignore it as unprotected":                                        "SSH-Schlüssel %s liegt auf einem %s-Mount, der Modus %04o meldet; ssh ignoriert ihn als ungeschützt",
+	"SSH key %s is on a network filesystem (%s); ssh stops using it if the mount loosens its permissions":                          "SSH-Schlüssel %s liegt auf einem Netzwerkdateisystem (%s); ssh verwendet ihn nicht mehr, wenn der Mount seine Rechte lockert",
+	"SSH key comment %q does not match workspace identity %q":                                                                      "SSH-Schlüsselkommentar %q passt nicht zur Workspace-Identität %q",
+	"SSH key for workspace %s is %d days old (max_key_age %s)":                                                                     "SSH-Schlüssel von Workspace %s ist %d Tage alt (max_key_age %s)",
+	"SSH signing key should end with .pub":                                                                                         "Der SSH-Signaturschlüssel sollte auf .pub enden",
+	"Set those values explicitly in '%s', move one of the roots so they do not nest, or give one workspace --isolation hasconfig":  "Setzen Sie diese Werte ausdrücklich in '%s', verschieben Sie ein Stammverzeichnis, damit sie nicht verschachtelt sind, oder geben Sie einem Workspace --isolation hasconfig",
+	"Set user.email: git config user.email 'your@email.com'":                                                                       "Setzen Sie user.email: git config user.email 'your@email.com'",
+	"Set user.name: git config user.name 'Your Name'":                                                                              "Setzen Sie user.name: git config user.name 'Your Name'",
+	"Signing enabled but no signing key configured":                                                                                "Signieren aktiviert, aber kein Signaturschlüssel konfiguriert",
+	"The test signature failed: wrong passphrase":                                                                                  "Die Testsignatur ist fehlgeschlagen: falsche Passphrase",
+	"The test signature was cancelled: pinentry was dismissed or timed out":                                                        "Die Testsignatur wurde abgebrochen: pinentry wurde geschlossen oder lief ab",
+	"Unset %s in your shell profile or direnv setup":                                                                               "Entfernen Sie %s aus Ihrem Shell-Profil oder Ihrer direnv-Einrichtung",
+	"Update signing key to use .pub file":                                                                                          "Stellen Sie den Signaturschlüssel auf die .pub-Datei um",
+	"Update the key comment (the key itself is unchanged)":                                                                         "Aktualisieren Sie den Schlüsselkommentar (der Schlüssel selbst bleibt unverändert)",
+	"Upgrade git and OpenSSH, or set signing: none for the workspace":                                                              "Aktualisieren Sie git und OpenSSH, oder setzen Sie signing: none für den Workspace",
+	"Upgrade git to %s or later so signing settings reach submodules under 'gitws exec'":                                           "Aktualisieren Sie git auf %s oder neuer, damit Signatureinstellungen Submodule unter 'gitws exec' erreichen",
+	"Upgrade git, then run 'gitws init %s --force' to switch back to remote-based isolation":                                       "Aktualisieren Sie git und führen Sie dann 'gitws init %s --force' aus, um zur remote-basierten Isolation zurückzukehren",
+	"Use one of: %s": "Verwenden Sie eines von: %s",
+	"Verify %s in the account's email settings, or commit with the noreply address":                                "Bestätigen Sie %s in den E-Mail-Einstellungen des Kontos, oder committen Sie mit der Noreply-Adresse",
+	"Verify the key with the provider, then: ssh-keygen -R %s && gitws known-hosts %s":                             "Prüfen Sie den Schlüssel beim Anbieter, dann: ssh-keygen -R %s && gitws known-hosts %s",
+	"Workspace '%s': %v; commits fail to sign":                                                                     "Workspace '%s': %v; Commits können nicht signiert werden",
+	"Workspace '%s': %v; it falls back to directory-based isolation under %s":                                      "Workspace '%s': %v; es fällt auf verzeichnisbasierte Isolation unter %s zurück",
+	"Worktree %s commits as %q, expected %s":                                                                       "Worktree %s committet als %q, erwartet %s",
+	"Worktree %s no longer exists":                                                                                 "Worktree %s existiert nicht mehr",
+	"git config --global --unset-all %s, then clone with 'gitws clone'":                                            "git config --global --unset-all %s, dann mit 'gitws clone' klonen",
+	"git config --global credential.useHttpPath true, or use 'gitws fix' to switch remotes to SSH aliases":         "git config --global credential.useHttpPath true, oder stellen Sie die Remotes mit 'gitws fix' auf SSH-Aliasse um",
+	"gpg cannot ask for the passphrase: pinentry has no terminal":                                                  "gpg kann nicht nach der Passphrase fragen: pinentry hat kein Terminal",
+	"gpg cannot reach gpg-agent":                                                                                   "gpg erreicht gpg-agent nicht",
+	"gpg key %s expired on %s":                                                                                     "gpg-Schlüssel %s ist am %s abgelaufen",
+	"gpg key %s expires on %s":                                                                                     "gpg-Schlüssel %s läuft am %s ab",
+	"gpg key %s has no key that can sign":                                                                          "gpg-Schlüssel %s hat keinen Schlüssel, der signieren kann",
+	"gpg key %s is revoked":                                                                                        "gpg-Schlüssel %s ist widerrufen",
+	"gpg-agent cannot start pinentry to ask for the passphrase":                                                    "gpg-agent kann pinentry nicht starten, um nach der Passphrase zu fragen",
+	"it is passed with -c or GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS, which override every file":                    "es wird mit -c oder GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS übergeben, die jede Datei überschreiben",
+	"it is read after the workspace gitconfig, and the last value git reads wins":                                  "es wird nach der Workspace-gitconfig gelesen, und der zuletzt gelesene Wert gewinnt",
+	"known_hosts key for %s (%s) does not match the published fingerprints":                                        "Der known_hosts-Schlüssel für %s (%s) passt nicht zu den veröffentlichten Fingerabdrücken",
+	"per-worktree config overrides the repository and global files":                                                "die Konfiguration pro Worktree überschreibt die Repository- und globalen Dateien",
+	"ssh offers %s to bare %s; remotes that bypass the workspace alias authenticate as whichever account owns it":  "ssh bietet %s dem bloßen %s an; Remotes, die den Workspace-Alias umgehen, melden sich als das Konto an, dem er gehört",
+	"the repository's own config overrides global files, including the workspace gitconfig":                        "die eigene Konfiguration des Repository überschreibt globale Dateien, einschließlich der Workspace-gitconfig",
+	"the workspace gitconfig %s is not included for this repository":                                               "die Workspace-gitconfig %s wird für dieses Repository nicht eingebunden",
+	"user.useConfigOnly is not enabled; outside a workspace git guesses an identity instead of refusing to commit": "user.useConfigOnly ist nicht aktiviert; außerhalb eines Workspace rät git eine Identität, statt den Commit zu verweigern",
+	"%s has mode %04o; it should be 0700":                                                                          "%s hat den Modus %04o; er sollte 0700 sein",
+	"%s is owned by another user; ssh refuses to use it":                                                           "%s gehört einem anderen Benutzer; ssh verweigert die Verwendung",
+	"SSH key %s does not exist":                                                                                    "SSH-Schlüssel %s existiert nicht",
+	"SSH key %s has mode %04o; ssh ignores keys others can read":                                                   "SSH-Schlüssel %s hat den Modus %04o; ssh ignoriert Schlüssel, die andere lesen können",
+	"SSH key %s is owned by another user; ssh refuses to use it":                                                   "SSH-Schlüssel %s gehört einem anderen Benutzer; ssh verweigert die Verwendung",
+	"Public key %s is missing":                                                                                     "Öffentlicher Schlüssel %s fehlt",
+	"%s does not match its private key (%s); the wrong key may be registered with your provider":                   "%s passt nicht zu seinem privaten Schlüssel (%s); möglicherweise ist beim Anbieter der falsche Schlüssel registriert",
+}
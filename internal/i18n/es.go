@@ -0,0 +1,268 @@
+package i18n
+
+// es is the Spanish catalog
+var es = map[string]string{
+	// Prompts
+	"%s (y/N): ":      "%s (s/N): ",
+	"Choose [1-%d]: ": "Elija [1-%d]: ",
+
+	// Summaries
+	"Public Key:":                               "Clave pública:",
+	"Public Key QR Code:":                       "Código QR de la clave pública:",
+	"Next Steps:":                               "Próximos pasos:",
+	"Adopt summary":                             "Resumen de adopción",
+	"✓ Backup of workspace '%s' complete":       "✓ Copia de seguridad del espacio de trabajo '%s' completada",
+	"✓ Exported %d workspace(s)":                "✓ %d espacio(s) de trabajo exportado(s)",
+	"✓ Identity '%s' added to workspace '%s'":   "✓ Identidad '%s' añadida al espacio de trabajo '%s'",
+	"✓ Imported %d workspace(s)":                "✓ %d espacio(s) de trabajo importado(s)",
+	"✓ Logged in successfully":                  "✓ Sesión iniciada correctamente",
+	"✓ Repository cloned successfully":          "✓ Repositorio clonado correctamente",
+	"✓ Repository created successfully":         "✓ Repositorio creado correctamente",
+	"✓ Repository mirrored successfully":        "✓ Repositorio replicado correctamente",
+	"✓ SSH keys rotated for %d workspace(s)":    "✓ Claves SSH rotadas en %d espacio(s) de trabajo",
+	"✓ SSH keys rotated for workspace '%s'":     "✓ Claves SSH rotadas en el espacio de trabajo '%s'",
+	"✓ Tutorial complete":                       "✓ Tutorial completado",
+	"✓ Workspace '%s' initialized successfully": "✓ Espacio de trabajo '%s' inicializado correctamente",
+	"✓ Workspace '%s' renamed to '%s'":          "✓ Espacio de trabajo '%s' renombrado a '%s'",
+	"✓ Worktree created successfully":           "✓ Worktree creado correctamente",
+
+	// Summary labels and table headers
+	"Account":               "Cuenta",
+	"Adopted":               "Adoptados",
+	"Already configured":    "Ya configurados",
+	"Archive":               "Archivo",
+	"Backup Location":       "Ubicación de la copia",
+	"Branch":                "Rama",
+	"Clone Options":         "Opciones de clonado",
+	"Default Branch":        "Rama por defecto",
+	"Destination":           "Destino",
+	"Email":                 "Correo",
+	"Env File":              "Archivo de entorno",
+	"Extra Host":            "Host adicional",
+	"Failed":                "Fallidos",
+	"Force Push":            "Push forzado",
+	"Host":                  "Host",
+	"Identity":              "Identidad",
+	"Imported":              "Importados",
+	"Isolation":             "Aislamiento",
+	"Issue Pattern":         "Patrón de incidencia",
+	"Keys":                  "Claves",
+	"Max File Size":         "Tamaño máximo de archivo",
+	"Mirror":                "Réplica",
+	"Name":                  "Nombre",
+	"New Mirrors":           "Réplicas nuevas",
+	"New Private Key":       "Nueva clave privada",
+	"New Public Key":        "Nueva clave pública",
+	"No matching workspace": "Sin espacio de trabajo",
+	"Old Provider Key":      "Clave anterior en el proveedor",
+	"Primary Email":         "Correo principal",
+	"Property":              "Propiedad",
+	"Protected Branches":    "Ramas protegidas",
+	"Provider Key Added":    "Clave añadida al proveedor",
+	"Provider Key Removed":  "Clave retirada del proveedor",
+	"Pull Strategy":         "Estrategia de pull",
+	"Pushed":                "Publicado",
+	"Remotes Rewritten":     "Remotos reescritos",
+	"Repositories found":    "Repositorios encontrados",
+	"Repository":            "Repositorio",
+	"Root":                  "Raíz",
+	"Rotated keys":          "Claves rotadas",
+	"SSH Alias":             "Alias SSH",
+	"SSH URL":               "URL SSH",
+	"Signing":               "Firma",
+	"Skipped":               "Omitidos",
+	"Stored In":             "Guardado en",
+	"Updated Mirrors":       "Réplicas actualizadas",
+	"Value":                 "Valor",
+	"Visibility":            "Visibilidad",
+	"Web URL":               "URL web",
+	"Workspace":             "Espacio de trabajo",
+	"Workspaces":            "Espacios de trabajo",
+	"Worktree":              "Worktree",
+
+	// Status
+	"Repository Status":    "Estado del repositorio",
+	"Path":                 "Ruta",
+	"Origin":               "Origen",
+	"User Name":            "Nombre de usuario",
+	"User Email":           "Correo de usuario",
+	"Signing Key":          "Clave de firma",
+	"Guard Hooks":          "Hooks de protección",
+	"Not set":              "No configurado",
+	"unknown":              "desconocido",
+	"Unmanaged (%s)":       "No gestionado (%s)",
+	"Disabled":             "Desactivada",
+	"Enabled (%s)":         "Activada (%s)",
+	"Installed":            "Instalados",
+	"Not installed":        "No instalados",
+	"⚠️  Issues found:":    "⚠️  Problemas encontrados:",
+	"✓ All checks passed!": "✓ ¡Todas las comprobaciones superadas!",
+	"Run 'gitws doctor' for detailed analysis and fixes.": "Ejecute 'gitws doctor' para un análisis detallado y soluciones.",
+
+	// Doctor
+	"Doctor Report": "Informe de diagnóstico",
+	"Fix: %s":       "Solución: %s",
+	"✓ All checks passed! No issues found.": "✓ ¡Todas las comprobaciones superadas! No se encontraron problemas.",
+	"Suppressed:": "Suprimidos:",
+	"ℹ️  %d issue(s) suppressed by .gitws.yaml or the workspace (--show-suppressed lists them)": "ℹ️  %d problema(s) suprimido(s) por .gitws.yaml o el espacio de trabajo (--show-suppressed los muestra)",
+	"ℹ️  No earlier doctor run to compare with; showing all issues":                             "ℹ️  No hay una ejecución anterior de doctor con la que comparar; se muestran todos los problemas",
+	"ℹ️  Since the run of %s: %d unchanged, %d resolved":                                        "ℹ️  Desde la ejecución del %s: %d sin cambios, %d resuelto(s)",
+	"Feature support:": "Funciones disponibles:",
+	"Check durations:": "Duración de las comprobaciones:",
+	"(timed out)":      "(tiempo agotado)",
+
+	// Doctor issues
+	"%s (was %s)": "%s (antes %s)",
+	"%s declares the repository unmanaged; only machine-wide checks ran":                                                                             "%s declara el repositorio como no gestionado; solo se ejecutaron las comprobaciones de todo el equipo",
+	"%s disables guard rule %q, which cannot be disabled and stays enforced":                                                                         "%s desactiva la regla de guardia %q, que no se puede desactivar y sigue aplicándose",
+	"%s disables unknown guard rule %q":                                                                                                              "%s desactiva la regla de guardia desconocida %q",
+	"%s is %q, workspace '%s' policy is %q":                                                                                                          "%s es %q, la política del espacio de trabajo '%s' es %q",
+	"%s is not trusted or changed since it was, so its settings are ignored; review it, then trust it":                                               "%s no es de confianza o cambió desde entonces, así que se ignora su configuración; revíselo y luego confíe en él",
+	"%s is on a network filesystem (%s); gitws falls back to in-place writes where rename is unreliable":                                             "%s está en un sistema de archivos de red (%s); gitws escribe en el sitio donde renombrar no es fiable",
+	"%s is unmanaged but also names a workspace or identity, which are ignored":                                                                      "%s no está gestionado pero también indica un espacio de trabajo o una identidad, que se ignoran",
+	"%s keeps %s private; pushes of commits using it are rejected (GH007) if the account blocks pushes that expose its email":                        "%s mantiene %s en privado; los pushes de commits que la usan se rechazan (GH007) si la cuenta bloquea los pushes que exponen su correo",
+	"%s pins workspace %q, which is not in config.yaml":                                                                                              "%s fija el espacio de trabajo %q, que no está en config.yaml",
+	"%s resolves to %q from %s (%s), not the workspace's %q: %s. Values in the order git reads them:%s":                                              "%s se resuelve a %q desde %s (%s), no al %q del espacio de trabajo: %s. Valores en el orden en que git los lee:%s",
+	"%s selects identity %q, which workspace '%s' does not have":                                                                                     "%s selecciona la identidad %q, que el espacio de trabajo '%s' no tiene",
+	"%s=%q in the environment overrides %s %q from every gitconfig":                                                                                  "%s=%q en el entorno anula %s %q de todos los gitconfig",
+	"Add %s.pub to your %s account, then test with: ssh -T %s":                                                                                       "Añada %s.pub a su cuenta de %s y pruebe con: ssh -T %s",
+	"Add a signing subkey: gpg --quick-add-key %s ed25519 sign 1y":                                                                                   "Añada una subclave de firma: gpg --quick-add-key %s ed25519 sign 1y",
+	"Add and verify %s in the account's email settings, or commit with the noreply address":                                                          "Añada y verifique %s en la configuración de correo de la cuenta, o haga commits con la dirección noreply",
+	"Add it with 'gitws identity add %s %s --email <email>', or correct %s":                                                                          "Añádala con 'gitws identity add %s %s --email <email>', o corrija %s",
+	"Add origin remote: git remote add origin <url>":                                                                                                 "Añada el remoto origin: git remote add origin <url>",
+	"Add to your shell profile: export GPG_TTY=$(tty)":                                                                                               "Añada a su perfil de shell: export GPG_TTY=$(tty)",
+	"Alias %s does not use IdentitiesOnly; ssh may offer agent keys before the workspace key":                                                        "El alias %s no usa IdentitiesOnly; ssh puede ofrecer claves del agente antes que la clave del espacio de trabajo",
+	"Alias %s offers %s before the workspace key %s":                                                                                                 "El alias %s ofrece %s antes que la clave del espacio de trabajo %s",
+	"An earlier 'Host *' block likely sets IdentitiesOnly no; move it below the gws blocks in ~/.ssh/config":                                         "Probablemente un bloque 'Host *' anterior establece IdentitiesOnly no; muévalo debajo de los bloques gws en ~/.ssh/config",
+	"Ask your administrator to fix %s":                                                                                                               "Pida a su administrador que corrija %s",
+	"Cannot connect to %s through alias %s":                                                                                                          "No se puede conectar a %s mediante el alias %s",
+	"Check '%s' timed out after %s":                                                                                                                  "La comprobación '%s' agotó el tiempo tras %s",
+	"Check 'git -C %s config --show-origin user.email' and remove the override":                                                                      "Revise 'git -C %s config --show-origin user.email' y elimine la anulación",
+	"Check for a wrapper or shell profile that resets GIT_AUTHOR_* variables":                                                                        "Busque un wrapper o perfil de shell que restablezca las variables GIT_AUTHOR_*",
+	"Check the includeIf entries in ~/.gitconfig, and that the repository is under %s":                                                               "Revise las entradas includeIf de ~/.gitconfig y que el repositorio esté bajo %s",
+	"Check your Git signing configuration":                                                                                                           "Revise su configuración de firma de Git",
+	"Check your network connection, or skip network checks with --offline":                                                                           "Revise su conexión de red, u omita las comprobaciones de red con --offline",
+	"Check your network connection; the last fetched rules still apply":                                                                              "Revise su conexión de red; se siguen aplicando las últimas reglas obtenidas",
+	"Check ~/.gws/config.yaml":                                                                                                                       "Revise ~/.gws/config.yaml",
+	"Check ~/.ssh/config with: ssh -G %s":                                                                                                            "Revise ~/.ssh/config con: ssh -G %s",
+	"Commit with the account's noreply address":                                                                                                      "Haga commits con la dirección noreply de la cuenta",
+	"Commit with the noreply address":                                                                                                                "Haga commits con la dirección noreply",
+	"Commits are signed with gpg, but %s is not installed":                                                                                           "Los commits se firman con gpg, pero %s no está instalado",
+	"Commits use %s, which is not an address of your %s account; they will not be attributed to the account and push rules may reject them":          "Los commits usan %s, que no es una dirección de su cuenta de %s; no se atribuirán a la cuenta y las reglas de push pueden rechazarlos",
+	"Commits use %s, which is not your %s account's noreply address (%s); they will not be attributed to the account and push rules may reject them": "Los commits usan %s, que no es la dirección noreply de su cuenta de %s (%s); no se atribuirán a la cuenta y las reglas de push pueden rechazarlos",
+	"Commits use %s, which your %s account has not verified; push rules may reject them":                                                             "Los commits usan %s, que su cuenta de %s no ha verificado; las reglas de push pueden rechazarlos",
+	"Commits would use %s, which workspace '%s' forbids (%s)":                                                                                        "Los commits usarían %s, que el espacio de trabajo '%s' prohíbe (%s)",
+	"Configure signing key: git config user.signingkey <key>":                                                                                        "Configure la clave de firma: git config user.signingkey <key>",
+	"Could not check guard hooks status":                                                                                                             "No se pudo comprobar el estado de los hooks de guardia",
+	"Could not determine signing configuration":                                                                                                      "No se pudo determinar la configuración de firma",
+	"Could not load workspace configuration":                                                                                                         "No se pudo cargar la configuración de los espacios de trabajo",
+	"Create it with 'gitws init %s', or correct %s":                                                                                                  "Créelo con 'gitws init %s', o corrija %s",
+	"Default branch is '%s', workspace '%s' policy is '%s'":                                                                                          "La rama por defecto es '%s', la política del espacio de trabajo '%s' es '%s'",
+	"Enter the key's passphrase when pinentry asks; if it never appears, restart the agent: gpgconf --kill gpg-agent":                                "Introduzca la frase de paso de la clave cuando pinentry la pida; si nunca aparece, reinicie el agente: gpgconf --kill gpg-agent",
+	"Export GPG_TTY in your shell profile: export GPG_TTY=$(tty)":                                                                                    "Exporte GPG_TTY en su perfil de shell: export GPG_TTY=$(tty)",
+	"Extend it: %s, then upload the public key to your provider again":                                                                               "Amplíela: %s, y vuelva a subir la clave pública a su proveedor",
+	"Fix or remove %s": "Corrija o elimine %s",
+	"GPG_TTY is not exported, so a terminal pinentry cannot ask for the passphrase":                    "GPG_TTY no está exportada, así que un pinentry de terminal no puede pedir la frase de paso",
+	"Generate a new key (gpg --quick-gen-key), then sign with it: git config user.signingkey <key-id>": "Genere una clave nueva (gpg --quick-gen-key) y firme con ella: git config user.signingkey <key-id>",
+	"Git is not installed or not in PATH":                                                              "Git no está instalado o no está en el PATH",
+	"Git version: %s":                                                                                  "Versión de Git: %s",
+	"Global credential.helper '%s' caches a single HTTPS login for %s, shared by workspaces %s":        "El credential.helper global '%s' guarda un único inicio de sesión HTTPS para %s, compartido por los espacios de trabajo %s",
+	"Global url rewrite maps '%s' to '%s', which uses the default SSH key instead of a workspace key":  "Una reescritura url global asigna '%s' a '%s', que usa la clave SSH por defecto en lugar de una clave del espacio de trabajo",
+	"Global url rewrite sends '%s' to '%s', bypassing workspace '%s'":                                  "Una reescritura url global envía '%s' a '%s', evitando el espacio de trabajo '%s'",
+	"Global user.email is set (%s); repos outside every workspace commit as it without complaint":      "El user.email global está definido (%s); los repositorios fuera de todo espacio de trabajo hacen commits con él sin avisar",
+	"Guard hooks not installed":                                                                        "Hooks de guardia no instalados",
+	"Import the key (gpg --import <file>), or sign with one you have: git config user.signingkey <key-id> (list them with: gpg --list-secret-keys --keyid-format long)": "Importe la clave (gpg --import <file>), o firme con una que tenga: git config user.signingkey <key-id> (lístelas con: gpg --list-secret-keys --keyid-format long)",
+	"Install Git and ensure it's in your PATH": "Instale Git y asegúrese de que esté en su PATH",
+	"Install GnuPG (e.g. brew install gnupg, apt install gnupg), or point git at it: git config --global gpg.program <path>": "Instale GnuPG (p. ej. brew install gnupg, apt install gnupg), o indíquele a git dónde está: git config --global gpg.program <path>",
+	"Install guard hooks": "Instale los hooks de guardia",
+	"Install pinentry (e.g. brew install pinentry-mac, apt install pinentry-curses), set pinentry-program in ~/.gnupg/gpg-agent.conf, then: gpgconf --kill gpg-agent":          "Instale pinentry (p. ej. brew install pinentry-mac, apt install pinentry-curses), defina pinentry-program en ~/.gnupg/gpg-agent.conf y luego: gpgconf --kill gpg-agent",
+	"Keep the key on a local disk and register it with 'gitws init <workspace> --force --key-file <path> --key-mode reference', or mount the share with owner-only file modes": "Guarde la clave en un disco local y regístrela con 'gitws init <workspace> --force --key-file <path> --key-mode reference', o monte el recurso compartido con permisos solo para el propietario",
+	"Manually verify hooks in %s":                                                                                                  "Verifique manualmente los hooks en %s",
+	"Move repository to workspace root or update workspace configuration":                                                          "Mueva el repositorio a la raíz del espacio de trabajo o actualice la configuración del espacio de trabajo",
+	"Nested git processes see user.email %q, not %s; git %s ignores GIT_CONFIG_COUNT":                                              "Los procesos git anidados ven user.email %q, no %s; git %s ignora GIT_CONFIG_COUNT",
+	"Nested git processes under 'gitws exec %s' commit as %s":                                                                      "Los procesos git anidados bajo 'gitws exec %s' hacen commits como %s",
+	"No gpg secret key for %s":                                                                                                     "No hay clave secreta gpg para %s",
+	"No host key for %s in known_hosts; the first clone will prompt, or fail in BatchMode":                                         "No hay clave de host para %s en known_hosts; el primer clon preguntará, o fallará en BatchMode",
+	"No origin remote configured":                                                                                                  "No hay remoto origin configurado",
+	"No user.email configured":                                                                                                     "No hay user.email configurado",
+	"No user.name configured":                                                                                                      "No hay user.name configurado",
+	"Organization policy bans HTTPS remotes; origin is %s":                                                                         "La política de la organización prohíbe los remotos HTTPS; origin es %s",
+	"Organization policy could not be refreshed from %s: %v":                                                                       "No se pudo actualizar la política de la organización desde %s: %v",
+	"Organization policy requires guard hooks, which are not installed":                                                            "La política de la organización exige hooks de guardia, que no están instalados",
+	"Organization policy requires signed commits on %s, but workspace '%s' does not sign":                                          "La política de la organización exige commits firmados en %s, pero el espacio de trabajo '%s' no firma",
+	"Organization policy: %v":                                                                                                      "Política de la organización: %v",
+	"Prefer a key on a local disk: 'gitws init <workspace> --force --key-file <path> --key-mode reference'":                        "Prefiera una clave en un disco local: 'gitws init <workspace> --force --key-file <path> --key-mode reference'",
+	"Re-run with --verbose to see how long each check takes":                                                                       "Vuelva a ejecutar con --verbose para ver cuánto tarda cada comprobación",
+	"Register the key with the account":                                                                                            "Registre la clave en la cuenta",
+	"Remote URL is not using SSH":                                                                                                  "La URL del remoto no usa SSH",
+	"Remote URL not using HTTPS, which workspace %s authenticates with (current: %s)":                                              "La URL del remoto no usa HTTPS, con el que se autentica el espacio de trabajo %s (actual: %s)",
+	"Remote URL not using gitws alias (current: %s)":                                                                               "La URL del remoto no usa el alias de gitws (actual: %s)",
+	"Remove %q from disable_guards in %s":                                                                                          "Elimine %q de disable_guards en %s",
+	"Remove %s from %s, or move it above the gitws includeIf block":                                                                "Elimine %s de %s, o muévalo encima del bloque includeIf de gitws",
+	"Remove either unmanaged or workspace/identity from %s":                                                                        "Elimine unmanaged o workspace/identity de %s",
+	"Remove the IdentityFile from the earlier matching block in ~/.ssh/config":                                                     "Elimine el IdentityFile del bloque coincidente anterior en ~/.ssh/config",
+	"Remove the override from your shell environment or git alias":                                                                 "Elimine la anulación de su entorno de shell o alias de git",
+	"Rename the default branch on the provider, then run 'git remote set-head origin --auto'":                                      "Renombre la rama por defecto en el proveedor y luego ejecute 'git remote set-head origin --auto'",
+	"Repository is also under the root of %s; '%s' wins because its includeIf is read last, but settings only %s sets still apply": "El repositorio también está bajo la raíz de %s; gana '%s' porque su includeIf se lee al final, pero la configuración que solo %s define sigue aplicándose",
+	"Repository is under nested workspace roots; '%s' wins over the innermost workspace '%s' because its includeIf is read later":  "El repositorio está bajo raíces de espacios de trabajo anidadas; '%s' gana sobre el espacio de trabajo más interno '%s' porque su includeIf se lee después",
+	"Repository not in workspace root (expected: %s)":                                                                              "El repositorio no está en la raíz del espacio de trabajo (esperado: %s)",
+	"Reproduce it with: echo test | %s --clearsign -u %s":                                                                          "Reprodúzcalo con: echo test | %s --clearsign -u %s",
+	"Restart it: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent":                                                           "Reinícielo: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent",
+	"Rewrite remote URL to HTTPS":                                                                                                  "Reescriba la URL del remoto a HTTPS",
+	"Rewrite remote URL to SSH":                                                                                                    "Reescriba la URL del remoto a SSH",
+	"Rewrite remote URL to use workspace alias":                                                                                    "Reescriba la URL del remoto para usar el alias del espacio de trabajo",
+	"Rewrite the includeIf block so nested workspaces come last":                                                                   "Reescriba el bloque includeIf para que los espacios de trabajo anidados vayan al final",
+	"Run 'gitws doctor' again and enter the passphrase; raise pinentry-timeout in ~/.gnupg/gpg-agent.conf if it closes too soon":   "Ejecute 'gitws doctor' de nuevo e introduzca la frase de paso; aumente pinentry-timeout en ~/.gnupg/gpg-agent.conf si se cierra demasiado pronto",
+	"Run 'gitws doctor' again with the right passphrase, or change it: gpg --change-passphrase %s":                                 "Ejecute 'gitws doctor' de nuevo con la frase de paso correcta, o cámbiela: gpg --change-passphrase %s",
+	"Run 'gitws init' to create workspace or check configuration":                                                                  "Ejecute 'gitws init' para crear el espacio de trabajo o revise la configuración",
+	"SSH alias '%s' not found in workspace configuration":                                                                          "El alias SSH '%s' no se encuentra en la configuración de los espacios de trabajo",
+	"SSH key %s is not registered with your %s account; pushes will be rejected unless it is a deploy key":                         "La clave SSH %s no está registrada en su cuenta de %s; los pushes se rechazarán salvo que sea una clave de despliegue",
+	"SSH key %s is on a %s mount that reports mode %04o; ssh will ignore it as unprotected":                                        "La clave SSH %s está en un montaje %s que indica el modo %04o; ssh la ignorará por no estar protegida",
+	"SSH key %s is on a network filesystem (%s); ssh stops using it if the mount loosens its permissions":                          "La clave SSH %s está en un sistema de archivos de red (%s); ssh deja de usarla si el montaje relaja sus permisos",
+	"SSH key comment %q does not match workspace identity %q":                                                                      "El comentario de la clave SSH %q no coincide con la identidad del espacio de trabajo %q",
+	"SSH key for workspace %s is %d days old (max_key_age %s)":                                                                     "La clave SSH del espacio de trabajo %s tiene %d días (max_key_age %s)",
+	"SSH signing key should end with .pub":                                                                                         "La clave de firma SSH debería terminar en .pub",
+	"Set those values explicitly in '%s', move one of the roots so they do not nest, or give one workspace --isolation hasconfig":  "Defina esos valores explícitamente en '%s', mueva una de las raíces para que no se aniden, o dé a un espacio de trabajo --isolation hasconfig",
+	"Set user.email: git config user.email 'your@email.com'":                                                                       "Defina user.email: git config user.email 'your@email.com'",
+	"Set user.name: git config user.name 'Your Name'":                                                                              "Defina user.name: git config user.name 'Your Name'",
+	"Signing enabled but no signing key configured":                                                                                "Firma activada pero sin clave de firma configurada",
+	"The test signature failed: wrong passphrase":                                                                                  "La firma de prueba falló: frase de paso incorrecta",
+	"The test signature was cancelled: pinentry was dismissed or timed out":                                                        "La firma de prueba se canceló: pinentry se cerró o agotó el tiempo",
+	"Unset %s in your shell profile or direnv setup":                                                                               "Elimine %s de su perfil de shell o configuración de direnv",
+	"Update signing key to use .pub file":                                                                                          "Actualice la clave de firma para usar el archivo .pub",
+	"Update the key comment (the key itself is unchanged)":                                                                         "Actualice el comentario de la clave (la clave en sí no cambia)",
+	"Upgrade git and OpenSSH, or set signing: none for the workspace":                                                              "Actualice git y OpenSSH, o defina signing: none para el espacio de trabajo",
+	"Upgrade git to %s or later so signing settings reach submodules under 'gitws exec'":                                           "Actualice git a %s o posterior para que la configuración de firma llegue a los submódulos bajo 'gitws exec'",
+	"Upgrade git, then run 'gitws init %s --force' to switch back to remote-based isolation":                                       "Actualice git y luego ejecute 'gitws init %s --force' para volver al aislamiento basado en el remoto",
+	"Use one of: %s": "Use uno de: %s",
+	"Verify %s in the account's email settings, or commit with the noreply address":                                "Verifique %s en la configuración de correo de la cuenta, o haga commits con la dirección noreply",
+	"Verify the key with the provider, then: ssh-keygen -R %s && gitws known-hosts %s":                             "Verifique la clave con el proveedor y luego: ssh-keygen -R %s && gitws known-hosts %s",
+	"Workspace '%s': %v; commits fail to sign":                                                                     "Espacio de trabajo '%s': %v; los commits no se pueden firmar",
+	"Workspace '%s': %v; it falls back to directory-based isolation under %s":                                      "Espacio de trabajo '%s': %v; vuelve al aislamiento por directorio bajo %s",
+	"Worktree %s commits as %q, expected %s":                                                                       "El worktree %s hace commits como %q, se esperaba %s",
+	"Worktree %s no longer exists":                                                                                 "El worktree %s ya no existe",
+	"git config --global --unset-all %s, then clone with 'gitws clone'":                                            "git config --global --unset-all %s, y luego clone con 'gitws clone'",
+	"git config --global credential.useHttpPath true, or use 'gitws fix' to switch remotes to SSH aliases":         "git config --global credential.useHttpPath true, o use 'gitws fix' para cambiar los remotos a alias SSH",
+	"gpg cannot ask for the passphrase: pinentry has no terminal":                                                  "gpg no puede pedir la frase de paso: pinentry no tiene terminal",
+	"gpg cannot reach gpg-agent":                                                                                   "gpg no puede contactar con gpg-agent",
+	"gpg key %s expired on %s":                                                                                     "La clave gpg %s caducó el %s",
+	"gpg key %s expires on %s":                                                                                     "La clave gpg %s caduca el %s",
+	"gpg key %s has no key that can sign":                                                                          "La clave gpg %s no tiene ninguna clave que pueda firmar",
+	"gpg key %s is revoked":                                                                                        "La clave gpg %s está revocada",
+	"gpg-agent cannot start pinentry to ask for the passphrase":                                                    "gpg-agent no puede iniciar pinentry para pedir la frase de paso",
+	"it is passed with -c or GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS, which override every file":                    "se pasa con -c o GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS, que anulan todos los archivos",
+	"it is read after the workspace gitconfig, and the last value git reads wins":                                  "se lee después del gitconfig del espacio de trabajo, y gana el último valor que lee git",
+	"known_hosts key for %s (%s) does not match the published fingerprints":                                        "La clave de known_hosts para %s (%s) no coincide con las huellas publicadas",
+	"per-worktree config overrides the repository and global files":                                                "la configuración por worktree anula los archivos del repositorio y globales",
+	"ssh offers %s to bare %s; remotes that bypass the workspace alias authenticate as whichever account owns it":  "ssh ofrece %s al %s sin alias; los remotos que evitan el alias del espacio de trabajo se autentican como la cuenta a la que pertenezca",
+	"the repository's own config overrides global files, including the workspace gitconfig":                        "la configuración propia del repositorio anula los archivos globales, incluido el gitconfig del espacio de trabajo",
+	"the workspace gitconfig %s is not included for this repository":                                               "el gitconfig del espacio de trabajo %s no se incluye para este repositorio",
+	"user.useConfigOnly is not enabled; outside a workspace git guesses an identity instead of refusing to commit": "user.useConfigOnly no está activado; fuera de un espacio de trabajo git adivina una identidad en lugar de negarse a hacer commit",
+	"%s has mode %04o; it should be 0700":                                                                          "%s tiene el modo %04o; debería ser 0700",
+	"%s is owned by another user; ssh refuses to use it":                                                           "%s pertenece a otro usuario; ssh se niega a usarlo",
+	"SSH key %s does not exist":                                                                                    "La clave SSH %s no existe",
+	"SSH key %s has mode %04o; ssh ignores keys others can read":                                                   "La clave SSH %s tiene el modo %04o; ssh ignora las claves que otros pueden leer",
+	"SSH key %s is owned by another user; ssh refuses to use it":                                                   "La clave SSH %s pertenece a otro usuario; ssh se niega a usarla",
+	"Public key %s is missing":                                                                                     "Falta la clave pública %s",
+	"%s does not match its private key (%s); the wrong key may be registered with your provider":                   "%s no coincide con su clave privada (%s); puede que haya una clave equivocada registrada en su proveedor",
+}
@@ -0,0 +1,113 @@
+// Package i18n translates gitws's user-facing text. Messages are looked
+// up by their English text, so a message missing from a catalog is shown
+// in English. Machine-readable output (--json) is never translated.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Default is the language of the message keys themselves
+const Default = "en"
+
+// catalogs maps a language to its translations, keyed by English text
+var catalogs = map[string]map[string]string{
+	"es": es,
+	"de": de,
+	"ja": ja,
+}
+
+// yesWords are the answers each language accepts for yes, besides y/yes
+var yesWords = map[string][]string{
+	"es": {"s", "si", "sí"},
+	"de": {"j", "ja"},
+	"ja": {"はい"},
+}
+
+// current is the language output is translated to
+var current = Default
+
+// Languages returns the supported languages, sorted
+func Languages() []string {
+	langs := []string{Default}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supported reports whether lang is one of Languages
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok || lang == Default
+}
+
+// Detect picks the language: configured (config.yaml's language) when
+// set, then LC_ALL, LC_MESSAGES and LANG, the way POSIX programs do.
+// Unsupported languages fall back to English.
+func Detect(configured string) string {
+	if configured != "" {
+		return normalize(configured)
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return normalize(value)
+		}
+	}
+	return Default
+}
+
+// normalize turns a locale such as de_DE.UTF-8 into a supported language
+func normalize(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if !Supported(lang) {
+		return Default
+	}
+	return lang
+}
+
+// SetLanguage selects the language T translates to
+func SetLanguage(lang string) {
+	if !Supported(lang) {
+		lang = Default
+	}
+	current = lang
+}
+
+// Language returns the selected language
+func Language() string {
+	return current
+}
+
+// T translates msg, formatting args into it like fmt.Sprintf when given
+func T(msg string, args ...any) string {
+	if translated, ok := catalogs[current][msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// IsYes reports whether answer means yes in English or the selected
+// language
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		return true
+	}
+	for _, word := range yesWords[current] {
+		if answer == word {
+			return true
+		}
+	}
+	return false
+}
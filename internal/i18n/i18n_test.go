@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		lcAll      string
+		lang       string
+		expected   string
+	}{
+		{"configured wins", "ja", "de_DE.UTF-8", "es_ES.UTF-8", "ja"},
+		{"LC_ALL over LANG", "", "de_DE.UTF-8", "es_ES.UTF-8", "de"},
+		{"LANG", "", "", "es_MX.UTF-8", "es"},
+		{"C locale", "", "", "C", "en"},
+		{"unsupported", "", "", "fr_FR.UTF-8", "en"},
+		{"nothing set", "", "", "", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", tt.lang)
+			if result := Detect(tt.configured); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(Default)
+
+	SetLanguage("de")
+	if result := T("Doctor Report"); result != "Diagnosebericht" {
+		t.Errorf("expected %q, got %q", "Diagnosebericht", result)
+	}
+	if result := T("Fix: %s", "gitws fix"); result != "Lösung: gitws fix" {
+		t.Errorf("expected %q, got %q", "Lösung: gitws fix", result)
+	}
+	if result := T("Not in any catalog"); result != "Not in any catalog" {
+		t.Errorf("expected the English text, got %q", result)
+	}
+	if !IsYes("Ja") || !IsYes("y") || IsYes("si") {
+		t.Errorf("expected ja and y to mean yes in German, and si not to")
+	}
+
+	SetLanguage("xx")
+	if Language() != Default {
+		t.Errorf("expected %q, got %q", Default, Language())
+	}
+}
+
+// verbPattern matches fmt verbs
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, catalog := range catalogs {
+		t.Run(lang, func(t *testing.T) {
+			for key := range es {
+				if _, ok := catalog[key]; !ok {
+					t.Errorf("missing translation of %q", key)
+				}
+			}
+			for key, translated := range catalog {
+				if _, ok := es[key]; !ok {
+					t.Errorf("translation of %q is missing from the es catalog", key)
+				}
+				expected := verbPattern.FindAllString(key, -1)
+				result := verbPattern.FindAllString(translated, -1)
+				sort.Strings(expected)
+				sort.Strings(result)
+				if len(expected) != len(result) {
+					t.Errorf("%q: expected verbs %v, got %v", key, expected, result)
+					continue
+				}
+				for i := range expected {
+					if expected[i] != result[i] {
+						t.Errorf("%q: expected verbs %v, got %v", key, expected, result)
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package i18n
+
+// ja is the Japanese catalog
+var ja = map[string]string{
+	// Prompts
+	"%s (y/N): ":      "%s (y/N): ",
+	"Choose [1-%d]: ": "選択してください [1-%d]: ",
+
+	// Summaries
+	"Public Key:":                               "公開鍵:",
+	"Public Key QR Code:":                       "公開鍵の QR コード:",
+	"Next Steps:":                               "次のステップ:",
+	"Adopt summary":                             "取り込みの概要",
+	"✓ Backup of workspace '%s' complete":       "✓ ワークスペース '%s' のバックアップが完了しました",
+	"✓ Exported %d workspace(s)":                "✓ %d 個のワークスペースをエクスポートしました",
+	"✓ Identity '%s' added to workspace '%s'":   "✓ ID '%s' をワークスペース '%s' に追加しました",
+	"✓ Imported %d workspace(s)":                "✓ %d 個のワークスペースをインポートしました",
+	"✓ Logged in successfully":                  "✓ ログインしました",
+	"✓ Repository cloned successfully":          "✓ リポジトリをクローンしました",
+	"✓ Repository created successfully":         "✓ リポジトリを作成しました",
+	"✓ Repository mirrored successfully":        "✓ リポジトリをミラーしました",
+	"✓ SSH keys rotated for %d workspace(s)":    "✓ %d 個のワークスペースの SSH 鍵をローテーションしました",
+	"✓ SSH keys rotated for workspace '%s'":     "✓ ワークスペース '%s' の SSH 鍵をローテーションしました",
+	"✓ Tutorial complete":                       "✓ チュートリアル完了",
+	"✓ Workspace '%s' initialized successfully": "✓ ワークスペース '%s' を初期化しました",
+	"✓ Workspace '%s' renamed to '%s'":          "✓ ワークスペース '%s' の名前を '%s' に変更しました",
+	"✓ Worktree created successfully":           "✓ ワークツリーを作成しました",
+
+	// Summary labels and table headers
+	"Account":               "アカウント",
+	"Adopted":               "取り込み済み",
+	"Already configured":    "設定済み",
+	"Archive":               "アーカイブ",
+	"Backup Location":       "バックアップ先",
+	"Branch":                "ブランチ",
+	"Clone Options":         "クローンオプション",
+	"Default Branch":        "デフォルトブランチ",
+	"Destination":           "保存先",
+	"Email":                 "メール",
+	"Env File":              "環境ファイル",
+	"Extra Host":            "追加ホスト",
+	"Failed":                "失敗",
+	"Force Push":            "強制プッシュ",
+	"Host":                  "ホスト",
+	"Identity":              "ID",
+	"Imported":              "インポート済み",
+	"Isolation":             "分離方式",
+	"Issue Pattern":         "課題番号パターン",
+	"Keys":                  "鍵",
+	"Max File Size":         "最大ファイルサイズ",
+	"Mirror":                "ミラー",
+	"Name":                  "名前",
+	"New Mirrors":           "新規ミラー",
+	"New Private Key":       "新しい秘密鍵",
+	"New Public Key":        "新しい公開鍵",
+	"No matching workspace": "該当ワークスペースなし",
+	"Old Provider Key":      "プロバイダーの旧鍵",
+	"Primary Email":         "プライマリメール",
+	"Property":              "項目",
+	"Protected Branches":    "保護ブランチ",
+	"Provider Key Added":    "プロバイダーに鍵を追加",
+	"Provider Key Removed":  "プロバイダーから鍵を削除",
+	"Pull Strategy":         "プル方式",
+	"Pushed":                "プッシュ済み",
+	"Remotes Rewritten":     "書き換えたリモート",
+	"Repositories found":    "見つかったリポジトリ",
+	"Repository":            "リポジトリ",
+	"Root":                  "ルート",
+	"Rotated keys":          "ローテーションした鍵",
+	"SSH Alias":             "SSH エイリアス",
+	"SSH URL":               "SSH URL",
+	"Signing":               "署名",
+	"Skipped":               "スキップ",
+	"Stored In":             "保存場所",
+	"Updated Mirrors":       "更新したミラー",
+	"Value":                 "値",
+	"Visibility":            "公開範囲",
+	"Web URL":               "Web URL",
+	"Workspace":             "ワークスペース",
+	"Workspaces":            "ワークスペース",
+	"Worktree":              "ワークツリー",
+
+	// Status
+	"Repository Status":    "リポジトリの状態",
+	"Path":                 "パス",
+	"Origin":               "origin",
+	"User Name":            "ユーザー名",
+	"User Email":           "ユーザーのメール",
+	"Signing Key":          "署名鍵",
+	"Guard Hooks":          "ガードフック",
+	"Not set":              "未設定",
+	"unknown":              "不明",
+	"Unmanaged (%s)":       "管理対象外 (%s)",
+	"Disabled":             "無効",
+	"Enabled (%s)":         "有効 (%s)",
+	"Installed":            "インストール済み",
+	"Not installed":        "未インストール",
+	"⚠️  Issues found:":    "⚠️  問題が見つかりました:",
+	"✓ All checks passed!": "✓ すべてのチェックに合格しました",
+	"Run 'gitws doctor' for detailed analysis and fixes.": "詳しい分析と修正方法は 'gitws doctor' を実行してください。",
+
+	// Doctor
+	"Doctor Report": "診断レポート",
+	"Fix: %s":       "修正: %s",
+	"✓ All checks passed! No issues found.": "✓ すべてのチェックに合格しました。問題はありません。",
+	"Suppressed:": "抑制された問題:",
+	"ℹ️  %d issue(s) suppressed by .gitws.yaml or the workspace (--show-suppressed lists them)": "ℹ️  .gitws.yaml またはワークスペースの設定により %d 件の問題を抑制しました (--show-suppressed で表示)",
+	"ℹ️  No earlier doctor run to compare with; showing all issues":                             "ℹ️  比較できる過去の doctor の実行結果がないため、すべての問題を表示します",
+	"ℹ️  Since the run of %s: %d unchanged, %d resolved":                                        "ℹ️  %s の実行以降: 変化なし %d 件、解決済み %d 件",
+	"Feature support:": "機能のサポート状況:",
+	"Check durations:": "チェックの所要時間:",
+	"(timed out)":      "(タイムアウト)",
+
+	// Doctor issues
+	"%s (was %s)": "%s (以前は %s)",
+	"%s declares the repository unmanaged; only machine-wide checks ran":                                                                             "%s がリポジトリを管理対象外としているため、マシン全体のチェックのみ実行しました",
+	"%s disables guard rule %q, which cannot be disabled and stays enforced":                                                                         "%s はガードルール %q を無効にしていますが、このルールは無効にできず適用されたままです",
+	"%s disables unknown guard rule %q":                                                                                                              "%s は不明なガードルール %q を無効にしています",
+	"%s is %q, workspace '%s' policy is %q":                                                                                                          "%s は %q ですが、ワークスペース '%s' のポリシーは %q です",
+	"%s is not trusted or changed since it was, so its settings are ignored; review it, then trust it":                                               "%s は信頼されていないか、信頼後に変更されたため、設定は無視されます。内容を確認してから信頼してください",
+	"%s is on a network filesystem (%s); gitws falls back to in-place writes where rename is unreliable":                                             "%s はネットワークファイルシステム (%s) 上にあります。名前の変更が信頼できない場所では、gitws はその場で書き込みます",
+	"%s is unmanaged but also names a workspace or identity, which are ignored":                                                                      "%s は管理対象外ですが、ワークスペースまたは ID も指定しています。これらは無視されます",
+	"%s keeps %s private; pushes of commits using it are rejected (GH007) if the account blocks pushes that expose its email":                        "%s は %s を非公開にしています。メールを公開する push をアカウントがブロックしている場合、このアドレスのコミットの push は拒否されます (GH007)",
+	"%s pins workspace %q, which is not in config.yaml":                                                                                              "%s はワークスペース %q を指定していますが、config.yaml にありません",
+	"%s resolves to %q from %s (%s), not the workspace's %q: %s. Values in the order git reads them:%s":                                              "%s は %q (%s、%s) になり、ワークスペースの %q ではありません: %s。git が読み込む順の値:%s",
+	"%s selects identity %q, which workspace '%s' does not have":                                                                                     "%s は ID %q を選択していますが、ワークスペース '%s' にはありません",
+	"%s=%q in the environment overrides %s %q from every gitconfig":                                                                                  "環境変数 %s=%q が、すべての gitconfig の %s %q より優先されます",
+	"Add %s.pub to your %s account, then test with: ssh -T %s":                                                                                       "%s.pub を %s のアカウントに追加し、次でテストしてください: ssh -T %s",
+	"Add a signing subkey: gpg --quick-add-key %s ed25519 sign 1y":                                                                                   "署名用サブキーを追加してください: gpg --quick-add-key %s ed25519 sign 1y",
+	"Add and verify %s in the account's email settings, or commit with the noreply address":                                                          "アカウントのメール設定で %s を追加して確認するか、noreply アドレスでコミットしてください",
+	"Add it with 'gitws identity add %s %s --email <email>', or correct %s":                                                                          "'gitws identity add %s %s --email <email>' で追加するか、%s を修正してください",
+	"Add origin remote: git remote add origin <url>":                                                                                                 "origin リモートを追加してください: git remote add origin <url>",
+	"Add to your shell profile: export GPG_TTY=$(tty)":                                                                                               "シェルのプロファイルに追加してください: export GPG_TTY=$(tty)",
+	"Alias %s does not use IdentitiesOnly; ssh may offer agent keys before the workspace key":                                                        "エイリアス %s は IdentitiesOnly を使っていません。ssh がワークスペースの鍵より先にエージェントの鍵を提示する可能性があります",
+	"Alias %s offers %s before the workspace key %s":                                                                                                 "エイリアス %s は %s をワークスペースの鍵 %s より先に提示します",
+	"An earlier 'Host *' block likely sets IdentitiesOnly no; move it below the gws blocks in ~/.ssh/config":                                         "前にある 'Host *' ブロックが IdentitiesOnly no を設定している可能性があります。~/.ssh/config で gws ブロックの後ろに移動してください",
+	"Ask your administrator to fix %s":                                                                                                               "管理者に %s の修正を依頼してください",
+	"Cannot connect to %s through alias %s":                                                                                                          "%s にエイリアス %s 経由で接続できません",
+	"Check '%s' timed out after %s":                                                                                                                  "チェック '%s' は %s でタイムアウトしました",
+	"Check 'git -C %s config --show-origin user.email' and remove the override":                                                                      "'git -C %s config --show-origin user.email' を確認し、上書きを削除してください",
+	"Check for a wrapper or shell profile that resets GIT_AUTHOR_* variables":                                                                        "GIT_AUTHOR_* 変数をリセットするラッパーやシェルのプロファイルがないか確認してください",
+	"Check the includeIf entries in ~/.gitconfig, and that the repository is under %s":                                                               "~/.gitconfig の includeIf エントリと、リポジトリが %s の下にあることを確認してください",
+	"Check your Git signing configuration":                                                                                                           "Git の署名設定を確認してください",
+	"Check your network connection, or skip network checks with --offline":                                                                           "ネットワーク接続を確認するか、--offline でネットワークのチェックを省略してください",
+	"Check your network connection; the last fetched rules still apply":                                                                              "ネットワーク接続を確認してください。最後に取得したルールが引き続き適用されます",
+	"Check ~/.gws/config.yaml":                                                                                                                       "~/.gws/config.yaml を確認してください",
+	"Check ~/.ssh/config with: ssh -G %s":                                                                                                            "次で ~/.ssh/config を確認してください: ssh -G %s",
+	"Commit with the account's noreply address":                                                                                                      "アカウントの noreply アドレスでコミットしてください",
+	"Commit with the noreply address":                                                                                                                "noreply アドレスでコミットしてください",
+	"Commits are signed with gpg, but %s is not installed":                                                                                           "コミットは gpg で署名されますが、%s がインストールされていません",
+	"Commits use %s, which is not an address of your %s account; they will not be attributed to the account and push rules may reject them":          "コミットは %s を使っていますが、これは %s アカウントのアドレスではありません。アカウントに紐付けられず、push ルールで拒否される可能性があります",
+	"Commits use %s, which is not your %s account's noreply address (%s); they will not be attributed to the account and push rules may reject them": "コミットは %s を使っていますが、これは %s アカウントの noreply アドレス (%s) ではありません。アカウントに紐付けられず、push ルールで拒否される可能性があります",
+	"Commits use %s, which your %s account has not verified; push rules may reject them":                                                             "コミットは %s を使っていますが、%s アカウントで未確認です。push ルールで拒否される可能性があります",
+	"Commits would use %s, which workspace '%s' forbids (%s)":                                                                                        "コミットは %s を使うことになりますが、ワークスペース '%s' で禁止されています (%s)",
+	"Configure signing key: git config user.signingkey <key>":                                                                                        "署名鍵を設定してください: git config user.signingkey <key>",
+	"Could not check guard hooks status":                                                                                                             "ガードフックの状態を確認できませんでした",
+	"Could not determine signing configuration":                                                                                                      "署名設定を判定できませんでした",
+	"Could not load workspace configuration":                                                                                                         "ワークスペースの設定を読み込めませんでした",
+	"Create it with 'gitws init %s', or correct %s":                                                                                                  "'gitws init %s' で作成するか、%s を修正してください",
+	"Default branch is '%s', workspace '%s' policy is '%s'":                                                                                          "デフォルトブランチは '%s' ですが、ワークスペース '%s' のポリシーは '%s' です",
+	"Enter the key's passphrase when pinentry asks; if it never appears, restart the agent: gpgconf --kill gpg-agent":                                "pinentry が求めたら鍵のパスフレーズを入力してください。表示されない場合はエージェントを再起動してください: gpgconf --kill gpg-agent",
+	"Export GPG_TTY in your shell profile: export GPG_TTY=$(tty)":                                                                                    "シェルのプロファイルで GPG_TTY を export してください: export GPG_TTY=$(tty)",
+	"Extend it: %s, then upload the public key to your provider again":                                                                               "期限を延長してください: %s。その後、公開鍵をプロバイダーに再度アップロードしてください",
+	"Fix or remove %s": "%s を修正または削除してください",
+	"GPG_TTY is not exported, so a terminal pinentry cannot ask for the passphrase":                    "GPG_TTY が export されていないため、端末の pinentry がパスフレーズを尋ねられません",
+	"Generate a new key (gpg --quick-gen-key), then sign with it: git config user.signingkey <key-id>": "新しい鍵を生成し (gpg --quick-gen-key)、それで署名してください: git config user.signingkey <key-id>",
+	"Git is not installed or not in PATH":                                                              "Git がインストールされていないか、PATH にありません",
+	"Git version: %s":                                                                                  "Git のバージョン: %s",
+	"Global credential.helper '%s' caches a single HTTPS login for %s, shared by workspaces %s":        "グローバルの credential.helper '%s' は %s の HTTPS ログインを 1 つだけ保存し、ワークスペース %s で共有されます",
+	"Global url rewrite maps '%s' to '%s', which uses the default SSH key instead of a workspace key":  "グローバルの url 書き換えが '%s' を '%s' に変換します。これはワークスペースの鍵ではなくデフォルトの SSH 鍵を使います",
+	"Global url rewrite sends '%s' to '%s', bypassing workspace '%s'":                                  "グローバルの url 書き換えが '%s' を '%s' に送り、ワークスペース '%s' を迂回します",
+	"Global user.email is set (%s); repos outside every workspace commit as it without complaint":      "グローバルの user.email が設定されています (%s)。どのワークスペースにも属さないリポジトリは警告なしにこのアドレスでコミットします",
+	"Guard hooks not installed":                                                                        "ガードフックがインストールされていません",
+	"Import the key (gpg --import <file>), or sign with one you have: git config user.signingkey <key-id> (list them with: gpg --list-secret-keys --keyid-format long)": "鍵をインポートするか (gpg --import <file>)、手持ちの鍵で署名してください: git config user.signingkey <key-id> (一覧: gpg --list-secret-keys --keyid-format long)",
+	"Install Git and ensure it's in your PATH": "Git をインストールし、PATH に含まれていることを確認してください",
+	"Install GnuPG (e.g. brew install gnupg, apt install gnupg), or point git at it: git config --global gpg.program <path>": "GnuPG をインストールするか (例: brew install gnupg、apt install gnupg)、git に場所を指定してください: git config --global gpg.program <path>",
+	"Install guard hooks": "ガードフックをインストールしてください",
+	"Install pinentry (e.g. brew install pinentry-mac, apt install pinentry-curses), set pinentry-program in ~/.gnupg/gpg-agent.conf, then: gpgconf --kill gpg-agent":          "pinentry をインストールし (例: brew install pinentry-mac、apt install pinentry-curses)、~/.gnupg/gpg-agent.conf に pinentry-program を設定してから実行してください: gpgconf --kill gpg-agent",
+	"Keep the key on a local disk and register it with 'gitws init <workspace> --force --key-file <path> --key-mode reference', or mount the share with owner-only file modes": "鍵をローカルディスクに置いて 'gitws init <workspace> --force --key-file <path> --key-mode reference' で登録するか、所有者のみのファイルモードで共有をマウントしてください",
+	"Manually verify hooks in %s":                                                                                                  "%s のフックを手動で確認してください",
+	"Move repository to workspace root or update workspace configuration":                                                          "リポジトリをワークスペースのルートに移動するか、ワークスペースの設定を更新してください",
+	"Nested git processes see user.email %q, not %s; git %s ignores GIT_CONFIG_COUNT":                                              "入れ子の git プロセスには user.email が %q と見え、%s ではありません。git %s は GIT_CONFIG_COUNT を無視します",
+	"Nested git processes under 'gitws exec %s' commit as %s":                                                                      "'gitws exec %s' の下の入れ子の git プロセスは %s としてコミットします",
+	"No gpg secret key for %s":                                                                                                     "%s の gpg 秘密鍵がありません",
+	"No host key for %s in known_hosts; the first clone will prompt, or fail in BatchMode":                                         "known_hosts に %s のホスト鍵がありません。最初のクローンで確認を求められるか、BatchMode では失敗します",
+	"No origin remote configured":                                                                                                  "origin リモートが設定されていません",
+	"No user.email configured":                                                                                                     "user.email が設定されていません",
+	"No user.name configured":                                                                                                      "user.name が設定されていません",
+	"Organization policy bans HTTPS remotes; origin is %s":                                                                         "組織のポリシーは HTTPS リモートを禁止しています。origin は %s です",
+	"Organization policy could not be refreshed from %s: %v":                                                                       "組織のポリシーを %s から更新できませんでした: %v",
+	"Organization policy requires guard hooks, which are not installed":                                                            "組織のポリシーはガードフックを必須としていますが、インストールされていません",
+	"Organization policy requires signed commits on %s, but workspace '%s' does not sign":                                          "組織のポリシーは %s で署名付きコミットを必須としていますが、ワークスペース '%s' は署名しません",
+	"Organization policy: %v":                                                                                                      "組織のポリシー: %v",
+	"Prefer a key on a local disk: 'gitws init <workspace> --force --key-file <path> --key-mode reference'":                        "ローカルディスク上の鍵を使うことをお勧めします: 'gitws init <workspace> --force --key-file <path> --key-mode reference'",
+	"Re-run with --verbose to see how long each check takes":                                                                       "--verbose を付けて再実行すると、各チェックの所要時間を確認できます",
+	"Register the key with the account":                                                                                            "鍵をアカウントに登録してください",
+	"Remote URL is not using SSH":                                                                                                  "リモート URL が SSH を使っていません",
+	"Remote URL not using HTTPS, which workspace %s authenticates with (current: %s)":                                              "リモート URL が、ワークスペース %s の認証に使う HTTPS になっていません (現在: %s)",
+	"Remote URL not using gitws alias (current: %s)":                                                                               "リモート URL が gitws のエイリアスを使っていません (現在: %s)",
+	"Remove %q from disable_guards in %s":                                                                                          "%q を disable_guards から削除してください (%s)",
+	"Remove %s from %s, or move it above the gitws includeIf block":                                                                "%s を %s から削除するか、gitws の includeIf ブロックより前に移動してください",
+	"Remove either unmanaged or workspace/identity from %s":                                                                        "unmanaged か workspace/identity のどちらかを削除してください (%s)",
+	"Remove the IdentityFile from the earlier matching block in ~/.ssh/config":                                                     "~/.ssh/config で前にある一致ブロックから IdentityFile を削除してください",
+	"Remove the override from your shell environment or git alias":                                                                 "シェル環境または git エイリアスから上書きを削除してください",
+	"Rename the default branch on the provider, then run 'git remote set-head origin --auto'":                                      "プロバイダーでデフォルトブランチの名前を変更し、'git remote set-head origin --auto' を実行してください",
+	"Repository is also under the root of %s; '%s' wins because its includeIf is read last, but settings only %s sets still apply": "リポジトリは %s のルートの下にもあります。includeIf が最後に読まれるため '%s' が優先されますが、%s だけが設定する項目は引き続き適用されます",
+	"Repository is under nested workspace roots; '%s' wins over the innermost workspace '%s' because its includeIf is read later":  "リポジトリは入れ子になったワークスペースのルートの下にあります。includeIf が後で読まれるため、'%s' が最も内側のワークスペース '%s' より優先されます",
+	"Repository not in workspace root (expected: %s)":                                                                              "リポジトリがワークスペースのルートにありません (想定: %s)",
+	"Reproduce it with: echo test | %s --clearsign -u %s":                                                                          "次で再現できます: echo test | %s --clearsign -u %s",
+	"Restart it: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent":                                                           "再起動してください: gpgconf --kill gpg-agent && gpgconf --launch gpg-agent",
+	"Rewrite remote URL to HTTPS":                                                                                                  "リモート URL を HTTPS に書き換えてください",
+	"Rewrite remote URL to SSH":                                                                                                    "リモート URL を SSH に書き換えてください",
+	"Rewrite remote URL to use workspace alias":                                                                                    "ワークスペースのエイリアスを使うようにリモート URL を書き換えてください",
+	"Rewrite the includeIf block so nested workspaces come last":                                                                   "入れ子のワークスペースが最後になるように includeIf ブロックを書き直してください",
+	"Run 'gitws doctor' again and enter the passphrase; raise pinentry-timeout in ~/.gnupg/gpg-agent.conf if it closes too soon":   "'gitws doctor' を再実行してパスフレーズを入力してください。すぐに閉じる場合は ~/.gnupg/gpg-agent.conf の pinentry-timeout を延ばしてください",
+	"Run 'gitws doctor' again with the right passphrase, or change it: gpg --change-passphrase %s":                                 "正しいパスフレーズで 'gitws doctor' を再実行するか、パスフレーズを変更してください: gpg --change-passphrase %s",
+	"Run 'gitws init' to create workspace or check configuration":                                                                  "'gitws init' でワークスペースを作成するか、設定を確認してください",
+	"SSH alias '%s' not found in workspace configuration":                                                                          "SSH エイリアス '%s' がワークスペースの設定に見つかりません",
+	"SSH key %s is not registered with your %s account; pushes will be rejected unless it is a deploy key":                         "SSH 鍵 %s が %s アカウントに登録されていません。デプロイキーでない限り push は拒否されます",
+	"SSH key %s is on a %s mount that reports mode %04o; ssh will ignore it as unprotected":                                        "SSH 鍵 %s は %s マウント上にあり、モードが %04o と報告されます。ssh は保護されていない鍵として無視します",
+	"SSH key %s is on a network filesystem (%s); ssh stops using it if the mount loosens its permissions":                          "SSH 鍵 %s はネットワークファイルシステム (%s) 上にあります。マウントで権限が緩むと ssh はこの鍵を使わなくなります",
+	"SSH key comment %q does not match workspace identity %q":                                                                      "SSH 鍵のコメント %q がワークスペースの ID %q と一致しません",
+	"SSH key for workspace %s is %d days old (max_key_age %s)":                                                                     "ワークスペース %s の SSH 鍵は作成から %d 日経過しています (max_key_age %s)",
+	"SSH signing key should end with .pub":                                                                                         "SSH 署名鍵は .pub で終わる必要があります",
+	"Set those values explicitly in '%s', move one of the roots so they do not nest, or give one workspace --isolation hasconfig":  "'%s' でそれらの値を明示的に設定するか、入れ子にならないようにルートの一方を移動するか、一方のワークスペースに --isolation hasconfig を指定してください",
+	"Set user.email: git config user.email 'your@email.com'":                                                                       "user.email を設定してください: git config user.email 'your@email.com'",
+	"Set user.name: git config user.name 'Your Name'":                                                                              "user.name を設定してください: git config user.name 'Your Name'",
+	"Signing enabled but no signing key configured":                                                                                "署名が有効ですが、署名鍵が設定されていません",
+	"The test signature failed: wrong passphrase":                                                                                  "テスト署名に失敗しました: パスフレーズが違います",
+	"The test signature was cancelled: pinentry was dismissed or timed out":                                                        "テスト署名がキャンセルされました: pinentry が閉じられたかタイムアウトしました",
+	"Unset %s in your shell profile or direnv setup":                                                                               "シェルのプロファイルまたは direnv の設定で %s を解除してください",
+	"Update signing key to use .pub file":                                                                                          "署名鍵が .pub ファイルを使うように更新してください",
+	"Update the key comment (the key itself is unchanged)":                                                                         "鍵のコメントを更新してください (鍵自体は変わりません)",
+	"Upgrade git and OpenSSH, or set signing: none for the workspace":                                                              "git と OpenSSH を更新するか、ワークスペースに signing: none を設定してください",
+	"Upgrade git to %s or later so signing settings reach submodules under 'gitws exec'":                                           "署名設定が 'gitws exec' の下のサブモジュールに届くよう、git を %s 以降に更新してください",
+	"Upgrade git, then run 'gitws init %s --force' to switch back to remote-based isolation":                                       "git を更新してから 'gitws init %s --force' を実行し、リモートによる分離に戻してください",
+	"Use one of: %s": "次のいずれかを使ってください: %s",
+	"Verify %s in the account's email settings, or commit with the noreply address":                                "アカウントのメール設定で %s を確認するか、noreply アドレスでコミットしてください",
+	"Verify the key with the provider, then: ssh-keygen -R %s && gitws known-hosts %s":                             "プロバイダーで鍵を確認してから実行してください: ssh-keygen -R %s && gitws known-hosts %s",
+	"Workspace '%s': %v; commits fail to sign":                                                                     "ワークスペース '%s': %v。コミットに署名できません",
+	"Workspace '%s': %v; it falls back to directory-based isolation under %s":                                      "ワークスペース '%s': %v。%s の下でディレクトリによる分離に切り替わります",
+	"Worktree %s commits as %q, expected %s":                                                                       "ワークツリー %s は %q としてコミットしますが、想定は %s です",
+	"Worktree %s no longer exists":                                                                                 "ワークツリー %s は存在しません",
+	"git config --global --unset-all %s, then clone with 'gitws clone'":                                            "git config --global --unset-all %s を実行してから 'gitws clone' でクローンしてください",
+	"git config --global credential.useHttpPath true, or use 'gitws fix' to switch remotes to SSH aliases":         "git config --global credential.useHttpPath true を実行するか、'gitws fix' でリモートを SSH エイリアスに切り替えてください",
+	"gpg cannot ask for the passphrase: pinentry has no terminal":                                                  "gpg がパスフレーズを尋ねられません: pinentry に端末がありません",
+	"gpg cannot reach gpg-agent":                                                                                   "gpg が gpg-agent に接続できません",
+	"gpg key %s expired on %s":                                                                                     "gpg 鍵 %s は %s に期限切れになりました",
+	"gpg key %s expires on %s":                                                                                     "gpg 鍵 %s は %s に期限切れになります",
+	"gpg key %s has no key that can sign":                                                                          "gpg 鍵 %s には署名できる鍵がありません",
+	"gpg key %s is revoked":                                                                                        "gpg 鍵 %s は失効しています",
+	"gpg-agent cannot start pinentry to ask for the passphrase":                                                    "gpg-agent がパスフレーズを尋ねるための pinentry を起動できません",
+	"it is passed with -c or GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS, which override every file":                    "-c または GIT_CONFIG_COUNT/GIT_CONFIG_PARAMETERS で渡されており、すべてのファイルより優先されます",
+	"it is read after the workspace gitconfig, and the last value git reads wins":                                  "ワークスペースの gitconfig の後に読まれ、git は最後に読んだ値を使います",
+	"known_hosts key for %s (%s) does not match the published fingerprints":                                        "%s の known_hosts の鍵 (%s) が公開されているフィンガープリントと一致しません",
+	"per-worktree config overrides the repository and global files":                                                "ワークツリーごとの設定がリポジトリとグローバルのファイルより優先されます",
+	"ssh offers %s to bare %s; remotes that bypass the workspace alias authenticate as whichever account owns it":  "ssh は %s をエイリアスなしの %s に提示します。ワークスペースのエイリアスを迂回するリモートは、その鍵を所有するアカウントとして認証されます",
+	"the repository's own config overrides global files, including the workspace gitconfig":                        "リポジトリ自身の設定が、ワークスペースの gitconfig を含むグローバルのファイルより優先されます",
+	"the workspace gitconfig %s is not included for this repository":                                               "ワークスペースの gitconfig %s がこのリポジトリで読み込まれていません",
+	"user.useConfigOnly is not enabled; outside a workspace git guesses an identity instead of refusing to commit": "user.useConfigOnly が有効になっていません。ワークスペースの外では、git はコミットを拒否せずに ID を推測します",
+	"%s has mode %04o; it should be 0700":                                                                          "%s のモードは %04o です。0700 にしてください",
+	"%s is owned by another user; ssh refuses to use it":                                                           "%s は別のユーザーが所有しているため、ssh は使用を拒否します",
+	"SSH key %s does not exist":                                                                                    "SSH 鍵 %s が存在しません",
+	"SSH key %s has mode %04o; ssh ignores keys others can read":                                                   "SSH 鍵 %s のモードは %04o です。ssh は他人が読める鍵を無視します",
+	"SSH key %s is owned by another user; ssh refuses to use it":                                                   "SSH 鍵 %s は別のユーザーが所有しているため、ssh は使用を拒否します",
+	"Public key %s is missing":                                                                                     "公開鍵 %s がありません",
+	"%s does not match its private key (%s); the wrong key may be registered with your provider":                   "%s が秘密鍵 (%s) と一致しません。プロバイダーに間違った鍵が登録されている可能性があります",
+}
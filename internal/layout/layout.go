@@ -0,0 +1,129 @@
+// Package layout expands the clone path templates that decide where a
+// workspace keeps its repositories, e.g. {root}/{host}/{org}/{repo} as
+// ghq does, or a flat {root}/{repo}
+package layout
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Default keeps repositories at <root>/<org>/<repo>
+const Default = "{root}/{org}/{repo}"
+
+// placeholders are the variables a layout can use
+var placeholders = []string{"{root}", "{host}", "{org}", "{repo}"}
+
+// Vars are the values a layout is expanded with
+type Vars struct {
+	Root string
+	Host string
+	Org  string // may hold slashes, e.g. a GitLab subgroup
+	Repo string
+}
+
+// Or returns layout, or Default when it is empty
+func Or(layout string) string {
+	if layout == "" {
+		return Default
+	}
+	return layout
+}
+
+// Validate checks that a layout puts every repository in a directory of
+// its own under the workspace root: it starts with {root}/ and names
+// {repo} in its last segment
+func Validate(layout string) error {
+	if !strings.HasPrefix(layout, "{root}/") {
+		return fmt.Errorf("clone layout %q must start with {root}/", layout)
+	}
+	segments := strings.Split(strings.TrimPrefix(layout, "{root}/"), "/")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("clone layout %q has an empty or relative path segment", layout)
+		}
+		rest := segment
+		for _, p := range placeholders {
+			rest = strings.ReplaceAll(rest, p, "")
+		}
+		if strings.ContainsAny(rest, "{}") {
+			return fmt.Errorf("clone layout %q: unknown placeholder in %q (supported: %s)", layout, segment, strings.Join(placeholders, ", "))
+		}
+	}
+	if strings.Contains(strings.Join(segments, "/"), "{root}") {
+		return fmt.Errorf("clone layout %q uses {root} after the start", layout)
+	}
+	if !strings.Contains(segments[len(segments)-1], "{repo}") {
+		return fmt.Errorf("clone layout %q must name {repo} in its last segment", layout)
+	}
+	return nil
+}
+
+// Expand returns the path of a repository under layout
+func Expand(layout string, v Vars) string {
+	path := strings.NewReplacer(
+		"{root}", filepath.ToSlash(v.Root),
+		"{host}", v.Host,
+		"{org}", v.Org,
+		"{repo}", v.Repo,
+	).Replace(Or(layout))
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+// MaxDepth returns how many directories below the root layout puts a
+// repository, or 0 when there is no limit: an {org} can hold slashes, as
+// GitLab subgroups do
+func MaxDepth(layout string) int {
+	layout = Or(layout)
+	if strings.Contains(layout, "{org}") {
+		return 0
+	}
+	return strings.Count(strings.TrimPrefix(layout, "{root}"), "/")
+}
+
+// Match reports whether path is where layout puts the repository
+// described by v; v.Root is the workspace root
+func Match(layout string, v Vars, path string) bool {
+	return samePath(Expand(layout, v), path)
+}
+
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if filepath.Separator == '\\' {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// Alternatives suggests other paths for a repository whose layout path
+// is taken, e.g. by a repository of the same name in another org: the
+// path qualified with the org, the default layout's path, and the path
+// with a number appended. Paths for which taken reports true are left
+// out.
+func Alternatives(layout string, v Vars, taken func(string) bool) []string {
+	path := Expand(layout, v)
+	var candidates []string
+	if v.Org != "" && !strings.Contains(Or(layout), "{org}") {
+		qualified := strings.ReplaceAll(v.Org, "/", "-") + "-" + v.Repo
+		candidates = append(candidates, filepath.Join(filepath.Dir(path), qualified))
+	}
+	candidates = append(candidates, Expand(Default, v))
+	for n := 2; n < 100; n++ {
+		numbered := fmt.Sprintf("%s-%d", path, n)
+		if !taken(numbered) {
+			candidates = append(candidates, numbered)
+			break
+		}
+	}
+
+	var free []string
+	seen := map[string]bool{path: true}
+	for _, c := range candidates {
+		if !seen[c] && !taken(c) {
+			free = append(free, c)
+		}
+		seen[c] = true
+	}
+	return free
+}
@@ -0,0 +1,105 @@
+package layout
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		layout string
+		valid  bool
+	}{
+		{Default, true},
+		{"{root}/{host}/{org}/{repo}", true},
+		{"{root}/{repo}", true},
+		{"{root}/{org}-{repo}", true},
+		{"{root}/{org}/{repo}.git", true},
+		{"~/src/{org}/{repo}", false},
+		{"{root}/{org}", false},
+		{"{root}/{repo}/src", false},
+		{"{root}//{repo}", false},
+		{"{root}/../{repo}", false},
+		{"{root}/{owner}/{repo}", false},
+		{"{root}/{root}/{repo}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			err := Validate(tt.layout)
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.layout, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected %q to be invalid", tt.layout)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	v := Vars{Root: "/home/me/code", Host: "gitlab.com", Org: "acme/platform", Repo: "api"}
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{"", "/home/me/code/acme/platform/api"},
+		{"{root}/{host}/{org}/{repo}", "/home/me/code/gitlab.com/acme/platform/api"},
+		{"{root}/{repo}", "/home/me/code/api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			if got := Expand(tt.layout, v); got != filepath.FromSlash(tt.expected) {
+				t.Errorf("expected %q, got %q", filepath.FromSlash(tt.expected), got)
+			}
+		})
+	}
+
+	if !Match("{root}/{repo}", v, filepath.FromSlash("/home/me/code/api/")) {
+		t.Errorf("expected a match for the flat layout")
+	}
+	if Match("", v, filepath.FromSlash("/home/me/code/api")) {
+		t.Errorf("expected no match for the default layout")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tests := map[string]int{
+		"":                           0,
+		"{root}/{repo}":              1,
+		"{root}/{host}/{repo}":       2,
+		"{root}/{host}/{org}/{repo}": 0,
+	}
+	for layout, expected := range tests {
+		if got := MaxDepth(layout); got != expected {
+			t.Errorf("%q: expected %d, got %d", layout, expected, got)
+		}
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	v := Vars{Root: "/code", Host: "github.com", Org: "acme/tools", Repo: "api"}
+	taken := map[string]bool{
+		filepath.FromSlash("/code/api"):   true,
+		filepath.FromSlash("/code/api-2"): true,
+	}
+	isTaken := func(path string) bool { return taken[path] }
+
+	got := Alternatives("{root}/{repo}", v, isTaken)
+	expected := []string{
+		filepath.FromSlash("/code/acme-tools-api"),
+		filepath.FromSlash("/code/acme/tools/api"),
+		filepath.FromSlash("/code/api-3"),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// The default layout is already qualified by org
+	taken[filepath.FromSlash("/code/acme/tools/api")] = true
+	got = Alternatives("", v, isTaken)
+	expected = []string{filepath.FromSlash("/code/acme/tools/api-2")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,381 @@
+// Package migrate finds multi-identity setups written by hand, Host
+// aliases in ~/.ssh/config and includeIf sections in ~/.gitconfig, and
+// turns them into workspace proposals
+package migrate
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// managedStart and managedEnd prefix the markers around blocks gitws
+// writes; what is between them is never a hand-written setup
+const (
+	managedStart = "# >>> gws "
+	managedEnd   = "# <<< gws "
+)
+
+// convertibleKeys are the SSH options a managed block reproduces, or that
+// only affect how a key is loaded. A Host block setting anything else
+// would lose it on conversion.
+var convertibleKeys = map[string]bool{
+	"host":                     true,
+	"hostname":                 true,
+	"user":                     true,
+	"identityfile":             true,
+	"identitiesonly":           true,
+	"addkeystoagent":           true,
+	"usekeychain":              true,
+	"preferredauthentications": true,
+}
+
+var includeIfHeader = regexp.MustCompile(`(?i)^\s*\[\s*includeif\s+"([^"]*)"\s*\]\s*$`)
+
+// Lines is a range of lines in a file, End exclusive
+type Lines struct {
+	Start, End int
+}
+
+// SSHHost is a Host block of an SSH config
+type SSHHost struct {
+	Patterns     []string
+	HostName     string
+	User         string
+	IdentityFile string
+	// Extra lists the options a managed block would not keep
+	Extra []string
+	Lines Lines
+}
+
+// Include is an includeIf section of a gitconfig
+type Include struct {
+	Condition string
+	Path      string
+	Lines     Lines
+	// Email and UserName are the identity the included file sets; the
+	// caller reads them
+	Email    string
+	UserName string
+}
+
+// Root returns the directory a gitdir condition covers, or "" for any
+// other condition
+func (i Include) Root() string {
+	kind, pattern, _ := strings.Cut(i.Condition, ":")
+	if kind != "gitdir" && kind != "gitdir/i" {
+		return ""
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		return ""
+	}
+	return strings.TrimSuffix(pattern, "/")
+}
+
+// Proposal is a workspace built from a hand-written setup
+type Proposal struct {
+	Name string
+	// From the Host block
+	Alias        string
+	HostName     string
+	IdentityFile string
+	Host         *SSHHost
+	// From the includeIf section
+	Root     string
+	Email    string
+	UserName string
+	Include  *Include
+}
+
+// Missing returns what the proposal still needs to become a workspace
+func (p Proposal) Missing() []string {
+	var missing []string
+	if p.Email == "" {
+		missing = append(missing, "an email (no includeIf with user.email matches)")
+	}
+	if p.Alias == "" {
+		missing = append(missing, "an SSH host (no Host alias matches)")
+	}
+	if p.Host != nil && len(p.Host.Extra) > 0 {
+		missing = append(missing, "a Host block gitws can reproduce ("+strings.Join(p.Host.Extra, ", ")+" would be dropped)")
+	}
+	return missing
+}
+
+// ParseSSHConfig returns the Host blocks of an SSH config outside the
+// blocks gitws manages
+func ParseSSHConfig(content string) []SSHHost {
+	var hosts []SSHHost
+	var current *SSHHost
+	managed := false
+
+	lines := strings.Split(content, "\n")
+	end := func(i int) {
+		if current != nil {
+			current.Lines.End = i
+			hosts = append(hosts, *current)
+			current = nil
+		}
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, managedStart):
+			end(i)
+			managed = true
+			continue
+		case strings.HasPrefix(trimmed, managedEnd):
+			managed = false
+			continue
+		}
+		if managed || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		key, value := sshOption(trimmed)
+		switch key {
+		case "host":
+			end(i)
+			current = &SSHHost{Patterns: strings.Fields(value), Lines: Lines{Start: i}}
+		case "match":
+			end(i)
+		default:
+			if current == nil {
+				continue
+			}
+			switch key {
+			case "hostname":
+				current.HostName = value
+			case "user":
+				current.User = value
+			case "identityfile":
+				if current.IdentityFile == "" {
+					current.IdentityFile = strings.Trim(value, `"`)
+				} else {
+					current.Extra = append(current.Extra, "a second IdentityFile")
+				}
+			}
+			if !convertibleKeys[key] {
+				current.Extra = append(current.Extra, key)
+			}
+		}
+	}
+	end(len(lines))
+
+	for i := range hosts {
+		hosts[i].Lines = attachComments(lines, hosts[i].Lines)
+	}
+	return hosts
+}
+
+// sshOption splits an SSH config line into its lowercased keyword and
+// value; both "Key value" and "Key=value" are allowed
+func sshOption(line string) (key, value string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return strings.ToLower(line), ""
+	}
+	key = strings.ToLower(line[:i])
+	value = strings.TrimSpace(line[i:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return key, value
+}
+
+// ParseIncludes returns the includeIf sections of a gitconfig outside the
+// blocks gitws manages
+func ParseIncludes(content string) []Include {
+	var includes []Include
+	var current *Include
+	managed := false
+
+	lines := strings.Split(content, "\n")
+	end := func(i int) {
+		if current != nil {
+			current.Lines.End = i
+			if current.Path != "" {
+				includes = append(includes, *current)
+			}
+			current = nil
+		}
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, managedStart):
+			end(i)
+			managed = true
+			continue
+		case strings.HasPrefix(trimmed, managedEnd):
+			managed = false
+			continue
+		}
+		if managed {
+			continue
+		}
+
+		if m := includeIfHeader.FindStringSubmatch(line); m != nil {
+			end(i)
+			current = &Include{Condition: m[1], Lines: Lines{Start: i}}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			end(i)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, "="); ok && strings.EqualFold(strings.TrimSpace(key), "path") {
+			current.Path = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	end(len(lines))
+
+	for i := range includes {
+		includes[i].Lines = attachComments(lines, includes[i].Lines)
+	}
+	return includes
+}
+
+// attachComments moves the blank and comment lines that end a block out
+// of it, and the comments right above it into it: a comment describes
+// the block below it
+func attachComments(lines []string, r Lines) Lines {
+	for r.End > r.Start+1 && isBlankOrComment(lines[r.End-1]) {
+		r.End--
+	}
+	for r.Start > 0 {
+		above := strings.TrimSpace(lines[r.Start-1])
+		if above == "" || !isBlankOrComment(above) || strings.HasPrefix(above, managedStart) || strings.HasPrefix(above, managedEnd) {
+			break
+		}
+		r.Start--
+	}
+	return r
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";")
+}
+
+// Detect pairs identity Host aliases with the includeIf sections setting
+// the identity for the same account. A Host is an identity alias when it
+// names one host, points at another HostName and has its own key. A pair
+// is found by name: the workspace name derived from the alias is the
+// included directory or appears in the included file's name.
+func Detect(hosts []SSHHost, includes []Include) []Proposal {
+	var proposals []Proposal
+	used := make(map[int]bool)
+
+	for i := range hosts {
+		h := &hosts[i]
+		if len(h.Patterns) != 1 || strings.ContainsAny(h.Patterns[0], "*?!") {
+			continue
+		}
+		if h.HostName == "" || strings.EqualFold(h.HostName, h.Patterns[0]) || h.IdentityFile == "" {
+			continue
+		}
+		p := Proposal{
+			Name:         WorkspaceName(h.Patterns[0], h.HostName),
+			Alias:        h.Patterns[0],
+			HostName:     h.HostName,
+			IdentityFile: h.IdentityFile,
+			Host:         h,
+		}
+		for j := range includes {
+			if used[j] || includes[j].Root() == "" || !includeNamed(includes[j], p.Name) {
+				continue
+			}
+			used[j] = true
+			p.setInclude(&includes[j])
+			break
+		}
+		proposals = append(proposals, p)
+	}
+
+	for j := range includes {
+		if used[j] || includes[j].Root() == "" || includes[j].Email == "" {
+			continue
+		}
+		p := Proposal{Name: slug(filepath.Base(filepath.FromSlash(includes[j].Root())))}
+		p.setInclude(&includes[j])
+		proposals = append(proposals, p)
+	}
+
+	sort.SliceStable(proposals, func(a, b int) bool { return proposals[a].Name < proposals[b].Name })
+	return proposals
+}
+
+func (p *Proposal) setInclude(inc *Include) {
+	p.Include = inc
+	p.Root = inc.Root()
+	p.Email = inc.Email
+	p.UserName = inc.UserName
+}
+
+// includeNamed reports whether an include belongs to the workspace name
+func includeNamed(inc Include, name string) bool {
+	if slug(filepath.Base(filepath.FromSlash(inc.Root()))) == name {
+		return true
+	}
+	base := slug(filepath.Base(filepath.FromSlash(inc.Path)))
+	for _, part := range strings.Split(base, "-") {
+		if part == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspaceName derives a workspace name from an identity alias by
+// removing the host it stands for: github-work, github.com-work and
+// work.github.com all name "work"
+func WorkspaceName(alias, hostName string) string {
+	name := strings.ToLower(alias)
+	host := strings.ToLower(hostName)
+	short, _, _ := strings.Cut(host, ".")
+	for _, h := range []string{host, short} {
+		for _, sep := range []string{"-", "_", "."} {
+			if rest, ok := strings.CutPrefix(name, h+sep); ok && rest != "" {
+				return slug(rest)
+			}
+			if rest, ok := strings.CutSuffix(name, sep+h); ok && rest != "" {
+				return slug(rest)
+			}
+		}
+	}
+	return slug(name)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug lowercases s and joins its words with dashes
+func slug(s string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// RemoveLines returns content without the given line ranges. A blank line
+// left doubled by a removal is dropped.
+func RemoveLines(content string, ranges []Lines) string {
+	lines := strings.Split(content, "\n")
+	drop := make(map[int]bool)
+	for _, r := range ranges {
+		for i := r.Start; i < r.End && i < len(lines); i++ {
+			drop[i] = true
+		}
+	}
+
+	var kept []string
+	for i, line := range lines {
+		if drop[i] {
+			continue
+		}
+		if strings.TrimSpace(line) == "" && len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" && i < len(lines)-1 {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sshConfig = `Host *
+  AddKeysToAgent yes
+
+# work account
+Host github-work
+  HostName github.com
+  User git
+  IdentityFile ~/.ssh/id_work
+  IdentitiesOnly yes
+
+Host=gitlab-client
+  HostName=gitlab.com
+  IdentityFile "~/.ssh/id_client"
+  ProxyJump bastion
+
+# >>> gws personal >>> DO NOT EDIT
+Host github.com-personal
+  HostName github.com
+  IdentityFile ~/.ssh/id_ed25519_gws_personal
+# <<< gws personal <<<
+Host bastion
+  HostName 10.0.0.1
+`
+
+func TestParseSSHConfig(t *testing.T) {
+	hosts := ParseSSHConfig(sshConfig)
+	expected := []SSHHost{
+		{Patterns: []string{"*"}, Lines: Lines{0, 2}},
+		{Patterns: []string{"github-work"}, HostName: "github.com", User: "git", IdentityFile: "~/.ssh/id_work", Lines: Lines{3, 9}},
+		{Patterns: []string{"gitlab-client"}, HostName: "gitlab.com", IdentityFile: "~/.ssh/id_client", Extra: []string{"proxyjump"}, Lines: Lines{10, 14}},
+		{Patterns: []string{"bastion"}, HostName: "10.0.0.1", Lines: Lines{20, 22}},
+	}
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, hosts)
+	}
+}
+
+const gitConfig = `[user]
+	name = Me
+[includeIf "gitdir:~/work/"]
+	path = ~/.gitconfig-work
+
+[includeIf "gitdir:~/src/*/"]
+	path = ~/.gitconfig-src
+[includeIf "onbranch:main"]
+	path = main.inc
+# >>> gws includeIf >>> DO NOT EDIT
+[includeIf "gitdir:/home/me/code/personal/"]
+	path = /home/me/.gws/personal/gitconfig
+# <<< gws includeIf <<<
+`
+
+func TestParseIncludes(t *testing.T) {
+	includes := ParseIncludes(gitConfig)
+	expected := []Include{
+		{Condition: "gitdir:~/work/", Path: "~/.gitconfig-work", Lines: Lines{2, 4}},
+		{Condition: "gitdir:~/src/*/", Path: "~/.gitconfig-src", Lines: Lines{5, 7}},
+		{Condition: "onbranch:main", Path: "main.inc", Lines: Lines{7, 9}},
+	}
+	if !reflect.DeepEqual(includes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, includes)
+	}
+
+	roots := []string{"~/work", "", ""}
+	for i, inc := range includes {
+		if inc.Root() != roots[i] {
+			t.Errorf("%s: expected root %q, got %q", inc.Condition, roots[i], inc.Root())
+		}
+	}
+}
+
+func TestWorkspaceName(t *testing.T) {
+	tests := []struct {
+		alias, hostName, expected string
+	}{
+		{"github-work", "github.com", "work"},
+		{"github.com-work", "github.com", "work"},
+		{"work.github.com", "github.com", "work"},
+		{"gh_Client_A", "github.com", "gh-client-a"},
+		{"gitlab-client", "gitlab.example.com", "client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			if got := WorkspaceName(tt.alias, tt.hostName); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	hosts := ParseSSHConfig(sshConfig)
+	includes := []Include{
+		{Condition: "gitdir:~/client/", Path: "~/.gitconfig.d/client", Email: "me@client.com"},
+		{Condition: "gitdir:~/code/", Path: "~/.gitconfig-work", Email: "me@work.com", UserName: "Me Work"},
+		{Condition: "gitdir:~/oss/", Path: "~/.gitconfig-oss", Email: "me@oss.dev"},
+		{Condition: "gitdir:~/nothing/", Path: "~/.gitconfig-nothing"},
+	}
+
+	proposals := Detect(hosts, includes)
+	var got []string
+	for _, p := range proposals {
+		got = append(got, p.Name+" "+p.Alias+" "+p.Root+" "+p.Email)
+	}
+	expected := []string{
+		"client gitlab-client ~/client me@client.com",
+		"oss  ~/oss me@oss.dev",
+		"work github-work ~/code me@work.com",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	missing := map[string]int{"client": 1, "oss": 1, "work": 0}
+	for _, p := range proposals {
+		if len(p.Missing()) != missing[p.Name] {
+			t.Errorf("%s: expected %d missing, got %q", p.Name, missing[p.Name], p.Missing())
+		}
+	}
+}
+
+func TestRemoveLines(t *testing.T) {
+	content := "a\n\nb\nc\n\nd\n"
+	got := RemoveLines(content, []Lines{{2, 4}})
+	if expected := "a\n\nd\n"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
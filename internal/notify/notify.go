@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+// Event types
+const (
+	EventKeyRotated       = "key_rotated"
+	EventPolicyViolation  = "policy_violation"
+	EventWorkspaceExpired = "workspace_expired"
+	EventRepoFixed        = "repo_fixed"
+	EventTest             = "test"
+)
+
+// Sink types
+const (
+	SinkSlack   = "slack"
+	SinkTeams   = "teams"
+	SinkWebhook = "webhook"
+)
+
+// Event is something worth telling someone about
+type Event struct {
+	Type      string    `json:"event"`
+	Workspace string    `json:"workspace,omitempty"`
+	Message   string    `json:"message"`
+	Host      string    `json:"host"`
+	Time      time.Time `json:"time"`
+}
+
+// NewEvent returns an event stamped with the local hostname and time
+func NewEvent(eventType, workspaceName, message string) Event {
+	host, _ := os.Hostname()
+	return Event{
+		Type:      eventType,
+		Workspace: workspaceName,
+		Message:   message,
+		Host:      host,
+		Time:      time.Now().UTC(),
+	}
+}
+
+// Text renders the event as a single chat line
+func (e Event) Text() string {
+	subject := e.Host
+	if e.Workspace != "" {
+		subject = fmt.Sprintf("workspace '%s' on %s", e.Workspace, e.Host)
+	}
+	return fmt.Sprintf("[gitws] %s (%s): %s", e.Type, subject, e.Message)
+}
+
+// Notifier fans events out to the configured sinks
+type Notifier struct {
+	sinks  []config.NotifySink
+	client *http.Client
+}
+
+// New validates the sink configuration and returns a notifier
+func New(sinks []config.NotifySink) (*Notifier, error) {
+	for i, sink := range sinks {
+		switch sink.Type {
+		case SinkSlack, SinkTeams, SinkWebhook:
+		default:
+			return nil, fmt.Errorf("notify sink %d: unknown type: %s (supported: slack, teams, webhook)", i+1, sink.Type)
+		}
+		if sink.URL == "" {
+			return nil, fmt.Errorf("notify sink %d: url is required", i+1)
+		}
+	}
+
+	return &Notifier{
+		sinks:  sinks,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify sends e to every sink subscribed to its type and returns the
+// errors of the sinks that failed
+func (n *Notifier) Notify(e Event) []error {
+	var errs []error
+	for _, sink := range n.sinks {
+		if !subscribed(sink, e.Type) {
+			continue
+		}
+		if err := n.send(sink, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", sink.Type, err))
+		}
+	}
+	return errs
+}
+
+// subscribed reports whether sink wants events of eventType; sinks
+// without an event list get everything, and test events go everywhere
+func subscribed(sink config.NotifySink, eventType string) bool {
+	if len(sink.Events) == 0 || eventType == EventTest {
+		return true
+	}
+	for _, e := range sink.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// payload renders e in the format the sink type expects
+func payload(sinkType string, e Event) interface{} {
+	switch sinkType {
+	case SinkSlack:
+		return map[string]string{"text": e.Text()}
+	case SinkTeams:
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "gitws " + e.Type,
+			"text":     e.Text(),
+		}
+	default:
+		return e
+	}
+}
+
+func (n *Notifier) send(sink config.NotifySink, e Event) error {
+	data, err := json.Marshal(payload(sink.Type, e))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	resp, err := n.client.Post(sink.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to deliver: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
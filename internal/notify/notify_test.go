@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestNotify(t *testing.T) {
+	received := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		received[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	notifier, err := New([]config.NotifySink{
+		{Type: SinkSlack, URL: server.URL + "/slack"},
+		{Type: SinkWebhook, URL: server.URL + "/hook", Events: []string{EventPolicyViolation}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	event := Event{Type: EventKeyRotated, Workspace: "work", Message: "rotated", Host: "laptop"}
+	if errs := notifier.Notify(event); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := "[gitws] key_rotated (workspace 'work' on laptop): rotated"
+	if got := received["/slack"]["text"]; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if _, ok := received["/hook"]; ok {
+		t.Errorf("expected webhook not subscribed to %s to be skipped", EventKeyRotated)
+	}
+}
+
+func TestNotifyFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier, err := New([]config.NotifySink{{Type: SinkTeams, URL: server.URL}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errs := notifier.Notify(NewEvent(EventTest, "", "test"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	expected := "teams sink: webhook returned 403: invalid_token"
+	if errs[0].Error() != expected {
+		t.Errorf("expected %q, got %q", expected, errs[0].Error())
+	}
+}
+
+func TestNewRejectsUnknownSink(t *testing.T) {
+	if _, err := New([]config.NotifySink{{Type: "pager", URL: "https://example.com"}}); err == nil {
+		t.Errorf("expected error but got none")
+	}
+}
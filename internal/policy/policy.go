@@ -0,0 +1,180 @@
+// Package policy reads the organization policy an administrator
+// distributes: company rules every workspace on the machine must follow.
+// Users cannot change it.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Env points gitws at a policy file other than the default
+const Env = "GWS_POLICY"
+
+// CacheName is the file under the state directory holding the last
+// policy fetched from a URL
+const CacheName = "policy-cache.yaml"
+
+// defaultRefresh is how often a policy URL is fetched again
+const defaultRefresh = 24 * time.Hour
+
+// maxSize bounds how much of a fetched policy is read
+const maxSize = 1 << 20
+
+// Policy is the organization policy
+type Policy struct {
+	// URL, when set, is where the rules are fetched from. The fetched
+	// copy is cached and replaces the rules in the local file.
+	URL string `yaml:"url,omitempty"`
+	// Refresh is how often URL is fetched again, e.g. "12h"; default 24h
+	Refresh string `yaml:"refresh,omitempty"`
+
+	// RequireSigning lists hosts whose commits must be signed
+	RequireSigning []string `yaml:"require_signing,omitempty"`
+	// BanHTTPSRemotes forbids http:// and https:// remotes, which keep
+	// credentials in a helper instead of a workspace key
+	BanHTTPSRemotes bool `yaml:"ban_https_remotes,omitempty"`
+	// RequireHooks makes guard hooks mandatory in workspace repositories
+	RequireHooks bool `yaml:"require_hooks,omitempty"`
+
+	// RefreshErr is why URL could not be fetched, when the cached or local
+	// rules are in use instead
+	RefreshErr error `yaml:"-"`
+}
+
+// Path returns the path of the policy file
+func Path() string {
+	if path := os.Getenv(Env); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "gitws", "policy.yaml")
+	}
+	return "/etc/gitws/policy.yaml"
+}
+
+// Parse decodes a policy. Unknown keys are an error, so a rule an older
+// gitws does not know is not silently dropped.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if p.Refresh != "" {
+		if _, err := time.ParseDuration(p.Refresh); err != nil {
+			return nil, fmt.Errorf("invalid refresh %q: %w", p.Refresh, err)
+		}
+	}
+	return &p, nil
+}
+
+// Load reads the policy file, refreshing the rules from its URL into
+// cacheDir when the cached copy is older than the refresh interval. It
+// returns nil when there is no policy.
+func Load(cacheDir string) (*Policy, error) {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	local, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", Path(), err)
+	}
+	if local.URL == "" {
+		return local, nil
+	}
+
+	refresh := defaultRefresh
+	if local.Refresh != "" {
+		refresh, _ = time.ParseDuration(local.Refresh)
+	}
+
+	cachePath := filepath.Join(cacheDir, CacheName)
+	var refreshErr error
+	if info, err := os.Stat(cachePath); err != nil || time.Since(info.ModTime()) > refresh {
+		refreshErr = fetch(local.URL, cachePath)
+	}
+
+	cached, err := os.ReadFile(cachePath)
+	if err != nil {
+		// Never fetched: the local rules are all there is
+		local.RefreshErr = refreshErr
+		return local, nil
+	}
+	remote, err := Parse(cached)
+	if err != nil {
+		local.RefreshErr = fmt.Errorf("cached policy from %s is invalid: %w", local.URL, err)
+		return local, nil
+	}
+	remote.URL, remote.Refresh, remote.RefreshErr = local.URL, local.Refresh, refreshErr
+	return remote, nil
+}
+
+// fetch downloads the policy at url into cachePath, keeping the previous
+// copy when the download or the policy is bad
+func fetch(url, cachePath string) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch policy: %s returned %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+	if err != nil {
+		return fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	if _, err := Parse(data); err != nil {
+		return fmt.Errorf("policy from %s is invalid: %w", url, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return err
+	}
+	return fsutil.AtomicWrite(cachePath, data, 0600)
+}
+
+// RequiresSigning reports whether commits to host must be signed
+func (p *Policy) RequiresSigning(host string) bool {
+	if p == nil {
+		return false
+	}
+	for _, h := range p.RequireSigning {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// BansRemote reports whether the policy forbids remoteURL
+func (p *Policy) BansRemote(remoteURL string) bool {
+	if p == nil || !p.BanHTTPSRemotes {
+		return false
+	}
+	lower := strings.ToLower(remoteURL)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// HooksRequired reports whether guard hooks are mandatory
+func (p *Policy) HooksRequired() bool {
+	return p != nil && p.RequireHooks
+}
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		hasErr bool
+	}{
+		{name: "empty", input: ""},
+		{name: "rules", input: "require_signing: [github.com]\nban_https_remotes: true\nrequire_hooks: true\n"},
+		{name: "unknown key", input: "require_signin: [github.com]\n", hasErr: true},
+		{name: "bad refresh", input: "url: https://example.com/p.yaml\nrefresh: daily\n", hasErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.hasErr {
+				t.Errorf("expected error %v, got %v", tt.hasErr, err)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	p := &Policy{RequireSigning: []string{"github.com"}, BanHTTPSRemotes: true}
+
+	if !p.RequiresSigning("GitHub.com") {
+		t.Errorf("expected signing to be required for GitHub.com")
+	}
+	if p.RequiresSigning("gitlab.com") {
+		t.Errorf("expected signing not to be required for gitlab.com")
+	}
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://github.com/acme/app.git", true},
+		{"HTTP://github.com/acme/app.git", true},
+		{"git@github-com-work:acme/app.git", false},
+		{"ssh://git@github.com/acme/app.git", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if result := p.BansRemote(tt.url); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	var none *Policy
+	if none.RequiresSigning("github.com") || none.BansRemote("https://github.com/a/b") || none.HooksRequired() {
+		t.Errorf("expected no rules without a policy")
+	}
+}
+
+func TestLoadFetchesAndCaches(t *testing.T) {
+	served := "require_hooks: true\n"
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(served))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(local, []byte("url: "+server.URL+"\nban_https_remotes: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(Env, local)
+	cacheDir := filepath.Join(dir, "state")
+
+	p, err := Load(cacheDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !p.RequireHooks || p.BanHTTPSRemotes || p.RefreshErr != nil {
+		t.Errorf("expected the fetched rules, got %+v", p)
+	}
+
+	// A fresh cache is used without fetching
+	served = "require_signing: [github.com]\n"
+	if p, _ = Load(cacheDir); !p.RequireHooks {
+		t.Errorf("expected the cached rules, got %+v", p)
+	}
+
+	// A failed refresh keeps the cached rules and says why
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(cacheDir, CacheName), old, old); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusInternalServerError
+	p, _ = Load(cacheDir)
+	if !p.RequireHooks || p.RefreshErr == nil {
+		t.Errorf("expected the cached rules and a refresh error, got %+v", p)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	t.Setenv(Env, filepath.Join(t.TempDir(), "policy.yaml"))
+
+	p, err := Load(t.TempDir())
+	if err != nil || p != nil {
+		t.Errorf("expected no policy, got %+v, %v", p, err)
+	}
+}
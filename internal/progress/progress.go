@@ -0,0 +1,294 @@
+// Package progress reports the progress of long-running operations such
+// as backups and repository scans. On a terminal it draws a spinner with
+// an overall bar and a bar per running task, redrawn in place; otherwise
+// it logs one line per task so CI logs show what is happening.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/prompt"
+)
+
+// interval is how often the spinner and bars are redrawn. Nothing is
+// drawn before the first tick, so quick operations show no progress.
+const interval = 100 * time.Millisecond
+
+// barWidth is the width of the bars, brackets excluded
+const barWidth = 20
+
+// Progress tracks a batch of tasks. A total of 0 means the number of
+// tasks is not known in advance, as when scanning directories.
+type Progress struct {
+	mu          sync.Mutex
+	out         io.Writer
+	interactive bool
+	ascii       bool
+	title       string
+	total       int
+	done        int
+	tasks       []*Task
+	frame       int
+	lines       int
+	stop        chan struct{}
+	stopped     chan struct{}
+}
+
+// active is the progress being drawn, which output must be printed above
+var (
+	activeMu sync.Mutex
+	active   *Progress
+)
+
+// Task is one unit of work within a Progress
+type Task struct {
+	p       *Progress
+	name    string
+	current int64
+	total   int64
+}
+
+// New starts reporting progress on stderr, animated when stderr is a
+// terminal and output is not plain
+func New(title string, total int) *Progress {
+	return start(os.Stderr, prompt.Animated(), !prompt.Emoji(), title, total)
+}
+
+func start(out io.Writer, interactive, ascii bool, title string, total int) *Progress {
+	p := &Progress{
+		out:         out,
+		interactive: interactive,
+		ascii:       ascii,
+		title:       title,
+		total:       total,
+	}
+	if interactive {
+		p.stop = make(chan struct{})
+		p.stopped = make(chan struct{})
+		activeMu.Lock()
+		active = p
+		activeMu.Unlock()
+		go p.animate()
+	}
+	return p
+}
+
+// Start begins a task. Non-interactive output logs it as [n/total].
+func (p *Progress) Start(name string) *Task {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := &Task{p: p, name: name}
+	p.tasks = append(p.tasks, t)
+	if !p.interactive {
+		position := p.done + len(p.tasks)
+		if p.total > 0 {
+			fmt.Fprintf(p.out, "[%d/%d] %s: %s\n", position, p.total, p.title, name)
+		} else {
+			fmt.Fprintf(p.out, "[%d] %s: %s\n", position, p.title, name)
+		}
+	}
+	return t
+}
+
+// Update records how far the task has got. A total of 0 shows a spinner
+// rather than a bar.
+func (t *Task) Update(current, total int64) {
+	t.p.mu.Lock()
+	defer t.p.mu.Unlock()
+	t.current, t.total = current, total
+}
+
+// Done finishes the task
+func (t *Task) Done() {
+	p := t.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, task := range p.tasks {
+		if task == t {
+			p.tasks = append(p.tasks[:i], p.tasks[i+1:]...)
+			p.done++
+			return
+		}
+	}
+}
+
+// Add counts n finished items that are not tracked as tasks, such as
+// directories scanned
+func (p *Progress) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+}
+
+// Printf prints a line of output to stdout above the bars
+func (p *Progress) Printf(format string, args ...any) {
+	p.fprintf(os.Stdout, format, args...)
+}
+
+func (p *Progress) fprintf(w io.Writer, format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	shown := p.lines > 0
+	p.clear()
+	fmt.Fprintf(w, format, args...)
+	if shown {
+		p.draw()
+	}
+}
+
+// Printf prints to stdout, above the progress being drawn if any. Code
+// that may run while progress is shown prints with it.
+func Printf(format string, args ...any) {
+	Fprintf(os.Stdout, format, args...)
+}
+
+// Fprintf is Printf for other writers, such as stderr
+func Fprintf(w io.Writer, format string, args ...any) {
+	activeMu.Lock()
+	p := active
+	activeMu.Unlock()
+
+	if p == nil {
+		fmt.Fprintf(w, format, args...)
+		return
+	}
+	p.fprintf(w, format, args...)
+}
+
+// Stop ends the progress display, erasing the bars
+func (p *Progress) Stop() {
+	if !p.interactive {
+		return
+	}
+	select {
+	case <-p.stop:
+		return
+	default:
+	}
+	close(p.stop)
+	<-p.stopped
+
+	activeMu.Lock()
+	if active == p {
+		active = nil
+	}
+	activeMu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+func (p *Progress) animate() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame++
+			p.clear()
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// clear erases the lines drawn last. Callers hold p.mu.
+func (p *Progress) clear() {
+	if !p.interactive || p.lines == 0 {
+		return
+	}
+	fmt.Fprintf(p.out, "\x1b[%dA\x1b[J", p.lines)
+	p.lines = 0
+}
+
+// draw renders the spinner and bars. Callers hold p.mu.
+func (p *Progress) draw() {
+	if !p.interactive {
+		return
+	}
+	width := prompt.Width() - 1
+	for _, line := range p.render() {
+		fmt.Fprintln(p.out, truncate(line, width))
+		p.lines++
+	}
+}
+
+// render returns the lines showing the current state
+func (p *Progress) render() []string {
+	spinner := p.spinner()
+	var lines []string
+	if p.total > 0 {
+		lines = append(lines, fmt.Sprintf("%s %s %s %d/%d", spinner, p.title, p.bar(int64(p.done), int64(p.total)), p.done, p.total))
+	} else if p.done > 0 {
+		lines = append(lines, fmt.Sprintf("%s %s (%d)", spinner, p.title, p.done))
+	} else {
+		lines = append(lines, fmt.Sprintf("%s %s", spinner, p.title))
+	}
+	for _, t := range p.tasks {
+		if t.total > 0 {
+			lines = append(lines, fmt.Sprintf("  %s %s %3d%%", t.name, p.bar(t.current, t.total), percent(t.current, t.total)))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s %s", spinner, t.name))
+		}
+	}
+	return lines
+}
+
+// spinner returns the current spinner frame
+func (p *Progress) spinner() string {
+	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	if p.ascii {
+		frames = []string{"|", "/", "-", "\\"}
+	}
+	return frames[p.frame%len(frames)]
+}
+
+// bar draws a bar filled to current/total
+func (p *Progress) bar(current, total int64) string {
+	full, empty := "█", "░"
+	if p.ascii {
+		full, empty = "#", "-"
+	}
+	filled := int(int64(barWidth) * clamp(current, total) / total)
+	return "[" + strings.Repeat(full, filled) + strings.Repeat(empty, barWidth-filled) + "]"
+}
+
+// percent returns current/total as a percentage
+func percent(current, total int64) int {
+	return int(100 * clamp(current, total) / total)
+}
+
+// clamp limits current to 0..total
+func clamp(current, total int64) int64 {
+	if current < 0 {
+		return 0
+	}
+	if current > total {
+		return total
+	}
+	return current
+}
+
+// truncate shortens a line to width characters so it does not wrap,
+// which would throw off the redraw
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+	return string(runes[:width])
+}
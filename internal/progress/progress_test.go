@@ -0,0 +1,68 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineLog(t *testing.T) {
+	var out bytes.Buffer
+	p := start(&out, false, true, "Backing up work", 2)
+
+	a := p.Start("api")
+	a.Update(5, 10)
+	a.Done()
+	p.Start("web").Done()
+	p.Stop()
+
+	expected := "[1/2] Backing up work: api\n[2/2] Backing up work: web\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		done     int
+		task     *Task
+		expected []string
+	}{
+		{"scan", 0, 0, nil, []string{"| Scanning"}},
+		{"scan count", 0, 12, nil, []string{"| Scanning (12)"}},
+		{"overall", 4, 1, nil, []string{"| Scanning [#####---------------] 1/4"}},
+		{"task bar", 4, 2, &Task{name: "api", current: 1, total: 2}, []string{
+			"| Scanning [##########----------] 2/4",
+			"  api [##########----------]  50%",
+		}},
+		{"task spinner", 4, 0, &Task{name: "web"}, []string{
+			"| Scanning [--------------------] 0/4",
+			"  | web",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Progress{ascii: true, title: "Scanning", total: tt.total, done: tt.done}
+			if tt.task != nil {
+				p.tasks = []*Task{tt.task}
+			}
+			result := p.render()
+			if strings.Join(result, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBarClamps(t *testing.T) {
+	p := &Progress{ascii: true}
+	if result := p.bar(15, 10); result != "["+strings.Repeat("#", barWidth)+"]" {
+		t.Errorf("expected a full bar, got %q", result)
+	}
+	if result := p.bar(-1, 10); result != "["+strings.Repeat("-", barWidth)+"]" {
+		t.Errorf("expected an empty bar, got %q", result)
+	}
+}
@@ -0,0 +1,71 @@
+package prompt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitHubActions reports whether gitws runs in a GitHub Actions job
+func GitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// WriteAnnotations writes issues as GitHub Actions workflow commands, so
+// they show up on the run and, when they name a file, next to it in pull
+// requests. Files are made relative to $GITHUB_WORKSPACE, the checkout
+// annotations are resolved against.
+func WriteAnnotations(w io.Writer, issues []Issue) error {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, annotation(issue, workspace)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotation returns the workflow command for issue: its type as the
+// level, its code as the title, and the fix after the message
+func annotation(issue Issue, workspace string) string {
+	level := "warning"
+	switch issue.Type {
+	case "error":
+		level = "error"
+	case "info":
+		level = "notice"
+	}
+
+	var properties []string
+	if file := issue.File; file != "" {
+		if workspace != "" {
+			if rel, err := filepath.Rel(workspace, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		properties = append(properties, "file="+escapeProperty(filepath.ToSlash(file)))
+	}
+	title := issue.Code
+	if title == "" {
+		title = "gitws"
+	}
+	properties = append(properties, "title="+escapeProperty(title))
+
+	message := issue.Message
+	if fix := issue.FixText(); fix != "" {
+		message += "\nFix: " + fix
+	}
+	return fmt.Sprintf("::%s %s::%s", level, strings.Join(properties, ","), escapeData(message))
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
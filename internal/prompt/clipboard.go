@@ -0,0 +1,99 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"github.com/gitworkspaces/gitws/internal/qr"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// clipboardCommands returns the programs that can set the clipboard on
+// goos, in order of preference
+func clipboardCommands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+
+	var commands [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	if getenv("DISPLAY") != "" {
+		commands = append(commands,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"})
+	}
+	if getenv("WSL_DISTRO_NAME") != "" {
+		commands = append(commands, []string{"clip.exe"})
+	}
+	return commands
+}
+
+// Copy puts text on the clipboard and returns how: with the first
+// clipboard program found or, failing that and on a terminal, with the
+// OSC 52 escape sequence, which most terminal emulators honor even over
+// SSH
+func Copy(text string) (string, error) {
+	for _, args := range clipboardCommands(runtime.GOOS, os.Getenv) {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("failed to copy with %s: %w", args[0], err)
+		}
+		return args[0], nil
+	}
+
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		termenv.Copy(text)
+		return "OSC 52", nil
+	}
+	return "", fmt.Errorf("no clipboard program found (pbcopy, wl-copy, xclip, xsel or clip)")
+}
+
+// QRCode draws text as a QR code: with block characters, or with ASCII
+// when emoji are off
+func QRCode(text string) (string, error) {
+	code, err := qr.Encode(text)
+	if err != nil {
+		return "", err
+	}
+	if noEmoji {
+		return code.ASCII(), nil
+	}
+	return code.Blocks(), nil
+}
+
+// showPublicKeyExtras draws the public key of a summary as a QR code and
+// copies it to the clipboard, as asked. Neither failing fails the
+// command: the key was printed anyway.
+func showPublicKeyExtras(data SummaryData) {
+	if data.PublicKey == "" {
+		return
+	}
+	if data.QR {
+		if code, err := QRCode(data.PublicKey); err != nil {
+			fmt.Fprintf(os.Stderr, Text("⚠️  Could not draw the public key as a QR code: %v\n"), err)
+		} else {
+			fmt.Printf("\n%s\n%s", i18n.T("Public Key QR Code:"), code)
+		}
+	}
+	if data.CopyPublicKey {
+		if method, err := Copy(data.PublicKey); err != nil {
+			fmt.Fprintf(os.Stderr, Text("⚠️  Could not copy the public key: %v\n"), err)
+		} else {
+			fmt.Printf(Text("✓ Public key copied to the clipboard (%s)\n"), method)
+		}
+	}
+}
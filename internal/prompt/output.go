@@ -0,0 +1,160 @@
+package prompt
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Output settings, set once from the command line and config.yaml
+var (
+	plainOutput bool
+	noEmoji     bool
+)
+
+// asciiBorder replaces the rounded box border when emoji are off, since
+// terminals that garble emoji usually garble box drawing too
+var asciiBorder = lipgloss.Border{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+}
+
+// asciiReplacer turns the emoji and symbols gitws prints into ASCII.
+// Sequences with a variation selector come before their bare symbol.
+var asciiReplacer = strings.NewReplacer(
+	"✓", "[ok]",
+	"❌", "[error]",
+	"⚠️", "[warn]", "⚠", "[warn]",
+	"ℹ️", "[info]", "ℹ", "[info]",
+	"→", "->",
+	"•", "*",
+	"─", "-",
+	"👉", ">",
+	"📁", "*", "📦", "*", "🔗", "*", "🌐", "*", "📍", "*", "🌿", "*",
+	"🔑", "*", "📧", "*", "👤", "*", "➕", "+", "➖", "-", "🔄", "*",
+	"🛡️", "*", "🛡", "*", "🔐", "*", "💾", "*", "⚙️", "*", "⚙", "*",
+	"⏭️", "*", "⏭", "*", "✍️", "*", "✍", "*", "⬇️", "*", "⬇", "*",
+	"🌱", "*", "🚀", "*", "🔁", "*", "🔒", "*", "🔓", "*", "🔧", "*",
+	"⌨️", "*", "⌨", "*", "↩️", "*", "↩", "*",
+	"️", "",
+)
+
+// Configure sets how output looks. noColor drops colors (as does NO_COLOR,
+// see https://no-color.org), noEmoji prints ASCII instead of emoji, and
+// plain does both and prints text instead of boxes, as in CI.
+func Configure(noColor, noEmojiOutput, plain bool) {
+	plainOutput = plain
+	noEmoji = noEmojiOutput || plain
+	if noColor || plain || os.Getenv("NO_COLOR") != "" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	if noEmoji {
+		boxStyle = boxStyle.Border(asciiBorder)
+	}
+}
+
+// Text returns s as it should be printed: with emoji replaced by ASCII
+// when they are off
+func Text(s string) string {
+	if !noEmoji {
+		return s
+	}
+	return asciiReplacer.Replace(s)
+}
+
+// isPlain reports whether output is plain text rather than styled boxes:
+// when asked for, in CI, and when stdout is not a terminal, so pipes and
+// redirects get text they can grep
+func isPlain() bool {
+	return plainOutput || os.Getenv("CI") != "" || !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Emoji reports whether emoji and other symbols outside ASCII are printed
+func Emoji() bool {
+	return !noEmoji
+}
+
+// Animated reports whether output may be redrawn in place, as progress
+// bars are: stderr is a terminal and output is not plain
+func Animated() bool {
+	return !plainOutput && os.Getenv("CI") == "" && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// defaultWidth is used when the terminal width cannot be determined
+const defaultWidth = 80
+
+// Width returns the width of the terminal: $COLUMNS when set, else the
+// size of stdout, else 80
+func Width() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return defaultWidth
+}
+
+// renderBox draws content in the box, wrapping lines that would not fit
+// the terminal. Long unbroken values such as keys and paths are split.
+func renderBox(content string) string {
+	frame := boxStyle.GetHorizontalFrameSize()
+	if max := Width() - frame; max > 0 && lipgloss.Width(content) > max {
+		content = lipgloss.NewStyle().Width(max).Render(content)
+	}
+	return boxStyle.Render(content)
+}
+
+// minValueWidth is the narrowest column a value is wrapped into
+const minValueWidth = 20
+
+// hangingIndent joins prefix and value, wrapping a value too long for the
+// box so its continuation lines stay aligned after the prefix
+func hangingIndent(prefix, value string) string {
+	width := Width() - boxStyle.GetHorizontalFrameSize() - lipgloss.Width(prefix)
+	if width < minValueWidth || lipgloss.Width(value) <= width {
+		return prefix + value
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, prefix, lipgloss.NewStyle().Width(width).Render(value))
+}
+
+// Theme holds the colors of styled output, each an ANSI color number or
+// a hex color. Empty fields keep the default.
+type Theme struct {
+	Title   string
+	Success string
+	Warning string
+	Error   string
+	Info    string
+	Border  string
+	Muted   string
+}
+
+// SetTheme overrides the colors of styled output
+func SetTheme(t Theme) {
+	if t.Title != "" {
+		titleStyle = titleStyle.Foreground(lipgloss.Color(t.Title))
+	}
+	if t.Success != "" {
+		successStyle = successStyle.Foreground(lipgloss.Color(t.Success))
+	}
+	if t.Warning != "" {
+		warningStyle = warningStyle.Foreground(lipgloss.Color(t.Warning))
+	}
+	if t.Error != "" {
+		errorStyle = errorStyle.Foreground(lipgloss.Color(t.Error))
+	}
+	if t.Info != "" {
+		infoStyle = infoStyle.Foreground(lipgloss.Color(t.Info))
+	}
+	if t.Border != "" {
+		boxStyle = boxStyle.BorderForeground(lipgloss.Color(t.Border))
+	}
+	if t.Muted != "" {
+		keyStyle = keyStyle.Foreground(lipgloss.Color(t.Muted))
+	}
+}
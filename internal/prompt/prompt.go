@@ -1,18 +1,58 @@
 package prompt
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"golang.org/x/term"
 )
 
 // Issue represents a doctor check issue
 type Issue struct {
-	Type    string // "error", "warning", "info"
-	Message string
-	Fix     string
+	Code      string // Stable identifier such as GWS-REMOTE-003, for suppression and tooling
+	Type      string // "error", "warning", "info"
+	Message   string
+	Fix       string
+	Workspace string   // Workspace the issue concerns, if resolved
+	Path      string   // Repository the issue concerns, if any
+	File      string   // File the issue is in, if any, for annotations to point at
+	Command   []string // Exact command that fixes the issue, built from the above
+}
+
+// FixText returns the copy-pasteable fix for an issue: the exact command
+// when one is known, otherwise the advice string
+func (i Issue) FixText() string {
+	if len(i.Command) > 0 {
+		return ShellJoin(i.Command)
+	}
+	return i.Fix
+}
+
+// codeSuffix is how an issue's code trails its message in reports
+func (i Issue) codeSuffix() string {
+	if i.Code == "" {
+		return ""
+	}
+	return " [" + i.Code + "]"
+}
+
+// ShellJoin joins args into a command line, quoting arguments the shell
+// would otherwise split or expand
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@+,") == "" {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
 }
 
 // SummaryData represents data for summary display
@@ -21,6 +61,11 @@ type SummaryData struct {
 	Items     []SummaryItem
 	PublicKey string
 	NextSteps []string
+
+	// QR also draws PublicKey as a QR code, and CopyPublicKey puts it on
+	// the clipboard
+	QR            bool
+	CopyPublicKey bool
 }
 
 // SummaryItem represents an item in the summary
@@ -33,199 +78,159 @@ type SummaryItem struct {
 // Confirm prompts for yes/no confirmation
 func Confirm(msg string) (bool, error) {
 	// Check for non-interactive environment
-	if os.Getenv("CI") != "" || os.Getenv("NO_COLOR") != "" {
+	if os.Getenv("CI") != "" {
 		// In non-interactive mode, default to yes
 		return true, nil
 	}
 
 	// Simple text-based confirmation for now
-	fmt.Printf("%s (y/N): ", msg)
+	fmt.Print(i18n.T("%s (y/N): ", msg))
 	var response string
 	fmt.Scanln(&response)
-	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes", nil
+	return i18n.IsYes(response), nil
 }
 
-// ShowSummary displays a styled summary
-func ShowSummary(data SummaryData) error {
-	// Check for non-interactive environment
-	if os.Getenv("CI") != "" || os.Getenv("NO_COLOR") != "" {
-		// Plain text output
-		fmt.Printf("\n%s\n", data.Title)
-		fmt.Println(strings.Repeat("=", len(data.Title)))
-		for _, item := range data.Items {
-			fmt.Printf("%s: %s\n", item.Label, item.Value)
-		}
-		if data.PublicKey != "" {
-			fmt.Printf("\nPublic Key:\n%s\n", data.PublicKey)
-		}
-		if len(data.NextSteps) > 0 {
-			fmt.Println("\nNext Steps:")
-			for i, step := range data.NextSteps {
-				fmt.Printf("%d. %s\n", i+1, step)
-			}
-		}
-		return nil
+// ConfirmInteractive is Confirm for decisions with no safe default, such
+// as trusting a key: like Choose, it fails when not running interactively
+// instead of assuming yes
+func ConfirmInteractive(msg string) (bool, error) {
+	if os.Getenv("CI") != "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("cannot prompt for confirmation: not running interactively")
 	}
+	return Confirm(msg)
+}
 
-	// Styled output with Lip Gloss
-	var content strings.Builder
+// Choose asks the user to pick one of options and returns its index.
+// Unlike Confirm there is no safe default, so it fails when not running
+// interactively.
+func Choose(msg string, options []string) (int, error) {
+	if os.Getenv("CI") != "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return -1, fmt.Errorf("cannot prompt for a choice: not running interactively")
+	}
 
-	// Title
-	content.WriteString(titleStyle.Render(data.Title))
-	content.WriteString("\n\n")
+	fmt.Println(msg)
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n", i+1, option)
+	}
+	fmt.Print(i18n.T("Choose [1-%d]: ", len(options)))
+	var response string
+	fmt.Scanln(&response)
+	n, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || n < 1 || n > len(options) {
+		return -1, fmt.Errorf("invalid choice %q", response)
+	}
+	return n - 1, nil
+}
 
-	// Items
-	for _, item := range data.Items {
-		icon := "✓"
-		if item.Icon != "" {
-			icon = item.Icon
-		}
-		content.WriteString(fmt.Sprintf("%s %s: %s\n",
-			successStyle.Render(icon),
-			keyStyle.Render(item.Label),
-			item.Value))
+// Input asks for a line of text. Like Choose, it fails when not running
+// interactively.
+func Input(msg string) (string, error) {
+	if os.Getenv("CI") != "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("cannot prompt for input: not running interactively")
 	}
 
-	// Public key
-	if data.PublicKey != "" {
-		content.WriteString("\n")
-		content.WriteString(keyStyle.Render("Public Key:"))
-		content.WriteString("\n")
-		content.WriteString(data.PublicKey)
-		content.WriteString("\n")
+	fmt.Print(i18n.T("%s: ", msg))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
 	}
+	return strings.TrimSpace(line), nil
+}
 
-	// Next steps
-	if len(data.NextSteps) > 0 {
-		content.WriteString("\n")
-		content.WriteString(keyStyle.Render("Next Steps:"))
-		content.WriteString("\n")
-		for i, step := range data.NextSteps {
-			content.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
-		}
+// Passphrase prompts for a secret without echoing it. GWS_PASSPHRASE is
+// used instead when set, for scripted use.
+func Passphrase(msg string) (string, error) {
+	if pass := os.Getenv("GWS_PASSPHRASE"); pass != "" {
+		return pass, nil
 	}
 
-	fmt.Println(boxStyle.Render(content.String()))
-	return nil
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot prompt for passphrase: stdin is not a terminal (set GWS_PASSPHRASE)")
+	}
+
+	fmt.Printf("%s: ", msg)
+	data, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(data), nil
 }
 
-// ShowDoctorReport displays a styled doctor report
-func ShowDoctorReport(issues []Issue) error {
-	// Check for non-interactive environment
-	if os.Getenv("CI") != "" || os.Getenv("NO_COLOR") != "" {
-		// Plain text output
-		fmt.Println("\nDoctor Report")
-		fmt.Println(strings.Repeat("=", 12))
-		for _, issue := range issues {
-			icon := "ℹ️"
-			switch issue.Type {
-			case "error":
-				icon = "❌"
-			case "warning":
-				icon = "⚠️"
-			case "info":
-				icon = "ℹ️"
-			}
-			fmt.Printf("%s %s\n", icon, issue.Message)
-			if issue.Fix != "" {
-				fmt.Printf("   Fix: %s\n", issue.Fix)
-			}
+// Secret reads a secret such as an API token without echoing it. When
+// stdin is not a terminal the first line of stdin is used, so tokens can
+// be piped in from a password manager.
+func Secret(msg string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read secret from stdin: %w", err)
 		}
-		return nil
+		return strings.TrimSpace(line), nil
 	}
 
-	// Styled output with Lip Gloss
-	var content strings.Builder
-
-	content.WriteString(titleStyle.Render("Doctor Report"))
-	content.WriteString("\n\n")
-
-	if len(issues) == 0 {
-		content.WriteString(successStyle.Render("✓ All checks passed! No issues found."))
-	} else {
-		for _, issue := range issues {
-			var icon, style string
-			switch issue.Type {
-			case "error":
-				icon = "❌"
-				style = errorStyle.Render(issue.Message)
-			case "warning":
-				icon = "⚠️"
-				style = warningStyle.Render(issue.Message)
-			case "info":
-				icon = "ℹ️"
-				style = infoStyle.Render(issue.Message)
-			default:
-				icon = "ℹ️"
-				style = issue.Message
-			}
-
-			content.WriteString(fmt.Sprintf("%s %s\n", icon, style))
-			if issue.Fix != "" {
-				content.WriteString(fmt.Sprintf("   %s\n", keyStyle.Render("Fix: "+issue.Fix)))
-			}
-			content.WriteString("\n")
-		}
+	fmt.Printf("%s: ", msg)
+	data, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
 	}
+	return strings.TrimSpace(string(data)), nil
+}
 
-	fmt.Println(boxStyle.Render(content.String()))
+// ShowSummary displays a summary in the selected format. Titles and
+// labels are translated, except as JSON or YAML; titles with arguments
+// must be translated by the caller.
+func ShowSummary(data SummaryData) error {
+	data.Title = Text(i18n.T(data.Title))
+	if err := renderer().Summary(os.Stdout, data); err != nil {
+		return err
+	}
+	if !Structured() {
+		showPublicKeyExtras(data)
+	}
 	return nil
 }
 
-// ShowStatusTable displays a status table
+// ShowDoctorReport displays a doctor report in the selected format
+func ShowDoctorReport(issues []Issue) error {
+	return renderer().DoctorReport(os.Stdout, issues)
+}
+
+// ShowStatusTable displays a status table in the selected format.
+// Headers are translated, except as JSON or YAML keys.
 func ShowStatusTable(headers []string, rows [][]string) error {
+	return renderer().StatusTable(os.Stdout, headers, rows)
+}
+
+// ShowDiff prints a unified diff, colorizing added and removed lines
+func ShowDiff(diff string) error {
 	// Check for non-interactive environment
-	if os.Getenv("CI") != "" || os.Getenv("NO_COLOR") != "" {
-		// Plain text output
-		for i, header := range headers {
-			if i > 0 {
-				fmt.Print(" | ")
-			}
-			fmt.Print(header)
-		}
-		fmt.Println()
-		fmt.Println(strings.Repeat("-", len(strings.Join(headers, " | "))))
-		for _, row := range rows {
-			for i, cell := range row {
-				if i > 0 {
-					fmt.Print(" | ")
-				}
-				fmt.Print(cell)
-			}
-			fmt.Println()
-		}
+	if isPlain() {
+		fmt.Print(diff)
 		return nil
 	}
 
-	// Styled output with Lip Gloss
-	var content strings.Builder
-
-	content.WriteString(titleStyle.Render("Repository Status"))
-	content.WriteString("\n\n")
-
-	// Headers
-	for i, header := range headers {
-		if i > 0 {
-			content.WriteString(" | ")
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
 		}
-		content.WriteString(keyStyle.Render(header))
-	}
-	content.WriteString("\n")
-	content.WriteString(strings.Repeat("-", len(strings.Join(headers, " | "))))
-	content.WriteString("\n")
-
-	// Rows
-	for _, row := range rows {
-		for i, cell := range row {
-			if i > 0 {
-				content.WriteString(" | ")
-			}
-			content.WriteString(cell)
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "---"), strings.HasPrefix(text, "+++"):
+			fmt.Println(keyStyle.Render(text))
+		case strings.HasPrefix(text, "@@"):
+			fmt.Println(infoStyle.Render(text))
+		case strings.HasPrefix(text, "-"):
+			fmt.Println(errorStyle.Render(text))
+		case strings.HasPrefix(text, "+"):
+			fmt.Println(successStyle.Render(text))
+		default:
+			fmt.Println(text)
 		}
-		content.WriteString("\n")
 	}
-
-	fmt.Println(boxStyle.Render(content.String()))
 	return nil
 }
 
@@ -0,0 +1,261 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellJoin(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected string
+	}{
+		{"plain", []string{"gitws", "fix", "--workspace", "work", "/home/me/code/repo"}, "gitws fix --workspace work /home/me/code/repo"},
+		{"space", []string{"gitws", "fix", "/home/me/my repo"}, "gitws fix '/home/me/my repo'"},
+		{"quote", []string{"echo", "it's"}, `echo 'it'\''s'`},
+		{"empty", []string{"git", "config", ""}, "git config ''"},
+		{"expansion", []string{"echo", "$HOME"}, "echo '$HOME'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ShellJoin(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	defer func() { noEmoji = false }()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"success", "✓ Installed hooks\n", "[ok] Installed hooks\n"},
+		{"warning", "⚠️  Key is old", "[warn]  Key is old"},
+		{"bare warning", "⚠ Key is old", "[warn] Key is old"},
+		{"error", "❌ Failed", "[error] Failed"},
+		{"info", "ℹ️  Nothing to do", "[info]  Nothing to do"},
+		{"arrow", "origin: a → b", "origin: a -> b"},
+		{"decoration", "🛡️ Guards", "* Guards"},
+		{"plain", "nothing to replace", "nothing to replace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noEmoji = false
+			if result := Text(tt.input); result != tt.input {
+				t.Errorf("expected %q unchanged, got %q", tt.input, result)
+			}
+			noEmoji = true
+			if result := Text(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHangingIndent(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+
+	result := hangingIndent("Key: ", "short")
+	if result != "Key: short" {
+		t.Errorf("expected %q, got %q", "Key: short", result)
+	}
+
+	long := "/home/me/code/a/very/long/path/to/a/repository"
+	lines := strings.Split(hangingIndent("Key: ", long), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected the value to wrap, got %q", lines)
+	}
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, "     ") {
+			t.Errorf("expected continuation line %q to be indented", line)
+		}
+	}
+}
+
+func TestClipboardCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		env      map[string]string
+		expected []string
+	}{
+		{"macOS", "darwin", nil, []string{"pbcopy"}},
+		{"Windows", "windows", nil, []string{"clip"}},
+		{"Wayland first", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xclip", "xsel"}},
+		{"X11", "linux", map[string]string{"DISPLAY": ":0"}, []string{"xclip", "xsel"}},
+		{"WSL", "linux", map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, []string{"clip.exe"}},
+		{"headless", "linux", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []string
+			for _, args := range clipboardCommands(tt.goos, func(key string) string { return tt.env[key] }) {
+				result = append(result, args[0])
+			}
+			if strings.Join(result, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMarkdownSummary(t *testing.T) {
+	var b strings.Builder
+	err := markdownRenderer{}.Summary(&b, SummaryData{
+		Title:     "✓ Workspace 'work' initialized successfully",
+		Items:     []SummaryItem{{Label: "SSH Alias", Value: "github-work", Icon: "🔑"}, {Label: "Root", Value: "a|<b>"}},
+		PublicKey: "ssh-ed25519 AAAA me@work.com\n",
+		NextSteps: []string{"Add the public key"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "## ✓ Workspace 'work' initialized successfully\n" +
+		"\n| Property | Value |\n|---|---|\n" +
+		"| SSH Alias | github-work |\n" +
+		"| Root | a\\|\\<b\\> |\n" +
+		"\n**Public Key:**\n\n```text\nssh-ed25519 AAAA me@work.com\n```\n" +
+		"\n**Next Steps:**\n\n1. Add the public key\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestMarkdownDoctorReport(t *testing.T) {
+	var b strings.Builder
+	err := markdownRenderer{}.DoctorReport(&b, []Issue{
+		{Code: "GWS-ID-001", Type: "error", Message: "Wrong email", Command: []string{"gitws", "fix"}},
+		{Code: "GWS-KEY-002", Type: "warning", Message: "Old key", Fix: "Rotate it"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "## Doctor Report\n\n" +
+		"| | Code | Issue | Fix |\n|---|---|---|---|\n" +
+		"| ❌ | GWS-ID-001 | Wrong email | `gitws fix` |\n" +
+		"| ⚠️ | GWS-KEY-002 | Old key | Rotate it |\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	defer func() { format = FormatText }()
+
+	if err := SetFormat("markdown"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := SetFormat("html"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if format != FormatMarkdown {
+		t.Errorf("expected the format to stay %q, got %q", FormatMarkdown, format)
+	}
+}
+
+func TestTableKey(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"Workspace", "workspace"},
+		{"User Email", "user_email"},
+		{"Ahead/Behind", "ahead_behind"},
+		{" Key (SSH) ", "key_ssh"},
+	}
+	for _, tt := range tests {
+		if result := tableKey(tt.header); result != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestStructuredStatusTable(t *testing.T) {
+	headers := []string{"Workspace", "User Email"}
+	rows := [][]string{{"work", "me@work.com"}}
+
+	var b strings.Builder
+	if err := renderers[FormatJSON].StatusTable(&b, headers, rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "[\n  {\n    \"user_email\": \"me@work.com\",\n    \"workspace\": \"work\"\n  }\n]\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	if err := renderers[FormatYAML].StatusTable(&b, headers, rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = "- user_email: me@work.com\n  workspace: work\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestMarkdownStatusTable(t *testing.T) {
+	var b strings.Builder
+	err := markdownRenderer{}.StatusTable(&b, []string{"Path", "Status"}, [][]string{{"/code/a|b", "✓"}, {"/code/c"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "| Path | Status |\n|---|---|\n| /code/a\\|b | ✓ |\n| /code/c |  |\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestAnnotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		issue    Issue
+		expected string
+	}{
+		{
+			name:     "error with file",
+			issue:    Issue{Code: "GWS-REPO-004", Type: "error", Message: "Unknown workspace", Fix: "Correct it", File: "/work/repo/.gitws.yaml"},
+			expected: "::error file=repo/.gitws.yaml,title=GWS-REPO-004::Unknown workspace%0AFix: Correct it",
+		},
+		{
+			name:     "warning with command",
+			issue:    Issue{Code: "GWS-HOOKS-002", Type: "warning", Message: "Hooks missing", Command: []string{"gitws", "fix"}},
+			expected: "::warning title=GWS-HOOKS-002::Hooks missing%0AFix: gitws fix",
+		},
+		{
+			name:     "info without code",
+			issue:    Issue{Type: "info", Message: "100% done"},
+			expected: "::notice title=gitws::100%25 done",
+		},
+		{
+			name:     "file outside the workspace",
+			issue:    Issue{Type: "error", Message: "m", File: "/etc/a,b:c"},
+			expected: "::error file=/etc/a%2Cb%3Ac,title=gitws::m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotation(tt.issue, "/work"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConfirmInteractiveUnderCI(t *testing.T) {
+	t.Setenv("CI", "1")
+
+	if confirmed, err := Confirm("Proceed?"); err != nil || !confirmed {
+		t.Errorf("expected Confirm to assume yes under CI, got %v, %v", confirmed, err)
+	}
+	if confirmed, err := ConfirmInteractive("Trust these keys?"); err == nil || confirmed {
+		t.Errorf("expected ConfirmInteractive to fail under CI, got %v, %v", confirmed, err)
+	}
+}
@@ -0,0 +1,494 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of summaries, reports and tables
+const (
+	FormatText     = "text"  // styled on a terminal, plain otherwise
+	FormatPlain    = "plain" // plain text, as with --plain
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+)
+
+// Renderer draws summaries, doctor reports and status tables in one
+// output format
+type Renderer interface {
+	Summary(w io.Writer, data SummaryData) error
+	DoctorReport(w io.Writer, issues []Issue) error
+	StatusTable(w io.Writer, headers []string, rows [][]string) error
+}
+
+var renderers = map[string]Renderer{
+	FormatText:     textRenderer{},
+	FormatPlain:    textRenderer{},
+	FormatJSON:     structuredRenderer{encode: encodeJSON},
+	FormatYAML:     structuredRenderer{encode: encodeYAML},
+	FormatMarkdown: markdownRenderer{},
+}
+
+// format is the selected output format
+var format = FormatText
+
+// SetFormat selects the output format of summaries and reports
+func SetFormat(name string) error {
+	if name == "" {
+		name = FormatText
+	}
+	if _, ok := renderers[name]; !ok {
+		return fmt.Errorf("unknown output format %q (expected %s)", name, strings.Join(Formats(), ", "))
+	}
+	format = name
+	return nil
+}
+
+// Formats returns the names of the output formats
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format returns the selected output format
+func Format() string {
+	return format
+}
+
+// Structured reports whether output is meant for programs, as JSON or
+// YAML, so nothing but the rendered data may be printed to stdout
+func Structured() bool {
+	return format == FormatJSON || format == FormatYAML
+}
+
+func renderer() Renderer {
+	return renderers[format]
+}
+
+// localizeSummary translates the labels of a summary and replaces its
+// icons when emoji are off
+func localizeSummary(data SummaryData) SummaryData {
+	items := make([]SummaryItem, len(data.Items))
+	for i, item := range data.Items {
+		item.Label = i18n.T(item.Label)
+		item.Icon = Text(item.Icon)
+		items[i] = item
+	}
+	data.Items = items
+	return data
+}
+
+// localizeTable translates table headers and replaces emoji in cells
+// when they are off
+func localizeTable(headers []string, rows [][]string) ([]string, [][]string) {
+	translated := make([]string, len(headers))
+	for i, header := range headers {
+		translated[i] = Text(i18n.T(header))
+	}
+	if !noEmoji {
+		return translated, rows
+	}
+	ascii := make([][]string, len(rows))
+	for i, row := range rows {
+		ascii[i] = make([]string, len(row))
+		for j, cell := range row {
+			ascii[i][j] = Text(cell)
+		}
+	}
+	return translated, ascii
+}
+
+// issueIcon is the marker of an issue type
+func issueIcon(issueType string) string {
+	switch issueType {
+	case "error":
+		return "❌"
+	case "warning":
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+// textRenderer draws styled boxes on a terminal and plain text otherwise
+type textRenderer struct{}
+
+func (textRenderer) Summary(w io.Writer, data SummaryData) error {
+	data = localizeSummary(data)
+
+	// Check for non-interactive environment
+	if isPlain() {
+		// Plain text output
+		fmt.Fprintf(w, "\n%s\n", data.Title)
+		fmt.Fprintln(w, strings.Repeat("=", lipgloss.Width(data.Title)))
+		for _, item := range data.Items {
+			fmt.Fprintf(w, "%s: %s\n", item.Label, item.Value)
+		}
+		if data.PublicKey != "" {
+			fmt.Fprintf(w, "\n%s\n%s\n", i18n.T("Public Key:"), data.PublicKey)
+		}
+		if len(data.NextSteps) > 0 {
+			fmt.Fprintf(w, "\n%s\n", i18n.T("Next Steps:"))
+			for i, step := range data.NextSteps {
+				fmt.Fprintf(w, "%d. %s\n", i+1, step)
+			}
+		}
+		return nil
+	}
+
+	// Styled output with Lip Gloss
+	var content strings.Builder
+
+	// Title
+	content.WriteString(titleStyle.Render(data.Title))
+	content.WriteString("\n\n")
+
+	// Items
+	for _, item := range data.Items {
+		icon := Text("✓")
+		if item.Icon != "" {
+			icon = item.Icon
+		}
+		prefix := fmt.Sprintf("%s %s: ", successStyle.Render(icon), keyStyle.Render(item.Label))
+		content.WriteString(hangingIndent(prefix, item.Value))
+		content.WriteString("\n")
+	}
+
+	// Public key
+	if data.PublicKey != "" {
+		content.WriteString("\n")
+		content.WriteString(keyStyle.Render(i18n.T("Public Key:")))
+		content.WriteString("\n")
+		content.WriteString(data.PublicKey)
+		content.WriteString("\n")
+	}
+
+	// Next steps
+	if len(data.NextSteps) > 0 {
+		content.WriteString("\n")
+		content.WriteString(keyStyle.Render(i18n.T("Next Steps:")))
+		content.WriteString("\n")
+		for i, step := range data.NextSteps {
+			content.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+		}
+	}
+
+	fmt.Fprintln(w, renderBox(content.String()))
+	return nil
+}
+
+func (textRenderer) DoctorReport(w io.Writer, issues []Issue) error {
+	title := i18n.T("Doctor Report")
+
+	// Check for non-interactive environment
+	if isPlain() {
+		// Plain text output
+		fmt.Fprintf(w, "\n%s\n", title)
+		fmt.Fprintln(w, strings.Repeat("=", lipgloss.Width(title)))
+		for _, issue := range issues {
+			fmt.Fprintf(w, "%s %s\n", Text(issueIcon(issue.Type)), issue.Message+issue.codeSuffix())
+			if fix := issue.FixText(); fix != "" {
+				fmt.Fprintf(w, "   %s\n", i18n.T("Fix: %s", fix))
+			}
+		}
+		return nil
+	}
+
+	// Styled output with Lip Gloss
+	var content strings.Builder
+
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n\n")
+
+	if len(issues) == 0 {
+		content.WriteString(successStyle.Render(Text(i18n.T("✓ All checks passed! No issues found."))))
+	} else {
+		for _, issue := range issues {
+			var style string
+			switch issue.Type {
+			case "error":
+				style = errorStyle.Render(issue.Message)
+			case "warning":
+				style = warningStyle.Render(issue.Message)
+			case "info":
+				style = infoStyle.Render(issue.Message)
+			default:
+				style = issue.Message
+			}
+
+			content.WriteString(fmt.Sprintf("%s %s%s\n", Text(issueIcon(issue.Type)), style, keyStyle.Render(issue.codeSuffix())))
+			if fix := issue.FixText(); fix != "" {
+				content.WriteString(fmt.Sprintf("   %s\n", keyStyle.Render(i18n.T("Fix: %s", fix))))
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintln(w, renderBox(content.String()))
+	return nil
+}
+
+func (textRenderer) StatusTable(w io.Writer, headers []string, rows [][]string) error {
+	headers, rows = localizeTable(headers, rows)
+
+	// Check for non-interactive environment
+	if isPlain() {
+		// Plain text output
+		fmt.Fprintln(w, strings.Join(headers, " | "))
+		fmt.Fprintln(w, strings.Repeat("-", lipgloss.Width(strings.Join(headers, " | "))))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, " | "))
+		}
+		return nil
+	}
+
+	// Styled output with Lip Gloss
+	var content strings.Builder
+
+	content.WriteString(titleStyle.Render(i18n.T("Repository Status")))
+	content.WriteString("\n\n")
+
+	// Headers
+	for i, header := range headers {
+		if i > 0 {
+			content.WriteString(" | ")
+		}
+		content.WriteString(keyStyle.Render(header))
+	}
+	content.WriteString("\n")
+	content.WriteString(strings.Repeat("-", lipgloss.Width(strings.Join(headers, " | "))))
+	content.WriteString("\n")
+
+	// Rows
+	for _, row := range rows {
+		content.WriteString(strings.Join(row, " | "))
+		content.WriteString("\n")
+	}
+
+	fmt.Fprintln(w, renderBox(content.String()))
+	return nil
+}
+
+// markdownRenderer writes GitHub-flavored Markdown, to paste into tickets
+// and wiki pages: tables, numbered lists and fenced code blocks
+type markdownRenderer struct{}
+
+func (markdownRenderer) Summary(w io.Writer, data SummaryData) error {
+	data = localizeSummary(data)
+	fmt.Fprintf(w, "## %s\n", data.Title)
+	if len(data.Items) > 0 {
+		fmt.Fprintf(w, "\n| %s | %s |\n|---|---|\n", i18n.T("Property"), i18n.T("Value"))
+		for _, item := range data.Items {
+			fmt.Fprintf(w, "| %s | %s |\n", markdownCell(item.Label), markdownCell(item.Value))
+		}
+	}
+	if data.PublicKey != "" {
+		fmt.Fprintf(w, "\n**%s**\n\n%s", i18n.T("Public Key:"), markdownFence(data.PublicKey))
+	}
+	if len(data.NextSteps) > 0 {
+		fmt.Fprintf(w, "\n**%s**\n\n", i18n.T("Next Steps:"))
+		for i, step := range data.NextSteps {
+			fmt.Fprintf(w, "%d. %s\n", i+1, step)
+		}
+	}
+	return nil
+}
+
+func (markdownRenderer) DoctorReport(w io.Writer, issues []Issue) error {
+	fmt.Fprintf(w, "## %s\n\n", i18n.T("Doctor Report"))
+	if len(issues) == 0 {
+		fmt.Fprintln(w, Text(i18n.T("✓ All checks passed! No issues found.")))
+		return nil
+	}
+
+	fmt.Fprintf(w, "| | %s | %s | %s |\n|---|---|---|---|\n", i18n.T("Code"), i18n.T("Issue"), i18n.T("Fix"))
+	for _, issue := range issues {
+		fix := markdownCell(issue.Fix)
+		if len(issue.Command) > 0 {
+			fix = markdownCode(issue.FixText())
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n", Text(issueIcon(issue.Type)), markdownCell(issue.Code), markdownCell(issue.Message), fix)
+	}
+	return nil
+}
+
+func (markdownRenderer) StatusTable(w io.Writer, headers []string, rows [][]string) error {
+	headers, rows = localizeTable(headers, rows)
+	cells := make([]string, len(headers))
+	for i, header := range headers {
+		cells[i] = markdownCell(header)
+	}
+	fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(cells, " | "), strings.Repeat("---|", len(headers)))
+	for _, row := range rows {
+		cells := make([]string, len(headers))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = markdownCell(row[i])
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return nil
+}
+
+// markdownCell escapes text for a table cell, which must stay on one line
+// and cannot hold a bare pipe. Angle brackets, as in "<url>", would be
+// taken for HTML.
+func markdownCell(s string) string {
+	s = strings.NewReplacer("|", `\|`, "<", `\<`, ">", `\>`).Replace(strings.TrimSpace(s))
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// markdownCode formats s as inline code in a table cell, with a fence
+// long enough for the backticks it contains
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	// Tables split code spans on pipes too, so they stay escaped
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+	return fence + s + fence
+}
+
+// markdownFence formats s as a fenced code block
+func markdownFence(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + "text\n" + strings.TrimRight(s, "\n") + "\n" + fence + "\n"
+}
+
+// structuredRenderer encodes summaries, reports and tables as data, for
+// scripts: JSON or YAML
+type structuredRenderer struct {
+	encode func(w io.Writer, v any) error
+}
+
+// summaryDocument is a summary as JSON or YAML encode it
+type summaryDocument struct {
+	Title     string            `json:"title" yaml:"title"`
+	Items     []summaryProperty `json:"items,omitempty" yaml:"items,omitempty"`
+	PublicKey string            `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	NextSteps []string          `json:"next_steps,omitempty" yaml:"next_steps,omitempty"`
+}
+
+type summaryProperty struct {
+	Label string `json:"label" yaml:"label"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// issueDocument is a doctor issue as JSON or YAML encode it
+type issueDocument struct {
+	Code      string   `json:"code" yaml:"code"`
+	Type      string   `json:"type" yaml:"type"`
+	Message   string   `json:"message" yaml:"message"`
+	Fix       string   `json:"fix,omitempty" yaml:"fix,omitempty"`
+	Command   []string `json:"command,omitempty" yaml:"command,omitempty"`
+	Workspace string   `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	Path      string   `json:"path,omitempty" yaml:"path,omitempty"`
+	File      string   `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+func (r structuredRenderer) Summary(w io.Writer, data SummaryData) error {
+	doc := summaryDocument{Title: data.Title, PublicKey: strings.TrimSpace(data.PublicKey), NextSteps: data.NextSteps}
+	for _, item := range data.Items {
+		doc.Items = append(doc.Items, summaryProperty{Label: item.Label, Value: item.Value})
+	}
+	return r.encode(w, doc)
+}
+
+func (r structuredRenderer) DoctorReport(w io.Writer, issues []Issue) error {
+	docs := make([]issueDocument, 0, len(issues))
+	for _, issue := range issues {
+		docs = append(docs, issueDocument{
+			Code:      issue.Code,
+			Type:      issue.Type,
+			Message:   issue.Message,
+			Fix:       issue.Fix,
+			Command:   issue.Command,
+			Workspace: issue.Workspace,
+			Path:      issue.Path,
+			File:      issue.File,
+		})
+	}
+	return r.encode(w, docs)
+}
+
+// StatusTable encodes each row as an object keyed by its column headers
+// in snake_case, untranslated so scripts can rely on them
+func (r structuredRenderer) StatusTable(w io.Writer, headers []string, rows [][]string) error {
+	keys := make([]string, len(headers))
+	for i, header := range headers {
+		keys[i] = tableKey(header)
+	}
+	docs := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		doc := make(map[string]string, len(keys))
+		for i, key := range keys {
+			if i < len(row) {
+				doc[key] = row[i]
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return r.encode(w, docs)
+}
+
+// ShowData encodes v as JSON or YAML, for a report with a shape of its
+// own; the other formats have no encoding for it
+func ShowData(v any) error {
+	r, ok := renderer().(structuredRenderer)
+	if !ok {
+		return fmt.Errorf("%s output cannot show data", format)
+	}
+	return r.encode(os.Stdout, v)
+}
+
+// tableKey turns a column header into a key: "User Email" is user_email
+func tableKey(header string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(header)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+func encodeYAML(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return encoder.Close()
+}
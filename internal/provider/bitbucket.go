@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type bitbucket struct {
+	*client
+}
+
+func (b *bitbucket) Name() string {
+	return "bitbucket"
+}
+
+// bitbucketAuth uses basic auth for "username:app-password" tokens and
+// bearer auth for workspace access tokens
+func bitbucketAuth(req *http.Request, token string) {
+	if user, pass, ok := strings.Cut(token, ":"); ok {
+		req.SetBasicAuth(user, pass)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+}
+
+func (b *bitbucket) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := b.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to verify token: %w", err)
+	}
+	return user.Username, nil
+}
+
+func (b *bitbucket) CreateRepo(ctx context.Context, opts CreateRepoOptions) (*Repo, error) {
+	if opts.Owner == "" {
+		return nil, fmt.Errorf("bitbucket repositories need an owning workspace (use <workspace>/<repo>)")
+	}
+	if opts.Visibility == VisibilityInternal {
+		return nil, fmt.Errorf("bitbucket does not support internal visibility")
+	}
+
+	body := map[string]interface{}{
+		"scm":         "git",
+		"description": opts.Description,
+		"is_private":  opts.Visibility != VisibilityPublic,
+	}
+	if opts.DefaultBranch != "" {
+		body["mainbranch"] = map[string]string{"type": "branch", "name": opts.DefaultBranch}
+	}
+	if opts.Team != "" {
+		body["project"] = map[string]string{"key": opts.Team}
+	}
+
+	var created struct {
+		FullName   string `json:"full_name"`
+		Mainbranch *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+		} `json:"links"`
+	}
+	path := fmt.Sprintf("/repositories/%s/%s", url.PathEscape(opts.Owner), url.PathEscape(strings.ToLower(opts.Name)))
+	if err := b.do(ctx, "POST", path, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+
+	repo := &Repo{
+		FullName:      created.FullName,
+		WebURL:        created.Links.HTML.Href,
+		DefaultBranch: opts.DefaultBranch,
+	}
+	if created.Mainbranch != nil && created.Mainbranch.Name != "" {
+		repo.DefaultBranch = created.Mainbranch.Name
+	}
+	for _, link := range created.Links.Clone {
+		if link.Name == "ssh" {
+			repo.SSHURL = link.Href
+		}
+	}
+
+	return repo, nil
+}
+
+func (b *bitbucket) GetRepo(ctx context.Context, fullName string) (*Repo, error) {
+	var found struct {
+		FullName   string `json:"full_name"`
+		Mainbranch *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Parent *struct {
+			FullName string `json:"full_name"`
+		} `json:"parent"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	if err := b.do(ctx, "GET", "/repositories/"+escapeFullName(strings.ToLower(fullName)), nil, &found); err != nil {
+		return nil, fmt.Errorf("failed to look up repository %s: %w", fullName, err)
+	}
+
+	repo := &Repo{FullName: found.FullName, WebURL: found.Links.HTML.Href}
+	if found.Mainbranch != nil {
+		repo.DefaultBranch = found.Mainbranch.Name
+	}
+	if found.Parent != nil {
+		repo.Parent = found.Parent.FullName
+	}
+	return repo, nil
+}
+
+func (b *bitbucket) ListRepos(ctx context.Context) ([]Repo, error) {
+	var listed struct {
+		Values []struct {
+			FullName   string `json:"full_name"`
+			Mainbranch *struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"values"`
+	}
+	if err := b.do(ctx, "GET", "/repositories?role=contributor&sort=-updated_on&pagelen=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	repos := make([]Repo, 0, len(listed.Values))
+	for _, r := range listed.Values {
+		repo := Repo{FullName: r.FullName, WebURL: r.Links.HTML.Href}
+		if r.Mainbranch != nil {
+			repo.DefaultBranch = r.Mainbranch.Name
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// bitbucketBranch is one end of a Bitbucket pull request
+type bitbucketBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+// bitbucketPull is a pull request as the Bitbucket API returns it
+type bitbucketPull struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Draft  bool   `json:"draft"`
+	Author struct {
+		Nickname string `json:"nickname"`
+	} `json:"author"`
+	Source      bitbucketBranch `json:"source"`
+	Destination bitbucketBranch `json:"destination"`
+	Links       struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func (p bitbucketPull) pullRequest() PullRequest {
+	return PullRequest{
+		Number: p.ID,
+		Title:  p.Title,
+		Author: p.Author.Nickname,
+		Head:   p.Source.Branch.Name,
+		Base:   p.Destination.Branch.Name,
+		Draft:  p.Draft,
+		WebURL: p.Links.HTML.Href,
+	}
+}
+
+func (b *bitbucket) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	body := map[string]interface{}{
+		"title":       opts.Title,
+		"description": opts.Body,
+		"draft":       opts.Draft,
+		"source": map[string]interface{}{
+			"branch":     map[string]string{"name": opts.Head},
+			"repository": map[string]string{"full_name": strings.ToLower(opts.headRepo())},
+		},
+		"destination": map[string]interface{}{
+			"branch": map[string]string{"name": opts.Base},
+		},
+	}
+
+	var created bitbucketPull
+	path := "/repositories/" + escapeFullName(strings.ToLower(opts.Repo)) + "/pullrequests"
+	if err := b.do(ctx, "POST", path, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	pr := created.pullRequest()
+	return &pr, nil
+}
+
+func (b *bitbucket) ListPullRequests(ctx context.Context, fullName string) ([]PullRequest, error) {
+	var listed struct {
+		Values []bitbucketPull `json:"values"`
+	}
+	path := "/repositories/" + escapeFullName(strings.ToLower(fullName)) + "/pullrequests?state=OPEN&pagelen=50"
+	if err := b.do(ctx, "GET", path, nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	pulls := make([]PullRequest, 0, len(listed.Values))
+	for _, p := range listed.Values {
+		pulls = append(pulls, p.pullRequest())
+	}
+	return pulls, nil
+}
+
+// keysPath returns the SSH keys endpoint of the token's account, which
+// Bitbucket addresses by UUID
+func (b *bitbucket) keysPath(ctx context.Context) (string, error) {
+	var user struct {
+		UUID string `json:"uuid"`
+	}
+	if err := b.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to look up account: %w", err)
+	}
+	return fmt.Sprintf("/users/%s/ssh-keys", url.PathEscape(user.UUID)), nil
+}
+
+func (b *bitbucket) ListSSHKeys(ctx context.Context) ([]SSHKey, error) {
+	path, err := b.keysPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var listed struct {
+		Values []struct {
+			UUID  string `json:"uuid"`
+			Label string `json:"label"`
+			Key   string `json:"key"`
+		} `json:"values"`
+	}
+	if err := b.do(ctx, "GET", path+"?pagelen=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list SSH keys: %w", err)
+	}
+
+	keys := make([]SSHKey, 0, len(listed.Values))
+	for _, k := range listed.Values {
+		keys = append(keys, SSHKey{ID: k.UUID, Title: k.Label, Key: k.Key})
+	}
+	return keys, nil
+}
+
+func (b *bitbucket) AddSSHKey(ctx context.Context, title, key string) (*SSHKey, error) {
+	path, err := b.keysPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var added struct {
+		UUID string `json:"uuid"`
+	}
+	body := map[string]string{"label": title, "key": key}
+	if err := b.do(ctx, "POST", path, body, &added); err != nil {
+		return nil, fmt.Errorf("failed to add SSH key: %w", err)
+	}
+	return &SSHKey{ID: added.UUID, Title: title, Key: key}, nil
+}
+
+func (b *bitbucket) DeleteSSHKey(ctx context.Context, id string) error {
+	path, err := b.keysPath(ctx)
+	if err != nil {
+		return err
+	}
+	if err := b.do(ctx, "DELETE", path+"/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete SSH key: %w", err)
+	}
+	return nil
+}
+
+// Bitbucket has no private commit addresses
+func (b *bitbucket) NoreplyEmail(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("noreply addresses on Bitbucket: %w", ErrUnsupported)
+}
+
+func (b *bitbucket) ListEmails(ctx context.Context) ([]Email, error) {
+	var listed struct {
+		Values []struct {
+			Email       string `json:"email"`
+			IsPrimary   bool   `json:"is_primary"`
+			IsConfirmed bool   `json:"is_confirmed"`
+		} `json:"values"`
+	}
+	if err := b.do(ctx, "GET", "/user/emails?pagelen=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	emails := make([]Email, 0, len(listed.Values))
+	for _, e := range listed.Values {
+		emails = append(emails, Email{Address: e.Email, Primary: e.IsPrimary, Verified: e.IsConfirmed})
+	}
+	return emails, nil
+}
+
+// Bitbucket's API does not manage GPG keys; they are added in the
+// personal settings
+
+func (b *bitbucket) ListGPGKeys(ctx context.Context) ([]GPGKey, error) {
+	return nil, fmt.Errorf("listing GPG keys on Bitbucket: %w", ErrUnsupported)
+}
+
+func (b *bitbucket) AddGPGKey(ctx context.Context, key string) (*GPGKey, error) {
+	return nil, fmt.Errorf("adding GPG keys on Bitbucket: %w", ErrUnsupported)
+}
+
+func (b *bitbucket) DeleteGPGKey(ctx context.Context, id string) error {
+	return fmt.Errorf("deleting GPG keys on Bitbucket: %w", ErrUnsupported)
+}
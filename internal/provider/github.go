@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type gitHub struct {
+	*client
+}
+
+func (g *gitHub) Name() string {
+	return "github"
+}
+
+func (g *gitHub) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to verify token: %w", err)
+	}
+	return user.Login, nil
+}
+
+func (g *gitHub) NoreplyEmail(ctx context.Context) (string, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to look up account: %w", err)
+	}
+	return fmt.Sprintf("%d+%s@users.noreply.%s", user.ID, user.Login, noreplyHost(g.hostName, "github.com")), nil
+}
+
+func (g *gitHub) ListEmails(ctx context.Context) ([]Email, error) {
+	var listed []struct {
+		Email      string `json:"email"`
+		Primary    bool   `json:"primary"`
+		Verified   bool   `json:"verified"`
+		Visibility string `json:"visibility"`
+	}
+	if err := g.do(ctx, "GET", "/user/emails?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	emails := make([]Email, 0, len(listed))
+	for _, e := range listed {
+		emails = append(emails, Email{Address: e.Email, Primary: e.Primary, Verified: e.Verified, Private: e.Visibility == "private"})
+	}
+	return emails, nil
+}
+
+func (g *gitHub) CreateRepo(ctx context.Context, opts CreateRepoOptions) (*Repo, error) {
+	body := map[string]interface{}{
+		"name":        opts.Name,
+		"description": opts.Description,
+		"private":     opts.Visibility != VisibilityPublic,
+	}
+	if opts.Visibility == VisibilityInternal {
+		body["visibility"] = VisibilityInternal
+	}
+
+	path := "/user/repos"
+	if opts.Owner != "" {
+		path = fmt.Sprintf("/orgs/%s/repos", url.PathEscape(opts.Owner))
+	}
+
+	var created struct {
+		FullName      string `json:"full_name"`
+		SSHURL        string `json:"ssh_url"`
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, "POST", path, body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+
+	repo := &Repo{
+		FullName:      created.FullName,
+		SSHURL:        created.SSHURL,
+		WebURL:        created.HTMLURL,
+		DefaultBranch: created.DefaultBranch,
+	}
+
+	// Empty repositories take their default branch from the first push,
+	// so the requested branch is applied by the clone instead
+	if opts.DefaultBranch != "" {
+		repo.DefaultBranch = opts.DefaultBranch
+	}
+
+	if opts.Team != "" {
+		if opts.Owner == "" {
+			return repo, fmt.Errorf("team assignment requires an organization owner")
+		}
+		path := fmt.Sprintf("/orgs/%s/teams/%s/repos/%s", url.PathEscape(opts.Owner), url.PathEscape(opts.Team), created.FullName)
+		if err := g.do(ctx, "PUT", path, map[string]string{"permission": "push"}, nil); err != nil {
+			return repo, fmt.Errorf("repository created but failed to grant team %q access: %w", opts.Team, err)
+		}
+	}
+
+	return repo, nil
+}
+
+func (g *gitHub) GetRepo(ctx context.Context, fullName string) (*Repo, error) {
+	var found struct {
+		FullName      string `json:"full_name"`
+		SSHURL        string `json:"ssh_url"`
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+		Parent        *struct {
+			FullName string `json:"full_name"`
+		} `json:"parent"`
+	}
+	if err := g.do(ctx, "GET", "/repos/"+escapeFullName(fullName), nil, &found); err != nil {
+		return nil, fmt.Errorf("failed to look up repository %s: %w", fullName, err)
+	}
+
+	repo := &Repo{
+		FullName:      found.FullName,
+		SSHURL:        found.SSHURL,
+		WebURL:        found.HTMLURL,
+		DefaultBranch: found.DefaultBranch,
+	}
+	if found.Parent != nil {
+		repo.Parent = found.Parent.FullName
+	}
+	return repo, nil
+}
+
+func (g *gitHub) ListRepos(ctx context.Context) ([]Repo, error) {
+	var listed []struct {
+		FullName      string `json:"full_name"`
+		SSHURL        string `json:"ssh_url"`
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, "GET", "/user/repos?sort=updated&per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	repos := make([]Repo, 0, len(listed))
+	for _, r := range listed {
+		repos = append(repos, Repo{FullName: r.FullName, SSHURL: r.SSHURL, WebURL: r.HTMLURL, DefaultBranch: r.DefaultBranch})
+	}
+	return repos, nil
+}
+
+// gitHubPull is a pull request as the GitHub API returns it
+type gitHubPull struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p gitHubPull) pullRequest() PullRequest {
+	return PullRequest{
+		Number: p.Number,
+		Title:  p.Title,
+		Author: p.User.Login,
+		Head:   p.Head.Ref,
+		Base:   p.Base.Ref,
+		Draft:  p.Draft,
+		WebURL: p.HTMLURL,
+	}
+}
+
+func (g *gitHub) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	// A branch in a fork is named owner:branch
+	head := opts.Head
+	if opts.HeadRepo != "" && !strings.EqualFold(opts.HeadRepo, opts.Repo) {
+		owner, _, _ := strings.Cut(opts.HeadRepo, "/")
+		head = owner + ":" + opts.Head
+	}
+	body := map[string]interface{}{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  head,
+		"base":  opts.Base,
+		"draft": opts.Draft,
+	}
+
+	var created gitHubPull
+	if err := g.do(ctx, "POST", "/repos/"+escapeFullName(opts.Repo)+"/pulls", body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	pr := created.pullRequest()
+	return &pr, nil
+}
+
+func (g *gitHub) ListPullRequests(ctx context.Context, fullName string) ([]PullRequest, error) {
+	var listed []gitHubPull
+	if err := g.do(ctx, "GET", "/repos/"+escapeFullName(fullName)+"/pulls?state=open&per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	pulls := make([]PullRequest, 0, len(listed))
+	for _, p := range listed {
+		pulls = append(pulls, p.pullRequest())
+	}
+	return pulls, nil
+}
+
+func (g *gitHub) ListSSHKeys(ctx context.Context) ([]SSHKey, error) {
+	var listed []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+		Key   string `json:"key"`
+	}
+	if err := g.do(ctx, "GET", "/user/keys?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list SSH keys: %w", err)
+	}
+
+	keys := make([]SSHKey, 0, len(listed))
+	for _, k := range listed {
+		keys = append(keys, SSHKey{ID: strconv.Itoa(k.ID), Title: k.Title, Key: k.Key})
+	}
+	return keys, nil
+}
+
+func (g *gitHub) AddSSHKey(ctx context.Context, title, key string) (*SSHKey, error) {
+	var added struct {
+		ID int `json:"id"`
+	}
+	body := map[string]string{"title": title, "key": key}
+	if err := g.do(ctx, "POST", "/user/keys", body, &added); err != nil {
+		return nil, fmt.Errorf("failed to add SSH key: %w", err)
+	}
+	return &SSHKey{ID: strconv.Itoa(added.ID), Title: title, Key: key}, nil
+}
+
+func (g *gitHub) DeleteSSHKey(ctx context.Context, id string) error {
+	if err := g.do(ctx, "DELETE", "/user/keys/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete SSH key: %w", err)
+	}
+	return nil
+}
+
+func (g *gitHub) ListGPGKeys(ctx context.Context) ([]GPGKey, error) {
+	var listed []struct {
+		ID     int    `json:"id"`
+		KeyID  string `json:"key_id"`
+		RawKey string `json:"raw_key"`
+	}
+	if err := g.do(ctx, "GET", "/user/gpg_keys?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list GPG keys: %w", err)
+	}
+
+	keys := make([]GPGKey, 0, len(listed))
+	for _, k := range listed {
+		keys = append(keys, GPGKey{ID: strconv.Itoa(k.ID), KeyID: k.KeyID, Key: k.RawKey})
+	}
+	return keys, nil
+}
+
+func (g *gitHub) AddGPGKey(ctx context.Context, key string) (*GPGKey, error) {
+	var added struct {
+		ID    int    `json:"id"`
+		KeyID string `json:"key_id"`
+	}
+	body := map[string]string{"armored_public_key": key}
+	if err := g.do(ctx, "POST", "/user/gpg_keys", body, &added); err != nil {
+		return nil, fmt.Errorf("failed to add GPG key: %w", err)
+	}
+	return &GPGKey{ID: strconv.Itoa(added.ID), KeyID: added.KeyID, Key: key}, nil
+}
+
+func (g *gitHub) DeleteGPGKey(ctx context.Context, id string) error {
+	if err := g.do(ctx, "DELETE", "/user/gpg_keys/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete GPG key: %w", err)
+	}
+	return nil
+}
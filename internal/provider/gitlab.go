@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type gitLab struct {
+	*client
+}
+
+func (g *gitLab) Name() string {
+	return "gitlab"
+}
+
+// gitLabDeveloperAccess is the access level granted to a shared group
+const gitLabDeveloperAccess = 30
+
+func (g *gitLab) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to verify token: %w", err)
+	}
+	return user.Username, nil
+}
+
+func (g *gitLab) NoreplyEmail(ctx context.Context) (string, error) {
+	var user struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to look up account: %w", err)
+	}
+	return fmt.Sprintf("%d-%s@users.noreply.%s", user.ID, user.Username, noreplyHost(g.hostName, "gitlab.com")), nil
+}
+
+// ListEmails returns the primary email, which /user/emails leaves out,
+// followed by the secondary ones
+func (g *gitLab) ListEmails(ctx context.Context) ([]Email, error) {
+	var user struct {
+		Email string `json:"email"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to look up account: %w", err)
+	}
+	var listed []struct {
+		Email       string  `json:"email"`
+		ConfirmedAt *string `json:"confirmed_at"`
+	}
+	if err := g.do(ctx, "GET", "/user/emails?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+
+	emails := []Email{{Address: user.Email, Primary: true, Verified: true}}
+	for _, e := range listed {
+		if e.Email == user.Email {
+			continue
+		}
+		emails = append(emails, Email{Address: e.Email, Verified: e.ConfirmedAt != nil})
+	}
+	return emails, nil
+}
+
+func (g *gitLab) CreateRepo(ctx context.Context, opts CreateRepoOptions) (*Repo, error) {
+	body := map[string]interface{}{
+		"name":        opts.Name,
+		"path":        opts.Name,
+		"description": opts.Description,
+		"visibility":  opts.Visibility,
+	}
+	if opts.DefaultBranch != "" {
+		body["default_branch"] = opts.DefaultBranch
+	}
+
+	if opts.Owner != "" {
+		namespace, err := g.groupID(ctx, opts.Owner)
+		if err != nil {
+			return nil, err
+		}
+		body["namespace_id"] = namespace
+	}
+
+	var created struct {
+		ID                int    `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+		SSHURLToRepo      string `json:"ssh_url_to_repo"`
+		WebURL            string `json:"web_url"`
+		DefaultBranch     string `json:"default_branch"`
+	}
+	if err := g.do(ctx, "POST", "/projects", body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+
+	repo := &Repo{
+		FullName:      created.PathWithNamespace,
+		SSHURL:        created.SSHURLToRepo,
+		WebURL:        created.WebURL,
+		DefaultBranch: created.DefaultBranch,
+	}
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = opts.DefaultBranch
+	}
+
+	if opts.Team != "" {
+		groupID, err := g.groupID(ctx, opts.Team)
+		if err != nil {
+			return repo, fmt.Errorf("repository created but %w", err)
+		}
+		share := map[string]interface{}{"group_id": groupID, "group_access": gitLabDeveloperAccess}
+		if err := g.do(ctx, "POST", fmt.Sprintf("/projects/%d/share", created.ID), share, nil); err != nil {
+			return repo, fmt.Errorf("repository created but failed to share with group %q: %w", opts.Team, err)
+		}
+	}
+
+	return repo, nil
+}
+
+func (g *gitLab) GetRepo(ctx context.Context, fullName string) (*Repo, error) {
+	var found struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		SSHURLToRepo      string `json:"ssh_url_to_repo"`
+		WebURL            string `json:"web_url"`
+		DefaultBranch     string `json:"default_branch"`
+		ForkedFrom        *struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"forked_from_project"`
+	}
+	if err := g.do(ctx, "GET", "/projects/"+url.PathEscape(fullName), nil, &found); err != nil {
+		return nil, fmt.Errorf("failed to look up repository %s: %w", fullName, err)
+	}
+
+	repo := &Repo{
+		FullName:      found.PathWithNamespace,
+		SSHURL:        found.SSHURLToRepo,
+		WebURL:        found.WebURL,
+		DefaultBranch: found.DefaultBranch,
+	}
+	if found.ForkedFrom != nil {
+		repo.Parent = found.ForkedFrom.PathWithNamespace
+	}
+	return repo, nil
+}
+
+func (g *gitLab) ListRepos(ctx context.Context) ([]Repo, error) {
+	var listed []struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		SSHURLToRepo      string `json:"ssh_url_to_repo"`
+		WebURL            string `json:"web_url"`
+		DefaultBranch     string `json:"default_branch"`
+	}
+	path := fmt.Sprintf("/projects?membership=true&min_access_level=%d&order_by=last_activity_at&simple=true&per_page=100", gitLabDeveloperAccess)
+	if err := g.do(ctx, "GET", path, nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	repos := make([]Repo, 0, len(listed))
+	for _, r := range listed {
+		repos = append(repos, Repo{FullName: r.PathWithNamespace, SSHURL: r.SSHURLToRepo, WebURL: r.WebURL, DefaultBranch: r.DefaultBranch})
+	}
+	return repos, nil
+}
+
+// gitLabMergeRequest is a merge request as the GitLab API returns it
+type gitLabMergeRequest struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	Draft        bool   `json:"draft"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+func (m gitLabMergeRequest) pullRequest() PullRequest {
+	return PullRequest{
+		Number: m.IID,
+		Title:  m.Title,
+		Author: m.Author.Username,
+		Head:   m.SourceBranch,
+		Base:   m.TargetBranch,
+		Draft:  m.Draft,
+		WebURL: m.WebURL,
+	}
+}
+
+func (g *gitLab) CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		title = "Draft: " + title
+	}
+	body := map[string]interface{}{
+		"title":         title,
+		"description":   opts.Body,
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+	}
+
+	// A merge request from a fork is opened on the fork, naming the
+	// project it targets by ID
+	source := opts.headRepo()
+	if !strings.EqualFold(source, opts.Repo) {
+		var target struct {
+			ID int `json:"id"`
+		}
+		if err := g.do(ctx, "GET", "/projects/"+url.PathEscape(opts.Repo), nil, &target); err != nil {
+			return nil, fmt.Errorf("failed to look up repository %s: %w", opts.Repo, err)
+		}
+		body["target_project_id"] = target.ID
+	}
+
+	var created gitLabMergeRequest
+	if err := g.do(ctx, "POST", "/projects/"+url.PathEscape(source)+"/merge_requests", body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+	pr := created.pullRequest()
+	return &pr, nil
+}
+
+func (g *gitLab) ListPullRequests(ctx context.Context, fullName string) ([]PullRequest, error) {
+	var listed []gitLabMergeRequest
+	if err := g.do(ctx, "GET", "/projects/"+url.PathEscape(fullName)+"/merge_requests?state=opened&per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	pulls := make([]PullRequest, 0, len(listed))
+	for _, m := range listed {
+		pulls = append(pulls, m.pullRequest())
+	}
+	return pulls, nil
+}
+
+func (g *gitLab) groupID(ctx context.Context, path string) (int, error) {
+	var group struct {
+		ID int `json:"id"`
+	}
+	if err := g.do(ctx, "GET", "/groups/"+url.PathEscape(path), nil, &group); err != nil {
+		return 0, fmt.Errorf("failed to look up group %q: %w", path, err)
+	}
+	return group.ID, nil
+}
+
+func (g *gitLab) ListSSHKeys(ctx context.Context) ([]SSHKey, error) {
+	var listed []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+		Key   string `json:"key"`
+	}
+	if err := g.do(ctx, "GET", "/user/keys?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list SSH keys: %w", err)
+	}
+
+	keys := make([]SSHKey, 0, len(listed))
+	for _, k := range listed {
+		keys = append(keys, SSHKey{ID: strconv.Itoa(k.ID), Title: k.Title, Key: k.Key})
+	}
+	return keys, nil
+}
+
+func (g *gitLab) AddSSHKey(ctx context.Context, title, key string) (*SSHKey, error) {
+	var added struct {
+		ID int `json:"id"`
+	}
+	body := map[string]string{"title": title, "key": key}
+	if err := g.do(ctx, "POST", "/user/keys", body, &added); err != nil {
+		return nil, fmt.Errorf("failed to add SSH key: %w", err)
+	}
+	return &SSHKey{ID: strconv.Itoa(added.ID), Title: title, Key: key}, nil
+}
+
+func (g *gitLab) DeleteSSHKey(ctx context.Context, id string) error {
+	if err := g.do(ctx, "DELETE", "/user/keys/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete SSH key: %w", err)
+	}
+	return nil
+}
+
+func (g *gitLab) ListGPGKeys(ctx context.Context) ([]GPGKey, error) {
+	var listed []struct {
+		ID  int    `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := g.do(ctx, "GET", "/user/gpg_keys?per_page=100", nil, &listed); err != nil {
+		return nil, fmt.Errorf("failed to list GPG keys: %w", err)
+	}
+
+	keys := make([]GPGKey, 0, len(listed))
+	for _, k := range listed {
+		keys = append(keys, GPGKey{ID: strconv.Itoa(k.ID), Key: k.Key})
+	}
+	return keys, nil
+}
+
+func (g *gitLab) AddGPGKey(ctx context.Context, key string) (*GPGKey, error) {
+	var added struct {
+		ID int `json:"id"`
+	}
+	body := map[string]string{"key": key}
+	if err := g.do(ctx, "POST", "/user/gpg_keys", body, &added); err != nil {
+		return nil, fmt.Errorf("failed to add GPG key: %w", err)
+	}
+	return &GPGKey{ID: strconv.Itoa(added.ID), Key: key}, nil
+}
+
+func (g *gitLab) DeleteGPGKey(ctx context.Context, id string) error {
+	if err := g.do(ctx, "DELETE", "/user/gpg_keys/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete GPG key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrDeviceCodeExpired is returned when the user does not approve the
+// device code before it expires
+var ErrDeviceCodeExpired = errors.New("device code expired before it was approved")
+
+// ErrAccessDenied is returned when the user declines the authorization
+var ErrAccessDenied = errors.New("authorization was denied")
+
+// pollUnit scales the polling interval; tests shrink it
+var pollUnit = time.Second
+
+// DeviceCode is the code pair handed out at the start of a device flow
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceFlow implements the OAuth 2.0 device authorization grant
+// (RFC 8628) against GitHub or GitLab
+type DeviceFlow struct {
+	CodeURL  string
+	TokenURL string
+	ClientID string
+	Scopes   []string
+	http     *http.Client
+}
+
+// NewDeviceFlow returns the device flow endpoints for kind on hostName
+func NewDeviceFlow(kind, hostName, clientID string) (*DeviceFlow, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("device flow needs an OAuth application client ID")
+	}
+
+	flow := &DeviceFlow{
+		ClientID: clientID,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+
+	switch kind {
+	case "github":
+		flow.CodeURL = fmt.Sprintf("https://%s/login/device/code", hostName)
+		flow.TokenURL = fmt.Sprintf("https://%s/login/oauth/access_token", hostName)
+		flow.Scopes = []string{"repo", "read:org"}
+	case "gitlab":
+		flow.CodeURL = fmt.Sprintf("https://%s/oauth/authorize_device", hostName)
+		flow.TokenURL = fmt.Sprintf("https://%s/oauth/token", hostName)
+		flow.Scopes = []string{"api"}
+	default:
+		return nil, fmt.Errorf("device flow is not supported for %s (supported: github, gitlab)", kind)
+	}
+
+	return flow, nil
+}
+
+// Start requests a device and user code pair
+func (f *DeviceFlow) Start(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {f.ClientID},
+		"scope":     {strings.Join(f.Scopes, " ")},
+	}
+
+	var code DeviceCode
+	var failure oauthError
+	if err := f.post(ctx, f.CodeURL, form, &code, &failure); err != nil {
+		return nil, fmt.Errorf("failed to start device flow: %w", err)
+	}
+	if failure.Error != "" {
+		return nil, fmt.Errorf("failed to start device flow: %s", failure)
+	}
+	if code.DeviceCode == "" {
+		return nil, fmt.Errorf("failed to start device flow: no device code returned")
+	}
+
+	return &code, nil
+}
+
+// Poll waits for the user to approve code and returns the access token.
+// It gives up when ctx is done.
+func (f *DeviceFlow) Poll(ctx context.Context, code *DeviceCode) (string, error) {
+	interval := code.Interval
+	if interval <= 0 {
+		interval = 5
+	}
+	expiresIn := code.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 900
+	}
+	deadline := time.Now().Add(time.Duration(expiresIn) * pollUnit)
+
+	form := url.Values{
+		"client_id":   {f.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Duration(interval) * pollUnit):
+		}
+
+		var token struct {
+			AccessToken string `json:"access_token"`
+		}
+		var failure oauthError
+		if err := f.post(ctx, f.TokenURL, form, &token, &failure); err != nil {
+			return "", fmt.Errorf("failed to poll for token: %w", err)
+		}
+
+		switch failure.Error {
+		case "":
+			if token.AccessToken == "" {
+				return "", fmt.Errorf("failed to poll for token: no access token returned")
+			}
+			return token.AccessToken, nil
+		case "authorization_pending":
+			// Keep waiting
+		case "slow_down":
+			interval += 5
+		case "expired_token":
+			return "", ErrDeviceCodeExpired
+		case "access_denied":
+			return "", ErrAccessDenied
+		default:
+			return "", fmt.Errorf("failed to poll for token: %s", failure)
+		}
+	}
+
+	return "", ErrDeviceCodeExpired
+}
+
+// oauthError is the RFC 6749 error body. GitHub sends it with a 200
+// status, GitLab with a 400, so both are decoded from every response.
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e oauthError) String() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s (%s)", e.Error, e.Description)
+	}
+	return e.Error
+}
+
+func (f *DeviceFlow) post(ctx context.Context, endpoint string, form url.Values, out interface{}, failure *oauthError) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(data, failure); err != nil {
+		return &APIError{Status: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+	if failure.Error != "" {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Status: resp.StatusCode, Message: errorMessage(data)}
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeviceFlowPoll(t *testing.T) {
+	pollUnit = time.Millisecond
+	defer func() { pollUnit = time.Second }()
+
+	responses := []string{
+		`{"error":"authorization_pending"}`,
+		`{"error":"slow_down"}`,
+		`{"access_token":"gho_token","token_type":"bearer"}`,
+	}
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.FormValue("grant_type"); got != "urn:ietf:params:oauth:grant-type:device_code" {
+			t.Errorf("unexpected grant_type %q", got)
+		}
+		w.Write([]byte(responses[polls]))
+		polls++
+	}))
+	defer server.Close()
+
+	flow := &DeviceFlow{TokenURL: server.URL, ClientID: "client", http: server.Client()}
+	token, err := flow.Poll(context.Background(), &DeviceCode{DeviceCode: "dc", Interval: 1, ExpiresIn: 60})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if token != "gho_token" {
+		t.Errorf("expected %q, got %q", "gho_token", token)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 polls, got %d", polls)
+	}
+}
+
+func TestDeviceFlowPollErrors(t *testing.T) {
+	pollUnit = time.Millisecond
+	defer func() { pollUnit = time.Second }()
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"denied", http.StatusOK, `{"error":"access_denied"}`, ErrAccessDenied},
+		{"expired", http.StatusBadRequest, `{"error":"expired_token"}`, ErrDeviceCodeExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			flow := &DeviceFlow{TokenURL: server.URL, ClientID: "client", http: server.Client()}
+			if _, err := flow.Poll(context.Background(), &DeviceCode{DeviceCode: "dc", Interval: 1}); err != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDeviceFlowPollCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"authorization_pending"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	flow := &DeviceFlow{TokenURL: server.URL, ClientID: "client", http: server.Client()}
+	if _, err := flow.Poll(ctx, &DeviceCode{DeviceCode: "dc", Interval: 5}); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,310 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Visibility values accepted by CreateRepoOptions
+const (
+	VisibilityPrivate  = "private"
+	VisibilityPublic   = "public"
+	VisibilityInternal = "internal"
+)
+
+// CreateRepoOptions describes a repository to create
+type CreateRepoOptions struct {
+	Owner         string // Organization, group or workspace; empty for the token's user
+	Name          string
+	Description   string
+	Visibility    string
+	DefaultBranch string
+	Team          string // Team (GitHub), group path (GitLab) or project key (Bitbucket)
+}
+
+// Repo is a repository as reported by the provider
+type Repo struct {
+	FullName      string
+	SSHURL        string
+	WebURL        string
+	DefaultBranch string
+	// Parent is the full name of the repository this one is a fork of;
+	// empty when it is not a fork
+	Parent string
+}
+
+// PullRequest is a pull request, or a merge request on GitLab
+type PullRequest struct {
+	Number int
+	Title  string
+	Author string
+	Head   string // Branch to merge
+	Base   string // Branch to merge into
+	Draft  bool
+	WebURL string
+}
+
+// CreatePullRequestOptions describes a pull request to open
+type CreatePullRequestOptions struct {
+	Repo     string // Full name of the repository to merge into
+	HeadRepo string // Full name of the fork holding Head; empty for Repo itself
+	Head     string
+	Base     string
+	Title    string
+	Body     string
+	Draft    bool
+}
+
+// headRepo returns the repository holding the branch to merge
+func (o CreatePullRequestOptions) headRepo() string {
+	if o.HeadRepo == "" {
+		return o.Repo
+	}
+	return o.HeadRepo
+}
+
+// SSHKey is an SSH key registered with the token's account
+type SSHKey struct {
+	ID    string
+	Title string
+	Key   string // authorized_keys format
+}
+
+// GPGKey is a GPG public key registered with the token's account
+type GPGKey struct {
+	ID    string
+	KeyID string // long ID of the primary key, when the API reports it
+	Key   string // armored public key, when the API reports it
+}
+
+// Email is an address registered with the token's account
+type Email struct {
+	Address  string
+	Primary  bool
+	Verified bool
+	// Private is set when the provider keeps the address out of public
+	// view; GitHub can then reject pushes of commits using it (GH007)
+	Private bool
+}
+
+// ErrUnsupported is returned for what a provider's API does not offer
+var ErrUnsupported = errors.New("not supported by the provider API")
+
+// Provider creates repositories and pull requests and manages SSH and GPG
+// keys through a hosting provider's API
+type Provider interface {
+	Name() string
+	// CurrentUser returns the account the token authenticates as
+	CurrentUser(ctx context.Context) (string, error)
+	// NoreplyEmail returns the private commit address the provider
+	// assigns the token's account, e.g. ID+login@users.noreply.github.com
+	NoreplyEmail(ctx context.Context) (string, error)
+	// ListEmails returns the addresses registered with the token's account
+	ListEmails(ctx context.Context) ([]Email, error)
+	CreateRepo(ctx context.Context, opts CreateRepoOptions) (*Repo, error)
+	// GetRepo looks up a repository by its full name, e.g. "acme/app"
+	GetRepo(ctx context.Context, fullName string) (*Repo, error)
+	// ListRepos returns the first 100 repositories the token's account
+	// can push to, most recently updated first
+	ListRepos(ctx context.Context) ([]Repo, error)
+	CreatePullRequest(ctx context.Context, opts CreatePullRequestOptions) (*PullRequest, error)
+	// ListPullRequests returns the open pull requests of a repository
+	ListPullRequests(ctx context.Context, fullName string) ([]PullRequest, error)
+	ListSSHKeys(ctx context.Context) ([]SSHKey, error)
+	AddSSHKey(ctx context.Context, title, key string) (*SSHKey, error)
+	DeleteSSHKey(ctx context.Context, id string) error
+	ListGPGKeys(ctx context.Context) ([]GPGKey, error)
+	// AddGPGKey registers an armored public key
+	AddGPGKey(ctx context.Context, key string) (*GPGKey, error)
+	DeleteGPGKey(ctx context.Context, id string) error
+}
+
+// New returns the provider client for kind ("github", "gitlab" or
+// "bitbucket") talking to hostName with token
+func New(kind, hostName, token string) (Provider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("no API token available")
+	}
+
+	c := &client{
+		http:     &http.Client{Timeout: 30 * time.Second},
+		hostName: hostName,
+		token:    token,
+	}
+
+	switch kind {
+	case "github":
+		c.baseURL = "https://api.github.com"
+		if hostName != "github.com" {
+			c.baseURL = fmt.Sprintf("https://%s/api/v3", hostName)
+		}
+		return &gitHub{c}, nil
+	case "gitlab":
+		c.baseURL = fmt.Sprintf("https://%s/api/v4", hostName)
+		return &gitLab{c}, nil
+	case "bitbucket":
+		c.baseURL = "https://api.bitbucket.org/2.0"
+		c.auth = bitbucketAuth
+		return &bitbucket{c}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s (supported: github, gitlab, bitbucket)", kind)
+	}
+}
+
+// noreplyHost is the host whose users.noreply subdomain carries the
+// private commit addresses of hostName, the public one when unknown
+func noreplyHost(hostName, public string) string {
+	if hostName == "" {
+		return public
+	}
+	if host, _, err := net.SplitHostPort(hostName); err == nil {
+		hostName = host
+	}
+	return strings.ToLower(hostName)
+}
+
+// Detect guesses the provider kind from a hostname, returning "" when
+// it cannot tell
+func Detect(hostName string) string {
+	for _, kind := range []string{"github", "gitlab", "bitbucket"} {
+		if strings.Contains(hostName, kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// TokenUsername returns the username git sends with an API token over
+// HTTPS on kind; the providers ignore it or expect a fixed one
+func TokenUsername(kind string) string {
+	switch kind {
+	case "github":
+		return "x-access-token"
+	case "gitlab":
+		return "oauth2"
+	case "bitbucket":
+		return "x-token-auth"
+	}
+	return "token"
+}
+
+// IsValidVisibility returns true if visibility is a supported value
+func IsValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPrivate, VisibilityPublic, VisibilityInternal:
+		return true
+	}
+	return false
+}
+
+// client is the JSON-over-HTTP plumbing shared by all providers
+type client struct {
+	http     *http.Client
+	baseURL  string
+	hostName string
+	token    string
+	auth     func(req *http.Request, token string)
+}
+
+// APIError is returned when a provider answers with a non-2xx status
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("provider API returned %d: %s", e.Status, e.Message)
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		c.auth(req, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Status: resp.StatusCode, Message: errorMessage(data)}
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// errorMessage pulls a human readable message out of an error body; the
+// three providers all use a slightly different shape
+func errorMessage(data []byte) string {
+	var body struct {
+		Message interface{} `json:"message"`
+		Error   interface{} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil {
+		for _, v := range []interface{}{body.Message, body.Error} {
+			switch m := v.(type) {
+			case string:
+				if m != "" {
+					return m
+				}
+			case map[string]interface{}:
+				if s, ok := m["message"].(string); ok {
+					return s
+				}
+			case nil:
+			default:
+				return fmt.Sprint(m)
+			}
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// escapeFullName escapes each segment of a repository's full name,
+// keeping the slashes between them
+func escapeFullName(fullName string) string {
+	segments := strings.Split(fullName, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
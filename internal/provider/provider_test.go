@@ -0,0 +1,472 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &client{http: server.Client(), baseURL: server.URL, token: "secret"}
+}
+
+func TestGitHubCreateRepo(t *testing.T) {
+	var calls []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("expected %q, got %q", "Bearer secret", got)
+		}
+		switch r.URL.Path {
+		case "/orgs/acme/repos":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["private"] != true {
+				t.Errorf("expected private repository, got %v", body["private"])
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"full_name":"acme/svc","ssh_url":"git@github.com:acme/svc.git","html_url":"https://github.com/acme/svc"}`))
+		case "/orgs/acme/teams/backend/repos/acme/svc":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	repo, err := (&gitHub{c}).CreateRepo(context.Background(), CreateRepoOptions{
+		Owner:         "acme",
+		Name:          "svc",
+		Visibility:    VisibilityPrivate,
+		DefaultBranch: "trunk",
+		Team:          "backend",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.FullName != "acme/svc" {
+		t.Errorf("expected %q, got %q", "acme/svc", repo.FullName)
+	}
+	if repo.DefaultBranch != "trunk" {
+		t.Errorf("expected %q, got %q", "trunk", repo.DefaultBranch)
+	}
+	if len(calls) != 2 {
+		t.Errorf("expected 2 API calls, got %v", calls)
+	}
+}
+
+func TestCreateRepoAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"name already exists on this account"}`))
+	})
+
+	_, err := (&gitHub{c}).CreateRepo(context.Background(), CreateRepoOptions{Name: "svc", Visibility: VisibilityPublic})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	expected := "failed to create repository: provider API returned 422: name already exists on this account"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"github.com", "github"},
+		{"github.example.com", "github"},
+		{"gitlab.internal.net", "gitlab"},
+		{"bitbucket.org", "bitbucket"},
+		{"git.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := Detect(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTokenUsername(t *testing.T) {
+	tests := map[string]string{
+		"github":    "x-access-token",
+		"gitlab":    "oauth2",
+		"bitbucket": "x-token-auth",
+		"":          "token",
+	}
+	for kind, expected := range tests {
+		if result := TokenUsername(kind); result != expected {
+			t.Errorf("%s: expected %q, got %q", kind, expected, result)
+		}
+	}
+}
+
+func TestGitHubSSHKeys(t *testing.T) {
+	var calls []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /user/keys":
+			w.Write([]byte(`[{"id":7,"title":"laptop","key":"ssh-ed25519 AAAA"}]`))
+		case "POST /user/keys":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["title"] != "new" {
+				t.Errorf("expected %q, got %q", "new", body["title"])
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":8}`))
+		case "DELETE /user/keys/7":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	g := &gitHub{c}
+
+	keys, err := g.ListSSHKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "7" || keys[0].Title != "laptop" {
+		t.Errorf("expected key 7 (laptop), got %+v", keys)
+	}
+
+	added, err := g.AddSSHKey(context.Background(), "new", "ssh-ed25519 BBBB")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added.ID != "8" {
+		t.Errorf("expected %q, got %q", "8", added.ID)
+	}
+
+	if err := g.DeleteSSHKey(context.Background(), "7"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("expected 3 API calls, got %v", calls)
+	}
+}
+
+func TestBitbucketSSHKeys(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /user":
+			w.Write([]byte(`{"uuid":"{abc}"}`))
+		case "GET /users/%7Babc%7D/ssh-keys":
+			w.Write([]byte(`{"values":[{"uuid":"{k1}","label":"laptop","key":"ssh-ed25519 AAAA"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	keys, err := (&bitbucket{c}).ListSSHKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "{k1}" || keys[0].Title != "laptop" {
+		t.Errorf("expected key {k1} (laptop), got %+v", keys)
+	}
+}
+
+func TestGetRepoParent(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		response string
+		get      func(c *client) (*Repo, error)
+		expected string
+	}{
+		{
+			name:     "github fork",
+			path:     "/repos/me/app",
+			response: `{"full_name": "me/app", "parent": {"full_name": "acme/app"}}`,
+			get:      func(c *client) (*Repo, error) { return (&gitHub{c}).GetRepo(context.Background(), "me/app") },
+			expected: "acme/app",
+		},
+		{
+			name:     "github source",
+			path:     "/repos/acme/app",
+			response: `{"full_name": "acme/app"}`,
+			get:      func(c *client) (*Repo, error) { return (&gitHub{c}).GetRepo(context.Background(), "acme/app") },
+			expected: "",
+		},
+		{
+			name:     "gitlab fork",
+			path:     "/projects/me%2Fapp",
+			response: `{"path_with_namespace": "me/app", "forked_from_project": {"path_with_namespace": "acme/platform/app"}}`,
+			get:      func(c *client) (*Repo, error) { return (&gitLab{c}).GetRepo(context.Background(), "me/app") },
+			expected: "acme/platform/app",
+		},
+		{
+			name:     "bitbucket fork",
+			path:     "/repositories/me/app",
+			response: `{"full_name": "me/app", "parent": {"full_name": "acme/app"}}`,
+			get:      func(c *client) (*Repo, error) { return (&bitbucket{c}).GetRepo(context.Background(), "Me/App") },
+			expected: "acme/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.EscapedPath() != tt.path {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+				}
+				_, _ = w.Write([]byte(tt.response))
+			})
+			repo, err := tt.get(c)
+			if err != nil {
+				t.Fatalf("GetRepo failed: %v", err)
+			}
+			if repo.Parent != tt.expected {
+				t.Errorf("expected parent %q, got %q", tt.expected, repo.Parent)
+			}
+		})
+	}
+}
+
+func TestGitHubCreatePullRequestFromFork(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/app/pulls" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["head"] != "me:fix-typo" {
+			t.Errorf("expected %q, got %v", "me:fix-typo", body["head"])
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number":7,"title":"Fix typo","html_url":"https://github.com/acme/app/pull/7","user":{"login":"me"},"head":{"ref":"fix-typo"},"base":{"ref":"main"}}`))
+	})
+
+	pr, err := (&gitHub{c}).CreatePullRequest(context.Background(), CreatePullRequestOptions{
+		Repo:     "acme/app",
+		HeadRepo: "me/app",
+		Head:     "fix-typo",
+		Base:     "main",
+		Title:    "Fix typo",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Number != 7 || pr.Author != "me" || pr.Base != "main" {
+		t.Errorf("expected #7 by me into main, got %+v", pr)
+	}
+}
+
+func TestGitLabCreateMergeRequestFromFork(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /projects/acme%2Fplatform%2Fapp":
+			w.Write([]byte(`{"id":42}`))
+		case "POST /projects/me%2Fapp/merge_requests":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["target_project_id"] != float64(42) {
+				t.Errorf("expected target project 42, got %v", body["target_project_id"])
+			}
+			if body["title"] != "Draft: Fix typo" {
+				t.Errorf("expected %q, got %v", "Draft: Fix typo", body["title"])
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"iid":3,"title":"Draft: Fix typo","draft":true,"source_branch":"fix-typo","target_branch":"main"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	pr, err := (&gitLab{c}).CreatePullRequest(context.Background(), CreatePullRequestOptions{
+		Repo:     "acme/platform/app",
+		HeadRepo: "me/app",
+		Head:     "fix-typo",
+		Base:     "main",
+		Title:    "Fix typo",
+		Draft:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr.Number != 3 || !pr.Draft {
+		t.Errorf("expected draft !3, got %+v", pr)
+	}
+}
+
+func TestBitbucketListPullRequests(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/acme/app/pullrequests" || r.URL.Query().Get("state") != "OPEN" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`{"values":[{"id":5,"title":"Add CI","author":{"nickname":"me"},"source":{"branch":{"name":"ci"}},"destination":{"branch":{"name":"main"}},"links":{"html":{"href":"https://bitbucket.org/acme/app/pull-requests/5"}}}]}`))
+	})
+
+	pulls, err := (&bitbucket{c}).ListPullRequests(context.Background(), "Acme/App")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 5 || pulls[0].Head != "ci" || pulls[0].Author != "me" {
+		t.Errorf("expected #5 from ci by me, got %+v", pulls)
+	}
+}
+
+func TestGitLabGPGKeys(t *testing.T) {
+	var calls []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /user/gpg_keys":
+			w.Write([]byte(`[{"id":3,"key":"-----BEGIN PGP PUBLIC KEY BLOCK-----"}]`))
+		case "POST /user/gpg_keys":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["key"] != "armored" {
+				t.Errorf("expected %q, got %q", "armored", body["key"])
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":4}`))
+		case "DELETE /user/gpg_keys/3":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	g := &gitLab{c}
+
+	keys, err := g.ListGPGKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "3" || keys[0].Key == "" {
+		t.Errorf("expected key 3 with its armored key, got %+v", keys)
+	}
+
+	added, err := g.AddGPGKey(context.Background(), "armored")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added.ID != "4" {
+		t.Errorf("expected %q, got %q", "4", added.ID)
+	}
+
+	if err := g.DeleteGPGKey(context.Background(), "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("expected 3 API calls, got %v", calls)
+	}
+}
+
+func TestBitbucketGPGKeysUnsupported(t *testing.T) {
+	_, err := (&bitbucket{}).AddGPGKey(context.Background(), "armored")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(c *client) Provider
+		hostName string
+		user     string
+		expected string
+	}{
+		{"github.com", func(c *client) Provider { return &gitHub{c} }, "github.com", `{"id":42,"login":"octo"}`, "42+octo@users.noreply.github.com"},
+		{"github enterprise", func(c *client) Provider { return &gitHub{c} }, "GHE.acme.com", `{"id":7,"login":"me"}`, "7+me@users.noreply.ghe.acme.com"},
+		{"host with port", func(c *client) Provider { return &gitHub{c} }, "ghe.acme.com:8443", `{"id":7,"login":"me"}`, "7+me@users.noreply.ghe.acme.com"},
+		{"gitlab.com", func(c *client) Provider { return &gitLab{c} }, "gitlab.com", `{"id":9,"username":"lab"}`, "9-lab@users.noreply.gitlab.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/user" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(tt.user))
+			})
+			c.hostName = tt.hostName
+
+			addr, err := tt.provider(c).NoreplyEmail(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if addr != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, addr)
+			}
+		})
+	}
+
+	if _, err := (&bitbucket{}).NoreplyEmail(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestListEmails(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(c *client) Provider
+		routes   map[string]string
+		expected []Email
+	}{
+		{
+			"github",
+			func(c *client) Provider { return &gitHub{c} },
+			map[string]string{"/user/emails": `[{"email":"me@me.com","primary":true,"verified":true,"visibility":"private"},{"email":"old@me.com","primary":false,"verified":false,"visibility":null}]`},
+			[]Email{{Address: "me@me.com", Primary: true, Verified: true, Private: true}, {Address: "old@me.com"}},
+		},
+		{
+			"gitlab",
+			func(c *client) Provider { return &gitLab{c} },
+			map[string]string{
+				"/user":        `{"email":"me@me.com"}`,
+				"/user/emails": `[{"email":"me@me.com","confirmed_at":"2024-01-01T00:00:00Z"},{"email":"work@me.com","confirmed_at":null}]`,
+			},
+			[]Email{{Address: "me@me.com", Primary: true, Verified: true}, {Address: "work@me.com"}},
+		},
+		{
+			"bitbucket",
+			func(c *client) Provider { return &bitbucket{c} },
+			map[string]string{"/user/emails": `{"values":[{"email":"me@me.com","is_primary":true,"is_confirmed":true}]}`},
+			[]Email{{Address: "me@me.com", Primary: true, Verified: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tt.routes[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(body))
+			})
+
+			emails, err := tt.provider(c).ListEmails(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(emails) != len(tt.expected) {
+				t.Fatalf("expected %+v, got %+v", tt.expected, emails)
+			}
+			for i := range emails {
+				if emails[i] != tt.expected[i] {
+					t.Errorf("expected %+v, got %+v", tt.expected[i], emails[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package qr draws text as a QR code in a terminal, so a public key can
+// be scanned from a phone or copied into a server console without
+// retyping it. rsc.io/qr does the encoding.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// ErrTooLong is returned for text that does not fit in a version 40 code
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// maxBytes is what a version 40 code holds at level L in byte mode
+const maxBytes = 2953
+
+// Code is an encoded QR code
+type Code struct {
+	Version int
+	Size    int // modules per side, without the quiet zone
+
+	code *qr.Code
+}
+
+// Encode returns text as the smallest QR code that holds it, at error
+// correction level L
+func Encode(text string) (*Code, error) {
+	if len(text) > maxBytes {
+		return nil, ErrTooLong
+	}
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return &Code{Version: (code.Size - 17) / 4, Size: code.Size, code: code}, nil
+}
+
+// Dark reports whether the module at column x, row y is dark. Modules
+// outside the code, in the quiet zone, are light.
+func (c *Code) Dark(x, y int) bool {
+	return c.code.Black(x, y)
+}
+
+// quietZone is the light border scanners need around the code, in
+// modules. The standard asks for 4; terminals are dark enough around it
+// that 2 scans reliably.
+const quietZone = 2
+
+// Blocks draws the code with Unicode half blocks, two rows of modules per
+// line. Light modules are drawn as blocks and dark ones as spaces, like
+// 'qrencode -t UTF8', so the code reads correctly on the usual light text
+// on a dark background.
+func (c *Code) Blocks() string {
+	var b strings.Builder
+	for y := -quietZone; y < c.Size+quietZone; y += 2 {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			top, bottom := !c.Dark(x, y), !c.Dark(x, y+1)
+			if y+1 >= c.Size+quietZone {
+				bottom = false
+			}
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ASCII draws the code with '#' for light modules, two characters per
+// module, for terminals without block characters
+func (c *Code) ASCII() string {
+	var b strings.Builder
+	for y := -quietZone; y < c.Size+quietZone; y++ {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			if c.Dark(x, y) {
+				b.WriteString("  ")
+			} else {
+				b.WriteString("##")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
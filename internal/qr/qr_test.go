@@ -0,0 +1,70 @@
+package qr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		version int
+	}{
+		{"short", 10, 1},
+		{"fills version 1", 17, 1},
+		{"needs version 2", 18, 2},
+		{"ssh ed25519 key", 100, 5},
+		{"armored gpg key", 700, 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(strings.Repeat("a", tt.length))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code.Version != tt.version {
+				t.Errorf("expected version %d, got %d", tt.version, code.Version)
+			}
+			if code.Size != tt.version*4+17 {
+				t.Errorf("expected size %d, got %d", tt.version*4+17, code.Size)
+			}
+			// The three finder patterns
+			for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+				x, y := corner[0], corner[1]
+				if !code.Dark(x, y) || code.Dark(x+1, y+1) || !code.Dark(x+3, y+3) {
+					t.Errorf("expected a finder pattern at %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("a", 2954)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+	if _, err := Encode(strings.Repeat("a", 2953)); err != nil {
+		t.Errorf("expected the largest text to fit, got %v", err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	code, err := Encode("gitws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(code.Blocks(), "\n"), "\n")
+	width := code.Size + 2*quietZone
+	if len(lines) != (width+1)/2 {
+		t.Errorf("expected %d lines, got %d", (width+1)/2, len(lines))
+	}
+	if n := len([]rune(lines[0])); n != width {
+		t.Errorf("expected %d columns, got %d", width, n)
+	}
+	// The quiet zone is light, drawn as full blocks
+	if lines[0] != strings.Repeat("█", width) {
+		t.Errorf("expected the first line to be quiet zone, got %q", lines[0])
+	}
+}
@@ -0,0 +1,46 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces redacted secrets
+const Placeholder = "[REDACTED]"
+
+var (
+	privateKeyBlock = regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY[A-Z ]*-----.*?-----END [A-Z0-9 ]*PRIVATE KEY[A-Z ]*-----`)
+	publicKey       = regexp.MustCompile(`\b(ssh-ed25519|ssh-rsa|ssh-dss|ecdsa-sha2-nistp\d+|sk-[a-z0-9-]+@openssh\.com) [A-Za-z0-9+/]{20,}={0,2}`)
+	tokens          = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|glpat-[A-Za-z0-9_-]{20,}|xox[abpr]-[A-Za-z0-9-]{10,}|ATBB[A-Za-z0-9]{20,})`)
+	bearer          = regexp.MustCompile(`(?i)\b(bearer|token)(\s+|=|:\s*)[A-Za-z0-9._~+/-]{16,}=*`)
+	urlCredentials  = regexp.MustCompile(`\b(https?://)[^/\s:@]+:[^/\s@]+@`)
+	emailAddress    = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+)
+
+// Email replaces an address with a stable hash, so the same address can
+// be matched across files without being revealed
+func Email(addr string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(addr))))
+	return "email-" + hex.EncodeToString(sum[:])[:10] + "@redacted"
+}
+
+// Text strips key material, tokens and credentials from s and hashes the
+// email addresses in it
+func Text(s string) string {
+	s = privateKeyBlock.ReplaceAllString(s, "[REDACTED PRIVATE KEY]")
+	s = publicKey.ReplaceAllString(s, "$1 "+Placeholder)
+	s = tokens.ReplaceAllString(s, Placeholder)
+	s = bearer.ReplaceAllString(s, "$1$2"+Placeholder)
+	s = urlCredentials.ReplaceAllString(s, "$1"+Placeholder+"@")
+	s = emailAddress.ReplaceAllStringFunc(s, func(addr string) string {
+		// git@host in SSH URLs is a user name, not an address
+		local, domain, _ := strings.Cut(addr, "@")
+		if local == "git" || domain == "openssh.com" || domain == "redacted" {
+			return addr
+		}
+		return Email(addr)
+	})
+	return s
+}
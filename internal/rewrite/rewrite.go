@@ -3,7 +3,9 @@ package rewrite
 import (
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -91,3 +93,127 @@ func ExtractHostFromSSHURL(sshURL string) (string, error) {
 	}
 	return "", fmt.Errorf("unable to extract host from SSH URL: %s", sshURL)
 }
+
+// ExtractHost extracts the host from any Git URL or URL prefix, accepting
+// both scheme URLs (https://host/, ssh://git@host/) and scp-like forms
+// (git@host:path, host:)
+func ExtractHost(gitURL string) (string, error) {
+	if strings.Contains(gitURL, "://") {
+		u, err := url.Parse(gitURL)
+		if err == nil && u.Hostname() != "" {
+			return u.Hostname(), nil
+		}
+		return "", fmt.Errorf("unable to extract host from URL: %s", gitURL)
+	}
+
+	re := regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):`)
+	matches := re.FindStringSubmatch(gitURL)
+	if len(matches) == 2 {
+		return matches[1], nil
+	}
+	return "", fmt.Errorf("unable to extract host from URL: %s", gitURL)
+}
+
+// ReplaceHost swaps the host of a Git URL from oldHost to newHost, keeping
+// the user, port and path. It reports false when the URL uses another host.
+func ReplaceHost(gitURL, oldHost, newHost string) (string, bool) {
+	host, err := ExtractHost(gitURL)
+	if err != nil || host != oldHost {
+		return gitURL, false
+	}
+
+	if strings.Contains(gitURL, "://") {
+		scheme, rest, _ := strings.Cut(gitURL, "://")
+		authority, path, _ := strings.Cut(rest, "/")
+		userinfo, hostport, found := strings.Cut(authority, "@")
+		if !found {
+			userinfo, hostport = "", authority
+		}
+		hostport = newHost + strings.TrimPrefix(hostport, oldHost)
+		if userinfo != "" {
+			hostport = userinfo + "@" + hostport
+		}
+		return scheme + "://" + hostport + "/" + path, true
+	}
+
+	user, rest, found := strings.Cut(gitURL, "@")
+	if !found || strings.Contains(user, ":") {
+		return newHost + strings.TrimPrefix(gitURL, oldHost), true
+	}
+	return user + "@" + newHost + strings.TrimPrefix(rest, oldHost), true
+}
+
+// PickAlias returns the SSH alias for the host input points at, given a
+// map from host names to aliases. An input already using one of the
+// aliases keeps it; ORG/REPO shorthand gets fallback. For a host the map
+// does not serve it returns fallback and false, so callers can leave such
+// remotes alone instead of moving them onto another host's alias.
+func PickAlias(input string, aliases map[string]string, fallback string) (string, bool) {
+	host, err := ExtractHost(input)
+	if err != nil {
+		return fallback, true
+	}
+	if alias, ok := aliases[host]; ok && alias != "" {
+		return alias, true
+	}
+	for _, alias := range aliases {
+		if alias == host {
+			return alias, true
+		}
+	}
+	return fallback, false
+}
+
+// PickHost is PickAlias for the real host: given a map from host names to
+// aliases, it returns the host input points at, directly or through one
+// of the aliases. ORG/REPO shorthand and unknown hosts get fallback.
+func PickHost(input string, aliases map[string]string, fallback string) string {
+	host, err := ExtractHost(input)
+	if err != nil {
+		return fallback
+	}
+	if _, ok := aliases[host]; ok {
+		return host
+	}
+	for hostName, alias := range aliases {
+		if alias == host {
+			return hostName
+		}
+	}
+	return fallback
+}
+
+// HTTPSURL returns the HTTPS clone URL of org/repo on host
+func HTTPSURL(host, org, repo string) string {
+	return fmt.Sprintf("https://%s/%s/%s.git", host, org, repo)
+}
+
+// MatchOwner returns the value of the most specific pattern in owners
+// that matches org/repo, or "" when none does. Patterns are ORG/REPO with
+// shell wildcards (myemployer/*, myemployer/infra-*), or a bare ORG for
+// all of its repositories. Fewer wildcards, then a longer pattern, is
+// more specific.
+func MatchOwner(owners map[string]string, org, repo string) string {
+	patterns := make([]string, 0, len(owners))
+	for pattern := range owners {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var best string
+	bestWildcards, bestLength := -1, 0
+	for _, pattern := range patterns {
+		full := pattern
+		if !strings.Contains(full, "/") {
+			full += "/*"
+		}
+		if ok, err := path.Match(strings.ToLower(full), strings.ToLower(org+"/"+repo)); err != nil || !ok {
+			continue
+		}
+		wildcards := strings.Count(full, "*") + strings.Count(full, "?") + strings.Count(full, "[")
+		if bestWildcards < 0 || wildcards < bestWildcards || (wildcards == bestWildcards && len(full) > bestLength) {
+			best, bestWildcards, bestLength = owners[pattern], wildcards, len(full)
+		}
+	}
+	return best
+}
@@ -217,3 +217,167 @@ func TestExtractHostFromSSHURL(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractHost(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		hasErr   bool
+	}{
+		{"git@github.com:microsoft/vscode.git", "github.com", false},
+		{"git@github-com-work:", "github-com-work", false},
+		{"github.com:", "github.com", false},
+		{"https://github.com/", "github.com", false},
+		{"ssh://git@gitlab.example.com:2222/group/repo.git", "gitlab.example.com", false},
+		{"https://", "", true},
+		{"not-a-url", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ExtractHost(tt.input)
+
+			if tt.hasErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReplaceHost(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		replaced bool
+	}{
+		{"git@github.com-old:org/repo.git", "git@github.com-new:org/repo.git", true},
+		{"github.com-old:org/repo.git", "github.com-new:org/repo.git", true},
+		{"ssh://git@github.com-old/org/repo.git", "ssh://git@github.com-new/org/repo.git", true},
+		{"ssh://git@github.com-old:2222/org/repo.git", "ssh://git@github.com-new:2222/org/repo.git", true},
+		{"git@github.com-older:org/repo.git", "git@github.com-older:org/repo.git", false},
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, replaced := ReplaceHost(tt.input, "github.com-old", "github.com-new")
+
+			if replaced != tt.replaced {
+				t.Errorf("expected replaced=%v, got %v", tt.replaced, replaced)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPickAlias(t *testing.T) {
+	aliases := map[string]string{
+		"github.com":      "github-com-work",
+		"gitlab.acme.com": "gitlab-acme-com-work",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		served   bool
+	}{
+		{"acme/app", "github-com-work", true},
+		{"https://gitlab.acme.com/platform/app.git", "gitlab-acme-com-work", true},
+		{"git@gitlab.acme.com:platform/app.git", "gitlab-acme-com-work", true},
+		{"git@gitlab-acme-com-work:platform/app.git", "gitlab-acme-com-work", true},
+		{"https://github.com/acme/app", "github-com-work", true},
+		{"https://bitbucket.org/acme/app.git", "github-com-work", false},
+		{"git@bitbucket.org:acme/app.git", "github-com-work", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, served := PickAlias(tt.input, aliases, "github-com-work")
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if served != tt.served {
+				t.Errorf("expected served %v, got %v", tt.served, served)
+			}
+		})
+	}
+}
+
+func TestPickHost(t *testing.T) {
+	aliases := map[string]string{
+		"github.com":      "github-com-work",
+		"gitlab.acme.com": "gitlab-acme-com-work",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"acme/app", "github.com"},
+		{"https://gitlab.acme.com/platform/app.git", "gitlab.acme.com"},
+		{"git@gitlab-acme-com-work:platform/app.git", "gitlab.acme.com"},
+		{"git@github.com:acme/app.git", "github.com"},
+		{"https://bitbucket.org/acme/app.git", "github.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := PickHost(tt.input, aliases, "github.com"); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTTPSURL(t *testing.T) {
+	expected := "https://github.com/acme/app.git"
+	if result := HTTPSURL("github.com", "acme", "app"); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestMatchOwner(t *testing.T) {
+	owners := map[string]string{
+		"myemployer/*":        "work",
+		"myemployer/infra-*":  "ops",
+		"myemployer/handbook": "docs",
+		"myuser":              "personal",
+	}
+
+	tests := []struct {
+		name     string
+		org      string
+		repo     string
+		expected string
+	}{
+		{name: "org wildcard", org: "myemployer", repo: "api", expected: "work"},
+		{name: "longer pattern wins", org: "myemployer", repo: "infra-dns", expected: "ops"},
+		{name: "exact repository wins", org: "myemployer", repo: "handbook", expected: "docs"},
+		{name: "bare org", org: "myuser", repo: "dotfiles", expected: "personal"},
+		{name: "case insensitive", org: "MyEmployer", repo: "API", expected: "work"},
+		{name: "no match", org: "someone", repo: "else", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MatchOwner(owners, tt.org, tt.repo)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package rpc is a small JSON-RPC 2.0 server speaking newline-delimited
+// messages over stream connections, such as a Unix socket
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+)
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// maxMessageSize is the longest request line the server reads
+const maxMessageSize = 1 << 20
+
+// Error is a JSON-RPC error object. Handlers return one to choose the
+// code; any other error is reported as an internal error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InvalidParams returns an error for parameters a handler cannot use
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler answers one method. params is null when the request has none.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Request is a JSON-RPC request, or a notification when ID is absent
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server dispatches requests to registered handlers
+type Server struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewServer returns a server with no methods
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]Handler)}
+}
+
+// Register adds a method, replacing any handler of the same name
+func (s *Server) Register(method string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+}
+
+// Methods returns the names of the registered methods, sorted
+func (s *Server) Methods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var names []string
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Serve accepts connections until ctx is done or the listener fails.
+// Each connection is served on its own goroutine; Serve returns once
+// they have all finished.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn answers requests on conn, one per line, until the peer
+// closes it or ctx is done. Requests are handled in order.
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriteCloser) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if response := s.handle(ctx, line); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return
+			}
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		encoder.Encode(Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{
+			Code:    CodeInvalidRequest,
+			Message: fmt.Sprintf("request longer than %d bytes", maxMessageSize),
+		}})
+	}
+}
+
+// handle answers one message; notifications get no response
+func (s *Server) handle(ctx context.Context, message []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return &Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: err.Error()}}
+	}
+	id := req.ID
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: CodeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}}
+	}
+
+	s.mu.RLock()
+	handler, exists := s.handlers[req.Method]
+	s.mu.RUnlock()
+
+	var response *Response
+	if !exists {
+		response = &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}}
+	} else {
+		result, err := call(ctx, handler, req.Params)
+		response = &Response{JSONRPC: "2.0", ID: id, Result: result}
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+			}
+			response.Result, response.Error = nil, rpcErr
+		} else if result == nil {
+			response.Result = json.RawMessage("null")
+		}
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	return response
+}
+
+// call runs a handler, turning a panic into an internal error so one bad
+// request cannot take the server down
+func call(ctx context.Context, handler Handler, params json.RawMessage) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("internal error: %v", r)
+		}
+	}()
+	return handler(ctx, params)
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestServeConn(t *testing.T) {
+	server := NewServer()
+	server.Register("echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || p.Text == "" {
+			return nil, InvalidParams("text is required")
+		}
+		return map[string]string{"text": p.Text}, nil
+	})
+	server.Register("fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("it broke")
+	})
+	server.Register("panic", func(ctx context.Context, params json.RawMessage) (any, error) {
+		panic("oops")
+	})
+	server.Register("nothing", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		name     string
+		request  string
+		expected string
+	}{
+		{"result", `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`, `{"jsonrpc":"2.0","id":1,"result":{"text":"hi"}}`},
+		{"string id", `{"jsonrpc":"2.0","id":"a","method":"nothing"}`, `{"jsonrpc":"2.0","id":"a","result":null}`},
+		{"invalid params", `{"jsonrpc":"2.0","id":2,"method":"echo","params":{}}`, `{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"text is required"}}`},
+		{"handler error", `{"jsonrpc":"2.0","id":3,"method":"fail"}`, `{"jsonrpc":"2.0","id":3,"error":{"code":-32603,"message":"it broke"}}`},
+		{"panic", `{"jsonrpc":"2.0","id":4,"method":"panic"}`, `{"jsonrpc":"2.0","id":4,"error":{"code":-32603,"message":"internal error: oops"}}`},
+		{"unknown method", `{"jsonrpc":"2.0","id":5,"method":"nope"}`, `{"jsonrpc":"2.0","id":5,"error":{"code":-32601,"message":"unknown method \"nope\""}}`},
+		{"not 2.0", `{"id":6,"method":"echo"}`, `{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"not a JSON-RPC 2.0 request"}}`},
+	}
+
+	client, conn := net.Pipe()
+	go server.ServeConn(context.Background(), conn)
+	defer client.Close()
+	reader := bufio.NewReader(client)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.Write([]byte(tt.request + "\n")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := strings.TrimSpace(line); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("notification and parse error", func(t *testing.T) {
+		// The notification gets no response, so the next line read
+		// answers the malformed message
+		if _, err := client.Write([]byte(`{"jsonrpc":"2.0","method":"echo","params":{"text":"x"}}` + "\n{oops\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var response Response
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if response.Error == nil || response.Error.Code != CodeParseError {
+			t.Errorf("expected a parse error, got %s", line)
+		}
+	})
+}
+
+func TestServeStopsWithContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- NewServer().Serve(ctx, listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
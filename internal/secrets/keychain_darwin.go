@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychain stores secrets in the macOS login keychain via security(1)
+type keychain struct{}
+
+func platformStore() Store {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil
+	}
+	return keychain{}
+}
+
+func (keychain) Name() string {
+	return "macOS Keychain"
+}
+
+func (keychain) Get(account string) (string, error) {
+	secret, err := run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	if err != nil {
+		if strings.Contains(err.Error(), "could not be found") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+func (keychain) Set(account, secret string) error {
+	if strings.ContainsAny(secret, "\r\n") {
+		return fmt.Errorf("secret must be a single line")
+	}
+
+	// security(1) takes the password from -w or a tty prompt only. In
+	// interactive mode the command itself is read from stdin, which keeps
+	// the secret out of the argument list every user can see with ps.
+	command := strings.Join([]string{
+		"add-generic-password", "-U",
+		"-s", keychainQuote(Service),
+		"-a", keychainQuote(account),
+		"-l", keychainQuote(Service + " " + account),
+		"-w", keychainQuote(secret),
+	}, " ")
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command + "\nquit\n")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	// Interactive mode reports a failed command on stderr but still exits 0
+	msg := strings.TrimSpace(strings.ReplaceAll(stderr.String(), "security>", ""))
+	if msg != "" {
+		return fmt.Errorf("security: %s", msg)
+	}
+	if err != nil {
+		return fmt.Errorf("security: %w", err)
+	}
+	return nil
+}
+
+// keychainQuote double-quotes s for security(1)'s interactive mode
+func keychainQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (keychain) Delete(account string) error {
+	_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return ErrNotFound
+	}
+	return err
+}
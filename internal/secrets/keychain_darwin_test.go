@@ -0,0 +1,22 @@
+package secrets
+
+import "testing"
+
+func TestKeychainQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ghp_token", `"ghp_token"`},
+		{"gitws work", `"gitws work"`},
+		{`a"b`, `"a\"b"`},
+		{`a\b`, `"a\\b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := keychainQuote(tt.input); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
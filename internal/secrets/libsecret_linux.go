@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"os"
+	"os/exec"
+)
+
+// libsecret stores secrets in the Secret Service (GNOME Keyring, KWallet)
+// via secret-tool(1)
+type libsecret struct{}
+
+func platformStore() Store {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+	// The Secret Service lives on the session bus; headless machines
+	// have the tool installed but nothing to talk to
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	return libsecret{}
+}
+
+func (libsecret) Name() string {
+	return "Secret Service (libsecret)"
+}
+
+func (libsecret) Get(account string) (string, error) {
+	secret, err := run("", "secret-tool", "lookup", "service", Service, "account", account)
+	if err != nil {
+		// lookup exits non-zero without output when nothing matches
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (libsecret) Set(account, secret string) error {
+	_, err := run(secret, "secret-tool", "store", "--label", Service+" "+account, "service", Service, "account", account)
+	return err
+}
+
+func (l libsecret) Delete(account string) error {
+	if _, err := l.Get(account); err != nil {
+		return err
+	}
+	_, err := run("", "secret-tool", "clear", "service", Service, "account", account)
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !linux && !windows
+
+package secrets
+
+// platformStore returns nil: there is no supported OS store here, so
+// secrets go to encrypted files
+func platformStore() Store {
+	return nil
+}
@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/archive"
+)
+
+// Service is the service name secrets are filed under in OS stores
+const Service = "gitws"
+
+// ErrNotFound is returned when no secret is stored for an account
+var ErrNotFound = errors.New("secret not found")
+
+// ErrEmptyPassphrase is returned when the file store is given an empty
+// passphrase
+var ErrEmptyPassphrase = errors.New("passphrase must not be empty")
+
+// Store keeps secrets keyed by account (the workspace name)
+type Store interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// Open returns the OS credential store when one is usable, falling back
+// to passphrase-encrypted files in dir. passphrase is only called by the
+// file store, and only when a secret is actually read or written.
+func Open(dir string, passphrase func() (string, error)) Store {
+	if store := platformStore(); store != nil {
+		return store
+	}
+	return &FileStore{Dir: dir, Passphrase: passphrase}
+}
+
+// FileStore keeps each secret in <Dir>/<account>.enc, encrypted with a
+// passphrase-derived key
+type FileStore struct {
+	Dir        string
+	Passphrase func() (string, error)
+}
+
+// Name returns the store name
+func (f *FileStore) Name() string {
+	return "encrypted file"
+}
+
+func (f *FileStore) path(account string) string {
+	return filepath.Join(f.Dir, account+".enc")
+}
+
+// Get decrypts the secret stored for account
+func (f *FileStore) Get(account string) (string, error) {
+	data, err := os.ReadFile(f.path(account))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := archive.Decrypt(data, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Set encrypts and stores the secret for account
+func (f *FileStore) Set(account, secret string) error {
+	passphrase, err := f.passphrase()
+	if err != nil {
+		return err
+	}
+
+	data, err := archive.Encrypt([]byte(secret), passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	if err := os.WriteFile(f.path(account), data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret: %w", err)
+	}
+	return nil
+}
+
+// passphrase asks for the passphrase, refusing an empty one: it would
+// leave the secrets readable by anyone who can read the files
+func (f *FileStore) passphrase() (string, error) {
+	passphrase, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+	return passphrase, nil
+}
+
+// Delete removes the secret for account
+func (f *FileStore) Delete(account string) error {
+	if err := os.Remove(f.path(account)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to remove secret: %w", err)
+	}
+	return nil
+}
+
+// run executes a store helper tool, feeding stdin and returning trimmed
+// stdout; stderr is folded into the error
+func run(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	store := &FileStore{
+		Dir:        t.TempDir(),
+		Passphrase: func() (string, error) { return "hunter2", nil },
+	}
+
+	if _, err := store.Get("work"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.Set("work", "ghp_token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secret, err := store.Get("work")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret != "ghp_token" {
+		t.Errorf("expected %q, got %q", "ghp_token", secret)
+	}
+
+	store.Passphrase = func() (string, error) { return "wrong", nil }
+	if _, err := store.Get("work"); err == nil {
+		t.Errorf("expected error for wrong passphrase but got none")
+	}
+
+	if err := store.Delete("work"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := store.Delete("work"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFileStoreEmptyPassphrase(t *testing.T) {
+	store := &FileStore{
+		Dir:        t.TempDir(),
+		Passphrase: func() (string, error) { return "", nil },
+	}
+
+	if err := store.Set("work", "ghp_token"); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("expected ErrEmptyPassphrase, got %v", err)
+	}
+	if _, err := os.Stat(store.path("work")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written, got %v", err)
+	}
+}
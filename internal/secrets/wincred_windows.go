@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential mirrors the Win32 CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// wincred stores secrets in the Windows Credential Manager
+type wincred struct{}
+
+func platformStore() Store {
+	if advapi32.Load() != nil {
+		return nil
+	}
+	return wincred{}
+}
+
+func (wincred) Name() string {
+	return "Windows Credential Manager"
+}
+
+func target(account string) *uint16 {
+	name, _ := syscall.UTF16PtrFromString(Service + ":" + account)
+	return name
+}
+
+func (wincred) Get(account string) (string, error) {
+	var cred *credential
+	ret, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target(account))), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if err == errorNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return string(blob), nil
+}
+
+func (wincred) Set(account, secret string) error {
+	blob := []byte(secret)
+	user, _ := syscall.UTF16PtrFromString(account)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target(account),
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+
+	ret, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func (wincred) Delete(account string) error {
+	ret, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target(account))), credTypeGeneric, 0)
+	if ret == 0 {
+		if err == errorNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoAgent is returned when no ssh-agent can be reached
+var ErrNoAgent = errors.New("no ssh-agent is running")
+
+// AgentFingerprints returns the SHA256 fingerprints of the keys loaded
+// into ssh-agent
+func AgentFingerprints(ctx context.Context) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "ssh-add", "-l", "-E", "sha256")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	// ssh-add exits 1 when the agent holds no keys and 2 without an agent
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return nil, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 2:
+		return nil, ErrNoAgent
+	default:
+		return nil, fmt.Errorf("failed to list agent keys: %w", err)
+	}
+	return parseAgentList(stdout.String()), nil
+}
+
+// parseAgentList parses 'ssh-add -l -E sha256':
+// <bits> SHA256:<hash> <comment> (<type>)
+func parseAgentList(output string) []string {
+	var fingerprints []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.HasPrefix(fields[1], "SHA256:") {
+			fingerprints = append(fingerprints, fields[1])
+		}
+	}
+	return fingerprints
+}
@@ -0,0 +1,12 @@
+package ssh
+
+import "testing"
+
+func TestParseAgentList(t *testing.T) {
+	output := "256 SHA256:abc me@work.com gitws-work (ED25519)\n" +
+		"3072 SHA256:def me@home (RSA)\n"
+	got := parseAgentList(output)
+	if len(got) != 2 || got[0] != "SHA256:abc" || got[1] != "SHA256:def" {
+		t.Errorf("expected [SHA256:abc SHA256:def], got %q", got)
+	}
+}
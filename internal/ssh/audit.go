@@ -0,0 +1,189 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/i18n"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// Key audit finding kinds
+const (
+	FindingKeyMissing   = "key-missing"
+	FindingKeyMode      = "key-mode"
+	FindingKeyOwner     = "key-owner"
+	FindingDirMode      = "dir-mode"
+	FindingPubMissing   = "pub-missing"
+	FindingPairMismatch = "pair-mismatch"
+)
+
+// Finding is one problem with an SSH key or the ~/.ssh directory
+type Finding struct {
+	Kind    string
+	Path    string
+	Message string
+	// Fixable findings can be repaired with Repair; others need the user
+	Fixable bool
+}
+
+// AuditSSHDir checks that ~/.ssh is private to the user, as ssh expects
+func AuditSSHDir() []Finding {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Dir(configPath)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		findings = append(findings, Finding{
+			Kind:    FindingDirMode,
+			Path:    dir,
+			Message: i18n.T("%s has mode %04o; it should be 0700", dir, info.Mode().Perm()),
+			Fixable: true,
+		})
+	}
+	if owned, ok := fsutil.OwnedByCurrentUser(dir); ok && !owned {
+		findings = append(findings, Finding{
+			Kind:    FindingKeyOwner,
+			Path:    dir,
+			Message: i18n.T("%s is owned by another user; ssh refuses to use it", dir),
+		})
+	}
+	return findings
+}
+
+// AuditKey checks a private key: that it exists, is private to and owned
+// by the user, and that its .pub file holds the matching public key
+func AuditKey(privPath string) []Finding {
+	info, err := os.Stat(privPath)
+	if err != nil {
+		return []Finding{{
+			Kind:    FindingKeyMissing,
+			Path:    privPath,
+			Message: i18n.T("SSH key %s does not exist", privPath),
+		}}
+	}
+
+	var findings []Finding
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		findings = append(findings, Finding{
+			Kind:    FindingKeyMode,
+			Path:    privPath,
+			Message: i18n.T("SSH key %s has mode %04o; ssh ignores keys others can read", privPath, info.Mode().Perm()),
+			Fixable: true,
+		})
+	}
+	if owned, ok := fsutil.OwnedByCurrentUser(privPath); ok && !owned {
+		findings = append(findings, Finding{
+			Kind:    FindingKeyOwner,
+			Path:    privPath,
+			Message: i18n.T("SSH key %s is owned by another user; ssh refuses to use it", privPath),
+		})
+	}
+
+	derived, err := derivePublicKey(privPath)
+	if err != nil || derived == nil {
+		// Unreadable or an old encrypted PEM key; nothing to compare
+		return findings
+	}
+
+	pubPath := privPath + ".pub"
+	pubData, err := os.ReadFile(pubPath)
+	if err != nil {
+		return append(findings, Finding{
+			Kind:    FindingPubMissing,
+			Path:    pubPath,
+			Message: i18n.T("Public key %s is missing", pubPath),
+			Fixable: true,
+		})
+	}
+	pub, _, _, _, err := cryptossh.ParseAuthorizedKey(pubData)
+	if err != nil || cryptossh.FingerprintSHA256(pub) != cryptossh.FingerprintSHA256(derived) {
+		findings = append(findings, Finding{
+			Kind:    FindingPairMismatch,
+			Path:    pubPath,
+			Message: i18n.T("%s does not match its private key (%s); the wrong key may be registered with your provider", pubPath, cryptossh.FingerprintSHA256(derived)),
+			Fixable: true,
+		})
+	}
+	return findings
+}
+
+// Repair fixes a finding that is Fixable
+func Repair(f Finding) error {
+	switch f.Kind {
+	case FindingDirMode:
+		return os.Chmod(f.Path, 0700)
+	case FindingKeyMode:
+		return os.Chmod(f.Path, 0600)
+	case FindingPubMissing, FindingPairMismatch:
+		privPath := strings.TrimSuffix(f.Path, ".pub")
+		derived, err := derivePublicKey(privPath)
+		if err != nil {
+			return err
+		}
+		if derived == nil {
+			return fmt.Errorf("cannot derive the public key of %s", privPath)
+		}
+
+		// Keep the comment from the old .pub file, if there was one
+		line := strings.TrimSpace(string(cryptossh.MarshalAuthorizedKey(derived)))
+		if comment, err := GetKeyComment(f.Path); err == nil && comment != "" {
+			line += " " + comment
+		}
+		return fsutil.AtomicWrite(f.Path, []byte(line+"\n"), 0644)
+	default:
+		return fmt.Errorf("%s cannot be repaired automatically", f.Kind)
+	}
+}
+
+// ManagedIdentityFiles returns the IdentityFile paths in a workspace's
+// managed SSH config block
+func ManagedIdentityFiles(workspaceName string) ([]string, error) {
+	block, found, err := ReadManagedBlock(workspaceName)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(block, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.EqualFold(fields[0], "IdentityFile") {
+			files = append(files, fields[1])
+		}
+	}
+	return files, nil
+}
+
+// derivePublicKey returns the public half stored in a private key file.
+// Encrypted OpenSSH keys carry it in the clear; old encrypted PEM keys do
+// not, and nil is returned for them.
+func derivePublicKey(privPath string) (cryptossh.PublicKey, error) {
+	data, err := os.ReadFile(privPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	signer, err := cryptossh.ParsePrivateKey(data)
+	var missing *cryptossh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return signer.PublicKey(), nil
+	case errors.As(err, &missing):
+		return missing.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("%s is not a usable SSH private key: %w", privPath, err)
+	}
+}
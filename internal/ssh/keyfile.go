@@ -0,0 +1,135 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// KeyInfo describes a private key found on disk
+type KeyInfo struct {
+	Type        string // e.g. "ssh-ed25519"
+	Fingerprint string // SHA256:...
+	Encrypted   bool
+	PublicKey   string // authorized_keys line, without comment
+	CertPath    string // <key>-cert.pub when present
+}
+
+// InspectKey validates a provided private key: it must parse, be of a type
+// current OpenSSH accepts, and not be readable by other users. The public
+// half comes from <key>.pub, or is derived when the key is unencrypted.
+func InspectKey(privPath string) (KeyInfo, error) {
+	var info KeyInfo
+
+	stat, err := os.Stat(privPath)
+	if err != nil {
+		return info, fmt.Errorf("failed to read key: %w", err)
+	}
+	if runtime.GOOS != "windows" && stat.Mode().Perm()&0077 != 0 {
+		return info, fmt.Errorf("key %s is accessible by other users (mode %04o); run: chmod 600 %s", privPath, stat.Mode().Perm(), privPath)
+	}
+
+	data, err := os.ReadFile(privPath)
+	if err != nil {
+		return info, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	var pub cryptossh.PublicKey
+	signer, err := cryptossh.ParsePrivateKey(data)
+	var missing *cryptossh.PassphraseMissingError
+	switch {
+	case err == nil:
+		pub = signer.PublicKey()
+	case errors.As(err, &missing):
+		info.Encrypted = true
+		pub = missing.PublicKey
+	default:
+		return info, fmt.Errorf("%s is not a usable SSH private key: %w", privPath, err)
+	}
+
+	// Older encrypted PEM keys don't carry their public half
+	if pubData, err := os.ReadFile(privPath + ".pub"); err == nil {
+		if parsed, _, _, _, err := cryptossh.ParseAuthorizedKey(pubData); err == nil {
+			pub = parsed
+		}
+	}
+	if pub == nil {
+		return info, fmt.Errorf("key %s is encrypted and has no %s.pub", privPath, filepath.Base(privPath))
+	}
+
+	info.Type = pub.Type()
+	if info.Type == cryptossh.KeyAlgoDSA {
+		return info, fmt.Errorf("key %s is a DSA key, which OpenSSH no longer accepts", privPath)
+	}
+	info.Fingerprint = cryptossh.FingerprintSHA256(pub)
+	info.PublicKey = strings.TrimSpace(string(cryptossh.MarshalAuthorizedKey(pub)))
+
+	if certPath := privPath + "-cert.pub"; fsutil.FileExists(certPath) {
+		info.CertPath = certPath
+	}
+
+	return info, nil
+}
+
+// ImportKey copies a provided key pair (and certificate, if any) to the
+// workspace key path. The public key is written from info when the
+// source has no .pub file.
+func ImportKey(srcPath, workspaceName string, info KeyInfo) (privPath, pubPath string, err error) {
+	privPath, err = KeyPath(workspaceName)
+	if err != nil {
+		return "", "", err
+	}
+	pubPath = privPath + ".pub"
+
+	if fsutil.FileExists(privPath) {
+		return "", "", fmt.Errorf("%s already exists (use --rotate-key to move it aside)", privPath)
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(privPath)); err != nil {
+		return "", "", fmt.Errorf("failed to create .ssh directory: %w", err)
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read key: %w", err)
+	}
+	if err := fsutil.AtomicWrite(privPath, data, 0600); err != nil {
+		return "", "", fmt.Errorf("failed to write key: %w", err)
+	}
+
+	pubData, err := os.ReadFile(srcPath + ".pub")
+	if err != nil {
+		pubData = []byte(info.PublicKey + "\n")
+	}
+	if err := fsutil.AtomicWrite(pubPath, pubData, 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	// ssh picks up <key>-cert.pub next to IdentityFile on its own
+	if info.CertPath != "" {
+		certData, err := os.ReadFile(info.CertPath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read certificate: %w", err)
+		}
+		if err := fsutil.AtomicWrite(privPath+"-cert.pub", certData, 0644); err != nil {
+			return "", "", fmt.Errorf("failed to write certificate: %w", err)
+		}
+	}
+
+	return privPath, pubPath, nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of a public key in
+// authorized_keys format
+func Fingerprint(authorizedKey string) (string, error) {
+	pub, _, _, _, err := cryptossh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %w", err)
+	}
+	return cryptossh.FingerprintSHA256(pub), nil
+}
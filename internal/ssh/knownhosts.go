@@ -0,0 +1,212 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ProviderFingerprints are the SHA256 host key fingerprints the providers
+// publish. Scanned keys for these hosts are only trusted when they match.
+var ProviderFingerprints = map[string][]string{
+	"github.com": {
+		"SHA256:+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU", // ED25519
+		"SHA256:p2QAMXNIC1TJYWeIOttrVc98/R1BUFWu3/LiyKgUfQM", // ECDSA
+		"SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s", // RSA
+	},
+	"gitlab.com": {
+		"SHA256:eUXGGm1YGsMAS7vkcx6JOJdOGHPem5gQp4taiCfCLB8", // ED25519
+		"SHA256:HbW3g8zUjNSksFbqTiUWPWg2Bq1x8xdGUrliXFzSnUw", // ECDSA
+		"SHA256:ROQFvPThGrW4RuWLoL9tq9I9zJ42fK4XywyRtbOz/EQ", // RSA
+	},
+	"bitbucket.org": {
+		"SHA256:ybgmFkzwOSotHTHLJgHO0QN8L0xErw6vd0VhFA9m3SM", // ED25519
+		"SHA256:FC73VB6C4OQLSCrjEayhMp9UMxS97caD/Yyi2bhW/J0", // ECDSA
+		"SHA256:46OSHA1Rmj8E8ERTC6xkNcmGUw9c4twmUM6yrUG6y/Y", // RSA
+	},
+}
+
+// HostKey is a host key offered by a server
+type HostKey struct {
+	Host        string
+	Type        string
+	Fingerprint string
+	Line        string // known_hosts line
+}
+
+// Host key states reported by CheckKnownHost
+const (
+	HostKeyKnown   = "known"
+	HostKeyMissing = "missing"
+	HostKeyChanged = "changed" // recorded key does not match the published fingerprints
+)
+
+// KnownHostsPath returns the path to the user's known_hosts file
+func KnownHostsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".ssh", "known_hosts"), nil
+}
+
+// ScanHostKeys fetches a host's keys with ssh-keyscan
+func ScanHostKeys(ctx context.Context, host string) ([]HostKey, error) {
+	output, err := exec.CommandContext(ctx, "ssh-keyscan", "-T", "10", "-t", "ed25519,ecdsa,rsa", host).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh-keyscan %s failed: %w", host, err)
+	}
+	keys := ParseScannedKeys(string(output))
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("ssh-keyscan returned no keys for %s", host)
+	}
+	return keys, nil
+}
+
+// ParseScannedKeys parses ssh-keyscan output, skipping comments and lines
+// that do not hold a valid key
+func ParseScannedKeys(output string) []HostKey {
+	var keys []HostKey
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		host, keyText, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		key, _, _, _, err := cryptossh.ParseAuthorizedKey([]byte(keyText))
+		if err != nil {
+			continue
+		}
+		keys = append(keys, HostKey{
+			Host:        host,
+			Type:        key.Type(),
+			Fingerprint: cryptossh.FingerprintSHA256(key),
+			Line:        knownhosts.Line([]string{host}, key),
+		})
+	}
+	return keys
+}
+
+// VerifyHostKeys returns the keys whose fingerprints the provider publishes.
+// published reports whether fingerprints are bundled for the host at all.
+func VerifyHostKeys(host string, keys []HostKey) (verified []HostKey, published bool) {
+	fingerprints, published := ProviderFingerprints[host]
+	if !published {
+		return nil, false
+	}
+	for _, key := range keys {
+		for _, fp := range fingerprints {
+			if key.Fingerprint == fp {
+				verified = append(verified, key)
+				break
+			}
+		}
+	}
+	return verified, true
+}
+
+// KnownHostFingerprints returns the fingerprints recorded for host in
+// known_hosts, including hashed entries
+func KnownHostFingerprints(ctx context.Context, host string) ([]string, error) {
+	path, err := KnownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	// ssh-keygen -F exits 1 when the host has no entry
+	output, _ := exec.CommandContext(ctx, "ssh-keygen", "-l", "-F", host, "-f", path).Output()
+
+	var fingerprints []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && !strings.HasPrefix(line, "#") && strings.HasPrefix(fields[2], "SHA256:") {
+			fingerprints = append(fingerprints, fields[2])
+		}
+	}
+	return fingerprints, nil
+}
+
+// CheckKnownHost reports whether known_hosts has a key for host and, for
+// providers with published fingerprints, whether every recorded key matches
+func CheckKnownHost(ctx context.Context, host string) (state string, fingerprints []string, err error) {
+	fingerprints, err = KnownHostFingerprints(ctx, host)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(fingerprints) == 0 {
+		return HostKeyMissing, nil, nil
+	}
+
+	if published, ok := ProviderFingerprints[host]; ok {
+		for _, fp := range fingerprints {
+			match := false
+			for _, p := range published {
+				if fp == p {
+					match = true
+					break
+				}
+			}
+			if !match {
+				return HostKeyChanged, fingerprints, nil
+			}
+		}
+	}
+	return HostKeyKnown, fingerprints, nil
+}
+
+// AddKnownHosts appends keys to known_hosts, skipping lines already present
+func AddKnownHosts(keys []HostKey) (added int, err error) {
+	path, err := KnownHostsPath()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, fmt.Errorf("failed to create SSH directory: %w", err)
+	}
+
+	lock, err := fsutil.LockFile(path, fsutil.LockTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	for _, key := range keys {
+		if bytes.Contains(existing, []byte(key.Line)) {
+			continue
+		}
+		buf.WriteString(key.Line + "\n")
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err := fsutil.AtomicWrite(path, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write known_hosts: %w", err)
+	}
+	return added, nil
+}
@@ -0,0 +1,41 @@
+package ssh
+
+import (
+	"testing"
+)
+
+func TestVerifyHostKeys(t *testing.T) {
+	scanned := `# github.com:22 SSH-2.0-babeld-1
+github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl
+not a key line
+`
+	keys := ParseScannedKeys(scanned)
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+	if keys[0].Fingerprint != "SHA256:+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU" {
+		t.Errorf("expected the published GitHub fingerprint, got %q", keys[0].Fingerprint)
+	}
+
+	tests := []struct {
+		host      string
+		verified  int
+		published bool
+	}{
+		{"github.com", 1, true},
+		{"gitlab.com", 0, true},
+		{"git.example.com", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			verified, published := VerifyHostKeys(tt.host, keys)
+			if published != tt.published {
+				t.Errorf("expected published=%v, got %v", tt.published, published)
+			}
+			if len(verified) != tt.verified {
+				t.Errorf("expected %d verified keys, got %d", tt.verified, len(verified))
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+	"github.com/gitworkspaces/gitws/internal/workspace"
+)
+
+// EffectiveConfig is the configuration ssh applies to a host, as printed
+// by 'ssh -G'. Keys are lowercase; repeatable options keep every value.
+type EffectiveConfig map[string][]string
+
+// Get returns the first value of an option, or "" when it is unset
+func (c EffectiveConfig) Get(key string) string {
+	if values := c[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ExistingIdentityFiles returns the identity files ssh would try for the
+// host that actually exist on disk, with ~ expanded
+func (c EffectiveConfig) ExistingIdentityFiles() []string {
+	var files []string
+	for _, file := range c["identityfile"] {
+		if file == "none" {
+			continue
+		}
+		path, err := workspace.ExpandPath(file)
+		if err != nil {
+			continue
+		}
+		if fsutil.FileExists(path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// ResolveConfig runs 'ssh -G host' and returns the effective configuration
+func ResolveConfig(ctx context.Context, host string) (EffectiveConfig, error) {
+	output, err := exec.CommandContext(ctx, "ssh", "-G", host).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve SSH config for %s: %w", host, err)
+	}
+	return ParseEffectiveConfig(string(output)), nil
+}
+
+// ParseEffectiveConfig parses 'ssh -G' output
+func ParseEffectiveConfig(output string) EffectiveConfig {
+	config := make(EffectiveConfig)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(key)
+		config[key] = append(config[key], value)
+	}
+	return config
+}
+
+// sectionStart matches the Host or Match line that ends the global options
+// at the top of an SSH config
+var sectionStart = regexp.MustCompile(`(?i)^\s*(host|match)(\s|=)`)
+
+// placeHostGuardBlock returns content with block, replacing any earlier
+// host guard block, between the global options at the top and the first
+// Host or Match section along with the comments leading into it. Placed
+// at the very top, the block's Host line would scope the global options
+// that follow it to the guarded hosts.
+func placeHostGuardBlock(content, block string) string {
+	start, end := workspace.HostGuardStartMarker(), workspace.HostGuardEndMarker()
+	if i := strings.Index(content, start); i != -1 {
+		if j := strings.Index(content[i:], end); j != -1 {
+			j += i + len(end)
+			// Along with the blank line placing it adds
+			for n := 0; n < 2 && strings.HasPrefix(content[j:], "\n"); n++ {
+				j++
+			}
+			content = content[:i] + content[j:]
+		}
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	at := len(lines)
+	for i, line := range lines {
+		if sectionStart.MatchString(line) {
+			at = i
+			break
+		}
+	}
+	for at > 0 && at < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[at-1]), "#") {
+		at--
+	}
+
+	before := strings.Join(lines[:at], "")
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	after := strings.Join(lines[at:], "")
+	if after != "" && !strings.HasPrefix(after, "\n") {
+		block += "\n"
+	}
+	return before + block + "\n" + after
+}
+
+// RenderHostGuardBlock returns the managed block that stops ssh from
+// offering any key to the bare provider hosts, including markers
+func RenderHostGuardBlock(hosts []string) string {
+	return fmt.Sprintf(`%s
+Host %s
+  IdentitiesOnly yes
+  IdentityFile none
+%s`, workspace.HostGuardStartMarker(), strings.Join(hosts, " "), workspace.HostGuardEndMarker())
+}
+
+// UpsertHostGuardBlock writes the host guard block to ~/.ssh/config,
+// ahead of every Host and Match section, since ssh uses the first value it
+// finds for IdentitiesOnly and a later 'Host *' must not override it
+func UpsertHostGuardBlock(hosts []string) error {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	lock, err := fsutil.LockFile(configPath, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	var content string
+	if fsutil.FileExists(configPath) {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		content = string(data)
+	}
+
+	if err := fsutil.CreateBackup(configPath); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	newContent := placeHostGuardBlock(content, RenderHostGuardBlock(hosts))
+	if err := fsutil.AtomicWrite(configPath, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package ssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEffectiveConfig(t *testing.T) {
+	output := `user git
+hostname github.com
+IdentitiesOnly yes
+identityfile ~/.ssh/id_ed25519_gws_work
+identityfile ~/.ssh/id_rsa
+`
+	config := ParseEffectiveConfig(output)
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user", "git"},
+		{"hostname", "github.com"},
+		{"identitiesonly", "yes"},
+		{"identityfile", "~/.ssh/id_ed25519_gws_work"},
+		{"proxycommand", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if result := config.Get(tt.key); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	expectedFiles := []string{"~/.ssh/id_ed25519_gws_work", "~/.ssh/id_rsa"}
+	if !reflect.DeepEqual(config["identityfile"], expectedFiles) {
+		t.Errorf("expected %v, got %v", expectedFiles, config["identityfile"])
+	}
+}
+
+func TestPlaceHostGuardBlock(t *testing.T) {
+	block := RenderHostGuardBlock([]string{"github.com"})
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			expected: block + "\n",
+		},
+		{
+			name:     "global options first",
+			content:  "ServerAliveInterval 60\nIdentityFile ~/.ssh/id_rsa\n\n# Personal\nHost gitlab.com\n  User git\n",
+			expected: "ServerAliveInterval 60\nIdentityFile ~/.ssh/id_rsa\n\n" + block + "\n\n# Personal\nHost gitlab.com\n  User git\n",
+		},
+		{
+			name:     "host first",
+			content:  "Host *\n  AddKeysToAgent yes\n",
+			expected: block + "\n\nHost *\n  AddKeysToAgent yes\n",
+		},
+		{
+			name:     "match section",
+			content:  "Compression yes\nMatch host *.corp\n  User me\n",
+			expected: "Compression yes\n" + block + "\n\nMatch host *.corp\n  User me\n",
+		},
+		{
+			name:     "only global options",
+			content:  "ServerAliveInterval 60",
+			expected: "ServerAliveInterval 60\n" + block + "\n",
+		},
+		{
+			name:     "block at the top from an older version",
+			content:  block + "\nServerAliveInterval 60\nHost gitlab.com\n",
+			expected: "ServerAliveInterval 60\n" + block + "\n\nHost gitlab.com\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := placeHostGuardBlock(tt.content, block)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			if again := placeHostGuardBlock(result, block); again != result {
+				t.Errorf("expected placing the block again to change nothing, got %q", again)
+			}
+		})
+	}
+}
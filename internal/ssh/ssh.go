@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +12,26 @@ import (
 	"github.com/gitworkspaces/gitws/internal/workspace"
 )
 
+// KeyPath returns the default private key path for a workspace
+func KeyPath(workspaceName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	keyName := fmt.Sprintf("id_ed25519_gws_%s", workspaceName)
+	return filepath.Join(home, ".ssh", keyName), nil
+}
+
+// ConfigPath returns the path to the user's SSH config
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".ssh", "config"), nil
+}
+
 // EnsureKey creates an SSH key for the workspace if it doesn't exist
 func EnsureKey(workspaceName, email string) (privPath, pubPath string, created bool, err error) {
 	home, err := os.UserHomeDir()
@@ -18,8 +39,10 @@ func EnsureKey(workspaceName, email string) (privPath, pubPath string, created b
 		return "", "", false, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	keyName := fmt.Sprintf("id_ed25519_gws_%s", workspaceName)
-	privPath = filepath.Join(home, ".ssh", keyName)
+	privPath, err = KeyPath(workspaceName)
+	if err != nil {
+		return "", "", false, err
+	}
 	pubPath = privPath + ".pub"
 
 	// Check if key already exists
@@ -34,10 +57,10 @@ func EnsureKey(workspaceName, email string) (privPath, pubPath string, created b
 	}
 
 	// Generate SSH key
-	comment := fmt.Sprintf("%s gws-%s", email, workspaceName)
+	comment := KeyComment(email, workspaceName)
 	cmd := exec.Command("ssh-keygen", "-t", "ed25519", "-C", comment, "-f", privPath, "-N", "")
 
-	if err := cmd.Run(); err != nil {
+	if err := fsutil.RecordChange(cmd.Run, privPath, pubPath); err != nil {
 		return "", "", false, fmt.Errorf("failed to generate SSH key: %w", err)
 	}
 
@@ -49,7 +72,42 @@ func EnsureKey(workspaceName, email string) (privPath, pubPath string, created b
 	return privPath, pubPath, true, nil
 }
 
-// UpsertSSHConfigBlock updates the SSH config with a managed block for the workspace
+// KeyComment returns the comment gitws puts on a workspace key. It records
+// the identity the key was created for.
+func KeyComment(email, workspaceName string) string {
+	return fmt.Sprintf("%s gws-%s", email, workspaceName)
+}
+
+// GetKeyComment returns the comment field of a public key file
+func GetKeyComment(pubPath string) (string, error) {
+	publicKey, err := GetPublicKey(pubPath)
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(publicKey)
+	if len(fields) < 3 {
+		return "", nil
+	}
+	return strings.Join(fields[2:], " "), nil
+}
+
+// SetKeyComment changes the comment of a key pair in place. The key
+// material, and therefore its fingerprint, is unchanged. ssh-keygen prompts
+// on the terminal if the key is passphrase-protected.
+func SetKeyComment(privPath, comment string) error {
+	cmd := exec.Command("ssh-keygen", "-c", "-C", comment, "-f", privPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update key comment: %w", err)
+	}
+	return nil
+}
+
+// UpsertSSHConfigBlock updates the SSH config with a managed block for the
+// workspace. alias may list several space-separated Host patterns.
 func UpsertSSHConfigBlock(workspaceName, alias, hostName, keyPath string) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -58,6 +116,12 @@ func UpsertSSHConfigBlock(workspaceName, alias, hostName, keyPath string) error
 
 	configPath := filepath.Join(home, ".ssh", "config")
 
+	lock, err := fsutil.LockFile(configPath, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// Read existing config
 	var content string
 	if fsutil.FileExists(configPath) {
@@ -76,14 +140,7 @@ func UpsertSSHConfigBlock(workspaceName, alias, hostName, keyPath string) error
 	// Build new block
 	startMarker := workspace.StartMarker(workspaceName)
 	endMarker := workspace.EndMarker(workspaceName)
-
-	newBlock := fmt.Sprintf(`%s
-Host %s
-  HostName %s
-  User git
-  IdentityFile %s
-  IdentitiesOnly yes
-%s`, startMarker, alias, hostName, keyPath, endMarker)
+	newBlock := RenderSSHConfigBlock(workspaceName, alias, hostName, keyPath)
 
 	// Replace content between markers
 	newContent, _ := fsutil.ReplaceBetweenMarkers(content, startMarker, endMarker, newBlock)
@@ -96,6 +153,37 @@ Host %s
 	return nil
 }
 
+// RenderSSHConfigBlock returns the managed SSH config block for a workspace, including markers
+func RenderSSHConfigBlock(workspaceName, alias, hostName, keyPath string) string {
+	return fmt.Sprintf(`%s
+Host %s
+  HostName %s
+  User git
+  IdentityFile %s
+  IdentitiesOnly yes
+%s`, workspace.StartMarker(workspaceName), alias, hostName, keyPath, workspace.EndMarker(workspaceName))
+}
+
+// ReadManagedBlock returns the content between a workspace's markers in
+// ~/.ssh/config, and whether the block exists
+func ReadManagedBlock(workspaceName string) (string, bool, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return "", false, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	block, found := fsutil.ExtractBetweenMarkers(string(data), workspace.StartMarker(workspaceName), workspace.EndMarker(workspaceName))
+	return block, found, nil
+}
+
 // GetPublicKey reads the public key content
 func GetPublicKey(pubPath string) (string, error) {
 	data, err := os.ReadFile(pubPath)
@@ -107,20 +195,38 @@ func GetPublicKey(pubPath string) (string, error) {
 
 // TestSSHConnection tests SSH connection to a host
 func TestSSHConnection(alias string) error {
-	cmd := exec.Command("ssh", "-T", alias, "-o", "ConnectTimeout=10", "-o", "BatchMode=yes")
+	return TestSSHConnectionContext(context.Background(), alias)
+}
+
+// TestSSHConnectionContext tests SSH connection to a host, killing ssh
+// when ctx is done
+func TestSSHConnectionContext(ctx context.Context, alias string) error {
+	cmd := exec.CommandContext(ctx, "ssh", "-T", alias, "-o", "ConnectTimeout=10", "-o", "BatchMode=yes")
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 
 	_ = cmd.Run()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("SSH connection to %s: %w", alias, err)
+	}
 	// SSH returns exit code 1 for successful connection to Git servers
 	// Exit code 255 indicates connection failure
-	if cmd.ProcessState.ExitCode() == 255 {
+	if cmd.ProcessState == nil || cmd.ProcessState.ExitCode() == 255 {
 		return fmt.Errorf("SSH connection to %s failed", alias)
 	}
 
 	return nil
 }
 
+// CloseControlMaster stops a multiplexing master connection
+func CloseControlMaster(alias, controlPath string) error {
+	cmd := exec.Command("ssh", "-o", "ControlPath="+controlPath, "-O", "exit", alias)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to close control master for %s: %w", alias, err)
+	}
+	return nil
+}
+
 // RemoveSSHConfigBlock removes the managed block for a workspace
 func RemoveSSHConfigBlock(workspaceName string) error {
 	home, err := os.UserHomeDir()
@@ -134,6 +240,12 @@ func RemoveSSHConfigBlock(workspaceName string) error {
 		return nil // No config file to modify
 	}
 
+	lock, err := fsutil.LockFile(configPath, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// Read existing config
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+	"github.com/gitworkspaces/gitws/internal/fsutil"
+)
+
+// Counter prefixes
+const (
+	PrefixCommand = "command."
+	PrefixFix     = "fix."
+	PrefixGuard   = "guard."
+)
+
+// dayFormat keys the daily buckets
+const dayFormat = "2006-01-02"
+
+// Data is the local counter store in ~/.gws/stats.json: day -> counter ->
+// count. It is only written for users who opt in and never leaves the machine.
+type Data struct {
+	Days map[string]map[string]int `json:"days"`
+}
+
+// Count is a counter total over a period
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Path returns the path to the stats file
+func Path() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stats.json"), nil
+}
+
+// Load reads the stats file, returning empty data when there is none
+func Load() (*Data, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &Data{Days: make(map[string]map[string]int)}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to parse stats file: %w", err)
+	}
+	if data.Days == nil {
+		data.Days = make(map[string]map[string]int)
+	}
+	return data, nil
+}
+
+// Save writes the stats file
+func (d *Data) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	return fsutil.AtomicWrite(path, append(raw, '\n'), 0600)
+}
+
+// Add increments a counter in the bucket for the day of at
+func (d *Data) Add(counter string, at time.Time) {
+	day := at.Format(dayFormat)
+	if d.Days[day] == nil {
+		d.Days[day] = make(map[string]int)
+	}
+	d.Days[day][counter]++
+}
+
+// Totals sums each counter with the given prefix from since onwards,
+// largest first
+func (d *Data) Totals(prefix string, since time.Time) []Count {
+	first := since.Format(dayFormat)
+	sums := make(map[string]int)
+	for day, counters := range d.Days {
+		if day < first {
+			continue
+		}
+		for name, n := range counters {
+			if strings.HasPrefix(name, prefix) {
+				sums[strings.TrimPrefix(name, prefix)] += n
+			}
+		}
+	}
+
+	counts := make([]Count, 0, len(sums))
+	for name, n := range sums {
+		counts = append(counts, Count{Name: name, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// Record increments a counter for today. The file is locked while it is
+// read and written, so hooks and commands running at once keep each
+// other's counts.
+func Record(counter string) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	lock, err := fsutil.LockFile(path, fsutil.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := Load()
+	if err != nil {
+		return err
+	}
+	data.Add(counter, time.Now())
+	return data.Save()
+}
+
+// Reset deletes the stats file
+func Reset() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stats file: %w", err)
+	}
+	return nil
+}
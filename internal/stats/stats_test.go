@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gitworkspaces/gitws/internal/config"
+)
+
+func TestTotals(t *testing.T) {
+	data := &Data{Days: make(map[string]map[string]int)}
+	day := func(s string) time.Time {
+		at, err := time.Parse(dayFormat, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+
+	data.Add("command.clone", day("2026-01-10"))
+	data.Add("command.clone", day("2026-03-02"))
+	data.Add("command.status", day("2026-03-02"))
+	data.Add("command.status", day("2026-03-03"))
+	data.Add("command.status", day("2026-03-04"))
+	data.Add("fix.rewrite-remote", day("2026-03-04"))
+
+	tests := []struct {
+		name     string
+		prefix   string
+		since    string
+		expected []Count
+	}{
+		{"all commands", PrefixCommand, "2026-01-01", []Count{{"status", 3}, {"clone", 2}}},
+		{"window excludes old days", PrefixCommand, "2026-03-01", []Count{{"status", 3}, {"clone", 1}}},
+		{"fixes", PrefixFix, "2026-01-01", []Count{{"rewrite-remote", 1}}},
+		{"empty window", PrefixCommand, "2026-04-01", []Count{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := data.Totals(tt.prefix, day(tt.since))
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecordConcurrent(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	const runs = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Record(PrefixCommand + "status")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	totals := data.Totals(PrefixCommand, time.Now().AddDate(0, 0, -1))
+	if len(totals) != 1 || totals[0].Count != runs {
+		t.Errorf("expected %d status runs, got %v", runs, totals)
+	}
+}
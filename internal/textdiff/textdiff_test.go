@@ -0,0 +1,64 @@
+package textdiff
+
+import (
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name:     "equal",
+			a:        "Host a\n",
+			b:        "Host a\n",
+			expected: "",
+		},
+		{
+			name: "changed line",
+			a:    "Host a\n  HostName github.com\n  User git\n",
+			b:    "Host a\n  HostName gitlab.com\n  User git\n",
+			expected: "--- actual\n+++ desired\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" Host a\n" +
+				"-  HostName github.com\n" +
+				"+  HostName gitlab.com\n" +
+				"   User git\n",
+		},
+		{
+			name: "missing file",
+			a:    "",
+			b:    "[user]\n  email = you@work.com\n",
+			expected: "--- actual\n+++ desired\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+[user]\n" +
+				"+  email = you@work.com\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "x\n2\n3\n4\n5\n6\n7\n8\ny\n",
+			expected: "--- actual\n+++ desired\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-1\n" +
+				"+x\n" +
+				" 2\n" +
+				"@@ -8,2 +8,2 @@\n" +
+				" 8\n" +
+				"-9\n" +
+				"+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Unified("actual", "desired", tt.a, tt.b, 1)
+			if result != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package update
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"aead.dev/minisign"
+)
+
+// Repository is the GitHub repository releases are published to
+const Repository = "gitworkspaces/gitws"
+
+// checksumsName is the checksum file goreleaser attaches to every release
+const checksumsName = "checksums.txt"
+
+// signatureName is the minisign signature of the checksum file
+const signatureName = checksumsName + ".minisig"
+
+// releaseKey is the minisign public key release checksums are signed
+// with. It is pinned in the binary, so a release that was tampered with
+// cannot vouch for itself.
+const releaseKey = "RWTnJD13Lw+3ULhMxJIugb8KFq7YDcTfnXcsYekF9+Vrc9cg0JbRKXoR"
+
+// maxDownload bounds how much of a release asset is read
+const maxDownload = 200 << 20
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published gitws release
+type Release struct {
+	Tag     string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Version returns the release version without the leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset with the given file name
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client talks to the GitHub releases API
+type Client struct {
+	http       *http.Client
+	baseURL    string
+	signingKey string
+}
+
+// NewClient returns a client for the public GitHub API
+func NewClient() *Client {
+	return &Client{
+		http:       &http.Client{Timeout: 60 * time.Second},
+		baseURL:    "https://api.github.com",
+		signingKey: releaseKey,
+	}
+}
+
+// Latest returns the newest non-prerelease release
+func (c *Client) Latest() (*Release, error) {
+	data, err := c.get(fmt.Sprintf("%s/repos/%s/releases/latest", c.baseURL, Repository))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the latest release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// FetchBinary downloads the release archive for a platform, verifies it
+// against the release checksums, once their signature checks out with
+// the pinned key, and returns the gitws binary inside it
+func (c *Client) FetchBinary(release *Release, goos, goarch string) ([]byte, error) {
+	name := ArchiveName(release.Version(), goos, goarch)
+	archive, ok := release.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no archive for %s/%s", release.Tag, goos, goarch)
+	}
+	sums, ok := release.Asset(checksumsName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.Tag, checksumsName)
+	}
+
+	signature, ok := release.Asset(signatureName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.Tag, signatureName)
+	}
+
+	sumData, err := c.get(sums.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", checksumsName, err)
+	}
+	sigData, err := c.get(signature.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", signatureName, err)
+	}
+	if err := verifySignature(c.signingKey, sumData, sigData); err != nil {
+		return nil, err
+	}
+	expected, ok := parseChecksums(sumData)[name]
+	if !ok {
+		return nil, fmt.Errorf("%s does not list %s", checksumsName, name)
+	}
+
+	data, err := c.get(archive.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if err := verifyChecksum(data, expected); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	binary := "gitws"
+	if goos == "windows" {
+		binary += ".exe"
+	}
+	return extractBinary(data, binary)
+}
+
+func (c *Client) get(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// ArchiveName returns the file name of a release archive, following the
+// name_template in .goreleaser.yaml
+func ArchiveName(version, goos, goarch string) string {
+	return fmt.Sprintf("gitws_%s_%s_%s.tar.gz", version, goos, goarch)
+}
+
+// parseChecksums parses sha256sum output into file name -> hex digest
+func parseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// verifySignature checks a minisign signature of the checksum file
+// against key
+func verifySignature(key string, checksums, signature []byte) error {
+	var publicKey minisign.PublicKey
+	if err := publicKey.UnmarshalText([]byte(key)); err != nil {
+		return fmt.Errorf("invalid release signing key: %w", err)
+	}
+	if !minisign.Verify(publicKey, checksums, signature) {
+		return fmt.Errorf("%s is not signed by the gitws release key; refusing to install it", checksumsName)
+	}
+	return nil
+}
+
+// verifyChecksum checks data against a hex SHA-256 digest
+func verifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// extractBinary returns the file called name from a .tar.gz archive
+func extractBinary(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive does not contain %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+// Newer reports whether version a is newer than version b. Both may have
+// a leading "v"; a pre-release ("1.2.0-rc1") sorts before its release.
+func Newer(a, b string) bool {
+	pa, prea := splitVersion(a)
+	pb, preb := splitVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+	switch {
+	case prea == preb:
+		return false
+	case prea == "":
+		return true
+	case preb == "":
+		return false
+	}
+	return prea > preb
+}
+
+// splitVersion parses "v1.2.3-rc1" into [1 2 3] and "rc1"
+func splitVersion(version string) ([3]int, string) {
+	version = strings.TrimPrefix(version, "v")
+	version, pre, _ := strings.Cut(version, "-")
+	var parts [3]int
+	for i, field := range strings.SplitN(version, ".", 3) {
+		n, _ := strconv.Atoi(field)
+		parts[i] = n
+	}
+	return parts, pre
+}
+
+// Manager returns the package manager that installed the binary at path,
+// and the command that upgrades it. ok is false for a standalone binary.
+func Manager(path string) (name, upgrade string, ok bool) {
+	p := filepath.ToSlash(path)
+	switch {
+	case strings.Contains(p, "/Cellar/") || strings.Contains(p, "/homebrew/") || strings.Contains(p, "/linuxbrew/"):
+		return "Homebrew", "brew upgrade gitws", true
+	case strings.Contains(strings.ToLower(p), "/scoop/"):
+		return "Scoop", "scoop update gitws", true
+	case strings.HasPrefix(p, "/nix/store/"):
+		return "Nix", "nix profile upgrade gitws", true
+	case strings.HasPrefix(p, "/snap/"):
+		return "Snap", "snap refresh gitws", true
+	}
+
+	// go install puts binaries in GOBIN or GOPATH/bin
+	gobin := os.Getenv("GOBIN")
+	if gobin == "" {
+		if gopath := os.Getenv("GOPATH"); gopath != "" {
+			gobin = filepath.Join(gopath, "bin")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			gobin = filepath.Join(home, "go", "bin")
+		}
+	}
+	if gobin != "" && filepath.Dir(path) == filepath.Clean(gobin) {
+		return "go install", "go install github.com/" + Repository + "/cmd/gitws@latest", true
+	}
+	return "", "", false
+}
+
+// Replace swaps the binary at path for a new one. The new binary is
+// written next to it first so the swap is a rename.
+func Replace(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gitws-update-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0111); err != nil {
+		return err
+	}
+
+	// Windows cannot replace a running executable, but can rename it
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
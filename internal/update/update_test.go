@@ -0,0 +1,162 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aead.dev/minisign"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"1.4.0", "1.3.9", true},
+		{"v1.4.0", "1.4.0", false},
+		{"1.4.0", "1.10.0", false},
+		{"2.0.0", "1.99.99", true},
+		{"1.4.0", "1.4.0-rc1", true},
+		{"1.4.0-rc2", "1.4.0-rc1", true},
+		{"1.4.0-rc1", "1.4.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if result := Newer(tt.a, tt.b); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	t.Setenv("GOBIN", "/home/me/go/bin")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/opt/homebrew/Cellar/gitws/1.3.0/bin/gitws", "Homebrew"},
+		{"/home/linuxbrew/.linuxbrew/bin/gitws", "Homebrew"},
+		{"C:/Users/me/scoop/apps/gitws/current/gitws.exe", "Scoop"},
+		{"/nix/store/abc-gitws-1.3.0/bin/gitws", "Nix"},
+		{"/home/me/go/bin/gitws", "go install"},
+		{"/usr/local/bin/gitws", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if name, _, _ := Manager(tt.path); name != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, name)
+			}
+		})
+	}
+}
+
+func TestFetchBinary(t *testing.T) {
+	archive := tarball(t, "gitws", "new binary")
+	sum := sha256.Sum256(archive)
+	name := ArchiveName("1.4.0", "linux", "amd64")
+	valid := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+
+	releaseKey, releasePrivate, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivate, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(key minisign.PrivateKey, checksums string) string {
+		return string(minisign.Sign(key, []byte(checksums)))
+	}
+
+	for _, tt := range []struct {
+		name      string
+		checksums string
+		signature string
+		expected  string
+	}{
+		{"valid", valid, sign(releasePrivate, valid), ""},
+		{"mismatch", fmt.Sprintf("%s  %s\n", strings.Repeat("0", 64), name), "", "checksum mismatch"},
+		{"unlisted", "", "", "does not list"},
+		{"other key", valid, sign(otherPrivate, valid), "not signed by the gitws release key"},
+		{"signature of other checksums", strings.ToUpper(valid), sign(releasePrivate, valid), "not signed by the gitws release key"},
+		{"unsigned", valid, "-", "has no checksums.txt.minisig"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.signature == "" {
+				tt.signature = sign(releasePrivate, tt.checksums)
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/" + name:
+					w.Write(archive)
+				case "/checksums.txt":
+					w.Write([]byte(tt.checksums))
+				case "/checksums.txt.minisig":
+					w.Write([]byte(tt.signature))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			release := &Release{Tag: "v1.4.0", Assets: []Asset{
+				{Name: name, URL: server.URL + "/" + name},
+				{Name: checksumsName, URL: server.URL + "/checksums.txt"},
+			}}
+			if tt.signature != "-" {
+				release.Assets = append(release.Assets, Asset{Name: signatureName, URL: server.URL + "/checksums.txt.minisig"})
+			}
+			client := &Client{http: server.Client(), baseURL: server.URL, signingKey: releaseKey.String()}
+
+			binary, err := client.FetchBinary(release, "linux", "amd64")
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("expected error containing %q, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(binary) != "new binary" {
+				t.Errorf("expected %q, got %q", "new binary", binary)
+			}
+		})
+	}
+}
+
+func tarball(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct{ name, content string }{{"README.md", "readme"}, {name, content}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(f.content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestReleaseKey(t *testing.T) {
+	var key minisign.PublicKey
+	if err := key.UnmarshalText([]byte(releaseKey)); err != nil {
+		t.Errorf("expected the pinned release key to parse, got %v", err)
+	}
+}
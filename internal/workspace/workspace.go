@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/gitworkspaces/gitws/internal/config"
 )
 
 // ProviderHosts maps provider names to their hostnames
@@ -76,13 +78,117 @@ func GitConfigPath(workspace string) (string, error) {
 	return filepath.Join(configDir, "gitconfig", workspace), nil
 }
 
+// Dir returns the directory holding a workspace's managed files
+func Dir(workspace string) (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, workspace), nil
+}
+
+// ExcludesFilePath returns the path of a workspace's core.excludesFile
+func ExcludesFilePath(workspace string) (string, error) {
+	dir, err := Dir(workspace)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitignore"), nil
+}
+
+// AttributesFilePath returns the path of a workspace's core.attributesFile
+func AttributesFilePath(workspace string) (string, error) {
+	dir, err := Dir(workspace)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitattributes"), nil
+}
+
+// GlobalHooksDir returns the directory used as the global core.hooksPath
+func GlobalHooksDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "hooks"), nil
+}
+
+// SecretsDir returns the directory for encrypted secrets when no OS
+// credential store is available
+func SecretsDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "secrets"), nil
+}
+
 // ConfigDir returns the configuration directory path
 func ConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+	return config.ConfigDir()
+}
+
+// Isolation modes select how a workspace gitconfig is conditionally included
+const (
+	// IsolationGitDir includes the workspace gitconfig for repos under the workspace root
+	IsolationGitDir = "gitdir"
+	// IsolationHasconfig includes the workspace gitconfig for repos whose remote uses the workspace alias
+	IsolationHasconfig = "hasconfig"
+)
+
+// Pull strategies a workspace can enforce
+const (
+	PullMerge  = "merge"
+	PullRebase = "rebase"
+	PullFFOnly = "ff-only"
+)
+
+// IsValidPullStrategy reports whether strategy is a supported pull strategy
+func IsValidPullStrategy(strategy string) bool {
+	switch strategy {
+	case PullMerge, PullRebase, PullFFOnly:
+		return true
+	}
+	return false
+}
+
+// PullConfig returns the pull.rebase and pull.ff values implementing
+// strategy; an empty value means the key is left unset
+func PullConfig(strategy string) (rebase, ff string) {
+	switch strategy {
+	case PullMerge:
+		return "false", ""
+	case PullRebase:
+		return "true", ""
+	case PullFFOnly:
+		return "", "only"
 	}
-	return filepath.Join(home, ".gws"), nil
+	return "", ""
+}
+
+// Transports select how a workspace's repositories reach their host
+const (
+	// TransportSSH uses the workspace SSH alias and key
+	TransportSSH = "ssh"
+	// TransportHTTPS uses HTTPS with the workspace token, for networks
+	// that block SSH
+	TransportHTTPS = "https"
+)
+
+// IsValidTransport reports whether transport is a supported transport
+func IsValidTransport(transport string) bool {
+	return transport == TransportSSH || transport == TransportHTTPS
+}
+
+// IsValidIsolation reports whether mode is a supported isolation mode
+func IsValidIsolation(mode string) bool {
+	return mode == IsolationGitDir || mode == IsolationHasconfig
+}
+
+// BuildHasconfigCondition creates the remote URL condition for includeIf
+func BuildHasconfigCondition(alias string) string {
+	return fmt.Sprintf("hasconfig:remote.*.url:git@%s:**", alias)
 }
 
 // BuildIncludeIfCondition creates the gitdir condition for includeIf
@@ -119,3 +225,14 @@ func IncludeIfStartMarker() string {
 func IncludeIfEndMarker() string {
 	return "# <<< gws includeIf <<<"
 }
+
+// HostGuardStartMarker returns the start marker for the SSH block that
+// stops bare provider hosts from offering default keys
+func HostGuardStartMarker() string {
+	return "# >>> gws host-guard >>> DO NOT EDIT"
+}
+
+// HostGuardEndMarker returns the end marker for the SSH host guard block
+func HostGuardEndMarker() string {
+	return "# <<< gws host-guard <<<"
+}